@@ -34,6 +34,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/logs/diagnostic"
 	"github.com/DataDog/datadog-agent/pkg/metadata/inventories"
 	v5 "github.com/DataDog/datadog-agent/pkg/metadata/v5"
+	"github.com/DataDog/datadog-agent/pkg/netflow"
 	"github.com/DataDog/datadog-agent/pkg/secrets"
 	"github.com/DataDog/datadog-agent/pkg/status"
 	"github.com/DataDog/datadog-agent/pkg/status/health"
@@ -155,6 +156,8 @@ func componentStatusGetterHandler(w http.ResponseWriter, r *http.Request) {
 	switch component {
 	case "py":
 		getPythonStatus(w, r)
+	case "netflow":
+		getNetflowStatus(w, r)
 	default:
 		http.Error(w, log.Errorf("bad url or resource does not exist").Error(), 404)
 	}
@@ -256,6 +259,32 @@ func streamLogs(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func getNetflowStatus(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if !netflow.IsEnabled() {
+		body, _ := json.Marshal(map[string]string{
+			"error":      "NetFlow not enabled in the Agent configuration",
+			"error_type": "not enabled",
+		})
+		w.WriteHeader(400)
+		w.Write(body)
+		return
+	}
+
+	exporters, err := netflow.GetExportersStatus()
+	if err != nil {
+		setJSONError(w, log.Errorf("Error getting the NetFlow exporters status: %s", err), 500)
+		return
+	}
+
+	body, err := json.Marshal(exporters)
+	if err != nil {
+		setJSONError(w, log.Errorf("Error marshalling the NetFlow exporters status: %s", err), 500)
+		return
+	}
+	w.Write(body)
+}
+
 func getDogstatsdStats(w http.ResponseWriter, r *http.Request, dogstatsdServer dogstatsdServer.Component, serverDebug dogstatsdDebug.Component) {
 	log.Info("Got a request for the Dogstatsd stats.")
 
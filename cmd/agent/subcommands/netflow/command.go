@@ -0,0 +1,332 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+// Package netflow implements 'agent netflow'.
+package netflow
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/fx"
+
+	"github.com/DataDog/datadog-agent/cmd/agent/command"
+	"github.com/DataDog/datadog-agent/comp/core"
+	"github.com/DataDog/datadog-agent/comp/core/config"
+	"github.com/DataDog/datadog-agent/comp/core/log"
+	"github.com/DataDog/datadog-agent/pkg/api/util"
+	pkgconfig "github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/netflow/common"
+	nfconfig "github.com/DataDog/datadog-agent/pkg/netflow/config"
+	"github.com/DataDog/datadog-agent/pkg/netflow/flowaggregator"
+	"github.com/DataDog/datadog-agent/pkg/netflow/replay"
+	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
+)
+
+// cliParams are the command-line arguments for this subcommand
+type cliParams struct {
+	*command.GlobalParams
+
+	// subcommand-specific flags
+
+	jsonStatus      bool
+	prettyPrintJSON bool
+
+	replayFile   string
+	replaySpeed  float64
+	replayLoops  int
+	replayHost   string
+	replayPort   uint16
+	replaySource string
+}
+
+// Commands returns a slice of subcommands for the 'agent' command.
+func Commands(globalParams *command.GlobalParams) []*cobra.Command {
+	cliParams := &cliParams{
+		GlobalParams: globalParams,
+	}
+
+	netflowStatusCmd := &cobra.Command{
+		Use:   "status",
+		Short: "Print the health of the NetFlow exporters: last packet, templates and sequence gaps",
+		Long:  ``,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return fxutil.OneShot(requestNetflowStatus,
+				fx.Supply(cliParams),
+				fx.Supply(command.GetDefaultCoreBundleParams(cliParams.GlobalParams)),
+				core.Bundle,
+			)
+		},
+	}
+	netflowStatusCmd.Flags().BoolVarP(&cliParams.jsonStatus, "json", "j", false, "print out raw json")
+	netflowStatusCmd.Flags().BoolVarP(&cliParams.prettyPrintJSON, "pretty-json", "p", false, "pretty print JSON")
+
+	netflowReplayCmd := &cobra.Command{
+		Use:   "replay",
+		Short: "Replay the NetFlow, IPFIX and sFlow packets of a pcap capture into the running listeners",
+		Long: `Replay the NetFlow, IPFIX and sFlow packets of a pcap or pcapng capture into the listeners of the running agent,
+to validate the configuration and the mappings against the traffic of real devices before pointing them at the agent.
+The packets are sent to the port of the listener configured for their flow type, unless a port is given.
+The agent sees the packets as sent by the address they are sent from, which can be set with --source to replay
+the capture of an exporter whose configuration depends on its address.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return fxutil.OneShot(replayNetflow,
+				fx.Supply(cliParams),
+				fx.Supply(command.GetDefaultCoreBundleParams(cliParams.GlobalParams)),
+				core.Bundle,
+			)
+		},
+	}
+	netflowReplayCmd.Flags().StringVarP(&cliParams.replayFile, "file", "f", "", "pcap or pcapng capture of the flow packets to replay")
+	netflowReplayCmd.Flags().Float64VarP(&cliParams.replaySpeed, "speed", "s", 1, "replay speed relative to the capture, 0 to send the packets as fast as possible")
+	netflowReplayCmd.Flags().IntVarP(&cliParams.replayLoops, "loops", "l", 1, "number of times the capture is replayed")
+	netflowReplayCmd.Flags().StringVar(&cliParams.replayHost, "host", "127.0.0.1", "host of the listeners")
+	netflowReplayCmd.Flags().Uint16VarP(&cliParams.replayPort, "port", "p", 0, "port to send all the packets to, instead of the port of the listener of their flow type")
+	netflowReplayCmd.Flags().StringVar(&cliParams.replaySource, "source", "", "local address to send the packets from, seen by the agent as the exporter address, e.g. any 127.0.0.0/8 address on Linux")
+	netflowReplayCmd.MarkFlagRequired("file") //nolint:errcheck
+
+	netflowCmd := &cobra.Command{
+		Use:   "netflow",
+		Short: "NetFlow tools",
+		Long:  ``,
+	}
+	netflowCmd.AddCommand(netflowStatusCmd)
+	netflowCmd.AddCommand(netflowReplayCmd)
+
+	return []*cobra.Command{netflowCmd}
+}
+
+func requestNetflowStatus(log log.Component, config config.Component, cliParams *cliParams) error {
+	c := util.GetClient(false) // FIX: get certificates right then make this true
+	ipcAddress, err := pkgconfig.GetIPCAddress()
+	if err != nil {
+		return err
+	}
+	urlstr := fmt.Sprintf("https://%v:%v/agent/netflow/status", ipcAddress, pkgconfig.Datadog.GetInt("cmd_port"))
+
+	// Set session token
+	if err := util.SetAuthToken(); err != nil {
+		return err
+	}
+
+	r, e := util.DoGet(c, urlstr, util.LeaveConnectionOpen)
+	if e != nil {
+		var errMap = make(map[string]string)
+		json.Unmarshal(r, &errMap) //nolint:errcheck
+		// If the error has been marshalled into a json object, check it and return it properly
+		if err, found := errMap["error"]; found {
+			e = fmt.Errorf(err)
+		}
+
+		if len(errMap["error_type"]) > 0 {
+			fmt.Println(e)
+			return nil
+		}
+
+		fmt.Printf("Could not reach agent: %v \nMake sure the agent is running before requesting the NetFlow status and contact support if you continue having issues. \n", e)
+		return e
+	}
+
+	// The rendering is done in the client so that the agent has less work to do
+	if cliParams.prettyPrintJSON {
+		var prettyJSON bytes.Buffer
+		json.Indent(&prettyJSON, r, "", "  ") //nolint:errcheck
+		fmt.Println(prettyJSON.String())
+		return nil
+	} else if cliParams.jsonStatus {
+		fmt.Println(string(r))
+		return nil
+	}
+
+	var exporters []flowaggregator.ExporterStatus
+	if err := json.Unmarshal(r, &exporters); err != nil {
+		return fmt.Errorf("could not parse the NetFlow status: %w", err)
+	}
+	fmt.Print(formatExportersStatus(exporters, time.Now()))
+	return nil
+}
+
+func replayNetflow(log log.Component, config config.Component, cliParams *cliParams) error {
+	f, err := os.Open(cliParams.replayFile)
+	if err != nil {
+		return err
+	}
+	packets, err := replay.ReadPackets(f)
+	f.Close()
+	if err != nil {
+		return err
+	}
+	if len(packets) == 0 {
+		return fmt.Errorf("no UDP packet found in %s", cliParams.replayFile)
+	}
+
+	var source net.IP
+	if cliParams.replaySource != "" {
+		if source = net.ParseIP(cliParams.replaySource); source == nil {
+			return fmt.Errorf("invalid source address %q", cliParams.replaySource)
+		}
+	}
+
+	var listeners []nfconfig.ListenerConfig
+	if cliParams.replayPort == 0 {
+		netflowConfig, err := nfconfig.ReadConfig()
+		if err != nil {
+			return fmt.Errorf("could not read the NetFlow configuration: %w", err)
+		}
+		listeners = netflowConfig.Listeners
+	}
+
+	sender := &packetSender{
+		host:      cliParams.replayHost,
+		port:      cliParams.replayPort,
+		source:    source,
+		listeners: listeners,
+		conns:     make(map[uint16]net.Conn),
+		sent:      make(map[common.FlowType]int),
+	}
+	defer sender.close()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	replayer := replay.NewReplayer(cliParams.replaySpeed, sender.send)
+	fmt.Printf("Replaying %d packets from %s\n", len(packets), cliParams.replayFile)
+	for i := 0; i < cliParams.replayLoops; i++ {
+		if _, err = replayer.Replay(ctx, packets); err != nil {
+			break
+		}
+	}
+	fmt.Print(sender.summary())
+	if errors.Is(err, context.Canceled) {
+		return nil
+	}
+	return err
+}
+
+// packetSender sends the replayed packets to the listeners of their flow type
+type packetSender struct {
+	host string
+	port uint16
+	// source is the local address the packets are sent from, chosen by the system when nil
+	source    net.IP
+	listeners []nfconfig.ListenerConfig
+
+	conns map[uint16]net.Conn
+	sent  map[common.FlowType]int
+}
+
+func (s *packetSender) send(packet replay.Packet) error {
+	port := s.port
+	if port == 0 {
+		var ok bool
+		if port, ok = replay.ListenerPort(s.listeners, packet.FlowType); !ok {
+			// no listener is configured for the flow type, the packet is sent to its captured port
+			port = packet.DstPort
+		}
+	}
+
+	conn, ok := s.conns[port]
+	if !ok {
+		var err error
+		dialer := net.Dialer{}
+		if s.source != nil {
+			dialer.LocalAddr = &net.UDPAddr{IP: s.source}
+		}
+		if conn, err = dialer.Dial("udp", net.JoinHostPort(s.host, fmt.Sprint(port))); err != nil {
+			return err
+		}
+		s.conns[port] = conn
+	}
+	if _, err := conn.Write(packet.Payload); err != nil {
+		return err
+	}
+	s.sent[packet.FlowType]++
+	return nil
+}
+
+func (s *packetSender) close() {
+	for _, conn := range s.conns {
+		conn.Close()
+	}
+}
+
+// summary returns the number of packets sent per flow type
+func (s *packetSender) summary() string {
+	flowTypes := make([]string, 0, len(s.sent))
+	for flowType := range s.sent {
+		flowTypes = append(flowTypes, string(flowType))
+	}
+	sort.Strings(flowTypes)
+
+	var b strings.Builder
+	for _, flowType := range flowTypes {
+		fmt.Fprintf(&b, "Sent %d %s packets\n", s.sent[common.FlowType(flowType)], flowType)
+	}
+	return b.String()
+}
+
+// formatExportersStatus renders the status of the exporters as a table
+func formatExportersStatus(exporters []flowaggregator.ExporterStatus, now time.Time) string {
+	var b strings.Builder
+	b.WriteString("NetFlow Exporters\n=================\n\n")
+	if len(exporters) == 0 {
+		b.WriteString("No exporter seen yet.\n")
+		return b.String()
+	}
+
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "EXPORTER\tNAMESPACE\tFLOW TYPE\tDOMAIN\tLAST PACKET\tFLOWS\tTEMPLATES\tSEQUENCE")
+	for _, exporter := range exporters {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%d\t%s\t%s\n",
+			exporter.IPAddress,
+			exporter.Namespace,
+			exporter.FlowType,
+			exporter.ObservationDomain,
+			formatLastPacket(exporter.LastPacket, now),
+			exporter.Flows,
+			formatTemplateStatus(exporter.Template),
+			formatSequenceStatus(exporter.Sequence),
+		)
+	}
+	w.Flush()
+	return b.String()
+}
+
+func formatLastPacket(lastPacket time.Time, now time.Time) string {
+	if lastPacket.IsZero() {
+		return "never"
+	}
+	return fmt.Sprintf("%s ago", now.Sub(lastPacket).Truncate(time.Second))
+}
+
+func formatTemplateStatus(status flowaggregator.TemplateStatus) string {
+	switch status.State {
+	case flowaggregator.TemplateStateNotApplicable:
+		return "n/a"
+	case flowaggregator.TemplateStateComplete:
+		return fmt.Sprintf("complete (%d)", status.Templates)
+	default:
+		return fmt.Sprintf("%s (%d, %d packets dropped)", status.State, status.Templates, status.MissingTemplateErrors)
+	}
+}
+
+func formatSequenceStatus(status flowaggregator.SequenceStatus) string {
+	if status.Gaps == 0 && status.Resets == 0 {
+		return "ok"
+	}
+	return fmt.Sprintf("%d gaps (%d missed), %d resets", status.Gaps, status.Missed, status.Resets)
+}
@@ -0,0 +1,147 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package netflow
+
+import (
+	"net"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/cmd/agent/command"
+	"github.com/DataDog/datadog-agent/pkg/netflow/common"
+	nfconfig "github.com/DataDog/datadog-agent/pkg/netflow/config"
+	"github.com/DataDog/datadog-agent/pkg/netflow/flowaggregator"
+	"github.com/DataDog/datadog-agent/pkg/netflow/replay"
+	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
+)
+
+func TestCommand(t *testing.T) {
+	fxutil.TestOneShotSubcommand(t,
+		Commands(&command.GlobalParams{}),
+		[]string{"netflow", "status", "--json"},
+		requestNetflowStatus,
+		func(cliParams *cliParams) {
+			require.True(t, cliParams.jsonStatus)
+			require.False(t, cliParams.prettyPrintJSON)
+		})
+}
+
+func TestReplayCommand(t *testing.T) {
+	fxutil.TestOneShotSubcommand(t,
+		Commands(&command.GlobalParams{}),
+		[]string{"netflow", "replay", "--file", "flows.pcap", "--speed", "2", "--loops", "3", "--port", "9995", "--source", "127.0.0.2"},
+		replayNetflow,
+		func(cliParams *cliParams) {
+			require.Equal(t, "flows.pcap", cliParams.replayFile)
+			require.Equal(t, 2.0, cliParams.replaySpeed)
+			require.Equal(t, 3, cliParams.replayLoops)
+			require.Equal(t, "127.0.0.1", cliParams.replayHost)
+			require.Equal(t, uint16(9995), cliParams.replayPort)
+			require.Equal(t, "127.0.0.2", cliParams.replaySource)
+		})
+}
+
+func TestPacketSender(t *testing.T) {
+	listener, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	port := uint16(listener.LocalAddr().(*net.UDPAddr).Port)
+
+	sender := &packetSender{
+		host:      "127.0.0.1",
+		listeners: []nfconfig.ListenerConfig{{FlowType: common.TypeNetFlow9, Port: port}},
+		conns:     make(map[uint16]net.Conn),
+		sent:      make(map[common.FlowType]int),
+	}
+	defer sender.close()
+
+	// the IPFIX packets are sent to the NetFlow v9 listener, the sFlow ones to their captured port
+	require.NoError(t, sender.send(replay.Packet{FlowType: common.TypeNetFlow9, Payload: []byte("nf9")}))
+	require.NoError(t, sender.send(replay.Packet{FlowType: common.TypeIPFIX, Payload: []byte("ipfix")}))
+	require.NoError(t, sender.send(replay.Packet{FlowType: common.TypeSFlow5, DstPort: port, Payload: []byte("sflow")}))
+
+	buf := make([]byte, 64)
+	for _, expected := range []string{"nf9", "ipfix", "sflow"} {
+		require.NoError(t, listener.SetReadDeadline(time.Now().Add(5*time.Second)))
+		n, _, err := listener.ReadFrom(buf)
+		require.NoError(t, err)
+		assert.Equal(t, expected, string(buf[:n]))
+	}
+
+	assert.Equal(t, "Sent 1 ipfix packets\nSent 1 netflow9 packets\nSent 1 sflow5 packets\n", sender.summary())
+}
+
+func TestFormatExportersStatus(t *testing.T) {
+	now := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+	exporters := []flowaggregator.ExporterStatus{
+		{
+			IPAddress:         "10.0.0.1",
+			Namespace:         "default",
+			FlowType:          common.TypeNetFlow9,
+			ObservationDomain: 256,
+			LastPacket:        now.Add(-5 * time.Second),
+			Flows:             120,
+			Template:          flowaggregator.TemplateStatus{State: flowaggregator.TemplateStateComplete, Templates: 3},
+		},
+		{
+			IPAddress:  "10.0.0.2",
+			Namespace:  "default",
+			FlowType:   common.TypeSFlow5,
+			LastPacket: now.Add(-time.Minute),
+			Flows:      42,
+			Template:   flowaggregator.TemplateStatus{State: flowaggregator.TemplateStateNotApplicable},
+			Sequence:   flowaggregator.SequenceStatus{Gaps: 2, Missed: 7, Resets: 1},
+		},
+		{
+			IPAddress: "10.0.0.3",
+			FlowType:  common.TypeUnknown,
+			Template:  flowaggregator.TemplateStatus{State: flowaggregator.TemplateStateMissing, MissingTemplateErrors: 12},
+		},
+	}
+
+	assert.Equal(t, `NetFlow Exporters
+=================
+
+EXPORTER  NAMESPACE  FLOW TYPE  DOMAIN  LAST PACKET  FLOWS  TEMPLATES                        SEQUENCE
+10.0.0.1  default    netflow9   256     5s ago       120    complete (3)                     ok
+10.0.0.2  default    sflow5     0       1m0s ago     42     n/a                              2 gaps (7 missed), 1 resets
+10.0.0.3             unknown    0       never        0      missing (0, 12 packets dropped)  ok
+`, formatExportersStatus(exporters, now))
+
+	assert.Equal(t, "NetFlow Exporters\n=================\n\nNo exporter seen yet.\n", formatExportersStatus(nil, now))
+}
+
+func TestPacketSenderSource(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("only Linux routes the whole 127.0.0.0/8 range to the loopback interface")
+	}
+
+	listener, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	sender := &packetSender{
+		host:   "127.0.0.1",
+		port:   uint16(listener.LocalAddr().(*net.UDPAddr).Port),
+		source: net.ParseIP("127.0.0.2"),
+		conns:  make(map[uint16]net.Conn),
+		sent:   make(map[common.FlowType]int),
+	}
+	defer sender.close()
+
+	require.NoError(t, sender.send(replay.Packet{FlowType: common.TypeNetFlow5, Payload: []byte("nf5")}))
+
+	buf := make([]byte, 64)
+	require.NoError(t, listener.SetReadDeadline(time.Now().Add(5*time.Second)))
+	n, addr, err := listener.ReadFrom(buf)
+	require.NoError(t, err)
+	assert.Equal(t, "nf5", string(buf[:n]))
+	assert.Equal(t, "127.0.0.2", addr.(*net.UDPAddr).IP.String())
+}
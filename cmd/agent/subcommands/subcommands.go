@@ -22,6 +22,7 @@ import (
 	cmdintegrations "github.com/DataDog/datadog-agent/cmd/agent/subcommands/integrations"
 	cmdjmx "github.com/DataDog/datadog-agent/cmd/agent/subcommands/jmx"
 	cmdlaunchgui "github.com/DataDog/datadog-agent/cmd/agent/subcommands/launchgui"
+	cmdnetflow "github.com/DataDog/datadog-agent/cmd/agent/subcommands/netflow"
 	cmdremoteconfig "github.com/DataDog/datadog-agent/cmd/agent/subcommands/remoteconfig"
 	cmdrun "github.com/DataDog/datadog-agent/cmd/agent/subcommands/run"
 	cmdsecret "github.com/DataDog/datadog-agent/cmd/agent/subcommands/secret"
@@ -55,6 +56,7 @@ func AgentSubcommands() []command.SubcommandFactory {
 		cmdrun.Commands,
 		cmdsecret.Commands,
 		cmdsnmp.Commands,
+		cmdnetflow.Commands,
 		cmdstatus.Commands,
 		cmdstreamlogs.Commands,
 		cmdtaggerlist.Commands,
@@ -0,0 +1,51 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/DataDog/datadog-agent/pkg/process/checks"
+	"github.com/DataDog/datadog-agent/pkg/process/procutil"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// processDetailsHandler returns the extended data of a single process, collected on demand by the process check
+func processDetailsHandler(w http.ResponseWriter, req *http.Request) {
+	pid, err := strconv.ParseInt(mux.Vars(req)["pid"], 10, 32)
+	if err != nil || pid <= 0 {
+		setJSONError(w, fmt.Errorf("invalid pid %q", mux.Vars(req)["pid"]), http.StatusBadRequest)
+		return
+	}
+
+	details, err := checks.InspectProcess(int32(pid))
+	switch {
+	case errors.Is(err, procutil.ErrInspectionRateLimited):
+		setJSONError(w, err, http.StatusTooManyRequests)
+		return
+	case errors.Is(err, checks.ErrProcessCheckNotRunning):
+		setJSONError(w, err, http.StatusServiceUnavailable)
+		return
+	case errors.Is(err, os.ErrNotExist):
+		setJSONError(w, err, http.StatusNotFound)
+		return
+	case err != nil:
+		setJSONError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(details); err != nil {
+		_ = log.Errorf("could not write the details of process %d: %s", pid, err)
+	}
+}
@@ -40,4 +40,5 @@ func SetupAPIServerHandlers(deps APIServerDeps, r *mux.Router) {
 	r.HandleFunc("/agent/workload-list/short", getShortWorkloadList).Methods("GET")
 	r.HandleFunc("/agent/workload-list/verbose", getVerboseWorkloadList).Methods("GET")
 	r.HandleFunc("/check/{check}", checkHandler).Methods("GET")
+	r.HandleFunc("/process/{pid}/details", processDetailsHandler).Methods("GET")
 }
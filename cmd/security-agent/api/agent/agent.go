@@ -10,6 +10,8 @@ package agent
 
 import (
 	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
 
 	"github.com/gorilla/mux"
@@ -49,6 +51,7 @@ func (a *Agent) SetupHandlers(r *mux.Router) {
 	r.HandleFunc("/stop", a.stopAgent).Methods("POST")
 	r.HandleFunc("/status", a.getStatus).Methods("GET")
 	r.HandleFunc("/status/health", a.getHealth).Methods("GET")
+	r.HandleFunc("/runtime/rules/simulate", a.simulateRules).Methods("POST")
 	r.HandleFunc("/config", settingshttp.Server.GetFullDatadogConfig("")).Methods("GET")
 	r.HandleFunc("/config/list-runtime", settingshttp.Server.ListConfigurable).Methods("GET")
 	r.HandleFunc("/config/{setting}", settingshttp.Server.GetValue).Methods("GET")
@@ -130,6 +133,43 @@ func (a *Agent) getHealth(w http.ResponseWriter, r *http.Request) {
 	w.Write(jsonHealth)
 }
 
+// maxSimulatedEventSize is the maximum size of the body of the rule simulation requests
+const maxSimulatedEventSize = 1 << 20
+
+// simulateRules evaluates the event of the request body, in the format of the `runtime policy eval` command, against
+// the rules loaded by the system probe, and reports the rules which would match, along with their sub-expressions
+func (a *Agent) simulateRules(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if a.runtimeAgent == nil {
+		body, _ := json.Marshal(map[string]string{"error": "runtime security is disabled"})
+		http.Error(w, string(body), http.StatusServiceUnavailable)
+		return
+	}
+
+	event, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxSimulatedEventSize))
+	if err != nil {
+		body, _ := json.Marshal(map[string]string{"error": fmt.Sprintf("invalid event: %s", err)})
+		http.Error(w, string(body), http.StatusBadRequest)
+		return
+	}
+
+	response, err := a.runtimeAgent.SimulateRules(r.Context(), event)
+	if err != nil {
+		log.Errorf("Error simulating the rules: %v", err)
+		body, _ := json.Marshal(map[string]string{"error": err.Error()})
+		http.Error(w, string(body), http.StatusInternalServerError)
+		return
+	}
+	if response.Error != "" {
+		body, _ := json.Marshal(map[string]string{"error": response.Error})
+		http.Error(w, string(body), http.StatusBadRequest)
+		return
+	}
+
+	w.Write(response.Report)
+}
+
 func (a *Agent) makeFlare(w http.ResponseWriter, r *http.Request) {
 	log.Infof("Making a flare")
 	w.Header().Set("Content-Type", "application/json")
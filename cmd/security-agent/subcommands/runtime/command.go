@@ -503,12 +503,6 @@ type EvalReport struct {
 	Error     error `json:",omitempty"`
 }
 
-// EventData defines the structure used to represent an event
-type EventData struct {
-	Type   eval.EventType
-	Values map[string]interface{}
-}
-
 func eventDataFromJSON(file string) (eval.Event, error) {
 	f, err := os.Open(file)
 	if err != nil {
@@ -516,41 +510,7 @@ func eventDataFromJSON(file string) (eval.Event, error) {
 	}
 	defer f.Close()
 
-	decoder := json.NewDecoder(f)
-	decoder.UseNumber()
-
-	var eventData EventData
-	if err := decoder.Decode(&eventData); err != nil {
-		return nil, err
-	}
-
-	kind := model.ParseEvalEventType(eventData.Type)
-	if kind == model.UnknownEventType {
-		return nil, errors.New("unknown event type")
-	}
-
-	m := &model.Model{}
-	event := m.NewDefaultEventWithType(kind)
-	event.Init()
-
-	for k, v := range eventData.Values {
-		switch v := v.(type) {
-		case json.Number:
-			value, err := v.Int64()
-			if err != nil {
-				return nil, err
-			}
-			if err := event.SetFieldValue(k, int(value)); err != nil {
-				return nil, err
-			}
-		default:
-			if err := event.SetFieldValue(k, v); err != nil {
-				return nil, err
-			}
-		}
-	}
-
-	return event, nil
+	return rules.NewEventFromJSON(f)
 }
 
 func evalRule(log log.Component, config config.Component, evalArgs *evalCliParams) error {
@@ -46,21 +46,26 @@ func Run(cloudService cloudservice.CloudService, logConfig *serverlessLog.Config
 func execute(cloudService cloudservice.CloudService, config *serverlessLog.Config, metricAgent *metrics.ServerlessMetricAgent, traceAgent *trace.ServerlessTraceAgent, args []string) error {
 	commandName, commandArgs := buildCommandParam(args)
 	cmd := exec.Command(commandName, commandArgs...)
-	cmd.Stdout = &serverlessLog.CustomWriter{
+	stdout := &serverlessLog.CustomWriter{
 		LogConfig:  config,
 		LineBuffer: bytes.Buffer{},
 	}
-	cmd.Stderr = &serverlessLog.CustomWriter{
+	stderr := &serverlessLog.CustomWriter{
 		LogConfig:  config,
 		LineBuffer: bytes.Buffer{},
 		IsError:    true,
 	}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
 	err := cmd.Start()
 	if err != nil {
 		return err
 	}
 	handleSignals(cloudService, cmd.Process, config, metricAgent, traceAgent)
 	err = cmd.Wait()
+	// send the last multi-line log entries of the process
+	stdout.Flush()
+	stderr.Flush()
 	flush(config.FlushTimeout, metricAgent, traceAgent)
 	return err
 }
@@ -10,6 +10,7 @@ import (
 	"bytes"
 	"fmt"
 	"os"
+	"regexp"
 	"strings"
 	"time"
 
@@ -26,6 +27,7 @@ const (
 	logLevelEnvVar      = "DD_LOG_LEVEL"
 	logEnabledEnvVar    = "DD_LOGS_ENABLED"
 	sourceEnvVar        = "DD_SOURCE"
+	multiLineEnvVar     = "DD_LOGS_MULTI_LINE_PATTERN"
 	sourceName          = "Datadog Agent"
 )
 
@@ -36,6 +38,9 @@ type Config struct {
 	source       string
 	loggerName   config.LoggerName
 	isEnabled    bool
+	// multiLinePattern matches the first line of each log entry, nil when multi-line aggregation is disabled
+	multiLinePattern      *regexp.Regexp
+	multiLineFlushTimeout time.Duration
 }
 
 // CustomWriter wraps the log config to allow stdout/stderr redirection
@@ -43,6 +48,8 @@ type CustomWriter struct {
 	LogConfig  *Config
 	LineBuffer bytes.Buffer
 	IsError    bool
+
+	multiLine *multiLineAggregator
 }
 
 // CreateConfig builds and returns a log config
@@ -57,6 +64,9 @@ func CreateConfig(origin string) *Config {
 		source:       source,
 		loggerName:   loggerName,
 		isEnabled:    isEnabled(os.Getenv(logEnabledEnvVar)),
+
+		multiLinePattern:      multiLinePattern(os.Getenv(multiLineEnvVar)),
+		multiLineFlushTimeout: defaultMultiLineFlushTimeout,
 	}
 }
 
@@ -105,11 +115,43 @@ func (cw *CustomWriter) Write(p []byte) (n int, err error) {
 		if len(logLine) == 0 {
 			continue
 		}
+		if cw.LogConfig.multiLinePattern != nil {
+			cw.multiLineAggregator().process(logLine)
+			continue
+		}
 		Write(cw.LogConfig, logLine, cw.IsError)
 	}
 	return len(p), nil
 }
 
+// Flush sends the pending multi-line log entry, if any
+func (cw *CustomWriter) Flush() {
+	if cw.multiLine != nil {
+		cw.multiLine.flush()
+	}
+}
+
+func (cw *CustomWriter) multiLineAggregator() *multiLineAggregator {
+	if cw.multiLine == nil {
+		cw.multiLine = newMultiLineAggregator(cw.LogConfig.multiLinePattern, cw.LogConfig.multiLineFlushTimeout, func(content []byte) {
+			Write(cw.LogConfig, content, cw.IsError)
+		})
+	}
+	return cw.multiLine
+}
+
+func multiLinePattern(envValue string) *regexp.Regexp {
+	if envValue == "" {
+		return nil
+	}
+	pattern, err := regexp.Compile(envValue)
+	if err != nil {
+		log.Errorf("Invalid %s, multi-line aggregation is disabled: %s", multiLineEnvVar, err)
+		return nil
+	}
+	return pattern
+}
+
 func isEnabled(envValue string) bool {
 	return strings.ToLower(envValue) == "true"
 }
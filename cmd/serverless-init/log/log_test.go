@@ -142,6 +142,19 @@ func TestCreateConfigWithSource(t *testing.T) {
 	assert.Equal(t, "DD_LOG_AGENT", string(config.loggerName))
 }
 
+func TestCreateConfigWithMultiLinePattern(t *testing.T) {
+	t.Setenv("DD_LOGS_MULTI_LINE_PATTERN", `^\d{4}-\d{2}-\d{2}`)
+	config := CreateConfig("cloudrun")
+	assert.NotNil(t, config.multiLinePattern)
+	assert.True(t, config.multiLinePattern.MatchString("2023-01-01 log"))
+}
+
+func TestCreateConfigWithInvalidMultiLinePattern(t *testing.T) {
+	t.Setenv("DD_LOGS_MULTI_LINE_PATTERN", "(")
+	config := CreateConfig("cloudrun")
+	assert.Nil(t, config.multiLinePattern)
+}
+
 func TestIsEnabledTrue(t *testing.T) {
 	assert.True(t, isEnabled("True"))
 	assert.True(t, isEnabled("TRUE"))
@@ -0,0 +1,86 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package log
+
+import (
+	"bytes"
+	"regexp"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultMultiLineFlushTimeout is the time after which an entry is sent when no new line is written
+	defaultMultiLineFlushTimeout = time.Second
+	// maxMultiLineSize is the size after which an entry is sent even if it is not complete
+	maxMultiLineSize = 256 * 1000
+)
+
+// multiLineAggregator aggregates consecutive lines into a single log entry. A new entry
+// starts on each line matching the pattern, e.g. `^\d{4}-\d{2}-\d{2}` for logs starting
+// with a date, the other lines are appended to the current entry such as stack traces.
+type multiLineAggregator struct {
+	mu           sync.Mutex
+	pattern      *regexp.Regexp
+	flushTimeout time.Duration
+	send         func([]byte)
+	buffer       bytes.Buffer
+	timer        *time.Timer
+}
+
+func newMultiLineAggregator(pattern *regexp.Regexp, flushTimeout time.Duration, send func([]byte)) *multiLineAggregator {
+	return &multiLineAggregator{
+		pattern:      pattern,
+		flushTimeout: flushTimeout,
+		send:         send,
+	}
+}
+
+// process adds a line to the current entry, sending the previous entry if the line starts a new one.
+func (a *multiLineAggregator) process(line []byte) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.pattern.Match(line) {
+		a.flushLocked()
+	}
+	if a.buffer.Len() > 0 {
+		a.buffer.WriteByte('\n')
+	}
+	a.buffer.Write(line)
+
+	if a.buffer.Len() >= maxMultiLineSize {
+		a.flushLocked()
+		return
+	}
+
+	// send the entry if no line follows it in time
+	if a.timer == nil {
+		a.timer = time.AfterFunc(a.flushTimeout, a.flush)
+	} else {
+		a.timer.Reset(a.flushTimeout)
+	}
+}
+
+// flush sends the current entry.
+func (a *multiLineAggregator) flush() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.flushLocked()
+}
+
+func (a *multiLineAggregator) flushLocked() {
+	if a.timer != nil {
+		a.timer.Stop()
+	}
+	if a.buffer.Len() == 0 {
+		return
+	}
+	content := make([]byte, a.buffer.Len())
+	copy(content, a.buffer.Bytes())
+	a.buffer.Reset()
+	a.send(content)
+}
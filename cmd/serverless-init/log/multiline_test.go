@@ -0,0 +1,94 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package log
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+)
+
+func TestMultiLineAggregator(t *testing.T) {
+	var entries []string
+	aggregator := newMultiLineAggregator(regexp.MustCompile(`^\d{4}-\d{2}-\d{2}`), time.Hour, func(content []byte) {
+		entries = append(entries, string(content))
+	})
+
+	aggregator.process([]byte("2023-01-01 first"))
+	aggregator.process([]byte("2023-01-01 error"))
+	aggregator.process([]byte("Traceback (most recent call last):"))
+	aggregator.process([]byte(`  File "main.py", line 1`))
+	aggregator.process([]byte("2023-01-01 last"))
+	assert.Equal(t, []string{
+		"2023-01-01 first",
+		"2023-01-01 error\nTraceback (most recent call last):\n  File \"main.py\", line 1",
+	}, entries)
+
+	aggregator.flush()
+	assert.Equal(t, "2023-01-01 last", entries[2])
+
+	// nothing is sent when there is no pending entry
+	aggregator.flush()
+	assert.Len(t, entries, 3)
+}
+
+func TestMultiLineAggregatorMaxSize(t *testing.T) {
+	var entries []string
+	aggregator := newMultiLineAggregator(regexp.MustCompile(`^start`), time.Hour, func(content []byte) {
+		entries = append(entries, string(content))
+	})
+
+	aggregator.process([]byte("start"))
+	aggregator.process([]byte(strings.Repeat("a", maxMultiLineSize)))
+	require.Len(t, entries, 1)
+	assert.Len(t, entries[0], len("start\n")+maxMultiLineSize)
+}
+
+func TestMultiLineAggregatorFlushTimeout(t *testing.T) {
+	entries := make(chan string, 1)
+	aggregator := newMultiLineAggregator(regexp.MustCompile(`^start`), 10*time.Millisecond, func(content []byte) {
+		entries <- string(content)
+	})
+
+	aggregator.process([]byte("start"))
+	aggregator.process([]byte("continued"))
+	select {
+	case entry := <-entries:
+		assert.Equal(t, "start\ncontinued", entry)
+	case <-time.After(time.Second):
+		assert.Fail(t, "the pending entry should have been sent")
+	}
+}
+
+func TestCustomWriterMultiLine(t *testing.T) {
+	logConfig := &Config{
+		channel:               make(chan *config.ChannelMessage, 2),
+		isEnabled:             true,
+		multiLinePattern:      regexp.MustCompile(`^\[`),
+		multiLineFlushTimeout: time.Hour,
+	}
+	cw := &CustomWriter{
+		LogConfig:  logConfig,
+		LineBuffer: bytes.Buffer{},
+		IsError:    true,
+	}
+	cw.Write([]byte("[ERROR] panic\n\tat main.go:10\n\tat main.go:20\n[INFO] done\n"))
+	cw.Flush()
+
+	require.Len(t, logConfig.channel, 2)
+	message := <-logConfig.channel
+	assert.Equal(t, []byte("[ERROR] panic\n\tat main.go:10\n\tat main.go:20"), message.Content)
+	assert.True(t, message.IsError)
+	message = <-logConfig.channel
+	assert.Equal(t, []byte("[INFO] done"), message.Content)
+}
@@ -73,6 +73,7 @@ func setup() (cloudservice.CloudService, *log.Config, *trace.ServerlessTraceAgen
 func setupTraceAgent(traceAgent *trace.ServerlessTraceAgent, tags map[string]string) {
 	traceAgent.Start(config.Datadog.GetBool("apm_config.enabled"), &trace.LoadConfig{Path: datadogConfigPath}, nil, random.Random.Uint64())
 	traceAgent.SetTags(tag.GetBaseTagsMapWithMetadata(tags))
+	traceAgent.SetSpanTags(tag.GetSpanTags())
 	for range time.Tick(3 * time.Second) {
 		traceAgent.Flush()
 	}
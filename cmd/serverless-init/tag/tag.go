@@ -9,6 +9,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/serverless/tags"
 )
 
@@ -63,3 +64,10 @@ func GetBaseTagsArrayWithMetadataTags(metadata map[string]string) []string {
 	tagsMap := GetBaseTagsMapWithMetadata(metadata)
 	return tags.MapToArray(tagsMap)
 }
+
+// GetSpanTags returns the user-defined tags applied to every span, built from
+// DD_SPAN_TAGS and the DD_SPAN_TAGS_JSON JSON object. They're only read once,
+// when the trace agent starts, like the other trace tags.
+func GetSpanTags() map[string]string {
+	return tags.BuildSpanTags(config.Datadog.GetStringSlice("serverless.span_tags"), config.Datadog.GetString("serverless.span_tags_json"))
+}
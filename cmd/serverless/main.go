@@ -29,6 +29,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/serverless/proxy"
 	"github.com/DataDog/datadog-agent/pkg/serverless/random"
 	"github.com/DataDog/datadog-agent/pkg/serverless/registration"
+	"github.com/DataDog/datadog-agent/pkg/serverless/tags"
 	"github.com/DataDog/datadog-agent/pkg/serverless/trace"
 	"github.com/DataDog/datadog-agent/pkg/serverless/trace/inferredspan"
 	"github.com/DataDog/datadog-agent/pkg/trace/pb"
@@ -37,12 +38,14 @@ import (
 )
 
 var (
-	kmsAPIKeyEnvVar            = "DD_KMS_API_KEY"
-	secretsManagerAPIKeyEnvVar = "DD_API_KEY_SECRET_ARN"
-	apiKeyEnvVar               = "DD_API_KEY"
-	logLevelEnvVar             = "DD_LOG_LEVEL"
-	flushStrategyEnvVar        = "DD_SERVERLESS_FLUSH_STRATEGY"
-	logsLogsTypeSubscribed     = "DD_LOGS_CONFIG_LAMBDA_LOGS_TYPE"
+	kmsAPIKeyEnvVar              = "DD_KMS_API_KEY"
+	secretsManagerAPIKeyEnvVar   = "DD_API_KEY_SECRET_ARN"
+	apiKeyEnvVar                 = "DD_API_KEY"
+	logLevelEnvVar               = "DD_LOG_LEVEL"
+	flushStrategyEnvVar          = "DD_SERVERLESS_FLUSH_STRATEGY"
+	flushPeriodicThresholdEnvVar = "DD_SERVERLESS_FLUSH_PERIODIC_THRESHOLD_MS"
+	flushIntervalEnvVar          = "DD_SERVERLESS_FLUSH_INTERVAL_MS"
+	logsLogsTypeSubscribed       = "DD_LOGS_CONFIG_LAMBDA_LOGS_TYPE"
 
 	// AWS Lambda is writing the Lambda function files in /var/task, we want the
 	// configuration file to be at the root of this directory.
@@ -195,6 +198,10 @@ func runAgent(stopCh chan struct{}) (serverlessDaemon *daemon.Daemon, err error)
 	} else {
 		serverlessDaemon.UseAdaptiveFlush(true) // already initialized to true, but let's be explicit just in case
 	}
+	serverlessDaemon.SetAdaptiveFlushSettings(daemon.AdaptiveFlushSettings{
+		PeriodicThreshold: durationFromMillisecondsEnv(flushPeriodicThresholdEnvVar),
+		FlushInterval:     durationFromMillisecondsEnv(flushIntervalEnvVar),
+	})
 
 	// validate that an apikey has been set, either by the env var, read from KMS or Secrets Manager.
 	// ---------------------------
@@ -228,6 +235,7 @@ func runAgent(stopCh chan struct{}) (serverlessDaemon *daemon.Daemon, err error)
 		defer wg.Done()
 		traceAgent := &trace.ServerlessTraceAgent{}
 		traceAgent.Start(config.Datadog.GetBool("apm_config.enabled"), &trace.LoadConfig{Path: datadogConfigPath}, lambdaSpanChan, coldStartSpanId)
+		traceAgent.SetSpanTags(tags.BuildSpanTags(config.Datadog.GetStringSlice("serverless.span_tags"), config.Datadog.GetString("serverless.span_tags_json")))
 		serverlessDaemon.SetTraceAgent(traceAgent)
 	}()
 
@@ -373,3 +381,18 @@ func handleSignals(serverlessDaemon *daemon.Daemon, stopCh chan struct{}) {
 		}
 	}
 }
+
+// durationFromMillisecondsEnv reads a duration expressed in milliseconds from the given
+// environment variable, returning 0 if it is unset or invalid.
+func durationFromMillisecondsEnv(envVar string) time.Duration {
+	v, exists := os.LookupEnv(envVar)
+	if !exists {
+		return 0
+	}
+	msecs, err := strconv.Atoi(v)
+	if err != nil || msecs <= 0 {
+		log.Debugf("Invalid value %s for %s, will use the default one instead", v, envVar)
+		return 0
+	}
+	return time.Duration(msecs) * time.Millisecond
+}
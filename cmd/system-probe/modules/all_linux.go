@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/DataDog/datadog-agent/cmd/system-probe/api/module"
+	"github.com/DataDog/datadog-agent/pkg/config/settings"
 )
 
 // All System Probe modules should register their factories here
@@ -24,6 +25,13 @@ var All = []module.Factory{
 	DynamicInstrumentation,
 }
 
+// RuntimeSettings holds the settings of the modules which can be changed at runtime
+var RuntimeSettings = []settings.RuntimeSetting{
+	usmProtocolRuntimeSetting{protocol: "http", configKey: "network_config.enable_http_monitoring"},
+	usmProtocolRuntimeSetting{protocol: "http2", configKey: "service_monitoring_config.enable_http2_monitoring"},
+	usmProtocolRuntimeSetting{protocol: "dns", configKey: "service_monitoring_config.enable_dns_monitoring"},
+}
+
 func inactivityEventLog(duration time.Duration) {
 
 }
@@ -8,7 +8,13 @@
 
 package modules
 
-import "github.com/DataDog/datadog-agent/cmd/system-probe/api/module"
+import (
+	"github.com/DataDog/datadog-agent/cmd/system-probe/api/module"
+	"github.com/DataDog/datadog-agent/pkg/config/settings"
+)
 
 // All System Probe modules should register their factories here
 var All = []module.Factory{}
+
+// RuntimeSettings holds the settings of the modules which can be changed at runtime
+var RuntimeSettings = []settings.RuntimeSetting{}
@@ -15,6 +15,7 @@ import (
 
 	"github.com/DataDog/datadog-agent/cmd/system-probe/api/module"
 	"github.com/DataDog/datadog-agent/cmd/system-probe/config"
+	"github.com/DataDog/datadog-agent/pkg/config/settings"
 )
 
 // All System Probe modules should register their factories here
@@ -23,6 +24,9 @@ var All = []module.Factory{
 	EventMonitor,
 }
 
+// RuntimeSettings holds the settings of the modules which can be changed at runtime
+var RuntimeSettings = []settings.RuntimeSetting{}
+
 const (
 	msgSysprobeRestartInactivity = 0x8000000f
 )
@@ -13,9 +13,12 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"net/netip"
 	"os"
 	"runtime"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"go.uber.org/atomic"
@@ -25,7 +28,9 @@ import (
 	"github.com/DataDog/datadog-agent/cmd/system-probe/utils"
 	"github.com/DataDog/datadog-agent/pkg/network"
 	networkconfig "github.com/DataDog/datadog-agent/pkg/network/config"
+	netdnsdebugging "github.com/DataDog/datadog-agent/pkg/network/dns/debugging"
 	"github.com/DataDog/datadog-agent/pkg/network/encoding"
+	dnsdebugging "github.com/DataDog/datadog-agent/pkg/network/protocols/dns/debugging"
 	httpdebugging "github.com/DataDog/datadog-agent/pkg/network/protocols/http/debugging"
 	kafkadebugging "github.com/DataDog/datadog-agent/pkg/network/protocols/kafka/debugging"
 	"github.com/DataDog/datadog-agent/pkg/network/protocols/telemetry"
@@ -40,6 +45,9 @@ var ErrSysprobeUnsupported = errors.New("system-probe unsupported")
 const inactivityLogDuration = 10 * time.Minute
 const inactivityRestartDuration = 20 * time.Minute
 
+// defaultProcessBandwidthLimit is the number of processes returned by /process_bandwidth when no limit is given
+const defaultProcessBandwidthLimit = 10
+
 // NetworkTracer is a factory for NPM's tracer
 var NetworkTracer = module.Factory{
 	Name:             config.NetworkTracerModule,
@@ -67,18 +75,54 @@ var NetworkTracer = module.Factory{
 		done := make(chan struct{})
 		if err == nil {
 			startTelemetryReporter(cfg, done)
+			setActiveTracer(t)
 		}
 
-		return &networkTracer{tracer: t, done: done}, err
+		return &networkTracer{
+			tracer:                t,
+			done:                  done,
+			connectionCorrelation: ncfg.EnableConnectionCorrelation,
+			serviceDependencies:   ncfg.EnableServiceDependencies,
+			rollupThreshold:       ncfg.ServiceDependenciesRollupThreshold,
+			usmTransactionsDebug:  ncfg.EnableInFlightTransactionsDebug,
+		}, err
 	},
 }
 
 var _ module.Module = &networkTracer{}
 
+// activeTracer is the tracer of the running network tracer module, used by the runtime settings
+var activeTracer struct {
+	sync.RWMutex
+	tracer *tracer.Tracer
+}
+
+func setActiveTracer(t *tracer.Tracer) {
+	activeTracer.Lock()
+	defer activeTracer.Unlock()
+	activeTracer.tracer = t
+}
+
+func getActiveTracer() *tracer.Tracer {
+	activeTracer.RLock()
+	defer activeTracer.RUnlock()
+	return activeTracer.tracer
+}
+
 type networkTracer struct {
 	tracer       *tracer.Tracer
 	done         chan struct{}
 	restartTimer *time.Timer
+
+	// connectionCorrelation enables the /correlation_id endpoint queried by the tracer libraries
+	connectionCorrelation bool
+	// serviceDependencies enables the /service_dependencies endpoint
+	serviceDependencies bool
+	// rollupThreshold is the number of connections above which the /connections payloads are rolled up,
+	// when serviceDependencies is enabled
+	rollupThreshold int
+	// usmTransactionsDebug enables the /debug/usm_transactions endpoint
+	usmTransactionsDebug bool
 }
 
 func (nt *networkTracer) GetStats() map[string]interface{} {
@@ -99,6 +143,9 @@ func (nt *networkTracer) Register(httpMux *module.Router) error {
 			w.WriteHeader(500)
 			return
 		}
+		if nt.serviceDependencies && nt.rollupThreshold > 0 && len(cs.Conns) > nt.rollupThreshold {
+			cs.Conns = network.RollUpConnections(cs.Conns)
+		}
 		contentType := req.Header.Get("Accept")
 		marshaler := encoding.GetMarshaler(contentType)
 		writeConnections(w, marshaler, cs)
@@ -122,6 +169,97 @@ func (nt *networkTracer) Register(httpMux *module.Router) error {
 		}
 	}))
 
+	// /process_bandwidth?client_id=<id>[&limit=<n>] returns the n processes which sent and received the
+	// most bytes since the last request of the client, without the full connections payload
+	httpMux.HandleFunc("/process_bandwidth", utils.WithConcurrencyLimit(utils.DefaultMaxConcurrentRequests, func(w http.ResponseWriter, req *http.Request) {
+		limit, err := parseProcessBandwidthLimit(req)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		id := getClientID(req)
+		cs, err := nt.tracer.GetActiveConnections(id)
+		if err != nil {
+			log.Errorf("unable to retrieve connections: %s", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		defer network.Reclaim(cs)
+
+		utils.WriteAsJSON(w, network.TopProcessesByBandwidth(cs.Conns, limit))
+	}))
+
+	if nt.connectionCorrelation {
+		// /correlation_id?laddr=<ip:port>&raddr=<ip:port>[&pid=<pid>] returns the correlation ID
+		// of the connection, which the tracer libraries add to the spans sent over it
+		httpMux.HandleFunc("/correlation_id", utils.WithConcurrencyLimit(utils.DefaultMaxConcurrentRequests, func(w http.ResponseWriter, req *http.Request) {
+			pid, laddr, raddr, err := parseCorrelationIDRequest(req)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			id, err := nt.tracer.GetCorrelationID(pid, laddr, raddr)
+			if errors.Is(err, network.ErrConnectionNotFound) {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			if err != nil {
+				log.Errorf("unable to retrieve connection correlation ID: %s", err)
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+
+			utils.WriteAsJSON(w, map[string]string{"correlation_id": id})
+		}))
+	}
+
+	if nt.serviceDependencies {
+		// /service_dependencies?client_id=<id> returns the dependencies between the services of the host since the
+		// last request of the client. It's also served on /debug/service_dependencies for the system-probe debug command.
+		serviceDependenciesHandler := utils.WithConcurrencyLimit(utils.DefaultMaxConcurrentRequests, func(w http.ResponseWriter, req *http.Request) {
+			id := getClientID(req)
+			cs, err := nt.tracer.GetActiveConnections(id)
+			if err != nil {
+				log.Errorf("unable to retrieve connections: %s", err)
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			defer network.Reclaim(cs)
+
+			utils.WriteAsJSON(w, network.ServiceDependencies(cs.Conns))
+		})
+		httpMux.HandleFunc("/service_dependencies", serviceDependenciesHandler)
+		httpMux.HandleFunc("/debug/service_dependencies", serviceDependenciesHandler)
+	}
+
+	// GET /usm/protocols returns whether the monitoring of each USM protocol is enabled, and
+	// POST /usm/protocols?protocol=<protocol>&enabled=<bool> enables or disables it without restarting system-probe
+	httpMux.HandleFunc("/usm/protocols", func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodPost {
+			protocol, enabled, err := parseUSMProtocolRequest(req)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if err := nt.tracer.SetUSMProtocolEnabled(protocol, enabled); err != nil {
+				log.Errorf("unable to toggle the monitoring of %s: %s", protocol, err)
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			log.Infof("monitoring of %s set to enabled=%t", protocol, enabled)
+		}
+
+		protocols, err := nt.tracer.GetUSMProtocols()
+		if err != nil {
+			log.Errorf("unable to retrieve the USM protocols: %s", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		utils.WriteAsJSON(w, protocols)
+	})
+
 	httpMux.HandleFunc("/debug/net_maps", func(w http.ResponseWriter, req *http.Request) {
 		cs, err := nt.tracer.DebugNetworkMaps()
 		if err != nil {
@@ -170,6 +308,29 @@ func (nt *networkTracer) Register(httpMux *module.Router) error {
 		utils.WriteAsJSON(w, kafkadebugging.Kafka(cs.Kafka))
 	})
 
+	httpMux.HandleFunc("/debug/dns_monitoring", func(w http.ResponseWriter, req *http.Request) {
+		id := getClientID(req)
+		cs, err := nt.tracer.GetActiveConnections(id)
+		if err != nil {
+			log.Errorf("unable to retrieve connections: %s", err)
+			w.WriteHeader(500)
+			return
+		}
+
+		utils.WriteAsJSON(w, dnsdebugging.DNS(cs.USMDNS))
+	})
+
+	httpMux.HandleFunc("/debug/dns_servers", func(w http.ResponseWriter, req *http.Request) {
+		stats, err := nt.tracer.GetDNSServerStats()
+		if err != nil {
+			log.Errorf("unable to retrieve the dns server stats: %s", err)
+			w.WriteHeader(500)
+			return
+		}
+
+		utils.WriteAsJSON(w, netdnsdebugging.Servers(stats))
+	})
+
 	httpMux.HandleFunc("/debug/http2_monitoring", func(w http.ResponseWriter, req *http.Request) {
 		id := getClientID(req)
 		cs, err := nt.tracer.GetActiveConnections(id)
@@ -182,6 +343,21 @@ func (nt *networkTracer) Register(httpMux *module.Router) error {
 		utils.WriteAsJSON(w, httpdebugging.HTTP(cs.HTTP2, cs.DNS))
 	})
 
+	if nt.usmTransactionsDebug {
+		// /debug/usm_transactions returns the HTTP and HTTP/2 transactions held by eBPF, either waiting for their
+		// response or not flushed to userspace yet, to diagnose the requests missing from the stats
+		httpMux.HandleFunc("/debug/usm_transactions", func(w http.ResponseWriter, req *http.Request) {
+			txs, err := nt.tracer.DebugUSMInFlightTransactions()
+			if err != nil {
+				log.Errorf("unable to retrieve the in-flight transactions: %s", err)
+				w.WriteHeader(500)
+				return
+			}
+
+			utils.WriteAsJSON(w, txs)
+		})
+	}
+
 	// /debug/ebpf_maps as default will dump all registered maps/perfmaps
 	// an optional ?maps= argument could be pass with a list of map name : ?maps=map1,map2,map3
 	httpMux.HandleFunc("/debug/ebpf_maps", func(w http.ResponseWriter, req *http.Request) {
@@ -267,6 +443,7 @@ func (nt *networkTracer) Register(httpMux *module.Router) error {
 // Close will stop all system probe activities
 func (nt *networkTracer) Close() {
 	close(nt.done)
+	setActiveTracer(nil)
 	nt.tracer.Stop()
 }
 
@@ -281,6 +458,50 @@ func logRequests(client string, count uint64, connectionsCount int, start time.T
 	}
 }
 
+func parseCorrelationIDRequest(req *http.Request) (pid uint32, laddr, raddr netip.AddrPort, err error) {
+	query := req.URL.Query()
+	if laddr, err = netip.ParseAddrPort(query.Get("laddr")); err != nil {
+		return 0, laddr, raddr, fmt.Errorf("invalid laddr: %w", err)
+	}
+	if raddr, err = netip.ParseAddrPort(query.Get("raddr")); err != nil {
+		return 0, laddr, raddr, fmt.Errorf("invalid raddr: %w", err)
+	}
+	if rawPID := query.Get("pid"); rawPID != "" {
+		p, err := strconv.ParseUint(rawPID, 10, 32)
+		if err != nil {
+			return 0, laddr, raddr, fmt.Errorf("invalid pid: %w", err)
+		}
+		pid = uint32(p)
+	}
+	return pid, laddr, raddr, nil
+}
+
+func parseProcessBandwidthLimit(req *http.Request) (int, error) {
+	rawLimit := req.URL.Query().Get("limit")
+	if rawLimit == "" {
+		return defaultProcessBandwidthLimit, nil
+	}
+	limit, err := strconv.Atoi(rawLimit)
+	if err != nil || limit <= 0 {
+		return 0, fmt.Errorf("invalid limit: %q", rawLimit)
+	}
+	return limit, nil
+}
+
+func parseUSMProtocolRequest(req *http.Request) (string, bool, error) {
+	query := req.URL.Query()
+	protocol := query.Get("protocol")
+	if protocol == "" {
+		return "", false, errors.New("missing protocol")
+	}
+	rawEnabled := query.Get("enabled")
+	enabled, err := strconv.ParseBool(rawEnabled)
+	if err != nil {
+		return "", false, fmt.Errorf("invalid enabled: %q", rawEnabled)
+	}
+	return protocol, enabled, nil
+}
+
 func getClientID(req *http.Request) string {
 	var clientID = network.DEBUGCLIENT
 	if rawCID := req.URL.Query().Get("client_id"); rawCID != "" {
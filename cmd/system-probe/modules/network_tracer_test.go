@@ -10,6 +10,7 @@ package modules
 
 import (
 	"net/http/httptest"
+	"net/netip"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -70,3 +71,62 @@ func TestDecode(t *testing.T) {
 	assert.Equal(t, expected, out)
 
 }
+
+func TestParseCorrelationIDRequest(t *testing.T) {
+	req := httptest.NewRequest("GET", "/network_tracer/correlation_id?laddr=10.1.1.1:35000&raddr=[2001:db8::1]:443&pid=42", nil)
+	pid, laddr, raddr, err := parseCorrelationIDRequest(req)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(42), pid)
+	assert.Equal(t, netip.MustParseAddrPort("10.1.1.1:35000"), laddr)
+	assert.Equal(t, netip.MustParseAddrPort("[2001:db8::1]:443"), raddr)
+
+	req = httptest.NewRequest("GET", "/network_tracer/correlation_id?laddr=10.1.1.1:35000&raddr=10.2.2.2:80", nil)
+	pid, _, _, err = parseCorrelationIDRequest(req)
+	require.NoError(t, err)
+	assert.Zero(t, pid)
+
+	for _, query := range []string{
+		"raddr=10.2.2.2:80",
+		"laddr=10.1.1.1&raddr=10.2.2.2:80",
+		"laddr=10.1.1.1:35000&raddr=10.2.2.2:80&pid=abc",
+	} {
+		_, _, _, err = parseCorrelationIDRequest(httptest.NewRequest("GET", "/network_tracer/correlation_id?"+query, nil))
+		assert.Error(t, err, query)
+	}
+}
+
+func TestParseProcessBandwidthLimit(t *testing.T) {
+	limit, err := parseProcessBandwidthLimit(httptest.NewRequest("GET", "/network_tracer/process_bandwidth?client_id=1", nil))
+	require.NoError(t, err)
+	assert.Equal(t, defaultProcessBandwidthLimit, limit)
+
+	limit, err = parseProcessBandwidthLimit(httptest.NewRequest("GET", "/network_tracer/process_bandwidth?limit=25", nil))
+	require.NoError(t, err)
+	assert.Equal(t, 25, limit)
+
+	for _, rawLimit := range []string{"abc", "0", "-1"} {
+		_, err = parseProcessBandwidthLimit(httptest.NewRequest("GET", "/network_tracer/process_bandwidth?limit="+rawLimit, nil))
+		assert.Error(t, err, rawLimit)
+	}
+}
+
+func TestParseUSMProtocolRequest(t *testing.T) {
+	protocol, enabled, err := parseUSMProtocolRequest(httptest.NewRequest("POST", "/network_tracer/usm/protocols?protocol=http2&enabled=false", nil))
+	require.NoError(t, err)
+	assert.Equal(t, "http2", protocol)
+	assert.False(t, enabled)
+
+	protocol, enabled, err = parseUSMProtocolRequest(httptest.NewRequest("POST", "/network_tracer/usm/protocols?protocol=kafka&enabled=true", nil))
+	require.NoError(t, err)
+	assert.Equal(t, "kafka", protocol)
+	assert.True(t, enabled)
+
+	for _, query := range []string{
+		"enabled=true",
+		"protocol=http",
+		"protocol=http&enabled=maybe",
+	} {
+		_, _, err = parseUSMProtocolRequest(httptest.NewRequest("POST", "/network_tracer/usm/protocols?"+query, nil))
+		assert.Error(t, err, query)
+	}
+}
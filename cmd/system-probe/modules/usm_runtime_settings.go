@@ -0,0 +1,74 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package modules
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/config/settings"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// errNetworkTracerNotRunning is returned when a USM protocol is toggled while the network tracer module isn't running
+var errNetworkTracerNotRunning = errors.New("the network tracer module is not running")
+
+// usmProtocolRuntimeSetting enables or disables the monitoring of a USM protocol at runtime, e.g. with
+// `system-probe config set service_monitoring_config.enable_http2_monitoring false`.
+// Only the protocols enabled at startup can be toggled.
+type usmProtocolRuntimeSetting struct {
+	protocol  string
+	configKey string
+}
+
+// Description returns the runtime setting's description
+func (s usmProtocolRuntimeSetting) Description() string {
+	return fmt.Sprintf("Enable or disable the monitoring of %s by USM, if it was enabled at startup.", s.protocol)
+}
+
+// Hidden returns whether or not this setting is hidden from the list of runtime settings
+func (s usmProtocolRuntimeSetting) Hidden() bool {
+	return false
+}
+
+// Name returns the name of the runtime setting
+func (s usmProtocolRuntimeSetting) Name() string {
+	return s.configKey
+}
+
+// Get returns the current value of the runtime setting
+func (s usmProtocolRuntimeSetting) Get() (interface{}, error) {
+	if t := getActiveTracer(); t != nil {
+		if protocols, err := t.GetUSMProtocols(); err == nil {
+			return protocols[s.protocol], nil
+		}
+	}
+	return config.SystemProbe.GetBool(s.configKey), nil
+}
+
+// Set changes the value of the runtime setting
+func (s usmProtocolRuntimeSetting) Set(v interface{}) error {
+	enabled, err := settings.GetBool(v)
+	if err != nil {
+		return fmt.Errorf("%s: %w", s.configKey, err)
+	}
+
+	t := getActiveTracer()
+	if t == nil {
+		return errNetworkTracerNotRunning
+	}
+	if err := t.SetUSMProtocolEnabled(s.protocol, enabled); err != nil {
+		return err
+	}
+
+	config.SystemProbe.Set(s.configKey, enabled)
+	log.Infof("monitoring of %s set to enabled=%t", s.protocol, enabled)
+	return nil
+}
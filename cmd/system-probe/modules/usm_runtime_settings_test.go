@@ -0,0 +1,33 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package modules
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+func TestUSMProtocolRuntimeSettingWithoutTracer(t *testing.T) {
+	setting := usmProtocolRuntimeSetting{protocol: "http2", configKey: "service_monitoring_config.enable_http2_monitoring"}
+	assert.Equal(t, "service_monitoring_config.enable_http2_monitoring", setting.Name())
+
+	config.SystemProbe.Set(setting.configKey, true)
+	t.Cleanup(func() { config.SystemProbe.Set(setting.configKey, false) })
+
+	value, err := setting.Get()
+	require.NoError(t, err)
+	assert.Equal(t, true, value)
+
+	assert.ErrorIs(t, setting.Set("false"), errNetworkTracerNotRunning)
+	assert.Error(t, setting.Set("maybe"))
+}
@@ -7,6 +7,7 @@ package run
 
 import (
 	"github.com/DataDog/datadog-agent/cmd/system-probe/config"
+	"github.com/DataDog/datadog-agent/cmd/system-probe/modules"
 	pkgconfig "github.com/DataDog/datadog-agent/pkg/config"
 	commonsettings "github.com/DataDog/datadog-agent/pkg/config/settings"
 )
@@ -34,5 +35,10 @@ func initRuntimeSettings() error {
 	if err := commonsettings.RegisterRuntimeSetting(commonsettings.ActivityDumpRuntimeSetting{ConfigKey: commonsettings.MaxDumpSizeConfKey}); err != nil {
 		return err
 	}
+	for _, setting := range modules.RuntimeSettings {
+		if err := commonsettings.RegisterRuntimeSetting(setting); err != nil {
+			return err
+		}
+	}
 	return nil
 }
@@ -197,6 +197,21 @@ func applyDatadogConfig(c *config.AgentConfig) error {
 	}
 	c.PeerServiceAggregation = coreconfig.Datadog.GetBool("apm_config.peer_service_aggregation")
 	c.ComputeStatsBySpanKind = coreconfig.Datadog.GetBool("apm_config.compute_stats_by_span_kind")
+	c.SupplementClientStats = coreconfig.Datadog.GetBool("apm_config.supplement_client_stats")
+	c.ComputePeerService = coreconfig.Datadog.GetBool("apm_config.compute_peer_service")
+	if k := "apm_config.file_receiver.dir"; coreconfig.Datadog.IsSet(k) {
+		c.FileReceiver.Dir = coreconfig.Datadog.GetString(k)
+	}
+	if k := "apm_config.file_receiver.poll_interval_seconds"; coreconfig.Datadog.GetInt(k) > 0 {
+		c.FileReceiver.PollInterval = time.Duration(coreconfig.Datadog.GetInt(k)) * time.Second
+	}
+	if k := "apm_config.peer_service_precedence"; coreconfig.Datadog.IsSet(k) {
+		if precedence := coreconfig.Datadog.GetStringSlice(k); len(precedence) > 0 {
+			c.PeerServicePrecedence = precedence
+		} else {
+			log.Warnf("%q is empty, using the default precedence %v", k, config.DefaultPeerServicePrecedence)
+		}
+	}
 	if coreconfig.Datadog.IsSet("apm_config.extra_sample_rate") {
 		c.ExtraSampleRate = coreconfig.Datadog.GetFloat64("apm_config.extra_sample_rate")
 	}
@@ -242,6 +257,9 @@ func applyDatadogConfig(c *config.AgentConfig) error {
 	if k := "apm_config.max_payload_size"; coreconfig.Datadog.IsSet(k) {
 		c.MaxRequestBytes = coreconfig.Datadog.GetInt64(k)
 	}
+	if k := "apm_config.max_payloads_per_second_per_container"; coreconfig.Datadog.IsSet(k) {
+		c.MaxContainerPayloadsPerSecond = coreconfig.Datadog.GetFloat64(k)
+	}
 	if k := "apm_config.replace_tags"; coreconfig.Datadog.IsSet(k) {
 		rt := make([]*config.ReplaceRule, 0)
 		if err := coreconfig.Datadog.UnmarshalKey(k, &rt); err != nil {
@@ -348,6 +366,9 @@ func applyDatadogConfig(c *config.AgentConfig) error {
 	if coreconfig.Datadog.IsSet("apm_config.connection_reset_interval") {
 		c.ConnectionResetInterval = getDuration(coreconfig.Datadog.GetInt("apm_config.connection_reset_interval"))
 	}
+	if coreconfig.Datadog.IsSet("apm_config.shutdown_drain_timeout") {
+		c.ShutdownDrainTimeout = getDuration(coreconfig.Datadog.GetInt("apm_config.shutdown_drain_timeout"))
+	}
 	if coreconfig.Datadog.IsSet("apm_config.sync_flushing") {
 		c.SynchronousFlushing = coreconfig.Datadog.GetBool("apm_config.sync_flushing")
 	}
@@ -452,6 +473,9 @@ func applyDatadogConfig(c *config.AgentConfig) error {
 		c.EVPProxy.MaxPayloadSize = coreconfig.Datadog.GetInt64(k)
 	}
 	c.DebugServerPort = coreconfig.Datadog.GetInt("apm_config.debug.port")
+	if k := "apm_config.debug.sampling_decisions_buffer_size"; coreconfig.Datadog.IsSet(k) {
+		c.SamplingDecisionsBufferSize = coreconfig.Datadog.GetInt(k)
+	}
 	return nil
 }
 
@@ -516,6 +516,7 @@ func TestUndocumentedYamlConfig(t *testing.T) {
 	assert.Equal(1000.0, c.MaxEPS)
 	assert.Equal(25, c.ReceiverPort)
 	assert.Equal(120*time.Second, c.ConnectionResetInterval)
+	assert.Equal(15*time.Second, c.ShutdownDrainTimeout)
 	// watchdog
 	assert.Equal(0.07, c.MaxCPU)
 	assert.Equal(30e6, c.MaxMemory)
@@ -523,8 +524,11 @@ func TestUndocumentedYamlConfig(t *testing.T) {
 	// Assert Trace Writer
 	assert.Equal(1, c.TraceWriter.ConnectionLimit)
 	assert.Equal(2, c.TraceWriter.QueueSize)
+	assert.Equal("zstd", c.TraceWriter.Compression)
+	assert.Equal(3, c.TraceWriter.CompressionLevel)
 	assert.Equal(5, c.StatsWriter.ConnectionLimit)
 	assert.Equal(6, c.StatsWriter.QueueSize)
+	assert.Equal("", c.StatsWriter.Compression)
 	// analysis legacy
 	assert.Equal(1.0, c.AnalyzedRateByServiceLegacy["db"])
 	assert.Equal(0.9, c.AnalyzedRateByServiceLegacy["web"])
@@ -929,6 +933,16 @@ func TestLoadEnv(t *testing.T) {
 		assert.Equal(337.41, cfg.MaxRemoteTPS)
 	})
 
+	env = "DD_APM_MAX_PAYLOADS_PER_SECOND_PER_CONTAINER"
+	t.Run(env, func(t *testing.T) {
+		defer cleanConfig()()
+		assert := assert.New(t)
+		t.Setenv(env, "12.5")
+		cfg, err := LoadConfigFile("./testdata/full.yaml")
+		assert.NoError(err)
+		assert.Equal(12.5, cfg.MaxContainerPayloadsPerSecond)
+	})
+
 	env = "DD_APM_ADDITIONAL_ENDPOINTS"
 	t.Run(env, func(t *testing.T) {
 		defer cleanConfig()()
@@ -1158,6 +1172,43 @@ func TestPeerServiceAggregation(t *testing.T) {
 	})
 }
 
+func TestComputePeerService(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		defer cleanConfig()
+		cfg := config.New()
+		err := applyDatadogConfig(cfg)
+
+		assert := assert.New(t)
+		assert.NoError(err)
+		assert.False(cfg.ComputePeerService)
+		assert.Equal([]string{"db.instance", "net.peer.name", "out.host"}, cfg.PeerServicePrecedence)
+	})
+	t.Run("precedence", func(t *testing.T) {
+		defer cleanConfig()
+		coreconfig.Datadog.Set("apm_config.compute_peer_service", true)
+		coreconfig.Datadog.Set("apm_config.peer_service_precedence", []string{"rpc.service", "out.host"})
+		cfg := config.New()
+		err := applyDatadogConfig(cfg)
+
+		assert := assert.New(t)
+		assert.NoError(err)
+		assert.True(cfg.ComputePeerService)
+		assert.Equal([]string{"rpc.service", "out.host"}, cfg.PeerServicePrecedence)
+	})
+	t.Run("env", func(t *testing.T) {
+		defer cleanConfig()
+		t.Setenv("DD_APM_COMPUTE_PEER_SERVICE", "true")
+		t.Setenv("DD_APM_PEER_SERVICE_PRECEDENCE", "rpc.service,db.instance")
+		cfg := config.New()
+		err := applyDatadogConfig(cfg)
+
+		assert := assert.New(t)
+		assert.NoError(err)
+		assert.True(cfg.ComputePeerService)
+		assert.Equal([]string{"rpc.service", "db.instance"}, cfg.PeerServicePrecedence)
+	})
+}
+
 func TestComputeStatsBySpanKind(t *testing.T) {
 	t.Run("disabled", func(t *testing.T) {
 		defer cleanConfig()
@@ -1179,3 +1230,28 @@ func TestComputeStatsBySpanKind(t *testing.T) {
 		assert.True(cfg.ComputeStatsBySpanKind)
 	})
 }
+
+func TestFileReceiver(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		defer cleanConfig()
+		cfg := config.New()
+		err := applyDatadogConfig(cfg)
+
+		assert := assert.New(t)
+		assert.NoError(err)
+		assert.Empty(cfg.FileReceiver.Dir)
+		assert.Equal(5*time.Second, cfg.FileReceiver.PollInterval)
+	})
+	t.Run("env", func(t *testing.T) {
+		defer cleanConfig()
+		t.Setenv("DD_APM_FILE_RECEIVER_DIR", "/var/lib/traces")
+		t.Setenv("DD_APM_FILE_RECEIVER_POLL_INTERVAL_SECONDS", "30")
+		cfg := config.New()
+		err := applyDatadogConfig(cfg)
+
+		assert := assert.New(t)
+		assert.NoError(err)
+		assert.Equal("/var/lib/traces", cfg.FileReceiver.Dir)
+		assert.Equal(30*time.Second, cfg.FileReceiver.PollInterval)
+	})
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/DataDog/datadog-agent/comp/core/config"
 	"github.com/DataDog/datadog-agent/comp/core/flare/helpers"
 	"github.com/DataDog/datadog-agent/comp/core/log"
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
 	"github.com/DataDog/datadog-agent/pkg/config/utils"
 	pkgFlare "github.com/DataDog/datadog-agent/pkg/flare"
 )
@@ -79,6 +80,7 @@ func (f *flare) Create(pdata ProfileData, ipcError error) (string, error) {
 	providers := append(
 		f.providers,
 		helpers.FlareProvider{Callback: pkgFlare.CompleteFlare},
+		helpers.FlareProvider{Callback: aggregator.FillFlare},
 		helpers.FlareProvider{Callback: f.collectLogsFiles},
 		helpers.FlareProvider{Callback: f.collectConfigFiles},
 	)
@@ -0,0 +1,129 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package listeners
+
+import (
+	"bytes"
+	"expvar"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/telemetry"
+	"github.com/DataDog/datadog-agent/pkg/util/backpressure"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+var (
+	backpressureExpvars = expvar.NewMap("dogstatsd-backpressure")
+
+	tlmBackpressureDropped = telemetry.NewCounter("dogstatsd", "backpressure_dropped_messages",
+		[]string{"prefix"}, "Count of low priority messages dropped because the flush pipeline is saturated")
+)
+
+// lowPriorityFilter drops the messages whose metric name starts with one of
+// the configured low priority prefixes while the flush pipeline is saturated,
+// so that the listeners don't block on data which can be lost.
+type lowPriorityFilter struct {
+	prefixes []lowPriorityPrefix
+	isActive func() bool
+}
+
+// lowPriorityPrefix holds a low priority prefix, to be matched against the
+// messages, and the counters of the messages dropped because of it. They are
+// resolved once so that dropping a message doesn't allocate.
+type lowPriorityPrefix struct {
+	bytes         []byte
+	name          string
+	dropped       telemetry.SimpleCounter
+	droppedExpvar *expvar.Int
+}
+
+func newLowPriorityPrefix(prefix string) lowPriorityPrefix {
+	// the expvar may already have been created by the filter of another listener
+	backpressureExpvars.Add(prefix, 0)
+	droppedExpvar, _ := backpressureExpvars.Get(prefix).(*expvar.Int)
+	return lowPriorityPrefix{
+		bytes:         []byte(prefix),
+		name:          prefix,
+		dropped:       tlmBackpressureDropped.WithValues(prefix),
+		droppedExpvar: droppedExpvar,
+	}
+}
+
+// newLowPriorityFilter returns a filter for the prefixes listed in
+// `dogstatsd_backpressure_low_priority_prefixes`, or nil when none are set.
+func newLowPriorityFilter(cfg config.ConfigReader) *lowPriorityFilter {
+	var prefixes []lowPriorityPrefix
+	var names []string
+	for _, prefix := range cfg.GetStringSlice("dogstatsd_backpressure_low_priority_prefixes") {
+		if prefix == "" {
+			continue
+		}
+		prefixes = append(prefixes, newLowPriorityPrefix(prefix))
+		names = append(names, prefix)
+	}
+	if len(prefixes) == 0 {
+		return nil
+	}
+	log.Debugf("dogstatsd: dropping metrics prefixed by %q when the flush pipeline is saturated", names)
+	return &lowPriorityFilter{
+		prefixes: prefixes,
+		isActive: backpressure.IsActive,
+	}
+}
+
+// filter removes the low priority messages from the given datagram when the
+// flush pipeline is saturated and returns what is left of it. The datagram
+// is modified in place.
+func (f *lowPriorityFilter) filter(datagram []byte) []byte {
+	if f == nil || !f.isActive() {
+		return datagram
+	}
+	return f.filterTo(datagram[:0], datagram)
+}
+
+// filterCopy is like filter but leaves the datagram untouched, for when it is
+// also held by a traffic capture. What is left of the datagram is copied to a
+// new buffer when the flush pipeline is saturated.
+func (f *lowPriorityFilter) filterCopy(datagram []byte) []byte {
+	if f == nil || !f.isActive() {
+		return datagram
+	}
+	return f.filterTo(make([]byte, 0, len(datagram)), datagram)
+}
+
+// filterTo appends the messages of the datagram which aren't dropped to out.
+func (f *lowPriorityFilter) filterTo(out []byte, datagram []byte) []byte {
+	for len(datagram) > 0 {
+		var message []byte
+		if i := bytes.IndexByte(datagram, '\n'); i >= 0 {
+			message, datagram = datagram[:i], datagram[i+1:]
+		} else {
+			message, datagram = datagram, nil
+		}
+
+		if len(message) == 0 || f.drop(message) {
+			continue
+		}
+		if len(out) > 0 {
+			out = append(out, '\n')
+		}
+		// when filtering in place, out never grows past the part of the
+		// datagram already read
+		out = append(out, message...)
+	}
+	return out
+}
+
+func (f *lowPriorityFilter) drop(message []byte) bool {
+	for _, prefix := range f.prefixes {
+		if bytes.HasPrefix(message, prefix.bytes) {
+			prefix.dropped.Inc()
+			prefix.droppedExpvar.Add(1)
+			return true
+		}
+	}
+	return false
+}
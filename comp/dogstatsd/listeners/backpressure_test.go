@@ -0,0 +1,87 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package listeners
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewLowPriorityFilter(t *testing.T) {
+	cfg := fulfillDepsWithConfig(t, map[string]interface{}{})
+	assert.Nil(t, newLowPriorityFilter(cfg))
+
+	cfg = fulfillDepsWithConfig(t, map[string]interface{}{
+		"dogstatsd_backpressure_low_priority_prefixes": []string{"debug.", ""},
+	})
+	f := newLowPriorityFilter(cfg)
+	require.NotNil(t, f)
+	require.Len(t, f.prefixes, 1)
+	assert.Equal(t, []byte("debug."), f.prefixes[0].bytes)
+	assert.Equal(t, "debug.", f.prefixes[0].name)
+}
+
+func TestLowPriorityFilter(t *testing.T) {
+	active := false
+	f := &lowPriorityFilter{
+		prefixes: []lowPriorityPrefix{newLowPriorityPrefix("debug."), newLowPriorityPrefix("test.")},
+		isActive: func() bool { return active },
+	}
+
+	datagram := "app.hits:1|c\ndebug.loop:1|c\napp.latency:12|ms\ntest.foo:2|g"
+	assert.Equal(t, datagram, string(f.filter([]byte(datagram))))
+
+	active = true
+	dropped := f.prefixes[0].droppedExpvar.Value()
+	assert.Equal(t, "app.hits:1|c\napp.latency:12|ms", string(f.filter([]byte(datagram))))
+	assert.Equal(t, dropped+1, f.prefixes[0].droppedExpvar.Value())
+	assert.Equal(t, "app.hits:1|c", string(f.filter([]byte("debug.a:1|c\n\napp.hits:1|c\n"))))
+	assert.Empty(t, f.filter([]byte("debug.a:1|c\ntest.b:1|c")))
+
+	var nilFilter *lowPriorityFilter
+	assert.Equal(t, datagram, string(nilFilter.filter([]byte(datagram))))
+	assert.Equal(t, datagram, string(nilFilter.filterCopy([]byte(datagram))))
+}
+
+func TestLowPriorityFilterCopy(t *testing.T) {
+	active := true
+	f := &lowPriorityFilter{
+		prefixes: []lowPriorityPrefix{newLowPriorityPrefix("debug.")},
+		isActive: func() bool { return active },
+	}
+
+	datagram := []byte("debug.loop:1|c\napp.hits:1|c")
+	assert.Equal(t, "app.hits:1|c", string(f.filterCopy(datagram)))
+	assert.Equal(t, "debug.loop:1|c\napp.hits:1|c", string(datagram), "the datagram must be left untouched")
+
+	active = false
+	assert.Equal(t, "debug.loop:1|c\napp.hits:1|c", string(f.filterCopy(datagram)))
+}
+
+func BenchmarkLowPriorityFilterDrop(b *testing.B) {
+	f := &lowPriorityFilter{
+		prefixes: []lowPriorityPrefix{newLowPriorityPrefix("debug.")},
+		isActive: func() bool { return true },
+	}
+	message := []byte("debug.loop:1|c")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f.drop(message)
+	}
+}
+
+func TestLowPriorityFilterDropDoesNotAllocate(t *testing.T) {
+	f := &lowPriorityFilter{
+		prefixes: []lowPriorityPrefix{newLowPriorityPrefix("debug.")},
+		isActive: func() bool { return true },
+	}
+	message := []byte("debug.loop:1|c")
+	assert.Zero(t, testing.AllocsPerRun(100, func() { f.drop(message) }))
+}
@@ -35,6 +35,7 @@ type NamedPipeListener struct {
 	packetManager  *packets.PacketManager
 	connections    *namedPipeConnections
 	trafficCapture replay.Component // Currently ignored
+	lowPriority    *lowPriorityFilter
 }
 
 // NewNamedPipeListener returns an named pipe Statsd listener
@@ -42,11 +43,16 @@ func NewNamedPipeListener(pipeName string, packetOut chan packets.Packets,
 	sharedPacketPoolManager *packets.PoolManager, cfg config.ConfigReader, capture replay.Component) (*NamedPipeListener, error) {
 
 	bufferSize := cfg.GetInt("dogstatsd_buffer_size")
-	return newNamedPipeListener(
+	listener, err := newNamedPipeListener(
 		pipeName,
 		bufferSize,
 		packets.NewPacketManagerFromConfig(packetOut, sharedPacketPoolManager, cfg),
 		capture)
+	if err != nil {
+		return nil, err
+	}
+	listener.lowPriority = newLowPriorityFilter(cfg)
+	return listener, nil
 }
 
 func newNamedPipeListener(
@@ -176,7 +182,9 @@ func (l *NamedPipeListener) listenConnection(conn net.Conn, buffer []byte) {
 				namedPipeTelemetry.onReadSuccess(messageSize)
 
 				// PacketAssembler merges multiple packets together and sends them when its buffer is full
-				l.packetManager.PacketAssembler.AddMessage(buffer[:messageSize])
+				if message := l.lowPriority.filter(buffer[:messageSize]); len(message) > 0 {
+					l.packetManager.PacketAssembler.AddMessage(message)
+				}
 			}
 
 			startWriteIndex = endIndex - messageSize
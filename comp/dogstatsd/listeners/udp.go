@@ -41,6 +41,7 @@ type UDPListener struct {
 	packetAssembler *packets.Assembler
 	buffer          []byte
 	trafficCapture  replay.Component // Currently ignored
+	lowPriority     *lowPriorityFilter
 }
 
 // NewUDPListener returns an idle UDP Statsd listener
@@ -84,6 +85,7 @@ func NewUDPListener(packetOut chan packets.Packets, sharedPacketPoolManager *pac
 		packetAssembler: packetAssembler,
 		buffer:          buffer,
 		trafficCapture:  capture,
+		lowPriority:     newLowPriorityFilter(cfg),
 	}
 	log.Debugf("dogstatsd-udp: %s successfully initialized", conn.LocalAddr())
 	return listener, nil
@@ -114,7 +116,9 @@ func (l *UDPListener) Listen() {
 			tlmUDPPacketsBytes.Add(float64(n))
 
 			// packetAssembler merges multiple packets together and sends them when its buffer is full
-			l.packetAssembler.AddMessage(l.buffer[:n])
+			if message := l.lowPriority.filter(l.buffer[:n]); len(message) > 0 {
+				l.packetAssembler.AddMessage(message)
+			}
 		}
 
 		t2 = time.Now()
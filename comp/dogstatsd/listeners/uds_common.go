@@ -48,6 +48,7 @@ type UDSListener struct {
 	trafficCapture          replay.Component
 	OriginDetection         bool
 	config                  config.ConfigReader
+	lowPriority             *lowPriorityFilter
 
 	dogstatsdMemBasedRateLimiter bool
 }
@@ -109,6 +110,7 @@ func NewUDSListener(packetOut chan packets.Packets, sharedPacketPoolManager *pac
 		trafficCapture:               capture,
 		dogstatsdMemBasedRateLimiter: cfg.GetBool("dogstatsd_mem_based_rate_limiter.enabled"),
 		config:                       cfg,
+		lowPriority:                  newLowPriorityFilter(cfg),
 	}
 
 	if listener.trafficCapture != nil {
@@ -268,6 +270,18 @@ func (l *UDSListener) Listen() {
 		packet.Contents = packet.Buffer[:n]
 		packet.Source = packets.UDS
 
+		// the filter modifies the packet in place, leave the captured traffic untouched
+		if capBuff == nil {
+			packet.Contents = l.lowPriority.filter(packet.Contents)
+		} else {
+			packet.Contents = l.lowPriority.filterCopy(packet.Contents)
+		}
+		if len(packet.Contents) == 0 {
+			// every message was dropped because of the backpressure
+			l.sharedPacketPoolManager.Put(packet)
+			continue
+		}
+
 		// packetsBuffer handles the forwarding of the packets to the dogstatsd server intake channel
 		l.packetsBuffer.Append(packet)
 	}
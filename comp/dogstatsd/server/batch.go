@@ -133,6 +133,11 @@ func newServerlessBatcher(demux aggregator.Demultiplexer) *batcher {
 		pipelineCount: pipelineCount,
 		tagsBuffer:    tagset.NewHashingTagsAccumulator(),
 		keyGenerator:  ckey.NewKeyGenerator(),
+
+		// the serverless demultiplexer has a single time sampler, it aggregates
+		// the samples with a timestamp itself when the no-aggregation pipeline
+		// is disabled.
+		noAggPipelineEnabled: true,
 	}
 }
 
@@ -15,6 +15,7 @@ import (
 	"github.com/DataDog/datadog-agent/comp/core/config"
 	"github.com/DataDog/datadog-agent/comp/forwarder/defaultforwarder/internal/retry"
 	"github.com/DataDog/datadog-agent/comp/forwarder/defaultforwarder/transaction"
+	"github.com/DataDog/datadog-agent/pkg/util/backpressure"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
@@ -260,6 +261,8 @@ func (f *domainForwarder) sendHTTPTransactions(t transaction.Transaction) {
 	case f.highPrio <- t:
 	default:
 		f.addToTransactionRetryQueue(t)
+		// let the intakes know they should shed low priority data
+		backpressure.Raise()
 		highPriorityQueueFull.Add(1)
 		tlmTxHighPriorityQueueFull.Inc(f.domain, t.GetEndpointName())
 		log.Debugf("Adding the transaction to the retry queue because the forwarder input queue for %s is full; consider increasing forwarder_num_workers", f.domain)
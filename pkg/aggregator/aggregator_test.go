@@ -14,6 +14,7 @@ import (
 	"expvar"
 	"fmt"
 	"sort"
+	"sync"
 	"testing"
 	"time"
 
@@ -599,6 +600,51 @@ func TestTimeSamplerFlush(t *testing.T) {
 	assertSeriesEqual(t, s.series, expectedSeries)
 }
 
+// concurrentMockSerializerIterableSerie is a MockSerializerIterableSerie supporting concurrent
+// calls of SendIterableSeries, which counts them.
+type concurrentMockSerializerIterableSerie struct {
+	MockSerializerIterableSerie
+	mu    sync.Mutex
+	calls int
+}
+
+func (s *concurrentMockSerializerIterableSerie) SendIterableSeries(seriesSource metrics.SerieSource) error {
+	var series []*metrics.Serie
+	for seriesSource.MoveNext() {
+		series = append(series, seriesSource.Current())
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.series = append(s.series, series...)
+	s.calls++
+	return nil
+}
+
+func TestTimeSamplerPartitionedFlush(t *testing.T) {
+	pc := pkgconfig.Datadog.GetInt("dogstatsd_pipeline_count")
+	pkgconfig.Datadog.Set("dogstatsd_pipeline_count", 1)
+	defer pkgconfig.Datadog.Set("dogstatsd_pipeline_count", pc)
+
+	s := &concurrentMockSerializerIterableSerie{}
+	s.On("SendServiceChecks", mock.Anything).Return(nil)
+	opts := demuxTestOptions()
+	forwarder := fxutil.Test[defaultforwarder.Component](t, defaultforwarder.MockModule, config.MockModule)
+	// the mock config module resets the configuration
+	partitions := pkgconfig.Datadog.GetInt("aggregator_flush_partitions")
+	pkgconfig.Datadog.Set("aggregator_flush_partitions", 4)
+	defer pkgconfig.Datadog.Set("aggregator_flush_partitions", partitions)
+	demux := InitAndStartAgentDemultiplexer(forwarder, opts, "")
+	demux.aggregator.serializer = s
+	demux.sharedSerializer = s
+	expectedSeries := flushSomeSamples(demux)
+
+	// the main sink, and each partition of the time sampler, are serialized separately
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	assert.Equal(t, 5, s.calls)
+	assertSeriesEqual(t, s.series, expectedSeries)
+}
+
 // The implementation of MockSerializer.SendIterableSeries uses `s.Called(series).Error(0)`.
 // It calls internaly `Printf` on each field of the real type of `IterableStreamJSONMarshaler` which is `IterableSeries`.
 // It can lead to a race condition, if another goruntine call `IterableSeries.Append` which modifies `series.count`.
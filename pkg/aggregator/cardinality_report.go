@@ -0,0 +1,202 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package aggregator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	flarehelpers "github.com/DataDog/datadog-agent/comp/core/flare/helpers"
+)
+
+// cardinalityReportTopN is the number of metric names and tag keys listed in the cardinality report
+const cardinalityReportTopN = 100
+
+// cardinalityReportTimeout bounds the time the flare waits for the samplers to report their contexts,
+// as they may be busy or stopped
+const cardinalityReportTimeout = 10 * time.Second
+
+// CardinalityReport describes the contexts currently tracked by the samplers, to help finding out
+// which metrics and tags are responsible for a high number of contexts.
+type CardinalityReport struct {
+	// Contexts is the total number of contexts tracked by the dogstatsd and check samplers
+	Contexts   int                 `json:"contexts"`
+	TopMetrics []MetricCardinality `json:"top_metrics"`
+	TopTagKeys []TagKeyCardinality `json:"top_tag_keys"`
+}
+
+// MetricCardinality is the number of contexts of a metric name
+type MetricCardinality struct {
+	Name     string `json:"name"`
+	Contexts int    `json:"contexts"`
+}
+
+// TagKeyCardinality is the number of distinct values of a tag key, across all the contexts
+type TagKeyCardinality struct {
+	Key            string `json:"key"`
+	DistinctValues int    `json:"distinct_values"`
+}
+
+// cardinalityAccumulator collects the contexts of the context resolvers of the samplers. As each sampler
+// owns its context resolver, the accumulator is handed to the sampler goroutines one after the other.
+type cardinalityAccumulator struct {
+	contexts       int
+	contextsByName map[string]int
+	valuesByTagKey map[string]map[string]struct{}
+}
+
+func newCardinalityAccumulator() *cardinalityAccumulator {
+	return &cardinalityAccumulator{
+		contextsByName: make(map[string]int),
+		valuesByTagKey: make(map[string]map[string]struct{}),
+	}
+}
+
+func (acc *cardinalityAccumulator) addContexts(cr *contextResolver) {
+	for _, context := range cr.contextsByKey {
+		acc.contexts++
+		acc.contextsByName[context.Name]++
+		context.Tags().ForEach(func(tag string) {
+			// tags without a value, e.g. `foo`, are counted with an empty value
+			key, value, _ := strings.Cut(tag, ":")
+			values, ok := acc.valuesByTagKey[key]
+			if !ok {
+				values = make(map[string]struct{})
+				acc.valuesByTagKey[key] = values
+			}
+			values[value] = struct{}{}
+		})
+	}
+}
+
+// report returns the report of the collected contexts, limited to the topN metric names and tag keys
+func (acc *cardinalityAccumulator) report(topN int) CardinalityReport {
+	report := CardinalityReport{
+		Contexts:   acc.contexts,
+		TopMetrics: make([]MetricCardinality, 0, len(acc.contextsByName)),
+		TopTagKeys: make([]TagKeyCardinality, 0, len(acc.valuesByTagKey)),
+	}
+
+	for name, contexts := range acc.contextsByName {
+		report.TopMetrics = append(report.TopMetrics, MetricCardinality{Name: name, Contexts: contexts})
+	}
+	sort.Slice(report.TopMetrics, func(i, j int) bool {
+		if report.TopMetrics[i].Contexts != report.TopMetrics[j].Contexts {
+			return report.TopMetrics[i].Contexts > report.TopMetrics[j].Contexts
+		}
+		return report.TopMetrics[i].Name < report.TopMetrics[j].Name
+	})
+	if len(report.TopMetrics) > topN {
+		report.TopMetrics = report.TopMetrics[:topN]
+	}
+
+	for key, values := range acc.valuesByTagKey {
+		report.TopTagKeys = append(report.TopTagKeys, TagKeyCardinality{Key: key, DistinctValues: len(values)})
+	}
+	sort.Slice(report.TopTagKeys, func(i, j int) bool {
+		if report.TopTagKeys[i].DistinctValues != report.TopTagKeys[j].DistinctValues {
+			return report.TopTagKeys[i].DistinctValues > report.TopTagKeys[j].DistinctValues
+		}
+		return report.TopTagKeys[i].Key < report.TopTagKeys[j].Key
+	})
+	if len(report.TopTagKeys) > topN {
+		report.TopTagKeys = report.TopTagKeys[:topN]
+	}
+
+	return report
+}
+
+// cardinalityRequest asks a sampler goroutine to add the contexts of its samplers to the accumulator.
+// The requester is unblocked through blockChan once it's done. blockChan is buffered, so that the
+// sampler goroutine isn't blocked when the requester gave up waiting.
+type cardinalityRequest struct {
+	acc       *cardinalityAccumulator
+	blockChan chan struct{}
+}
+
+// handle adds the contexts of the check samplers. It's sent to the aggregator as a check item
+// so that the check samplers aren't accessed concurrently with the samples processing.
+func (r *cardinalityRequest) handle(agg *BufferedAggregator) {
+	agg.mu.Lock()
+	for _, cs := range agg.checkSamplers {
+		r.acc.addContexts(cs.contextResolver.resolver)
+	}
+	agg.mu.Unlock()
+	r.blockChan <- struct{}{}
+}
+
+func newCardinalityRequest(acc *cardinalityAccumulator) *cardinalityRequest {
+	return &cardinalityRequest{acc: acc, blockChan: make(chan struct{}, 1)}
+}
+
+// wait waits for the request to be handled, or for the context to be done
+func (r *cardinalityRequest) wait(ctx context.Context) error {
+	select {
+	case <-r.blockChan:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("timed out waiting for the cardinality report of the samplers: %w", ctx.Err())
+	}
+}
+
+// CardinalityReport returns the cardinality report of the contexts currently tracked by the
+// dogstatsd time samplers and the check samplers. It returns an error if the samplers don't
+// handle the request before the context is done.
+func (d *AgentDemultiplexer) CardinalityReport(ctx context.Context) (CardinalityReport, error) {
+	acc := newCardinalityAccumulator()
+
+	for _, worker := range d.statsd.workers {
+		req := newCardinalityRequest(acc)
+		select {
+		case worker.cardinalityChan <- req:
+		case <-ctx.Done():
+			return CardinalityReport{}, fmt.Errorf("timed out sending the cardinality request to the time samplers: %w", ctx.Err())
+		}
+		if err := req.wait(ctx); err != nil {
+			return CardinalityReport{}, err
+		}
+	}
+
+	req := newCardinalityRequest(acc)
+	select {
+	case d.aggregator.checkItems <- req:
+	case <-ctx.Done():
+		return CardinalityReport{}, fmt.Errorf("timed out sending the cardinality request to the check samplers: %w", ctx.Err())
+	}
+	if err := req.wait(ctx); err != nil {
+		return CardinalityReport{}, err
+	}
+
+	return acc.report(cardinalityReportTopN), nil
+}
+
+// FillFlare adds the cardinality report of the running demultiplexer to the flare. Nothing is added
+// when the flare isn't created by the running agent, e.g. for local flares.
+func FillFlare(fb flarehelpers.FlareBuilder) error {
+	demultiplexerInstanceMu.Lock()
+	demux, ok := demultiplexerInstance.(*AgentDemultiplexer)
+	demultiplexerInstanceMu.Unlock()
+	if !ok {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cardinalityReportTimeout)
+	defer cancel()
+	cardinalityReport, err := demux.CardinalityReport(ctx)
+	if err != nil {
+		return err
+	}
+
+	report, err := json.MarshalIndent(cardinalityReport, "", "  ")
+	if err != nil {
+		return err
+	}
+	return fb.AddFile("aggregator_cardinality.json", report)
+}
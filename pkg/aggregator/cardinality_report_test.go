@@ -0,0 +1,109 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build test
+// +build test
+
+package aggregator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/comp/core/config"
+	flarehelpers "github.com/DataDog/datadog-agent/comp/core/flare/helpers"
+	"github.com/DataDog/datadog-agent/comp/forwarder/defaultforwarder"
+	"github.com/DataDog/datadog-agent/pkg/aggregator/internal/tags"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
+)
+
+func testCardinalityReport(t *testing.T, store *tags.Store) {
+	cr := newContextResolver(store)
+	for _, sample := range []metrics.MetricSample{
+		{Name: "metric.a", Tags: []string{"env:prod", "pod:a"}},
+		{Name: "metric.a", Tags: []string{"env:prod", "pod:b"}},
+		{Name: "metric.a", Tags: []string{"env:prod", "pod:c"}},
+		{Name: "metric.b", Tags: []string{"env:staging", "pod:a", "canary"}},
+		{Name: "metric.c", Tags: []string{"env:prod"}},
+	} {
+		cr.trackContext(&sample)
+	}
+
+	acc := newCardinalityAccumulator()
+	acc.addContexts(cr)
+
+	assert.Equal(t, CardinalityReport{
+		Contexts: 5,
+		TopMetrics: []MetricCardinality{
+			{Name: "metric.a", Contexts: 3},
+			{Name: "metric.b", Contexts: 1},
+			{Name: "metric.c", Contexts: 1},
+		},
+		TopTagKeys: []TagKeyCardinality{
+			{Key: "pod", DistinctValues: 3},
+			{Key: "env", DistinctValues: 2},
+			{Key: "canary", DistinctValues: 1},
+		},
+	}, acc.report(10))
+
+	report := acc.report(1)
+	assert.Equal(t, 5, report.Contexts)
+	assert.Equal(t, []MetricCardinality{{Name: "metric.a", Contexts: 3}}, report.TopMetrics)
+	assert.Equal(t, []TagKeyCardinality{{Key: "pod", DistinctValues: 3}}, report.TopTagKeys)
+}
+
+func TestCardinalityReport(t *testing.T) {
+	testWithTagsStore(t, testCardinalityReport)
+}
+
+func TestDemuxCardinalityReport(t *testing.T) {
+	opts := demuxTestOptions()
+	forwarder := fxutil.Test[defaultforwarder.Component](t, defaultforwarder.MockModule, config.MockModule)
+	demux := InitAndStartAgentDemultiplexer(forwarder, opts, "")
+	defer demux.Stop(false)
+
+	demux.AggregateSample(metrics.MetricSample{Name: "dogstatsd.metric", Value: 1, Mtype: metrics.GaugeType, Tags: []string{"env:prod"}, SampleRate: 1, Timestamp: 1})
+	sender, err := demux.GetDefaultSender()
+	require.NoError(t, err)
+	sender.Gauge("check.metric", 1, "", []string{"env:staging"})
+	sender.Commit()
+
+	require.Eventually(t, func() bool {
+		report, err := demux.CardinalityReport(context.Background())
+		return err == nil && report.Contexts == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	report, err := demux.CardinalityReport(context.Background())
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []MetricCardinality{
+		{Name: "dogstatsd.metric", Contexts: 1},
+		{Name: "check.metric", Contexts: 1},
+	}, report.TopMetrics)
+	assert.Equal(t, []TagKeyCardinality{{Key: "env", DistinctValues: 2}}, report.TopTagKeys)
+
+	fb := flarehelpers.NewFlareBuilderMock(t, false)
+	require.NoError(t, FillFlare(fb.Fb))
+	fb.AssertFileExists("aggregator_cardinality.json")
+	fb.AssertFileContentMatch(`"contexts": 2`, "aggregator_cardinality.json")
+}
+
+func TestDemuxCardinalityReportTimeout(t *testing.T) {
+	// the worker isn't running, so nothing reads its cardinality requests
+	demux := &AgentDemultiplexer{
+		statsd: statsd{
+			workers: []*timeSamplerWorker{{cardinalityChan: make(chan *cardinalityRequest)}},
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := demux.CardinalityReport(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
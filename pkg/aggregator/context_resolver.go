@@ -22,6 +22,8 @@ type Context struct {
 	taggerTags *tags.Entry
 	metricTags *tags.Entry
 	noIndex    bool
+	// originKey is the hash of the tagger tags, shared by all the contexts of an origin
+	originKey ckey.TagsKey
 }
 
 // Tags returns tags for the context.
@@ -74,6 +76,7 @@ func (cr *contextResolver) trackContext(metricSampleContext metrics.MetricSample
 			Host:       metricSampleContext.GetHost(),
 			mtype:      mtype,
 			noIndex:    metricSampleContext.IsNoIndex(),
+			originKey:  taggerKey,
 		}
 		cr.countsByMtype[mtype]++
 	}
@@ -101,6 +101,10 @@ type flushTrigger struct {
 
 	sketchesSink metrics.SketchesSink
 	seriesSink   metrics.SerieSink
+
+	// serializeSeries serializes the series of each flush partition of the time samplers with its
+	// own sink, nil when the partitions are appended to seriesSink
+	serializeSeries seriesSerializer
 }
 
 func createIterableMetrics(
@@ -109,18 +113,9 @@ func createIterableMetrics(
 	logPayloads bool,
 	isServerless bool,
 ) (*metrics.IterableSeries, *metrics.IterableSketches) {
-	var series *metrics.IterableSeries
+	series := createIterableSeries(flushAndSerializeInParallel, serializer, logPayloads)
 	var sketches *metrics.IterableSketches
 
-	if serializer.AreSeriesEnabled() {
-		series = metrics.NewIterableSeries(func(se *metrics.Serie) {
-			if logPayloads {
-				log.Debugf("Flushing serie: %s", se)
-			}
-			tagsetTlm.updateHugeSerieTelemetry(se)
-		}, flushAndSerializeInParallel.BufferSize, flushAndSerializeInParallel.ChannelSize)
-	}
-
 	if serializer.AreSketchesEnabled() {
 		sketches = metrics.NewIterableSketches(func(sketch *metrics.SketchSeries) {
 			if logPayloads {
@@ -135,18 +130,42 @@ func createIterableMetrics(
 	return series, sketches
 }
 
+// createIterableSeries returns the IterableSeries of a flush, nil when the series are disabled.
+func createIterableSeries(
+	flushAndSerializeInParallel FlushAndSerializeInParallel,
+	serializer serializer.MetricSerializer,
+	logPayloads bool,
+) *metrics.IterableSeries {
+	if !serializer.AreSeriesEnabled() {
+		return nil
+	}
+	return metrics.NewIterableSeries(func(se *metrics.Serie) {
+		if logPayloads {
+			log.Debugf("Flushing serie: %s", se)
+		}
+		tagsetTlm.updateHugeSerieTelemetry(se)
+	}, flushAndSerializeInParallel.BufferSize, flushAndSerializeInParallel.ChannelSize)
+}
+
 // sendIterableSeries is continuously sending series to the serializer, until another routine calls SenderStopped on the
 // series sink.
 // Mainly meant to be executed in its own routine, sendIterableSeries is closing the `done` channel once it has returned
 // from SendIterableSeries (because the SenderStopped methods has been called on the sink).
 func sendIterableSeries(serializer serializer.MetricSerializer, start time.Time, serieSource metrics.SerieSource) {
+	count := serializeIterableSeries(serializer, start, serieSource)
+	addFlushCount("Series", int64(count))
+}
+
+// serializeIterableSeries sends the series to the serializer and returns their count, without adding it
+// to the flush count stats.
+func serializeIterableSeries(serializer serializer.MetricSerializer, start time.Time, serieSource metrics.SerieSource) uint64 {
 	log.Debug("Demultiplexer: sendIterableSeries: start sending iterable series to the serializer")
 	err := serializer.SendIterableSeries(serieSource)
 	// if err == nil, SenderStopped was called and it is safe to read the number of series.
 	count := serieSource.Count()
-	addFlushCount("Series", int64(count))
 	updateSerieTelemetry(start, count, err)
 	log.Debug("Demultiplexer: sendIterableSeries: stop routine")
+	return count
 }
 
 // GetDogStatsDWorkerAndPipelineCount returns how many routines should be spawned
@@ -8,6 +8,7 @@ package aggregator
 import (
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	forwarder "github.com/DataDog/datadog-agent/comp/forwarder/defaultforwarder"
@@ -51,6 +52,12 @@ type AgentDemultiplexer struct {
 
 	// sharded statsd time samplers
 	statsd
+
+	// seriesMirror forwards a selection of the flushed series to a local endpoint, nil when disabled
+	seriesMirror *seriesMirror
+
+	// secondaryFlush sends the flushed series and sketches to a second intake at its own interval, nil when disabled
+	secondaryFlush *secondaryFlush
 }
 
 // AgentDemultiplexerOptions are the options used to initialize a Demultiplexer.
@@ -191,9 +198,20 @@ func initAgentDemultiplexer(sharedForwarder forwarder.Forwarder, options AgentDe
 			config.Datadog.GetInt("dogstatsd_no_aggregation_pipeline_batch_size"),
 			noAggSerializer,
 			agg.flushAndSerializeInParallel,
+			agg.hostname,
 		)
 	}
 
+	seriesMirror, err := newSeriesMirrorFromConfig()
+	if err != nil {
+		log.Errorf("Unable to create the series mirror: %v", err)
+	}
+
+	secondaryFlush, err := newSecondaryFlushFromConfig()
+	if err != nil {
+		log.Errorf("Unable to create the secondary flush pipeline: %v", err)
+	}
+
 	// --
 
 	demux := &AgentDemultiplexer{
@@ -226,6 +244,9 @@ func initAgentDemultiplexer(sharedForwarder forwarder.Forwarder, options AgentDe
 			metricSamplePool:  metricSamplePool,
 			noAggStreamWorker: noAggWorker,
 		},
+
+		seriesMirror:   seriesMirror,
+		secondaryFlush: secondaryFlush,
 	}
 
 	return demux
@@ -309,6 +330,14 @@ func (d *AgentDemultiplexer) Run() {
 		go d.noAggStreamWorker.run()
 	}
 
+	if d.seriesMirror != nil {
+		go d.seriesMirror.run()
+	}
+
+	if d.secondaryFlush != nil {
+		go d.secondaryFlush.run(!d.options.DontStartForwarders)
+	}
+
 	d.flushLoop() // this is the blocking call
 }
 
@@ -379,6 +408,16 @@ func (d *AgentDemultiplexer) Stop(flush bool) {
 	}
 	d.aggregator = nil
 
+	if d.seriesMirror != nil {
+		d.seriesMirror.stop()
+		d.seriesMirror = nil
+	}
+
+	if d.secondaryFlush != nil {
+		d.secondaryFlush.stop(!d.options.DontStartForwarders)
+		d.secondaryFlush = nil
+	}
+
 	// forwarders
 
 	if !d.options.DontStartForwarders {
@@ -444,10 +483,34 @@ func (d *AgentDemultiplexer) flushToSerializer(start time.Time, waitForSerialize
 	logPayloads := config.Datadog.GetBool("log_payloads")
 	series, sketches := createIterableMetrics(d.aggregator.flushAndSerializeInParallel, d.sharedSerializer, logPayloads, false)
 
+	// the series of the flush partitions of the time samplers are serialized in parallel, and counted
+	// with the series of the main sink
+	var partitionsSeriesCount uint64
+	serializeSeries := func(produce func(metrics.SerieSink)) {
+		metrics.Serialize(
+			createIterableSeries(d.aggregator.flushAndSerializeInParallel, d.sharedSerializer, logPayloads),
+			nil,
+			func(seriesSink metrics.SerieSink, _ metrics.SketchesSink) {
+				seriesSink, flush := d.teeSeriesSink(seriesSink)
+				defer flush()
+				produce(seriesSink)
+			}, func(serieSource metrics.SerieSource) {
+				atomic.AddUint64(&partitionsSeriesCount, serializeIterableSeries(d.sharedSerializer, start, serieSource))
+			}, nil)
+	}
+
 	metrics.Serialize(
 		series,
 		sketches,
 		func(seriesSink metrics.SerieSink, sketchesSink metrics.SketchesSink) {
+			seriesSink, flush := d.teeSeriesSink(seriesSink)
+			defer flush()
+			if d.secondaryFlush != nil {
+				secondarySketchesSink := d.secondaryFlush.sketchesSink(sketchesSink)
+				defer secondarySketchesSink.flush()
+				sketchesSink = secondarySketchesSink
+			}
+
 			// flush DogStatsD pipelines (statsd/time samplers)
 			// ------------------------------------------------
 
@@ -458,8 +521,9 @@ func (d *AgentDemultiplexer) flushToSerializer(start time.Time, waitForSerialize
 						time:      start,
 						blockChan: make(chan struct{}),
 					},
-					sketchesSink: sketchesSink,
-					seriesSink:   seriesSink,
+					sketchesSink:    sketchesSink,
+					seriesSink:      seriesSink,
+					serializeSeries: serializeSeries,
 				}
 
 				worker.flushChan <- t
@@ -484,7 +548,9 @@ func (d *AgentDemultiplexer) flushToSerializer(start time.Time, waitForSerialize
 				<-t.trigger.blockChan
 			}
 		}, func(serieSource metrics.SerieSource) {
-			sendIterableSeries(d.sharedSerializer, start, serieSource)
+			// the partitions are serialized by the time the main sink is stopped
+			count := serializeIterableSeries(d.sharedSerializer, start, serieSource)
+			addFlushCount("Series", int64(count+atomic.LoadUint64(&partitionsSeriesCount)))
 		},
 		func(sketches metrics.SketchesSource) {
 			// Don't send empty sketches payloads
@@ -501,6 +567,27 @@ func (d *AgentDemultiplexer) flushToSerializer(start time.Time, waitForSerialize
 	aggregatorNumberOfFlush.Add(1)
 }
 
+// teeSeriesSink wraps the series sink of a flush with the sinks of the series mirror and of the
+// secondary flush, when enabled. The returned func must be called once the series are appended.
+func (d *AgentDemultiplexer) teeSeriesSink(seriesSink metrics.SerieSink) (metrics.SerieSink, func()) {
+	var flushes []func()
+	if d.seriesMirror != nil {
+		mirrorSink := d.seriesMirror.sink(seriesSink)
+		flushes = append(flushes, mirrorSink.flush)
+		seriesSink = mirrorSink
+	}
+	if d.secondaryFlush != nil {
+		secondarySink := d.secondaryFlush.sink(seriesSink)
+		flushes = append(flushes, secondarySink.flush)
+		seriesSink = secondarySink
+	}
+	return seriesSink, func() {
+		for _, flush := range flushes {
+			flush()
+		}
+	}
+}
+
 // GetEventsAndServiceChecksChannels returneds underlying events and service checks channels.
 func (d *AgentDemultiplexer) GetEventsAndServiceChecksChannels() (chan []*metrics.Event, chan []*metrics.ServiceCheck) {
 	return d.aggregator.GetBufferedChannels()
@@ -15,6 +15,7 @@ import (
 
 	"github.com/DataDog/datadog-agent/comp/core/config"
 	"github.com/DataDog/datadog-agent/comp/forwarder/defaultforwarder"
+	pkgconfig "github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/metrics"
 	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
 	"github.com/stretchr/testify/require"
@@ -134,3 +135,47 @@ func TestMetricSampleTypeConversion(t *testing.T) {
 		require.Equal(test.apiMetricType, rv, fmt.Sprintf("Wrong conversion for %s", test.metricType.String()))
 	}
 }
+
+// the samples of the no aggregation pipeline are enriched like the aggregated ones.
+func TestNoAggStreamSamplesEnrichment(t *testing.T) {
+	w := newNoAggregationStreamWorker(100, &MockSerializerIterableSerie{}, NewFlushAndSerializeInParallel(pkgconfig.Datadog), "hostname")
+	w.samplesByOrigin = make(map[string]*originSamples)
+
+	batch := metrics.MetricSampleBatch{
+		{
+			Name:      "first",
+			Value:     1,
+			Mtype:     metrics.GaugeType,
+			Timestamp: 1657099120.0,
+			Tags:      []string{"tag:2", "tag:1", "tag:2"},
+			NoIndex:   true,
+		},
+		{
+			Name:      "second",
+			Value:     2,
+			Mtype:     metrics.DistributionType,
+			Timestamp: 1657099120.0,
+		},
+	}
+	sink := mockSink{}
+	require.Equal(t, 1, w.streamSamples(batch, &sink))
+
+	require.Len(t, sink, 1)
+	require.Equal(t, "first", sink[0].Name)
+	require.Equal(t, []string{"tag:1", "tag:2"}, sink[0].Tags.UnsafeToReadOnlySliceString())
+	require.True(t, sink[0].NoIndex)
+
+	// the samples without origin are counted for the empty origin
+	sink = mockSink{}
+	w.sendOriginTelemetry(&sink)
+	require.Len(t, sink, 1)
+	require.Equal(t, "datadog.agent.aggregator.dogstatsd_samples_by_origin", sink[0].Name)
+	require.Equal(t, "hostname", sink[0].Host)
+	require.Equal(t, []string{"pipeline:no_aggregation"}, sink[0].Tags.UnsafeToReadOnlySliceString())
+	require.Equal(t, 1.0, sink[0].Points[0].Value)
+
+	// the counts are reset by the flush
+	sink = mockSink{}
+	w.sendOriginTelemetry(&sink)
+	require.Empty(t, sink)
+}
@@ -27,6 +27,8 @@ type ServerlessDemultiplexer struct {
 	forwarder     *forwarder.SyncForwarder
 	statsdSampler *TimeSampler
 	statsdWorker  *timeSamplerWorker
+	// noAggStreamWorker forwards the samples with a timestamp as-is, nil when the no-aggregation pipeline is disabled
+	noAggStreamWorker *noAggregationStreamWorker
 
 	flushLock *sync.Mutex
 
@@ -37,6 +39,19 @@ type ServerlessDemultiplexer struct {
 
 // InitAndStartServerlessDemultiplexer creates and starts new Demultiplexer for the serverless agent.
 func InitAndStartServerlessDemultiplexer(domainResolvers map[string]resolver.DomainResolver, forwarderTimeout time.Duration) *ServerlessDemultiplexer {
+	demux := initServerlessDemultiplexer(domainResolvers, forwarderTimeout)
+
+	// set the global instance
+	demultiplexerInstance = demux
+
+	// start routines
+	go demux.Run()
+
+	// we're done with the initialization
+	return demux
+}
+
+func initServerlessDemultiplexer(domainResolvers map[string]resolver.DomainResolver, forwarderTimeout time.Duration) *ServerlessDemultiplexer {
 	bufferSize := config.Datadog.GetInt("aggregator_buffer_size")
 	forwarder := forwarder.NewSyncForwarder(config.Datadog, domainResolvers, forwarderTimeout)
 	serializer := serializer.NewSerializer(forwarder, nil)
@@ -47,24 +62,28 @@ func InitAndStartServerlessDemultiplexer(domainResolvers map[string]resolver.Dom
 	flushAndSerializeInParallel := NewFlushAndSerializeInParallel(config.Datadog)
 	statsdWorker := newTimeSamplerWorker(statsdSampler, DefaultFlushInterval, bufferSize, metricSamplePool, flushAndSerializeInParallel, tagsStore)
 
+	var noAggWorker *noAggregationStreamWorker
+	if config.Datadog.GetBool("dogstatsd_no_aggregation_pipeline") {
+		noAggWorker = newNoAggregationStreamWorker(
+			config.Datadog.GetInt("dogstatsd_no_aggregation_pipeline_batch_size"),
+			serializer,
+			flushAndSerializeInParallel,
+			"",
+		)
+	}
+
 	demux := &ServerlessDemultiplexer{
-		forwarder:        forwarder,
-		statsdSampler:    statsdSampler,
-		statsdWorker:     statsdWorker,
-		serializer:       serializer,
-		metricSamplePool: metricSamplePool,
-		flushLock:        &sync.Mutex{},
+		forwarder:         forwarder,
+		statsdSampler:     statsdSampler,
+		statsdWorker:      statsdWorker,
+		noAggStreamWorker: noAggWorker,
+		serializer:        serializer,
+		metricSamplePool:  metricSamplePool,
+		flushLock:         &sync.Mutex{},
 
 		flushAndSerializeInParallel: flushAndSerializeInParallel,
 	}
 
-	// set the global instance
-	demultiplexerInstance = demux
-
-	// start routines
-	go demux.Run()
-
-	// we're done with the initialization
 	return demux
 }
 
@@ -77,6 +96,10 @@ func (d *ServerlessDemultiplexer) Run() {
 		log.Debug("not starting the forwarder")
 	}
 
+	if d.noAggStreamWorker != nil {
+		go d.noAggStreamWorker.run()
+	}
+
 	log.Debug("Demultiplexer started")
 	d.statsdWorker.run()
 }
@@ -88,17 +111,24 @@ func (d *ServerlessDemultiplexer) Stop(flush bool) {
 	}
 
 	d.statsdWorker.stop()
+	if d.noAggStreamWorker != nil {
+		d.noAggStreamWorker.stop(flush)
+	}
 
 	if d.forwarder != nil {
 		d.forwarder.Stop()
 	}
 }
 
-// ForceFlushToSerializer flushes all data from the time sampler to the serializer.
+// ForceFlushToSerializer flushes all data from the time sampler and the no-aggregation pipeline to the serializer.
 func (d *ServerlessDemultiplexer) ForceFlushToSerializer(start time.Time, waitForSerializer bool) {
 	d.flushLock.Lock()
 	defer d.flushLock.Unlock()
 
+	if d.noAggStreamWorker != nil {
+		d.noAggStreamWorker.flush()
+	}
+
 	logPayloads := config.Datadog.GetBool("log_payloads")
 	series, sketches := createIterableMetrics(d.flushAndSerializeInParallel, d.serializer, logPayloads, true)
 
@@ -147,9 +177,18 @@ func (d *ServerlessDemultiplexer) AggregateSamples(shard TimeSamplerID, samples
 	d.statsdWorker.samplesChan <- samples
 }
 
-// SendSamplesWithoutAggregation is not supported in the Serverless Agent implementation.
+// SendSamplesWithoutAggregation sends a MetricSampleBatch of metrics with a timestamp to the no-aggregation
+// pipeline, which forwards them as-is. The samples are aggregated by the TimeSampler when the pipeline is disabled.
 func (d *ServerlessDemultiplexer) SendSamplesWithoutAggregation(samples metrics.MetricSampleBatch) {
-	panic("not implemented.")
+	if d.noAggStreamWorker == nil {
+		d.AggregateSamples(TimeSamplerID(0), samples)
+		return
+	}
+
+	d.flushLock.Lock()
+	defer d.flushLock.Unlock()
+	tlmProcessed.Add(float64(len(samples)), "late_metrics")
+	d.noAggStreamWorker.addSamples(samples)
 }
 
 // Serializer returns the shared serializer
@@ -0,0 +1,61 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build test
+// +build test
+
+package aggregator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+// the samples with a timestamp are forwarded as-is, and sent by the flush of the invocation.
+func TestServerlessDemuxNoAggPipeline(t *testing.T) {
+	require := require.New(t)
+
+	config.Datadog.Set("dogstatsd_no_aggregation_pipeline", true)
+	defer config.Datadog.Set("dogstatsd_no_aggregation_pipeline", true)
+
+	demux := initServerlessDemultiplexer(nil, time.Second)
+	require.NotNil(demux.noAggStreamWorker)
+	mockSerializer := &MockSerializerIterableSerie{}
+	demux.noAggStreamWorker.serializer = mockSerializer
+	go demux.Run()
+	defer demux.Stop(false)
+
+	batch := testDemuxSamples(t)
+	demux.SendSamplesWithoutAggregation(batch)
+	// no automatic flush is awaited, the flush streams the samples already received
+	demux.ForceFlushToSerializer(time.Now(), true)
+
+	require.Len(mockSerializer.series, 3)
+	for i := 0; i < len(batch); i++ {
+		require.Equal(batch[i].Name, mockSerializer.series[i].Name)
+		require.Len(mockSerializer.series[i].Points, 1)
+		require.Equal(batch[i].Timestamp, mockSerializer.series[i].Points[0].Ts)
+	}
+}
+
+func TestServerlessDemuxNoAggPipelineDisabled(t *testing.T) {
+	require := require.New(t)
+
+	config.Datadog.Set("dogstatsd_no_aggregation_pipeline", false)
+	defer config.Datadog.Set("dogstatsd_no_aggregation_pipeline", true)
+
+	demux := initServerlessDemultiplexer(nil, time.Second)
+	require.Nil(demux.noAggStreamWorker)
+
+	// the samples are aggregated by the time sampler
+	demux.SendSamplesWithoutAggregation(testDemuxSamples(t))
+	require.Len(demux.statsdWorker.samplesChan, 1)
+	read := <-demux.statsdWorker.samplesChan
+	require.Len(read, 3)
+}
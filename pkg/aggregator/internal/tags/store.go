@@ -8,6 +8,7 @@ package tags
 import (
 	"fmt"
 	"math/bits"
+	"unsafe"
 
 	"go.uber.org/atomic"
 
@@ -28,6 +29,20 @@ type Entry struct {
 
 	// tags contains the cached tags in this entry.
 	tags []string
+
+	// size is the memory used by tags, in bytes.
+	size int
+}
+
+func newEntry(tags []string) *Entry {
+	size := int(unsafe.Sizeof(tags)) + len(tags)*int(unsafe.Sizeof(""))
+	for _, t := range tags {
+		size += len(t)
+	}
+	return &Entry{
+		tags: tags,
+		size: size,
+	}
 }
 
 // Tags returns the strings stored in the Entry. The slice may be
@@ -77,9 +92,7 @@ func NewStore(enabled bool, name string) *Store {
 // concurrently with other methods.
 func (tc *Store) Insert(key ckey.TagsKey, tagsBuffer *tagset.HashingTagsAccumulator) *Entry {
 	if !tc.enabled {
-		return &Entry{
-			tags: tagsBuffer.Copy(),
-		}
+		return newEntry(tagsBuffer.Copy())
 	}
 
 	entry := tc.tagsByKey[key]
@@ -88,9 +101,7 @@ func (tc *Store) Insert(key ckey.TagsKey, tagsBuffer *tagset.HashingTagsAccumula
 		entry.refs.Inc()
 		tc.telemetry.hits.Inc()
 	} else {
-		entry = &Entry{
-			tags: tagsBuffer.Copy(),
-		}
+		entry = newEntry(tagsBuffer.Copy())
 		entry.refs.Inc()
 		tc.tagsByKey[key] = entry
 		tc.cap++
@@ -142,6 +153,10 @@ func (tc *Store) updateTelemetry(s *entryStats) {
 	tlmTagsetMinTags.Set(float64(s.minSize), t.name)
 	tlmTagsetMaxTags.Set(float64(s.maxSize), t.name)
 	tlmTagsetSumTags.Set(float64(s.sumSize), t.name)
+
+	tlmDedupRatio.Set(s.dedupRatio(), t.name)
+	tlmStoredBytes.Set(float64(s.storedBytes), t.name)
+	tlmSavedBytes.Set(float64(s.savedBytes), t.name)
 }
 
 func newCounter(name string, help string, tags ...string) telemetry.Counter {
@@ -163,6 +178,9 @@ var (
 	tlmTagsetMaxTags = newGauge("tagset_max_tags", "maximum number of tags in a tagset")
 	tlmTagsetSumTags = newGauge("tagset_sum_tags", "total number of tags stored in all tagsets by the cache")
 	tlmTagsetRefsCnt = newGauge("tagset_refs_count", "distribution of usage count of tagsets in the cache", "ge")
+	tlmDedupRatio    = newGauge("dedup_ratio", "average number of references to each tagset stored in the cache")
+	tlmStoredBytes   = newGauge("stored_bytes", "memory used by the tagsets stored in the cache, in bytes")
+	tlmSavedBytes    = newGauge("saved_bytes", "memory saved by sharing the tagsets between their references, in bytes")
 )
 
 type storeTelemetry struct {
@@ -180,11 +198,15 @@ func newStoreTelemetry(name string) storeTelemetry {
 }
 
 type entryStats struct {
-	refsFreq [8]uint64
-	minSize  int
-	maxSize  int
-	sumSize  int
-	count    int
+	refsFreq    [8]uint64
+	minSize     int
+	maxSize     int
+	sumSize     int
+	count       int
+	sumRefs     uint64
+	storedBytes int
+	// savedBytes is the memory which would be used by the copies of the tagsets if they were not shared
+	savedBytes int
 }
 
 func (s *entryStats) visit(e *Entry, r uint64) {
@@ -205,4 +227,16 @@ func (s *entryStats) visit(e *Entry, r uint64) {
 	}
 	s.sumSize += n
 	s.count++
+
+	s.sumRefs += r
+	s.storedBytes += e.size
+	s.savedBytes += int(r-1) * e.size
+}
+
+// dedupRatio returns the average number of references to each entry, 0 when there are no entries
+func (s *entryStats) dedupRatio() float64 {
+	if s.count == 0 {
+		return 0
+	}
+	return float64(s.sumRefs) / float64(s.count)
 }
@@ -7,6 +7,7 @@ package tags
 
 import (
 	"testing"
+	"unsafe"
 
 	"github.com/DataDog/datadog-agent/pkg/aggregator/ckey"
 	"github.com/DataDog/datadog-agent/pkg/tagset"
@@ -130,3 +131,31 @@ func BenchmarkRefCounting(b *testing.B) {
 		entr.Release()
 	}
 }
+
+func TestStoreEntryStats(t *testing.T) {
+	c := NewStore(true, "test")
+
+	t1 := tagset.NewHashingTagsAccumulatorWithTags([]string{"env:prod", "service:web"})
+	t2 := tagset.NewHashingTagsAccumulatorWithTags([]string{"env:dev"})
+
+	e1 := c.Insert(1, t1)
+	c.Insert(1, t1)
+	c.Insert(1, t1)
+	e2 := c.Insert(2, t2)
+
+	// slice header, string headers, and string contents
+	sliceHeader, stringHeader := int(unsafe.Sizeof([]string{})), int(unsafe.Sizeof(""))
+	require.Equal(t, sliceHeader+2*stringHeader+len("env:prod")+len("service:web"), e1.size)
+	require.Equal(t, sliceHeader+stringHeader+len("env:dev"), e2.size)
+
+	stats := entryStats{}
+	for _, entry := range c.tagsByKey {
+		stats.visit(entry, entry.refs.Load())
+	}
+	require.EqualValues(t, 4, stats.sumRefs)
+	require.Equal(t, 2.0, stats.dedupRatio())
+	require.Equal(t, e1.size+e2.size, stats.storedBytes)
+	require.Equal(t, 2*e1.size, stats.savedBytes)
+
+	require.Equal(t, 0.0, (&entryStats{}).dedupRatio())
+}
@@ -7,6 +7,7 @@ package aggregator
 
 import (
 	"expvar"
+	"strings"
 	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/aggregator/internal/util"
@@ -35,6 +36,7 @@ type noAggregationStreamWorker struct {
 	serializer           serializer.MetricSerializer
 	flushConfig          FlushAndSerializeInParallel
 	maxMetricsPerPayload int
+	hostname             string
 
 	seriesSink   *metrics.IterableSeries
 	sketchesSink *metrics.IterableSketches
@@ -42,12 +44,23 @@ type noAggregationStreamWorker struct {
 	taggerBuffer *tagset.HashlessTagsAccumulator
 	metricBuffer *tagset.HashlessTagsAccumulator
 
+	// samplesByOrigin counts the samples streamed per origin since the last flush to the forwarder,
+	// it is nil when the origin telemetry is disabled
+	samplesByOrigin map[string]*originSamples
+
 	samplesChan chan metrics.MetricSampleBatch
 	stopChan    chan trigger
+	flushChan   chan trigger
 
 	logThrottling util.SimpleThrottler
 }
 
+// originSamples holds the tagger tags of an origin and the number of samples streamed for it.
+type originSamples struct {
+	tags  []string
+	count uint64
+}
+
 // noAggWorkerStreamCheckFrequency is the frequency at which the no agg worker
 // is checking if it has some samples to flush. It triggers this flush only
 // if it not still receiving samples.
@@ -73,11 +86,17 @@ func init() {
 	noaggExpvars.Set("Flush", &expvarNoAggFlush)
 }
 
-func newNoAggregationStreamWorker(maxMetricsPerPayload int, serializer serializer.MetricSerializer, flushConfig FlushAndSerializeInParallel) *noAggregationStreamWorker {
+func newNoAggregationStreamWorker(maxMetricsPerPayload int, serializer serializer.MetricSerializer, flushConfig FlushAndSerializeInParallel, hostname string) *noAggregationStreamWorker {
+	var samplesByOrigin map[string]*originSamples
+	if config.Datadog.GetBool("telemetry.enabled") && config.Datadog.GetBool("telemetry.dogstatsd_origin") {
+		samplesByOrigin = make(map[string]*originSamples)
+	}
+
 	return &noAggregationStreamWorker{
 		serializer:           serializer,
 		flushConfig:          flushConfig,
 		maxMetricsPerPayload: maxMetricsPerPayload,
+		hostname:             hostname,
 
 		seriesSink:   nil,
 		sketchesSink: nil,
@@ -85,7 +104,10 @@ func newNoAggregationStreamWorker(maxMetricsPerPayload int, serializer serialize
 		taggerBuffer: tagset.NewHashlessTagsAccumulator(),
 		metricBuffer: tagset.NewHashlessTagsAccumulator(),
 
+		samplesByOrigin: samplesByOrigin,
+
 		stopChan:    make(chan trigger),
+		flushChan:   make(chan trigger),
 		samplesChan: make(chan metrics.MetricSampleBatch, config.Datadog.GetInt("dogstatsd_queue_size")),
 
 		// warning for the unsupported metric types should appear maximum 200 times
@@ -119,6 +141,19 @@ func (w *noAggregationStreamWorker) stop(wait bool) {
 	}
 }
 
+// flush streams the samples received so far to the serializer, and blocks until the serializer sent them.
+// It is used by the serverless agent, which has to send the metrics before the end of the invocation instead
+// of relying on the automatic flushes.
+func (w *noAggregationStreamWorker) flush() {
+	trigger := trigger{
+		time:      time.Now(),
+		blockChan: make(chan struct{}),
+	}
+
+	w.flushChan <- trigger
+	<-trigger.blockChan
+}
+
 // mainloop of the no aggregation stream worker:
 //   - it receives samples and counts how much it has sent to the serializer, if it has more than a given amount it stops
 //     streaming for the serializer to start sending the payloads to the forwarder, and then starts the streaming
@@ -127,7 +162,7 @@ func (w *noAggregationStreamWorker) stop(wait bool) {
 //     the serializer for a while in order to let the serializer sends the payloads up to the forwarder, and starts
 //     the streaming mainloop again
 //   - listens for a stop signal
-//   - listens for a flush signal
+//   - listens for a flush signal, which streams the samples already received before flushing
 //
 // This is not ideal since the serializer should automatically takes the decision when to flush payloads to
 // the serializer but that's not how it works today, see noAggregationStreamWorker comment.
@@ -141,6 +176,7 @@ func (w *noAggregationStreamWorker) run() {
 
 	stopped := false
 	var stopBlockChan chan struct{}
+	var flushBlockChan chan struct{}
 	var lastStream time.Time
 
 	for !stopped {
@@ -170,64 +206,45 @@ func (w *noAggregationStreamWorker) run() {
 							break mainloop // end `Serialize` call and trigger a flush to the forwarder
 						}
 
-					// receiving samples
-					case samples := <-w.samplesChan:
-						log.Tracef("Streaming %d metrics from the no-aggregation pipeline", len(samples))
-						countProcessed := 0
-						countUnsupportedType := 0
-
-						for _, sample := range samples {
-							mtype, supported := metricSampleAPIType(sample)
-
-							if !supported {
-								if !w.logThrottling.ShouldThrottle() {
-									log.Warnf("Discarding unsupported metric sample in the no-aggregation pipeline for sample '%s', sample type '%s'", sample.Name, sample.Mtype.String())
-								}
-								countUnsupportedType++
-								continue
+					// flush signal
+					case trigger := <-w.flushChan:
+						// stream the samples already received, for the flush to include them
+						for pending := true; pending; {
+							select {
+							case samples := <-w.samplesChan:
+								serializedSamples += w.streamSamples(samples, seriesSink)
+							default:
+								pending = false
 							}
-
-							// enrich metric sample tags
-							sample.GetTags(w.taggerBuffer, w.metricBuffer)
-							w.metricBuffer.AppendHashlessAccumulator(w.taggerBuffer)
-
-							// turns this metric sample into a serie
-							var serie metrics.Serie
-							serie.Name = sample.Name
-							serie.Points = []metrics.Point{{Ts: sample.Timestamp, Value: sample.Value}}
-							serie.Tags = tagset.CompositeTagsFromSlice(w.metricBuffer.Copy())
-							serie.Host = sample.Host
-							serie.MType = mtype
-							// ignored by the intake when late but mimic dogstatsd traffic here anyway
-							serie.Interval = 10
-							w.seriesSink.Append(&serie)
-
-							w.taggerBuffer.Reset()
-							w.metricBuffer.Reset()
-							countProcessed++
 						}
+						flushBlockChan = trigger.blockChan
+						tlmNoAggFlush.Add(1)
+						expvarNoAggFlush.Add(1)
+						break mainloop // end `Serialize` call and trigger a flush to the forwarder
 
+					// receiving samples
+					case samples := <-w.samplesChan:
+						serializedSamples += w.streamSamples(samples, seriesSink)
 						lastStream = time.Now()
 
-						serializedSamples += countProcessed
-
-						tlmNoAggSamplesProcessedOk.Add(float64(countProcessed))
-						expvarNoAggSamplesProcessedOk.Add(int64(countProcessed))
-						tlmNoAggSamplesProcessedUnsupportedType.Add(float64(countUnsupportedType))
-						expvarNoAggSamplesProcessedUnsupportedType.Add(int64(countUnsupportedType))
-
 						if serializedSamples > w.maxMetricsPerPayload {
 							tlmNoAggFlush.Add(1)
 							break mainloop // end `Serialize` call and trigger a flush to the forwarder
 						}
 					}
 				}
+				w.sendOriginTelemetry(seriesSink)
 			}, func(serieSource metrics.SerieSource) {
 				sendIterableSeries(w.serializer, start, serieSource)
 			}, func(sketches metrics.SketchesSource) {
 				// noop: we do not support sketches in the no-agg pipeline.
 			})
 
+		if flushBlockChan != nil {
+			close(flushBlockChan)
+			flushBlockChan = nil
+		}
+
 		if stopped {
 			break
 		}
@@ -240,6 +257,90 @@ func (w *noAggregationStreamWorker) run() {
 	}
 }
 
+// streamSamples turns the samples into series appended to the series sink, and returns the number of samples
+// streamed. The samples of the unsupported metric types are discarded.
+func (w *noAggregationStreamWorker) streamSamples(samples metrics.MetricSampleBatch, series metrics.SerieSink) int {
+	log.Tracef("Streaming %d metrics from the no-aggregation pipeline", len(samples))
+	countProcessed := 0
+	countUnsupportedType := 0
+
+	for _, sample := range samples {
+		mtype, supported := metricSampleAPIType(sample)
+
+		if !supported {
+			if !w.logThrottling.ShouldThrottle() {
+				log.Warnf("Discarding unsupported metric sample in the no-aggregation pipeline for sample '%s', sample type '%s'", sample.Name, sample.Mtype.String())
+			}
+			countUnsupportedType++
+			continue
+		}
+
+		// enrich metric sample tags, the tagger and the metric tags being deduplicated like the
+		// tags of the contexts of the aggregated samples
+		sample.GetTags(w.taggerBuffer, w.metricBuffer)
+		w.taggerBuffer.SortUniq()
+		w.countOrigin()
+		w.metricBuffer.AppendHashlessAccumulator(w.taggerBuffer)
+		w.metricBuffer.SortUniq()
+
+		// turns this metric sample into a serie
+		var serie metrics.Serie
+		serie.Name = sample.Name
+		serie.Points = []metrics.Point{{Ts: sample.Timestamp, Value: sample.Value}}
+		serie.Tags = tagset.CompositeTagsFromSlice(w.metricBuffer.Copy())
+		serie.Host = sample.Host
+		serie.MType = mtype
+		serie.NoIndex = sample.NoIndex
+		// ignored by the intake when late but mimic dogstatsd traffic here anyway
+		serie.Interval = 10
+		series.Append(&serie)
+
+		w.taggerBuffer.Reset()
+		w.metricBuffer.Reset()
+		countProcessed++
+	}
+
+	tlmNoAggSamplesProcessedOk.Add(float64(countProcessed))
+	expvarNoAggSamplesProcessedOk.Add(int64(countProcessed))
+	tlmNoAggSamplesProcessedUnsupportedType.Add(float64(countUnsupportedType))
+	expvarNoAggSamplesProcessedUnsupportedType.Add(int64(countUnsupportedType))
+
+	return countProcessed
+}
+
+// countOrigin counts a sample for the origin of the tagger tags buffer, when the origin telemetry is enabled.
+func (w *noAggregationStreamWorker) countOrigin() {
+	if w.samplesByOrigin == nil {
+		return
+	}
+	key := strings.Join(w.taggerBuffer.Get(), ",")
+	origin, found := w.samplesByOrigin[key]
+	if !found {
+		origin = &originSamples{tags: w.taggerBuffer.Copy()}
+		w.samplesByOrigin[key] = origin
+	}
+	origin.count++
+}
+
+// sendOriginTelemetry appends to the series the number of samples streamed per origin since the last
+// flush, the counterpart of the contexts per origin of the aggregated samples.
+func (w *noAggregationStreamWorker) sendOriginTelemetry(series metrics.SerieSink) {
+	if len(w.samplesByOrigin) == 0 {
+		return
+	}
+	timestamp := float64(time.Now().Unix())
+	for key, origin := range w.samplesByOrigin {
+		series.Append(&metrics.Serie{
+			Name:   "datadog.agent.aggregator.dogstatsd_samples_by_origin",
+			Host:   w.hostname,
+			Tags:   tagset.NewCompositeTags([]string{"pipeline:no_aggregation"}, origin.tags),
+			MType:  metrics.APICountType,
+			Points: []metrics.Point{{Ts: timestamp, Value: float64(origin.count)}},
+		})
+		delete(w.samplesByOrigin, key)
+	}
+}
+
 // metricSampleAPIType returns the APIMetricType of the given sample, the second
 // return value informs the caller if the input type is supported by
 // the no-aggregation pipeline: APIMetricType only supports gauges, counts and rates.
@@ -0,0 +1,312 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package aggregator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DataDog/opentelemetry-mapping-go/pkg/quantile"
+
+	forwarder "github.com/DataDog/datadog-agent/comp/forwarder/defaultforwarder"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/config/resolver"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/serializer"
+	"github.com/DataDog/datadog-agent/pkg/tagset"
+	"github.com/DataDog/datadog-agent/pkg/telemetry"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+var tlmSecondaryFlush = telemetry.NewCounter("aggregator", "secondary_flush_series",
+	[]string{"state"}, "Number of series handled by the secondary flush pipeline")
+
+var tlmSecondaryFlushSketches = telemetry.NewCounter("aggregator", "secondary_flush_sketches",
+	[]string{"state"}, "Number of sketches handled by the secondary flush pipeline")
+
+// secondaryFlush is a second flush pipeline sending a copy of the flushed series and sketches to
+// another intake at its own interval, e.g. to validate a staging intake during a migration.
+//
+// The metrics flushed by the main pipeline are rolled up by context into buckets of the secondary
+// interval, and sent on every tick of the secondary interval through a dedicated forwarder, so that
+// an unavailable secondary intake never blocks the main pipeline: once the buffer holds maxSeries
+// contexts, the metrics of the new contexts are dropped.
+type secondaryFlush struct {
+	interval   time.Duration
+	maxSeries  int
+	forwarder  forwarder.Forwarder
+	serializer serializer.MetricSerializer
+
+	mu       sync.Mutex
+	series   *rollup[*metrics.Serie]
+	sketches *rollup[*metrics.SketchSeries]
+
+	stopChan chan struct{}
+	done     chan struct{}
+}
+
+// rollup holds the metrics of the contexts in the order they were first seen.
+type rollup[M any] struct {
+	metrics []M
+	index   map[string]int
+}
+
+func newRollup[M any]() *rollup[M] {
+	return &rollup[M]{index: make(map[string]int)}
+}
+
+func newSecondaryFlush(interval time.Duration, maxSeries int, fwd forwarder.Forwarder, s serializer.MetricSerializer) *secondaryFlush {
+	return &secondaryFlush{
+		interval:   interval,
+		maxSeries:  maxSeries,
+		forwarder:  fwd,
+		serializer: s,
+		series:     newRollup[*metrics.Serie](),
+		sketches:   newRollup[*metrics.SketchSeries](),
+		stopChan:   make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+}
+
+// newSecondaryFlushFromConfig returns the secondary flush pipeline configured with
+// `aggregator_secondary_flush`, or nil if it is disabled.
+func newSecondaryFlushFromConfig() (*secondaryFlush, error) {
+	if !config.Datadog.GetBool("aggregator_secondary_flush.enabled") {
+		return nil, nil
+	}
+
+	url := config.Datadog.GetString("aggregator_secondary_flush.dd_url")
+	apiKey := config.Datadog.GetString("aggregator_secondary_flush.api_key")
+	if url == "" || apiKey == "" {
+		return nil, fmt.Errorf("aggregator_secondary_flush.dd_url and aggregator_secondary_flush.api_key are required")
+	}
+	interval := config.Datadog.GetDuration("aggregator_secondary_flush.interval")
+	if interval < time.Second {
+		return nil, fmt.Errorf("aggregator_secondary_flush.interval must be at least 1s, got %s", interval)
+	}
+
+	options := forwarder.NewOptionsWithResolvers(config.Datadog, resolver.NewSingleDomainResolvers(map[string][]string{url: {apiKey}}))
+	options.DisableAPIKeyChecking = true
+	fwd := forwarder.NewDefaultForwarder(config.Datadog, options)
+
+	log.Infof("Sending a copy of the flushed series and sketches to %s every %s", url, interval)
+	return newSecondaryFlush(interval, config.Datadog.GetInt("aggregator_secondary_flush.max_buffered_series"), fwd, serializer.NewSerializer(fwd, nil)), nil
+}
+
+func (f *secondaryFlush) run(startForwarder bool) {
+	defer close(f.done)
+
+	if startForwarder {
+		if err := f.forwarder.Start(); err != nil {
+			log.Errorf("Unable to start the secondary flush forwarder: %v", err)
+		}
+	}
+
+	ticker := time.NewTicker(f.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-f.stopChan:
+			return
+		case <-ticker.C:
+			f.flush()
+		}
+	}
+}
+
+// stop flushes the buffered metrics and stops the pipeline.
+func (f *secondaryFlush) stop(stopForwarder bool) {
+	close(f.stopChan)
+	<-f.done
+	f.flush()
+	if stopForwarder {
+		f.forwarder.Stop()
+	}
+}
+
+// intervalSeconds returns the secondary interval in seconds, the interval of the rolled up metrics.
+func (f *secondaryFlush) intervalSeconds() int64 {
+	return int64(f.interval / time.Second)
+}
+
+// bucket returns the timestamp of the secondary interval bucket holding ts.
+func (f *secondaryFlush) bucket(ts int64) int64 {
+	return ts - ts%f.intervalSeconds()
+}
+
+// add rolls up the series into the buffered series of their context until the next secondary flush.
+// The points of a context falling into the same secondary interval are merged: counts are summed,
+// rates are averaged over the secondary interval and gauges keep their last value.
+func (f *secondaryFlush) add(series []*metrics.Serie) {
+	if len(series) == 0 {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	intervalSeconds := f.intervalSeconds()
+	dropped := 0
+	for _, serie := range series {
+		key := secondaryFlushContextKey(serie.Name, serie.Host, serie.Device, serie.MType.String(), serie.Tags)
+		i, ok := f.series.index[key]
+		if !ok {
+			if len(f.series.metrics) >= f.maxSeries {
+				dropped++
+				continue
+			}
+			rolledUp := *serie
+			rolledUp.Points = nil
+			rolledUp.Interval = intervalSeconds
+			i = len(f.series.metrics)
+			f.series.index[key] = i
+			f.series.metrics = append(f.series.metrics, &rolledUp)
+		}
+
+		rolledUp := f.series.metrics[i]
+		for _, point := range serie.Points {
+			value := point.Value
+			if serie.MType == metrics.APIRateType && serie.Interval > 0 && serie.Interval < intervalSeconds {
+				// the rate is per second over the interval of the serie
+				value = value * float64(serie.Interval) / float64(intervalSeconds)
+			}
+
+			ts := float64(f.bucket(int64(point.Ts)))
+			last := len(rolledUp.Points) - 1
+			if last < 0 || rolledUp.Points[last].Ts != ts {
+				rolledUp.Points = append(rolledUp.Points, metrics.Point{Ts: ts, Value: value})
+				continue
+			}
+			switch serie.MType {
+			case metrics.APICountType, metrics.APIRateType:
+				rolledUp.Points[last].Value += value
+			default:
+				rolledUp.Points[last].Value = value
+			}
+		}
+	}
+	if dropped > 0 {
+		tlmSecondaryFlush.Add(float64(dropped), "dropped")
+	}
+}
+
+// addSketches merges the sketches into the buffered sketches of their context until the next
+// secondary flush, one sketch per secondary interval.
+func (f *secondaryFlush) addSketches(sketches []*metrics.SketchSeries) {
+	if len(sketches) == 0 {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	dropped := 0
+	for _, sketchSeries := range sketches {
+		key := secondaryFlushContextKey(sketchSeries.Name, sketchSeries.Host, "", "", sketchSeries.Tags)
+		i, ok := f.sketches.index[key]
+		if !ok {
+			if len(f.sketches.metrics) >= f.maxSeries {
+				dropped++
+				continue
+			}
+			rolledUp := *sketchSeries
+			rolledUp.Points = nil
+			rolledUp.Interval = f.intervalSeconds()
+			i = len(f.sketches.metrics)
+			f.sketches.index[key] = i
+			f.sketches.metrics = append(f.sketches.metrics, &rolledUp)
+		}
+
+		rolledUp := f.sketches.metrics[i]
+		for _, point := range sketchSeries.Points {
+			ts := f.bucket(point.Ts)
+			last := len(rolledUp.Points) - 1
+			if last < 0 || rolledUp.Points[last].Ts != ts {
+				rolledUp.Points = append(rolledUp.Points, metrics.SketchPoint{Ts: ts, Sketch: point.Sketch})
+				continue
+			}
+			rolledUp.Points[last].Sketch.Merge(quantile.Default(), point.Sketch)
+		}
+	}
+	if dropped > 0 {
+		tlmSecondaryFlushSketches.Add(float64(dropped), "dropped")
+	}
+}
+
+// secondaryFlushContextKey returns the key of the context of a metric, its tags being sorted.
+func secondaryFlushContextKey(name, host, device, mtype string, tags tagset.CompositeTags) string {
+	sortedTags := make([]string, 0, tags.Len())
+	tags.ForEach(func(tag string) {
+		sortedTags = append(sortedTags, tag)
+	})
+	sort.Strings(sortedTags)
+	return strings.Join(append([]string{name, host, device, mtype}, sortedTags...), "\x00")
+}
+
+// flush sends the buffered series and sketches to the secondary intake.
+func (f *secondaryFlush) flush() {
+	f.mu.Lock()
+	series := f.series.metrics
+	sketches := f.sketches.metrics
+	f.series = newRollup[*metrics.Serie]()
+	f.sketches = newRollup[*metrics.SketchSeries]()
+	f.mu.Unlock()
+
+	if len(series) == 0 && len(sketches) == 0 {
+		return
+	}
+
+	var iterableSeries *metrics.IterableSeries
+	if len(series) > 0 {
+		iterableSeries = metrics.NewIterableSeries(func(*metrics.Serie) {}, len(series), len(series))
+	}
+	var iterableSketches *metrics.IterableSketches
+	if len(sketches) > 0 {
+		iterableSketches = metrics.NewIterableSketches(func(*metrics.SketchSeries) {}, len(sketches), len(sketches))
+	}
+	metrics.Serialize(
+		iterableSeries,
+		iterableSketches,
+		func(seriesSink metrics.SerieSink, sketchesSink metrics.SketchesSink) {
+			for _, serie := range series {
+				seriesSink.Append(serie)
+			}
+			for _, sketch := range sketches {
+				sketchesSink.Append(sketch)
+			}
+		}, func(serieSource metrics.SerieSource) {
+			if err := f.serializer.SendIterableSeries(serieSource); err != nil {
+				log.Debugf("Unable to send %d series to the secondary intake: %v", len(series), err)
+				tlmSecondaryFlush.Add(float64(len(series)), "error")
+				return
+			}
+			tlmSecondaryFlush.Add(float64(len(series)), "ok")
+		}, func(sketchesSource metrics.SketchesSource) {
+			if err := f.serializer.SendSketch(sketchesSource); err != nil {
+				log.Debugf("Unable to send %d sketches to the secondary intake: %v", len(sketches), err)
+				tlmSecondaryFlushSketches.Add(float64(len(sketches)), "error")
+				return
+			}
+			tlmSecondaryFlushSketches.Add(float64(len(sketches)), "ok")
+		})
+}
+
+// sink returns a SerieSink appending the series to serieSink and keeping a copy
+// of them. The copies are handed to the secondary pipeline by calling flush.
+func (f *secondaryFlush) sink(serieSink metrics.SerieSink) *teeSink[*metrics.Serie, *metrics.Serie] {
+	return newTeeSink[*metrics.Serie](serieSink, func(serie *metrics.Serie) (*metrics.Serie, bool) {
+		return copySerie(serie), true
+	}, f.add)
+}
+
+// sketchesSink returns a SketchesSink appending the sketches to sketchesSink and keeping a copy
+// of them. The copies are handed to the secondary pipeline by calling flush.
+func (f *secondaryFlush) sketchesSink(sketchesSink metrics.SketchesSink) *teeSink[*metrics.SketchSeries, *metrics.SketchSeries] {
+	return newTeeSink[*metrics.SketchSeries](sketchesSink, func(sketchSeries *metrics.SketchSeries) (*metrics.SketchSeries, bool) {
+		return copySketchSeries(sketchSeries), true
+	}, f.addSketches)
+}
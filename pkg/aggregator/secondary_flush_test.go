@@ -0,0 +1,187 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build test
+// +build test
+
+package aggregator
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DataDog/opentelemetry-mapping-go/pkg/quantile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	forwarder "github.com/DataDog/datadog-agent/comp/forwarder/defaultforwarder"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/serializer"
+	"github.com/DataDog/datadog-agent/pkg/tagset"
+)
+
+type failingSerializerIterableSerie struct {
+	serializer.MockSerializer
+}
+
+func (s *failingSerializerIterableSerie) SendIterableSeries(seriesSource metrics.SerieSource) error {
+	return errors.New("intake unavailable")
+}
+
+// secondaryFlushSerializer collects the series and the sketches sent to the secondary intake.
+type secondaryFlushSerializer struct {
+	MockSerializerIterableSerie
+	sketches []*metrics.SketchSeries
+}
+
+func (s *secondaryFlushSerializer) SendSketch(sketchesSource metrics.SketchesSource) error {
+	for sketchesSource.MoveNext() {
+		s.sketches = append(s.sketches, sketchesSource.Current())
+	}
+	return nil
+}
+
+func TestSecondaryFlushSerieSink(t *testing.T) {
+	s := &MockSerializerIterableSerie{}
+	secondary := newSecondaryFlush(time.Hour, 10, forwarder.NoopForwarder{}, s)
+
+	var series metrics.Series
+	sink := secondary.sink(&series)
+	tags := tagset.CompositeTagsFromSlice([]string{"env:prod"})
+	serie := &metrics.Serie{
+		Name:     "my.metric",
+		Points:   []metrics.Point{{Ts: 1657099120, Value: 1}},
+		Tags:     tags,
+		Host:     "my-host",
+		MType:    metrics.APIGaugeType,
+		Interval: 10,
+	}
+	sink.Append(serie)
+	sink.Append(&metrics.Serie{Name: "other.metric", Points: []metrics.Point{{Ts: 1657099120, Value: 2}}})
+	sink.flush()
+
+	// every serie is still sent to the wrapped sink
+	require.Len(t, series, 2)
+	assert.Same(t, serie, series[0])
+
+	// the series are only sent on the next secondary flush
+	assert.Empty(t, s.series)
+	serie.Points[0].Value = 42
+
+	secondary.flush()
+	require.Len(t, s.series, 2)
+	assert.NotSame(t, serie, s.series[0])
+	assert.Equal(t, "my.metric", s.series[0].Name)
+	// the points are rolled up into the buckets of the secondary interval
+	assert.Equal(t, []metrics.Point{{Ts: 1657098000, Value: 1}}, s.series[0].Points)
+	assert.Equal(t, int64(3600), s.series[0].Interval)
+	assert.Equal(t, []string{"env:prod"}, s.series[0].Tags.UnsafeToReadOnlySliceString())
+	assert.Equal(t, "my-host", s.series[0].Host)
+	assert.Equal(t, "other.metric", s.series[1].Name)
+
+	// the buffer is emptied by a flush
+	s.series = nil
+	secondary.flush()
+	assert.Empty(t, s.series)
+}
+
+func TestSecondaryFlushBufferLimit(t *testing.T) {
+	s := &MockSerializerIterableSerie{}
+	secondary := newSecondaryFlush(time.Hour, 3, forwarder.NoopForwarder{}, s)
+
+	secondary.add([]*metrics.Serie{{Name: "a"}, {Name: "b"}})
+	secondary.add([]*metrics.Serie{{Name: "c"}, {Name: "d"}})
+	secondary.add([]*metrics.Serie{{Name: "e"}})
+
+	secondary.flush()
+	require.Len(t, s.series, 3)
+	assert.Equal(t, "c", s.series[2].Name)
+}
+
+func TestSecondaryFlushErrorIsolation(t *testing.T) {
+	secondary := newSecondaryFlush(time.Hour, 10, forwarder.NoopForwarder{}, &failingSerializerIterableSerie{})
+
+	var series metrics.Series
+	sink := secondary.sink(&series)
+	sink.Append(&metrics.Serie{Name: "my.metric"})
+	sink.flush()
+
+	// a failing secondary intake doesn't affect the main pipeline and drops the buffered series
+	assert.Len(t, series, 1)
+	secondary.flush()
+	assert.Empty(t, secondary.series.metrics)
+}
+
+func TestSecondaryFlushStop(t *testing.T) {
+	s := &MockSerializerIterableSerie{}
+	secondary := newSecondaryFlush(time.Hour, 10, forwarder.NoopForwarder{}, s)
+	go secondary.run(false)
+
+	secondary.add([]*metrics.Serie{{Name: "my.metric"}})
+	secondary.stop(false)
+
+	// the buffered series are flushed on stop
+	require.Len(t, s.series, 1)
+}
+
+func TestSecondaryFlushRollup(t *testing.T) {
+	s := &MockSerializerIterableSerie{}
+	secondary := newSecondaryFlush(time.Minute, 10, forwarder.NoopForwarder{}, s)
+
+	for _, ts := range []float64{1657099200, 1657099215, 1657099230, 1657099245, 1657099260} {
+		secondary.add([]*metrics.Serie{
+			{Name: "my.count", Points: []metrics.Point{{Ts: ts, Value: 2}}, MType: metrics.APICountType, Interval: 15},
+			{Name: "my.rate", Points: []metrics.Point{{Ts: ts, Value: 4}}, MType: metrics.APIRateType, Interval: 15},
+			{Name: "my.gauge", Points: []metrics.Point{{Ts: ts, Value: ts}}, MType: metrics.APIGaugeType, Interval: 15},
+			// the contexts are identified by their sorted tags
+			{Name: "my.count", Points: []metrics.Point{{Ts: ts, Value: 1}}, MType: metrics.APICountType, Interval: 15,
+				Tags: tagset.CompositeTagsFromSlice([]string{"b:2", "a:1"})},
+			{Name: "my.count", Points: []metrics.Point{{Ts: ts, Value: 1}}, MType: metrics.APICountType, Interval: 15,
+				Tags: tagset.CompositeTagsFromSlice([]string{"a:1", "b:2"})},
+		})
+	}
+	secondary.flush()
+
+	require.Len(t, s.series, 4)
+	assert.Equal(t, []metrics.Point{{Ts: 1657099200, Value: 8}, {Ts: 1657099260, Value: 2}}, s.series[0].Points)
+	assert.Equal(t, []metrics.Point{{Ts: 1657099200, Value: 4}, {Ts: 1657099260, Value: 1}}, s.series[1].Points)
+	assert.Equal(t, []metrics.Point{{Ts: 1657099200, Value: 1657099245}, {Ts: 1657099260, Value: 1657099260}}, s.series[2].Points)
+	assert.Equal(t, []metrics.Point{{Ts: 1657099200, Value: 8}, {Ts: 1657099260, Value: 2}}, s.series[3].Points)
+	for _, serie := range s.series {
+		assert.Equal(t, int64(60), serie.Interval)
+	}
+}
+
+func TestSecondaryFlushSketchesSink(t *testing.T) {
+	s := &secondaryFlushSerializer{}
+	secondary := newSecondaryFlush(time.Minute, 10, forwarder.NoopForwarder{}, s)
+
+	var sketches metrics.SketchSeriesList
+	sink := secondary.sketchesSink(&sketches)
+	for i, ts := range []int64{1657099200, 1657099215} {
+		sketch := &quantile.Sketch{}
+		sketch.Insert(quantile.Default(), float64(i+1))
+		sink.Append(&metrics.SketchSeries{
+			Name:     "my.distribution",
+			Tags:     tagset.CompositeTagsFromSlice([]string{"env:prod"}),
+			Interval: 15,
+			Points:   []metrics.SketchPoint{{Ts: ts, Sketch: sketch}},
+		})
+	}
+	sink.flush()
+
+	// every sketch is still sent to the wrapped sink, untouched
+	require.Len(t, sketches, 2)
+	assert.Equal(t, int64(1), sketches[0].Points[0].Sketch.Basic.Cnt)
+
+	secondary.flush()
+	require.Len(t, s.sketches, 1)
+	assert.Equal(t, int64(60), s.sketches[0].Interval)
+	require.Len(t, s.sketches[0].Points, 1)
+	assert.Equal(t, int64(1657099200), s.sketches[0].Points[0].Ts)
+	assert.Equal(t, int64(2), s.sketches[0].Points[0].Sketch.Basic.Cnt)
+	assert.Equal(t, 3.0, s.sketches[0].Points[0].Sketch.Basic.Sum)
+}
@@ -0,0 +1,69 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package aggregator
+
+import (
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/tagset"
+)
+
+// teeSink is a SerieSink or a SketchesSink keeping a copy of the metrics before appending them to
+// the sink it wraps, which owns the metrics from then on: the serializer consuming the wrapped sink
+// may modify a metric as soon as it is appended. The copies are handed to send by calling flush.
+// As the sink it wraps, it doesn't support concurrent usage.
+type teeSink[M any, T any] struct {
+	sink interface{ Append(M) }
+	// copy returns the copy of the metric to keep, and false when the metric isn't kept
+	copy   func(M) (T, bool)
+	send   func([]T)
+	copies []T
+}
+
+func newTeeSink[M any, T any](sink interface{ Append(M) }, copy func(M) (T, bool), send func([]T)) *teeSink[M, T] {
+	return &teeSink[M, T]{sink: sink, copy: copy, send: send}
+}
+
+// Append implements the SerieSink and SketchesSink interfaces.
+func (s *teeSink[M, T]) Append(metric M) {
+	if copied, ok := s.copy(metric); ok {
+		s.copies = append(s.copies, copied)
+	}
+	s.sink.Append(metric)
+}
+
+// flush sends the copies kept since the last flush.
+func (s *teeSink[M, T]) flush() {
+	s.send(s.copies)
+	s.copies = nil
+}
+
+// copySerie returns a deep copy of the serie, sharing nothing with it.
+func copySerie(serie *metrics.Serie) *metrics.Serie {
+	copied := *serie
+	copied.Points = append([]metrics.Point(nil), serie.Points...)
+	tags := make([]string, 0, serie.Tags.Len())
+	serie.Tags.ForEach(func(tag string) {
+		tags = append(tags, tag)
+	})
+	copied.Tags = tagset.CompositeTagsFromSlice(tags)
+	copied.Resources = append([]metrics.Resource(nil), serie.Resources...)
+	return &copied
+}
+
+// copySketchSeries returns a deep copy of the sketch series, sharing nothing with it.
+func copySketchSeries(sketchSeries *metrics.SketchSeries) *metrics.SketchSeries {
+	copied := *sketchSeries
+	copied.Points = make([]metrics.SketchPoint, 0, len(sketchSeries.Points))
+	for _, point := range sketchSeries.Points {
+		copied.Points = append(copied.Points, metrics.SketchPoint{Ts: point.Ts, Sketch: point.Sketch.Copy()})
+	}
+	tags := make([]string, 0, sketchSeries.Tags.Len())
+	sketchSeries.Tags.ForEach(func(tag string) {
+		tags = append(tags, tag)
+	})
+	copied.Tags = tagset.CompositeTagsFromSlice(tags)
+	return &copied
+}
@@ -0,0 +1,57 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package aggregator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/tagset"
+)
+
+// mutatingSerieSink modifies the series once appended, as the serializer does.
+type mutatingSerieSink struct {
+	metrics.Series
+}
+
+func (s *mutatingSerieSink) Append(serie *metrics.Serie) {
+	serie.Name = "mutated"
+	serie.Points[0].Value = -1
+	serie.Tags = tagset.CompositeTagsFromSlice([]string{"mutated"})
+	s.Series = append(s.Series, serie)
+}
+
+func TestTeeSinkCopiesBeforeAppend(t *testing.T) {
+	var sent [][]*metrics.Serie
+	inner := &mutatingSerieSink{}
+	sink := newTeeSink[*metrics.Serie](inner, func(serie *metrics.Serie) (*metrics.Serie, bool) {
+		return copySerie(serie), serie.Name != "skipped"
+	}, func(series []*metrics.Serie) {
+		sent = append(sent, series)
+	})
+
+	sink.Append(&metrics.Serie{
+		Name:   "my.metric",
+		Points: []metrics.Point{{Ts: 1657099120, Value: 1}},
+		Tags:   tagset.CompositeTagsFromSlice([]string{"env:prod"}),
+	})
+	sink.Append(&metrics.Serie{Name: "skipped", Points: []metrics.Point{{Ts: 1657099120, Value: 2}}})
+	sink.flush()
+	sink.flush()
+
+	// every serie is appended to the wrapped sink
+	require.Len(t, inner.Series, 2)
+	require.Len(t, sent, 2)
+	require.Len(t, sent[0], 1)
+	assert.Equal(t, "my.metric", sent[0][0].Name)
+	assert.Equal(t, []metrics.Point{{Ts: 1657099120, Value: 1}}, sent[0][0].Points)
+	assert.Equal(t, []string{"env:prod"}, sent[0][0].Tags.UnsafeToReadOnlySliceString())
+	// the copies are only sent once
+	assert.Empty(t, sent[1])
+}
@@ -0,0 +1,296 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package aggregator
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	ddstatsd "github.com/DataDog/datadog-go/v5/statsd"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/pmetric/pmetricotlp"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/telemetry"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	seriesMirrorProtocolStatsd = "statsd"
+	seriesMirrorProtocolOTLP   = "otlp"
+
+	// seriesMirrorQueueSize is the number of flushes waiting to be exported. The batches
+	// of the next flushes are dropped when the queue is full.
+	seriesMirrorQueueSize = 4
+	seriesMirrorTimeout   = 5 * time.Second
+)
+
+var tlmSeriesMirror = telemetry.NewCounter("aggregator", "series_mirror",
+	[]string{"state"}, "Number of series mirrored to the local endpoint")
+
+// mirroredSerie is a copy of a flushed serie, made so that the mirror doesn't
+// share the series sent to the serializer.
+type mirroredSerie struct {
+	name     string
+	points   []metrics.Point
+	tags     []string
+	host     string
+	mtype    metrics.APIMetricType
+	interval int64
+}
+
+// seriesExporter sends mirrored series to a local endpoint.
+type seriesExporter interface {
+	export(series []*mirroredSerie) error
+	close()
+}
+
+// seriesMirror forwards the flushed series whose name matches one of its prefixes
+// to a secondary endpoint, e.g. a local statsd server or OpenTelemetry collector.
+// Exports run in their own routine so that a slow endpoint never delays a flush.
+type seriesMirror struct {
+	prefixes []string
+	exporter seriesExporter
+	batches  chan []*mirroredSerie
+	done     chan struct{}
+}
+
+func newSeriesMirror(prefixes []string, exporter seriesExporter) *seriesMirror {
+	return &seriesMirror{
+		prefixes: prefixes,
+		exporter: exporter,
+		batches:  make(chan []*mirroredSerie, seriesMirrorQueueSize),
+		done:     make(chan struct{}),
+	}
+}
+
+// newSeriesMirrorFromConfig returns the series mirror configured with `aggregator_series_mirror`,
+// or nil if it is disabled.
+func newSeriesMirrorFromConfig() (*seriesMirror, error) {
+	if !config.Datadog.GetBool("aggregator_series_mirror.enabled") {
+		return nil, nil
+	}
+
+	prefixes := config.Datadog.GetStringSlice("aggregator_series_mirror.prefixes")
+	if len(prefixes) == 0 {
+		return nil, fmt.Errorf("aggregator_series_mirror.prefixes is empty")
+	}
+
+	endpoint := config.Datadog.GetString("aggregator_series_mirror.endpoint")
+	var exporter seriesExporter
+	switch protocol := config.Datadog.GetString("aggregator_series_mirror.protocol"); protocol {
+	case seriesMirrorProtocolStatsd:
+		var err error
+		if exporter, err = newStatsdSeriesExporter(endpoint); err != nil {
+			return nil, err
+		}
+	case seriesMirrorProtocolOTLP:
+		exporter = &otlpSeriesExporter{
+			endpoint: endpoint,
+			client:   &http.Client{Timeout: seriesMirrorTimeout},
+		}
+	default:
+		return nil, fmt.Errorf("unknown aggregator_series_mirror.protocol %q, expected %q or %q", protocol, seriesMirrorProtocolStatsd, seriesMirrorProtocolOTLP)
+	}
+
+	log.Infof("Mirroring the series matching %v to %s endpoint %s", prefixes, config.Datadog.GetString("aggregator_series_mirror.protocol"), endpoint)
+	return newSeriesMirror(prefixes, exporter), nil
+}
+
+func (m *seriesMirror) run() {
+	defer close(m.done)
+	for batch := range m.batches {
+		if err := m.exporter.export(batch); err != nil {
+			log.Debugf("Unable to mirror %d series: %v", len(batch), err)
+			tlmSeriesMirror.Add(float64(len(batch)), "error")
+			continue
+		}
+		tlmSeriesMirror.Add(float64(len(batch)), "ok")
+	}
+}
+
+// stop waits for the pending batches to be exported and releases the exporter.
+func (m *seriesMirror) stop() {
+	close(m.batches)
+	<-m.done
+	m.exporter.close()
+}
+
+func (m *seriesMirror) matches(name string) bool {
+	for _, prefix := range m.prefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// sink returns a SerieSink appending the series to serieSink and keeping a copy
+// of the matching ones. The copies are sent to the mirror by calling flush.
+func (m *seriesMirror) sink(serieSink metrics.SerieSink) *teeSink[*metrics.Serie, *mirroredSerie] {
+	return newTeeSink[*metrics.Serie](serieSink, m.copy, m.send)
+}
+
+// copy returns a copy of the serie when it matches one of the prefixes.
+func (m *seriesMirror) copy(serie *metrics.Serie) (*mirroredSerie, bool) {
+	if !m.matches(serie.Name) {
+		return nil, false
+	}
+	mirrored := &mirroredSerie{
+		name:     serie.Name,
+		points:   append([]metrics.Point(nil), serie.Points...),
+		tags:     make([]string, 0, serie.Tags.Len()),
+		host:     serie.Host,
+		mtype:    serie.MType,
+		interval: serie.Interval,
+	}
+	serie.Tags.ForEach(func(tag string) {
+		mirrored.tags = append(mirrored.tags, tag)
+	})
+	return mirrored, true
+}
+
+func (m *seriesMirror) send(batch []*mirroredSerie) {
+	if len(batch) == 0 {
+		return
+	}
+	select {
+	case m.batches <- batch:
+	default:
+		tlmSeriesMirror.Add(float64(len(batch)), "dropped")
+	}
+}
+
+// statsdSeriesExporter sends the series to a statsd server. Only the last point of
+// each serie is sent, as statsd doesn't support timestamps.
+type statsdSeriesExporter struct {
+	client ddstatsd.ClientInterface
+}
+
+func newStatsdSeriesExporter(address string) (*statsdSeriesExporter, error) {
+	// the series are already aggregated, they are sent as is on each export
+	client, err := ddstatsd.New(address, ddstatsd.WithoutTelemetry(), ddstatsd.WithoutClientSideAggregation())
+	if err != nil {
+		return nil, fmt.Errorf("unable to create the statsd client: %w", err)
+	}
+	return &statsdSeriesExporter{client: client}, nil
+}
+
+func (e *statsdSeriesExporter) export(series []*mirroredSerie) error {
+	var err error
+	for _, serie := range series {
+		if len(serie.points) == 0 {
+			continue
+		}
+		value := serie.points[len(serie.points)-1].Value
+		tags := serie.tags
+		if serie.host != "" {
+			tags = append(tags, "host:"+serie.host)
+		}
+
+		var sendErr error
+		switch serie.mtype {
+		case metrics.APICountType:
+			sendErr = e.client.Count(serie.name, int64(math.Round(value)), tags, 1)
+		default:
+			// rates are already normalized per second, they are sent as gauges
+			sendErr = e.client.Gauge(serie.name, value, tags, 1)
+		}
+		if sendErr != nil {
+			err = sendErr
+		}
+	}
+	if flushErr := e.client.Flush(); flushErr != nil {
+		return flushErr
+	}
+	return err
+}
+
+func (e *statsdSeriesExporter) close() {
+	if err := e.client.Close(); err != nil {
+		log.Debugf("Unable to close the series mirror statsd client: %v", err)
+	}
+}
+
+// otlpSeriesExporter sends the series to an OTLP/HTTP endpoint, e.g. http://localhost:4318/v1/metrics.
+type otlpSeriesExporter struct {
+	endpoint string
+	client   *http.Client
+}
+
+func (e *otlpSeriesExporter) export(series []*mirroredSerie) error {
+	body, err := pmetricotlp.NewExportRequestFromMetrics(seriesToOTLP(series)).MarshalProto()
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected response status %s", resp.Status)
+	}
+	return nil
+}
+
+func (e *otlpSeriesExporter) close() {
+	e.client.CloseIdleConnections()
+}
+
+// seriesToOTLP converts the series to OTLP metrics: counts are mapped to delta sums,
+// gauges and rates to gauges. Tags are mapped to attributes.
+func seriesToOTLP(series []*mirroredSerie) pmetric.Metrics {
+	md := pmetric.NewMetrics()
+	ms := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics()
+
+	for _, serie := range series {
+		m := ms.AppendEmpty()
+		m.SetName(serie.name)
+
+		var dps pmetric.NumberDataPointSlice
+		if serie.mtype == metrics.APICountType {
+			sum := m.SetEmptySum()
+			sum.SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+			dps = sum.DataPoints()
+		} else {
+			dps = m.SetEmptyGauge().DataPoints()
+		}
+
+		for _, point := range serie.points {
+			dp := dps.AppendEmpty()
+			ts := time.Unix(0, int64(point.Ts*float64(time.Second)))
+			dp.SetTimestamp(pcommon.NewTimestampFromTime(ts))
+			if serie.mtype == metrics.APICountType && serie.interval > 0 {
+				dp.SetStartTimestamp(pcommon.NewTimestampFromTime(ts.Add(-time.Duration(serie.interval) * time.Second)))
+			}
+			dp.SetDoubleValue(point.Value)
+
+			attributes := dp.Attributes()
+			for _, tag := range serie.tags {
+				key, value, _ := strings.Cut(tag, ":")
+				attributes.PutStr(key, value)
+			}
+			if serie.host != "" {
+				attributes.PutStr("host", serie.host)
+			}
+		}
+	}
+	return md
+}
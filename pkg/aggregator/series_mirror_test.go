@@ -0,0 +1,185 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package aggregator
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/pmetric/pmetricotlp"
+
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/tagset"
+)
+
+type fakeSeriesExporter struct {
+	batches chan []*mirroredSerie
+	closed  bool
+}
+
+func (e *fakeSeriesExporter) export(series []*mirroredSerie) error {
+	e.batches <- series
+	return nil
+}
+
+func (e *fakeSeriesExporter) close() {
+	e.closed = true
+}
+
+func TestMirrorSerieSink(t *testing.T) {
+	exporter := &fakeSeriesExporter{batches: make(chan []*mirroredSerie, 1)}
+	mirror := newSeriesMirror([]string{"datadog.agent.", "datadog.dogstatsd."}, exporter)
+	go mirror.run()
+
+	var series metrics.Series
+	sink := mirror.sink(&series)
+	sink.Append(&metrics.Serie{
+		Name:     "datadog.agent.running",
+		Points:   []metrics.Point{{Ts: 1657099120, Value: 1}},
+		Tags:     tagset.CompositeTagsFromSlice([]string{"version:7.42.0"}),
+		Host:     "my-host",
+		MType:    metrics.APIGaugeType,
+		Interval: 15,
+	})
+	sink.Append(&metrics.Serie{Name: "system.cpu.user", Points: []metrics.Point{{Ts: 1657099120, Value: 2}}})
+	sink.Append(&metrics.Serie{Name: "datadog.dogstatsd.packets", Points: []metrics.Point{{Ts: 1657099120, Value: 3}}, MType: metrics.APICountType})
+	sink.flush()
+
+	// every serie is still sent to the wrapped sink
+	require.Len(t, series, 3)
+
+	select {
+	case batch := <-exporter.batches:
+		require.Len(t, batch, 2)
+		assert.Equal(t, &mirroredSerie{
+			name:     "datadog.agent.running",
+			points:   []metrics.Point{{Ts: 1657099120, Value: 1}},
+			tags:     []string{"version:7.42.0"},
+			host:     "my-host",
+			mtype:    metrics.APIGaugeType,
+			interval: 15,
+		}, batch[0])
+		assert.Equal(t, "datadog.dogstatsd.packets", batch[1].name)
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "the series were not exported")
+	}
+
+	// nothing is sent when no serie matches
+	sink.Append(&metrics.Serie{Name: "system.cpu.user"})
+	sink.flush()
+
+	mirror.stop()
+	assert.True(t, exporter.closed)
+	assert.Len(t, exporter.batches, 0)
+}
+
+func TestSeriesToOTLP(t *testing.T) {
+	md := seriesToOTLP([]*mirroredSerie{
+		{
+			name:   "datadog.agent.running",
+			points: []metrics.Point{{Ts: 1657099120, Value: 1}},
+			tags:   []string{"version:7.42.0", "standalone"},
+			host:   "my-host",
+			mtype:  metrics.APIGaugeType,
+		},
+		{
+			name:     "datadog.dogstatsd.packets",
+			points:   []metrics.Point{{Ts: 1657099120, Value: 30}},
+			mtype:    metrics.APICountType,
+			interval: 10,
+		},
+	})
+
+	ms := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	require.Equal(t, 2, ms.Len())
+
+	gauge := ms.At(0)
+	assert.Equal(t, "datadog.agent.running", gauge.Name())
+	require.Equal(t, pmetric.MetricTypeGauge, gauge.Type())
+	dp := gauge.Gauge().DataPoints().At(0)
+	assert.Equal(t, 1.0, dp.DoubleValue())
+	assert.Equal(t, time.Unix(1657099120, 0).UTC(), dp.Timestamp().AsTime())
+	assert.Equal(t, map[string]interface{}{"version": "7.42.0", "standalone": "", "host": "my-host"}, dp.Attributes().AsRaw())
+
+	sum := ms.At(1)
+	assert.Equal(t, "datadog.dogstatsd.packets", sum.Name())
+	require.Equal(t, pmetric.MetricTypeSum, sum.Type())
+	assert.Equal(t, pmetric.AggregationTemporalityDelta, sum.Sum().AggregationTemporality())
+	dp = sum.Sum().DataPoints().At(0)
+	assert.Equal(t, 30.0, dp.DoubleValue())
+	assert.Equal(t, time.Unix(1657099110, 0).UTC(), dp.StartTimestamp().AsTime())
+}
+
+func TestOTLPSeriesExporter(t *testing.T) {
+	received := make(chan pmetric.Metrics, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/x-protobuf", r.Header.Get("Content-Type"))
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		req := pmetricotlp.NewExportRequest()
+		require.NoError(t, req.UnmarshalProto(body))
+		received <- req.Metrics()
+	}))
+	defer server.Close()
+
+	exporter := &otlpSeriesExporter{endpoint: server.URL, client: server.Client()}
+	defer exporter.close()
+
+	err := exporter.export([]*mirroredSerie{{name: "datadog.agent.running", points: []metrics.Point{{Ts: 1657099120, Value: 1}}}})
+	require.NoError(t, err)
+
+	md := <-received
+	assert.Equal(t, 1, md.DataPointCount())
+	assert.Equal(t, "datadog.agent.running", md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Name())
+}
+
+func TestStatsdSeriesExporter(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	exporter, err := newStatsdSeriesExporter(conn.LocalAddr().String())
+	require.NoError(t, err)
+	defer exporter.close()
+
+	err = exporter.export([]*mirroredSerie{
+		{
+			name:   "datadog.agent.running",
+			points: []metrics.Point{{Ts: 1657099110, Value: 0}, {Ts: 1657099120, Value: 1}},
+			tags:   []string{"version:7.42.0"},
+			host:   "my-host",
+			mtype:  metrics.APIGaugeType,
+		},
+		{
+			name:   "datadog.dogstatsd.packets",
+			points: []metrics.Point{{Ts: 1657099120, Value: 30}},
+			mtype:  metrics.APICountType,
+		},
+	})
+	require.NoError(t, err)
+
+	// the metrics may be split across several packets depending on the client's buffers
+	var lines []string
+	buf := make([]byte, 1024)
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	for len(lines) < 2 {
+		n, _, err := conn.ReadFrom(buf)
+		require.NoError(t, err)
+		lines = append(lines, strings.Split(strings.TrimSpace(string(buf[:n])), "\n")...)
+	}
+	assert.ElementsMatch(t, []string{
+		"datadog.agent.running:1|g|#version:7.42.0,host:my-host",
+		"datadog.dogstatsd.packets:30|c",
+	}, lines)
+}
@@ -38,6 +38,10 @@ type TimeSampler struct {
 	// since we start running more than one with the demultiplexer introduction
 	id TimeSamplerID
 
+	// flushPartitions is the number of partitions the contexts are split into, by origin, to
+	// serialize the series in parallel during a flush
+	flushPartitions int
+
 	hostname string
 }
 
@@ -49,6 +53,11 @@ func NewTimeSampler(id TimeSamplerID, interval int64, cache *tags.Store, hostnam
 
 	log.Infof("Creating TimeSampler #%d", id)
 
+	flushPartitions := config.Datadog.GetInt("aggregator_flush_partitions")
+	if flushPartitions < 1 {
+		flushPartitions = 1
+	}
+
 	s := &TimeSampler{
 		interval:                    interval,
 		contextResolver:             newTimestampContextResolver(cache),
@@ -56,6 +65,7 @@ func NewTimeSampler(id TimeSamplerID, interval int64, cache *tags.Store, hostnam
 		counterLastSampledByContext: map[ckey.ContextKey]float64{},
 		sketchMap:                   make(sketchMap),
 		id:                          id,
+		flushPartitions:             flushPartitions,
 		hostname:                    hostname,
 	}
 
@@ -115,10 +125,22 @@ func (s *TimeSampler) newSketchSeries(ck ckey.ContextKey, points []metrics.Sketc
 	return ss
 }
 
-func (s *TimeSampler) flushSeries(cutoffTime int64, series metrics.SerieSink) {
+func (s *TimeSampler) flushSeries(cutoffTime int64, series metrics.SerieSink, serializeSeries seriesSerializer) {
 	// Map to hold the expired contexts that will need to be deleted after the flush so that we stop sending zeros
 	counterContextsToDelete := map[ckey.ContextKey]struct{}{}
-	contextMetricsFlusher := metrics.NewContextMetricsFlusher()
+	contextMetricsFlushers := make([]*metrics.ContextMetricsFlusher, s.flushPartitions)
+	for i := range contextMetricsFlushers {
+		contextMetricsFlushers[i] = metrics.NewContextMetricsFlusher()
+	}
+	appendContextMetrics := func(bucketTimestamp float64, contextMetrics metrics.ContextMetrics) {
+		if len(contextMetricsFlushers) == 1 {
+			contextMetricsFlushers[0].Append(bucketTimestamp, contextMetrics)
+			return
+		}
+		for i, partition := range s.partitionContextMetrics(contextMetrics) {
+			contextMetricsFlushers[i].Append(bucketTimestamp, partition)
+		}
+	}
 
 	if len(s.metricsByTimestamp) > 0 {
 		for bucketTimestamp, contextMetrics := range s.metricsByTimestamp {
@@ -130,7 +152,7 @@ func (s *TimeSampler) flushSeries(cutoffTime int64, series metrics.SerieSink) {
 			// Add a 0 sample to all the counters that are not expired.
 			// It is ok to add 0 samples to a counter that was already sampled for real in the bucket, since it won't change its value
 			s.countersSampleZeroValue(bucketTimestamp, contextMetrics, counterContextsToDelete)
-			contextMetricsFlusher.Append(float64(bucketTimestamp), contextMetrics)
+			appendContextMetrics(float64(bucketTimestamp), contextMetrics)
 
 			delete(s.metricsByTimestamp, bucketTimestamp)
 		}
@@ -141,15 +163,19 @@ func (s *TimeSampler) flushSeries(cutoffTime int64, series metrics.SerieSink) {
 		contextMetrics := metrics.MakeContextMetrics()
 
 		s.countersSampleZeroValue(cutoffTime-s.interval, contextMetrics, counterContextsToDelete)
-		contextMetricsFlusher.Append(float64(cutoffTime-s.interval), contextMetrics)
+		appendContextMetrics(float64(cutoffTime-s.interval), contextMetrics)
 	}
 
-	// serieBySignature is reused for each call of dedupSerieBySerieSignature to avoid allocations.
-	serieBySignature := make(map[SerieSignature]*metrics.Serie)
-	s.flushContextMetrics(contextMetricsFlusher, func(rawSeries []*metrics.Serie) {
-		// Note: rawSeries is reused at each call
-		s.dedupSerieBySerieSignature(rawSeries, series, serieBySignature)
-	})
+	if len(contextMetricsFlushers) == 1 {
+		// serieBySignature is reused for each call of dedupSerieBySerieSignature to avoid allocations.
+		serieBySignature := make(map[SerieSignature]*metrics.Serie)
+		s.flushContextMetrics(contextMetricsFlushers[0], func(rawSeries []*metrics.Serie) {
+			// Note: rawSeries is reused at each call
+			s.dedupSerieBySerieSignature(rawSeries, series, serieBySignature)
+		})
+	} else {
+		s.flushPartitionedContextMetrics(contextMetricsFlushers, series, serializeSeries)
+	}
 
 	// Delete the contexts associated to an expired counter
 	for context := range counterContextsToDelete {
@@ -210,10 +236,17 @@ func (s *TimeSampler) flushSketches(cutoffTime int64, sketchesSink metrics.Sketc
 }
 
 func (s *TimeSampler) flush(timestamp float64, series metrics.SerieSink, sketches metrics.SketchesSink) {
+	s.flushWithSeriesSerializer(timestamp, series, sketches, nil)
+}
+
+// flushWithSeriesSerializer flushes the sampler, the series of each flush partition being serialized
+// in parallel by serializeSeries when it isn't nil. The series of an unpartitioned flush, and the
+// telemetry series, are appended to series.
+func (s *TimeSampler) flushWithSeriesSerializer(timestamp float64, series metrics.SerieSink, sketches metrics.SketchesSink, serializeSeries seriesSerializer) {
 	// Compute a limit timestamp
 	cutoffTime := s.calculateBucketStart(timestamp)
 
-	s.flushSeries(cutoffTime, series)
+	s.flushSeries(cutoffTime, series, serializeSeries)
 	s.flushSketches(cutoffTime, sketches)
 
 	// expiring contexts
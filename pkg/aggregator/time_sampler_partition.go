@@ -0,0 +1,104 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package aggregator
+
+import (
+	"sync"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/ckey"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+// flushPartitionBatchSize is the number of series a partition worker buffers before handing them
+// over to the goroutine appending them to the sink, when the partitions share the sink.
+const flushPartitionBatchSize = 1000
+
+// seriesSerializer calls produce with a series sink serialized by its own goroutine, and returns once
+// the series appended by produce are serialized. It is used to serialize each flush partition in
+// parallel, each call using its own sink.
+type seriesSerializer func(produce func(metrics.SerieSink))
+
+// partitionOf returns the flush partition of a context. The contexts of an origin all land in the
+// same partition, the contexts without origin tags are spread by context key.
+func (s *TimeSampler) partitionOf(contextKey ckey.ContextKey) int {
+	key := uint64(contextKey)
+	if context, ok := s.contextResolver.get(contextKey); ok && len(context.taggerTags.Tags()) > 0 {
+		key = uint64(context.originKey)
+	}
+	return int(key % uint64(s.flushPartitions))
+}
+
+// partitionContextMetrics splits the metrics of a bucket in one ContextMetrics per flush partition.
+func (s *TimeSampler) partitionContextMetrics(contextMetrics metrics.ContextMetrics) []metrics.ContextMetrics {
+	partitions := make([]metrics.ContextMetrics, s.flushPartitions)
+	for i := range partitions {
+		partitions[i] = make(metrics.ContextMetrics, len(contextMetrics)/s.flushPartitions)
+	}
+	for contextKey, metric := range contextMetrics {
+		partitions[s.partitionOf(contextKey)][contextKey] = metric
+	}
+	return partitions
+}
+
+// flushPartitionedContextMetrics flushes each partition in its own worker goroutine. The workers
+// aggregate, dedup and resolve the series of their partition in independent buffers.
+//
+// With a seriesSerializer, each worker appends the series of its partition to its own sink, serialized
+// in parallel with the other partitions. Otherwise, the workers hand the series over in batches to the
+// calling goroutine, the only one appending to the sink, as the sinks are not safe for concurrent use.
+func (s *TimeSampler) flushPartitionedContextMetrics(contextMetricsFlushers []*metrics.ContextMetricsFlusher, series metrics.SerieSink, serializeSeries seriesSerializer) {
+	if serializeSeries != nil {
+		var wg sync.WaitGroup
+		for _, contextMetricsFlusher := range contextMetricsFlushers {
+			wg.Add(1)
+			go func(contextMetricsFlusher *metrics.ContextMetricsFlusher) {
+				defer wg.Done()
+				serializeSeries(func(partitionSeries metrics.SerieSink) {
+					serieBySignature := make(map[SerieSignature]*metrics.Serie)
+					s.flushContextMetrics(contextMetricsFlusher, func(rawSeries []*metrics.Serie) {
+						s.dedupSerieBySerieSignature(rawSeries, partitionSeries, serieBySignature)
+					})
+				})
+			}(contextMetricsFlusher)
+		}
+		wg.Wait()
+		return
+	}
+
+	batches := make(chan metrics.Series, len(contextMetricsFlushers))
+
+	var wg sync.WaitGroup
+	for _, contextMetricsFlusher := range contextMetricsFlushers {
+		wg.Add(1)
+		go func(contextMetricsFlusher *metrics.ContextMetricsFlusher) {
+			defer wg.Done()
+
+			batch := make(metrics.Series, 0, flushPartitionBatchSize)
+			serieBySignature := make(map[SerieSignature]*metrics.Serie)
+			s.flushContextMetrics(contextMetricsFlusher, func(rawSeries []*metrics.Serie) {
+				s.dedupSerieBySerieSignature(rawSeries, &batch, serieBySignature)
+				if len(batch) >= flushPartitionBatchSize {
+					batches <- batch
+					batch = make(metrics.Series, 0, flushPartitionBatchSize)
+				}
+			})
+			if len(batch) > 0 {
+				batches <- batch
+			}
+		}(contextMetricsFlusher)
+	}
+
+	go func() {
+		wg.Wait()
+		close(batches)
+	}()
+
+	for batch := range batches {
+		for _, serie := range batch {
+			series.Append(serie)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build test
+// +build test
+
+package aggregator
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/ckey"
+	"github.com/DataDog/datadog-agent/pkg/aggregator/internal/tags"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/tagset"
+)
+
+func testPartitionedTimeSampler(partitions int) *TimeSampler {
+	sampler := testTimeSampler()
+	sampler.flushPartitions = partitions
+	return sampler
+}
+
+func sampleContexts(sampler *TimeSampler, contexts int, timestamp float64) {
+	mtypes := []metrics.MetricType{metrics.GaugeType, metrics.CounterType, metrics.HistogramType}
+	for i := 0; i < contexts; i++ {
+		sample := metrics.MetricSample{
+			Name:       fmt.Sprintf("my.metric.%d", i%10),
+			Value:      float64(i),
+			Mtype:      mtypes[i%len(mtypes)],
+			Tags:       []string{fmt.Sprintf("context:%d", i)},
+			SampleRate: 1,
+		}
+		sampler.sample(&sample, timestamp)
+	}
+}
+
+func seriesByKey(series metrics.Series) map[ckey.ContextKey]*metrics.Serie {
+	byKey := make(map[ckey.ContextKey]*metrics.Serie, len(series))
+	for _, serie := range series {
+		byKey[generateSerieContextKey(serie)] = serie
+	}
+	return byKey
+}
+
+func TestPartitionedFlush(t *testing.T) {
+	sequential := testPartitionedTimeSampler(1)
+	partitioned := testPartitionedTimeSampler(4)
+
+	for _, sampler := range []*TimeSampler{sequential, partitioned} {
+		// more contexts than a partition batch, over two buckets
+		sampleContexts(sampler, 3*flushPartitionBatchSize, 12345.0)
+		sampleContexts(sampler, 3*flushPartitionBatchSize, 12355.0)
+	}
+
+	expected, _ := flushSerie(sequential, 12360.0)
+	actual, _ := flushSerie(partitioned, 12360.0)
+	require.Len(t, actual, len(expected))
+
+	actualByKey := seriesByKey(actual)
+	for key, serie := range seriesByKey(expected) {
+		if assert.Contains(t, actualByKey, key) {
+			metrics.AssertSerieEqual(t, serie, actualByKey[key])
+		}
+	}
+
+	// the counters still sample zero values after a partitioned flush
+	expected, _ = flushSerie(sequential, 12380.0)
+	actual, _ = flushSerie(partitioned, 12380.0)
+	assert.NotEmpty(t, actual)
+	assert.Len(t, actual, len(expected))
+}
+
+func TestPartitionOf(t *testing.T) {
+	store := tags.NewStore(true, "test")
+	sampler := testPartitionedTimeSampler(8)
+	resolver := sampler.contextResolver.resolver
+
+	addContext := func(contextKey ckey.ContextKey, originKey ckey.TagsKey, originTags ...string) {
+		resolver.contextsByKey[contextKey] = &Context{
+			taggerTags: store.Insert(originKey, tagset.NewHashingTagsAccumulatorWithTags(originTags)),
+			originKey:  originKey,
+		}
+	}
+
+	// the contexts of an origin are flushed by the same worker
+	for i := 0; i < 100; i++ {
+		addContext(ckey.ContextKey(i), 42, "pod_name:foo")
+	}
+	for i := 0; i < 100; i++ {
+		assert.Equal(t, sampler.partitionOf(0), sampler.partitionOf(ckey.ContextKey(i)))
+	}
+
+	// the contexts without origin are spread over all the partitions
+	partitions := make(map[int]struct{})
+	for i := 100; i < 200; i++ {
+		addContext(ckey.ContextKey(i), 0)
+		partitions[sampler.partitionOf(ckey.ContextKey(i))] = struct{}{}
+	}
+	assert.Len(t, partitions, 8)
+}
+
+func TestPartitionContextMetrics(t *testing.T) {
+	sampler := testPartitionedTimeSampler(4)
+	contextMetrics := metrics.MakeContextMetrics()
+	for i := 0; i < 100; i++ {
+		contextMetrics[ckey.ContextKey(i)] = &metrics.Gauge{}
+	}
+
+	partitions := sampler.partitionContextMetrics(contextMetrics)
+	require.Len(t, partitions, 4)
+
+	total := 0
+	for i, partition := range partitions {
+		for contextKey := range partition {
+			assert.Equal(t, i, sampler.partitionOf(contextKey))
+		}
+		total += len(partition)
+	}
+	assert.Equal(t, len(contextMetrics), total)
+}
+
+// collectingSeriesSerializer returns a seriesSerializer appending the series of each partition to its
+// own sink, and the sinks of the partitions.
+func collectingSeriesSerializer() (seriesSerializer, func() []metrics.Series) {
+	var mu sync.Mutex
+	var sinks []metrics.Series
+	return func(produce func(metrics.SerieSink)) {
+			var series metrics.Series
+			produce(&series)
+			mu.Lock()
+			defer mu.Unlock()
+			sinks = append(sinks, series)
+		}, func() []metrics.Series {
+			mu.Lock()
+			defer mu.Unlock()
+			return sinks
+		}
+}
+
+func TestPartitionedFlushWithSeriesSerializer(t *testing.T) {
+	sequential := testPartitionedTimeSampler(1)
+	partitioned := testPartitionedTimeSampler(4)
+	for _, sampler := range []*TimeSampler{sequential, partitioned} {
+		sampleContexts(sampler, 3*flushPartitionBatchSize, 12345.0)
+	}
+
+	expected, _ := flushSerie(sequential, 12360.0)
+
+	serializeSeries, partitionSinks := collectingSeriesSerializer()
+	var series metrics.Series
+	var sketches metrics.SketchSeriesList
+	partitioned.flushWithSeriesSerializer(12360.0, &series, &sketches, serializeSeries)
+
+	// each partition is appended to its own sink, not to the main one
+	assert.Empty(t, series)
+	require.Len(t, partitionSinks(), 4)
+	actualByKey := make(map[ckey.ContextKey]*metrics.Serie)
+	for i, partitionSeries := range partitionSinks() {
+		assert.NotEmpty(t, partitionSeries, "partition %d", i)
+		for key, serie := range seriesByKey(partitionSeries) {
+			actualByKey[key] = serie
+		}
+	}
+	require.Len(t, actualByKey, len(expected))
+	for key, serie := range seriesByKey(expected) {
+		if assert.Contains(t, actualByKey, key) {
+			metrics.AssertSerieEqual(t, serie, actualByKey[key])
+		}
+	}
+}
+
+func benchmarkTimeSamplerFlush(b *testing.B, partitions int, contexts int, serializeSeries seriesSerializer) {
+	sampler := testPartitionedTimeSampler(partitions)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		b.StopTimer()
+		timestamp := float64(12345 + n*10)
+		sampleContexts(sampler, contexts, timestamp)
+		b.StartTimer()
+
+		var series metrics.Series
+		sampler.flushSeries(sampler.calculateBucketStart(timestamp+10), &series, serializeSeries)
+	}
+}
+
+func BenchmarkTimeSamplerFlush(b *testing.B) {
+	for _, contexts := range []int{10000, 100000} {
+		for _, partitions := range []int{1, 2, 4, 8} {
+			b.Run(fmt.Sprintf("contexts=%d/partitions=%d", contexts, partitions), func(b *testing.B) {
+				benchmarkTimeSamplerFlush(b, partitions, contexts, nil)
+			})
+			b.Run(fmt.Sprintf("contexts=%d/partitions=%d/parallel-serialization", contexts, partitions), func(b *testing.B) {
+				benchmarkTimeSamplerFlush(b, partitions, contexts, func(produce func(metrics.SerieSink)) {
+					var series metrics.Series
+					produce(&series)
+				})
+			})
+		}
+	}
+}
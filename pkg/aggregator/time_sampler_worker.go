@@ -34,6 +34,8 @@ type timeSamplerWorker struct {
 	samplesChan chan []metrics.MetricSample
 	// use this chan to trigger a flush of the time sampler
 	flushChan chan flushTrigger
+	// use this chan to collect the contexts of the time sampler for the cardinality report
+	cardinalityChan chan *cardinalityRequest
 	// use this chan to stop the timeSamplerWorker
 	stopChan chan struct{}
 
@@ -56,6 +58,8 @@ func newTimeSamplerWorker(sampler *TimeSampler, flushInterval time.Duration, buf
 		stopChan:    make(chan struct{}),
 		flushChan:   make(chan flushTrigger),
 
+		cardinalityChan: make(chan *cardinalityRequest),
+
 		tagsStore: tagsStore,
 	}
 }
@@ -82,6 +86,9 @@ func (w *timeSamplerWorker) run() {
 		case trigger := <-w.flushChan:
 			w.triggerFlush(trigger)
 			w.tagsStore.Shrink()
+		case req := <-w.cardinalityChan:
+			req.acc.addContexts(w.sampler.contextResolver.resolver)
+			req.blockChan <- struct{}{}
 		}
 	}
 }
@@ -91,6 +98,6 @@ func (w *timeSamplerWorker) stop() {
 }
 
 func (w *timeSamplerWorker) triggerFlush(trigger flushTrigger) {
-	w.sampler.flush(float64(trigger.time.Unix()), trigger.seriesSink, trigger.sketchesSink)
+	w.sampler.flushWithSeriesSerializer(float64(trigger.time.Unix()), trigger.seriesSink, trigger.sketchesSink, trigger.serializeSeries)
 	trigger.blockChan <- struct{}{}
 }
@@ -43,10 +43,14 @@ func setupAPM(config Config) {
 	config.SetKnown("apm_config.dd_agent_bin")
 	config.SetKnown("apm_config.trace_writer.connection_limit")
 	config.SetKnown("apm_config.trace_writer.queue_size")
+	config.SetKnown("apm_config.trace_writer.compression")
+	config.SetKnown("apm_config.trace_writer.compression_level")
 	config.SetKnown("apm_config.service_writer.connection_limit")
 	config.SetKnown("apm_config.service_writer.queue_size")
 	config.SetKnown("apm_config.stats_writer.connection_limit")
 	config.SetKnown("apm_config.stats_writer.queue_size")
+	config.SetKnown("apm_config.stats_writer.compression")
+	config.SetKnown("apm_config.stats_writer.compression_level")
 	config.SetKnown("apm_config.analyzed_rate_by_service.*")
 	config.SetKnown("apm_config.log_throttling")
 	config.SetKnown("apm_config.bucket_size_seconds")
@@ -70,10 +74,14 @@ func setupAPM(config Config) {
 	config.BindEnvAndSetDefault("apm_config.remote_tagger", true, "DD_APM_REMOTE_TAGGER")                                                     //nolint:errcheck
 	config.BindEnvAndSetDefault("apm_config.peer_service_aggregation", false, "DD_APM_PEER_SERVICE_AGGREGATION")                              //nolint:errcheck
 	config.BindEnvAndSetDefault("apm_config.compute_stats_by_span_kind", false, "DD_APM_COMPUTE_STATS_BY_SPAN_KIND")                          //nolint:errcheck
+	config.BindEnvAndSetDefault("apm_config.supplement_client_stats", false, "DD_APM_SUPPLEMENT_CLIENT_STATS")                                //nolint:errcheck
+	config.BindEnvAndSetDefault("apm_config.compute_peer_service", false, "DD_APM_COMPUTE_PEER_SERVICE")                                      //nolint:errcheck
+	config.BindEnvAndSetDefault("apm_config.file_receiver.poll_interval_seconds", 5, "DD_APM_FILE_RECEIVER_POLL_INTERVAL_SECONDS")            //nolint:errcheck
 
 	config.BindEnv("apm_config.max_catalog_services", "DD_APM_MAX_CATALOG_SERVICES")
 	config.BindEnv("apm_config.receiver_timeout", "DD_APM_RECEIVER_TIMEOUT")
 	config.BindEnv("apm_config.max_payload_size", "DD_APM_MAX_PAYLOAD_SIZE")
+	config.BindEnv("apm_config.max_payloads_per_second_per_container", "DD_APM_MAX_PAYLOADS_PER_SECOND_PER_CONTAINER")
 	config.BindEnv("apm_config.log_file", "DD_APM_LOG_FILE")
 	config.BindEnv("apm_config.max_events_per_second", "DD_APM_MAX_EPS", "DD_MAX_EPS")
 	config.BindEnv("apm_config.max_traces_per_second", "DD_APM_MAX_TPS", "DD_MAX_TPS")
@@ -81,6 +89,7 @@ func setupAPM(config Config) {
 	config.BindEnv("apm_config.enable_rare_sampler", "DD_APM_ENABLE_RARE_SAMPLER")
 	config.BindEnv("apm_config.disable_rare_sampler", "DD_APM_DISABLE_RARE_SAMPLER") //Deprecated
 	config.BindEnv("apm_config.max_remote_traces_per_second", "DD_APM_MAX_REMOTE_TPS")
+	config.BindEnv("apm_config.file_receiver.dir", "DD_APM_FILE_RECEIVER_DIR")
 
 	config.BindEnv("apm_config.max_memory", "DD_APM_MAX_MEMORY")
 	config.BindEnv("apm_config.max_cpu_percent", "DD_APM_MAX_CPU_PERCENT")
@@ -89,12 +98,14 @@ func setupAPM(config Config) {
 	config.BindEnv("apm_config.apm_dd_url", "DD_APM_DD_URL")
 	config.BindEnv("apm_config.connection_limit", "DD_APM_CONNECTION_LIMIT", "DD_CONNECTION_LIMIT")
 	config.BindEnv("apm_config.connection_reset_interval", "DD_APM_CONNECTION_RESET_INTERVAL")
+	config.BindEnv("apm_config.shutdown_drain_timeout", "DD_APM_SHUTDOWN_DRAIN_TIMEOUT")
 	config.BindEnv("apm_config.profiling_dd_url", "DD_APM_PROFILING_DD_URL")
 	config.BindEnv("apm_config.profiling_additional_endpoints", "DD_APM_PROFILING_ADDITIONAL_ENDPOINTS")
 	config.BindEnv("apm_config.additional_endpoints", "DD_APM_ADDITIONAL_ENDPOINTS")
 	config.BindEnv("apm_config.replace_tags", "DD_APM_REPLACE_TAGS")
 	config.BindEnv("apm_config.analyzed_spans", "DD_APM_ANALYZED_SPANS")
 	config.BindEnv("apm_config.ignore_resources", "DD_APM_IGNORE_RESOURCES", "DD_IGNORE_RESOURCE")
+	config.BindEnv("apm_config.peer_service_precedence", "DD_APM_PEER_SERVICE_PRECEDENCE")
 	config.BindEnv("apm_config.receiver_socket", "DD_APM_RECEIVER_SOCKET")
 	config.BindEnv("apm_config.windows_pipe_name", "DD_APM_WINDOWS_PIPE_NAME")
 	config.BindEnv("apm_config.sync_flushing", "DD_APM_SYNC_FLUSHING")
@@ -113,6 +124,7 @@ func setupAPM(config Config) {
 	config.BindEnv("apm_config.obfuscation.credit_cards.enabled", "DD_APM_OBFUSCATION_CREDIT_CARDS_ENABLED")
 	config.BindEnv("apm_config.obfuscation.credit_cards.luhn", "DD_APM_OBFUSCATION_CREDIT_CARDS_LUHN")
 	config.BindEnvAndSetDefault("apm_config.debug.port", 5012, "DD_APM_DEBUG_PORT")
+	config.BindEnv("apm_config.debug.sampling_decisions_buffer_size", "DD_APM_DEBUG_SAMPLING_DECISIONS_BUFFER_SIZE")
 	config.BindEnv("apm_config.features", "DD_APM_FEATURES")
 	config.SetEnvKeyTransformer("apm_config.features", parseKVList("apm_config.features"))
 
@@ -125,6 +137,15 @@ func setupAPM(config Config) {
 		return r
 	})
 
+	config.SetEnvKeyTransformer("apm_config.peer_service_precedence", func(in string) interface{} {
+		r, err := splitCSVString(in, ',')
+		if err != nil {
+			log.Warnf(`"apm_config.peer_service_precedence" can not be parsed: %v`, err)
+			return []string{}
+		}
+		return r
+	})
+
 	config.SetEnvKeyTransformer("apm_config.filter_tags.require", parseKVList("apm_config.filter_tags.require"))
 
 	config.SetEnvKeyTransformer("apm_config.filter_tags.reject", parseKVList("apm_config.filter_tags.reject"))
@@ -438,9 +438,19 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("aggregator_stop_timeout", 2)
 	config.BindEnvAndSetDefault("aggregator_buffer_size", 100)
 	config.BindEnvAndSetDefault("aggregator_use_tags_store", true)
+	config.BindEnvAndSetDefault("aggregator_flush_partitions", 1)
 	config.BindEnvAndSetDefault("basic_telemetry_add_container_tags", false) // configure adding the agent container tags to the basic agent telemetry metrics (e.g. `datadog.agent.running`)
 	config.BindEnvAndSetDefault("aggregator_flush_metrics_and_serialize_in_parallel_chan_size", 200)
 	config.BindEnvAndSetDefault("aggregator_flush_metrics_and_serialize_in_parallel_buffer_size", 4000)
+	config.BindEnvAndSetDefault("aggregator_series_mirror.enabled", false)
+	config.BindEnvAndSetDefault("aggregator_series_mirror.prefixes", []string{"datadog.agent.", "datadog.dogstatsd."})
+	config.BindEnvAndSetDefault("aggregator_series_mirror.protocol", "statsd")
+	config.BindEnvAndSetDefault("aggregator_series_mirror.endpoint", "localhost:8125")
+	config.BindEnvAndSetDefault("aggregator_secondary_flush.enabled", false)
+	config.BindEnvAndSetDefault("aggregator_secondary_flush.interval", 60*time.Second)
+	config.BindEnvAndSetDefault("aggregator_secondary_flush.dd_url", "")
+	config.BindEnvAndSetDefault("aggregator_secondary_flush.api_key", "")
+	config.BindEnvAndSetDefault("aggregator_secondary_flush.max_buffered_series", 100000)
 
 	// Serializer
 	config.BindEnvAndSetDefault("enable_stream_payload_serialization", true)
@@ -510,6 +520,9 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("dogstatsd_packet_buffer_size", 32)
 	config.BindEnvAndSetDefault("dogstatsd_packet_buffer_flush_timeout", 100*time.Millisecond)
 	config.BindEnvAndSetDefault("dogstatsd_queue_size", 1024)
+	// Metrics prefixed by one of these are dropped by the listeners instead of blocking them while the
+	// forwarder queues are saturated.
+	config.BindEnvAndSetDefault("dogstatsd_backpressure_low_priority_prefixes", []string{})
 
 	config.BindEnvAndSetDefault("dogstatsd_non_local_traffic", false)
 	config.BindEnvAndSetDefault("dogstatsd_socket", "") // Notice: empty means feature disabled
@@ -690,6 +703,17 @@ func InitConfig(config Config) {
 	config.SetKnown("network_devices.netflow.aggregator_flow_context_ttl")
 	config.SetKnown("network_devices.netflow.aggregator_port_rollup_threshold")
 	config.SetKnown("network_devices.netflow.aggregator_rollup_tracker_refresh_interval")
+	config.SetKnown("network_devices.netflow.aggregator_deduplication_enabled")
+	config.SetKnown("network_devices.netflow.prometheus_listener_enabled")
+	config.SetKnown("network_devices.netflow.prometheus_listener_address")
+	config.SetKnown("network_devices.netflow.kubernetes_enrichment_enabled")
+	config.SetKnown("network_devices.netflow.clock_skew_threshold")
+	config.SetKnown("network_devices.netflow.clock_skew_correction_enabled")
+	config.SetKnown("network_devices.netflow.geoip.enabled")
+	config.SetKnown("network_devices.netflow.geoip.country_database_path")
+	config.SetKnown("network_devices.netflow.geoip.asn_database_path")
+	config.SetKnown("network_devices.netflow.geoip.cache_size")
+	config.SetKnown("network_devices.netflow.tenants")
 	config.BindEnvAndSetDefault("network_devices.netflow.enabled", "false")
 	bindEnvAndSetLogsConfigKeys(config, "network_devices.netflow.forwarder.")
 
@@ -1166,6 +1190,10 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("capture_lambda_payload", false)
 	config.BindEnvAndSetDefault("serverless.trace_enabled", false, "DD_TRACE_ENABLED")
 	config.BindEnvAndSetDefault("serverless.trace_managed_services", true, "DD_TRACE_MANAGED_SERVICES")
+	config.BindEnvAndSetDefault("serverless.span_tags", []string{}, "DD_SPAN_TAGS")
+	config.BindEnvAndSetDefault("serverless.span_tags_json", "", "DD_SPAN_TAGS_JSON")
+	config.BindEnvAndSetDefault("serverless.response_trace_id_path", "", "DD_SERVERLESS_RESPONSE_TRACE_ID_PATH")
+	config.BindEnvAndSetDefault("serverless.response_parent_id_path", "", "DD_SERVERLESS_RESPONSE_PARENT_ID_PATH")
 
 	// trace-agent's evp_proxy
 	config.BindEnv("evp_proxy_config.enabled")
@@ -70,6 +70,9 @@ const (
 
 	// DefaultProcessDiscoveryHintFrequency is the default frequency in terms of number of checks which we send a process discovery hint
 	DefaultProcessDiscoveryHintFrequency = 60
+
+	// DefaultProcessDetailsMaxPerSecond is the default number of processes whose details can be collected on demand per second
+	DefaultProcessDetailsMaxPerSecond = 1.0
 )
 
 // setupProcesses is meant to be called multiple times for different configs, but overrides apply to all configs, so
@@ -125,6 +128,7 @@ func setupProcesses(config Config) {
 	procBindEnvAndSetDefault(config, "process_config.queue_size", DefaultProcessQueueSize)
 	procBindEnvAndSetDefault(config, "process_config.process_queue_bytes", DefaultProcessQueueBytes)
 	procBindEnvAndSetDefault(config, "process_config.rt_queue_size", DefaultProcessRTQueueSize)
+	procBindEnvAndSetDefault(config, "process_config.rt_collection_budget", time.Duration(0))
 	procBindEnvAndSetDefault(config, "process_config.max_per_message", DefaultProcessMaxPerMessage)
 	procBindEnvAndSetDefault(config, "process_config.max_message_bytes", DefaultProcessMaxMessageBytes)
 	procBindEnvAndSetDefault(config, "process_config.cmd_port", DefaultProcessCmdPort)
@@ -192,6 +196,10 @@ func setupProcesses(config Config) {
 	procBindEnvAndSetDefault(config, "process_config.event_collection.interval", DefaultProcessEventsCheckInterval)
 
 	procBindEnvAndSetDefault(config, "process_config.cache_lookupid", false)
+	procBindEnvAndSetDefault(config, "process_config.gpu_stats.enabled", false)
+	procBindEnvAndSetDefault(config, "process_config.pressure_stats.enabled", false)
+	procBindEnvAndSetDefault(config, "process_config.process_details.max_per_second", DefaultProcessDetailsMaxPerSecond)
+	procBindEnvAndSetDefault(config, "process_config.process_details.env_allowlist", []string{})
 
 	processesAddOverrideOnce.Do(func() {
 		AddOverrideFunc(loadProcessTransforms)
@@ -66,6 +66,10 @@ func TestProcessDefaultConfig(t *testing.T) {
 			key:          "process_config.rt_queue_size",
 			defaultValue: DefaultProcessRTQueueSize,
 		},
+		{
+			key:          "process_config.rt_collection_budget",
+			defaultValue: time.Duration(0),
+		},
 		{
 			key:          "process_config.process_queue_bytes",
 			defaultValue: DefaultProcessQueueBytes,
@@ -126,6 +130,10 @@ func TestProcessDefaultConfig(t *testing.T) {
 			key:          "process_config.event_collection.interval",
 			defaultValue: DefaultProcessEventsCheckInterval,
 		},
+		{
+			key:          "process_config.process_details.max_per_second",
+			defaultValue: DefaultProcessDetailsMaxPerSecond,
+		},
 	} {
 		t.Run(tc.key+" default", func(t *testing.T) {
 			assert.Equal(t, tc.defaultValue, cfg.Get(tc.key))
@@ -233,6 +241,31 @@ func TestEnvVarOverride(t *testing.T) {
 			value:    "1h",
 			expected: time.Hour,
 		},
+		{
+			key:      "process_config.gpu_stats.enabled",
+			env:      "DD_PROCESS_CONFIG_GPU_STATS_ENABLED",
+			value:    "true",
+			expected: true,
+		},
+		{
+			key:      "process_config.pressure_stats.enabled",
+			env:      "DD_PROCESS_CONFIG_PRESSURE_STATS_ENABLED",
+			value:    "true",
+			expected: true,
+		},
+		{
+			key:      "process_config.process_details.max_per_second",
+			env:      "DD_PROCESS_CONFIG_PROCESS_DETAILS_MAX_PER_SECOND",
+			value:    "0.5",
+			expected: 0.5,
+		},
+		{
+			key:      "process_config.process_details.env_allowlist",
+			env:      "DD_PROCESS_CONFIG_PROCESS_DETAILS_ENV_ALLOWLIST",
+			value:    "JAVA_OPTS GOMAXPROCS",
+			expected: []string{"JAVA_OPTS", "GOMAXPROCS"},
+			expType:  "stringSlice",
+		},
 		{
 			key:      "process_config.disable_realtime_checks",
 			env:      "DD_PROCESS_CONFIG_DISABLE_REALTIME_CHECKS",
@@ -275,6 +308,12 @@ func TestEnvVarOverride(t *testing.T) {
 			value:    "10",
 			expected: 10,
 		},
+		{
+			key:      "process_config.rt_collection_budget",
+			env:      "DD_PROCESS_CONFIG_RT_COLLECTION_BUDGET",
+			value:    "500ms",
+			expected: 500 * time.Millisecond,
+		},
 		{
 			key:      "process_config.process_queue_bytes",
 			env:      "DD_PROCESS_CONFIG_PROCESS_QUEUE_BYTES",
@@ -23,6 +23,7 @@ const (
 	dsNS                         = "data_streams_config"
 	evNS                         = "event_monitoring_config"
 	smjtNS                       = smNS + ".java_tls"
+	smcaNS                       = smNS + ".cgroup_attach"
 	diNS                         = "dynamic_instrumentation"
 	defaultConnsMessageBatchSize = 600
 
@@ -164,6 +165,7 @@ func InitSystemProbeConfig(cfg Config) {
 	cfg.BindEnvAndSetDefault(join(netNS, "ignore_conntrack_init_failure"), false, "DD_SYSTEM_PROBE_NETWORK_IGNORE_CONNTRACK_INIT_FAILURE")
 	cfg.BindEnvAndSetDefault(join(netNS, "conntrack_init_timeout"), 10*time.Second)
 	cfg.BindEnvAndSetDefault(join(netNS, "allow_netlink_conntracker_fallback"), true)
+	cfg.BindEnvAndSetDefault(join(netNS, "conntrack_partial_tracking_cidrs"), []string{}, "DD_SYSTEM_PROBE_NETWORK_CONNTRACK_PARTIAL_TRACKING_CIDRS")
 
 	cfg.BindEnvAndSetDefault(join(spNS, "source_excludes"), map[string][]string{})
 	cfg.BindEnvAndSetDefault(join(spNS, "dest_excludes"), map[string][]string{})
@@ -175,16 +177,47 @@ func InitSystemProbeConfig(cfg Config) {
 	cfg.BindEnvAndSetDefault(join(smNS, "enable_go_tls_support"), false)
 
 	cfg.BindEnvAndSetDefault(join(smNS, "enable_http2_monitoring"), false)
+	cfg.BindEnvAndSetDefault(join(smNS, "enable_http3_monitoring"), false)
+	cfg.BindEnvAndSetDefault(join(smNS, "enable_dns_monitoring"), false)
+	cfg.BindEnvAndSetDefault(join(smNS, "enable_istio_monitoring"), false)
+	cfg.BindEnvAndSetDefault(join(smNS, "enable_nodejs_monitoring"), false)
+	cfg.BindEnvAndSetDefault(join(smNS, "enable_connection_correlation"), false)
+	cfg.BindEnvAndSetDefault(join(smcaNS, "enabled"), false)
+	cfg.BindEnvAndSetDefault(join(smcaNS, "cgroup_paths"), []string{})
 	cfg.BindEnvAndSetDefault(join(smjtNS, "enabled"), false)
 	cfg.BindEnvAndSetDefault(join(smjtNS, "debug"), false)
 	cfg.BindEnvAndSetDefault(join(smjtNS, "args"), defaultServiceMonitoringJavaAgentArgs)
 	cfg.BindEnvAndSetDefault(join(smjtNS, "allow_regex"), "")
 	cfg.BindEnvAndSetDefault(join(smjtNS, "block_regex"), "")
 	cfg.BindEnvAndSetDefault(join(smNS, "enable_http_stats_by_status_code"), false)
+	cfg.BindEnvAndSetDefault(join(smNS, "http_events_batch_size"), 0)
+	cfg.BindEnvAndSetDefault(join(smNS, "http_events_flush_interval_ms"), 1000)
+	cfg.BindEnvAndSetDefault(join(smNS, "enable_http_sampling"), false)
+	cfg.BindEnvAndSetDefault(join(smNS, "http_sampling_drop_threshold"), 0.01)
+	cfg.BindEnvAndSetDefault(join(smNS, "enable_http_path_quantization"), false)
+	cfg.BindEnvAndSetDefault(join(smNS, "max_shared_libraries_registrations"), 4096)
+	cfg.BindEnvAndSetDefault(join(smNS, "max_shared_libraries_blocklist_entries"), 4096)
+	cfg.BindEnvAndSetDefault(join(smNS, "shared_libraries_blocklist_ttl_in_s"), 600)
+	cfg.BindEnvAndSetDefault(join(smNS, "enable_in_flight_transactions_debug"), false)
+	httpQuantizationRules := join(smNS, "http_path_quantization_rules")
+	cfg.BindEnv(httpQuantizationRules)
+	cfg.SetEnvKeyTransformer(httpQuantizationRules, func(in string) interface{} {
+		var out []map[string]string
+		if err := json.Unmarshal([]byte(in), &out); err != nil {
+			log.Warnf(`%q can not be parsed: %v`, httpQuantizationRules, err)
+		}
+		return out
+	})
 
 	cfg.BindEnvAndSetDefault(join(netNS, "enable_gateway_lookup"), true, "DD_SYSTEM_PROBE_NETWORK_ENABLE_GATEWAY_LOOKUP")
+	cfg.BindEnvAndSetDefault(join(netNS, "collect_tcp_listen_overflows"), false, "DD_SYSTEM_PROBE_NETWORK_COLLECT_TCP_LISTEN_OVERFLOWS")
+	cfg.BindEnvAndSetDefault(join(netNS, "collect_tcp_failed_connections"), false, "DD_SYSTEM_PROBE_NETWORK_COLLECT_TCP_FAILED_CONNECTIONS")
+	cfg.BindEnvAndSetDefault(join(netNS, "enable_service_dependencies"), false, "DD_SYSTEM_PROBE_NETWORK_ENABLE_SERVICE_DEPENDENCIES")
+	cfg.BindEnvAndSetDefault(join(netNS, "service_dependencies_rollup_threshold"), 0, "DD_SYSTEM_PROBE_NETWORK_SERVICE_DEPENDENCIES_ROLLUP_THRESHOLD")
+	cfg.BindEnvAndSetDefault(join(netNS, "enable_fentry"), false, "DD_SYSTEM_PROBE_NETWORK_ENABLE_FENTRY")
 	cfg.BindEnvAndSetDefault(join(netNS, "max_http_stats_buffered"), 100000, "DD_SYSTEM_PROBE_NETWORK_MAX_HTTP_STATS_BUFFERED")
 	cfg.BindEnvAndSetDefault(join(smNS, "max_kafka_stats_buffered"), 100000)
+	cfg.BindEnvAndSetDefault(join(smNS, "max_dns_stats_buffered"), 100000)
 	httpRules := join(netNS, "http_replace_rules")
 	cfg.BindEnv(httpRules, "DD_SYSTEM_PROBE_NETWORK_HTTP_REPLACE_RULES")
 	cfg.SetEnvKeyTransformer(httpRules, func(in string) interface{} {
@@ -299,6 +332,12 @@ func InitSystemProbeConfig(cfg Config) {
 	cfg.BindEnvAndSetDefault("runtime_security_config.sbom.enabled", false)
 	cfg.BindEnvAndSetDefault("runtime_security_config.sbom.workloads_cache_size", 10)
 
+	// CWS - Hash resolver
+	cfg.BindEnvAndSetDefault("runtime_security_config.hash_resolver.enabled", false)
+	cfg.BindEnvAndSetDefault("runtime_security_config.hash_resolver.max_file_size", (1<<20)*10) // 10 MB
+	cfg.BindEnvAndSetDefault("runtime_security_config.hash_resolver.max_hash_rate", 500)
+	cfg.BindEnvAndSetDefault("runtime_security_config.hash_resolver.cache_size", 500)
+
 	// CWS - Security Profiles
 	cfg.BindEnvAndSetDefault("runtime_security_config.security_profile.enabled", false)
 	cfg.BindEnvAndSetDefault("runtime_security_config.security_profile.dir", DefaultSecurityProfilesDir)
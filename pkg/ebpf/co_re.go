@@ -25,26 +25,33 @@ import (
 // LoadCOREAsset attempts to find kernel BTF, reads the CO-RE object file, and then calls the callback function with the
 // asset and BTF options pre-filled. You should attempt to load the CO-RE program in the startFn func for telemetry to
 // be correctly recorded.
-func LoadCOREAsset(cfg *Config, filename string, startFn func(bytecode.AssetReader, manager.Options) error) error {
+//
+// The diagnostics of the load, such as the verifier log on failure, are available with GetLoadDiagnosticsForAsset.
+func LoadCOREAsset(cfg *Config, filename string, startFn func(bytecode.AssetReader, manager.Options) error) (err error) {
 	var telemetry COREResult
+	var btfData *btf.Spec
+	var buf bytecode.AssetReader
 	base := strings.TrimSuffix(filename, path.Ext(filename))
 	defer func() {
 		StoreCORETelemetryForAsset(base, telemetry)
+		storeLoadDiagnosticsForAsset(base, newLoadDiagnostics(btfData != nil, telemetry, buf, err))
+		if buf != nil {
+			buf.Close()
+		}
 	}()
 
-	var btfData *btf.Spec
 	btfData, telemetry = GetBTF(cfg.BTFPath, cfg.BPFDir)
 	if btfData == nil {
 		return fmt.Errorf("could not find BTF data on host")
 	}
 	defer btf.FlushKernelSpec()
 
-	buf, err := bytecode.GetReader(filepath.Join(cfg.BPFDir, "co-re"), filename)
+	buf, err = bytecode.GetReader(filepath.Join(cfg.BPFDir, "co-re"), filename)
 	if err != nil {
 		telemetry = AssetReadError
+		buf = nil
 		return fmt.Errorf("error reading %s: %s", filename, err)
 	}
-	defer buf.Close()
 
 	opts := manager.Options{
 		VerifierOptions: bpflib.CollectionOptions{
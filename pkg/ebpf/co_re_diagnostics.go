@@ -0,0 +1,115 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux_bpf
+// +build linux_bpf
+
+package ebpf
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/DataDog/gopsutil/host"
+	bpflib "github.com/cilium/ebpf"
+
+	"github.com/DataDog/datadog-agent/pkg/ebpf/bytecode"
+)
+
+// LoadDiagnostics is a report of the last load of a CO-RE asset, gathering what is needed to investigate a
+// load failure without access to the host
+type LoadDiagnostics struct {
+	Time          time.Time `json:"time"`
+	KernelVersion string    `json:"kernel_version"`
+	BTFAvailable  bool      `json:"btf_available"`
+	Result        string    `json:"result"`
+	Error         string    `json:"error,omitempty"`
+	// VerifierLog holds the output of the verifier when a program was rejected
+	VerifierLog          []string `json:"verifier_log,omitempty"`
+	VerifierLogTruncated bool     `json:"verifier_log_truncated,omitempty"`
+	// Maps holds the map specs of the asset when the load failed, as written in the object file
+	Maps map[string]MapSpecDiagnostics `json:"maps,omitempty"`
+}
+
+// MapSpecDiagnostics describes the spec of a map of a CO-RE asset
+type MapSpecDiagnostics struct {
+	Type       string `json:"type"`
+	KeySize    uint32 `json:"key_size"`
+	ValueSize  uint32 `json:"value_size"`
+	MaxEntries uint32 `json:"max_entries"`
+}
+
+// loadDiagnosticsByAsset is a global object storing the diagnostics of the last load of every CO-RE asset
+var loadDiagnosticsByAsset = make(map[string]LoadDiagnostics)
+var diagnosticsmu sync.Mutex
+
+// storeLoadDiagnosticsForAsset stores the diagnostics of the last load of an asset
+func storeLoadDiagnosticsForAsset(assetName string, diagnostics LoadDiagnostics) {
+	diagnosticsmu.Lock()
+	defer diagnosticsmu.Unlock()
+
+	loadDiagnosticsByAsset[assetName] = diagnostics
+}
+
+// GetLoadDiagnosticsForAsset returns the diagnostics of the last load of an asset, if it was loaded with CO-RE
+func GetLoadDiagnosticsForAsset(assetName string) (LoadDiagnostics, bool) {
+	diagnosticsmu.Lock()
+	defer diagnosticsmu.Unlock()
+
+	diagnostics, ok := loadDiagnosticsByAsset[assetName]
+	return diagnostics, ok
+}
+
+// GetLoadDiagnosticsByAsset returns the diagnostics of the last load of every CO-RE asset
+func GetLoadDiagnosticsByAsset() map[string]LoadDiagnostics {
+	diagnosticsmu.Lock()
+	defer diagnosticsmu.Unlock()
+
+	result := make(map[string]LoadDiagnostics, len(loadDiagnosticsByAsset))
+	for assetName, diagnostics := range loadDiagnosticsByAsset {
+		result[assetName] = diagnostics
+	}
+	return result
+}
+
+// newLoadDiagnostics builds the diagnostics of the load of an asset. The verifier log and the map specs are only
+// collected when the load failed, the asset being nil when it couldn't be read.
+func newLoadDiagnostics(btfAvailable bool, result COREResult, asset bytecode.AssetReader, err error) LoadDiagnostics {
+	diagnostics := LoadDiagnostics{
+		Time:         time.Now(),
+		BTFAvailable: btfAvailable,
+		Result:       result.String(),
+	}
+	if kernelVersion, kerr := host.KernelVersion(); kerr == nil {
+		diagnostics.KernelVersion = kernelVersion
+	}
+
+	if err == nil {
+		return diagnostics
+	}
+	diagnostics.Error = err.Error()
+
+	var ve *bpflib.VerifierError
+	if errors.As(err, &ve) {
+		diagnostics.VerifierLog = ve.Log
+		diagnostics.VerifierLogTruncated = ve.Truncated
+	}
+
+	if asset != nil {
+		if spec, serr := bpflib.LoadCollectionSpecFromReader(asset); serr == nil {
+			diagnostics.Maps = make(map[string]MapSpecDiagnostics, len(spec.Maps))
+			for name, m := range spec.Maps {
+				diagnostics.Maps[name] = MapSpecDiagnostics{
+					Type:       m.Type.String(),
+					KeySize:    m.KeySize,
+					ValueSize:  m.ValueSize,
+					MaxEntries: m.MaxEntries,
+				}
+			}
+		}
+	}
+	return diagnostics
+}
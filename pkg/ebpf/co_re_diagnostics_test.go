@@ -0,0 +1,64 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux_bpf
+// +build linux_bpf
+
+package ebpf
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	bpflib "github.com/cilium/ebpf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadDiagnostics(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		diagnostics := newLoadDiagnostics(true, successDefaultBTF, nil, nil)
+		assert.True(t, diagnostics.BTFAvailable)
+		assert.Equal(t, "success_default_btf", diagnostics.Result)
+		assert.Empty(t, diagnostics.Error)
+		assert.Empty(t, diagnostics.VerifierLog)
+	})
+
+	t.Run("btf not found", func(t *testing.T) {
+		diagnostics := newLoadDiagnostics(false, btfNotFound, nil, errors.New("could not find BTF data on host"))
+		assert.False(t, diagnostics.BTFAvailable)
+		assert.Equal(t, "btf_not_found", diagnostics.Result)
+		assert.Equal(t, "could not find BTF data on host", diagnostics.Error)
+	})
+
+	t.Run("verifier error", func(t *testing.T) {
+		ve := &bpflib.VerifierError{
+			Cause:     errors.New("permission denied"),
+			Log:       []string{"0: R1=ctx() R10=fp0", "invalid mem access 'scalar'"},
+			Truncated: true,
+		}
+		diagnostics := newLoadDiagnostics(true, VerifierError, nil, fmt.Errorf("failed to init manager: %w", ve))
+		assert.Equal(t, "verifier_error", diagnostics.Result)
+		assert.Equal(t, ve.Log, diagnostics.VerifierLog)
+		assert.True(t, diagnostics.VerifierLogTruncated)
+	})
+}
+
+func TestLoadDiagnosticsByAsset(t *testing.T) {
+	storeLoadDiagnosticsForAsset("exampleAsset1", LoadDiagnostics{Result: successCustomBTF.String()})
+	storeLoadDiagnosticsForAsset("exampleAsset2", LoadDiagnostics{Result: LoaderError.String(), Error: "error"})
+
+	diagnostics, ok := GetLoadDiagnosticsForAsset("exampleAsset2")
+	require.True(t, ok)
+	assert.Equal(t, "loader_error", diagnostics.Result)
+
+	_, ok = GetLoadDiagnosticsForAsset("unknownAsset")
+	assert.False(t, ok)
+
+	all := GetLoadDiagnosticsByAsset()
+	assert.Contains(t, all, "exampleAsset1")
+	assert.Contains(t, all, "exampleAsset2")
+}
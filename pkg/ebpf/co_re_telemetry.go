@@ -25,6 +25,28 @@ const (
 	LoaderError
 )
 
+// String returns a description of the CO-RE result
+func (r COREResult) String() string {
+	switch r {
+	case successCustomBTF:
+		return "success_custom_btf"
+	case successEmbeddedBTF:
+		return "success_embedded_btf"
+	case successDefaultBTF:
+		return "success_default_btf"
+	case btfNotFound:
+		return "btf_not_found"
+	case AssetReadError:
+		return "asset_read_error"
+	case VerifierError:
+		return "verifier_error"
+	case LoaderError:
+		return "loader_error"
+	default:
+		return "unknown"
+	}
+}
+
 // coreTelemetryByAsset is a global object which is responsible for storing CO-RE telemetry for all ebpf assets
 var coreTelemetryByAsset = make(map[string]COREResult)
 var telemetrymu sync.Mutex
@@ -269,9 +269,16 @@ type passthroughPipelineDesc struct {
 	defaultInputChanSize          int
 }
 
+// Tenant is a Datadog organization, other than the one of the agent, to which events are sent
+type Tenant struct {
+	Name   string
+	APIKey string
+}
+
 // newHTTPPassthroughPipeline creates a new HTTP-only event platform pipeline that sends messages directly to intake
-// without any of the processing that exists in regular logs pipelines.
-func newHTTPPassthroughPipeline(desc passthroughPipelineDesc, destinationsContext *client.DestinationsContext, pipelineID int) (p *passthroughPipeline, err error) {
+// without any of the processing that exists in regular logs pipelines. When a tenant is given, the messages are
+// only sent to the main endpoint, with the API key of the tenant.
+func newHTTPPassthroughPipeline(desc passthroughPipelineDesc, destinationsContext *client.DestinationsContext, pipelineID int, tenant *Tenant) (p *passthroughPipeline, err error) {
 	configKeys := config.NewLogsConfigKeys(desc.endpointsConfigPrefix, coreConfig.Datadog)
 	endpoints, err := config.BuildHTTPEndpointsWithConfig(configKeys, desc.hostnameEndpointPrefix, desc.intakeTrackType, config.DefaultIntakeProtocol, config.DefaultIntakeOrigin)
 	if err != nil {
@@ -280,6 +287,13 @@ func newHTTPPassthroughPipeline(desc passthroughPipelineDesc, destinationsContex
 	if !endpoints.UseHTTP {
 		return nil, fmt.Errorf("endpoints must be http")
 	}
+	telemetryPrefix := desc.eventType
+	if tenant != nil {
+		// the additional endpoints belong to the organizations of the agent
+		endpoints.Main.APIKey = tenant.APIKey
+		endpoints.Endpoints = []config.Endpoint{endpoints.Main}
+		telemetryPrefix = desc.eventType + "_" + tenant.Name
+	}
 	// epforwarder pipelines apply their own defaults on top of the hardcoded logs defaults
 	if endpoints.BatchMaxConcurrentSend <= 0 {
 		endpoints.BatchMaxConcurrentSend = desc.defaultBatchMaxConcurrentSend
@@ -295,12 +309,12 @@ func newHTTPPassthroughPipeline(desc passthroughPipelineDesc, destinationsContex
 	}
 	reliable := []client.Destination{}
 	for i, endpoint := range endpoints.GetReliableEndpoints() {
-		telemetryName := fmt.Sprintf("%s_%d_reliable_%d", desc.eventType, pipelineID, i)
+		telemetryName := fmt.Sprintf("%s_%d_reliable_%d", telemetryPrefix, pipelineID, i)
 		reliable = append(reliable, http.NewDestination(endpoint, desc.contentType, destinationsContext, endpoints.BatchMaxConcurrentSend, true, telemetryName))
 	}
 	additionals := []client.Destination{}
 	for i, endpoint := range endpoints.GetUnReliableEndpoints() {
-		telemetryName := fmt.Sprintf("%s_%d_unreliable_%d", desc.eventType, pipelineID, i)
+		telemetryName := fmt.Sprintf("%s_%d_unreliable_%d", telemetryPrefix, pipelineID, i)
 		additionals = append(additionals, http.NewDestination(endpoint, desc.contentType, destinationsContext, endpoints.BatchMaxConcurrentSend, false, telemetryName))
 	}
 	destinations := client.NewDestinations(reliable, additionals)
@@ -361,11 +375,15 @@ func joinHosts(endpoints []config.Endpoint) string {
 }
 
 func newDefaultEventPlatformForwarder() *defaultEventPlatformForwarder {
+	return newEventPlatformForwarder(passthroughPipelineDescs, nil)
+}
+
+func newEventPlatformForwarder(descs []passthroughPipelineDesc, tenant *Tenant) *defaultEventPlatformForwarder {
 	destinationsCtx := client.NewDestinationsContext()
 	destinationsCtx.Start()
 	pipelines := make(map[string]*passthroughPipeline)
-	for i, desc := range passthroughPipelineDescs {
-		p, err := newHTTPPassthroughPipeline(desc, destinationsCtx, i)
+	for i, desc := range descs {
+		p, err := newHTTPPassthroughPipeline(desc, destinationsCtx, i, tenant)
 		if err != nil {
 			log.Errorf("Failed to initialize event platform forwarder pipeline. eventType=%s, error=%s", desc.eventType, err.Error())
 			continue
@@ -383,6 +401,21 @@ func NewEventPlatformForwarder() EventPlatformForwarder {
 	return newDefaultEventPlatformForwarder()
 }
 
+// NewTenantEventPlatformForwarder creates an EventPlatformForwarder sending the events of the given types to
+// the organization of the tenant. Each tenant forwarder has its own pipelines, so that a tenant whose intake
+// is slow doesn't delay the events of the others.
+func NewTenantEventPlatformForwarder(tenant Tenant, eventTypes ...string) EventPlatformForwarder {
+	var descs []passthroughPipelineDesc
+	for _, desc := range passthroughPipelineDescs {
+		for _, eventType := range eventTypes {
+			if desc.eventType == eventType {
+				descs = append(descs, desc)
+			}
+		}
+	}
+	return newEventPlatformForwarder(descs, &tenant)
+}
+
 // NewNoopEventPlatformForwarder returns the standard event platform forwarder with sending disabled, meaning events
 // will build up in each pipeline channel without being forwarded to the intake
 func NewNoopEventPlatformForwarder() EventPlatformForwarder {
@@ -0,0 +1,79 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package windowsevent
+
+import (
+	"github.com/clbanning/mxj"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+const (
+	channelPath = "Event.System.Channel"
+
+	securityChannel = "Security"
+
+	evtPath      = "evt"
+	evtTitlePath = "title"
+
+	auditEventCategory = "security"
+)
+
+// auditEvent describes a higher-level event synthesized from a security audit event
+type auditEvent struct {
+	name   string
+	title  string
+	status string
+}
+
+// auditEvents maps the IDs of the events of the Security channel tampering with the audit
+// to the event they are promoted to
+// https://learn.microsoft.com/en-us/windows/security/threat-protection/auditing/event-4719
+// https://learn.microsoft.com/en-us/windows/security/threat-protection/auditing/event-1102
+var auditEvents = map[string]auditEvent{
+	"4719": {
+		name:   "audit_policy_change",
+		title:  "System audit policy was changed",
+		status: message.StatusWarning,
+	},
+	"1102": {
+		name:   "audit_log_cleared",
+		title:  "The audit log was cleared",
+		status: message.StatusError,
+	},
+}
+
+// lookupAuditEvent returns the audit event matching the normalized event, if any
+func lookupAuditEvent(mv mxj.Map) (auditEvent, bool) {
+	channel, err := mv.ValueForPathString(channelPath)
+	if err != nil || channel != securityChannel {
+		return auditEvent{}, false
+	}
+	// The event ID must be normalized first, see normalizeEventID
+	eventID, err := mv.ValueForPathString(eventIDPath)
+	if err != nil {
+		return auditEvent{}, false
+	}
+	ae, found := auditEvents[eventID]
+	return ae, found
+}
+
+// setAuditEvent adds the attributes of the audit event to the normalized event
+func setAuditEvent(mv mxj.Map, ae auditEvent) {
+	_ = mv.SetValueForPath(map[string]interface{}{
+		"name":     ae.name,
+		"category": auditEventCategory,
+	}, evtPath)
+	_ = mv.SetValueForPath(ae.title, evtTitlePath)
+}
+
+// tags returns the tags of the messages of the audit event
+func (ae auditEvent) tags(eventID string) []string {
+	return []string{
+		"security_event:" + ae.name,
+		"event_id:" + eventID,
+	}
+}
@@ -21,6 +21,7 @@ typedef struct RichEvent_t {
 } RichEvent ;
 
 ULONGLONG startEventSubscribe(char *channel, char* query, ULONGLONG  ullBookmark, int flags, PVOID ctx);
-RichEvent* EnrichEvent(ULONGLONG ullEvent);
+LPWSTR GetEventProviderName(ULONGLONG ullEvent);
+RichEvent* EnrichEvent(ULONGLONG ullEvent, ULONGLONG ullMetadata);
 
 #endif /* DD_EVENT_H */
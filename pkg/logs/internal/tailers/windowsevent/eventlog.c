@@ -151,37 +151,39 @@ cleanup:
 
 LPWSTR FormatEvtField(EVT_HANDLE hMetadata, EVT_HANDLE hEvent, EVT_FORMAT_MESSAGE_FLAGS FormatId);
 PEVT_VARIANT GetProviderName(EVT_HANDLE hEvent);
-RichEvent* EnrichEvent(ULONGLONG ullEvent)
+
+// Get a copy of the provider name of the event, to be freed by the caller
+LPWSTR GetEventProviderName(ULONGLONG ullEvent)
 {
-    EVT_HANDLE hProviderMetadata = NULL;
-    LPWSTR pwsMessage = NULL;
+    LPWSTR providerName = NULL;
     EVT_HANDLE hEvent = (EVT_HANDLE)(ULONG_PTR) ullEvent;
-    RichEvent *richEvent = (RichEvent*)malloc(sizeof(RichEvent));
 
-    // Get Provider name
     PEVT_VARIANT pRenderedValues = GetProviderName(hEvent);
     if (NULL == pRenderedValues) {
-        free(richEvent);
-        richEvent = NULL;
-        goto cleanup;
+        return NULL;
     }
-
-    LPCWSTR providerName = pRenderedValues[0].StringVal;
-    if (NULL == providerName) {
-        free(richEvent);
-        richEvent = NULL;
-        goto cleanup;
+    if (NULL != pRenderedValues[0].StringVal) {
+        providerName = _wcsdup(pRenderedValues[0].StringVal);
     }
+    free(pRenderedValues);
 
-    // Get Provider metadata
-    hProviderMetadata = EvtOpenPublisherMetadata(NULL, providerName, NULL, 0, 0);
+    return providerName;
+}
 
+// Render the fields of the event with the metadata of its provider, opened with EvtOpenPublisherMetadata.
+// The metadata handle is owned by the caller, the event handle is closed.
+RichEvent* EnrichEvent(ULONGLONG ullEvent, ULONGLONG ullMetadata)
+{
+    EVT_HANDLE hProviderMetadata = (EVT_HANDLE)(ULONG_PTR) ullMetadata;
+    EVT_HANDLE hEvent = (EVT_HANDLE)(ULONG_PTR) ullEvent;
+    RichEvent *richEvent = NULL;
 
-    if (NULL == hProviderMetadata)
-    {
-        wprintf(L"EvtOpenPublisherMetadata failed with %d\n", GetLastError());
-        free(richEvent);
-        richEvent = NULL;
+    if (NULL == hProviderMetadata) {
+        goto cleanup;
+    }
+
+    richEvent = (RichEvent*)malloc(sizeof(RichEvent));
+    if (NULL == richEvent) {
         goto cleanup;
     }
 
@@ -196,12 +198,6 @@ cleanup:
     if (hEvent) {
         EvtClose(hEvent);
     }
-    if (pRenderedValues) {
-        free(pRenderedValues);
-    }
-    if (hProviderMetadata) {
-        EvtClose(hProviderMetadata);
-    }
 
     return richEvent;
 }
@@ -0,0 +1,74 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package windowsevent
+
+import (
+	"sync"
+)
+
+// publisherMetadataCache keeps the publisher metadata handles used to render the events, so that
+// EvtOpenPublisherMetadata is called once per provider instead of once per event. It's shared by
+// all the tailers of the process, high volume channels such as Security having very few providers.
+//
+// Handles are used under a read lock, so that they can't be closed while an event is rendered.
+type publisherMetadataCache struct {
+	mu      sync.RWMutex
+	handles map[string]uintptr
+
+	open  func(provider string) (uintptr, error)
+	close func(handle uintptr)
+}
+
+func newPublisherMetadataCache(open func(provider string) (uintptr, error), close func(handle uintptr)) *publisherMetadataCache {
+	return &publisherMetadataCache{
+		handles: make(map[string]uintptr),
+		open:    open,
+		close:   close,
+	}
+}
+
+// withHandle calls fn with the metadata handle of the provider, opening it if it's not cached yet.
+// Failures to open the metadata aren't cached, so that providers installed later are picked up.
+func (c *publisherMetadataCache) withHandle(provider string, fn func(handle uintptr)) error {
+	c.mu.RLock()
+	if handle, ok := c.handles[provider]; ok {
+		defer c.mu.RUnlock()
+		fn(handle)
+		return nil
+	}
+	c.mu.RUnlock()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	handle, ok := c.handles[provider]
+	if !ok {
+		var err error
+		if handle, err = c.open(provider); err != nil {
+			return err
+		}
+		c.handles[provider] = handle
+	}
+	fn(handle)
+	return nil
+}
+
+// flush closes all the cached handles. It's called when providers are installed, updated or
+// uninstalled, as their metadata, e.g. the message files, may have changed.
+func (c *publisherMetadataCache) flush() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for provider, handle := range c.handles {
+		c.close(handle)
+		delete(c.handles, provider)
+	}
+}
+
+// size returns the number of cached handles
+func (c *publisherMetadataCache) size() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.handles)
+}
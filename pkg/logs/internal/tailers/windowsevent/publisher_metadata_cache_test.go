@@ -0,0 +1,139 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package windowsevent
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakePublisherMetadata mimics EvtOpenPublisherMetadata and EvtClose
+type fakePublisherMetadata struct {
+	mu         sync.Mutex
+	known      map[string]bool
+	nextHandle uintptr
+	opened     map[uintptr]string
+	opens      int
+}
+
+func newFakePublisherMetadata(providers ...string) *fakePublisherMetadata {
+	f := &fakePublisherMetadata{known: make(map[string]bool), opened: make(map[uintptr]string)}
+	for _, provider := range providers {
+		f.known[provider] = true
+	}
+	return f
+}
+
+func (f *fakePublisherMetadata) open(provider string) (uintptr, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.opens++
+	if !f.known[provider] {
+		return 0, errors.New("the publisher metadata cannot be found in the resource")
+	}
+	f.nextHandle++
+	f.opened[f.nextHandle] = provider
+	return f.nextHandle, nil
+}
+
+func (f *fakePublisherMetadata) close(handle uintptr) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.opened, handle)
+}
+
+func TestPublisherMetadataCache(t *testing.T) {
+	fake := newFakePublisherMetadata("Microsoft-Windows-Security-Auditing", "Service Control Manager")
+	cache := newPublisherMetadataCache(fake.open, fake.close)
+
+	var securityHandle uintptr
+	for i := 0; i < 3; i++ {
+		require.NoError(t, cache.withHandle("Microsoft-Windows-Security-Auditing", func(handle uintptr) {
+			securityHandle = handle
+		}))
+	}
+	require.NoError(t, cache.withHandle("Service Control Manager", func(handle uintptr) {
+		assert.NotEqual(t, securityHandle, handle)
+	}))
+	assert.Equal(t, 2, fake.opens)
+	assert.Equal(t, 2, cache.size())
+	assert.Equal(t, "Microsoft-Windows-Security-Auditing", fake.opened[securityHandle])
+
+	// failures aren't cached, so that the provider is picked up once installed
+	called := false
+	assert.Error(t, cache.withHandle("MyApp", func(uintptr) { called = true }))
+	assert.False(t, called)
+	fake.known["MyApp"] = true
+	assert.NoError(t, cache.withHandle("MyApp", func(uintptr) { called = true }))
+	assert.True(t, called)
+	assert.Equal(t, 4, fake.opens)
+
+	cache.flush()
+	assert.Zero(t, cache.size())
+	assert.Empty(t, fake.opened)
+
+	require.NoError(t, cache.withHandle("Microsoft-Windows-Security-Auditing", func(uintptr) {}))
+	assert.Equal(t, 5, fake.opens)
+}
+
+func TestPublisherMetadataCacheConcurrentFlush(t *testing.T) {
+	fake := newFakePublisherMetadata("Microsoft-Windows-Security-Auditing")
+	cache := newPublisherMetadataCache(fake.open, fake.close)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				_ = cache.withHandle("Microsoft-Windows-Security-Auditing", func(handle uintptr) {
+					// the handle can't be closed while it's used
+					fake.mu.Lock()
+					defer fake.mu.Unlock()
+					assert.Contains(t, fake.opened, handle)
+				})
+			}
+		}()
+	}
+	for i := 0; i < 100; i++ {
+		cache.flush()
+	}
+	wg.Wait()
+}
+
+// BenchmarkPublisherMetadataCache renders events with the distribution of providers of a busy
+// Security channel, where nearly all the events come from the auditing provider.
+func BenchmarkPublisherMetadataCache(b *testing.B) {
+	providers := []string{
+		"Microsoft-Windows-Security-Auditing",
+		"Microsoft-Windows-Security-Auditing",
+		"Microsoft-Windows-Security-Auditing",
+		"Microsoft-Windows-Security-Auditing",
+		"Microsoft-Windows-Security-Auditing",
+		"Microsoft-Windows-Security-Auditing",
+		"Microsoft-Windows-Security-Auditing",
+		"Microsoft-Windows-Security-Auditing",
+		"Microsoft-Windows-Eventlog",
+		"Microsoft-Windows-Security-Mitigations",
+	}
+	fake := newFakePublisherMetadata(providers...)
+	cache := newPublisherMetadataCache(fake.open, fake.close)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			_ = cache.withHandle(providers[i%len(providers)], func(uintptr) {})
+			i++
+		}
+	})
+	b.ReportMetric(float64(fake.opens)/float64(b.N), "opens/op")
+}
@@ -0,0 +1,83 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build windows
+// +build windows
+
+package windowsevent
+
+import (
+	"sync"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// publishersRegistryKey holds a subkey per provider registered on the host
+const publishersRegistryKey = `SOFTWARE\Microsoft\Windows\CurrentVersion\WINEVT\Publishers`
+
+var (
+	procEvtOpenPublisherMetadata = modWinEvtAPI.NewProc("EvtOpenPublisherMetadata")
+	procEvtClose                 = modWinEvtAPI.NewProc("EvtClose")
+
+	globalPublisherMetadataCache     *publisherMetadataCache
+	globalPublisherMetadataCacheOnce sync.Once
+)
+
+// getPublisherMetadataCache returns the publisher metadata cache of the process, and starts watching
+// the providers registrations the first time it's called
+func getPublisherMetadataCache() *publisherMetadataCache {
+	globalPublisherMetadataCacheOnce.Do(func() {
+		globalPublisherMetadataCache = newPublisherMetadataCache(openPublisherMetadata, closeEvtHandle)
+		go watchPublishers(globalPublisherMetadataCache)
+	})
+	return globalPublisherMetadataCache
+}
+
+func openPublisherMetadata(provider string) (uintptr, error) {
+	providerPtr, err := windows.UTF16PtrFromString(provider)
+	if err != nil {
+		return 0, err
+	}
+	handle, _, err := procEvtOpenPublisherMetadata.Call(
+		uintptr(0), // local session
+		uintptr(unsafe.Pointer(providerPtr)),
+		uintptr(0), // no archived log file
+		uintptr(0), // current locale
+		uintptr(0)) // reserved
+	if handle == 0 {
+		return 0, err
+	}
+	return handle, nil
+}
+
+func closeEvtHandle(handle uintptr) {
+	_, _, _ = procEvtClose.Call(handle)
+}
+
+// watchPublishers flushes the cache whenever a provider is installed, updated or uninstalled,
+// i.e. whenever the providers registry key or one of its subkeys changes.
+func watchPublishers(cache *publisherMetadataCache) {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, publishersRegistryKey, registry.NOTIFY)
+	if err != nil {
+		log.Warnf("Unable to watch the event log providers registrations, the publisher metadata cache won't be refreshed: %s", err)
+		return
+	}
+	defer key.Close()
+
+	for {
+		// blocks until the key or one of its subkeys changes
+		err := windows.RegNotifyChangeKeyValue(windows.Handle(key), true, windows.REG_NOTIFY_CHANGE_NAME|windows.REG_NOTIFY_CHANGE_LAST_SET, 0, false)
+		if err != nil {
+			log.Warnf("Stopped watching the event log providers registrations: %s", err)
+			return
+		}
+		log.Debugf("Event log providers changed, flushing %d cached publisher metadata handles", cache.size())
+		cache.flush()
+	}
+}
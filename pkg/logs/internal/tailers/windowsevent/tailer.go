@@ -121,6 +121,16 @@ func (t *Tailer) toMessage(re *richEvent) (*message.Message, error) { //nolint:u
 		log.Debugf("Error normalizing EventID: %s", err)
 	}
 
+	// Promote the events tampering with the audit to security events
+	status := message.StatusInfo
+	var tags []string
+	if ae, found := lookupAuditEvent(mv); found {
+		setAuditEvent(mv, ae)
+		eventID, _ := mv.ValueForPathString(eventIDPath)
+		status = ae.status
+		tags = ae.tags(eventID)
+	}
+
 	// Replace Task and Opcode codes by the rendered value
 	if re.task != "" {
 		_, _ = mv.UpdateValuesForPath("Task:"+re.task, taskPath)
@@ -142,7 +152,11 @@ func (t *Tailer) toMessage(re *richEvent) (*message.Message, error) { //nolint:u
 	}
 	jsonEvent = replaceTextKeyToValue(jsonEvent)
 	log.Debug("Sending JSON:", string(jsonEvent))
-	return message.NewMessageWithSource(jsonEvent, message.StatusInfo, t.source, time.Now().UnixNano()), nil
+	msg := message.NewMessageWithSource(jsonEvent, status, t.source, time.Now().UnixNano())
+	if len(tags) > 0 {
+		msg.Origin.SetTags(tags)
+	}
+	return msg, nil
 }
 
 // EventID sometimes comes in like <EventID>7036</EventID>
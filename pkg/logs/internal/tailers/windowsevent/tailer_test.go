@@ -9,6 +9,10 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/logs/sources"
 )
 
 func TestToMessage(t *testing.T) {
@@ -62,6 +66,36 @@ func TestToMessage(t *testing.T) {
 	assert.Equal(t, expected7, string(actual.Content))
 }
 
+func TestToMessageAuditEvents(t *testing.T) {
+	source := sources.NewLogSource("", &config.LogsConfig{})
+	tailer := NewTailer(source, &Config{ChannelPath: "Security"}, nil)
+
+	// Audit policy change
+	evt1 := `<Event xmlns='http://schemas.microsoft.com/win/2004/08/events/event'><System><Provider Name='Microsoft-Windows-Security-Auditing' Guid='{54849625-5478-4994-a5ba-3e3b0328c30d}'/><EventID>4719</EventID><Version>0</Version><Level>0</Level><Task>13568</Task><Opcode>0</Opcode><Keywords>0x8020000000000000</Keywords><TimeCreated SystemTime='2023-03-14T10:12:03.491720700Z'/><EventRecordID>4521</EventRecordID><Correlation/><Execution ProcessID='748' ThreadID='3804'/><Channel>Security</Channel><Computer>windows-n7iefg2</Computer><Security/></System><EventData><Data Name='SubjectUserName'>WIN-GG82ULGC9GO$</Data><Data Name='CategoryId'>%%8274</Data><Data Name='AuditPolicyChanges'>%%8448</Data></EventData></Event>`
+	expected1 := `{"Event":{"EventData":{"Data":{"AuditPolicyChanges":"%%8448","CategoryId":"%%8274","SubjectUserName":"WIN-GG82ULGC9GO$"}},"System":{"Channel":"Security","Computer":"windows-n7iefg2","Correlation":"","EventID":"4719","EventRecordID":"4521","Execution":{"ProcessID":"748","ThreadID":"3804"},"Keywords":"0x8020000000000000","Level":"0","Opcode":"0","Provider":{"Guid":"{54849625-5478-4994-a5ba-3e3b0328c30d}","Name":"Microsoft-Windows-Security-Auditing"},"Security":"","Task":"13568","TimeCreated":{"SystemTime":"2023-03-14T10:12:03.491720700Z"},"Version":"0"},"xmlns":"http://schemas.microsoft.com/win/2004/08/events/event"},"evt":{"category":"security","name":"audit_policy_change"},"title":"System audit policy was changed"}`
+	actual, err := tailer.toMessage(richEventFromXML(evt1))
+	assert.NoError(t, err)
+	assert.Equal(t, expected1, string(actual.Content))
+	assert.Equal(t, message.StatusWarning, actual.GetStatus())
+	assert.Equal(t, []string{"security_event:audit_policy_change", "event_id:4719"}, actual.Origin.Tags())
+
+	// Audit log cleared
+	evt2 := `<Event xmlns='http://schemas.microsoft.com/win/2004/08/events/event'><System><Provider Name='Microsoft-Windows-Eventlog' Guid='{fc65ddd8-d6ef-4962-83d5-6e5cfe9ce148}'/><EventID>1102</EventID><Version>0</Version><Level>4</Level><Task>104</Task><Opcode>0</Opcode><Keywords>0x4020000000000000</Keywords><TimeCreated SystemTime='2023-03-14T10:15:41.139820600Z'/><EventRecordID>4522</EventRecordID><Correlation/><Execution ProcessID='1044' ThreadID='2332'/><Channel>Security</Channel><Computer>windows-n7iefg2</Computer><Security/></System></Event>`
+	actual, err = tailer.toMessage(richEventFromXML(evt2))
+	assert.NoError(t, err)
+	assert.Contains(t, string(actual.Content), `"evt":{"category":"security","name":"audit_log_cleared"},"title":"The audit log was cleared"`)
+	assert.Equal(t, message.StatusError, actual.GetStatus())
+	assert.Equal(t, []string{"security_event:audit_log_cleared", "event_id:1102"}, actual.Origin.Tags())
+
+	// Same event ID on another channel
+	evt3 := `<Event xmlns='http://schemas.microsoft.com/win/2004/08/events/event'><System><Provider Name='Some Provider'/><EventID Qualifiers='16384'>1102</EventID><Level>4</Level><Channel>Application</Channel><Computer>windows-n7iefg2</Computer></System></Event>`
+	actual, err = tailer.toMessage(richEventFromXML(evt3))
+	assert.NoError(t, err)
+	assert.NotContains(t, string(actual.Content), `"evt"`)
+	assert.Equal(t, message.StatusInfo, actual.GetStatus())
+	assert.Empty(t, actual.Origin.Tags())
+}
+
 func richEventFromXML(xml string) *richEvent {
 	return &richEvent{xmlEvent: xml}
 }
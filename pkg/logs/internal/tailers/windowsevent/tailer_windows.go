@@ -153,7 +153,23 @@ func EvtRender(h C.ULONGLONG) (richEvt *richEvent, err error) {
 func enrichEvent(h C.ULONGLONG, xml string) *richEvent {
 	var message, task, opcode, level string
 	// Enrich event with rendered
-	richEvtCStruct := C.EnrichEvent(h)
+	var richEvtCStruct *C.RichEvent
+	var rendered bool
+	if cProvider := C.GetEventProviderName(h); cProvider != nil {
+		provider := LPWSTRToString(cProvider)
+		C.free(unsafe.Pointer(cProvider))
+		err := getPublisherMetadataCache().withHandle(provider, func(metadata uintptr) {
+			richEvtCStruct = C.EnrichEvent(h, C.ULONGLONG(metadata))
+			rendered = true
+		})
+		if err != nil {
+			log.Debugf("Couldn't open publisher metadata of provider %s: %s", provider, err)
+		}
+	}
+	if !rendered {
+		// closes the event handle
+		C.EnrichEvent(h, C.ULONGLONG(0))
+	}
 	if richEvtCStruct != nil {
 		if richEvtCStruct.message != nil {
 			message = LPWSTRToString(richEvtCStruct.message)
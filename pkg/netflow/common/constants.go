@@ -21,6 +21,13 @@ const (
 	// DefaultAggregatorRollupTrackerRefreshInterval is the default aggregator rollup tracker refresh interval
 	DefaultAggregatorRollupTrackerRefreshInterval = 300 // 5min
 
+	// DefaultClockSkewThreshold is the default difference in seconds between the flow end timestamp and the
+	// agent time above which the exporter clock is considered skewed
+	DefaultClockSkewThreshold = 300 // 5min
+
+	// DefaultGeoIPCacheSize is the default number of IPs whose GeoIP tags are kept in cache
+	DefaultGeoIPCacheSize = 10000
+
 	// DefaultBindHost is the default bind host used for flow listeners
 	DefaultBindHost = "0.0.0.0"
 
@@ -22,6 +22,14 @@ type Flow struct {
 	// Exporter information
 	ExporterAddr []byte
 
+	// SequenceNum is the sequence number of the exporter packet the flow was received in
+	SequenceNum uint32
+	// ObservationDomain is the NetFlow v9 source ID or the IPFIX observation domain ID of the exporter packet,
+	// the sequence numbers and templates of an exporter being scoped to it
+	ObservationDomain uint32
+	// SamplerID is the ID of the sampler of the flow, 0 if not exported
+	SamplerID uint64
+
 	// Flow time
 	StartTimestamp uint64 // in seconds
 	EndTimestamp   uint64 // in seconds
@@ -52,6 +60,10 @@ type Flow struct {
 	InputInterface  uint32 // FLOW KEY
 	OutputInterface uint32
 
+	// Interface names, from the options data records of the exporter, empty when unknown
+	InputInterfaceName  string
+	OutputInterfaceName string
+
 	// Mac Address
 	SrcMac uint64
 	DstMac uint64
@@ -64,12 +76,23 @@ type Flow struct {
 	Tos uint32 // FLOW KEY
 
 	NextHop []byte // FLOW KEY
+
+	// Reason the exporter ended the flow (IPFIX flowEndReason), 0 if not exported
+	FlowEndReason uint32
+
+	// ClockSkewCorrected is true when the flow timestamps were rewritten to the receive time
+	// because the exporter clock was off
+	ClockSkewCorrected bool
+
+	// Tenant is the name of the tenant to which the flow is sent, empty for the organization of the agent
+	Tenant string
 }
 
 // AggregationHash return a hash used as aggregation key
 func (f *Flow) AggregationHash() uint64 {
 	h := fnv.New64()
 	h.Write([]byte(f.Namespace))                           //nolint:errcheck
+	h.Write([]byte(f.Tenant))                              //nolint:errcheck
 	h.Write(f.ExporterAddr)                                //nolint:errcheck
 	h.Write(f.SrcAddr)                                     //nolint:errcheck
 	h.Write(f.DstAddr)                                     //nolint:errcheck
@@ -81,10 +104,26 @@ func (f *Flow) AggregationHash() uint64 {
 	return h.Sum64()
 }
 
+// FiveTupleHash returns a hash of the 5-tuple of the flow, i.e. its source and destination addresses and
+// ports, and its protocol. Unlike `AggregationHash`, it doesn't depend on the exporter, so that the flows
+// reported by several exporters, e.g. on ingress and egress, share the same hash.
+func (f *Flow) FiveTupleHash() uint64 {
+	h := fnv.New64()
+	h.Write([]byte(f.Namespace))                       //nolint:errcheck
+	h.Write([]byte(f.Tenant))                          //nolint:errcheck
+	h.Write(f.SrcAddr)                                 //nolint:errcheck
+	h.Write(f.DstAddr)                                 //nolint:errcheck
+	binary.Write(h, binary.LittleEndian, f.SrcPort)    //nolint:errcheck
+	binary.Write(h, binary.LittleEndian, f.DstPort)    //nolint:errcheck
+	binary.Write(h, binary.LittleEndian, f.IPProtocol) //nolint:errcheck
+	return h.Sum64()
+}
+
 // IsEqualFlowContext check if the flow and another flow have equal values for all fields used in `AggregationHash`.
 // This method is used for hash collision detection.
 func IsEqualFlowContext(a Flow, b Flow) bool {
 	if a.Namespace == b.Namespace &&
+		a.Tenant == b.Tenant &&
 		bytes.Compare(a.ExporterAddr, b.ExporterAddr) == 0 &&
 		bytes.Compare(a.SrcAddr, b.SrcAddr) == 0 &&
 		bytes.Compare(a.DstAddr, b.DstAddr) == 0 &&
@@ -97,3 +136,15 @@ func IsEqualFlowContext(a Flow, b Flow) bool {
 	}
 	return false
 }
+
+// IsEqualFiveTuple check if the flow and another flow have equal values for all fields used in `FiveTupleHash`.
+// This method is used for hash collision detection.
+func IsEqualFiveTuple(a Flow, b Flow) bool {
+	return a.Namespace == b.Namespace &&
+		a.Tenant == b.Tenant &&
+		bytes.Equal(a.SrcAddr, b.SrcAddr) &&
+		bytes.Equal(a.DstAddr, b.DstAddr) &&
+		a.SrcPort == b.SrcPort &&
+		a.DstPort == b.DstPort &&
+		a.IPProtocol == b.IPProtocol
+}
@@ -89,6 +89,44 @@ func TestFlow_AggregationHash(t *testing.T) {
 	assert.Equal(t, 10, len(allHash))
 }
 
+func TestFlow_FiveTupleHash(t *testing.T) {
+	origFlow := Flow{
+		Namespace:      "default",
+		ExporterAddr:   []byte{127, 0, 0, 1},
+		SrcAddr:        []byte{1, 2, 3, 4},
+		DstAddr:        []byte{2, 3, 4, 5},
+		IPProtocol:     6,
+		SrcPort:        2000,
+		DstPort:        80,
+		InputInterface: 1,
+		Tos:            0,
+	}
+	origHash := origFlow.FiveTupleHash()
+
+	// the exporter, interface and ToS aren't part of the 5-tuple
+	flow := origFlow
+	flow.ExporterAddr = []byte{127, 0, 0, 2}
+	flow.InputInterface = 2
+	flow.Tos = 1
+	assert.Equal(t, origHash, flow.FiveTupleHash())
+	assert.True(t, IsEqualFiveTuple(origFlow, flow))
+
+	for _, update := range []func(*Flow){
+		func(f *Flow) { f.Namespace = "my-new-ns" },
+		func(f *Flow) { f.Tenant = "my-tenant" },
+		func(f *Flow) { f.SrcAddr = []byte{1, 2, 3, 5} },
+		func(f *Flow) { f.DstAddr = []byte{2, 3, 4, 6} },
+		func(f *Flow) { f.SrcPort = 2001 },
+		func(f *Flow) { f.DstPort = 81 },
+		func(f *Flow) { f.IPProtocol = 17 },
+	} {
+		flow := origFlow
+		update(&flow)
+		assert.NotEqual(t, origHash, flow.FiveTupleHash())
+		assert.False(t, IsEqualFiveTuple(origFlow, flow))
+	}
+}
+
 func TestFlow_IsEqualFlowContext(t *testing.T) {
 	origFlow := Flow{
 		Namespace:      "default",
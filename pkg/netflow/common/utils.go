@@ -6,6 +6,7 @@
 package common
 
 import (
+	"fmt"
 	"net"
 )
 
@@ -40,3 +41,19 @@ func IPBytesToString(ip []byte) string {
 	}
 	return net.IP(ip).String()
 }
+
+// ParseIPOrCIDR parses an IP, e.g. 10.0.0.1, or a CIDR, e.g. 10.0.0.0/24, into a network. An IP is
+// parsed into a network holding only this IP.
+func ParseIPOrCIDR(s string) (*net.IPNet, error) {
+	if _, ipNet, err := net.ParseCIDR(s); err == nil {
+		return ipNet, nil
+	}
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return nil, fmt.Errorf("not an IP or a CIDR")
+	}
+	if ipv4 := ip.To4(); ipv4 != nil {
+		return &net.IPNet{IP: ipv4, Mask: net.CIDRMask(32, 32)}, nil
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}, nil
+}
@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestMaxUint64(t *testing.T) {
@@ -32,3 +33,25 @@ func TestIPBytesToString(t *testing.T) {
 	assert.Equal(t, "127.0.0.1", IPBytesToString([]byte{127, 0, 0, 1}))
 	assert.Equal(t, "255.255.255.255", IPBytesToString([]byte{255, 255, 255, 255}))
 }
+
+func TestParseIPOrCIDR(t *testing.T) {
+	for _, tc := range []struct {
+		input    string
+		expected string
+	}{
+		{input: "10.0.0.1", expected: "10.0.0.1/32"},
+		{input: "10.0.0.0/24", expected: "10.0.0.0/24"},
+		{input: "10.0.0.12/24", expected: "10.0.0.0/24"},
+		{input: "2001:db8::1", expected: "2001:db8::1/128"},
+		{input: "2001:db8::/32", expected: "2001:db8::/32"},
+	} {
+		ipNet, err := ParseIPOrCIDR(tc.input)
+		require.NoError(t, err, tc.input)
+		assert.Equal(t, tc.expected, ipNet.String())
+	}
+
+	for _, input := range []string{"", "10.0.0", "10.0.0.0/33", "my-host"} {
+		_, err := ParseIPOrCIDR(input)
+		assert.Error(t, err, input)
+	}
+}
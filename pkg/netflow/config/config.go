@@ -25,11 +25,49 @@ type NetflowConfig struct {
 	AggregatorPortRollupThreshold int              `mapstructure:"aggregator_port_rollup_threshold"`
 	AggregatorPortRollupDisabled  bool             `mapstructure:"aggregator_port_rollup_disabled"`
 
+	// AggregatorDeduplicationEnabled aggregates the flows by 5-tuple, and deduplicates the flows reported
+	// at several observation points, e.g. by a router on egress and the next one on ingress
+	AggregatorDeduplicationEnabled bool `mapstructure:"aggregator_deduplication_enabled"`
+
 	// AggregatorRollupTrackerRefreshInterval is useful to speed up testing to avoid wait for 1h default
 	AggregatorRollupTrackerRefreshInterval uint `mapstructure:"aggregator_rollup_tracker_refresh_interval"`
 
 	PrometheusListenerAddress string `mapstructure:"prometheus_listener_address"` // Example `localhost:9090`
 	PrometheusListenerEnabled bool   `mapstructure:"prometheus_listener_enabled"`
+
+	KubernetesEnrichmentEnabled bool `mapstructure:"kubernetes_enrichment_enabled"`
+
+	// ClockSkewThreshold is the difference in seconds between the flow end timestamp and the agent time
+	// above which the exporter clock is considered skewed
+	ClockSkewThreshold int `mapstructure:"clock_skew_threshold"`
+	// ClockSkewCorrectionEnabled rewrites the timestamps of the flows of skewed exporters to their receive time
+	ClockSkewCorrectionEnabled bool `mapstructure:"clock_skew_correction_enabled"`
+
+	GeoIP GeoIPConfig `mapstructure:"geoip"`
+
+	// Tenants are the Datadog organizations, other than the one of the agent, to which the flows of some
+	// exporters are sent, e.g. for a MSP collecting the flows of the devices of its customers
+	Tenants []TenantConfig `mapstructure:"tenants"`
+}
+
+// TenantConfig contains configuration for a Datadog organization to which flows are routed
+type TenantConfig struct {
+	Name   string `mapstructure:"name"`
+	APIKey string `mapstructure:"api_key"`
+	// Exporters are the IPs or CIDRs of the exporters whose flows are sent to the tenant, whichever
+	// listener they're received on
+	Exporters []string `mapstructure:"exporters"`
+}
+
+// GeoIPConfig contains configuration for the GeoIP enrichment of the flow endpoints
+type GeoIPConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// CountryDatabasePath and ASNDatabasePath are paths to MaxMind databases (MMDB format),
+	// e.g. GeoLite2-Country.mmdb and GeoLite2-ASN.mmdb
+	CountryDatabasePath string `mapstructure:"country_database_path"`
+	ASNDatabasePath     string `mapstructure:"asn_database_path"`
+	// CacheSize is the number of IPs whose GeoIP tags are kept in cache
+	CacheSize int `mapstructure:"cache_size"`
 }
 
 // ListenerConfig contains configuration for a single flow listener
@@ -39,6 +77,10 @@ type ListenerConfig struct {
 	BindHost  string          `mapstructure:"bind_host"`
 	Workers   int             `mapstructure:"workers"`
 	Namespace string          `mapstructure:"namespace"`
+	// Tenant is the name of the tenant to which the flows received by the listener are sent, unless
+	// their exporter belongs to another tenant. The flows are sent to the organization of the agent
+	// when empty.
+	Tenant string `mapstructure:"tenant"`
 }
 
 // ReadConfig builds and returns configuration from Agent configuration.
@@ -49,6 +91,11 @@ func ReadConfig() (*NetflowConfig, error) {
 	if err != nil {
 		return nil, err
 	}
+	tenants, err := validateTenants(mainConfig.Tenants)
+	if err != nil {
+		return nil, err
+	}
+
 	for i := range mainConfig.Listeners {
 		listenerConfig := &mainConfig.Listeners[i]
 
@@ -77,6 +124,10 @@ func ReadConfig() (*NetflowConfig, error) {
 			return nil, fmt.Errorf("invalid namespace `%s` error: %s", listenerConfig.Namespace, err)
 		}
 		listenerConfig.Namespace = normalizedNamespace
+
+		if _, ok := tenants[listenerConfig.Tenant]; listenerConfig.Tenant != "" && !ok {
+			return nil, fmt.Errorf("the tenant `%s` of the listener on %s is not defined", listenerConfig.Tenant, listenerConfig.Addr())
+		}
 	}
 
 	if mainConfig.StopTimeout == 0 {
@@ -100,6 +151,17 @@ func ReadConfig() (*NetflowConfig, error) {
 		mainConfig.AggregatorRollupTrackerRefreshInterval = common.DefaultAggregatorRollupTrackerRefreshInterval
 	}
 
+	if mainConfig.ClockSkewThreshold == 0 {
+		mainConfig.ClockSkewThreshold = common.DefaultClockSkewThreshold
+	}
+
+	if mainConfig.GeoIP.CacheSize == 0 {
+		mainConfig.GeoIP.CacheSize = common.DefaultGeoIPCacheSize
+	}
+	if mainConfig.GeoIP.Enabled && mainConfig.GeoIP.CountryDatabasePath == "" && mainConfig.GeoIP.ASNDatabasePath == "" {
+		return nil, fmt.Errorf("GeoIP enrichment is enabled but neither `country_database_path` nor `asn_database_path` is set")
+	}
+
 	if mainConfig.PrometheusListenerAddress == "" {
 		mainConfig.PrometheusListenerAddress = common.DefaultPrometheusListenerAddress
 	}
@@ -107,6 +169,29 @@ func ReadConfig() (*NetflowConfig, error) {
 	return &mainConfig, nil
 }
 
+// validateTenants checks the tenants and returns them by name
+func validateTenants(tenants []TenantConfig) (map[string]TenantConfig, error) {
+	tenantsByName := make(map[string]TenantConfig, len(tenants))
+	for _, tenant := range tenants {
+		if tenant.Name == "" {
+			return nil, fmt.Errorf("a tenant has no name")
+		}
+		if _, ok := tenantsByName[tenant.Name]; ok {
+			return nil, fmt.Errorf("the tenant `%s` is defined more than once", tenant.Name)
+		}
+		if tenant.APIKey == "" {
+			return nil, fmt.Errorf("the tenant `%s` has no API key", tenant.Name)
+		}
+		for _, exporter := range tenant.Exporters {
+			if _, err := common.ParseIPOrCIDR(exporter); err != nil {
+				return nil, fmt.Errorf("invalid exporter `%s` for the tenant `%s`: %w", exporter, tenant.Name, err)
+			}
+		}
+		tenantsByName[tenant.Name] = tenant
+	}
+	return tenantsByName, nil
+}
+
 // Addr returns the host:port address to listen on.
 func (c *ListenerConfig) Addr() string {
 	return fmt.Sprintf("%s:%d", c.BindHost, c.Port)
@@ -37,8 +37,17 @@ network_devices:
     aggregator_rollup_tracker_refresh_interval: 60
     log_payloads: true
     aggregator_port_rollup_disabled: true
+    aggregator_deduplication_enabled: true
     prometheus_listener_enabled: true
     prometheus_listener_address: 127.0.0.1:9099
+    kubernetes_enrichment_enabled: true
+    clock_skew_threshold: 120
+    clock_skew_correction_enabled: true
+    geoip:
+      enabled: true
+      country_database_path: /etc/datadog-agent/GeoLite2-Country.mmdb
+      asn_database_path: /etc/datadog-agent/GeoLite2-ASN.mmdb
+      cache_size: 500
     listeners:
       - flow_type: netflow9
         bind_host: 127.0.0.1
@@ -61,8 +70,18 @@ network_devices:
 				AggregatorPortRollupThreshold:          20,
 				AggregatorRollupTrackerRefreshInterval: 60,
 				AggregatorPortRollupDisabled:           true,
+				AggregatorDeduplicationEnabled:         true,
 				PrometheusListenerEnabled:              true,
 				PrometheusListenerAddress:              "127.0.0.1:9099",
+				KubernetesEnrichmentEnabled:            true,
+				ClockSkewThreshold:                     120,
+				ClockSkewCorrectionEnabled:             true,
+				GeoIP: GeoIPConfig{
+					Enabled:             true,
+					CountryDatabasePath: "/etc/datadog-agent/GeoLite2-Country.mmdb",
+					ASNDatabasePath:     "/etc/datadog-agent/GeoLite2-ASN.mmdb",
+					CacheSize:           500,
+				},
 				Listeners: []ListenerConfig{
 					{
 						FlowType:  common.TypeNetFlow9,
@@ -97,6 +116,8 @@ network_devices:
 				AggregatorFlowContextTTL:               300,
 				AggregatorPortRollupThreshold:          10,
 				AggregatorRollupTrackerRefreshInterval: 300,
+				ClockSkewThreshold:                     300,
+				GeoIP:                                  GeoIPConfig{CacheSize: 10000},
 				PrometheusListenerAddress:              "localhost:9090",
 				Listeners: []ListenerConfig{
 					{
@@ -126,6 +147,8 @@ network_devices:
 				AggregatorFlowContextTTL:               50,
 				AggregatorPortRollupThreshold:          10,
 				AggregatorRollupTrackerRefreshInterval: 300,
+				ClockSkewThreshold:                     300,
+				GeoIP:                                  GeoIPConfig{CacheSize: 10000},
 				PrometheusListenerAddress:              "localhost:9090",
 				Listeners: []ListenerConfig{
 					{
@@ -149,6 +172,19 @@ network_devices:
 `,
 			expectedError: "the provided flow type `invalidType` is not valid",
 		},
+		{
+			name: "geoip enabled without database",
+			configYaml: `
+network_devices:
+  netflow:
+    enabled: true
+    geoip:
+      enabled: true
+    listeners:
+      - flow_type: netflow9
+`,
+			expectedError: "GeoIP enrichment is enabled but neither `country_database_path` nor `asn_database_path` is set",
+		},
 		{
 			name: "invalid namespace with >100 chars",
 			configYaml: `
@@ -161,6 +197,107 @@ network_devices:
 `,
 			expectedError: "invalid namespace `abcdefgabcdefgabcdefgabcdefgabcdefgabcdefgabcdefgabcdefgabcdefgabcdefgabcdefgabcdefgabcdefgabcdefgabcdefgabcdefg` error: namespace is too long, should contain less than 100 characters",
 		},
+		{
+			name: "tenants",
+			configYaml: `
+network_devices:
+  netflow:
+    enabled: true
+    tenants:
+      - name: customer-a
+        api_key: key-a
+        exporters:
+          - 10.0.0.0/24
+          - 192.168.1.1
+      - name: customer-b
+        api_key: key-b
+    listeners:
+      - flow_type: netflow9
+        tenant: customer-b
+`,
+			expectedConfig: NetflowConfig{
+				StopTimeout:                            5,
+				AggregatorBufferSize:                   10000,
+				AggregatorFlushInterval:                300,
+				AggregatorFlowContextTTL:               300,
+				AggregatorPortRollupThreshold:          10,
+				AggregatorRollupTrackerRefreshInterval: 300,
+				ClockSkewThreshold:                     300,
+				GeoIP:                                  GeoIPConfig{CacheSize: 10000},
+				PrometheusListenerAddress:              "localhost:9090",
+				Tenants: []TenantConfig{
+					{Name: "customer-a", APIKey: "key-a", Exporters: []string{"10.0.0.0/24", "192.168.1.1"}},
+					{Name: "customer-b", APIKey: "key-b"},
+				},
+				Listeners: []ListenerConfig{
+					{
+						FlowType:  common.TypeNetFlow9,
+						BindHost:  "0.0.0.0",
+						Port:      uint16(2055),
+						Workers:   1,
+						Namespace: "default",
+						Tenant:    "customer-b",
+					},
+				},
+			},
+		},
+		{
+			name: "tenant without API key",
+			configYaml: `
+network_devices:
+  netflow:
+    enabled: true
+    tenants:
+      - name: customer-a
+    listeners:
+      - flow_type: netflow9
+`,
+			expectedError: "the tenant `customer-a` has no API key",
+		},
+		{
+			name: "duplicated tenant",
+			configYaml: `
+network_devices:
+  netflow:
+    enabled: true
+    tenants:
+      - name: customer-a
+        api_key: key-a
+      - name: customer-a
+        api_key: key-b
+    listeners:
+      - flow_type: netflow9
+`,
+			expectedError: "the tenant `customer-a` is defined more than once",
+		},
+		{
+			name: "invalid tenant exporter",
+			configYaml: `
+network_devices:
+  netflow:
+    enabled: true
+    tenants:
+      - name: customer-a
+        api_key: key-a
+        exporters:
+          - 10.0.0.300
+    listeners:
+      - flow_type: netflow9
+`,
+			expectedError: "invalid exporter `10.0.0.300` for the tenant `customer-a`",
+		},
+		{
+			name: "undefined listener tenant",
+			configYaml: `
+network_devices:
+  netflow:
+    enabled: true
+    listeners:
+      - flow_type: netflow9
+        tenant: customer-a
+`,
+			expectedError: "the tenant `customer-a` of the listener on 0.0.0.0:2055 is not defined",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
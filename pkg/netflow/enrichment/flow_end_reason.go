@@ -0,0 +1,24 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package enrichment
+
+// flowEndReasonMapping maps the IPFIX flowEndReason values, see https://www.iana.org/assignments/ipfix/ipfix.xhtml#ipfix-flow-end-reason
+var flowEndReasonMapping = map[uint32]string{
+	1: "idle_timeout",
+	2: "active_timeout",
+	3: "end_of_flow",
+	4: "forced_end",
+	5: "lack_of_resources",
+}
+
+// FlowEndReasonTags returns the `flow_end_reason` tag of a flow, or nil if the reason is unknown
+func FlowEndReasonTags(reason uint32) []string {
+	strReason, ok := flowEndReasonMapping[reason]
+	if !ok {
+		return nil
+	}
+	return []string{"flow_end_reason:" + strReason}
+}
@@ -0,0 +1,22 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package enrichment
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlowEndReasonTags(t *testing.T) {
+	assert.Nil(t, FlowEndReasonTags(0))
+	assert.Equal(t, []string{"flow_end_reason:idle_timeout"}, FlowEndReasonTags(1))
+	assert.Equal(t, []string{"flow_end_reason:active_timeout"}, FlowEndReasonTags(2))
+	assert.Equal(t, []string{"flow_end_reason:end_of_flow"}, FlowEndReasonTags(3))
+	assert.Equal(t, []string{"flow_end_reason:forced_end"}, FlowEndReasonTags(4))
+	assert.Equal(t, []string{"flow_end_reason:lack_of_resources"}, FlowEndReasonTags(5))
+	assert.Nil(t, FlowEndReasonTags(99))
+}
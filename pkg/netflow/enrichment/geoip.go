@@ -0,0 +1,122 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package enrichment
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// geoIPDatabase is implemented by maxminddb.Reader
+type geoIPDatabase interface {
+	Lookup(ip net.IP, result interface{}) error
+	Close() error
+}
+
+type countryRecord struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+}
+
+type asnRecord struct {
+	AutonomousSystemNumber       uint   `maxminddb:"autonomous_system_number"`
+	AutonomousSystemOrganization string `maxminddb:"autonomous_system_organization"`
+}
+
+// GeoIPResolver resolves IPs to their country and autonomous system, using MaxMind
+// databases provided by the user. The tags of the most recently resolved IPs are cached.
+type GeoIPResolver struct {
+	countryDB geoIPDatabase // nil when no country database is configured
+	asnDB     geoIPDatabase // nil when no ASN database is configured
+	cache     *lru.Cache[string, []string]
+}
+
+// NewGeoIPResolver returns a new GeoIPResolver using the MaxMind databases at the given paths.
+// Either path can be empty to skip the corresponding enrichment.
+func NewGeoIPResolver(countryDatabasePath string, asnDatabasePath string, cacheSize int) (*GeoIPResolver, error) {
+	var countryDB, asnDB geoIPDatabase
+	if countryDatabasePath != "" {
+		reader, err := maxminddb.Open(countryDatabasePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open GeoIP country database `%s`: %w", countryDatabasePath, err)
+		}
+		countryDB = reader
+	}
+	if asnDatabasePath != "" {
+		reader, err := maxminddb.Open(asnDatabasePath)
+		if err != nil {
+			if countryDB != nil {
+				countryDB.Close()
+			}
+			return nil, fmt.Errorf("failed to open GeoIP ASN database `%s`: %w", asnDatabasePath, err)
+		}
+		asnDB = reader
+	}
+	return newGeoIPResolver(countryDB, asnDB, cacheSize)
+}
+
+func newGeoIPResolver(countryDB geoIPDatabase, asnDB geoIPDatabase, cacheSize int) (*GeoIPResolver, error) {
+	cache, err := lru.New[string, []string](cacheSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GeoIP cache: %w", err)
+	}
+	return &GeoIPResolver{
+		countryDB: countryDB,
+		asnDB:     asnDB,
+		cache:     cache,
+	}, nil
+}
+
+// Tags returns the `country_iso_code`, `as_number` and `as_organization` tags of the given IP.
+// IPs missing from the databases, e.g. private IPs, have no tags.
+func (r *GeoIPResolver) Tags(ip string) []string {
+	if tags, ok := r.cache.Get(ip); ok {
+		return tags
+	}
+	tags := r.lookup(ip)
+	r.cache.Add(ip, tags)
+	return tags
+}
+
+func (r *GeoIPResolver) lookup(ip string) []string {
+	parsedIP := net.ParseIP(ip)
+	if parsedIP == nil {
+		return nil
+	}
+
+	var tags []string
+	if r.countryDB != nil {
+		var record countryRecord
+		if err := r.countryDB.Lookup(parsedIP, &record); err == nil && record.Country.ISOCode != "" {
+			tags = append(tags, "country_iso_code:"+record.Country.ISOCode)
+		}
+	}
+	if r.asnDB != nil {
+		var record asnRecord
+		if err := r.asnDB.Lookup(parsedIP, &record); err == nil && record.AutonomousSystemNumber != 0 {
+			tags = append(tags, "as_number:"+strconv.FormatUint(uint64(record.AutonomousSystemNumber), 10))
+			if record.AutonomousSystemOrganization != "" {
+				tags = append(tags, "as_organization:"+record.AutonomousSystemOrganization)
+			}
+		}
+	}
+	return tags
+}
+
+// Close closes the databases of the GeoIPResolver
+func (r *GeoIPResolver) Close() {
+	if r.countryDB != nil {
+		r.countryDB.Close()
+	}
+	if r.asnDB != nil {
+		r.asnDB.Close()
+	}
+}
@@ -0,0 +1,103 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package enrichment
+
+import (
+	"net"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeGeoIPDatabase mimics maxminddb.Reader, returning records from a map keyed by IP
+type fakeGeoIPDatabase struct {
+	countries map[string]string
+	asns      map[string]asnRecord
+	lookups   int
+	closed    bool
+}
+
+func (db *fakeGeoIPDatabase) Lookup(ip net.IP, result interface{}) error {
+	db.lookups++
+	switch record := result.(type) {
+	case *countryRecord:
+		record.Country.ISOCode = db.countries[ip.String()]
+	case *asnRecord:
+		*record = db.asns[ip.String()]
+	}
+	return nil
+}
+
+func (db *fakeGeoIPDatabase) Close() error {
+	db.closed = true
+	return nil
+}
+
+func TestGeoIPResolver_Tags(t *testing.T) {
+	countryDB := &fakeGeoIPDatabase{countries: map[string]string{
+		"8.8.8.8":              "US",
+		"2001:4860:4860::8888": "US",
+		"1.1.1.1":              "AU",
+	}}
+	asnDB := &fakeGeoIPDatabase{asns: map[string]asnRecord{
+		"8.8.8.8":              {AutonomousSystemNumber: 15169, AutonomousSystemOrganization: "GOOGLE"},
+		"2001:4860:4860::8888": {AutonomousSystemNumber: 15169, AutonomousSystemOrganization: "GOOGLE"},
+		"9.9.9.9":              {AutonomousSystemNumber: 19281},
+	}}
+	resolver, err := newGeoIPResolver(countryDB, asnDB, 10)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"country_iso_code:US", "as_number:15169", "as_organization:GOOGLE"}, resolver.Tags("8.8.8.8"))
+	assert.Equal(t, []string{"country_iso_code:US", "as_number:15169", "as_organization:GOOGLE"}, resolver.Tags("2001:4860:4860::8888"))
+	assert.Equal(t, []string{"country_iso_code:AU"}, resolver.Tags("1.1.1.1"))
+	assert.Equal(t, []string{"as_number:19281"}, resolver.Tags("9.9.9.9"))
+	assert.Nil(t, resolver.Tags("10.0.0.1"))
+	assert.Nil(t, resolver.Tags("not-an-ip"))
+
+	resolver.Close()
+	assert.True(t, countryDB.closed)
+	assert.True(t, asnDB.closed)
+}
+
+func TestGeoIPResolver_TagsWithoutASNDatabase(t *testing.T) {
+	countryDB := &fakeGeoIPDatabase{countries: map[string]string{"8.8.8.8": "US"}}
+	resolver, err := newGeoIPResolver(countryDB, nil, 10)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"country_iso_code:US"}, resolver.Tags("8.8.8.8"))
+	resolver.Close()
+}
+
+func TestGeoIPResolver_Cache(t *testing.T) {
+	countryDB := &fakeGeoIPDatabase{countries: map[string]string{"8.8.8.8": "US", "1.1.1.1": "AU"}}
+	resolver, err := newGeoIPResolver(countryDB, nil, 1)
+	require.NoError(t, err)
+
+	resolver.Tags("8.8.8.8")
+	resolver.Tags("8.8.8.8")
+	assert.Equal(t, 1, countryDB.lookups)
+
+	// unknown IPs are cached as well
+	resolver.Tags("10.0.0.1")
+	resolver.Tags("10.0.0.1")
+	assert.Equal(t, 2, countryDB.lookups)
+
+	// 8.8.8.8 was evicted from the cache
+	assert.Equal(t, []string{"country_iso_code:US"}, resolver.Tags("8.8.8.8"))
+	assert.Equal(t, 3, countryDB.lookups)
+}
+
+func TestNewGeoIPResolver_InvalidDatabase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing.mmdb")
+
+	_, err := NewGeoIPResolver(path, "", 10)
+	assert.ErrorContains(t, err, "failed to open GeoIP country database")
+
+	_, err = NewGeoIPResolver("", path, 10)
+	assert.ErrorContains(t, err, "failed to open GeoIP ASN database")
+}
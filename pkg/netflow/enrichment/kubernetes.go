@@ -0,0 +1,144 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package enrichment
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+)
+
+const podResolverName = "netflow-pod-resolver"
+
+type podInfo struct {
+	ip   string
+	tags []string
+}
+
+// PodResolver resolves IPs to the Kubernetes pods they are assigned to, using the pods
+// known by the workloadmeta store.
+type PodResolver struct {
+	store    workloadmeta.Store
+	stopChan chan struct{}
+	done     chan struct{}
+
+	mu sync.RWMutex
+	// pods structure: map[POD_ID]podInfo
+	pods map[string]podInfo
+	// podsByIP structure: map[IP]map[POD_ID]struct{}
+	podsByIP map[string]map[string]struct{}
+}
+
+// NewPodResolver returns a new PodResolver using the given workloadmeta store.
+// It returns an error if the store isn't available, e.g. when the workloadmeta store isn't started.
+func NewPodResolver(store workloadmeta.Store) (*PodResolver, error) {
+	if store == nil {
+		return nil, errors.New("workloadmeta store not available")
+	}
+	return &PodResolver{
+		store:    store,
+		stopChan: make(chan struct{}),
+		done:     make(chan struct{}),
+		pods:     make(map[string]podInfo),
+		podsByIP: make(map[string]map[string]struct{}),
+	}, nil
+}
+
+// Start starts listening to the pod events of the workloadmeta store
+func (r *PodResolver) Start() {
+	filter := workloadmeta.NewFilter([]workloadmeta.Kind{workloadmeta.KindKubernetesPod}, workloadmeta.SourceAll, workloadmeta.EventTypeAll)
+	ch := r.store.Subscribe(podResolverName, workloadmeta.NormalPriority, filter)
+
+	go func() {
+		defer close(r.done)
+		defer r.store.Unsubscribe(ch)
+		for {
+			select {
+			case bundle := <-ch:
+				// close Ch to indicate that the Store can proceed to the next subscriber
+				close(bundle.Ch)
+				r.processEvents(bundle.Events)
+			case <-r.stopChan:
+				return
+			}
+		}
+	}()
+	log.Info("NetFlow pod resolver started")
+}
+
+// Stop stops the PodResolver
+func (r *PodResolver) Stop() {
+	close(r.stopChan)
+	<-r.done
+}
+
+// Tags returns the tags of the pod the given IP is assigned to. IPs shared by several pods,
+// e.g. the node IP used by host network pods, are not resolved.
+func (r *PodResolver) Tags(ip string) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	podIDs := r.podsByIP[ip]
+	if len(podIDs) != 1 {
+		return nil
+	}
+	for podID := range podIDs {
+		return r.pods[podID].tags
+	}
+	return nil
+}
+
+func (r *PodResolver) processEvents(events []workloadmeta.Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, event := range events {
+		podID := event.Entity.GetID().ID
+		r.removePod(podID)
+
+		if event.Type != workloadmeta.EventTypeSet {
+			continue
+		}
+		pod, ok := event.Entity.(*workloadmeta.KubernetesPod)
+		if !ok || pod.IP == "" {
+			continue
+		}
+
+		r.pods[podID] = podInfo{
+			ip:   pod.IP,
+			tags: podTags(pod),
+		}
+		if _, ok := r.podsByIP[pod.IP]; !ok {
+			r.podsByIP[pod.IP] = make(map[string]struct{})
+		}
+		r.podsByIP[pod.IP][podID] = struct{}{}
+	}
+}
+
+func (r *PodResolver) removePod(podID string) {
+	info, ok := r.pods[podID]
+	if !ok {
+		return
+	}
+	delete(r.pods, podID)
+	delete(r.podsByIP[info.ip], podID)
+	if len(r.podsByIP[info.ip]) == 0 {
+		delete(r.podsByIP, info.ip)
+	}
+}
+
+func podTags(pod *workloadmeta.KubernetesPod) []string {
+	tags := []string{
+		"pod_name:" + pod.Name,
+		"kube_namespace:" + pod.Namespace,
+	}
+	for _, service := range pod.KubeServices {
+		tags = append(tags, "kube_service:"+service)
+	}
+	return tags
+}
@@ -0,0 +1,86 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package enrichment
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+)
+
+func newPod(id string, name string, namespace string, ip string, services ...string) *workloadmeta.KubernetesPod {
+	return &workloadmeta.KubernetesPod{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindKubernetesPod,
+			ID:   id,
+		},
+		EntityMeta: workloadmeta.EntityMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		IP:           ip,
+		KubeServices: services,
+	}
+}
+
+func TestPodResolver_Tags(t *testing.T) {
+	resolver, err := NewPodResolver(workloadmeta.NewMockStore())
+	require.NoError(t, err)
+
+	resolver.processEvents([]workloadmeta.Event{
+		{Type: workloadmeta.EventTypeSet, Entity: newPod("pod-1", "web-1", "frontend", "10.0.0.1", "web")},
+		{Type: workloadmeta.EventTypeSet, Entity: newPod("pod-2", "db-1", "backend", "10.0.0.2")},
+		{Type: workloadmeta.EventTypeSet, Entity: newPod("pod-3", "pending-1", "backend", "")},
+		// host network pods share the node IP
+		{Type: workloadmeta.EventTypeSet, Entity: newPod("pod-4", "agent-1", "datadog", "192.168.1.1")},
+		{Type: workloadmeta.EventTypeSet, Entity: newPod("pod-5", "kube-proxy-1", "kube-system", "192.168.1.1")},
+	})
+
+	assert.Equal(t, []string{"pod_name:web-1", "kube_namespace:frontend", "kube_service:web"}, resolver.Tags("10.0.0.1"))
+	assert.Equal(t, []string{"pod_name:db-1", "kube_namespace:backend"}, resolver.Tags("10.0.0.2"))
+	assert.Nil(t, resolver.Tags("192.168.1.1"))
+	assert.Nil(t, resolver.Tags("10.0.0.3"))
+
+	resolver.processEvents([]workloadmeta.Event{
+		// pod IP changed
+		{Type: workloadmeta.EventTypeSet, Entity: newPod("pod-1", "web-1", "frontend", "10.0.0.3", "web")},
+		// unset events only contain the entity ID
+		{Type: workloadmeta.EventTypeUnset, Entity: &workloadmeta.KubernetesPod{EntityID: workloadmeta.EntityID{Kind: workloadmeta.KindKubernetesPod, ID: "pod-2"}}},
+		{Type: workloadmeta.EventTypeUnset, Entity: &workloadmeta.KubernetesPod{EntityID: workloadmeta.EntityID{Kind: workloadmeta.KindKubernetesPod, ID: "pod-5"}}},
+	})
+
+	assert.Nil(t, resolver.Tags("10.0.0.1"))
+	assert.Equal(t, []string{"pod_name:web-1", "kube_namespace:frontend", "kube_service:web"}, resolver.Tags("10.0.0.3"))
+	assert.Nil(t, resolver.Tags("10.0.0.2"))
+	assert.Equal(t, []string{"pod_name:agent-1", "kube_namespace:datadog"}, resolver.Tags("192.168.1.1"))
+	assert.Len(t, resolver.pods, 2)
+	assert.Len(t, resolver.podsByIP, 2)
+}
+
+func TestNewPodResolver_NilStore(t *testing.T) {
+	resolver, err := NewPodResolver(nil)
+	assert.Error(t, err)
+	assert.Nil(t, resolver)
+}
+
+func TestPodResolver_StartStop(t *testing.T) {
+	store := workloadmeta.NewMockStore()
+	resolver, err := NewPodResolver(store)
+	require.NoError(t, err)
+	resolver.Start()
+	defer resolver.Stop()
+
+	store.SetEntity(newPod("pod-1", "web-1", "frontend", "10.0.0.1"))
+
+	assert.Eventually(t, func() bool {
+		return len(resolver.Tags("10.0.0.1")) > 0
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"pod_name:web-1", "kube_namespace:frontend"}, resolver.Tags("10.0.0.1"))
+}
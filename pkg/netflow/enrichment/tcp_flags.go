@@ -5,6 +5,13 @@
 
 package enrichment
 
+const (
+	tcpProtocol = 6
+
+	synFlag = 2
+	rstFlag = 4
+)
+
 var tcpFlagsMapping = map[uint32]string{
 	1:  "FIN",
 	2:  "SYN",
@@ -33,3 +40,19 @@ func FormatFCPFlags(flags uint32) []string {
 	}
 	return strFlags
 }
+
+// TCPFlagsTags returns tags highlighting abnormal TCP flows: `tcp_flags:syn_only` for flows made only of SYNs,
+// e.g. port scans or unanswered connection attempts, and `tcp_flags:rst` for flows reset by one of the peers.
+func TCPFlagsTags(ipProtocol uint32, flags uint32) []string {
+	if ipProtocol != tcpProtocol {
+		return nil
+	}
+	var tags []string
+	if flags == synFlag {
+		tags = append(tags, "tcp_flags:syn_only")
+	}
+	if flags&rstFlag != 0 {
+		tags = append(tags, "tcp_flags:rst")
+	}
+	return tags
+}
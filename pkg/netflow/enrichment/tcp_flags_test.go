@@ -65,3 +65,42 @@ func TestFormatFCPFlags(t *testing.T) {
 		})
 	}
 }
+
+func TestTCPFlagsTags(t *testing.T) {
+	tests := []struct {
+		name         string
+		ipProtocol   uint32
+		flags        uint32
+		expectedTags []string
+	}{
+		{
+			name:         "SYN only",
+			ipProtocol:   6,
+			flags:        uint32(2),
+			expectedTags: []string{"tcp_flags:syn_only"},
+		},
+		{
+			name:         "SYN RST",
+			ipProtocol:   6,
+			flags:        uint32(6),
+			expectedTags: []string{"tcp_flags:rst"},
+		},
+		{
+			name:         "FIN SYN ACK",
+			ipProtocol:   6,
+			flags:        uint32(19),
+			expectedTags: nil,
+		},
+		{
+			name:         "not TCP",
+			ipProtocol:   17,
+			flags:        uint32(2),
+			expectedTags: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedTags, TCPFlagsTags(tt.ipProtocol, tt.flags))
+		})
+	}
+}
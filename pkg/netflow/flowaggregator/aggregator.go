@@ -8,6 +8,7 @@ package flowaggregator
 import (
 	"encoding/json"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -18,11 +19,13 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/logs/message"
 	"github.com/DataDog/datadog-agent/pkg/metrics"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
 
 	"github.com/DataDog/datadog-agent/pkg/networkdevice/metadata"
 
 	"github.com/DataDog/datadog-agent/pkg/netflow/common"
 	"github.com/DataDog/datadog-agent/pkg/netflow/config"
+	"github.com/DataDog/datadog-agent/pkg/netflow/enrichment"
 	"github.com/DataDog/datadog-agent/pkg/netflow/goflowlib"
 )
 
@@ -37,14 +40,34 @@ type FlowAggregator struct {
 	flowAcc                      *flowAccumulator
 	sender                       aggregator.Sender
 	epForwarder                  epforwarder.EventPlatformForwarder
+	tenants                      *tenantRouter
 	stopChan                     chan struct{}
 	flushLoopDone                chan struct{}
 	runDone                      chan struct{}
 	receivedFlowCount            *atomic.Uint64
 	flushedFlowCount             *atomic.Uint64
+	sentFlowPayloadCount         *atomic.Uint64
+	sentMetadataPayloadCount     *atomic.Uint64
+	flowPayloadErrorCount        *atomic.Uint64
+	metadataPayloadErrorCount    *atomic.Uint64
 	hostname                     string
 	goflowPrometheusGatherer     prometheus.Gatherer
-	timeNowFunction              func() time.Time // Allows to mock time in tests
+	podResolver                  *enrichment.PodResolver   // nil when Kubernetes enrichment is disabled
+	geoIPResolver                *enrichment.GeoIPResolver // nil when GeoIP enrichment is disabled
+	timeNowFunction              func() time.Time          // Allows to mock time in tests
+
+	clockSkewThreshold         int64 // in seconds
+	clockSkewCorrectionEnabled bool
+	// exporterClockSkews holds the last clock skew in seconds seen for each exporter since the last flush
+	exporterClockSkews      map[exporterKey]int64
+	exporterClockSkewsMutex sync.Mutex
+
+	exporterStates *exporterStateStore
+}
+
+type exporterKey struct {
+	namespace string
+	ip        string
 }
 
 // NewFlowAggregator returns a new FlowAggregator
@@ -52,27 +75,58 @@ func NewFlowAggregator(sender aggregator.Sender, epForwarder epforwarder.EventPl
 	flushInterval := time.Duration(config.AggregatorFlushInterval) * time.Second
 	flowContextTTL := time.Duration(config.AggregatorFlowContextTTL) * time.Second
 	rollupTrackerRefreshInterval := time.Duration(config.AggregatorRollupTrackerRefreshInterval) * time.Second
+	var podResolver *enrichment.PodResolver
+	if config.KubernetesEnrichmentEnabled {
+		var err error
+		podResolver, err = enrichment.NewPodResolver(workloadmeta.GetGlobalStore())
+		if err != nil {
+			log.Errorf("Kubernetes enrichment disabled: %s", err)
+		}
+	}
+	var geoIPResolver *enrichment.GeoIPResolver
+	if config.GeoIP.Enabled {
+		var err error
+		geoIPResolver, err = enrichment.NewGeoIPResolver(config.GeoIP.CountryDatabasePath, config.GeoIP.ASNDatabasePath, config.GeoIP.CacheSize)
+		if err != nil {
+			log.Errorf("GeoIP enrichment disabled: %s", err)
+		}
+	}
 	return &FlowAggregator{
 		flowIn:                       make(chan *common.Flow, config.AggregatorBufferSize),
-		flowAcc:                      newFlowAccumulator(flushInterval, flowContextTTL, config.AggregatorPortRollupThreshold, config.AggregatorPortRollupDisabled),
+		flowAcc:                      newFlowAccumulator(flushInterval, flowContextTTL, config.AggregatorPortRollupThreshold, config.AggregatorPortRollupDisabled, config.AggregatorDeduplicationEnabled),
 		flushFlowsToSendInterval:     flushFlowsToSendInterval,
 		rollupTrackerRefreshInterval: rollupTrackerRefreshInterval,
 		sender:                       sender,
 		epForwarder:                  epForwarder,
+		tenants:                      newTenantRouter(config.Tenants),
 		stopChan:                     make(chan struct{}),
 		runDone:                      make(chan struct{}),
 		flushLoopDone:                make(chan struct{}),
 		receivedFlowCount:            atomic.NewUint64(0),
 		flushedFlowCount:             atomic.NewUint64(0),
+		sentFlowPayloadCount:         atomic.NewUint64(0),
+		sentMetadataPayloadCount:     atomic.NewUint64(0),
+		flowPayloadErrorCount:        atomic.NewUint64(0),
+		metadataPayloadErrorCount:    atomic.NewUint64(0),
 		hostname:                     hostname,
 		goflowPrometheusGatherer:     prometheus.DefaultGatherer,
+		podResolver:                  podResolver,
+		geoIPResolver:                geoIPResolver,
 		timeNowFunction:              time.Now,
+		clockSkewThreshold:           int64(config.ClockSkewThreshold),
+		clockSkewCorrectionEnabled:   config.ClockSkewCorrectionEnabled,
+		exporterClockSkews:           make(map[exporterKey]int64),
+		exporterStates:               newExporterStateStore(),
 	}
 }
 
 // Start will start the FlowAggregator worker
 func (agg *FlowAggregator) Start() {
 	log.Info("Flow Aggregator started")
+	if agg.podResolver != nil {
+		agg.podResolver.Start()
+	}
+	agg.tenants.start()
 	go agg.run()
 	agg.flushLoop() // blocking call
 }
@@ -82,6 +136,18 @@ func (agg *FlowAggregator) Stop() {
 	close(agg.stopChan)
 	<-agg.flushLoopDone
 	<-agg.runDone
+	agg.tenants.stop()
+	if agg.podResolver != nil {
+		agg.podResolver.Stop()
+	}
+	if agg.geoIPResolver != nil {
+		agg.geoIPResolver.Close()
+	}
+}
+
+// ExportersStatus returns the health of the exporters the flows were received from
+func (agg *FlowAggregator) ExportersStatus() []ExporterStatus {
+	return agg.exporterStates.status(agg.goflowPrometheusGatherer)
 }
 
 // GetFlowInChan returns flow input chan
@@ -98,33 +164,103 @@ func (agg *FlowAggregator) run() {
 			return
 		case flow := <-agg.flowIn:
 			agg.receivedFlowCount.Inc()
+			agg.exporterStates.observe(flow, agg.timeNowFunction())
+			agg.tenants.resolveTenant(flow)
+			agg.checkClockSkew(flow)
 			agg.flowAcc.add(flow)
 		}
 	}
 }
 
+// checkClockSkew records the clock skew of the flow exporter, i.e. the difference between the agent time
+// and the flow end timestamp. When the skew exceeds the threshold and correction is enabled, the flow
+// timestamps are moved to the receive time so that the flow isn't dropped as too old by the intake.
+func (agg *FlowAggregator) checkClockSkew(flow *common.Flow) {
+	if flow.EndTimestamp == 0 {
+		return
+	}
+	now := agg.timeNowFunction().Unix()
+	skew := now - int64(flow.EndTimestamp)
+
+	agg.exporterClockSkewsMutex.Lock()
+	agg.exporterClockSkews[exporterKey{namespace: flow.Namespace, ip: common.IPBytesToString(flow.ExporterAddr)}] = skew
+	agg.exporterClockSkewsMutex.Unlock()
+
+	if !agg.clockSkewCorrectionEnabled || (skew <= agg.clockSkewThreshold && skew >= -agg.clockSkewThreshold) {
+		return
+	}
+	var duration uint64
+	if flow.EndTimestamp > flow.StartTimestamp {
+		duration = flow.EndTimestamp - flow.StartTimestamp
+	}
+	flow.EndTimestamp = uint64(now)
+	flow.StartTimestamp = flow.EndTimestamp - duration
+	flow.ClockSkewCorrected = true
+}
+
+// submitExporterClockSkews sends the clock skew of the exporters seen since the last flush
+func (agg *FlowAggregator) submitExporterClockSkews() {
+	agg.exporterClockSkewsMutex.Lock()
+	skews := agg.exporterClockSkews
+	agg.exporterClockSkews = make(map[exporterKey]int64)
+	agg.exporterClockSkewsMutex.Unlock()
+
+	for exporter, skew := range skews {
+		tags := []string{"exporter_ip:" + exporter.ip, "device_namespace:" + exporter.namespace}
+		agg.sender.Gauge("datadog.netflow.exporter.clock_skew", float64(skew), "", tags)
+	}
+}
+
+// endpointTags returns the enrichment tags of a flow endpoint
+func (agg *FlowAggregator) endpointTags(ip string) []string {
+	var tags []string
+	if agg.podResolver != nil {
+		tags = append(tags, agg.podResolver.Tags(ip)...)
+	}
+	if agg.geoIPResolver != nil {
+		tags = append(tags, agg.geoIPResolver.Tags(ip)...)
+	}
+	return tags
+}
+
 func (agg *FlowAggregator) sendFlows(flows []*common.Flow) {
 	for _, flow := range flows {
 		flowPayload := buildPayload(flow, agg.hostname)
+		flowPayload.Source.Tags = agg.endpointTags(flowPayload.Source.IP)
+		flowPayload.Destination.Tags = agg.endpointTags(flowPayload.Destination.IP)
 		payloadBytes, err := json.Marshal(flowPayload)
 		if err != nil {
 			log.Errorf("Error marshalling device metadata: %s", err)
+			agg.flowPayloadErrorCount.Inc()
 			continue
 		}
 
 		log.Tracef("flushed flow: %s", string(payloadBytes))
 
 		m := &message.Message{Content: payloadBytes}
-		err = agg.epForwarder.SendEventPlatformEventBlocking(m, epforwarder.EventTypeNetworkDevicesNetFlow)
+		err = agg.tenants.forwarder(flow.Tenant, agg.epForwarder).SendEventPlatformEventBlocking(m, epforwarder.EventTypeNetworkDevicesNetFlow)
 		if err != nil {
 			// at the moment, SendEventPlatformEventBlocking can only fail if the event type is invalid
 			log.Errorf("Error sending to event platform forwarder: %s", err)
+			agg.flowPayloadErrorCount.Inc()
 			continue
 		}
+		agg.sentFlowPayloadCount.Inc()
 	}
 }
 
 func (agg *FlowAggregator) sendExporterMetadata(flows []*common.Flow, flushTime time.Time) {
+	// the exporters are grouped by tenant, as the metadata of an exporter is sent to the organization of its flows
+	flowsByTenant := make(map[string][]*common.Flow)
+	for _, flow := range flows {
+		flowsByTenant[flow.Tenant] = append(flowsByTenant[flow.Tenant], flow)
+	}
+	for tenant, tenantFlows := range flowsByTenant {
+		agg.sendTenantExporterMetadata(tenantFlows, flushTime, agg.tenants.forwarder(tenant, agg.epForwarder))
+	}
+}
+
+func (agg *FlowAggregator) sendTenantExporterMetadata(flows []*common.Flow, flushTime time.Time, forwarder epforwarder.EventPlatformForwarder) {
 	// exporterMap structure: map[NAMESPACE]map[EXPORTER_ID]metadata.NetflowExporter
 	exporterMap := make(map[string]map[string]metadata.NetflowExporter)
 
@@ -163,14 +299,18 @@ func (agg *FlowAggregator) sendExporterMetadata(flows []*common.Flow, flushTime
 			payloadBytes, err := json.Marshal(payload)
 			if err != nil {
 				log.Errorf("Error marshalling device metadata: %s", err)
+				agg.metadataPayloadErrorCount.Inc()
 				continue
 			}
 			log.Debugf("netflow exporter metadata payload: %s", string(payloadBytes))
 			m := &message.Message{Content: payloadBytes}
-			err = agg.epForwarder.SendEventPlatformEventBlocking(m, epforwarder.EventTypeNetworkDevicesMetadata)
+			err = forwarder.SendEventPlatformEventBlocking(m, epforwarder.EventTypeNetworkDevicesMetadata)
 			if err != nil {
 				log.Errorf("Error sending event platform event for netflow exporter metadata: %s", err)
+				agg.metadataPayloadErrorCount.Inc()
+				continue
 			}
+			agg.sentMetadataPayloadCount.Inc()
 		}
 	}
 }
@@ -229,6 +369,7 @@ func (agg *FlowAggregator) flush() int {
 	flushCount := len(flowsToFlush)
 
 	agg.sender.MonotonicCount("datadog.netflow.aggregator.hash_collisions", float64(agg.flowAcc.hashCollisionFlowCount.Load()), "", nil)
+	agg.sender.MonotonicCount("datadog.netflow.aggregator.flows_deduplicated", float64(agg.flowAcc.deduplicatedFlowCount.Load()), "", nil)
 	agg.sender.MonotonicCount("datadog.netflow.aggregator.flows_received", float64(agg.receivedFlowCount.Load()), "", nil)
 	agg.sender.Count("datadog.netflow.aggregator.flows_flushed", float64(flushCount), "", nil)
 	agg.sender.Gauge("datadog.netflow.aggregator.flows_contexts", float64(flowsContexts), "", nil)
@@ -236,6 +377,8 @@ func (agg *FlowAggregator) flush() int {
 	agg.sender.Gauge("datadog.netflow.aggregator.port_rollup.new_store_size", float64(agg.flowAcc.portRollup.GetNewStoreSize()), "", nil)
 	agg.sender.Gauge("datadog.netflow.aggregator.input_buffer.capacity", float64(cap(agg.flowIn)), "", nil)
 	agg.sender.Gauge("datadog.netflow.aggregator.input_buffer.length", float64(len(agg.flowIn)), "", nil)
+	agg.submitExporterClockSkews()
+	agg.exporterStates.expire(flushTime)
 
 	err := agg.submitCollectorMetrics()
 	if err != nil {
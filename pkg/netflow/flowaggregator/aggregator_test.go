@@ -30,11 +30,13 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/epforwarder"
 	"github.com/DataDog/datadog-agent/pkg/logs/message"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
 
 	"github.com/DataDog/datadog-agent/pkg/networkdevice/metadata"
 
 	"github.com/DataDog/datadog-agent/pkg/netflow/common"
 	"github.com/DataDog/datadog-agent/pkg/netflow/config"
+	"github.com/DataDog/datadog-agent/pkg/netflow/enrichment"
 	"github.com/DataDog/datadog-agent/pkg/netflow/goflowlib"
 	"github.com/DataDog/datadog-agent/pkg/netflow/testutil"
 )
@@ -274,7 +276,7 @@ func TestAggregator_withMockPayload(t *testing.T) {
 		stoppedFlushLoop <- struct{}{}
 	}()
 
-	flowState, err := goflowlib.StartFlowRoutine(common.TypeNetFlow5, "127.0.0.1", port, 1, "default", aggregator.GetFlowInChan())
+	flowState, err := goflowlib.StartFlowRoutine(common.TypeNetFlow5, "127.0.0.1", port, 1, "default", "", aggregator.GetFlowInChan())
 	assert.NoError(t, err)
 
 	time.Sleep(100 * time.Millisecond) // wait to make sure goflow listener is started before sending
@@ -853,3 +855,209 @@ func TestFlowAggregator_sendExporterMetadata_singleExporterIpWithMultipleFlowTyp
 	// call sendExporterMetadata does not trigger any call to epForwarder.SendEventPlatformEventBlocking(...)
 	aggregator.sendExporterMetadata(flows, now)
 }
+
+func TestNewFlowAggregator_kubernetesEnrichmentWithoutStore(t *testing.T) {
+	require.Nil(t, workloadmeta.GetGlobalStore())
+	conf := config.NetflowConfig{
+		AggregatorBufferSize:        20,
+		KubernetesEnrichmentEnabled: true,
+	}
+	ctrl := gomock.NewController(t)
+	aggregator := NewFlowAggregator(mocksender.NewMockSender(""), epforwarder.NewMockEventPlatformForwarder(ctrl), &conf, "my-hostname")
+
+	assert.Nil(t, aggregator.podResolver)
+	assert.Empty(t, aggregator.endpointTags("10.10.10.20"))
+}
+
+func TestFlowAggregator_sendFlows_kubernetesEnrichment(t *testing.T) {
+	sender := mocksender.NewMockSender("")
+	conf := config.NetflowConfig{
+		StopTimeout:                            10,
+		AggregatorBufferSize:                   20,
+		AggregatorFlushInterval:                1,
+		AggregatorPortRollupThreshold:          10,
+		AggregatorRollupTrackerRefreshInterval: 3600,
+	}
+	ctrl := gomock.NewController(t)
+	epForwarder := epforwarder.NewMockEventPlatformForwarder(ctrl)
+	aggregator := NewFlowAggregator(sender, epForwarder, &conf, "my-hostname")
+
+	store := workloadmeta.NewMockStore()
+	store.SetEntity(&workloadmeta.KubernetesPod{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindKubernetesPod,
+			ID:   "pod-1",
+		},
+		EntityMeta: workloadmeta.EntityMeta{
+			Name:      "web-1",
+			Namespace: "frontend",
+		},
+		IP:           "10.10.10.20",
+		KubeServices: []string{"web"},
+	})
+	podResolver, err := enrichment.NewPodResolver(store)
+	require.NoError(t, err)
+	aggregator.podResolver = podResolver
+	aggregator.podResolver.Start()
+	defer aggregator.podResolver.Stop()
+	require.Eventually(t, func() bool {
+		return len(aggregator.podResolver.Tags("10.10.10.20")) > 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	flow := &common.Flow{
+		Namespace:      "my-ns",
+		FlowType:       common.TypeNetFlow9,
+		ExporterAddr:   []byte{127, 0, 0, 1},
+		StartTimestamp: 1234568,
+		EndTimestamp:   1234569,
+		Bytes:          20,
+		Packets:        4,
+		SrcAddr:        []byte{10, 10, 10, 10},
+		DstAddr:        []byte{10, 10, 10, 20},
+		IPProtocol:     uint32(6),
+		SrcPort:        2000,
+		DstPort:        80,
+		EtherType:      uint32(0x0800),
+	}
+
+	// language=json
+	event := []byte(`
+{
+  "type": "netflow9",
+  "sampling_rate": 0,
+  "direction": "ingress",
+  "start": 1234568,
+  "end": 1234569,
+  "bytes": 20,
+  "packets": 4,
+  "ether_type": "IPv4",
+  "ip_protocol": "TCP",
+  "device": {
+    "namespace": "my-ns"
+  },
+  "exporter": {
+    "ip": "127.0.0.1"
+  },
+  "source": {
+    "ip": "10.10.10.10",
+    "port": "2000",
+    "mac": "00:00:00:00:00:00",
+    "mask": "0.0.0.0/0"
+  },
+  "destination": {
+    "ip": "10.10.10.20",
+    "port": "80",
+    "mac": "00:00:00:00:00:00",
+    "mask": "0.0.0.0/0",
+    "tags": [
+      "pod_name:web-1",
+      "kube_namespace:frontend",
+      "kube_service:web"
+    ]
+  },
+  "ingress": {
+    "interface": {
+      "index": 0
+    }
+  },
+  "egress": {
+    "interface": {
+      "index": 0
+    }
+  },
+  "host": "my-hostname",
+  "next_hop": {
+    "ip": ""
+  }
+}
+`)
+	compactEvent := new(bytes.Buffer)
+	err = json.Compact(compactEvent, event)
+	require.NoError(t, err)
+	epForwarder.EXPECT().SendEventPlatformEventBlocking(&message.Message{Content: compactEvent.Bytes()}, "network-devices-netflow").Return(nil).Times(1)
+
+	aggregator.sendFlows([]*common.Flow{flow})
+}
+
+func TestFlowAggregator_checkClockSkew(t *testing.T) {
+	now := time.Unix(1000000, 0)
+	tests := []struct {
+		name              string
+		correctionEnabled bool
+		start, end        uint64
+		expectedStart     uint64
+		expectedEnd       uint64
+		expectedCorrected bool
+		expectedSkew      int64
+	}{
+		{
+			name:              "no skew",
+			correctionEnabled: true,
+			start:             999990,
+			end:               999995,
+			expectedStart:     999990,
+			expectedEnd:       999995,
+			expectedSkew:      5,
+		},
+		{
+			name:              "exporter clock behind",
+			correctionEnabled: true,
+			start:             990000,
+			end:               990010,
+			expectedStart:     999990,
+			expectedEnd:       1000000,
+			expectedCorrected: true,
+			expectedSkew:      9990,
+		},
+		{
+			name:              "exporter clock ahead",
+			correctionEnabled: true,
+			start:             1003600,
+			end:               1003605,
+			expectedStart:     999995,
+			expectedEnd:       1000000,
+			expectedCorrected: true,
+			expectedSkew:      -3605,
+		},
+		{
+			name:          "correction disabled",
+			start:         990000,
+			end:           990010,
+			expectedStart: 990000,
+			expectedEnd:   990010,
+			expectedSkew:  9990,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sender := mocksender.NewMockSender("")
+			sender.On("Gauge", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+			conf := config.NetflowConfig{
+				AggregatorFlushInterval:                1,
+				AggregatorRollupTrackerRefreshInterval: 3600,
+				ClockSkewThreshold:                     300,
+				ClockSkewCorrectionEnabled:             tt.correctionEnabled,
+			}
+			ctrl := gomock.NewController(t)
+			epForwarder := epforwarder.NewMockEventPlatformForwarder(ctrl)
+			aggregator := NewFlowAggregator(sender, epForwarder, &conf, "my-hostname")
+			aggregator.timeNowFunction = func() time.Time { return now }
+
+			flow := &common.Flow{
+				Namespace:      "my-ns",
+				ExporterAddr:   []byte{127, 0, 0, 1},
+				StartTimestamp: tt.start,
+				EndTimestamp:   tt.end,
+			}
+			aggregator.checkClockSkew(flow)
+
+			assert.Equal(t, tt.expectedStart, flow.StartTimestamp)
+			assert.Equal(t, tt.expectedEnd, flow.EndTimestamp)
+			assert.Equal(t, tt.expectedCorrected, flow.ClockSkewCorrected)
+
+			aggregator.submitExporterClockSkews()
+			sender.AssertMetric(t, "Gauge", "datadog.netflow.exporter.clock_skew", float64(tt.expectedSkew), "", []string{"exporter_ip:127.0.0.1", "device_namespace:my-ns"})
+			assert.Empty(t, aggregator.exporterClockSkews)
+		})
+	}
+}
@@ -45,17 +45,28 @@ func buildPayload(aggFlow *common.Flow, hostname string) payload.FlowPayload {
 		Ingress: payload.ObservationPoint{
 			Interface: payload.Interface{
 				Index: aggFlow.InputInterface,
+				Name:  aggFlow.InputInterfaceName,
 			},
 		},
 		Egress: payload.ObservationPoint{
 			Interface: payload.Interface{
 				Index: aggFlow.OutputInterface,
+				Name:  aggFlow.OutputInterfaceName,
 			},
 		},
 		Host:     hostname,
 		TCPFlags: enrichment.FormatFCPFlags(aggFlow.TCPFlags),
+		Tags:     buildTags(aggFlow),
 		NextHop: payload.NextHop{
 			IP: common.IPBytesToString(aggFlow.NextHop),
 		},
 	}
 }
+
+func buildTags(aggFlow *common.Flow) []string {
+	tags := append(enrichment.TCPFlagsTags(aggFlow.IPProtocol, aggFlow.TCPFlags), enrichment.FlowEndReasonTags(aggFlow.FlowEndReason)...)
+	if aggFlow.ClockSkewCorrected {
+		tags = append(tags, "clock_skew_corrected:true")
+	}
+	return tags
+}
@@ -22,6 +22,72 @@ func Test_buildPayload(t *testing.T) {
 	}{
 		{
 			name: "base case",
+			flow: common.Flow{
+				Namespace:           "my-namespace",
+				FlowType:            common.TypeNetFlow9,
+				SamplingRate:        10,
+				Direction:           1,
+				ExporterAddr:        []byte{127, 0, 0, 1},
+				StartTimestamp:      1234568,
+				EndTimestamp:        1234569,
+				Bytes:               10,
+				Packets:             2,
+				SrcAddr:             []byte{10, 10, 10, 10},
+				DstAddr:             []byte{10, 10, 10, 20},
+				SrcMac:              uint64(10),
+				DstMac:              uint64(20),
+				SrcMask:             uint32(10),
+				DstMask:             uint32(20),
+				EtherType:           uint32(0x0800),
+				IPProtocol:          uint32(6),
+				SrcPort:             2000,
+				DstPort:             80,
+				InputInterface:      10,
+				OutputInterface:     20,
+				InputInterfaceName:  "eth0",
+				OutputInterfaceName: "eth1",
+				Tos:                 3,
+				NextHop:             []byte{10, 10, 10, 30},
+				TCPFlags:            uint32(19), // 19 = SYN,ACK,FIN
+			},
+			expectedPayload: payload.FlowPayload{
+				FlowType:     "netflow9",
+				SamplingRate: 10,
+				Direction:    "egress",
+				Start:        1234568,
+				End:          1234569,
+				Bytes:        10,
+				Packets:      2,
+				EtherType:    "IPv4",
+				IPProtocol:   "TCP",
+				Device: payload.Device{
+					Namespace: "my-namespace",
+				},
+				Exporter: payload.Exporter{
+					IP: "127.0.0.1",
+				},
+				Source: payload.Endpoint{
+					IP:   "10.10.10.10",
+					Port: "2000",
+					Mac:  "00:00:00:00:00:0a",
+					Mask: "10.0.0.0/10",
+				},
+				Destination: payload.Endpoint{IP: "10.10.10.20",
+					Port: "80",
+					Mac:  "00:00:00:00:00:14",
+					Mask: "10.10.0.0/20",
+				},
+				Ingress:  payload.ObservationPoint{Interface: payload.Interface{Index: 10, Name: "eth0"}},
+				Egress:   payload.ObservationPoint{Interface: payload.Interface{Index: 20, Name: "eth1"}},
+				Host:     "my-hostname",
+				TCPFlags: []string{"FIN", "SYN", "ACK"},
+				NextHop: payload.NextHop{
+					IP: "10.10.10.30",
+				},
+			},
+		},
+		{
+			name: "ephemeral source port",
 			flow: common.Flow{
 				Namespace:       "my-namespace",
 				FlowType:        common.TypeNetFlow9,
@@ -40,7 +106,7 @@ func Test_buildPayload(t *testing.T) {
 				DstMask:         uint32(20),
 				EtherType:       uint32(0x0800),
 				IPProtocol:      uint32(6),
-				SrcPort:         2000,
+				SrcPort:         -1,
 				DstPort:         80,
 				InputInterface:  10,
 				OutputInterface: 20,
@@ -66,7 +132,7 @@ func Test_buildPayload(t *testing.T) {
 				},
 				Source: payload.Endpoint{
 					IP:   "10.10.10.10",
-					Port: "2000",
+					Port: "*",
 					Mac:  "00:00:00:00:00:0a",
 					Mask: "10.0.0.0/10",
 				},
@@ -104,8 +170,8 @@ func Test_buildPayload(t *testing.T) {
 				DstMask:         uint32(20),
 				EtherType:       uint32(0x0800),
 				IPProtocol:      uint32(6),
-				SrcPort:         -1,
-				DstPort:         80,
+				SrcPort:         2000,
+				DstPort:         -1,
 				InputInterface:  10,
 				OutputInterface: 20,
 				Tos:             3,
@@ -130,12 +196,12 @@ func Test_buildPayload(t *testing.T) {
 				},
 				Source: payload.Endpoint{
 					IP:   "10.10.10.10",
-					Port: "*",
+					Port: "2000",
 					Mac:  "00:00:00:00:00:0a",
 					Mask: "10.0.0.0/10",
 				},
 				Destination: payload.Endpoint{IP: "10.10.10.20",
-					Port: "80",
+					Port: "*",
 					Mac:  "00:00:00:00:00:14",
 					Mask: "10.10.0.0/20",
 				},
@@ -149,7 +215,7 @@ func Test_buildPayload(t *testing.T) {
 			},
 		},
 		{
-			name: "ephemeral source port",
+			name: "reset flow ended by idle timeout",
 			flow: common.Flow{
 				Namespace:       "my-namespace",
 				FlowType:        common.TypeNetFlow9,
@@ -169,12 +235,13 @@ func Test_buildPayload(t *testing.T) {
 				EtherType:       uint32(0x0800),
 				IPProtocol:      uint32(6),
 				SrcPort:         2000,
-				DstPort:         -1,
+				DstPort:         80,
 				InputInterface:  10,
 				OutputInterface: 20,
 				Tos:             3,
 				NextHop:         []byte{10, 10, 10, 30},
-				TCPFlags:        uint32(19), // 19 = SYN,ACK,FIN
+				TCPFlags:        uint32(6), // 6 = SYN,RST
+				FlowEndReason:   1,         // idle timeout
 			},
 			expectedPayload: payload.FlowPayload{
 				FlowType:     "netflow9",
@@ -199,14 +266,15 @@ func Test_buildPayload(t *testing.T) {
 					Mask: "10.0.0.0/10",
 				},
 				Destination: payload.Endpoint{IP: "10.10.10.20",
-					Port: "*",
+					Port: "80",
 					Mac:  "00:00:00:00:00:14",
 					Mask: "10.10.0.0/20",
 				},
 				Ingress:  payload.ObservationPoint{Interface: payload.Interface{Index: 10}},
 				Egress:   payload.ObservationPoint{Interface: payload.Interface{Index: 20}},
 				Host:     "my-hostname",
-				TCPFlags: []string{"FIN", "SYN", "ACK"},
+				TCPFlags: []string{"SYN", "RST"},
+				Tags:     []string{"tcp_flags:rst", "flow_end_reason:idle_timeout"},
 				NextHop: payload.NextHop{
 					IP: "10.10.10.30",
 				},
@@ -0,0 +1,289 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package flowaggregator
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	promClient "github.com/prometheus/client_model/go"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+
+	"github.com/DataDog/datadog-agent/pkg/netflow/common"
+)
+
+// Template states of an exporter
+const (
+	// TemplateStateComplete means that templates were received, and no packet was dropped for a missing template
+	TemplateStateComplete = "complete"
+	// TemplateStateIncomplete means that templates were received, but some packets referenced unknown templates
+	TemplateStateIncomplete = "incomplete"
+	// TemplateStateMissing means that no template was received from the exporter
+	TemplateStateMissing = "missing"
+	// TemplateStateNotApplicable is used for the flow types without templates, i.e. NetFlow v5 and sFlow
+	TemplateStateNotApplicable = "not_applicable"
+)
+
+// exporterStateTTL is the time after which the state of an exporter which stopped sending flows is forgotten
+const exporterStateTTL = time.Hour
+
+// ExporterStatus holds the health of a flow exporter
+type ExporterStatus struct {
+	IPAddress string          `json:"ip_address"`
+	Namespace string          `json:"namespace"`
+	FlowType  common.FlowType `json:"flow_type"`
+	// ObservationDomain is the NetFlow v9 source ID or the IPFIX observation domain ID, zero for the other flow types
+	ObservationDomain uint32 `json:"observation_domain"`
+	// LastPacket is the time the last flow of the exporter was received, zero when no flow was decoded
+	LastPacket time.Time      `json:"last_packet"`
+	Flows      uint64         `json:"flows"`
+	Template   TemplateStatus `json:"template"`
+	Sequence   SequenceStatus `json:"sequence"`
+}
+
+// TemplateStatus holds the state of the NetFlow v9 and IPFIX templates of an exporter
+type TemplateStatus struct {
+	State string `json:"state"`
+	// Templates is the number of templates and options templates received
+	Templates int `json:"templates"`
+	// MissingTemplateErrors is the number of packets dropped because they referenced an unknown template
+	MissingTemplateErrors uint64 `json:"missing_template_errors"`
+}
+
+// SequenceStatus holds the sequence number gaps of an exporter, which are caused by packets lost between the
+// exporter and the agent. The gaps are counted in packets for NetFlow v9 and sFlow, and in flows for NetFlow v5
+// and IPFIX, whose sequence numbers count the records sent.
+type SequenceStatus struct {
+	Gaps   uint64 `json:"gaps"`
+	Missed uint64 `json:"missed"`
+	// Resets counts the sequence numbers going backward, when the exporter restarts or the packets are reordered
+	Resets uint64 `json:"resets"`
+}
+
+// exporterStateKey identifies the sequence of packets of an exporter. The sequence numbers of NetFlow v9 and
+// IPFIX are scoped to an observation domain, an exporter sending several of them.
+type exporterStateKey struct {
+	namespace         string
+	ip                string
+	flowType          common.FlowType
+	observationDomain uint32
+}
+
+// templateKey identifies the templates of an exporter, which are scoped to an observation domain as well
+type templateKey struct {
+	ip                string
+	observationDomain uint32
+}
+
+// exporterState holds what is known about an exporter from the flows received from it
+type exporterState struct {
+	lastPacket time.Time
+	flows      uint64
+
+	hasSequence bool
+	// sequence is the sequence number of the last packet received
+	sequence uint32
+	// flowsInSequence is the number of flows received in the last packet
+	flowsInSequence uint32
+	sequenceStatus  SequenceStatus
+}
+
+// sequenceCountsRecords returns true for the flow types whose sequence numbers count the records sent
+// rather than the packets.
+func sequenceCountsRecords(flowType common.FlowType) bool {
+	return flowType == common.TypeNetFlow5 || flowType == common.TypeIPFIX
+}
+
+// observeSequence updates the sequence gaps with the sequence number of a flow. The flows of a packet
+// all share the sequence number of the packet.
+func (s *exporterState) observeSequence(flowType common.FlowType, sequence uint32) {
+	if !s.hasSequence {
+		s.hasSequence = true
+		s.sequence = sequence
+		s.flowsInSequence = 1
+		return
+	}
+	if sequence == s.sequence {
+		s.flowsInSequence++
+		return
+	}
+
+	expected := s.sequence + 1
+	if sequenceCountsRecords(flowType) {
+		expected = s.sequence + s.flowsInSequence
+	}
+	// the sequence numbers wrap around
+	if diff := int32(sequence - expected); diff > 0 {
+		s.sequenceStatus.Gaps++
+		s.sequenceStatus.Missed += uint64(diff)
+	} else if diff < 0 {
+		s.sequenceStatus.Resets++
+	}
+	s.sequence = sequence
+	s.flowsInSequence = 1
+}
+
+// exporterStateStore tracks the state of the exporters the flows are received from, to report their health
+type exporterStateStore struct {
+	mu        sync.Mutex
+	exporters map[exporterStateKey]*exporterState
+}
+
+func newExporterStateStore() *exporterStateStore {
+	return &exporterStateStore{
+		exporters: make(map[exporterStateKey]*exporterState),
+	}
+}
+
+// observe updates the state of the exporter of a flow received at the given time
+func (s *exporterStateStore) observe(flow *common.Flow, now time.Time) {
+	key := exporterStateKey{
+		namespace:         flow.Namespace,
+		ip:                common.IPBytesToString(flow.ExporterAddr),
+		flowType:          flow.FlowType,
+		observationDomain: flow.ObservationDomain,
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state, ok := s.exporters[key]
+	if !ok {
+		state = &exporterState{}
+		s.exporters[key] = state
+	}
+	state.lastPacket = now
+	state.flows++
+	state.observeSequence(flow.FlowType, flow.SequenceNum)
+}
+
+// expire forgets the exporters which sent no flow since the TTL, for the store not to grow with the exporters
+// which were removed or renumbered
+func (s *exporterStateStore) expire(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, state := range s.exporters {
+		if now.Sub(state.lastPacket) > exporterStateTTL {
+			delete(s.exporters, key)
+		}
+	}
+}
+
+// status returns the status of the known exporters, sorted by IP address. The template states are read from
+// the goflow metrics, which also report the exporters whose packets couldn't be decoded into flows at all.
+func (s *exporterStateStore) status(gatherer prometheus.Gatherer) []ExporterStatus {
+	templates, missingTemplateErrors := gatherTemplateStats(gatherer)
+
+	s.mu.Lock()
+	statuses := make([]ExporterStatus, 0, len(s.exporters))
+	withFlows := make(map[string]struct{}, len(s.exporters))
+	for key, state := range s.exporters {
+		status := ExporterStatus{
+			IPAddress:         key.ip,
+			Namespace:         key.namespace,
+			FlowType:          key.flowType,
+			ObservationDomain: key.observationDomain,
+			LastPacket:        state.lastPacket,
+			Flows:             state.flows,
+			Sequence:          state.sequenceStatus,
+			Template:          TemplateStatus{State: TemplateStateNotApplicable},
+		}
+		if key.flowType == common.TypeNetFlow9 || key.flowType == common.TypeIPFIX {
+			// goflow doesn't report the observation domain of the packets dropped for a missing template
+			status.Template = newTemplateStatus(templates[templateKey{key.ip, key.observationDomain}], missingTemplateErrors[key.ip])
+		}
+		statuses = append(statuses, status)
+		withFlows[key.ip] = struct{}{}
+	}
+	s.mu.Unlock()
+
+	for ip, errors := range missingTemplateErrors {
+		if _, ok := withFlows[ip]; ok {
+			continue
+		}
+		exporterTemplates := 0
+		for key, count := range templates {
+			if key.ip == ip {
+				exporterTemplates += count
+			}
+		}
+		statuses = append(statuses, ExporterStatus{
+			IPAddress: ip,
+			FlowType:  common.TypeUnknown,
+			Template:  newTemplateStatus(exporterTemplates, errors),
+		})
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].IPAddress != statuses[j].IPAddress {
+			return statuses[i].IPAddress < statuses[j].IPAddress
+		}
+		if statuses[i].Namespace != statuses[j].Namespace {
+			return statuses[i].Namespace < statuses[j].Namespace
+		}
+		if statuses[i].FlowType != statuses[j].FlowType {
+			return statuses[i].FlowType < statuses[j].FlowType
+		}
+		return statuses[i].ObservationDomain < statuses[j].ObservationDomain
+	})
+	return statuses
+}
+
+func newTemplateStatus(templates int, missingTemplateErrors uint64) TemplateStatus {
+	status := TemplateStatus{
+		State:                 TemplateStateComplete,
+		Templates:             templates,
+		MissingTemplateErrors: missingTemplateErrors,
+	}
+	if templates == 0 {
+		status.State = TemplateStateMissing
+	} else if missingTemplateErrors > 0 {
+		status.State = TemplateStateIncomplete
+	}
+	return status
+}
+
+// gatherTemplateStats returns the number of templates received by exporter IP address and observation domain,
+// and the number of packets dropped for a missing template by exporter IP address.
+func gatherTemplateStats(gatherer prometheus.Gatherer) (map[templateKey]int, map[string]uint64) {
+	templates := make(map[templateKey]int)
+	missingTemplateErrors := make(map[string]uint64)
+
+	metricFamilies, err := gatherer.Gather()
+	if err != nil {
+		log.Debugf("Error gathering goflow metrics: %s", err)
+		return templates, missingTemplateErrors
+	}
+	for _, metricFamily := range metricFamilies {
+		switch metricFamily.GetName() {
+		case "flow_process_nf_templates_count":
+			// one metric per template
+			for _, metric := range metricFamily.Metric {
+				observationDomain, _ := strconv.ParseUint(labelValue(metric, "obs_domain_id"), 10, 32)
+				templates[templateKey{labelValue(metric, "router"), uint32(observationDomain)}]++
+			}
+		case "flow_process_nf_errors_count":
+			for _, metric := range metricFamily.Metric {
+				if labelValue(metric, "error") == "template_not_found" {
+					missingTemplateErrors[labelValue(metric, "router")] += uint64(metric.GetCounter().GetValue())
+				}
+			}
+		}
+	}
+	return templates, missingTemplateErrors
+}
+
+func labelValue(metric *promClient.Metric, name string) string {
+	for _, label := range metric.GetLabel() {
+		if label.GetName() == name {
+			return label.GetValue()
+		}
+	}
+	return ""
+}
@@ -0,0 +1,169 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package flowaggregator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/prometheus/client_golang/prometheus"
+	promClient "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/netflow/common"
+)
+
+func TestExporterStateObserveSequence(t *testing.T) {
+	tests := []struct {
+		name      string
+		flowType  common.FlowType
+		sequences []uint32
+		expected  SequenceStatus
+	}{
+		{
+			name:      "netflow9 without gap",
+			flowType:  common.TypeNetFlow9,
+			sequences: []uint32{10, 10, 11, 12, 12, 12, 13},
+		},
+		{
+			name:      "netflow9 with gaps",
+			flowType:  common.TypeNetFlow9,
+			sequences: []uint32{10, 10, 13, 14, 16},
+			expected:  SequenceStatus{Gaps: 2, Missed: 3},
+		},
+		{
+			name:      "ipfix counts records",
+			flowType:  common.TypeIPFIX,
+			sequences: []uint32{100, 100, 100, 103, 103, 110},
+			expected:  SequenceStatus{Gaps: 1, Missed: 5},
+		},
+		{
+			name:      "exporter restart",
+			flowType:  common.TypeSFlow5,
+			sequences: []uint32{500, 501, 1, 2},
+			expected:  SequenceStatus{Resets: 1},
+		},
+		{
+			name:      "wrap around",
+			flowType:  common.TypeNetFlow9,
+			sequences: []uint32{4294967294, 4294967295, 0, 2},
+			expected:  SequenceStatus{Gaps: 1, Missed: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := &exporterState{}
+			for _, sequence := range tt.sequences {
+				state.observeSequence(tt.flowType, sequence)
+			}
+			assert.Equal(t, tt.expected, state.sequenceStatus)
+		})
+	}
+}
+
+func counterMetric(value float64, labels map[string]string) *promClient.Metric {
+	metric := &promClient.Metric{Counter: &promClient.Counter{Value: proto.Float64(value)}}
+	for name, value := range labels {
+		metric.Label = append(metric.Label, &promClient.LabelPair{Name: proto.String(name), Value: proto.String(value)})
+	}
+	return metric
+}
+
+func TestExporterStateStoreStatus(t *testing.T) {
+	now := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+	store := newExporterStateStore()
+	store.observe(&common.Flow{Namespace: "default", FlowType: common.TypeNetFlow9, ExporterAddr: []byte{10, 0, 0, 1}, SequenceNum: 1}, now.Add(-time.Minute))
+	store.observe(&common.Flow{Namespace: "default", FlowType: common.TypeNetFlow9, ExporterAddr: []byte{10, 0, 0, 1}, SequenceNum: 3}, now)
+	// the sequence numbers of the other observation domain of the exporter are tracked apart
+	store.observe(&common.Flow{Namespace: "default", FlowType: common.TypeNetFlow9, ExporterAddr: []byte{10, 0, 0, 1}, ObservationDomain: 1, SequenceNum: 100}, now)
+	store.observe(&common.Flow{Namespace: "default", FlowType: common.TypeNetFlow9, ExporterAddr: []byte{10, 0, 0, 1}, ObservationDomain: 1, SequenceNum: 101}, now)
+	store.observe(&common.Flow{Namespace: "default", FlowType: common.TypeIPFIX, ExporterAddr: []byte{10, 0, 0, 2}, SequenceNum: 1}, now)
+	store.observe(&common.Flow{Namespace: "default", FlowType: common.TypeSFlow5, ExporterAddr: []byte{10, 0, 0, 3}, SequenceNum: 1}, now)
+
+	gatherer := prometheus.GathererFunc(func() ([]*promClient.MetricFamily, error) {
+		return []*promClient.MetricFamily{
+			{
+				Name: proto.String("flow_process_nf_templates_count"),
+				Type: promClient.MetricType_COUNTER.Enum(),
+				Metric: []*promClient.Metric{
+					counterMetric(1, map[string]string{"router": "10.0.0.1", "obs_domain_id": "0", "template_id": "256"}),
+					counterMetric(2, map[string]string{"router": "10.0.0.1", "obs_domain_id": "0", "template_id": "257"}),
+					counterMetric(1, map[string]string{"router": "10.0.0.1", "obs_domain_id": "1", "template_id": "256"}),
+					counterMetric(1, map[string]string{"router": "10.0.0.2", "obs_domain_id": "0", "template_id": "256"}),
+					counterMetric(1, map[string]string{"router": "10.0.0.4", "obs_domain_id": "0", "template_id": "300"}),
+				},
+			},
+			{
+				Name: proto.String("flow_process_nf_errors_count"),
+				Type: promClient.MetricType_COUNTER.Enum(),
+				Metric: []*promClient.Metric{
+					counterMetric(4, map[string]string{"router": "10.0.0.2", "error": "template_not_found"}),
+					counterMetric(3, map[string]string{"router": "10.0.0.2", "error": "error_decoding"}),
+					counterMetric(7, map[string]string{"router": "10.0.0.4", "error": "template_not_found"}),
+				},
+			},
+		}, nil
+	})
+
+	assert.Equal(t, []ExporterStatus{
+		{
+			IPAddress:  "10.0.0.1",
+			Namespace:  "default",
+			FlowType:   common.TypeNetFlow9,
+			LastPacket: now,
+			Flows:      2,
+			Template:   TemplateStatus{State: TemplateStateComplete, Templates: 2},
+			Sequence:   SequenceStatus{Gaps: 1, Missed: 1},
+		},
+		{
+			IPAddress:         "10.0.0.1",
+			Namespace:         "default",
+			FlowType:          common.TypeNetFlow9,
+			ObservationDomain: 1,
+			LastPacket:        now,
+			Flows:             2,
+			Template:          TemplateStatus{State: TemplateStateComplete, Templates: 1},
+		},
+		{
+			IPAddress:  "10.0.0.2",
+			Namespace:  "default",
+			FlowType:   common.TypeIPFIX,
+			LastPacket: now,
+			Flows:      1,
+			Template:   TemplateStatus{State: TemplateStateIncomplete, Templates: 1, MissingTemplateErrors: 4},
+		},
+		{
+			IPAddress:  "10.0.0.3",
+			Namespace:  "default",
+			FlowType:   common.TypeSFlow5,
+			LastPacket: now,
+			Flows:      1,
+			Template:   TemplateStatus{State: TemplateStateNotApplicable},
+		},
+		{
+			// no flow could be decoded, as the exporter didn't send the templates its flows reference
+			IPAddress: "10.0.0.4",
+			FlowType:  common.TypeUnknown,
+			Template:  TemplateStatus{State: TemplateStateIncomplete, Templates: 1, MissingTemplateErrors: 7},
+		},
+	}, store.status(gatherer))
+}
+
+func TestExporterStateStoreExpire(t *testing.T) {
+	now := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+	store := newExporterStateStore()
+	store.observe(&common.Flow{Namespace: "default", FlowType: common.TypeSFlow5, ExporterAddr: []byte{10, 0, 0, 1}}, now.Add(-exporterStateTTL-time.Second))
+	store.observe(&common.Flow{Namespace: "default", FlowType: common.TypeSFlow5, ExporterAddr: []byte{10, 0, 0, 2}}, now.Add(-exporterStateTTL))
+
+	store.expire(now)
+
+	emptyGatherer := prometheus.GathererFunc(func() ([]*promClient.MetricFamily, error) { return nil, nil })
+	statuses := store.status(emptyGatherer)
+	if assert.Len(t, statuses, 1) {
+		assert.Equal(t, "10.0.0.2", statuses[0].IPAddress)
+	}
+}
@@ -23,6 +23,50 @@ type flowContext struct {
 	flow                *common.Flow
 	nextFlush           time.Time
 	lastSuccessfulFlush time.Time
+
+	// countersByObservation holds the bytes and packets reported at each observation point of the flow since
+	// the last flush, when the flows are deduplicated. The attributes of the flow, such as its exporter and
+	// interfaces, are the ones of its representative observation point.
+	countersByObservation map[observationPoint]*flowCounters
+	representative        observationPoint
+}
+
+// observationPoint identifies where a flow is observed: by an exporter, on its interfaces, and in a direction.
+// An exporter may report the same packets on ingress and on egress, so these are different observation points.
+type observationPoint struct {
+	exporter        string
+	direction       uint32
+	inputInterface  uint32
+	outputInterface uint32
+}
+
+func newObservationPoint(flow *common.Flow) observationPoint {
+	return observationPoint{
+		exporter:        string(flow.ExporterAddr),
+		direction:       flow.Direction,
+		inputInterface:  flow.InputInterface,
+		outputInterface: flow.OutputInterface,
+	}
+}
+
+// less orders the observation points, to choose the representative of a flow deterministically
+func (o observationPoint) less(other observationPoint) bool {
+	if o.exporter != other.exporter {
+		return o.exporter < other.exporter
+	}
+	if o.direction != other.direction {
+		return o.direction < other.direction
+	}
+	if o.inputInterface != other.inputInterface {
+		return o.inputInterface < other.inputInterface
+	}
+	return o.outputInterface < other.outputInterface
+}
+
+// flowCounters holds the bytes and packets of a flow
+type flowCounters struct {
+	bytes   uint64
+	packets uint64
 }
 
 // flowAccumulator is used to accumulate aggregated flows
@@ -39,7 +83,12 @@ type flowAccumulator struct {
 	portRollupThreshold int
 	portRollupDisabled  bool
 
+	// deduplicationEnabled aggregates the flows by 5-tuple, whichever exporter reports them. The bytes
+	// and packets of a flow are the highest ones among its observation points, instead of their sum.
+	deduplicationEnabled bool
+
 	hashCollisionFlowCount *atomic.Uint64
+	deduplicatedFlowCount  *atomic.Uint64
 }
 
 func newFlowContext(flow *common.Flow) flowContext {
@@ -50,7 +99,7 @@ func newFlowContext(flow *common.Flow) flowContext {
 	}
 }
 
-func newFlowAccumulator(aggregatorFlushInterval time.Duration, aggregatorFlowContextTTL time.Duration, portRollupThreshold int, portRollupDisabled bool) *flowAccumulator {
+func newFlowAccumulator(aggregatorFlushInterval time.Duration, aggregatorFlowContextTTL time.Duration, portRollupThreshold int, portRollupDisabled bool, deduplicationEnabled bool) *flowAccumulator {
 	return &flowAccumulator{
 		flows:                  make(map[uint64]flowContext),
 		flowFlushInterval:      aggregatorFlushInterval,
@@ -58,7 +107,9 @@ func newFlowAccumulator(aggregatorFlushInterval time.Duration, aggregatorFlowCon
 		portRollup:             portrollup.NewEndpointPairPortRollupStore(portRollupThreshold),
 		portRollupThreshold:    portRollupThreshold,
 		portRollupDisabled:     portRollupDisabled,
+		deduplicationEnabled:   deduplicationEnabled,
 		hashCollisionFlowCount: atomic.NewUint64(0),
+		deduplicatedFlowCount:  atomic.NewUint64(0),
 	}
 }
 
@@ -92,6 +143,7 @@ func (f *flowAccumulator) flush() []*common.Flow {
 			flowsToFlush = append(flowsToFlush, flowCtx.flow)
 			flowCtx.lastSuccessfulFlush = now
 			flowCtx.flow = nil
+			flowCtx.countersByObservation = nil
 		}
 		flowCtx.nextFlush = flowCtx.nextFlush.Add(f.flowFlushInterval)
 		f.flows[key] = flowCtx
@@ -118,27 +170,79 @@ func (f *flowAccumulator) add(flowToAdd *common.Flow) {
 	defer f.flowsMutex.Unlock()
 
 	aggHash := flowToAdd.AggregationHash()
+	if f.deduplicationEnabled {
+		aggHash = flowToAdd.FiveTupleHash()
+	}
 	aggFlow, ok := f.flows[aggHash]
 	if !ok {
-		f.flows[aggHash] = newFlowContext(flowToAdd)
-		return
+		aggFlow = newFlowContext(nil)
 	}
 	if aggFlow.flow == nil {
 		aggFlow.flow = flowToAdd
+		if f.deduplicationEnabled {
+			aggFlow.representative = newObservationPoint(flowToAdd)
+			aggFlow.countersByObservation = map[observationPoint]*flowCounters{
+				aggFlow.representative: {bytes: flowToAdd.Bytes, packets: flowToAdd.Packets},
+			}
+		}
 	} else {
 		// use go routine for has collision detection to avoid blocking critical path
 		go f.detectHashCollision(aggHash, *aggFlow.flow, *flowToAdd)
 
 		// accumulate flowToAdd with existing flow(s) with same hash
-		aggFlow.flow.Bytes += flowToAdd.Bytes
-		aggFlow.flow.Packets += flowToAdd.Packets
+		if f.deduplicationEnabled {
+			f.addObservationCounters(&aggFlow, flowToAdd)
+		} else {
+			aggFlow.flow.Bytes += flowToAdd.Bytes
+			aggFlow.flow.Packets += flowToAdd.Packets
+		}
 		aggFlow.flow.StartTimestamp = common.MinUint64(aggFlow.flow.StartTimestamp, flowToAdd.StartTimestamp)
 		aggFlow.flow.EndTimestamp = common.MaxUint64(aggFlow.flow.EndTimestamp, flowToAdd.EndTimestamp)
 		aggFlow.flow.TCPFlags |= flowToAdd.TCPFlags
+		if flowToAdd.FlowEndReason != 0 {
+			aggFlow.flow.FlowEndReason = flowToAdd.FlowEndReason
+		}
+		aggFlow.flow.ClockSkewCorrected = aggFlow.flow.ClockSkewCorrected || flowToAdd.ClockSkewCorrected
 	}
 	f.flows[aggHash] = aggFlow
 }
 
+// addObservationCounters accumulates the bytes and packets of the flow with the ones reported at its observation
+// point since the last flush. The flow context keeps the highest counters among its observation points, as they
+// see the same packets, e.g. a router on egress and the next one on ingress.
+// The representative observation point of the flow is the one which reported the most bytes, the lowest one
+// winning ties, so that it doesn't depend on the order the exporters report the flow in.
+func (f *flowAccumulator) addObservationCounters(aggFlow *flowContext, flowToAdd *common.Flow) {
+	point := newObservationPoint(flowToAdd)
+	counters, ok := aggFlow.countersByObservation[point]
+	if !ok {
+		f.deduplicatedFlowCount.Inc()
+		counters = &flowCounters{}
+		aggFlow.countersByObservation[point] = counters
+	}
+	counters.bytes += flowToAdd.Bytes
+	counters.packets += flowToAdd.Packets
+
+	representative := aggFlow.countersByObservation[aggFlow.representative]
+	if point != aggFlow.representative &&
+		(counters.bytes > representative.bytes || (counters.bytes == representative.bytes && point.less(aggFlow.representative))) {
+		// the attributes of the flow are replaced by the ones of the new representative, the timestamps and
+		// flags being merged by the caller
+		merged := *flowToAdd
+		merged.StartTimestamp = aggFlow.flow.StartTimestamp
+		merged.EndTimestamp = aggFlow.flow.EndTimestamp
+		merged.TCPFlags = aggFlow.flow.TCPFlags
+		merged.FlowEndReason = aggFlow.flow.FlowEndReason
+		merged.ClockSkewCorrected = aggFlow.flow.ClockSkewCorrected
+		merged.Bytes = aggFlow.flow.Bytes
+		merged.Packets = aggFlow.flow.Packets
+		aggFlow.flow = &merged
+		aggFlow.representative = point
+	}
+	aggFlow.flow.Bytes = common.MaxUint64(aggFlow.flow.Bytes, counters.bytes)
+	aggFlow.flow.Packets = common.MaxUint64(aggFlow.flow.Packets, counters.packets)
+}
+
 func (f *flowAccumulator) getFlowContextCount() int {
 	f.flowsMutex.Lock()
 	defer f.flowsMutex.Unlock()
@@ -147,7 +251,11 @@ func (f *flowAccumulator) getFlowContextCount() int {
 }
 
 func (f *flowAccumulator) detectHashCollision(hash uint64, existingFlow common.Flow, flowToAdd common.Flow) {
-	if !common.IsEqualFlowContext(existingFlow, flowToAdd) {
+	isEqual := common.IsEqualFlowContext
+	if f.deduplicationEnabled {
+		isEqual = common.IsEqualFiveTuple
+	}
+	if !isEqual(existingFlow, flowToAdd) {
 		log.Warnf("Hash collision for flows with hash `%d`: existingFlow=`%+v` flowToAdd=`%+v`", hash, existingFlow, flowToAdd)
 		f.hashCollisionFlowCount.Inc()
 	}
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/DataDog/datadog-agent/pkg/netflow/common"
 	"github.com/DataDog/datadog-agent/pkg/netflow/portrollup"
@@ -79,7 +80,7 @@ func Test_flowAccumulator_add(t *testing.T) {
 	}
 
 	// When
-	acc := newFlowAccumulator(common.DefaultAggregatorFlushInterval, common.DefaultAggregatorFlushInterval, common.DefaultAggregatorPortRollupThreshold, false)
+	acc := newFlowAccumulator(common.DefaultAggregatorFlushInterval, common.DefaultAggregatorFlushInterval, common.DefaultAggregatorPortRollupThreshold, false, false)
 	acc.add(flowA1)
 	acc.add(flowA2)
 	acc.add(flowB1)
@@ -101,6 +102,71 @@ func Test_flowAccumulator_add(t *testing.T) {
 	assert.Equal(t, []byte{10, 10, 10, 30}, wrappedFlowB.flow.DstAddr)
 }
 
+func Test_flowAccumulator_deduplication(t *testing.T) {
+	newFlow := func(exporter byte, direction uint32, inputInterface uint32, bytes uint64, packets uint64) *common.Flow {
+		return &common.Flow{
+			FlowType:        common.TypeNetFlow9,
+			ExporterAddr:    []byte{127, 0, 0, exporter},
+			StartTimestamp:  1234568,
+			EndTimestamp:    1234569,
+			Bytes:           bytes,
+			Packets:         packets,
+			SrcAddr:         []byte{10, 10, 10, 10},
+			DstAddr:         []byte{10, 10, 10, 20},
+			IPProtocol:      uint32(6),
+			SrcPort:         2000,
+			DstPort:         80,
+			Direction:       direction,
+			InputInterface:  inputInterface,
+			OutputInterface: inputInterface + 1,
+		}
+	}
+	setMockTimeNow(MockTimeNow())
+
+	flows := []*common.Flow{
+		// the same packets reported by an exporter on ingress and on egress aren't counted twice
+		newFlow(1, 0, 1, 10, 2),
+		newFlow(1, 1, 1, 10, 2),
+		// nor the ones reported by another exporter
+		newFlow(2, 0, 3, 25, 5),
+		newFlow(2, 0, 3, 10, 2),
+	}
+	// the representative exporter of the flow doesn't depend on the order the flows are added in
+	for _, order := range [][]int{{0, 1, 2, 3}, {3, 2, 1, 0}} {
+		acc := newFlowAccumulator(common.DefaultAggregatorFlushInterval, common.DefaultAggregatorFlushInterval, common.DefaultAggregatorPortRollupThreshold, true, true)
+		for _, i := range order {
+			flow := *flows[i]
+			acc.add(&flow)
+		}
+
+		assert.Equal(t, 1, len(acc.flows))
+		assert.Equal(t, uint64(2), acc.deduplicatedFlowCount.Load())
+		flushed := acc.flush()
+		require.Len(t, flushed, 1)
+		assert.Equal(t, []byte{127, 0, 0, 2}, flushed[0].ExporterAddr)
+		assert.Equal(t, uint32(3), flushed[0].InputInterface)
+		assert.Equal(t, uint64(35), flushed[0].Bytes)
+		assert.Equal(t, uint64(7), flushed[0].Packets)
+	}
+
+	acc := newFlowAccumulator(common.DefaultAggregatorFlushInterval, common.DefaultAggregatorFlushInterval, common.DefaultAggregatorPortRollupThreshold, true, true)
+	acc.add(newFlow(1, 0, 1, 10, 2))
+	acc.add(newFlow(2, 0, 3, 25, 5))
+	acc.flush()
+
+	// the counters of the observation points are reset by the flush, and the lowest one is the representative
+	// on ties
+	setMockTimeNow(MockTimeNow().Add(common.DefaultAggregatorFlushInterval))
+	acc.add(newFlow(2, 0, 3, 5, 1))
+	acc.add(newFlow(1, 0, 1, 5, 1))
+	flushed := acc.flush()
+	require.Len(t, flushed, 1)
+	assert.Equal(t, []byte{127, 0, 0, 1}, flushed[0].ExporterAddr)
+	assert.Equal(t, uint64(5), flushed[0].Bytes)
+	assert.Equal(t, uint64(1), flushed[0].Packets)
+	assert.Equal(t, uint64(1234568), flushed[0].StartTimestamp)
+}
+
 func Test_flowAccumulator_portRollUp(t *testing.T) {
 	synFlag := uint32(2)
 	ackFlag := uint32(16)
@@ -150,7 +216,7 @@ func Test_flowAccumulator_portRollUp(t *testing.T) {
 	}
 
 	// When
-	acc := newFlowAccumulator(common.DefaultAggregatorFlushInterval, common.DefaultAggregatorFlushInterval, 3, false)
+	acc := newFlowAccumulator(common.DefaultAggregatorFlushInterval, common.DefaultAggregatorFlushInterval, 3, false, false)
 	acc.add(flowA1)
 	acc.add(flowA2)
 
@@ -225,7 +291,7 @@ func Test_flowAccumulator_flush(t *testing.T) {
 	}
 
 	// When
-	acc := newFlowAccumulator(flushInterval, flowContextTTL, common.DefaultAggregatorPortRollupThreshold, false)
+	acc := newFlowAccumulator(flushInterval, flowContextTTL, common.DefaultAggregatorPortRollupThreshold, false, false)
 	acc.add(flow)
 
 	// Then
@@ -0,0 +1,77 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package flowaggregator
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const prometheusNamespace = "datadog_netflow"
+
+var (
+	promFlowsReceived = prometheus.NewDesc(
+		prometheus.BuildFQName(prometheusNamespace, "aggregator", "flows_received_total"),
+		"Number of flows received by the aggregator from the listeners.", nil, nil)
+	promFlowsFlushed = prometheus.NewDesc(
+		prometheus.BuildFQName(prometheusNamespace, "aggregator", "flows_flushed_total"),
+		"Number of aggregated flows flushed to the forwarder.", nil, nil)
+	promHashCollisions = prometheus.NewDesc(
+		prometheus.BuildFQName(prometheusNamespace, "aggregator", "hash_collisions_total"),
+		"Number of flow hash collisions.", nil, nil)
+	promFlowContexts = prometheus.NewDesc(
+		prometheus.BuildFQName(prometheusNamespace, "aggregator", "flow_contexts"),
+		"Number of flow contexts currently held by the aggregator.", nil, nil)
+	promInputBufferLength = prometheus.NewDesc(
+		prometheus.BuildFQName(prometheusNamespace, "aggregator", "input_buffer_length"),
+		"Number of flows waiting in the aggregator input buffer.", nil, nil)
+	promInputBufferCapacity = prometheus.NewDesc(
+		prometheus.BuildFQName(prometheusNamespace, "aggregator", "input_buffer_capacity"),
+		"Capacity of the aggregator input buffer.", nil, nil)
+	promPayloadsSent = prometheus.NewDesc(
+		prometheus.BuildFQName(prometheusNamespace, "forwarder", "payloads_sent_total"),
+		"Number of payloads sent to the event platform forwarder.", []string{"payload_type"}, nil)
+	promPayloadErrors = prometheus.NewDesc(
+		prometheus.BuildFQName(prometheusNamespace, "forwarder", "payload_errors_total"),
+		"Number of payloads which could not be built or sent to the event platform forwarder.", []string{"payload_type"}, nil)
+)
+
+// prometheusCollector exposes the aggregator and forwarder internals as
+// Prometheus metrics, values are read from the aggregator when scraped.
+type prometheusCollector struct {
+	agg *FlowAggregator
+}
+
+// PrometheusCollector returns a Prometheus collector for the aggregator internals.
+func (agg *FlowAggregator) PrometheusCollector() prometheus.Collector {
+	return &prometheusCollector{agg: agg}
+}
+
+// Describe implements prometheus.Collector.
+func (c *prometheusCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- promFlowsReceived
+	ch <- promFlowsFlushed
+	ch <- promHashCollisions
+	ch <- promFlowContexts
+	ch <- promInputBufferLength
+	ch <- promInputBufferCapacity
+	ch <- promPayloadsSent
+	ch <- promPayloadErrors
+}
+
+// Collect implements prometheus.Collector.
+func (c *prometheusCollector) Collect(ch chan<- prometheus.Metric) {
+	agg := c.agg
+	ch <- prometheus.MustNewConstMetric(promFlowsReceived, prometheus.CounterValue, float64(agg.receivedFlowCount.Load()))
+	ch <- prometheus.MustNewConstMetric(promFlowsFlushed, prometheus.CounterValue, float64(agg.flushedFlowCount.Load()))
+	ch <- prometheus.MustNewConstMetric(promHashCollisions, prometheus.CounterValue, float64(agg.flowAcc.hashCollisionFlowCount.Load()))
+	ch <- prometheus.MustNewConstMetric(promFlowContexts, prometheus.GaugeValue, float64(agg.flowAcc.getFlowContextCount()))
+	ch <- prometheus.MustNewConstMetric(promInputBufferLength, prometheus.GaugeValue, float64(len(agg.flowIn)))
+	ch <- prometheus.MustNewConstMetric(promInputBufferCapacity, prometheus.GaugeValue, float64(cap(agg.flowIn)))
+	ch <- prometheus.MustNewConstMetric(promPayloadsSent, prometheus.CounterValue, float64(agg.sentFlowPayloadCount.Load()), "flow")
+	ch <- prometheus.MustNewConstMetric(promPayloadsSent, prometheus.CounterValue, float64(agg.sentMetadataPayloadCount.Load()), "metadata")
+	ch <- prometheus.MustNewConstMetric(promPayloadErrors, prometheus.CounterValue, float64(agg.flowPayloadErrorCount.Load()), "flow")
+	ch <- prometheus.MustNewConstMetric(promPayloadErrors, prometheus.CounterValue, float64(agg.metadataPayloadErrorCount.Load()), "metadata")
+}
@@ -0,0 +1,68 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package flowaggregator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/epforwarder"
+
+	"github.com/DataDog/datadog-agent/pkg/netflow/common"
+	"github.com/DataDog/datadog-agent/pkg/netflow/config"
+)
+
+func TestFlowAggregator_PrometheusCollector(t *testing.T) {
+	sender := mocksender.NewMockSender("")
+	conf := config.NetflowConfig{
+		AggregatorBufferSize:                   20,
+		AggregatorFlushInterval:                1,
+		AggregatorPortRollupThreshold:          10,
+		AggregatorRollupTrackerRefreshInterval: 3600,
+	}
+	ctrl := gomock.NewController(t)
+	epForwarder := epforwarder.NewMockEventPlatformForwarder(ctrl)
+
+	aggregator := NewFlowAggregator(sender, epForwarder, &conf, "my-hostname")
+	aggregator.receivedFlowCount.Add(3)
+	aggregator.sentFlowPayloadCount.Add(2)
+	aggregator.metadataPayloadErrorCount.Inc()
+	aggregator.flowIn <- &common.Flow{}
+
+	expected := `
+# HELP datadog_netflow_aggregator_flows_received_total Number of flows received by the aggregator from the listeners.
+# TYPE datadog_netflow_aggregator_flows_received_total counter
+datadog_netflow_aggregator_flows_received_total 3
+# HELP datadog_netflow_aggregator_input_buffer_capacity Capacity of the aggregator input buffer.
+# TYPE datadog_netflow_aggregator_input_buffer_capacity gauge
+datadog_netflow_aggregator_input_buffer_capacity 20
+# HELP datadog_netflow_aggregator_input_buffer_length Number of flows waiting in the aggregator input buffer.
+# TYPE datadog_netflow_aggregator_input_buffer_length gauge
+datadog_netflow_aggregator_input_buffer_length 1
+# HELP datadog_netflow_forwarder_payload_errors_total Number of payloads which could not be built or sent to the event platform forwarder.
+# TYPE datadog_netflow_forwarder_payload_errors_total counter
+datadog_netflow_forwarder_payload_errors_total{payload_type="flow"} 0
+datadog_netflow_forwarder_payload_errors_total{payload_type="metadata"} 1
+# HELP datadog_netflow_forwarder_payloads_sent_total Number of payloads sent to the event platform forwarder.
+# TYPE datadog_netflow_forwarder_payloads_sent_total counter
+datadog_netflow_forwarder_payloads_sent_total{payload_type="flow"} 2
+datadog_netflow_forwarder_payloads_sent_total{payload_type="metadata"} 0
+`
+	err := testutil.CollectAndCompare(aggregator.PrometheusCollector(), strings.NewReader(expected),
+		"datadog_netflow_aggregator_flows_received_total",
+		"datadog_netflow_aggregator_input_buffer_capacity",
+		"datadog_netflow_aggregator_input_buffer_length",
+		"datadog_netflow_forwarder_payload_errors_total",
+		"datadog_netflow_forwarder_payloads_sent_total",
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, 10, testutil.CollectAndCount(aggregator.PrometheusCollector()))
+}
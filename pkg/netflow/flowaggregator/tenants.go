@@ -0,0 +1,99 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package flowaggregator
+
+import (
+	"net"
+
+	"github.com/DataDog/datadog-agent/pkg/epforwarder"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+
+	"github.com/DataDog/datadog-agent/pkg/netflow/common"
+	"github.com/DataDog/datadog-agent/pkg/netflow/config"
+)
+
+// newTenantForwarder creates the forwarder of a tenant, it's replaced in tests
+var newTenantForwarder = epforwarder.NewTenantEventPlatformForwarder
+
+type tenantExporters struct {
+	tenant string
+	nets   []*net.IPNet
+}
+
+// tenantRouter resolves the tenant of the flows, and holds the forwarders sending the flows and the
+// exporter metadata to the organizations of the tenants
+type tenantRouter struct {
+	exporters  []tenantExporters
+	forwarders map[string]epforwarder.EventPlatformForwarder
+}
+
+func newTenantRouter(tenants []config.TenantConfig) *tenantRouter {
+	router := &tenantRouter{
+		forwarders: make(map[string]epforwarder.EventPlatformForwarder, len(tenants)),
+	}
+	for _, tenant := range tenants {
+		exporters := tenantExporters{tenant: tenant.Name}
+		for _, exporter := range tenant.Exporters {
+			ipNet, err := common.ParseIPOrCIDR(exporter)
+			if err != nil {
+				// the exporters are validated when reading the config
+				log.Warnf("Ignoring the invalid exporter `%s` of the tenant `%s`: %s", exporter, tenant.Name, err)
+				continue
+			}
+			exporters.nets = append(exporters.nets, ipNet)
+		}
+		if len(exporters.nets) > 0 {
+			router.exporters = append(router.exporters, exporters)
+		}
+		router.forwarders[tenant.Name] = newTenantForwarder(
+			epforwarder.Tenant{Name: tenant.Name, APIKey: tenant.APIKey},
+			epforwarder.EventTypeNetworkDevicesNetFlow,
+			epforwarder.EventTypeNetworkDevicesMetadata,
+		)
+	}
+	return router
+}
+
+// resolveTenant sets the tenant of the flow. The tenant whose exporters contain the flow exporter takes
+// precedence over the tenant of the listener the flow was received on.
+func (r *tenantRouter) resolveTenant(flow *common.Flow) {
+	if len(r.exporters) == 0 {
+		return
+	}
+	exporterIP := net.IP(flow.ExporterAddr)
+	for _, exporters := range r.exporters {
+		for _, ipNet := range exporters.nets {
+			if ipNet.Contains(exporterIP) {
+				flow.Tenant = exporters.tenant
+				return
+			}
+		}
+	}
+}
+
+// forwarder returns the forwarder of the tenant, or the given default forwarder for the organization of the agent
+func (r *tenantRouter) forwarder(tenant string, defaultForwarder epforwarder.EventPlatformForwarder) epforwarder.EventPlatformForwarder {
+	if tenant == "" {
+		return defaultForwarder
+	}
+	if forwarder, ok := r.forwarders[tenant]; ok {
+		return forwarder
+	}
+	log.Warnf("Unknown tenant `%s`, sending to the organization of the agent", tenant)
+	return defaultForwarder
+}
+
+func (r *tenantRouter) start() {
+	for _, forwarder := range r.forwarders {
+		forwarder.Start()
+	}
+}
+
+func (r *tenantRouter) stop() {
+	for _, forwarder := range r.forwarders {
+		forwarder.Stop()
+	}
+}
@@ -0,0 +1,113 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package flowaggregator
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/epforwarder"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+
+	"github.com/DataDog/datadog-agent/pkg/netflow/common"
+	"github.com/DataDog/datadog-agent/pkg/netflow/config"
+)
+
+func mockTenantForwarders(t *testing.T, ctrl *gomock.Controller) map[string]*epforwarder.MockEventPlatformForwarder {
+	forwarders := make(map[string]*epforwarder.MockEventPlatformForwarder)
+	previous := newTenantForwarder
+	newTenantForwarder = func(tenant epforwarder.Tenant, eventTypes ...string) epforwarder.EventPlatformForwarder {
+		assert.ElementsMatch(t, []string{epforwarder.EventTypeNetworkDevicesNetFlow, epforwarder.EventTypeNetworkDevicesMetadata}, eventTypes)
+		forwarder := epforwarder.NewMockEventPlatformForwarder(ctrl)
+		forwarders[tenant.Name] = forwarder
+		return forwarder
+	}
+	t.Cleanup(func() { newTenantForwarder = previous })
+	return forwarders
+}
+
+func TestTenantRouter_resolveTenant(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockTenantForwarders(t, ctrl)
+
+	router := newTenantRouter([]config.TenantConfig{
+		{Name: "customer-a", APIKey: "key-a", Exporters: []string{"10.0.0.0/24", "192.168.1.1"}},
+		{Name: "customer-b", APIKey: "key-b", Exporters: []string{"2001:db8::/32"}},
+		{Name: "customer-c", APIKey: "key-c"},
+	})
+
+	for _, tc := range []struct {
+		name           string
+		exporterAddr   []byte
+		listenerTenant string
+		expectedTenant string
+	}{
+		{name: "exporter in CIDR", exporterAddr: []byte{10, 0, 0, 12}, expectedTenant: "customer-a"},
+		{name: "exporter IP", exporterAddr: []byte{192, 168, 1, 1}, expectedTenant: "customer-a"},
+		{name: "IPv6 exporter", exporterAddr: []byte{0x20, 0x01, 0x0d, 0xb8, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1}, expectedTenant: "customer-b"},
+		{name: "exporter takes precedence over listener", exporterAddr: []byte{10, 0, 0, 12}, listenerTenant: "customer-c", expectedTenant: "customer-a"},
+		{name: "listener tenant", exporterAddr: []byte{192, 168, 1, 2}, listenerTenant: "customer-c", expectedTenant: "customer-c"},
+		{name: "no tenant", exporterAddr: []byte{192, 168, 1, 2}, expectedTenant: ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			flow := &common.Flow{ExporterAddr: tc.exporterAddr, Tenant: tc.listenerTenant}
+			router.resolveTenant(flow)
+			assert.Equal(t, tc.expectedTenant, flow.Tenant)
+		})
+	}
+}
+
+func TestFlowAggregator_tenantRouting(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	tenantForwarders := mockTenantForwarders(t, ctrl)
+	epForwarder := epforwarder.NewMockEventPlatformForwarder(ctrl)
+
+	conf := config.NetflowConfig{
+		AggregatorBufferSize:                   20,
+		AggregatorFlushInterval:                1,
+		AggregatorPortRollupThreshold:          10,
+		AggregatorRollupTrackerRefreshInterval: 3600,
+		Tenants: []config.TenantConfig{
+			{Name: "customer-a", APIKey: "key-a", Exporters: []string{"10.0.0.0/24"}},
+		},
+	}
+	aggregator := NewFlowAggregator(mocksender.NewMockSender(""), epForwarder, &conf, "my-hostname")
+	require.Contains(t, tenantForwarders, "customer-a")
+	tenantForwarder := tenantForwarders["customer-a"]
+
+	flows := []*common.Flow{
+		{Namespace: "my-ns", FlowType: common.TypeNetFlow9, ExporterAddr: []byte{10, 0, 0, 1}, Tenant: "customer-a"},
+		{Namespace: "my-ns", FlowType: common.TypeNetFlow9, ExporterAddr: []byte{127, 0, 0, 1}},
+	}
+
+	exporterIPs := func(forwarder *epforwarder.MockEventPlatformForwarder, eventType string, expectedIP string) {
+		forwarder.EXPECT().SendEventPlatformEventBlocking(gomock.Any(), eventType).DoAndReturn(func(m *message.Message, _ string) error {
+			var payload map[string]interface{}
+			require.NoError(t, json.Unmarshal(m.Content, &payload))
+			if eventType == epforwarder.EventTypeNetworkDevicesNetFlow {
+				assert.Equal(t, expectedIP, payload["exporter"].(map[string]interface{})["ip"])
+			} else {
+				exporters := payload["netflow_exporters"].([]interface{})
+				require.Len(t, exporters, 1)
+				assert.Equal(t, expectedIP, exporters[0].(map[string]interface{})["ip_address"])
+			}
+			return nil
+		}).Times(1)
+	}
+	exporterIPs(tenantForwarder, epforwarder.EventTypeNetworkDevicesNetFlow, "10.0.0.1")
+	exporterIPs(epForwarder, epforwarder.EventTypeNetworkDevicesNetFlow, "127.0.0.1")
+	exporterIPs(tenantForwarder, epforwarder.EventTypeNetworkDevicesMetadata, "10.0.0.1")
+	exporterIPs(epForwarder, epforwarder.EventTypeNetworkDevicesMetadata, "127.0.0.1")
+
+	aggregator.sendFlows(flows)
+	aggregator.sendExporterMetadata(flows, time.Unix(1681295467, 0))
+}
@@ -14,30 +14,34 @@ import (
 // ConvertFlow convert goflow flow structure to internal flow structure
 func ConvertFlow(srcFlow *flowpb.FlowMessage, namespace string) *common.Flow {
 	return &common.Flow{
-		Namespace:       namespace,
-		FlowType:        convertFlowType(srcFlow.Type),
-		SamplingRate:    srcFlow.SamplingRate,
-		Direction:       srcFlow.FlowDirection,
-		ExporterAddr:    srcFlow.SamplerAddress, // Sampler is renamed to Exporter since it's a more commonly used
-		StartTimestamp:  srcFlow.TimeFlowStart,
-		EndTimestamp:    srcFlow.TimeFlowEnd,
-		Bytes:           srcFlow.Bytes,
-		Packets:         srcFlow.Packets,
-		SrcAddr:         srcFlow.SrcAddr,
-		DstAddr:         srcFlow.DstAddr,
-		SrcMac:          srcFlow.SrcMac,
-		DstMac:          srcFlow.DstMac,
-		SrcMask:         srcFlow.SrcNet,
-		DstMask:         srcFlow.DstNet,
-		EtherType:       srcFlow.Etype,
-		IPProtocol:      srcFlow.Proto,
-		SrcPort:         int32(srcFlow.SrcPort),
-		DstPort:         int32(srcFlow.DstPort),
-		InputInterface:  srcFlow.InIf,
-		OutputInterface: srcFlow.OutIf,
-		Tos:             srcFlow.IpTos,
-		NextHop:         srcFlow.NextHop,
-		TCPFlags:        srcFlow.TcpFlags,
+		Namespace:         namespace,
+		FlowType:          convertFlowType(srcFlow.Type),
+		SamplingRate:      srcFlow.SamplingRate,
+		Direction:         srcFlow.FlowDirection,
+		ExporterAddr:      srcFlow.SamplerAddress, // Sampler is renamed to Exporter since it's a more commonly used
+		SequenceNum:       srcFlow.SequenceNum,
+		ObservationDomain: srcFlow.ObservationDomainId,
+		StartTimestamp:    srcFlow.TimeFlowStart,
+		EndTimestamp:      srcFlow.TimeFlowEnd,
+		Bytes:             srcFlow.Bytes,
+		Packets:           srcFlow.Packets,
+		SrcAddr:           srcFlow.SrcAddr,
+		DstAddr:           srcFlow.DstAddr,
+		SrcMac:            srcFlow.SrcMac,
+		DstMac:            srcFlow.DstMac,
+		SrcMask:           srcFlow.SrcNet,
+		DstMask:           srcFlow.DstNet,
+		EtherType:         srcFlow.Etype,
+		IPProtocol:        srcFlow.Proto,
+		SrcPort:           int32(srcFlow.SrcPort),
+		DstPort:           int32(srcFlow.DstPort),
+		InputInterface:    srcFlow.InIf,
+		OutputInterface:   srcFlow.OutIf,
+		Tos:               srcFlow.IpTos,
+		NextHop:           srcFlow.NextHop,
+		TCPFlags:          srcFlow.TcpFlags,
+		FlowEndReason:     uint32(srcFlow.CustomInteger_1),
+		SamplerID:         srcFlow.CustomInteger_2,
 	}
 }
 
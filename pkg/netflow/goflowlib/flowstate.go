@@ -11,6 +11,7 @@ import (
 
 	"github.com/netsampler/goflow2/decoders/netflow/templates"
 	_ "github.com/netsampler/goflow2/decoders/netflow/templates/memory"
+	"github.com/netsampler/goflow2/producer"
 	"github.com/netsampler/goflow2/utils"
 
 	"github.com/DataDog/datadog-agent/pkg/util/log"
@@ -22,6 +23,28 @@ import (
 // more info here: https://stackoverflow.com/questions/14388706/how-do-so-reuseaddr-and-so-reuseport-differ
 const reusePort = false
 
+// flowEndReasonField is the IPFIX flowEndReason information element, also used by some NetFlow v9 exporters.
+// goflow doesn't decode it, so it's mapped to a custom field of the flow message.
+const flowEndReasonField = 136
+
+// goflow doesn't decode the ID of the sampler of the flows either, the NetFlow v9 FLOW_SAMPLER_ID field or the
+// IPFIX selectorId information element, so it's mapped to a custom field of the flow message.
+var netFlowProducerConfig = &producer.ProducerConfig{
+	IPFIX: producer.IPFIXProducerConfig{
+		Mapping: []producer.NetFlowMapField{
+			{Type: flowEndReasonField, Destination: "CustomInteger_1"},
+			{Type: fieldSamplerID, Destination: "CustomInteger_2"},
+			{Type: fieldSelectorID, Destination: "CustomInteger_2"},
+		},
+	},
+	NetFlowV9: producer.NetFlowV9ProducerConfig{
+		Mapping: []producer.NetFlowMapField{
+			{Type: flowEndReasonField, Destination: "CustomInteger_1"},
+			{Type: fieldSamplerID, Destination: "CustomInteger_2"},
+		},
+	},
+}
+
 // FlowStateWrapper is a wrapper for StateNetFlow/StateSFlow/StateNFLegacy to provide additional info like hostname/port
 type FlowStateWrapper struct {
 	State    FlowRunnableState
@@ -39,10 +62,10 @@ type FlowRunnableState interface {
 }
 
 // StartFlowRoutine starts one of the goflow flow routine depending on the flow type
-func StartFlowRoutine(flowType common.FlowType, hostname string, port uint16, workers int, namespace string, flowInChan chan *common.Flow) (*FlowStateWrapper, error) {
+func StartFlowRoutine(flowType common.FlowType, hostname string, port uint16, workers int, namespace string, tenant string, flowInChan chan *common.Flow) (*FlowStateWrapper, error) {
 	var flowState FlowRunnableState
 
-	formatDriver := NewAggregatorFormatDriver(flowInChan, namespace)
+	formatDriver := NewAggregatorFormatDriver(flowInChan, namespace, tenant)
 	logger := GetLogrusLevel()
 	ctx := context.Background()
 
@@ -54,7 +77,10 @@ func StartFlowRoutine(flowType common.FlowType, hostname string, port uint16, wo
 		}
 		defer templateSystem.Close(ctx)
 
-		state := utils.NewStateNetFlow()
+		options := newExporterOptionsCache()
+		formatDriver.options = options
+
+		state := newNetFlowState(netFlowProducerConfig, options)
 		state.Format = formatDriver
 		state.Logger = logger
 		state.TemplateSystem = templateSystem
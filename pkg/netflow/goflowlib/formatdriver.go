@@ -17,13 +17,18 @@ import (
 // AggregatorFormatDriver is used as goflow formatter to forward flow data to aggregator/EP Forwarder
 type AggregatorFormatDriver struct {
 	namespace string
+	tenant    string
 	flowAggIn chan *common.Flow
+
+	// options holds the options of the NetFlow v9 and IPFIX exporters, nil for the other flow types
+	options *exporterOptionsCache
 }
 
 // NewAggregatorFormatDriver returns a new AggregatorFormatDriver
-func NewAggregatorFormatDriver(flowAgg chan *common.Flow, namespace string) *AggregatorFormatDriver {
+func NewAggregatorFormatDriver(flowAgg chan *common.Flow, namespace string, tenant string) *AggregatorFormatDriver {
 	return &AggregatorFormatDriver{
 		namespace: namespace,
+		tenant:    tenant,
 		flowAggIn: flowAgg,
 	}
 }
@@ -44,6 +49,11 @@ func (d *AggregatorFormatDriver) Format(data interface{}) ([]byte, []byte, error
 	if !ok {
 		return nil, nil, fmt.Errorf("message is not flowpb.FlowMessage")
 	}
-	d.flowAggIn <- ConvertFlow(flow, d.namespace)
+	aggFlow := ConvertFlow(flow, d.namespace)
+	aggFlow.Tenant = d.tenant
+	if d.options != nil {
+		d.options.enrich(aggFlow)
+	}
+	d.flowAggIn <- aggFlow
 	return nil, nil, nil
 }
@@ -0,0 +1,186 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package goflowlib
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/netsampler/goflow2/decoders/netflow"
+	"github.com/netsampler/goflow2/decoders/netflow/templates"
+	"github.com/netsampler/goflow2/format"
+	flowpb "github.com/netsampler/goflow2/pb"
+	"github.com/netsampler/goflow2/producer"
+	"github.com/netsampler/goflow2/utils"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// netFlowState decodes the NetFlow v9 and IPFIX packets like goflow's StateNetFlow, and reports the same goflow
+// metrics. Unlike StateNetFlow, which drops the options data records once the sampling rate is read from them,
+// it keeps the options of the exporters to enrich their flows.
+type netFlowState struct {
+	Format         format.FormatInterface
+	Logger         utils.Logger
+	TemplateSystem templates.TemplateInterface
+
+	config  *producer.ProducerConfigMapped
+	options *exporterOptionsCache
+
+	samplingLock sync.RWMutex
+	sampling     map[string]producer.SamplingRateSystem
+
+	stopLock sync.Mutex
+	stopCh   chan struct{}
+
+	ctx context.Context
+}
+
+func newNetFlowState(config *producer.ProducerConfig, options *exporterOptionsCache) *netFlowState {
+	return &netFlowState{
+		config:   producer.NewProducerConfigMapped(config),
+		options:  options,
+		sampling: make(map[string]producer.SamplingRateSystem),
+		ctx:      context.Background(),
+	}
+}
+
+// FlowRoutine starts the flow processing workers
+func (s *netFlowState) FlowRoutine(workers int, addr string, port int, reuseport bool) error {
+	s.stopLock.Lock()
+	if s.stopCh != nil {
+		s.stopLock.Unlock()
+		return utils.ErrAlreadyStarted
+	}
+	stopCh := make(chan struct{})
+	s.stopCh = stopCh
+	s.stopLock.Unlock()
+
+	return utils.UDPStoppableRoutine(stopCh, "NetFlow", s.decodeFlow, workers, addr, port, reuseport, s.Logger)
+}
+
+// Shutdown triggers the shutdown of the flow processing workers
+func (s *netFlowState) Shutdown() {
+	s.stopLock.Lock()
+	defer s.stopLock.Unlock()
+	if s.stopCh != nil {
+		close(s.stopCh)
+		s.stopCh = nil
+	}
+}
+
+func (s *netFlowState) samplingSystem(key string) producer.SamplingRateSystem {
+	s.samplingLock.RLock()
+	sampling, ok := s.sampling[key]
+	s.samplingLock.RUnlock()
+	if ok {
+		return sampling
+	}
+
+	s.samplingLock.Lock()
+	defer s.samplingLock.Unlock()
+	if sampling, ok = s.sampling[key]; !ok {
+		sampling = producer.CreateSamplingSystem()
+		s.sampling[key] = sampling
+	}
+	return sampling
+}
+
+func (s *netFlowState) decodeFlow(msg interface{}) error {
+	pkt := msg.(utils.BaseMessage)
+	key := pkt.Src.String()
+	samplerAddress := pkt.Src
+	if samplerAddress.To4() != nil {
+		samplerAddress = samplerAddress.To4()
+	}
+	sampling := s.samplingSystem(key)
+
+	ts := uint64(time.Now().UTC().Unix())
+	if pkt.SetTime {
+		ts = uint64(pkt.RecvTime.UTC().Unix())
+	}
+
+	timeTrackStart := time.Now()
+	msgDec, err := netflow.DecodeMessageContext(s.ctx, bytes.NewBuffer(pkt.Payload), key, netflow.TemplateWrapper{Ctx: s.ctx, Key: key, Inner: s.TemplateSystem})
+	if err != nil {
+		decodingError := "error_decoding"
+		if _, ok := err.(*netflow.ErrorTemplateNotFound); ok {
+			decodingError = "template_not_found"
+		}
+		utils.NetFlowErrors.With(prometheus.Labels{"router": key, "error": decodingError}).Inc()
+		return err
+	}
+
+	var version uint16
+	var flowSets []interface{}
+	var observationDomain uint32
+	switch msgDecConv := msgDec.(type) {
+	case netflow.NFv9Packet:
+		version, flowSets, observationDomain = 9, msgDecConv.FlowSets, msgDecConv.SourceId
+	case netflow.IPFIXPacket:
+		version, flowSets, observationDomain = 10, msgDecConv.FlowSets, msgDecConv.ObservationDomainId
+	}
+	versionLabel := strconv.Itoa(int(version))
+	utils.NetFlowStats.With(prometheus.Labels{"router": key, "version": versionLabel}).Inc()
+
+	var optionsDataFlowSets []netflow.OptionsDataFlowSet
+	for _, flowSet := range flowSets {
+		var flowSetType string
+		var records int
+		switch flowSetConv := flowSet.(type) {
+		case netflow.TemplateFlowSet:
+			flowSetType, records = "TemplateFlowSet", len(flowSetConv.Records)
+		case netflow.NFv9OptionsTemplateFlowSet:
+			flowSetType, records = "OptionsTemplateFlowSet", len(flowSetConv.Records)
+		case netflow.IPFIXOptionsTemplateFlowSet:
+			flowSetType, records = "OptionsTemplateFlowSet", len(flowSetConv.Records)
+		case netflow.OptionsDataFlowSet:
+			flowSetType, records = "OptionsDataFlowSet", len(flowSetConv.Records)
+			optionsDataFlowSets = append(optionsDataFlowSets, flowSetConv)
+		case netflow.DataFlowSet:
+			flowSetType, records = "DataFlowSet", len(flowSetConv.Records)
+		default:
+			continue
+		}
+		labels := prometheus.Labels{"router": key, "version": versionLabel, "type": flowSetType}
+		utils.NetFlowSetStatsSum.With(labels).Inc()
+		utils.NetFlowSetRecordsStatsSum.With(labels).Add(float64(records))
+	}
+	// the options are updated before the flows of the packet are sent, for them to be enriched
+	s.options.update(key, observationDomain, version, optionsDataFlowSets)
+
+	flowMessageSet, err := producer.ProcessMessageNetFlowConfig(msgDec, sampling, s.config)
+	if err != nil {
+		return err
+	}
+	for _, fmsg := range flowMessageSet {
+		fmsg.TimeReceived = ts
+		fmsg.SamplerAddress = samplerAddress
+		// goflow only sets the observation domain of the IPFIX flows
+		if version == 9 {
+			fmsg.ObservationDomainId = observationDomain
+		}
+		timeDiff := fmsg.TimeReceived - fmsg.TimeFlowEnd
+		utils.NetFlowTimeStatsSum.With(prometheus.Labels{"router": key, "version": versionLabel}).Observe(float64(timeDiff))
+	}
+	utils.DecoderTime.With(prometheus.Labels{"name": "NetFlow"}).Observe(float64(time.Since(timeTrackStart).Nanoseconds()) / 1000)
+
+	s.sendFlows(flowMessageSet)
+	return nil
+}
+
+func (s *netFlowState) sendFlows(flowMessageSet []*flowpb.FlowMessage) {
+	if s.Format == nil {
+		return
+	}
+	for _, fmsg := range flowMessageSet {
+		if _, _, err := s.Format.Format(fmsg); err != nil && s.Logger != nil {
+			s.Logger.Error(err)
+		}
+	}
+}
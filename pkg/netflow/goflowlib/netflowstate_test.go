@@ -0,0 +1,78 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package goflowlib
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"net"
+	"testing"
+
+	"github.com/netsampler/goflow2/decoders/netflow/templates"
+	"github.com/netsampler/goflow2/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/netflow/common"
+)
+
+// netFlow9Packet returns a NetFlow v9 packet holding the given flow sets
+func netFlow9Packet(flowSets ...[]byte) []byte {
+	var buf bytes.Buffer
+	header := []uint32{1000, 1670000000, 1, 0}                               // sysUptime, unixSecs, sequence, sourceID
+	binary.Write(&buf, binary.BigEndian, []uint16{9, uint16(len(flowSets))}) //nolint:errcheck
+	binary.Write(&buf, binary.BigEndian, header)                             //nolint:errcheck
+	for _, flowSet := range flowSets {
+		buf.Write(flowSet)
+	}
+	return buf.Bytes()
+}
+
+// flowSet returns a flow set holding the given values, encoded in big endian
+func flowSet(id uint16, values ...interface{}) []byte {
+	var body bytes.Buffer
+	for _, value := range values {
+		binary.Write(&body, binary.BigEndian, value) //nolint:errcheck
+	}
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.BigEndian, []uint16{id, uint16(4 + body.Len())}) //nolint:errcheck
+	buf.Write(body.Bytes())
+	return buf.Bytes()
+}
+
+func Test_netFlowState_decodeFlow(t *testing.T) {
+	templateSystem, err := templates.FindTemplateSystem(context.Background(), "memory")
+	require.NoError(t, err)
+
+	flowChan := make(chan *common.Flow, 10)
+	options := newExporterOptionsCache()
+	formatDriver := NewAggregatorFormatDriver(flowChan, "my-ns", "")
+	formatDriver.options = options
+	state := newNetFlowState(netFlowProducerConfig, options)
+	state.Format = formatDriver
+	state.TemplateSystem = templateSystem
+
+	packet := netFlow9Packet(
+		// template 256: IN_BYTES, INPUT_SNMP, OUTPUT_SNMP
+		flowSet(0, []uint16{256, 3, 1, 4, 10, 4, 14, 4}),
+		// options template 257: interface scope, IF_NAME
+		flowSet(1, []uint16{257, 4, 4, nfv9ScopeInterface, 4, fieldInterfaceName, 4}),
+		flowSet(257, uint32(1), []byte("eth0"), uint32(2), []byte("eth1")),
+		flowSet(256, []uint32{100, 1, 2}),
+	)
+	require.NoError(t, state.decodeFlow(utils.BaseMessage{Src: net.ParseIP("127.0.0.1"), Payload: packet}))
+
+	require.Len(t, flowChan, 1)
+	flow := <-flowChan
+	assert.Equal(t, common.TypeNetFlow9, flow.FlowType)
+	assert.Equal(t, "127.0.0.1", common.IPBytesToString(flow.ExporterAddr))
+	assert.Equal(t, uint64(100), flow.Bytes)
+	assert.Equal(t, uint32(1), flow.InputInterface)
+	assert.Equal(t, "eth0", flow.InputInterfaceName)
+	assert.Equal(t, uint32(2), flow.OutputInterface)
+	assert.Equal(t, "eth1", flow.OutputInterfaceName)
+}
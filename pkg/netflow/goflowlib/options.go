@@ -0,0 +1,207 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package goflowlib
+
+import (
+	"bytes"
+	"sync"
+
+	"github.com/netsampler/goflow2/decoders/netflow"
+	"github.com/netsampler/goflow2/producer"
+
+	"github.com/DataDog/datadog-agent/pkg/netflow/common"
+)
+
+// Fields of the options data records describing the interfaces and the samplers of an exporter.
+// The NetFlow v9 fields share the numbers of the IPFIX information elements, except for the scope fields.
+const (
+	// nfv9ScopeInterface is the NetFlow v9 scope of the records describing an interface
+	nfv9ScopeInterface = 2
+
+	fieldIngressInterface       = 10
+	fieldEgressInterface        = 14
+	fieldSamplingInterval       = 34
+	fieldSamplerRandomInterval  = 50
+	fieldSamplerID              = 48
+	fieldInterfaceName          = 82
+	fieldInterfaceDescription   = 83
+	fieldSelectorID             = 302
+	fieldSamplingPacketInterval = 305
+	fieldSamplingPacketSpace    = 306
+)
+
+// exporterOptionsKey identifies an observation domain of an exporter, the options data records of an exporter
+// being scoped to the observation domain they are sent in like its templates.
+type exporterOptionsKey struct {
+	exporter          string
+	observationDomain uint32
+}
+
+// exporterOptions holds what the options data records of an exporter describe
+type exporterOptions struct {
+	// interfaceNames holds the names of the interfaces by index
+	interfaceNames map[uint32]string
+	// samplingRates holds the sampling rates of the samplers by ID, the sampler ID 0 being used for the
+	// sampling rates that aren't described for a sampler
+	samplingRates map[uint64]uint32
+}
+
+// exporterOptionsCache holds the options of the exporters by IP address and observation domain. The options
+// data records are sent periodically by the exporters, the options of an exporter being updated with each
+// record received.
+type exporterOptionsCache struct {
+	mu        sync.RWMutex
+	exporters map[exporterOptionsKey]*exporterOptions
+}
+
+func newExporterOptionsCache() *exporterOptionsCache {
+	return &exporterOptionsCache{
+		exporters: make(map[exporterOptionsKey]*exporterOptions),
+	}
+}
+
+// update updates the options of the observation domain of the exporter with the options data records of a
+// NetFlow v9 (version 9) or IPFIX (version 10) packet.
+func (c *exporterOptionsCache) update(exporter string, observationDomain uint32, version uint16, flowSets []netflow.OptionsDataFlowSet) {
+	if len(flowSets) == 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := exporterOptionsKey{exporter: exporter, observationDomain: observationDomain}
+	options, ok := c.exporters[key]
+	if !ok {
+		options = &exporterOptions{
+			interfaceNames: make(map[uint32]string),
+			samplingRates:  make(map[uint64]uint32),
+		}
+		c.exporters[key] = options
+	}
+	for _, flowSet := range flowSets {
+		for _, record := range flowSet.Records {
+			if index, name, ok := interfaceOption(version, record); ok {
+				options.interfaceNames[index] = name
+			}
+			if samplingRate, ok := samplingRateOption(record); ok {
+				options.samplingRates[samplerID(record)] = samplingRate
+			}
+		}
+	}
+}
+
+// enrich sets the interface names of the flow, and its sampling rate when the flow doesn't have one. The
+// sampling rate is the one of the sampler of the flow, or the one not described for a sampler when the
+// sampler of the flow is unknown.
+func (c *exporterOptionsCache) enrich(flow *common.Flow) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	options, ok := c.exporters[exporterOptionsKey{
+		exporter:          common.IPBytesToString(flow.ExporterAddr),
+		observationDomain: flow.ObservationDomain,
+	}]
+	if !ok {
+		return
+	}
+	flow.InputInterfaceName = options.interfaceNames[flow.InputInterface]
+	flow.OutputInterfaceName = options.interfaceNames[flow.OutputInterface]
+	if flow.SamplingRate == 0 {
+		samplingRate, ok := options.samplingRates[flow.SamplerID]
+		if !ok {
+			samplingRate = options.samplingRates[0]
+		}
+		flow.SamplingRate = uint64(samplingRate)
+	}
+}
+
+// interfaceOption returns the index and the name of the interface described by an options data record. The
+// description of the interface is used when the record doesn't have its name.
+func interfaceOption(version uint16, record netflow.OptionsDataRecord) (uint32, string, bool) {
+	index, ok := interfaceIndex(version, record)
+	if !ok {
+		return 0, "", false
+	}
+	for _, fieldType := range []uint16{fieldInterfaceName, fieldInterfaceDescription} {
+		if value, ok := fieldValue(record.OptionsValues, fieldType); ok {
+			// the exporters pad the names of fixed length fields with null bytes
+			if name := string(bytes.TrimRight(value, "\x00")); name != "" {
+				return index, name, true
+			}
+		}
+	}
+	return 0, "", false
+}
+
+// interfaceIndex returns the index of the interface an options data record is scoped to, or holds.
+func interfaceIndex(version uint16, record netflow.OptionsDataRecord) (uint32, bool) {
+	scopeTypes := []uint16{fieldIngressInterface, fieldEgressInterface}
+	if version == 9 {
+		scopeTypes = []uint16{nfv9ScopeInterface}
+	}
+	for _, fieldType := range scopeTypes {
+		if index, ok := uintValue(record.ScopesValues, fieldType); ok {
+			return uint32(index), true
+		}
+	}
+	for _, fieldType := range []uint16{fieldIngressInterface, fieldEgressInterface} {
+		if index, ok := uintValue(record.OptionsValues, fieldType); ok {
+			return uint32(index), true
+		}
+	}
+	return 0, false
+}
+
+// samplingRateOption returns the sampling rate of the sampler described by an options data record.
+func samplingRateOption(record netflow.OptionsDataRecord) (uint32, bool) {
+	// one packet is sampled out of samplingPacketInterval + samplingPacketSpace packets
+	if interval, ok := uintValue(record.OptionsValues, fieldSamplingPacketInterval); ok && interval > 0 {
+		space, _ := uintValue(record.OptionsValues, fieldSamplingPacketSpace)
+		return uint32((interval + space) / interval), true
+	}
+	for _, fieldType := range []uint16{fieldSamplerRandomInterval, fieldSamplingInterval} {
+		if samplingRate, ok := uintValue(record.OptionsValues, fieldType); ok && samplingRate > 0 {
+			return uint32(samplingRate), true
+		}
+	}
+	return 0, false
+}
+
+// samplerID returns the ID of the sampler described by an options data record, 0 when the record doesn't
+// describe a sampler.
+func samplerID(record netflow.OptionsDataRecord) uint64 {
+	for _, fields := range [][]netflow.DataField{record.ScopesValues, record.OptionsValues} {
+		for _, fieldType := range []uint16{fieldSelectorID, fieldSamplerID} {
+			if id, ok := uintValue(fields, fieldType); ok {
+				return id
+			}
+		}
+	}
+	return 0
+}
+
+// fieldValue returns the value of the field of the given type, the enterprise specific fields being ignored.
+func fieldValue(fields []netflow.DataField, fieldType uint16) ([]byte, bool) {
+	for _, field := range fields {
+		if field.PenProvided || field.Type != fieldType {
+			continue
+		}
+		value, ok := field.Value.([]byte)
+		return value, ok
+	}
+	return nil, false
+}
+
+func uintValue(fields []netflow.DataField, fieldType uint16) (uint64, bool) {
+	value, ok := fieldValue(fields, fieldType)
+	if !ok {
+		return 0, false
+	}
+	var number uint64
+	if err := producer.DecodeUNumber(value, &number); err != nil {
+		return 0, false
+	}
+	return number, true
+}
@@ -0,0 +1,202 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package goflowlib
+
+import (
+	"testing"
+
+	"github.com/netsampler/goflow2/decoders/netflow"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/netflow/common"
+)
+
+func field(fieldType uint16, value ...byte) netflow.DataField {
+	return netflow.DataField{Type: fieldType, Value: value}
+}
+
+func optionsDataFlowSet(records ...netflow.OptionsDataRecord) []netflow.OptionsDataFlowSet {
+	return []netflow.OptionsDataFlowSet{{Records: records}}
+}
+
+func Test_exporterOptionsCache(t *testing.T) {
+	tests := []struct {
+		name         string
+		version      uint16
+		records      []netflow.OptionsDataRecord
+		flow         common.Flow
+		expectedFlow common.Flow
+	}{
+		{
+			name:    "netflow9 interfaces",
+			version: 9,
+			records: []netflow.OptionsDataRecord{
+				{
+					ScopesValues:  []netflow.DataField{field(nfv9ScopeInterface, 0, 0, 0, 1)},
+					OptionsValues: []netflow.DataField{field(fieldInterfaceName, 'e', 't', 'h', '0', 0, 0)},
+				},
+				{
+					ScopesValues:  []netflow.DataField{field(nfv9ScopeInterface, 0, 0, 0, 2)},
+					OptionsValues: []netflow.DataField{field(fieldInterfaceDescription, 'u', 'p', 'l', 'i', 'n', 'k')},
+				},
+				{
+					// the system scope doesn't describe an interface
+					ScopesValues:  []netflow.DataField{field(1, 0, 0, 0, 3)},
+					OptionsValues: []netflow.DataField{field(fieldInterfaceName, 'l', 'o')},
+				},
+			},
+			flow:         common.Flow{InputInterface: 1, OutputInterface: 2},
+			expectedFlow: common.Flow{InputInterface: 1, OutputInterface: 2, InputInterfaceName: "eth0", OutputInterfaceName: "uplink"},
+		},
+		{
+			name:    "ipfix interfaces",
+			version: 10,
+			records: []netflow.OptionsDataRecord{
+				{
+					ScopesValues: []netflow.DataField{field(fieldIngressInterface, 0, 0, 0, 1)},
+					OptionsValues: []netflow.DataField{
+						field(fieldInterfaceName, 'e', 't', 'h', '0'),
+						field(fieldInterfaceDescription, 'u', 'p', 'l', 'i', 'n', 'k'),
+					},
+				},
+				{
+					// the interface index is an option of the records scoped to the exporter
+					ScopesValues:  []netflow.DataField{field(149, 0, 0, 0, 1)},
+					OptionsValues: []netflow.DataField{field(fieldEgressInterface, 0, 2), field(fieldInterfaceName, 'e', 't', 'h', '1')},
+				},
+			},
+			flow:         common.Flow{InputInterface: 1, OutputInterface: 2},
+			expectedFlow: common.Flow{InputInterface: 1, OutputInterface: 2, InputInterfaceName: "eth0", OutputInterfaceName: "eth1"},
+		},
+		{
+			name:    "sampling interval",
+			version: 9,
+			records: []netflow.OptionsDataRecord{
+				{OptionsValues: []netflow.DataField{field(fieldSamplingInterval, 0, 0, 0, 100)}},
+			},
+			expectedFlow: common.Flow{SamplingRate: 100},
+		},
+		{
+			name:    "sampler random interval",
+			version: 9,
+			records: []netflow.OptionsDataRecord{
+				{OptionsValues: []netflow.DataField{field(fieldSamplerRandomInterval, 0, 0, 0, 50)}},
+			},
+			expectedFlow: common.Flow{SamplingRate: 50},
+		},
+		{
+			name:    "sampling packet interval and space",
+			version: 10,
+			records: []netflow.OptionsDataRecord{
+				{OptionsValues: []netflow.DataField{field(fieldSamplingPacketInterval, 0, 0, 0, 1), field(fieldSamplingPacketSpace, 0, 0, 0, 99)}},
+			},
+			expectedFlow: common.Flow{SamplingRate: 100},
+		},
+		{
+			name:    "flow sampling rate is kept",
+			version: 10,
+			records: []netflow.OptionsDataRecord{
+				{OptionsValues: []netflow.DataField{field(fieldSamplingInterval, 0, 0, 0, 100)}},
+			},
+			flow:         common.Flow{SamplingRate: 10},
+			expectedFlow: common.Flow{SamplingRate: 10},
+		},
+		{
+			name:    "enterprise fields are ignored",
+			version: 10,
+			records: []netflow.OptionsDataRecord{
+				{OptionsValues: []netflow.DataField{{PenProvided: true, Pen: 9, Type: fieldSamplingInterval, Value: []byte{0, 0, 0, 100}}}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := newExporterOptionsCache()
+			cache.update("127.0.0.1", 0, tt.version, optionsDataFlowSet(tt.records...))
+
+			flow := tt.flow
+			flow.ExporterAddr = []byte{127, 0, 0, 1}
+			cache.enrich(&flow)
+			expectedFlow := tt.expectedFlow
+			expectedFlow.ExporterAddr = flow.ExporterAddr
+			assert.Equal(t, expectedFlow, flow)
+
+			// the flows of the other exporters aren't enriched
+			otherFlow := tt.flow
+			otherFlow.ExporterAddr = []byte{127, 0, 0, 2}
+			cache.enrich(&otherFlow)
+			assert.Empty(t, otherFlow.InputInterfaceName)
+			assert.Equal(t, tt.flow.SamplingRate, otherFlow.SamplingRate)
+		})
+	}
+}
+
+func Test_exporterOptionsCache_update(t *testing.T) {
+	cache := newExporterOptionsCache()
+	cache.update("127.0.0.1", 0, 10, optionsDataFlowSet(netflow.OptionsDataRecord{
+		ScopesValues:  []netflow.DataField{field(fieldIngressInterface, 0, 0, 0, 1)},
+		OptionsValues: []netflow.DataField{field(fieldInterfaceName, 'e', 't', 'h', '0')},
+	}))
+	// the interfaces are renamed, and the other options are kept
+	cache.update("127.0.0.1", 0, 10, optionsDataFlowSet(
+		netflow.OptionsDataRecord{
+			ScopesValues:  []netflow.DataField{field(fieldIngressInterface, 0, 0, 0, 1)},
+			OptionsValues: []netflow.DataField{field(fieldInterfaceName, 'w', 'a', 'n')},
+		},
+		netflow.OptionsDataRecord{
+			OptionsValues: []netflow.DataField{field(fieldSamplingInterval, 0, 0, 0, 100)},
+		},
+	))
+	cache.update("127.0.0.1", 0, 10, nil)
+
+	flow := common.Flow{ExporterAddr: []byte{127, 0, 0, 1}, InputInterface: 1}
+	cache.enrich(&flow)
+	assert.Equal(t, "wan", flow.InputInterfaceName)
+	assert.Equal(t, uint64(100), flow.SamplingRate)
+}
+
+func Test_exporterOptionsCache_samplers(t *testing.T) {
+	cache := newExporterOptionsCache()
+	cache.update("127.0.0.1", 1, 9, optionsDataFlowSet(
+		netflow.OptionsDataRecord{
+			OptionsValues: []netflow.DataField{field(fieldSamplerID, 1), field(fieldSamplerRandomInterval, 0, 0, 0, 10)},
+		},
+		netflow.OptionsDataRecord{
+			OptionsValues: []netflow.DataField{field(fieldSamplerID, 2), field(fieldSamplerRandomInterval, 0, 0, 0, 20)},
+		},
+	))
+	cache.update("127.0.0.1", 2, 10, optionsDataFlowSet(
+		netflow.OptionsDataRecord{
+			ScopesValues:  []netflow.DataField{field(fieldSelectorID, 0, 0, 0, 1)},
+			OptionsValues: []netflow.DataField{field(fieldSamplingPacketInterval, 0, 0, 0, 1), field(fieldSamplingPacketSpace, 0, 0, 0, 29)},
+		},
+		netflow.OptionsDataRecord{
+			OptionsValues: []netflow.DataField{field(fieldSamplingInterval, 0, 0, 0, 40)},
+		},
+	))
+
+	tests := []struct {
+		name                 string
+		observationDomain    uint32
+		samplerID            uint64
+		expectedSamplingRate uint64
+	}{
+		{name: "first sampler", observationDomain: 1, samplerID: 1, expectedSamplingRate: 10},
+		{name: "second sampler", observationDomain: 1, samplerID: 2, expectedSamplingRate: 20},
+		{name: "unknown sampler", observationDomain: 1, samplerID: 3, expectedSamplingRate: 0},
+		{name: "no sampler", observationDomain: 1, expectedSamplingRate: 0},
+		{name: "sampler of another observation domain", observationDomain: 2, samplerID: 1, expectedSamplingRate: 30},
+		{name: "sampling rate not described for a sampler", observationDomain: 2, samplerID: 2, expectedSamplingRate: 40},
+		{name: "unknown observation domain", observationDomain: 3, samplerID: 1, expectedSamplingRate: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flow := common.Flow{ExporterAddr: []byte{127, 0, 0, 1}, ObservationDomain: tt.observationDomain, SamplerID: tt.samplerID}
+			cache.enrich(&flow)
+			assert.Equal(t, tt.expectedSamplingRate, flow.SamplingRate)
+		})
+	}
+}
@@ -17,10 +17,11 @@ type Exporter struct {
 
 // Endpoint contains source or destination endpoint details
 type Endpoint struct {
-	IP   string `json:"ip"`
-	Port string `json:"port"` // Port number can be zero/positive or `*` (ephemeral port)
-	Mac  string `json:"mac"`
-	Mask string `json:"mask"`
+	IP   string   `json:"ip"`
+	Port string   `json:"port"` // Port number can be zero/positive or `*` (ephemeral port)
+	Mac  string   `json:"mac"`
+	Mask string   `json:"mask"`
+	Tags []string `json:"tags,omitempty"`
 }
 
 // NextHop contains next hop details
@@ -31,6 +32,7 @@ type NextHop struct {
 // Interface contains interface details
 type Interface struct {
 	Index uint32 `json:"index"`
+	Name  string `json:"name,omitempty"`
 }
 
 // ObservationPoint contains ingress or egress observation point
@@ -57,5 +59,6 @@ type FlowPayload struct {
 	Egress       ObservationPoint `json:"egress"`
 	Host         string           `json:"host"`
 	TCPFlags     []string         `json:"tcp_flags,omitempty"`
+	Tags         []string         `json:"tags,omitempty"`
 	NextHop      NextHop          `json:"next_hop,omitempty"`
 }
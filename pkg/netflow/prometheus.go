@@ -0,0 +1,71 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package netflow
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+var promListenerWorkers = prometheus.NewDesc(
+	"datadog_netflow_listener_workers",
+	"Number of goflow workers of a running listener.",
+	[]string{"flow_type", "bind_host", "port", "namespace"}, nil)
+
+// listenersCollector exposes the running listeners as Prometheus metrics.
+type listenersCollector struct {
+	listeners []*netflowListener
+}
+
+// Describe implements prometheus.Collector.
+func (c *listenersCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- promListenerWorkers
+}
+
+// Collect implements prometheus.Collector.
+func (c *listenersCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, listener := range c.listeners {
+		cfg := listener.config
+		ch <- prometheus.MustNewConstMetric(promListenerWorkers, prometheus.GaugeValue, float64(cfg.Workers),
+			string(cfg.FlowType), cfg.BindHost, strconv.Itoa(int(cfg.Port)), cfg.Namespace)
+	}
+}
+
+// startPrometheusServer registers the given collectors along the goflow ones
+// in the default Prometheus registry and serves them on addr at /metrics.
+func startPrometheusServer(addr string, collectors ...prometheus.Collector) *http.Server {
+	for _, collector := range collectors {
+		if err := prometheus.Register(collector); err != nil {
+			log.Warnf("error registering netflow prometheus collector: %s", err)
+		}
+	}
+
+	serverMux := http.NewServeMux()
+	serverMux.Handle("/metrics", promhttp.Handler())
+	server := &http.Server{Addr: addr, Handler: serverMux}
+	go func() {
+		err := server.ListenAndServe()
+		if err != nil && err != http.ErrServerClosed {
+			log.Errorf("error starting prometheus server `%s`: %s", addr, err)
+		}
+	}()
+	return server
+}
+
+// stopPrometheusServer stops serving the Prometheus metrics and unregisters the given collectors.
+func stopPrometheusServer(server *http.Server, collectors ...prometheus.Collector) {
+	if err := server.Close(); err != nil {
+		log.Warnf("error stopping prometheus server `%s`: %s", server.Addr, err)
+	}
+	for _, collector := range collectors {
+		prometheus.Unregister(collector)
+	}
+}
@@ -0,0 +1,188 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+// Package replay replays the NetFlow, IPFIX and sFlow packets of pcap captures into the flow listeners, to validate
+// the configuration and the mappings of the agent against the traffic of real devices.
+package replay
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcapgo"
+
+	"github.com/DataDog/datadog-agent/pkg/netflow/common"
+	"github.com/DataDog/datadog-agent/pkg/netflow/config"
+)
+
+// pcapngMagic is the block type of the section header block starting the pcapng files
+var pcapngMagic = []byte{0x0a, 0x0d, 0x0d, 0x0a}
+
+// Packet is a flow packet read from a capture
+type Packet struct {
+	// Timestamp is the capture time of the packet
+	Timestamp time.Time
+	// DstPort is the UDP port the packet was sent to
+	DstPort uint16
+	// FlowType is the protocol of the packet, detected from its header
+	FlowType common.FlowType
+	// Payload is the UDP payload of the packet
+	Payload []byte
+}
+
+type packetReader interface {
+	ReadPacketData() ([]byte, gopacket.CaptureInfo, error)
+	LinkType() layers.LinkType
+}
+
+// ReadPackets reads the UDP packets of a pcap or pcapng capture. The packets that aren't UDP are skipped.
+func ReadPackets(r io.Reader) ([]Packet, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(len(pcapngMagic))
+	if err != nil {
+		return nil, fmt.Errorf("could not read the capture header: %w", err)
+	}
+
+	var reader packetReader
+	if bytes.Equal(magic, pcapngMagic) {
+		reader, err = pcapgo.NewNgReader(br, pcapgo.DefaultNgReaderOptions)
+	} else {
+		reader, err = pcapgo.NewReader(br)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read the capture: %w", err)
+	}
+
+	var packets []Packet
+	for {
+		data, ci, err := reader.ReadPacketData()
+		if errors.Is(err, io.EOF) {
+			return packets, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not read packet %d of the capture: %w", len(packets)+1, err)
+		}
+
+		packet := gopacket.NewPacket(data, reader.LinkType(), gopacket.DecodeOptions{Lazy: true, NoCopy: true})
+		udp, ok := packet.Layer(layers.LayerTypeUDP).(*layers.UDP)
+		if !ok || len(udp.Payload) == 0 {
+			continue
+		}
+		packets = append(packets, Packet{
+			Timestamp: ci.Timestamp,
+			DstPort:   uint16(udp.DstPort),
+			FlowType:  DetectFlowType(udp.Payload),
+			Payload:   udp.Payload,
+		})
+	}
+}
+
+// DetectFlowType returns the flow type of a packet from the version of its header
+func DetectFlowType(payload []byte) common.FlowType {
+	if len(payload) >= 4 && binary.BigEndian.Uint32(payload) == 5 {
+		return common.TypeSFlow5
+	}
+	if len(payload) >= 2 {
+		switch binary.BigEndian.Uint16(payload) {
+		case 5:
+			return common.TypeNetFlow5
+		case 9:
+			return common.TypeNetFlow9
+		case 10:
+			return common.TypeIPFIX
+		}
+	}
+	return common.TypeUnknown
+}
+
+// Replayer sends captured packets, preserving the intervals between them
+type Replayer struct {
+	// Speed is the replay speed relative to the capture, e.g. 2 replays the packets twice as fast as they were
+	// captured. The packets are sent as fast as possible when it isn't positive.
+	Speed float64
+	// Send sends a packet to its listener
+	Send func(Packet) error
+
+	// sleep waits for the given duration, or until the context is done
+	sleep func(ctx context.Context, d time.Duration) error
+	now   func() time.Time
+}
+
+// NewReplayer returns a replayer sending the packets with the given function at the given speed
+func NewReplayer(speed float64, send func(Packet) error) *Replayer {
+	return &Replayer{
+		Speed: speed,
+		Send:  send,
+		sleep: sleepContext,
+		now:   time.Now,
+	}
+}
+
+// Replay sends the packets, and returns the number of packets sent
+func (r *Replayer) Replay(ctx context.Context, packets []Packet) (int, error) {
+	if len(packets) == 0 {
+		return 0, nil
+	}
+
+	start := r.now()
+	first := packets[0].Timestamp
+	for i, packet := range packets {
+		if r.Speed > 0 {
+			offset := time.Duration(float64(packet.Timestamp.Sub(first)) / r.Speed)
+			if wait := offset - r.now().Sub(start); wait > 0 {
+				if err := r.sleep(ctx, wait); err != nil {
+					return i, err
+				}
+			}
+		} else if err := ctx.Err(); err != nil {
+			return i, err
+		}
+
+		if err := r.Send(packet); err != nil {
+			return i, fmt.Errorf("could not send packet %d: %w", i+1, err)
+		}
+	}
+	return len(packets), nil
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// compatibleFlowTypes are the flow types of the listeners able to decode the packets of each flow type, the
+// NetFlow v9 and IPFIX listeners decoding both protocols
+var compatibleFlowTypes = map[common.FlowType][]common.FlowType{
+	common.TypeNetFlow5: {common.TypeNetFlow5},
+	common.TypeNetFlow9: {common.TypeNetFlow9, common.TypeIPFIX},
+	common.TypeIPFIX:    {common.TypeIPFIX, common.TypeNetFlow9},
+	common.TypeSFlow5:   {common.TypeSFlow5},
+}
+
+// ListenerPort returns the port of the first listener able to decode the packets of the given flow type
+func ListenerPort(listeners []config.ListenerConfig, flowType common.FlowType) (uint16, bool) {
+	for _, compatible := range compatibleFlowTypes[flowType] {
+		for _, listener := range listeners {
+			if listener.FlowType == compatible {
+				return listener.Port, true
+			}
+		}
+	}
+	return 0, false
+}
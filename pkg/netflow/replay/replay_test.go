@@ -0,0 +1,159 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+package replay
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/netflow/common"
+	"github.com/DataDog/datadog-agent/pkg/netflow/config"
+)
+
+func readPcap(t *testing.T, name string) []Packet {
+	f, err := os.Open("../testutil/pcap_recordings/" + name)
+	require.NoError(t, err)
+	defer f.Close()
+
+	packets, err := ReadPackets(f)
+	require.NoError(t, err)
+	return packets
+}
+
+func TestReadPackets(t *testing.T) {
+	for _, tt := range []struct {
+		file     string
+		flowType common.FlowType
+	}{
+		{"netflow5.pcapng", common.TypeNetFlow5},
+		{"netflow9.pcapng", common.TypeNetFlow9},
+		{"sflow.pcapng", common.TypeSFlow5},
+	} {
+		t.Run(tt.file, func(t *testing.T) {
+			packets := readPcap(t, tt.file)
+			require.NotEmpty(t, packets)
+			for _, packet := range packets {
+				assert.Equal(t, tt.flowType, packet.FlowType)
+				assert.NotZero(t, packet.DstPort)
+				assert.NotEmpty(t, packet.Payload)
+				assert.False(t, packet.Timestamp.IsZero())
+			}
+		})
+	}
+}
+
+func TestReadPacketsInvalid(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "capture")
+	require.NoError(t, err)
+	_, err = f.WriteString("not a capture")
+	require.NoError(t, err)
+	_, err = f.Seek(0, 0)
+	require.NoError(t, err)
+
+	_, err = ReadPackets(f)
+	assert.Error(t, err)
+}
+
+func TestDetectFlowType(t *testing.T) {
+	assert.Equal(t, common.TypeNetFlow5, DetectFlowType([]byte{0x00, 0x05, 0x00, 0x01}))
+	assert.Equal(t, common.TypeNetFlow9, DetectFlowType([]byte{0x00, 0x09, 0x00, 0x01}))
+	assert.Equal(t, common.TypeIPFIX, DetectFlowType([]byte{0x00, 0x0a, 0x00, 0x30}))
+	assert.Equal(t, common.TypeSFlow5, DetectFlowType([]byte{0x00, 0x00, 0x00, 0x05, 0x00, 0x00, 0x00, 0x01}))
+	assert.Equal(t, common.TypeUnknown, DetectFlowType([]byte{0x00, 0x01}))
+	assert.Equal(t, common.TypeUnknown, DetectFlowType(nil))
+}
+
+func TestReplay(t *testing.T) {
+	start := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+	packets := []Packet{
+		{Timestamp: start, Payload: []byte{1}},
+		{Timestamp: start.Add(2 * time.Second), Payload: []byte{2}},
+		{Timestamp: start.Add(6 * time.Second), Payload: []byte{3}},
+	}
+
+	for _, tt := range []struct {
+		name          string
+		speed         float64
+		expectedSleep []time.Duration
+	}{
+		{"real time", 1, []time.Duration{2 * time.Second, 4 * time.Second}},
+		{"twice as fast", 2, []time.Duration{time.Second, 2 * time.Second}},
+		{"as fast as possible", 0, nil},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var sent []byte
+			r := NewReplayer(tt.speed, func(p Packet) error {
+				sent = append(sent, p.Payload...)
+				return nil
+			})
+
+			now := start
+			var slept []time.Duration
+			r.now = func() time.Time { return now }
+			r.sleep = func(_ context.Context, d time.Duration) error {
+				slept = append(slept, d)
+				now = now.Add(d)
+				return nil
+			}
+
+			n, err := r.Replay(context.Background(), packets)
+			require.NoError(t, err)
+			assert.Equal(t, 3, n)
+			assert.Equal(t, []byte{1, 2, 3}, sent)
+			assert.Equal(t, tt.expectedSleep, slept)
+		})
+	}
+}
+
+func TestReplayCanceled(t *testing.T) {
+	start := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+	packets := []Packet{
+		{Timestamp: start},
+		{Timestamp: start.Add(time.Hour)},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sent := 0
+	r := NewReplayer(1, func(p Packet) error {
+		sent++
+		cancel()
+		return nil
+	})
+
+	n, err := r.Replay(ctx, packets)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, 1, sent)
+}
+
+func TestListenerPort(t *testing.T) {
+	listeners := []config.ListenerConfig{
+		{FlowType: common.TypeIPFIX, Port: 4739},
+		{FlowType: common.TypeNetFlow5, Port: 2055},
+		{FlowType: common.TypeSFlow5, Port: 6343},
+	}
+
+	for _, tt := range []struct {
+		flowType common.FlowType
+		port     uint16
+		found    bool
+	}{
+		{common.TypeNetFlow5, 2055, true},
+		{common.TypeNetFlow9, 4739, true},
+		{common.TypeIPFIX, 4739, true},
+		{common.TypeSFlow5, 6343, true},
+		{common.TypeUnknown, 0, false},
+	} {
+		port, found := ListenerPort(listeners, tt.flowType)
+		assert.Equal(t, tt.port, port, tt.flowType)
+		assert.Equal(t, tt.found, found, tt.flowType)
+	}
+}
@@ -7,10 +7,12 @@ package netflow
 
 import (
 	"context"
+	"errors"
 	"net/http"
+	"sync"
 	"time"
 
-	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/client_golang/prometheus"
 
 	"github.com/DataDog/datadog-agent/pkg/aggregator"
 	coreconfig "github.com/DataDog/datadog-agent/pkg/config"
@@ -22,7 +24,11 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/netflow/flowaggregator"
 )
 
-var serverInstance *Server
+var (
+	serverInstance *Server
+	// serverInstanceMu guards serverInstance, which the agent API reads concurrently to the start and stop
+	serverInstanceMu sync.RWMutex
+)
 
 // Server manages netflow listeners.
 type Server struct {
@@ -30,6 +36,9 @@ type Server struct {
 	config    *config.NetflowConfig
 	listeners []*netflowListener
 	flowAgg   *flowaggregator.FlowAggregator
+
+	promServer     *http.Server
+	promCollectors []prometheus.Collector
 }
 
 // NewNetflowServer configures and returns a running SNMP traps server.
@@ -50,17 +59,6 @@ func NewNetflowServer(sender aggregator.Sender, epForwarder epforwarder.EventPla
 	flowAgg := flowaggregator.NewFlowAggregator(sender, epForwarder, mainConfig, hostnameDetected)
 	go flowAgg.Start()
 
-	if mainConfig.PrometheusListenerEnabled {
-		go func() {
-			serverMux := http.NewServeMux()
-			serverMux.Handle("/metrics", promhttp.Handler())
-			err := http.ListenAndServe(mainConfig.PrometheusListenerAddress, serverMux)
-			if err != nil {
-				log.Errorf("error starting prometheus server `%s`", mainConfig.PrometheusListenerAddress)
-			}
-		}()
-	}
-
 	log.Debugf("NetFlow Server configs (aggregator_buffer_size=%d, aggregator_flush_interval=%d, aggregator_flow_context_ttl=%d)", mainConfig.AggregatorBufferSize, mainConfig.AggregatorFlushInterval, mainConfig.AggregatorFlowContextTTL)
 	for _, listenerConfig := range mainConfig.Listeners {
 		log.Infof("Starting Netflow listener for flow type %s on %s", listenerConfig.FlowType, listenerConfig.Addr())
@@ -72,17 +70,31 @@ func NewNetflowServer(sender aggregator.Sender, epForwarder epforwarder.EventPla
 		listeners = append(listeners, listener)
 	}
 
-	return &Server{
+	server := &Server{
 		listeners: listeners,
 		config:    mainConfig,
 		flowAgg:   flowAgg,
-	}, nil
+	}
+
+	if mainConfig.PrometheusListenerEnabled {
+		server.promCollectors = []prometheus.Collector{
+			flowAgg.PrometheusCollector(),
+			&listenersCollector{listeners: listeners},
+		}
+		server.promServer = startPrometheusServer(mainConfig.PrometheusListenerAddress, server.promCollectors...)
+	}
+
+	return server, nil
 }
 
 // Stop stops the Server.
 func (s *Server) stop() {
 	log.Infof("Stop NetFlow Server")
 
+	if s.promServer != nil {
+		stopPrometheusServer(s.promServer, s.promCollectors...)
+	}
+
 	s.flowAgg.Stop()
 
 	for _, listener := range s.listeners {
@@ -118,18 +130,32 @@ func StartServer(demux aggregator.DemultiplexerWithAggregator) error {
 	if err != nil {
 		return err
 	}
+	serverInstanceMu.Lock()
 	serverInstance = server
+	serverInstanceMu.Unlock()
 	return nil
 }
 
 // StopServer stops the netflow server, if it is running.
 func StopServer() {
+	serverInstanceMu.Lock()
+	defer serverInstanceMu.Unlock()
 	if serverInstance != nil {
 		serverInstance.stop()
 		serverInstance = nil
 	}
 }
 
+// GetExportersStatus returns the health of the flow exporters known by the running NetFlow server.
+func GetExportersStatus() ([]flowaggregator.ExporterStatus, error) {
+	serverInstanceMu.RLock()
+	defer serverInstanceMu.RUnlock()
+	if serverInstance == nil {
+		return nil, errors.New("NetFlow server is not running")
+	}
+	return serverInstance.flowAgg.ExportersStatus(), nil
+}
+
 // IsEnabled returns whether NetFlow collection is enabled in the Agent configuration.
 func IsEnabled() bool {
 	return coreconfig.Datadog.GetBool("network_devices.netflow.enabled")
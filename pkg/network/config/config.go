@@ -23,6 +23,7 @@ const (
 	dsNS   = "data_streams_config"
 	evNS   = "event_monitoring_config"
 	smjtNS = smNS + ".java_tls"
+	smcaNS = smNS + ".cgroup_attach"
 
 	defaultUDPTimeoutSeconds       = 30
 	defaultUDPStreamTimeoutSeconds = 120
@@ -86,9 +87,17 @@ type Config struct {
 	// EnableHTTP2Monitoring specifies whether the tracer should monitor HTTP2 traffic
 	EnableHTTP2Monitoring bool
 
+	// EnableConnectionCorrelation specifies whether tracer libraries can retrieve the correlation ID of
+	// their connections, to link their spans to the connection records
+	EnableConnectionCorrelation bool
+
 	// EnableKafkaMonitoring specifies whether the tracer should monitor Kafka traffic
 	EnableKafkaMonitoring bool
 
+	// EnableDNSMonitoring specifies whether the tracer should monitor the DNS over UDP traffic, to report
+	// the latency of the queries by query type
+	EnableDNSMonitoring bool
+
 	// EnableHTTPSMonitoring specifies whether the tracer should monitor HTTPS traffic
 	// Supported libraries: OpenSSL
 	EnableHTTPSMonitoring bool
@@ -101,6 +110,66 @@ type Config struct {
 	// traffic done through Java's TLS implementation
 	EnableJavaTLSSupport bool
 
+	// EnableHTTP3Monitoring specifies whether the tracer should monitor HTTP/3
+	// traffic done through userspace QUIC libraries
+	EnableHTTP3Monitoring bool
+
+	// EnableIstioMonitoring specifies whether the tracer should monitor HTTPS
+	// traffic encrypted by the Envoy proxies injected by Istio, which link BoringSSL statically
+	EnableIstioMonitoring bool
+
+	// EnableNodeJSMonitoring specifies whether the tracer should monitor HTTPS
+	// traffic encrypted by Node.js, which links OpenSSL statically
+	EnableNodeJSMonitoring bool
+
+	// EnableUSMCgroupAttach restricts the USM monitoring to the traffic of the workloads whose cgroup matches
+	// USMCgroupAttachPaths, by attaching cgroup_skb programs to their cgroups. Requires cgroup v2.
+	EnableUSMCgroupAttach bool
+
+	// USMCgroupAttachPaths are the glob patterns of the cgroups monitored in the cgroup attach mode, relative to
+	// the root of the cgroup v2 hierarchy, such as "kubepods.slice/*/kubepods-*-pod<pod UID>.slice"
+	USMCgroupAttachPaths []string
+
+	// HTTPEventsBatchSize is the number of HTTP events handed at once to the HTTP monitoring. When set to 0, the
+	// events are processed individually
+	HTTPEventsBatchSize int
+
+	// HTTPEventsFlushInterval is the maximum time an HTTP event is held before being processed when the HTTP
+	// events are batched
+	HTTPEventsFlushInterval time.Duration
+
+	// EnableHTTPSampling enables the sampling of the HTTP transactions in eBPF when they are dropped on their way to
+	// userspace, the counts of the sampled transactions being scaled back up by the HTTP monitoring
+	EnableHTTPSampling bool
+
+	// HTTPSamplingDropThreshold is the ratio of HTTP transactions dropped on their way to userspace above which the
+	// HTTP transactions are sampled
+	HTTPSamplingDropThreshold float64
+
+	// EnableHTTPPathQuantization enables the quantization of the paths of the HTTP transactions, replacing the path
+	// segments holding identifiers, such as numbers and UUIDs, with a placeholder before the transactions are aggregated
+	EnableHTTPPathQuantization bool
+
+	// HTTPPathQuantizationRules are the user-defined rules quantizing the path segments, applied before the
+	// built-in ones
+	HTTPPathQuantizationRules []*ReplaceRule
+
+	// MaxSharedLibrariesRegistrations is the maximum number of shared libraries and binaries hooked at once by each
+	// USM program. The libraries opened beyond it aren't hooked until others are released.
+	MaxSharedLibrariesRegistrations int
+
+	// MaxSharedLibrariesBlocklistEntries is the maximum number of shared libraries and binaries which couldn't be
+	// hooked remembered by each USM program, the least recently blocked ones being evicted beyond it
+	MaxSharedLibrariesBlocklistEntries int
+
+	// SharedLibrariesBlocklistTTL is the time after which the hooking of a shared library or binary which couldn't be
+	// hooked is attempted again
+	SharedLibrariesBlocklistTTL time.Duration
+
+	// EnableInFlightTransactionsDebug enables the /debug/usm_transactions endpoint, returning the HTTP and HTTP/2
+	// transactions held by the in-flight eBPF maps
+	EnableInFlightTransactionsDebug bool
+
 	// MaxTrackedHTTPConnections max number of http(s) flows that will be concurrently tracked.
 	// value is currently Windows only
 	MaxTrackedHTTPConnections int64
@@ -168,6 +237,10 @@ type Config struct {
 	// get flushed on every client request (default 30s check interval)
 	MaxKafkaStatsBuffered int
 
+	// MaxUSMDNSStatsBuffered represents the maximum number of DNS stats of the DNS monitoring we'll buffer in memory.
+	// These stats get flushed on every client request (default 30s check interval)
+	MaxUSMDNSStatsBuffered int
+
 	// MaxConnectionsStateBuffered represents the maximum number of state objects that we'll store in memory. These state objects store
 	// the stats for a connection so we can accurately determine traffic change between client requests.
 	MaxConnectionsStateBuffered int
@@ -206,6 +279,11 @@ type Config struct {
 	// can't load the ebpf-based conntracker
 	AllowNetlinkConntrackerFallback bool
 
+	// ConntrackPartialTrackingCIDRs restricts the NAT translations tracked by the conntracker to the
+	// connections whose destination belongs to one of these CIDRs. Partial tracking is only supported
+	// by the netlink conntracker, which is used instead of the ebpf-based one when it is enabled.
+	ConntrackPartialTrackingCIDRs []string
+
 	// ClosedChannelSize specifies the size for closed channel for the tracer
 	ClosedChannelSize int
 
@@ -224,6 +302,27 @@ type Config struct {
 	// EnableGatewayLookup enables looking up gateway information for connection destinations
 	EnableGatewayLookup bool
 
+	// CollectTCPListenOverflows enables counting SYN backlog and accept queue overflows of listening TCP sockets.
+	// Only supported by the runtime compiled and CO-RE tracers.
+	CollectTCPListenOverflows bool
+
+	// CollectTCPFailedConnections enables counting failed outgoing TCP connection attempts by destination.
+	// Only supported by the runtime compiled and CO-RE tracers.
+	CollectTCPFailedConnections bool
+
+	// EnableServiceDependencies enables the /service_dependencies endpoint, which rolls the connections up
+	// into the dependencies between the services of the host after NAT.
+	EnableServiceDependencies bool
+
+	// ServiceDependenciesRollupThreshold is the number of connections above which the connections payloads
+	// are rolled up, by merging the connections of a process with the same server. 0 disables the rollup.
+	// Only used when EnableServiceDependencies is set.
+	ServiceDependenciesRollupThreshold int
+
+	// EnableFentry enables the use of fentry/fexit programs instead of kprobes on kernels supporting them
+	// (5.5+ with BTF), falling back to kprobes otherwise.
+	EnableFentry bool
+
 	// RecordedQueryTypes enables specific DNS query types to be recorded
 	RecordedQueryTypes []string
 
@@ -240,7 +339,7 @@ type Config struct {
 	// for things like creating netlink sockets for conntrack updates, etc.
 	EnableRootNetNs bool
 
-	// HTTPMapCleanerInterval is the interval to run the cleaner function.
+	// HTTPMapCleanerInterval is the interval to run the cleaner function of the HTTP, HTTP/2 and SSL maps.
 	HTTPMapCleanerInterval time.Duration
 
 	// HTTPIdleConnectionTTL is the time an idle connection counted as "inactive" and should be deleted.
@@ -307,6 +406,8 @@ func New() *Config {
 		MaxHTTPStatsBuffered:  cfg.GetInt(join(netNS, "max_http_stats_buffered")),
 		MaxKafkaStatsBuffered: cfg.GetInt(join(smNS, "max_kafka_stats_buffered")),
 
+		EnableConnectionCorrelation: cfg.GetBool(join(smNS, "enable_connection_correlation")),
+
 		MaxTrackedHTTPConnections: cfg.GetInt64(join(netNS, "max_tracked_http_connections")),
 		HTTPNotificationThreshold: cfg.GetInt64(join(netNS, "http_notification_threshold")),
 		HTTPMaxRequestFragment:    cfg.GetInt64(join(netNS, "http_max_request_fragment")),
@@ -320,9 +421,18 @@ func New() *Config {
 		ConntrackInitTimeout:            cfg.GetDuration(join(netNS, "conntrack_init_timeout")),
 		EnableEbpfConntracker:           true,
 		AllowNetlinkConntrackerFallback: cfg.GetBool(join(netNS, "allow_netlink_conntracker_fallback")),
+		ConntrackPartialTrackingCIDRs:   cfg.GetStringSlice(join(netNS, "conntrack_partial_tracking_cidrs")),
 
 		EnableGatewayLookup: cfg.GetBool(join(netNS, "enable_gateway_lookup")),
 
+		CollectTCPListenOverflows:   cfg.GetBool(join(netNS, "collect_tcp_listen_overflows")),
+		CollectTCPFailedConnections: cfg.GetBool(join(netNS, "collect_tcp_failed_connections")),
+
+		EnableServiceDependencies:          cfg.GetBool(join(netNS, "enable_service_dependencies")),
+		ServiceDependenciesRollupThreshold: cfg.GetInt(join(netNS, "service_dependencies_rollup_threshold")),
+
+		EnableFentry: cfg.GetBool(join(netNS, "enable_fentry")),
+
 		EnableMonotonicCount: cfg.GetBool(join(spNS, "windows.enable_monotonic_count")),
 
 		RecordedQueryTypes: cfg.GetStringSlice(join(netNS, "dns_recorded_query_types")),
@@ -342,7 +452,25 @@ func New() *Config {
 		JavaAgentAllowRegex:         cfg.GetString(join(smjtNS, "allow_regex")),
 		JavaAgentBlockRegex:         cfg.GetString(join(smjtNS, "block_regex")),
 		EnableGoTLSSupport:          cfg.GetBool(join(smNS, "enable_go_tls_support")),
+		EnableHTTP3Monitoring:       cfg.GetBool(join(smNS, "enable_http3_monitoring")),
+		EnableDNSMonitoring:         cfg.GetBool(join(smNS, "enable_dns_monitoring")),
+		EnableUSMCgroupAttach:       cfg.GetBool(join(smcaNS, "enabled")),
+		USMCgroupAttachPaths:        cfg.GetStringSlice(join(smcaNS, "cgroup_paths")),
+		MaxUSMDNSStatsBuffered:      cfg.GetInt(join(smNS, "max_dns_stats_buffered")),
+		EnableIstioMonitoring:       cfg.GetBool(join(smNS, "enable_istio_monitoring")),
+		EnableNodeJSMonitoring:      cfg.GetBool(join(smNS, "enable_nodejs_monitoring")),
 		EnableHTTPStatsByStatusCode: cfg.GetBool(join(smNS, "enable_http_stats_by_status_code")),
+		HTTPEventsBatchSize:         cfg.GetInt(join(smNS, "http_events_batch_size")),
+		HTTPEventsFlushInterval:     time.Duration(cfg.GetInt(join(smNS, "http_events_flush_interval_ms"))) * time.Millisecond,
+		EnableHTTPSampling:          cfg.GetBool(join(smNS, "enable_http_sampling")),
+		HTTPSamplingDropThreshold:   cfg.GetFloat64(join(smNS, "http_sampling_drop_threshold")),
+		EnableHTTPPathQuantization:  cfg.GetBool(join(smNS, "enable_http_path_quantization")),
+
+		MaxSharedLibrariesRegistrations:    cfg.GetInt(join(smNS, "max_shared_libraries_registrations")),
+		MaxSharedLibrariesBlocklistEntries: cfg.GetInt(join(smNS, "max_shared_libraries_blocklist_entries")),
+		SharedLibrariesBlocklistTTL:        time.Duration(cfg.GetInt(join(smNS, "shared_libraries_blocklist_ttl_in_s"))) * time.Second,
+
+		EnableInFlightTransactionsDebug: cfg.GetBool(join(smNS, "enable_in_flight_transactions_debug")),
 	}
 
 	if cfg.GetBool(join(spNS, "disable_tcp")) {
@@ -392,6 +520,13 @@ func New() *Config {
 	} else {
 		c.HTTPReplaceRules = rr
 	}
+	httpQuantizationRulesKey := join(smNS, "http_path_quantization_rules")
+	qr, err := parseReplaceRules(cfg, httpQuantizationRulesKey)
+	if err != nil {
+		log.Errorf("error parsing %q: %v", httpQuantizationRulesKey, err)
+	} else {
+		c.HTTPPathQuantizationRules = qr
+	}
 
 	if c.OffsetGuessThreshold > maxOffsetThreshold {
 		log.Warn("offset_guess_threshold exceeds maximum of 3000. Setting it to the default of 400")
@@ -12,6 +12,7 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -203,6 +204,362 @@ func TestEnableHTTP2Monitoring(t *testing.T) {
 	})
 }
 
+func TestEnableHTTP3Monitoring(t *testing.T) {
+	t.Run("via YAML", func(t *testing.T) {
+		newConfig(t)
+
+		_, err := sysconfig.New("./testdata/TestDDAgentConfigYamlAndSystemProbeConfig-EnableHTTP3.yaml")
+		require.NoError(t, err)
+		cfg := New()
+
+		assert.True(t, cfg.EnableHTTP3Monitoring)
+	})
+
+	t.Run("via ENV variable", func(t *testing.T) {
+		newConfig(t)
+
+		t.Setenv("DD_SERVICE_MONITORING_CONFIG_ENABLE_HTTP3_MONITORING", "true")
+		_, err := sysconfig.New("")
+		require.NoError(t, err)
+		cfg := New()
+
+		assert.True(t, cfg.EnableHTTP3Monitoring)
+	})
+}
+
+func TestEnableDNSMonitoring(t *testing.T) {
+	t.Run("via YAML", func(t *testing.T) {
+		newConfig(t)
+
+		_, err := sysconfig.New("./testdata/TestDDAgentConfigYamlAndSystemProbeConfig-EnableDNS.yaml")
+		require.NoError(t, err)
+		cfg := New()
+
+		assert.True(t, cfg.EnableDNSMonitoring)
+	})
+
+	t.Run("via ENV variable", func(t *testing.T) {
+		newConfig(t)
+
+		t.Setenv("DD_SERVICE_MONITORING_CONFIG_ENABLE_DNS_MONITORING", "true")
+		_, err := sysconfig.New("")
+		require.NoError(t, err)
+		cfg := New()
+
+		assert.True(t, cfg.EnableDNSMonitoring)
+	})
+}
+
+func TestUSMCgroupAttach(t *testing.T) {
+	t.Run("via YAML", func(t *testing.T) {
+		newConfig(t)
+
+		_, err := sysconfig.New("./testdata/TestDDAgentConfigYamlAndSystemProbeConfig-USMCgroupAttach.yaml")
+		require.NoError(t, err)
+		cfg := New()
+
+		assert.True(t, cfg.EnableUSMCgroupAttach)
+		assert.Equal(t, []string{"kubepods.slice/*/kubepods-*-pod1234.slice", "system.slice/nginx.service"}, cfg.USMCgroupAttachPaths)
+	})
+
+	t.Run("via ENV variable", func(t *testing.T) {
+		newConfig(t)
+
+		t.Setenv("DD_SERVICE_MONITORING_CONFIG_CGROUP_ATTACH_ENABLED", "true")
+		t.Setenv("DD_SERVICE_MONITORING_CONFIG_CGROUP_ATTACH_CGROUP_PATHS", "system.slice/nginx.service")
+		_, err := sysconfig.New("")
+		require.NoError(t, err)
+		cfg := New()
+
+		assert.True(t, cfg.EnableUSMCgroupAttach)
+		assert.Equal(t, []string{"system.slice/nginx.service"}, cfg.USMCgroupAttachPaths)
+	})
+
+	t.Run("default", func(t *testing.T) {
+		newConfig(t)
+
+		_, err := sysconfig.New("")
+		require.NoError(t, err)
+		cfg := New()
+
+		assert.False(t, cfg.EnableUSMCgroupAttach)
+		assert.Empty(t, cfg.USMCgroupAttachPaths)
+	})
+}
+
+func TestHTTPEventsBatching(t *testing.T) {
+	t.Run("via YAML", func(t *testing.T) {
+		newConfig(t)
+
+		_, err := sysconfig.New("./testdata/TestDDSystemProbeConfig-HTTPEventsBatching.yaml")
+		require.NoError(t, err)
+		cfg := New()
+
+		assert.Equal(t, 256, cfg.HTTPEventsBatchSize)
+		assert.Equal(t, 200*time.Millisecond, cfg.HTTPEventsFlushInterval)
+	})
+
+	t.Run("via ENV variable", func(t *testing.T) {
+		newConfig(t)
+
+		t.Setenv("DD_SERVICE_MONITORING_CONFIG_HTTP_EVENTS_BATCH_SIZE", "128")
+		t.Setenv("DD_SERVICE_MONITORING_CONFIG_HTTP_EVENTS_FLUSH_INTERVAL_MS", "500")
+		_, err := sysconfig.New("")
+		require.NoError(t, err)
+		cfg := New()
+
+		assert.Equal(t, 128, cfg.HTTPEventsBatchSize)
+		assert.Equal(t, 500*time.Millisecond, cfg.HTTPEventsFlushInterval)
+	})
+
+	t.Run("default", func(t *testing.T) {
+		newConfig(t)
+
+		_, err := sysconfig.New("")
+		require.NoError(t, err)
+		cfg := New()
+
+		assert.Zero(t, cfg.HTTPEventsBatchSize)
+		assert.Equal(t, time.Second, cfg.HTTPEventsFlushInterval)
+	})
+}
+
+func TestHTTPSampling(t *testing.T) {
+	t.Run("via YAML", func(t *testing.T) {
+		newConfig(t)
+
+		_, err := sysconfig.New("./testdata/TestDDSystemProbeConfig-HTTPSampling.yaml")
+		require.NoError(t, err)
+		cfg := New()
+
+		assert.True(t, cfg.EnableHTTPSampling)
+		assert.Equal(t, 0.05, cfg.HTTPSamplingDropThreshold)
+	})
+
+	t.Run("via ENV variable", func(t *testing.T) {
+		newConfig(t)
+
+		t.Setenv("DD_SERVICE_MONITORING_CONFIG_ENABLE_HTTP_SAMPLING", "true")
+		t.Setenv("DD_SERVICE_MONITORING_CONFIG_HTTP_SAMPLING_DROP_THRESHOLD", "0.1")
+		_, err := sysconfig.New("")
+		require.NoError(t, err)
+		cfg := New()
+
+		assert.True(t, cfg.EnableHTTPSampling)
+		assert.Equal(t, 0.1, cfg.HTTPSamplingDropThreshold)
+	})
+
+	t.Run("default", func(t *testing.T) {
+		newConfig(t)
+
+		_, err := sysconfig.New("")
+		require.NoError(t, err)
+		cfg := New()
+
+		assert.False(t, cfg.EnableHTTPSampling)
+		assert.Equal(t, 0.01, cfg.HTTPSamplingDropThreshold)
+	})
+}
+
+func TestHTTPPathQuantization(t *testing.T) {
+	expected := []*ReplaceRule{
+		{
+			Pattern: "^[a-z]{2}-[A-Z]{2}$",
+			Re:      regexp.MustCompile("^[a-z]{2}-[A-Z]{2}$"),
+			Repl:    "{locale}",
+		},
+		{
+			Pattern: "^user-",
+			Re:      regexp.MustCompile("^user-"),
+		},
+	}
+
+	t.Run("via YAML", func(t *testing.T) {
+		newConfig(t)
+
+		_, err := sysconfig.New("./testdata/TestDDSystemProbeConfig-HTTPPathQuantization.yaml")
+		require.NoError(t, err)
+		cfg := New()
+
+		assert.True(t, cfg.EnableHTTPPathQuantization)
+		assert.Equal(t, expected, cfg.HTTPPathQuantizationRules)
+	})
+
+	t.Run("via ENV variable", func(t *testing.T) {
+		newConfig(t)
+
+		t.Setenv("DD_SERVICE_MONITORING_CONFIG_ENABLE_HTTP_PATH_QUANTIZATION", "true")
+		t.Setenv("DD_SERVICE_MONITORING_CONFIG_HTTP_PATH_QUANTIZATION_RULES", `
+        [
+          {
+            "pattern": "^[a-z]{2}-[A-Z]{2}$",
+            "repl": "{locale}"
+          },
+          {
+            "pattern": "^user-"
+          }
+        ]
+        `)
+		_, err := sysconfig.New("")
+		require.NoError(t, err)
+		cfg := New()
+
+		assert.True(t, cfg.EnableHTTPPathQuantization)
+		assert.Equal(t, expected, cfg.HTTPPathQuantizationRules)
+	})
+
+	t.Run("default", func(t *testing.T) {
+		newConfig(t)
+
+		_, err := sysconfig.New("")
+		require.NoError(t, err)
+		cfg := New()
+
+		assert.False(t, cfg.EnableHTTPPathQuantization)
+		assert.Empty(t, cfg.HTTPPathQuantizationRules)
+	})
+}
+
+func TestSharedLibrariesLimits(t *testing.T) {
+	t.Run("via YAML", func(t *testing.T) {
+		newConfig(t)
+
+		_, err := sysconfig.New("./testdata/TestDDSystemProbeConfig-SharedLibrariesLimits.yaml")
+		require.NoError(t, err)
+		cfg := New()
+
+		assert.Equal(t, 1000, cfg.MaxSharedLibrariesRegistrations)
+		assert.Equal(t, 200, cfg.MaxSharedLibrariesBlocklistEntries)
+		assert.Equal(t, 5*time.Minute, cfg.SharedLibrariesBlocklistTTL)
+	})
+
+	t.Run("via ENV variable", func(t *testing.T) {
+		newConfig(t)
+
+		t.Setenv("DD_SERVICE_MONITORING_CONFIG_MAX_SHARED_LIBRARIES_REGISTRATIONS", "2000")
+		t.Setenv("DD_SERVICE_MONITORING_CONFIG_MAX_SHARED_LIBRARIES_BLOCKLIST_ENTRIES", "300")
+		t.Setenv("DD_SERVICE_MONITORING_CONFIG_SHARED_LIBRARIES_BLOCKLIST_TTL_IN_S", "60")
+		_, err := sysconfig.New("")
+		require.NoError(t, err)
+		cfg := New()
+
+		assert.Equal(t, 2000, cfg.MaxSharedLibrariesRegistrations)
+		assert.Equal(t, 300, cfg.MaxSharedLibrariesBlocklistEntries)
+		assert.Equal(t, time.Minute, cfg.SharedLibrariesBlocklistTTL)
+	})
+
+	t.Run("default", func(t *testing.T) {
+		newConfig(t)
+
+		_, err := sysconfig.New("")
+		require.NoError(t, err)
+		cfg := New()
+
+		assert.Equal(t, 4096, cfg.MaxSharedLibrariesRegistrations)
+		assert.Equal(t, 4096, cfg.MaxSharedLibrariesBlocklistEntries)
+		assert.Equal(t, 10*time.Minute, cfg.SharedLibrariesBlocklistTTL)
+	})
+}
+
+func TestEnableInFlightTransactionsDebug(t *testing.T) {
+	t.Run("via YAML", func(t *testing.T) {
+		newConfig(t)
+
+		_, err := sysconfig.New("./testdata/TestDDSystemProbeConfig-EnableInFlightTransactionsDebug.yaml")
+		require.NoError(t, err)
+		cfg := New()
+
+		assert.True(t, cfg.EnableInFlightTransactionsDebug)
+	})
+
+	t.Run("via ENV variable", func(t *testing.T) {
+		newConfig(t)
+
+		t.Setenv("DD_SERVICE_MONITORING_CONFIG_ENABLE_IN_FLIGHT_TRANSACTIONS_DEBUG", "true")
+		_, err := sysconfig.New("")
+		require.NoError(t, err)
+		cfg := New()
+
+		assert.True(t, cfg.EnableInFlightTransactionsDebug)
+	})
+
+	t.Run("default", func(t *testing.T) {
+		newConfig(t)
+
+		_, err := sysconfig.New("")
+		require.NoError(t, err)
+		cfg := New()
+
+		assert.False(t, cfg.EnableInFlightTransactionsDebug)
+	})
+}
+
+func TestEnableIstioMonitoring(t *testing.T) {
+	t.Run("via YAML", func(t *testing.T) {
+		newConfig(t)
+
+		_, err := sysconfig.New("./testdata/TestDDAgentConfigYamlAndSystemProbeConfig-EnableIstio.yaml")
+		require.NoError(t, err)
+		cfg := New()
+
+		assert.True(t, cfg.EnableIstioMonitoring)
+	})
+
+	t.Run("via ENV variable", func(t *testing.T) {
+		newConfig(t)
+
+		t.Setenv("DD_SERVICE_MONITORING_CONFIG_ENABLE_ISTIO_MONITORING", "true")
+		_, err := sysconfig.New("")
+		require.NoError(t, err)
+		cfg := New()
+
+		assert.True(t, cfg.EnableIstioMonitoring)
+	})
+
+	t.Run("default", func(t *testing.T) {
+		newConfig(t)
+
+		_, err := sysconfig.New("")
+		require.NoError(t, err)
+		cfg := New()
+
+		assert.False(t, cfg.EnableIstioMonitoring)
+	})
+}
+
+func TestEnableNodeJSMonitoring(t *testing.T) {
+	t.Run("via YAML", func(t *testing.T) {
+		newConfig(t)
+
+		_, err := sysconfig.New("./testdata/TestDDAgentConfigYamlAndSystemProbeConfig-EnableNodeJS.yaml")
+		require.NoError(t, err)
+		cfg := New()
+
+		assert.True(t, cfg.EnableNodeJSMonitoring)
+	})
+
+	t.Run("via ENV variable", func(t *testing.T) {
+		newConfig(t)
+
+		t.Setenv("DD_SERVICE_MONITORING_CONFIG_ENABLE_NODEJS_MONITORING", "true")
+		_, err := sysconfig.New("")
+		require.NoError(t, err)
+		cfg := New()
+
+		assert.True(t, cfg.EnableNodeJSMonitoring)
+	})
+
+	t.Run("default", func(t *testing.T) {
+		newConfig(t)
+
+		_, err := sysconfig.New("")
+		require.NoError(t, err)
+		cfg := New()
+
+		assert.False(t, cfg.EnableNodeJSMonitoring)
+	})
+}
+
 func TestDefaultDisabledJavaTLSSupport(t *testing.T) {
 	newConfig(t)
 
@@ -223,6 +580,16 @@ func TestDefaultDisabledHTTP2Support(t *testing.T) {
 	assert.False(t, cfg.EnableHTTP2Monitoring)
 }
 
+func TestDefaultDisabledHTTP3Support(t *testing.T) {
+	newConfig(t)
+
+	_, err := sysconfig.New("")
+	require.NoError(t, err)
+	cfg := New()
+
+	assert.False(t, cfg.EnableHTTP3Monitoring)
+}
+
 func TestDisableGatewayLookup(t *testing.T) {
 	t.Run("via YAML", func(t *testing.T) {
 		newConfig(t)
@@ -274,6 +641,160 @@ func TestIgnoreConntrackInitFailure(t *testing.T) {
 	})
 }
 
+func TestCollectTCPListenOverflows(t *testing.T) {
+	t.Run("via YAML", func(t *testing.T) {
+		newConfig(t)
+		_, err := sysconfig.New("./testdata/TestDDAgentConfigYamlAndSystemProbeConfig-CollectTCPListenOverflows.yaml")
+		require.NoError(t, err)
+		cfg := New()
+
+		assert.True(t, cfg.CollectTCPListenOverflows)
+	})
+
+	t.Run("via ENV variable", func(t *testing.T) {
+		newConfig(t)
+		t.Setenv("DD_SYSTEM_PROBE_NETWORK_COLLECT_TCP_LISTEN_OVERFLOWS", "true")
+		_, err := sysconfig.New("")
+		require.NoError(t, err)
+		cfg := New()
+
+		assert.True(t, cfg.CollectTCPListenOverflows)
+	})
+
+	t.Run("default", func(t *testing.T) {
+		newConfig(t)
+		_, err := sysconfig.New("")
+		require.NoError(t, err)
+		cfg := New()
+
+		assert.False(t, cfg.CollectTCPListenOverflows)
+	})
+}
+
+func TestEnableConnectionCorrelation(t *testing.T) {
+	t.Run("via YAML", func(t *testing.T) {
+		newConfig(t)
+		_, err := sysconfig.New("./testdata/TestDDAgentConfigYamlAndSystemProbeConfig-EnableConnectionCorrelation.yaml")
+		require.NoError(t, err)
+		cfg := New()
+
+		assert.True(t, cfg.EnableConnectionCorrelation)
+	})
+
+	t.Run("via ENV variable", func(t *testing.T) {
+		newConfig(t)
+		t.Setenv("DD_SERVICE_MONITORING_CONFIG_ENABLE_CONNECTION_CORRELATION", "true")
+		_, err := sysconfig.New("")
+		require.NoError(t, err)
+		cfg := New()
+
+		assert.True(t, cfg.EnableConnectionCorrelation)
+	})
+
+	t.Run("default", func(t *testing.T) {
+		newConfig(t)
+		_, err := sysconfig.New("")
+		require.NoError(t, err)
+		cfg := New()
+
+		assert.False(t, cfg.EnableConnectionCorrelation)
+	})
+}
+
+func TestCollectTCPFailedConnections(t *testing.T) {
+	t.Run("via YAML", func(t *testing.T) {
+		newConfig(t)
+		_, err := sysconfig.New("./testdata/TestDDAgentConfigYamlAndSystemProbeConfig-CollectTCPFailedConnections.yaml")
+		require.NoError(t, err)
+		cfg := New()
+
+		assert.True(t, cfg.CollectTCPFailedConnections)
+	})
+
+	t.Run("via ENV variable", func(t *testing.T) {
+		newConfig(t)
+		t.Setenv("DD_SYSTEM_PROBE_NETWORK_COLLECT_TCP_FAILED_CONNECTIONS", "true")
+		_, err := sysconfig.New("")
+		require.NoError(t, err)
+		cfg := New()
+
+		assert.True(t, cfg.CollectTCPFailedConnections)
+	})
+
+	t.Run("default", func(t *testing.T) {
+		newConfig(t)
+		_, err := sysconfig.New("")
+		require.NoError(t, err)
+		cfg := New()
+
+		assert.False(t, cfg.CollectTCPFailedConnections)
+	})
+}
+
+func TestEnableServiceDependencies(t *testing.T) {
+	t.Run("via YAML", func(t *testing.T) {
+		newConfig(t)
+		_, err := sysconfig.New("./testdata/TestDDAgentConfigYamlAndSystemProbeConfig-EnableServiceDependencies.yaml")
+		require.NoError(t, err)
+		cfg := New()
+
+		assert.True(t, cfg.EnableServiceDependencies)
+		assert.Equal(t, 5000, cfg.ServiceDependenciesRollupThreshold)
+	})
+
+	t.Run("via ENV variable", func(t *testing.T) {
+		newConfig(t)
+		t.Setenv("DD_SYSTEM_PROBE_NETWORK_ENABLE_SERVICE_DEPENDENCIES", "true")
+		t.Setenv("DD_SYSTEM_PROBE_NETWORK_SERVICE_DEPENDENCIES_ROLLUP_THRESHOLD", "5000")
+		_, err := sysconfig.New("")
+		require.NoError(t, err)
+		cfg := New()
+
+		assert.True(t, cfg.EnableServiceDependencies)
+		assert.Equal(t, 5000, cfg.ServiceDependenciesRollupThreshold)
+	})
+
+	t.Run("default", func(t *testing.T) {
+		newConfig(t)
+		_, err := sysconfig.New("")
+		require.NoError(t, err)
+		cfg := New()
+
+		assert.False(t, cfg.EnableServiceDependencies)
+		assert.Equal(t, 0, cfg.ServiceDependenciesRollupThreshold)
+	})
+}
+
+func TestEnableFentry(t *testing.T) {
+	t.Run("via YAML", func(t *testing.T) {
+		newConfig(t)
+		_, err := sysconfig.New("./testdata/TestDDAgentConfigYamlAndSystemProbeConfig-EnableFentry.yaml")
+		require.NoError(t, err)
+		cfg := New()
+
+		assert.True(t, cfg.EnableFentry)
+	})
+
+	t.Run("via ENV variable", func(t *testing.T) {
+		newConfig(t)
+		t.Setenv("DD_SYSTEM_PROBE_NETWORK_ENABLE_FENTRY", "true")
+		_, err := sysconfig.New("")
+		require.NoError(t, err)
+		cfg := New()
+
+		assert.True(t, cfg.EnableFentry)
+	})
+
+	t.Run("default", func(t *testing.T) {
+		newConfig(t)
+		_, err := sysconfig.New("")
+		require.NoError(t, err)
+		cfg := New()
+
+		assert.False(t, cfg.EnableFentry)
+	})
+}
+
 func TestEnablingDNSStatsCollection(t *testing.T) {
 	t.Run("via YAML", func(t *testing.T) {
 		newConfig(t)
@@ -478,6 +999,34 @@ service_monitoring_config:
 	})
 }
 
+func TestConntrackPartialTrackingCIDRs(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		newConfig(t)
+		cfg := New()
+		assert.Empty(t, cfg.ConntrackPartialTrackingCIDRs)
+	})
+
+	t.Run("value set through env var", func(t *testing.T) {
+		newConfig(t)
+		t.Setenv("DD_SYSTEM_PROBE_NETWORK_CONNTRACK_PARTIAL_TRACKING_CIDRS", "10.0.0.0/8 fd00::/8")
+
+		cfg := New()
+		assert.Equal(t, []string{"10.0.0.0/8", "fd00::/8"}, cfg.ConntrackPartialTrackingCIDRs)
+	})
+
+	t.Run("value set through yaml", func(t *testing.T) {
+		newConfig(t)
+		cfg := configurationFromYAML(t, `
+network_config:
+  conntrack_partial_tracking_cidrs:
+    - 10.0.0.0/8
+    - 192.168.1.10
+`)
+
+		assert.Equal(t, []string{"10.0.0.0/8", "192.168.1.10"}, cfg.ConntrackPartialTrackingCIDRs)
+	})
+}
+
 func TestNetworkConfigEnabled(t *testing.T) {
 	ys := true
 
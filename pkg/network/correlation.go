@@ -0,0 +1,114 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package network
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"net/netip"
+	"sync"
+	"time"
+)
+
+// ErrConnectionNotFound is returned when looking up the correlation ID of a connection that isn't tracked
+var ErrConnectionNotFound = errors.New("connection not found")
+
+// CorrelationID returns the token linking the spans of a connection reported by the tracer libraries to its
+// connection record. It's only derived from fields of the connection record sent in the connections payload, so
+// that it can be computed again from the record instead of being sent along with it, as an unbounded tag.
+func CorrelationID(c *ConnectionStats) string {
+	h := fnv.New64a()
+	_, _ = h.Write(c.Source.AsSlice())
+	_, _ = h.Write(c.Dest.AsSlice())
+
+	var b [14]byte
+	binary.LittleEndian.PutUint16(b[0:], c.SPort)
+	binary.LittleEndian.PutUint16(b[2:], c.DPort)
+	binary.LittleEndian.PutUint32(b[4:], c.Pid)
+	binary.LittleEndian.PutUint32(b[8:], c.NetNS)
+	b[12] = uint8(c.Type)
+	b[13] = uint8(c.Family)
+	_, _ = h.Write(b[:])
+
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+type correlationKey struct {
+	laddr, raddr netip.AddrPort
+}
+
+type correlationEntry struct {
+	pid uint32
+	id  string
+}
+
+// CorrelationIndex indexes the correlation IDs of the connections by their local and remote endpoints. It's
+// rebuilt from the connections of each check, and the connections established since then are looked up by
+// rebuilding it at most once per refresh interval, so that the lookups don't scan the connections each time.
+type CorrelationIndex struct {
+	refreshInterval time.Duration
+
+	mu          sync.Mutex
+	byEndpoints map[correlationKey][]correlationEntry
+	lastUpdate  time.Time
+}
+
+// NewCorrelationIndex returns an empty CorrelationIndex, which may be rebuilt for a lookup every refreshInterval
+func NewCorrelationIndex(refreshInterval time.Duration) *CorrelationIndex {
+	return &CorrelationIndex{refreshInterval: refreshInterval}
+}
+
+// CorrelationIndexBuilder builds the content of a CorrelationIndex, see CorrelationIndex.Update
+type CorrelationIndexBuilder map[correlationKey][]correlationEntry
+
+// Add indexes the correlation ID of the connection
+func (b CorrelationIndexBuilder) Add(c *ConnectionStats) {
+	key := correlationKey{
+		laddr: netip.AddrPortFrom(c.Source.Addr, c.SPort),
+		raddr: netip.AddrPortFrom(c.Dest.Addr, c.DPort),
+	}
+	b[key] = append(b[key], correlationEntry{pid: c.Pid, id: CorrelationID(c)})
+}
+
+// Update replaces the content of the index with the connections added to the builder
+func (i *CorrelationIndex) Update(b CorrelationIndexBuilder, now time.Time) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.byEndpoints = b
+	i.lastUpdate = now
+}
+
+// Lookup returns the correlation ID of the connection between laddr and raddr, as seen from the local endpoint.
+// pid is the process ID in the host PID namespace, 0 matches any process.
+func (i *CorrelationIndex) Lookup(pid uint32, laddr, raddr netip.AddrPort) (string, bool) {
+	key := correlationKey{
+		laddr: netip.AddrPortFrom(laddr.Addr().Unmap(), laddr.Port()),
+		raddr: netip.AddrPortFrom(raddr.Addr().Unmap(), raddr.Port()),
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	for _, entry := range i.byEndpoints[key] {
+		if pid == 0 || entry.pid == pid {
+			return entry.id, true
+		}
+	}
+	return "", false
+}
+
+// ShouldRefresh reports whether the index may be rebuilt to look up a connection missing from it. A true result
+// reserves the refresh, so that the concurrent lookups don't rebuild the index at the same time.
+func (i *CorrelationIndex) ShouldRefresh(now time.Time) bool {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if now.Sub(i.lastUpdate) < i.refreshInterval {
+		return false
+	}
+	i.lastUpdate = now
+	return true
+}
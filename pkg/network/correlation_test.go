@@ -0,0 +1,83 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package network
+
+import (
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+)
+
+func TestCorrelationID(t *testing.T) {
+	conn := ConnectionStats{
+		Source: util.AddressFromString("10.1.1.1"),
+		Dest:   util.AddressFromString("10.2.2.2"),
+		SPort:  35000,
+		DPort:  443,
+		Pid:    42,
+		NetNS:  4026531992,
+		Cookie: 1234,
+		Type:   TCP,
+		Family: AFINET,
+	}
+
+	id := CorrelationID(&conn)
+	assert.Len(t, id, 16)
+
+	// the ID only depends on the fields of the connection record, not on its stats nor its cookie
+	withStats := conn
+	withStats.Monotonic = StatCounters{SentBytes: 100}
+	withStats.LastUpdateEpoch = 50
+	withStats.Cookie = 5678
+	assert.Equal(t, id, CorrelationID(&withStats))
+
+	reversed := conn
+	reversed.Source, reversed.Dest = conn.Dest, conn.Source
+	assert.NotEqual(t, id, CorrelationID(&reversed))
+}
+
+func TestCorrelationIndex(t *testing.T) {
+	conn := ConnectionStats{
+		Source: util.AddressFromString("10.1.1.1"),
+		Dest:   util.AddressFromString("10.2.2.2"),
+		SPort:  35000,
+		DPort:  443,
+		Pid:    42,
+		Type:   TCP,
+		Family: AFINET,
+	}
+	laddr := netip.MustParseAddrPort("10.1.1.1:35000")
+	raddr := netip.MustParseAddrPort("10.2.2.2:443")
+
+	now := time.Now()
+	index := NewCorrelationIndex(time.Second)
+	_, found := index.Lookup(0, laddr, raddr)
+	assert.False(t, found)
+
+	builder := make(CorrelationIndexBuilder)
+	builder.Add(&conn)
+	index.Update(builder, now)
+
+	id, found := index.Lookup(0, laddr, raddr)
+	assert.True(t, found)
+	assert.Equal(t, CorrelationID(&conn), id)
+	// the IPv4-mapped IPv6 addresses match their IPv4 address
+	_, found = index.Lookup(42, netip.MustParseAddrPort("[::ffff:10.1.1.1]:35000"), raddr)
+	assert.True(t, found)
+	_, found = index.Lookup(43, laddr, raddr)
+	assert.False(t, found)
+	_, found = index.Lookup(0, raddr, laddr)
+	assert.False(t, found)
+
+	// the index is rebuilt at most once per refresh interval
+	assert.False(t, index.ShouldRefresh(now.Add(500*time.Millisecond)))
+	assert.True(t, index.ShouldRefresh(now.Add(time.Second)))
+	assert.False(t, index.ShouldRefresh(now.Add(1500*time.Millisecond)))
+}
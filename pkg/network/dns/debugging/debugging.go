@@ -0,0 +1,76 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package debugging provides a debug-friendly representation of the DNS stats collected by the network tracer
+package debugging
+
+import (
+	"sort"
+
+	"github.com/DataDog/sketches-go/ddsketch"
+	"github.com/google/gopacket/layers"
+
+	"github.com/DataDog/datadog-agent/pkg/network/dns"
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+)
+
+// ServerSummary represents a (debug-friendly) view of the health of a DNS server
+type ServerSummary struct {
+	Server             string
+	Queries            uint32
+	TCPQueries         uint32
+	Timeouts           uint32
+	TruncatedResponses uint32
+	ResponseCodes      map[string]uint32 `json:",omitempty"`
+	// TimeoutRate and ServFailRate are the ratios of the queries which timed out, or failed with SERVFAIL
+	TimeoutRate  float64
+	ServFailRate float64
+	// The latencies are in microseconds
+	LatencyP50 float64
+	LatencyP95 float64
+	LatencyP99 float64
+}
+
+// Servers returns a debug-friendly representation of the health stats of the DNS servers, sorted by server IP
+func Servers(stats map[util.Address]*dns.ServerStats) []ServerSummary {
+	all := make([]ServerSummary, 0, len(stats))
+	for server, serverStats := range stats {
+		summary := ServerSummary{
+			Server:             server.String(),
+			Queries:            serverStats.Queries,
+			TCPQueries:         serverStats.TCPQueries,
+			Timeouts:           serverStats.Timeouts,
+			TruncatedResponses: serverStats.TruncatedResponses,
+			LatencyP50:         getSketchQuantile(serverStats.Latencies, 0.5),
+			LatencyP95:         getSketchQuantile(serverStats.Latencies, 0.95),
+			LatencyP99:         getSketchQuantile(serverStats.Latencies, 0.99),
+		}
+		for rcode, count := range serverStats.CountByRcode {
+			if summary.ResponseCodes == nil {
+				summary.ResponseCodes = make(map[string]uint32)
+			}
+			summary.ResponseCodes[layers.DNSResponseCode(rcode).String()] = count
+		}
+		if serverStats.Queries > 0 {
+			summary.TimeoutRate = float64(serverStats.Timeouts) / float64(serverStats.Queries)
+			summary.ServFailRate = float64(serverStats.CountByRcode[uint32(layers.DNSResponseCodeServFail)]) / float64(serverStats.Queries)
+		}
+		all = append(all, summary)
+	}
+
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].Server < all[j].Server
+	})
+	return all
+}
+
+func getSketchQuantile(sketch *ddsketch.DDSketch, percentile float64) float64 {
+	if sketch == nil {
+		return 0.0
+	}
+
+	val, _ := sketch.GetValueAtQuantile(percentile)
+	return val
+}
@@ -27,6 +27,10 @@ func (nullReverseDNS) GetDNSStats() StatsByKeyByNameByType {
 	return nil
 }
 
+func (nullReverseDNS) GetServerStats() map[util.Address]*ServerStats {
+	return nil
+}
+
 func (nullReverseDNS) Start() error {
 	return nil
 }
@@ -203,6 +203,7 @@ func (p *dnsParser) parseAnswerInto(
 	}
 
 	pktInfo.rCode = uint8(dns.ResponseCode)
+	pktInfo.truncated = dns.TC
 	if dns.ResponseCode != 0 {
 		pktInfo.pktType = failedResponse
 		return nil
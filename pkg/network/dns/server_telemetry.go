@@ -0,0 +1,107 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build windows || linux_bpf
+// +build windows linux_bpf
+
+package dns
+
+import (
+	"github.com/google/gopacket/layers"
+
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+	"github.com/DataDog/datadog-agent/pkg/telemetry"
+)
+
+const dnsServersModuleName = "network_tracer__dns_servers"
+
+// The health stats of the DNS servers, over the last interval they were collected, tagged by server IP. The
+// number of servers is bounded by maxServerStats.
+var serverTelemetry = struct {
+	queries            telemetry.Gauge
+	tcpQueries         telemetry.Gauge
+	timeouts           telemetry.Gauge
+	truncatedResponses telemetry.Gauge
+	responses          telemetry.Gauge
+	latencyP50         telemetry.Gauge
+	latencyP99         telemetry.Gauge
+}{
+	telemetry.NewGauge(dnsServersModuleName, "queries", []string{"server"}, "Gauge measuring the number of queries sent to a DNS server"),
+	telemetry.NewGauge(dnsServersModuleName, "tcp_queries", []string{"server"}, "Gauge measuring the number of queries sent over TCP to a DNS server"),
+	telemetry.NewGauge(dnsServersModuleName, "timeouts", []string{"server"}, "Gauge measuring the number of queries to a DNS server which timed out"),
+	telemetry.NewGauge(dnsServersModuleName, "truncated_responses", []string{"server"}, "Gauge measuring the number of UDP responses of a DNS server with the TC flag set"),
+	telemetry.NewGauge(dnsServersModuleName, "responses", []string{"server", "rcode"}, "Gauge measuring the number of responses of a DNS server by response code"),
+	telemetry.NewGauge(dnsServersModuleName, "latency_p50", []string{"server"}, "Gauge measuring the median latency of the responses of a DNS server, in microseconds"),
+	telemetry.NewGauge(dnsServersModuleName, "latency_p99", []string{"server"}, "Gauge measuring the 99th percentile of the latency of the responses of a DNS server, in microseconds"),
+}
+
+// serverStatsReporter reports the health stats of the DNS servers as telemetry
+type serverStatsReporter struct {
+	// rcodes holds the response codes reported by server, whose gauges are deleted once the server is no longer
+	// in the stats
+	rcodes map[string][]uint32
+}
+
+func newServerStatsReporter() *serverStatsReporter {
+	return &serverStatsReporter{rcodes: make(map[string][]uint32)}
+}
+
+// report sets the gauges of the servers with the given stats, and deletes the gauges of the servers which were
+// reported the last time but don't have stats anymore.
+func (r *serverStatsReporter) report(stats map[util.Address]*ServerStats) {
+	reported := make(map[string][]uint32, len(stats))
+	for address, serverStats := range stats {
+		server := address.String()
+		serverTelemetry.queries.Set(float64(serverStats.Queries), server)
+		serverTelemetry.tcpQueries.Set(float64(serverStats.TCPQueries), server)
+		serverTelemetry.timeouts.Set(float64(serverStats.Timeouts), server)
+		serverTelemetry.truncatedResponses.Set(float64(serverStats.TruncatedResponses), server)
+
+		rcodes := make([]uint32, 0, len(serverStats.CountByRcode))
+		for rcode, count := range serverStats.CountByRcode {
+			serverTelemetry.responses.Set(float64(count), server, rcodeName(rcode))
+			rcodes = append(rcodes, rcode)
+		}
+		// the response codes the server no longer returns are deleted
+		for _, rcode := range r.rcodes[server] {
+			if _, ok := serverStats.CountByRcode[rcode]; !ok {
+				serverTelemetry.responses.Delete(server, rcodeName(rcode))
+			}
+		}
+		reported[server] = rcodes
+
+		if serverStats.Latencies != nil {
+			if p50, err := serverStats.Latencies.GetValueAtQuantile(0.5); err == nil {
+				serverTelemetry.latencyP50.Set(p50, server)
+			}
+			if p99, err := serverStats.Latencies.GetValueAtQuantile(0.99); err == nil {
+				serverTelemetry.latencyP99.Set(p99, server)
+			}
+		} else {
+			serverTelemetry.latencyP50.Delete(server)
+			serverTelemetry.latencyP99.Delete(server)
+		}
+	}
+
+	for server, rcodes := range r.rcodes {
+		if _, ok := reported[server]; ok {
+			continue
+		}
+		serverTelemetry.queries.Delete(server)
+		serverTelemetry.tcpQueries.Delete(server)
+		serverTelemetry.timeouts.Delete(server)
+		serverTelemetry.truncatedResponses.Delete(server)
+		serverTelemetry.latencyP50.Delete(server)
+		serverTelemetry.latencyP99.Delete(server)
+		for _, rcode := range rcodes {
+			serverTelemetry.responses.Delete(server, rcodeName(rcode))
+		}
+	}
+	r.rcodes = reported
+}
+
+func rcodeName(rcode uint32) string {
+	return layers.DNSResponseCode(rcode).String()
+}
@@ -126,6 +126,14 @@ func (s *socketFilterSnooper) GetDNSStats() StatsByKeyByNameByType {
 	return s.statKeeper.GetAndResetAllStats()
 }
 
+// GetServerStats gets the health stats of the DNS servers since the last call, keyed by server IP
+func (s *socketFilterSnooper) GetServerStats() map[util.Address]*ServerStats {
+	if s.statKeeper == nil {
+		return nil
+	}
+	return s.statKeeper.GetAndResetServerStats()
+}
+
 // Start starts the snooper (no-op currently)
 func (s *socketFilterSnooper) Start() error {
 	return nil // no-op as this is done in newSocketFilterSnooper above
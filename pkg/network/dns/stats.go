@@ -10,9 +10,13 @@ package dns
 
 import (
 	"sync"
+	"syscall"
 	"time"
 
+	"github.com/DataDog/sketches-go/ddsketch"
+
 	nettelemetry "github.com/DataDog/datadog-agent/pkg/network/telemetry"
+	"github.com/DataDog/datadog-agent/pkg/process/util"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
@@ -31,14 +35,20 @@ const (
 	// This const limits the maximum size of the state map. Benchmark results show that allocated space is less than 3MB
 	// for 10000 entries.
 	maxStateMapSize = 10000
+	// This const limits the number of DNS servers whose health stats are kept
+	maxServerStats = 1024
+	// serverLatencyRelativeAccuracy is the relative accuracy of the latency sketches of the DNS servers
+	serverLatencyRelativeAccuracy = 0.01
 )
 
 var statsTelemetry = struct {
 	processedStats *nettelemetry.StatCounterWrapper
 	droppedStats   *nettelemetry.StatCounterWrapper
+	droppedServers *nettelemetry.StatCounterWrapper
 }{
 	nettelemetry.NewStatCounterWrapper(dnsStatKeeperModuleName, "processed_stats", []string{}, "Counter measuring the number of processed DNS stats"),
 	nettelemetry.NewStatCounterWrapper(dnsStatKeeperModuleName, "dropped_stats", []string{}, "Counter measuring the number of dropped DNS stats"),
+	nettelemetry.NewStatCounterWrapper(dnsStatKeeperModuleName, "dropped_server_stats", []string{}, "Counter measuring the number of DNS packets not accounted in the stats of their server, because of the limit of servers"),
 }
 
 type dnsPacketInfo struct {
@@ -48,6 +58,7 @@ type dnsPacketInfo struct {
 	rCode         uint8    // responseCode
 	question      Hostname // only relevant for query packets
 	queryType     QueryType
+	truncated     bool // only relevant for response packets
 }
 
 type stateKey struct {
@@ -65,6 +76,8 @@ type dnsStatKeeper struct {
 	mux sync.Mutex
 	// map a DNS key to a map of domain strings to a map of query types to a map of  DNS stats
 	stats              StatsByKeyByNameByType
+	serverStats        map[util.Address]*ServerStats
+	serverReporter     *serverStatsReporter
 	state              map[stateKey]stateValue
 	expirationPeriod   time.Duration
 	exit               chan struct{}
@@ -79,6 +92,8 @@ type dnsStatKeeper struct {
 func newDNSStatkeeper(timeout time.Duration, maxStats int64) *dnsStatKeeper {
 	statsKeeper := &dnsStatKeeper{
 		stats:            make(StatsByKeyByNameByType),
+		serverStats:      make(map[util.Address]*ServerStats),
+		serverReporter:   newServerStatsReporter(),
 		state:            make(map[stateKey]stateValue),
 		expirationPeriod: timeout,
 		exit:             make(chan struct{}),
@@ -117,6 +132,12 @@ func (d *dnsStatKeeper) ProcessPacketInfo(info dnsPacketInfo, ts time.Time) {
 
 		if _, ok := d.state[sk]; !ok {
 			d.state[sk] = stateValue{question: info.question, ts: microSecs(ts), qtype: info.queryType}
+			if server := d.getServerStats(info.key.ServerIP); server != nil {
+				server.Queries++
+				if info.key.Protocol == syscall.IPPROTO_TCP {
+					server.TCPQueries++
+				}
+			}
 		}
 		return
 	}
@@ -132,6 +153,7 @@ func (d *dnsStatKeeper) ProcessPacketInfo(info dnsPacketInfo, ts time.Time) {
 	d.deleteCount++
 
 	latency := microSecs(ts) - start.ts
+	d.processServerResponse(info, latency)
 
 	allStats, ok := d.stats[info.key]
 	if !ok {
@@ -185,6 +207,62 @@ func (d *dnsStatKeeper) GetAndResetAllStats() StatsByKeyByNameByType {
 	return ret
 }
 
+// GetAndResetServerStats returns the health stats of the DNS servers since the last call, and reports them as
+// telemetry
+func (d *dnsStatKeeper) GetAndResetServerStats() map[util.Address]*ServerStats {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+	ret := d.serverStats // No deep copy needed since `d.serverStats` gets reset
+	d.serverStats = make(map[util.Address]*ServerStats)
+	d.serverReporter.report(ret)
+	return ret
+}
+
+// getServerStats returns the health stats of a DNS server, or nil when the limit of servers is reached.
+// It must be called with the lock held.
+func (d *dnsStatKeeper) getServerStats(server util.Address) *ServerStats {
+	stats, ok := d.serverStats[server]
+	if ok {
+		return stats
+	}
+	if len(d.serverStats) >= maxServerStats {
+		statsTelemetry.droppedServers.Inc()
+		return nil
+	}
+	stats = &ServerStats{CountByRcode: make(map[uint32]uint32)}
+	d.serverStats[server] = stats
+	return stats
+}
+
+// processServerResponse accounts a response, received after the given latency in microseconds, in the health
+// stats of its server. It must be called with the lock held.
+func (d *dnsStatKeeper) processServerResponse(info dnsPacketInfo, latency uint64) {
+	server := d.getServerStats(info.key.ServerIP)
+	if server == nil {
+		return
+	}
+	if latency > uint64(d.expirationPeriod.Microseconds()) {
+		server.Timeouts++
+		return
+	}
+
+	server.CountByRcode[uint32(info.rCode)]++
+	if info.truncated && info.key.Protocol == syscall.IPPROTO_UDP {
+		server.TruncatedResponses++
+	}
+	if server.Latencies == nil {
+		sketch, err := ddsketch.NewDefaultDDSketch(serverLatencyRelativeAccuracy)
+		if err != nil {
+			log.Debugf("could not create the latency sketch of the DNS server %s: %v", info.key.ServerIP, err)
+			return
+		}
+		server.Latencies = sketch
+	}
+	if err := server.Latencies.Add(float64(latency)); err != nil {
+		log.Debugf("could not add the DNS response latency to the sketch: %v", err)
+	}
+}
+
 // Snapshot returns a deep copy of all DNS stats.
 // Please only use this for testing.
 func (d *dnsStatKeeper) Snapshot() StatsByKeyByNameByType {
@@ -221,6 +299,9 @@ func (d *dnsStatKeeper) removeExpiredStates(earliestTs time.Time) {
 		if v.ts < threshold {
 			delete(d.state, k)
 			d.deleteCount++
+			if server := d.getServerStats(k.key.ServerIP); server != nil {
+				server.Timeouts++
+			}
 			// When we expire a state, we need to increment timeout count for that key:domain
 			allStats, ok := d.stats[k.key]
 			if !ok {
@@ -107,6 +107,63 @@ func TestExpiredStateRemoval(t *testing.T) {
 	assert.Equal(t, uint32(1), stats[key][d][TypeA].Timeouts)
 }
 
+func TestServerStats(t *testing.T) {
+	sk := newDNSStatkeeper(DNSTimeoutSecs*time.Second, 10000)
+	key := getSampleDNSKey()
+	tcpKey := key
+	tcpKey.ClientPort = 1001
+	tcpKey.Protocol = syscall.IPPROTO_TCP
+	var d = ToHostname("abc.com")
+
+	then := time.Now()
+	// a query whose response is truncated, and retried over TCP
+	sk.ProcessPacketInfo(dnsPacketInfo{transactionID: 1, pktType: query, key: key, question: d, queryType: TypeA}, then)
+	sk.ProcessPacketInfo(dnsPacketInfo{transactionID: 1, pktType: successfulResponse, key: key, queryType: TypeA, truncated: true}, then.Add(10*time.Microsecond))
+	sk.ProcessPacketInfo(dnsPacketInfo{transactionID: 1, pktType: query, key: tcpKey, question: d, queryType: TypeA}, then)
+	sk.ProcessPacketInfo(dnsPacketInfo{transactionID: 1, pktType: successfulResponse, key: tcpKey, queryType: TypeA}, then.Add(30*time.Microsecond))
+	// a failed query
+	sk.ProcessPacketInfo(dnsPacketInfo{transactionID: 2, pktType: query, key: key, question: d, queryType: TypeA}, then)
+	sk.ProcessPacketInfo(dnsPacketInfo{transactionID: 2, pktType: failedResponse, key: key, queryType: TypeA, rCode: 2}, then.Add(20*time.Microsecond))
+	// a query answered after the timeout, and one which isn't answered
+	sk.ProcessPacketInfo(dnsPacketInfo{transactionID: 3, pktType: query, key: key, question: d, queryType: TypeA}, then)
+	sk.ProcessPacketInfo(dnsPacketInfo{transactionID: 3, pktType: successfulResponse, key: key, queryType: TypeA}, then.Add(DNSTimeoutSecs*time.Second+time.Microsecond))
+	sk.ProcessPacketInfo(dnsPacketInfo{transactionID: 4, pktType: query, key: key, question: d, queryType: TypeA}, then)
+	sk.removeExpiredStates(then.Add(time.Microsecond))
+
+	stats := sk.GetAndResetServerStats()
+	require.Len(t, stats, 1)
+	require.Contains(t, stats, key.ServerIP)
+	server := stats[key.ServerIP]
+	assert.Equal(t, uint32(5), server.Queries)
+	assert.Equal(t, uint32(1), server.TCPQueries)
+	assert.Equal(t, uint32(2), server.Timeouts)
+	assert.Equal(t, uint32(1), server.TruncatedResponses)
+	assert.Equal(t, map[uint32]uint32{0: 2, 2: 1}, server.CountByRcode)
+	require.NotNil(t, server.Latencies)
+	assert.Equal(t, float64(3), server.Latencies.GetCount())
+	maxLatency, err := server.Latencies.GetMaxValue()
+	require.NoError(t, err)
+	assert.InDelta(t, 30, maxLatency, 30*serverLatencyRelativeAccuracy)
+
+	assert.Empty(t, sk.GetAndResetServerStats())
+}
+
+func TestServerStatsLimit(t *testing.T) {
+	sk := newDNSStatkeeper(DNSTimeoutSecs*time.Second, 10000)
+	key := getSampleDNSKey()
+	var d = ToHostname("abc.com")
+
+	now := time.Now()
+	for i := 0; i <= maxServerStats; i++ {
+		key.ServerIP = util.V4Address(uint32(i))
+		sk.ProcessPacketInfo(dnsPacketInfo{transactionID: 1, pktType: query, key: key, question: d, queryType: TypeA}, now)
+	}
+
+	stats := sk.GetAndResetServerStats()
+	assert.Len(t, stats, maxServerStats)
+	assert.NotContains(t, stats, key.ServerIP)
+}
+
 func BenchmarkStats(b *testing.B) {
 	key := getSampleDNSKey()
 
@@ -132,3 +189,22 @@ func BenchmarkStats(b *testing.B) {
 		})
 	}
 }
+
+func TestServerStatsReporter(t *testing.T) {
+	reporter := newServerStatsReporter()
+	server := util.AddressFromString("8.8.8.8")
+	other := util.AddressFromString("1.1.1.1")
+
+	reporter.report(map[util.Address]*ServerStats{
+		server: {Queries: 2, CountByRcode: map[uint32]uint32{0: 1, 2: 1}},
+		other:  {Queries: 1, CountByRcode: map[uint32]uint32{0: 1}},
+	})
+	assert.Len(t, reporter.rcodes, 2)
+	assert.ElementsMatch(t, []uint32{0, 2}, reporter.rcodes[server.String()])
+
+	// the servers without stats are no longer reported
+	reporter.report(map[util.Address]*ServerStats{
+		server: {Queries: 1, CountByRcode: map[uint32]uint32{0: 1}},
+	})
+	assert.Equal(t, map[string][]uint32{server.String(): {0}}, reporter.rcodes)
+}
@@ -6,6 +6,7 @@
 package dns
 
 import (
+	"github.com/DataDog/sketches-go/ddsketch"
 	"github.com/google/gopacket/layers"
 
 	"github.com/DataDog/datadog-agent/pkg/process/util"
@@ -67,6 +68,7 @@ type StatsByKeyByNameByType map[Key]map[Hostname]map[QueryType]Stats
 type ReverseDNS interface {
 	Resolve([]util.Address) map[util.Address][]Hostname
 	GetDNSStats() StatsByKeyByNameByType
+	GetServerStats() map[util.Address]*ServerStats
 	Start() error
 	Close()
 }
@@ -87,3 +89,16 @@ type Stats struct {
 	FailureLatencySum uint64
 	CountByRcode      map[uint32]uint32
 }
+
+// ServerStats holds the health statistics of a DNS server, aggregated across all its clients and domains
+type ServerStats struct {
+	Queries uint32
+	// TCPQueries is the number of queries sent over TCP, mostly retries of the queries whose response was truncated
+	TCPQueries uint32
+	Timeouts   uint32
+	// TruncatedResponses is the number of responses sent over UDP with the TC flag set
+	TruncatedResponses uint32
+	CountByRcode       map[uint32]uint32
+	// Latencies holds the latencies of the responses received within the timeout, in microseconds
+	Latencies *ddsketch.DDSketch
+}
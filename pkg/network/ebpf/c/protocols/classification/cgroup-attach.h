@@ -0,0 +1,128 @@
+#ifndef __USM_CGROUP_ATTACH_H
+#define __USM_CGROUP_ATTACH_H
+
+#include "ktypes.h"
+#include "bpf_builtins.h"
+#include "bpf_endian.h"
+#include "bpf_helpers.h"
+#include "ip.h"
+#include "port_range.h"
+
+#include "protocols/classification/common.h"
+#include "protocols/classification/dispatcher-maps.h"
+#include "protocols/dns/dns-classification.h"
+
+// In the cgroup attach mode, USM only monitors the traffic of the workloads selected in the configuration.
+// cgroup_skb programs are attached to the cgroups of these workloads, and record the tuples of the packets they
+// send and receive. The protocol dispatcher then skips the packets of the other connections before classifying
+// them. The parsing itself stays in the socket filter, as the programs tail called by the dispatcher are socket
+// filters, and a program can only tail call programs of its own type.
+static __always_inline bool is_usm_cgroup_attach_enabled() {
+    __u64 val = 0;
+    LOAD_CONSTANT("usm_cgroup_attach_enabled", val);
+    return val > 0;
+}
+
+// read_conn_tuple_cgroup_skb reads the tuple of a packet seen by a cgroup_skb program, whose data starts at the
+// IP header. The tuple is encoded as read_conn_tuple_skb does, so that the socket filter finds it, but the
+// packet is read with bpf_skb_load_bytes, the LD_ABS instructions not being allowed for this program type.
+static __always_inline bool read_conn_tuple_cgroup_skb(struct __sk_buff *skb, conn_tuple_t *tup) {
+    __u32 offset = 0;
+    __u8 l4_proto = 0;
+
+    switch (bpf_ntohs(skb->protocol)) {
+    case ETH_P_IP:
+    {
+        __u8 version_ihl = 0;
+        if (bpf_skb_load_bytes(skb, 0, &version_ihl, sizeof(version_ihl)) < 0) {
+            return false;
+        }
+        __u8 ipv4_hdr_len = (version_ihl & 0x0f) << 2;
+        if (ipv4_hdr_len < sizeof(struct iphdr)) {
+            return false;
+        }
+        __u32 saddr = 0, daddr = 0;
+        if (bpf_skb_load_bytes(skb, offsetof(struct iphdr, protocol), &l4_proto, sizeof(l4_proto)) < 0 ||
+            bpf_skb_load_bytes(skb, offsetof(struct iphdr, saddr), &saddr, sizeof(saddr)) < 0 ||
+            bpf_skb_load_bytes(skb, offsetof(struct iphdr, daddr), &daddr, sizeof(daddr)) < 0) {
+            return false;
+        }
+        tup->metadata |= CONN_V4;
+        tup->saddr_l = saddr;
+        tup->daddr_l = daddr;
+        offset = ipv4_hdr_len;
+        break;
+    }
+    case ETH_P_IPV6:
+    {
+        __u64 addr[4] = {0};
+        if (bpf_skb_load_bytes(skb, offsetof(struct ipv6hdr, nexthdr), &l4_proto, sizeof(l4_proto)) < 0 ||
+            bpf_skb_load_bytes(skb, offsetof(struct ipv6hdr, saddr), addr, sizeof(addr)) < 0) {
+            return false;
+        }
+        tup->metadata |= CONN_V6;
+        tup->saddr_h = addr[0];
+        tup->saddr_l = addr[1];
+        tup->daddr_h = addr[2];
+        tup->daddr_l = addr[3];
+        offset = sizeof(struct ipv6hdr);
+        break;
+    }
+    default:
+        return false;
+    }
+
+    // the source and destination ports are at the same offsets in the TCP and UDP headers
+    __u16 ports[2] = {0};
+    switch (l4_proto) {
+    case __IPPROTO_UDP:
+        tup->metadata |= CONN_TYPE_UDP;
+        break;
+    case __IPPROTO_TCP:
+        tup->metadata |= CONN_TYPE_TCP;
+        break;
+    default:
+        return false;
+    }
+    if (bpf_skb_load_bytes(skb, offset, ports, sizeof(ports)) < 0) {
+        return false;
+    }
+    tup->sport = bpf_ntohs(ports[0]);
+    tup->dport = bpf_ntohs(ports[1]);
+    return true;
+}
+
+// usm_cgroup_record_tuple records the tuple of a packet sent or received by a selected workload.
+static __always_inline void usm_cgroup_record_tuple(struct __sk_buff *skb) {
+    conn_tuple_t tup;
+    bpf_memset(&tup, 0, sizeof(tup));
+    if (!read_conn_tuple_cgroup_skb(skb, &tup)) {
+        return;
+    }
+    // the socket filter only dispatches the DNS datagrams, the tuples of the other UDP flows would never be looked up
+    if (is_udp(&tup) && !is_dns_port(&tup)) {
+        return;
+    }
+    normalize_tuple(&tup);
+
+    if (bpf_map_lookup_elem(&usm_cgroup_tuples, &tup) != NULL) {
+        return;
+    }
+    __u8 monitored = 1;
+    bpf_map_update_elem(&usm_cgroup_tuples, &tup, &monitored, BPF_NOEXIST);
+}
+
+// is_usm_cgroup_monitored returns true if the packet read by the socket filter belongs to a connection of a
+// selected workload, or if the cgroup attach mode is disabled.
+static __always_inline bool is_usm_cgroup_monitored(conn_tuple_t *skb_tup) {
+    if (!is_usm_cgroup_attach_enabled()) {
+        return true;
+    }
+
+    conn_tuple_t tup;
+    bpf_memcpy(&tup, skb_tup, sizeof(conn_tuple_t));
+    normalize_tuple(&tup);
+    return bpf_map_lookup_elem(&usm_cgroup_tuples, &tup) != NULL;
+}
+
+#endif
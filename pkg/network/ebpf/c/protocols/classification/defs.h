@@ -56,11 +56,13 @@ typedef enum {
     PROTOCOL_AMQP,
     PROTOCOL_REDIS,
     PROTOCOL_MYSQL,
+    PROTOCOL_DNS,
     __LAYER_APPLICATION_MAX = LAYER_APPLICATION_MAX,
 
     __LAYER_ENCRYPTION_MIN = LAYER_ENCRYPTION_BIT,
     //  Add encryption protocols below (eg. TLS)
     PROTOCOL_TLS,
+    PROTOCOL_QUIC,
     __LAYER_ENCRYPTION_MAX = LAYER_ENCRYPTION_MAX,
 } __attribute__ ((packed)) protocol_t;
 
@@ -109,6 +111,8 @@ typedef enum {
     PROG_HTTP,
     PROG_HTTP2,
     PROG_KAFKA,
+    PROG_HTTP_RESPONSE_HEADERS,
+    PROG_DNS,
     // Add before this value.
     PROG_MAX,
 } protocol_prog_t;
@@ -121,6 +125,8 @@ __maybe_unused static __always_inline protocol_prog_t protocol_to_program(protoc
         return PROG_HTTP2;
     case PROTOCOL_KAFKA:
         return PROG_KAFKA;
+    case PROTOCOL_DNS:
+        return PROG_DNS;
     default:
         if (proto != PROTOCOL_UNKNOWN) {
             log_debug("protocol doesn't have a matching program: %d\n", proto);
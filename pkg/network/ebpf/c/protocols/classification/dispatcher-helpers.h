@@ -5,16 +5,20 @@
 
 #include "ip.h"
 
+#include "protocols/classification/cgroup-attach.h"
 #include "protocols/classification/defs.h"
 #include "protocols/classification/maps.h"
 #include "protocols/classification/structs.h"
 #include "protocols/classification/dispatcher-maps.h"
+#include "protocols/dns/dns-classification.h"
+#include "protocols/dns/usm-events.h"
 #include "protocols/http/classification-helpers.h"
 #include "protocols/http/usm-events.h"
 #include "protocols/http2/helpers.h"
 #include "protocols/http2/usm-events.h"
 #include "protocols/kafka/kafka-classification.h"
 #include "protocols/kafka/usm-events.h"
+#include "protocols/tls/tls-info.h"
 
 // Returns true if the payload represents a TCP termination by checking if the tcp flags contains TCPHDR_FIN or TCPHDR_RST.
 static __always_inline bool is_tcp_termination(skb_info_t *skb_info) {
@@ -62,6 +66,43 @@ static __always_inline void classify_protocol_for_dispatcher(protocol_t *protoco
     log_debug("[protocol_dispatcher_classifier]: Classified protocol as %d %d; %s\n", *protocol, size, buf);
 }
 
+// UDP has no connection whose protocol could be classified once, so every non empty datagram is classified on its
+// own, the classification of the UDP protocols being cheap. Then the datagram is dispatched to the program of its protocol.
+static __always_inline void dispatch_udp(struct __sk_buff *skb, skb_info_t *skb_info, conn_tuple_t *skb_tup) {
+    if (is_payload_empty(skb, skb_info)) {
+        return;
+    }
+
+    char request_fragment[CLASSIFICATION_MAX_BUFFER];
+    bpf_memset(request_fragment, 0, sizeof(request_fragment));
+    read_into_buffer_for_classification((char *)request_fragment, skb, skb_info->data_off);
+    const size_t payload_length = skb->len - skb_info->data_off;
+    const size_t final_fragment_size = payload_length < CLASSIFICATION_MAX_BUFFER ? payload_length : CLASSIFICATION_MAX_BUFFER;
+
+    // other UDP based protocols, such as QUIC, are to be classified here
+    protocol_t cur_fragment_protocol = PROTOCOL_UNKNOWN;
+    if (is_dns(skb_tup, request_fragment, final_fragment_size)) {
+        cur_fragment_protocol = PROTOCOL_DNS;
+    }
+
+    if (cur_fragment_protocol == PROTOCOL_UNKNOWN) {
+        return;
+    }
+
+    const u32 zero = 0;
+    dispatcher_arguments_t *args = bpf_map_lookup_elem(&dispatcher_arguments, &zero);
+    if (args == NULL) {
+        log_debug("dispatcher failed to save arguments for tail call\n");
+        return;
+    }
+    bpf_memset(args, 0, sizeof(dispatcher_arguments_t));
+    bpf_memcpy(&args->tup, skb_tup, sizeof(conn_tuple_t));
+    bpf_memcpy(&args->skb_info, skb_info, sizeof(skb_info_t));
+
+    log_debug("dispatching udp to protocol number: %d\n", cur_fragment_protocol);
+    bpf_tail_call_compat(skb, &protocols_progs, protocol_to_program(cur_fragment_protocol));
+}
+
 // A shared implementation for the runtime & prebuilt socket filter that classifies & dispatches the protocols of the connections.
 static __always_inline void protocol_dispatcher_entrypoint(struct __sk_buff *skb) {
     skb_info_t skb_info = {0};
@@ -72,7 +113,23 @@ static __always_inline void protocol_dispatcher_entrypoint(struct __sk_buff *skb
         return;
     }
 
-    // We don't process non tcp packets, nor empty tcp packets which are not tcp termination packets, nor ACK only packets.
+    // DNS is the only UDP protocol dispatched for now, so the datagrams are skipped right away when it isn't
+    // monitored, or when none of their ports is the DNS port.
+    if (is_udp(&skb_tup) && (!is_dns_monitoring_enabled() || !is_dns_port(&skb_tup))) {
+        return;
+    }
+
+    // In the cgroup attach mode, the traffic of the workloads which aren't selected is skipped.
+    if (!is_usm_cgroup_monitored(&skb_tup)) {
+        return;
+    }
+
+    if (is_udp(&skb_tup)) {
+        dispatch_udp(skb, &skb_info, &skb_tup);
+        return;
+    }
+
+    // We don't process empty tcp packets which are not tcp termination packets, nor ACK only packets.
     if (!is_tcp(&skb_tup) || is_tcp_ack(&skb_info) || (is_payload_empty(skb, &skb_info) && !is_tcp_termination(&skb_info))) {
         return;
     }
@@ -94,6 +151,9 @@ static __always_inline void protocol_dispatcher_entrypoint(struct __sk_buff *skb
     protocol_t cur_fragment_protocol = get_protocol_from_stack(stack, LAYER_APPLICATION);
     if (cur_fragment_protocol == PROTOCOL_UNKNOWN) {
         log_debug("[protocol_dispatcher_entrypoint]: %p was not classified\n", skb);
+        if (is_tls_info_enabled()) {
+            tls_record_server_hello(skb, &skb_info, &skb_tup);
+        }
         char request_fragment[CLASSIFICATION_MAX_BUFFER];
         bpf_memset(request_fragment, 0, sizeof(request_fragment));
         read_into_buffer_for_classification((char *)request_fragment, skb, skb_info.data_off);
@@ -22,6 +22,10 @@ BPF_PROG_ARRAY(protocols_progs, PROG_MAX)
 // by using tail call.
 BPF_PROG_ARRAY(dispatcher_classification_progs, DISPATCHER_PROG_MAX)
 
+// Holds the normalized tuples of the connections of the workloads selected by the cgroup attach mode, which are
+// recorded by the cgroup_skb programs attached to their cgroups.
+BPF_LRU_MAP(usm_cgroup_tuples, conn_tuple_t, __u8, 0)
+
 // A per-cpu array to share conn_tuple and skb_info between the dispatcher and the tail-calls.
 BPF_PERCPU_ARRAY_MAP(dispatcher_arguments, __u32, dispatcher_arguments_t, 1)
 
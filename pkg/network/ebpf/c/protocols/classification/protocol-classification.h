@@ -21,6 +21,7 @@
 #include "protocols/mysql/helpers.h"
 #include "protocols/redis/helpers.h"
 #include "protocols/postgres/helpers.h"
+#include "protocols/quic/helpers.h"
 #include "protocols/tls/tls.h"
 
 // Some considerations about multiple protocol classification:
@@ -64,6 +65,14 @@ static __always_inline bool is_protocol_classification_supported() {
     return val > 0;
 }
 
+// QUIC connections are only classified when HTTP/3 monitoring is enabled, as QUIC is the only UDP based
+// protocol we classify.
+static __always_inline bool is_quic_classification_enabled() {
+    __u64 val = 0;
+    LOAD_CONSTANT("quic_classification_enabled", val);
+    return val > 0;
+}
+
 // Checks if a given buffer is http, http2, gRPC.
 static __always_inline protocol_t classify_applayer_protocols(const char *buf, __u32 size) {
     if (is_http(buf, size)) {
@@ -125,8 +134,12 @@ __maybe_unused static __always_inline void protocol_classifier_entrypoint(struct
         return;
     }
 
-    // We support non empty TCP payloads for classification at the moment.
-    if (!is_tcp(&skb_tup) || is_payload_empty(skb, &skb_info)) {
+    // We support non empty TCP and UDP payloads for classification at the moment.
+    // UDP payloads are only inspected for QUIC.
+    if (!is_tcp(&skb_tup) && !(is_udp(&skb_tup) && is_quic_classification_enabled())) {
+        return;
+    }
+    if (is_payload_empty(skb, &skb_info)) {
         return;
     }
 
@@ -135,6 +148,25 @@ __maybe_unused static __always_inline void protocol_classifier_entrypoint(struct
         return;
     }
 
+    const char *buffer = &(usm_ctx->buffer.data[0]);
+    // QUIC is the only UDP based protocol we classify. Since QUIC encrypts the
+    // whole payload there is nothing else to be learnt from the socket filter.
+    // The protocol stack of a UDP flow is only created once a QUIC packet is seen,
+    // as most UDP flows are not QUIC and their entries would only be deleted when
+    // the socket is destroyed.
+    if (is_udp(&skb_tup)) {
+        if (!is_quic(buffer, usm_ctx->buffer.size)) {
+            return;
+        }
+        protocol_stack_t *protocol_stack = get_protocol_stack(&usm_ctx->tuple);
+        if (!protocol_stack) {
+            return;
+        }
+        set_protocol(protocol_stack, PROTOCOL_QUIC);
+        mark_as_fully_classified(protocol_stack);
+        return;
+    }
+
     protocol_stack_t *protocol_stack = get_protocol_stack(&usm_ctx->tuple);
     if (!protocol_stack) {
         return;
@@ -147,7 +179,6 @@ __maybe_unused static __always_inline void protocol_classifier_entrypoint(struct
     // Load information that will be later on used to route tail-calls
     init_routing_cache(usm_ctx, protocol_stack);
 
-    const char *buffer = &(usm_ctx->buffer.data[0]);
     // TLS classification
     if (is_tls(buffer, usm_ctx->buffer.size)) {
         update_protocol_information(usm_ctx, protocol_stack, PROTOCOL_TLS);
@@ -0,0 +1,24 @@
+#ifndef __DNS_DEFS_H
+#define __DNS_DEFS_H
+
+#define DNS_PORT 53
+
+// Size of the header preceding the questions of every DNS message.
+// Reference: https://www.rfc-editor.org/rfc/rfc1035#section-4.1.1
+#define DNS_HEADER_SIZE 12
+
+#define DNS_FLAG_RESPONSE 0x8000
+#define DNS_OPCODE(flags) (((flags) >> 11) & 0xf)
+#define DNS_RCODE(flags) ((flags) & 0xf)
+#define DNS_OPCODE_QUERY 0
+
+// The labels of the question name are skipped to reach the query type. The names made of more labels
+// are ignored, as the loop has to be unrolled.
+#define DNS_MAX_LABELS 16
+// The two high bits of a label length are set for a compression pointer, which never appears in a query.
+#define DNS_MAX_LABEL_SIZE 63
+
+// This controls the number of DNS transactions read from userspace at a time
+#define DNS_BATCH_SIZE 25
+
+#endif
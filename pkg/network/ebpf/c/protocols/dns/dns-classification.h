@@ -0,0 +1,34 @@
+#ifndef __DNS_CLASSIFICATION_H
+#define __DNS_CLASSIFICATION_H
+
+#include "bpf_endian.h"
+
+#include "protocols/classification/common.h"
+#include "protocols/dns/defs.h"
+#include "protocols/dns/types.h"
+
+// Checks if one of the ports of the given tuple is the DNS port. It is checked before reading the payload of a
+// datagram, so that the datagrams of the other UDP flows are skipped at the lowest cost.
+static __always_inline bool is_dns_port(conn_tuple_t *tup) {
+    return tup->sport == DNS_PORT || tup->dport == DNS_PORT;
+}
+
+// Checks if the given UDP payload represents a DNS message: one of the ports must be the DNS port, and the
+// header must hold a standard query, or its response, with a single question, as the resolvers send them.
+static __always_inline bool is_dns(conn_tuple_t *tup, const char *buf, __u32 buf_size) {
+    CHECK_PRELIMINARY_BUFFER_CONDITIONS(buf, buf_size, DNS_HEADER_SIZE);
+
+    if (!is_dns_port(tup)) {
+        return false;
+    }
+
+    const dns_header_t *header = (const dns_header_t *)buf;
+    __u16 flags = bpf_ntohs(header->flags);
+    if (DNS_OPCODE(flags) != DNS_OPCODE_QUERY) {
+        return false;
+    }
+
+    return bpf_ntohs(header->qdcount) == 1;
+}
+
+#endif
@@ -0,0 +1,104 @@
+#ifndef __DNS_PARSING_H
+#define __DNS_PARSING_H
+
+#include "bpf_builtins.h"
+#include "bpf_endian.h"
+#include "bpf_telemetry.h"
+#include "port_range.h"
+
+#include "protocols/dns/defs.h"
+#include "protocols/dns/maps.h"
+#include "protocols/dns/types.h"
+#include "protocols/dns/usm-events.h"
+
+// dns_skip_question_name moves the offset past the name of the question, made of labels prefixed by their
+// length and terminated by an empty label. Returns false if the name can't be parsed.
+static __always_inline bool dns_skip_question_name(struct __sk_buff *skb, __u32 *offset) {
+#pragma unroll
+    for (int i = 0; i < DNS_MAX_LABELS; i++) {
+        __u8 label_size = 0;
+        if (bpf_skb_load_bytes_with_telemetry(skb, *offset, &label_size, sizeof(label_size)) < 0) {
+            return false;
+        }
+        *offset += sizeof(label_size);
+        if (label_size == 0) {
+            return true;
+        }
+        if (label_size > DNS_MAX_LABEL_SIZE) {
+            return false;
+        }
+        *offset += label_size;
+    }
+
+    return false;
+}
+
+// dns_process_query records the start and the type of a query, until its response is seen.
+static __always_inline void dns_process_query(struct __sk_buff *skb, conn_tuple_t *tup, dns_transaction_key_t *key, __u32 offset) {
+    if (!dns_skip_question_name(skb, &offset)) {
+        return;
+    }
+
+    __u16 query_type = 0;
+    if (bpf_skb_load_bytes_with_telemetry(skb, offset, &query_type, sizeof(query_type)) < 0) {
+        return;
+    }
+
+    dns_transaction_batch_entry_t query;
+    bpf_memset(&query, 0, sizeof(query));
+    bpf_memcpy(&query.tup, tup, sizeof(conn_tuple_t));
+    query.query_type = bpf_ntohs(query_type);
+    query.request_started = bpf_ktime_get_ns();
+
+    log_debug("dns: query id=%d type=%d\n", bpf_ntohs(key->id), query.query_type);
+    // the query can be seen twice on the loopback interface, the first one gives the start of the transaction
+    bpf_map_update_with_telemetry(dns_in_flight, key, &query, BPF_NOEXIST);
+}
+
+static __always_inline void dns_process(struct __sk_buff *skb, conn_tuple_t *tup, __u32 offset) {
+    dns_header_t header;
+    bpf_memset(&header, 0, sizeof(header));
+    if (bpf_skb_load_bytes_with_telemetry(skb, offset, &header, sizeof(header)) < 0) {
+        return;
+    }
+    __u16 flags = bpf_ntohs(header.flags);
+
+    // the query and its response share the key, as the tuple is normalized
+    dns_transaction_key_t key;
+    bpf_memset(&key, 0, sizeof(key));
+    bpf_memcpy(&key.tup, tup, sizeof(conn_tuple_t));
+    normalize_tuple(&key.tup);
+    key.id = header.id;
+
+    if (!(flags & DNS_FLAG_RESPONSE)) {
+        dns_process_query(skb, tup, &key, offset + DNS_HEADER_SIZE);
+        return;
+    }
+
+    dns_transaction_batch_entry_t *query = bpf_map_lookup_elem(&dns_in_flight, &key);
+    if (query == NULL) {
+        return;
+    }
+    query->rcode = DNS_RCODE(flags);
+    query->response_last_seen = bpf_ktime_get_ns();
+
+    log_debug("dns: response id=%d rcode=%d\n", bpf_ntohs(key.id), query->rcode);
+    dns_batch_enqueue(query);
+    bpf_map_delete_elem(&dns_in_flight, &key);
+}
+
+SEC("socket/dns_filter")
+int socket__dns_filter(struct __sk_buff *skb) {
+    skb_info_t skb_info;
+    conn_tuple_t tup;
+
+    if (!fetch_dispatching_arguments(&tup, &skb_info)) {
+        log_debug("socket__dns_filter failed to fetch arguments for tail call\n");
+        return 0;
+    }
+
+    dns_process(skb, &tup, skb_info.data_off);
+    return 0;
+}
+
+#endif
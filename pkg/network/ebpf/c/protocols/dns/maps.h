@@ -0,0 +1,15 @@
+#ifndef __DNS_MAPS_H
+#define __DNS_MAPS_H
+
+#include "map-defs.h"
+
+#include "protocols/dns/types.h"
+
+/*
+    This map holds the queries waiting for their response, the transaction is only sent to
+    userspace once the response is received, so that its latency and response code are known.
+    The queries whose response is lost are evicted by the LRU.
+   */
+BPF_LRU_MAP(dns_in_flight, dns_transaction_key_t, dns_transaction_batch_entry_t, 0)
+
+#endif
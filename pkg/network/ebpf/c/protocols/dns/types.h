@@ -0,0 +1,34 @@
+#ifndef __DNS_TYPES_H
+#define __DNS_TYPES_H
+
+#include "conn_tuple.h"
+
+#include "protocols/dns/defs.h"
+
+typedef struct {
+    __u16 id;
+    __u16 flags;
+    __u16 qdcount;
+    __u16 ancount;
+    __u16 nscount;
+    __u16 arcount;
+} __attribute__ ((packed)) dns_header_t;
+
+// Queries waiting for their response are identified by their connection and transaction ID,
+// as a resolver can have several queries in flight on the same socket.
+typedef struct {
+    conn_tuple_t tup;
+    __u16 id;
+} dns_transaction_key_t;
+
+typedef struct {
+    // tup is the tuple of the query, from the client to the server
+    conn_tuple_t tup;
+    __u16 query_type;
+    // rcode is the response code, 0 if the query succeeded
+    __u8 rcode;
+    __u64 request_started;
+    __u64 response_last_seen;
+} dns_transaction_batch_entry_t;
+
+#endif
@@ -0,0 +1,9 @@
+#ifndef __DNS_USM_EVENTS
+#define __DNS_USM_EVENTS
+
+#include "protocols/dns/types.h"
+#include "protocols/events.h"
+
+USM_EVENTS_INIT(dns, dns_transaction_batch_entry_t, DNS_BATCH_SIZE);
+
+#endif
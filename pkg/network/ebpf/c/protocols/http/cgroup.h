@@ -0,0 +1,65 @@
+#ifndef __HTTP_CGROUP_H
+#define __HTTP_CGROUP_H
+
+#include "bpf_helpers.h"
+#include "bpf_telemetry.h"
+#include "port_range.h"
+#include "sock.h"
+
+#include "protocols/http/maps.h"
+#include "protocols/http/types.h"
+
+// The HTTP transactions are attributed to the cgroup v2 of the process owning the socket, which userspace
+// resolves to a container. bpf_get_current_cgroup_id is only available from kernel 4.18, the attribution
+// is disabled by userspace on the older kernels, so that the verifier never walks the helper call.
+static __always_inline bool http_cgroup_id_enabled() {
+    __u64 val = 0;
+    LOAD_CONSTANT("http_cgroup_id_enabled", val);
+    return val > 0;
+}
+
+// http_current_cgroup_id returns the cgroup ID of the current task, used by the TLS hooks which run in
+// the context of the process owning the connection.
+static __always_inline __u64 http_current_cgroup_id() {
+    if (!http_cgroup_id_enabled()) {
+        return 0;
+    }
+    return bpf_get_current_cgroup_id();
+}
+
+// http_record_cgroup_id is called from tcp_sendmsg to associate the connection to the cgroup of the
+// process sending data on it. Both the client and the server send data on a connection carrying HTTP,
+// so the association exists by the time the response is seen by the socket filter, which runs outside
+// of the process context. On a connection between two local processes, the last one sending data wins.
+static __always_inline void http_record_cgroup_id(struct sock *skp) {
+    if (!http_cgroup_id_enabled()) {
+        return;
+    }
+
+    conn_tuple_t t = {};
+    if (!read_conn_tuple(&t, skp, 0, CONN_TYPE_TCP)) {
+        return;
+    }
+    // same as the tuples read from the socket filter, see tup_from_ssl_ctx
+    t.netns = 0;
+    t.pid = 0;
+    normalize_tuple(&t);
+
+    __u64 cgroup_id = bpf_get_current_cgroup_id();
+    __u64 *recorded = bpf_map_lookup_elem(&http_cgroup_id_by_tuple, &t);
+    if (recorded != NULL && *recorded == cgroup_id) {
+        return;
+    }
+    bpf_map_update_with_telemetry(http_cgroup_id_by_tuple, &t, &cgroup_id, BPF_ANY);
+}
+
+// http_lookup_cgroup_id returns the cgroup ID recorded for a connection, 0 when unknown.
+static __always_inline __u64 http_lookup_cgroup_id(conn_tuple_t *t) {
+    if (!http_cgroup_id_enabled()) {
+        return 0;
+    }
+    __u64 *cgroup_id = bpf_map_lookup_elem(&http_cgroup_id_by_tuple, t);
+    return cgroup_id != NULL ? *cgroup_id : 0;
+}
+
+#endif
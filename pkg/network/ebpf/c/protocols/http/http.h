@@ -20,6 +20,8 @@ static __always_inline void http_begin_request(http_transaction_t *http, http_me
     http->request_started = bpf_ktime_get_ns();
     http->response_last_seen = 0;
     http->response_status_code = 0;
+    http->response_content_type = HTTP_CONTENT_TYPE_UNKNOWN;
+    http->response_size_class = HTTP_SIZE_CLASS_UNKNOWN;
     bpf_memcpy(&http->request_fragment, buffer, HTTP_BUFFER_SIZE);
     log_debug("http_begin_request: htx=%llx method=%d start=%llx\n", http, http->request_method, http->request_started);
 }
@@ -33,8 +35,12 @@ static __always_inline void http_begin_response(http_transaction_t *http, const
     log_debug("http_begin_response: htx=%llx status=%d\n", http, status_code);
 }
 
+static __always_inline bool http_is_response(char const *p) {
+    return (p[0] == 'H') && (p[1] == 'T') && (p[2] == 'T') && (p[3] == 'P');
+}
+
 static __always_inline void http_parse_data(char const *p, http_packet_t *packet_type, http_method_t *method) {
-    if ((p[0] == 'H') && (p[1] == 'T') && (p[2] == 'T') && (p[3] == 'P')) {
+    if (http_is_response(p)) {
         *packet_type = HTTP_RESPONSE;
     } else if ((p[0] == 'G') && (p[1] == 'E') && (p[2] == 'T') && (p[3]  == ' ') && (p[4] == '/')) {
         *packet_type = HTTP_REQUEST;
@@ -113,6 +119,33 @@ static __always_inline bool http_should_flush_previous_state(http_transaction_t
         (packet_type == HTTP_RESPONSE && http->response_status_code);
 }
 
+// http_sampled decides whether a transaction is sent to userspace, when the sampling is enabled by userspace. The
+// decision is a hash of the tuple, so that it is the same for all the transactions of a connection, including the
+// request and response halves of the transactions seen separately on localhost with NAT, which are joined in userspace.
+static __always_inline bool http_sampled(http_transaction_t *http) {
+    const __u32 zero = 0;
+    __u32 *rate = bpf_map_lookup_elem(&http_sampling_rate, &zero);
+    if (rate == NULL || *rate == 0 || *rate >= HTTP_SAMPLING_SCALE) {
+        http->sampling_rate = 0;
+        return true;
+    }
+
+    conn_tuple_t *t = &http->tup;
+    __u64 addrs = t->saddr_h ^ t->saddr_l ^ t->daddr_h ^ t->daddr_l;
+    __u32 hash = (__u32)(addrs ^ (addrs >> 32)) ^ ((__u32)t->sport << 16 | t->dport);
+    // Knuth's multiplicative hash, whose 10 upper bits are in [0, HTTP_SAMPLING_SCALE)
+    hash *= 2654435761u;
+
+    http->sampling_rate = *rate;
+    return (hash >> 22) < *rate;
+}
+
+static __always_inline void http_enqueue(http_transaction_t *http) {
+    if (http_sampled(http)) {
+        http_batch_enqueue(http);
+    }
+}
+
 // http_process is reponsible for parsing traffic and emitting events
 // representing HTTP transactions.
 //
@@ -130,7 +163,7 @@ static __always_inline bool http_process(http_transaction_t *http_stack, skb_inf
     }
 
     if (http_should_flush_previous_state(http, packet_type)) {
-        http_batch_enqueue(http);
+        http_enqueue(http);
         bpf_memcpy(http, http_stack, sizeof(http_transaction_t));
     }
 
@@ -143,12 +176,23 @@ static __always_inline bool http_process(http_transaction_t *http_stack, skb_inf
 
     http->tags |= tags;
 
+    if (http_stack->tls_version) {
+        http->tls_version = http_stack->tls_version;
+        http->tls_cipher_suite = http_stack->tls_cipher_suite;
+    }
+
+    if (http_stack->cgroup_id) {
+        http->cgroup_id = http_stack->cgroup_id;
+    } else if (!http->cgroup_id) {
+        http->cgroup_id = http_lookup_cgroup_id(&http->tup);
+    }
+
     if (http_responding(http)) {
         http->response_last_seen = bpf_ktime_get_ns();
     }
 
     if (http_closed(skb_info)) {
-        http_batch_enqueue(http);
+        http_enqueue(http);
         bpf_map_delete_elem(&http_in_flight, &http_stack->tup);
     }
 
@@ -191,7 +235,122 @@ int socket__http_filter(struct __sk_buff* skb) {
     normalize_tuple(&http.tup);
 
     read_into_buffer_skb((char *)http.request_fragment, skb, skb_info.data_off);
-    http_process(&http, &skb_info, NO_TAGS);
+    if (http_process(&http, &skb_info, NO_TAGS) && http_is_response(http.request_fragment)) {
+        // The headers of the response are parsed by a separate program, as the parsing doesn't fit in the
+        // instructions limit of the older kernels. The tail call is a no-op when the program isn't loaded.
+        bpf_tail_call_compat(skb, &protocols_progs, PROG_HTTP_RESPONSE_HEADERS);
+    }
+    return 0;
+}
+
+#define HTTP_LOWER(c) ((c) | 0x20)
+
+static __always_inline bool http_is_content_header(const char *p) {
+    return HTTP_LOWER(p[0]) == 'c' && HTTP_LOWER(p[1]) == 'o' && HTTP_LOWER(p[2]) == 'n' && HTTP_LOWER(p[3]) == 't' &&
+        HTTP_LOWER(p[4]) == 'e' && HTTP_LOWER(p[5]) == 'n' && HTTP_LOWER(p[6]) == 't' && p[7] == '-';
+}
+
+// http_classify_content_type normalizes the value of a Content-Type header. The parameters of the
+// media type, such as the charset, are ignored.
+static __always_inline __u8 http_classify_content_type(const char *v) {
+    if (HTTP_LOWER(v[0]) == 't' && HTTP_LOWER(v[1]) == 'e' && HTTP_LOWER(v[2]) == 'x' && HTTP_LOWER(v[3]) == 't' && v[4] == '/' &&
+        HTTP_LOWER(v[5]) == 'h' && HTTP_LOWER(v[6]) == 't' && HTTP_LOWER(v[7]) == 'm' && HTTP_LOWER(v[8]) == 'l') {
+        return HTTP_CONTENT_TYPE_HTML;
+    }
+
+    // application/
+    if (!(HTTP_LOWER(v[0]) == 'a' && HTTP_LOWER(v[1]) == 'p' && HTTP_LOWER(v[2]) == 'p' && HTTP_LOWER(v[3]) == 'l' &&
+          HTTP_LOWER(v[4]) == 'i' && HTTP_LOWER(v[5]) == 'c' && HTTP_LOWER(v[6]) == 'a' && HTTP_LOWER(v[7]) == 't' &&
+          HTTP_LOWER(v[8]) == 'i' && HTTP_LOWER(v[9]) == 'o' && HTTP_LOWER(v[10]) == 'n' && v[11] == '/')) {
+        return HTTP_CONTENT_TYPE_UNKNOWN;
+    }
+    const char *subtype = v + 12;
+    if (HTTP_LOWER(subtype[0]) == 'j' && HTTP_LOWER(subtype[1]) == 's' && HTTP_LOWER(subtype[2]) == 'o' && HTTP_LOWER(subtype[3]) == 'n') {
+        return HTTP_CONTENT_TYPE_JSON;
+    }
+    // application/grpc also matches its variants, such as application/grpc+proto
+    if (HTTP_LOWER(subtype[0]) == 'g' && HTTP_LOWER(subtype[1]) == 'r' && HTTP_LOWER(subtype[2]) == 'p' && HTTP_LOWER(subtype[3]) == 'c') {
+        return HTTP_CONTENT_TYPE_GRPC;
+    }
+    if (HTTP_LOWER(subtype[0]) == 'o' && HTTP_LOWER(subtype[1]) == 'c' && HTTP_LOWER(subtype[2]) == 't' && HTTP_LOWER(subtype[3]) == 'e') {
+        return HTTP_CONTENT_TYPE_OCTET_STREAM;
+    }
+    return HTTP_CONTENT_TYPE_UNKNOWN;
+}
+
+// http_classify_content_length parses the value of a Content-Length header into its size class
+static __always_inline __u8 http_classify_content_length(const char *v) {
+    __u64 length = 0;
+    bool found = false;
+#pragma unroll
+    for (int i = 0; i < HTTP_CONTENT_LENGTH_MAX_DIGITS; i++) {
+        if (v[i] < '0' || v[i] > '9') {
+            break;
+        }
+        length = length * 10 + (v[i] - '0');
+        found = true;
+    }
+
+    if (!found) {
+        return HTTP_SIZE_CLASS_UNKNOWN;
+    }
+    if (length < 1024) {
+        return HTTP_SIZE_CLASS_SMALL;
+    }
+    if (length < 100 * 1024) {
+        return HTTP_SIZE_CLASS_MEDIUM;
+    }
+    if (length < 1024 * 1024) {
+        return HTTP_SIZE_CLASS_LARGE;
+    }
+    return HTTP_SIZE_CLASS_HUGE;
+}
+
+// http_parse_response_headers looks for the Content-Type and Content-Length headers in the response fragment.
+// The headers which don't entirely fit in the fragment are ignored.
+static __always_inline void http_parse_response_headers(http_transaction_t *http, const char *buffer) {
+#pragma unroll
+    for (int i = HTTP_STATUS_OFFSET; i < HTTP_BUFFER_SIZE - HTTP_CONTENT_TYPE_HEADER_SIZE; i++) {
+        if (buffer[i] != '\n' || !http_is_content_header(&buffer[i + 1])) {
+            continue;
+        }
+
+        const char *name = &buffer[i + 1 + HTTP_CONTENT_HEADER_PREFIX_SIZE];
+        // the value may be preceded by a space
+        if (i + HTTP_CONTENT_TYPE_HEADER_SIZE + 1 + HTTP_CONTENT_TYPE_VALUE_SIZE <= HTTP_BUFFER_SIZE &&
+            HTTP_LOWER(name[0]) == 't' && HTTP_LOWER(name[1]) == 'y' && HTTP_LOWER(name[2]) == 'p' && HTTP_LOWER(name[3]) == 'e' && name[4] == ':') {
+            const char *value = &buffer[i + HTTP_CONTENT_TYPE_HEADER_SIZE];
+            http->response_content_type = value[0] == ' ' ? http_classify_content_type(value + 1) : http_classify_content_type(value);
+        } else if (i + HTTP_CONTENT_LENGTH_HEADER_SIZE + 1 + HTTP_CONTENT_LENGTH_MAX_DIGITS <= HTTP_BUFFER_SIZE &&
+            HTTP_LOWER(name[0]) == 'l' && HTTP_LOWER(name[1]) == 'e' && HTTP_LOWER(name[2]) == 'n' && HTTP_LOWER(name[3]) == 'g' &&
+            HTTP_LOWER(name[4]) == 't' && HTTP_LOWER(name[5]) == 'h' && name[6] == ':') {
+            const char *value = &buffer[i + HTTP_CONTENT_LENGTH_HEADER_SIZE];
+            http->response_size_class = value[0] == ' ' ? http_classify_content_length(value + 1) : http_classify_content_length(value);
+        }
+    }
+}
+
+// socket__http_response_headers is tail called by socket__http_filter on the beginning of the responses, to
+// record the content type and the size class of their body. It requires kernels >= 5.2.
+SEC("socket/http_response_headers")
+int socket__http_response_headers(struct __sk_buff* skb) {
+    skb_info_t skb_info;
+    conn_tuple_t tup;
+    if (!fetch_dispatching_arguments(&tup, &skb_info)) {
+        log_debug("http_response_headers failed to fetch arguments for tail call\n");
+        return 0;
+    }
+    normalize_tuple(&tup);
+
+    http_transaction_t *http = bpf_map_lookup_elem(&http_in_flight, &tup);
+    if (!http_responding(http)) {
+        return 0;
+    }
+
+    char buffer[HTTP_BUFFER_SIZE];
+    bpf_memset(buffer, 0, HTTP_BUFFER_SIZE);
+    read_into_buffer_skb(buffer, skb, skb_info.data_off);
+    http_parse_response_headers(http, buffer);
     return 0;
 }
 
@@ -10,6 +10,22 @@
 /* This map is used to keep track of in-flight HTTP transactions for each TCP connection */
 BPF_LRU_MAP(http_in_flight, conn_tuple_t, http_transaction_t, 0)
 
+/* This map associates TCP connections to the cgroup ID of the process sending data on them, to attribute the HTTP
+   transactions seen by the socket filter, which runs outside of the process context, to a container */
+BPF_LRU_MAP(http_cgroup_id_by_tuple, conn_tuple_t, __u64, 0)
+
+/* This map associates TLS connections to the version and cipher suite they negotiated, which are read from the
+   ServerHello by the socket filter, and attached to the HTTP transactions decrypted by the TLS hooks */
+BPF_LRU_MAP(tls_info_by_tuple, conn_tuple_t, tls_info_t, 0)
+
+/* This map holds the sampling rate of the HTTP transactions, written by userspace when the transactions are dropped on
+   their way to userspace. A rate of 0 disables the sampling */
+BPF_ARRAY_MAP(http_sampling_rate, __u32, 1)
+
+/* This map holds the PIDs of the sidecar proxies of a service mesh, such as the Envoy sidecars injected by Istio.
+   The transactions they decrypt are the mTLS traffic between the meshed workloads */
+BPF_HASH_MAP(service_mesh_pids, __u32, __u8, 1024)
+
 BPF_LRU_MAP(ssl_sock_by_ctx, void *, ssl_sock_t, 1)
 
 BPF_LRU_MAP(ssl_read_args, u64, ssl_read_args_t, 1024)
@@ -22,7 +38,7 @@ BPF_LRU_MAP(ssl_write_ex_args, u64, ssl_write_ex_args_t, 1024)
 
 BPF_LRU_MAP(bio_new_socket_args, __u64, __u32, 1024)
 
-BPF_LRU_MAP(fd_by_ssl_bio, __u32, void *, 1024)
+BPF_LRU_MAP(fd_by_ssl_bio, ssl_bio_key_t, __u32, 1024)
 
 BPF_LRU_MAP(ssl_ctx_by_pid_tgid, __u64, void *, 1024)
 
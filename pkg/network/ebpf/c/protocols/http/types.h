@@ -8,10 +8,22 @@
 // This controls the number of HTTP transactions read from userspace at a time
 #define HTTP_BATCH_SIZE 15
 
+// Scale of the sampling rate of the HTTP transactions, a rate of HTTP_SAMPLING_SCALE keeping every transaction
+#define HTTP_SAMPLING_SCALE 1024
+
 // HTTP/1.1 XXX
 // _________^
 #define HTTP_STATUS_OFFSET 9
 
+// Sizes of the "content-" header names prefix, and of the "\ncontent-type:" and
+// "\ncontent-length:" header lines prefixes searched in the response fragment
+#define HTTP_CONTENT_HEADER_PREFIX_SIZE 8
+#define HTTP_CONTENT_TYPE_HEADER_SIZE 14
+#define HTTP_CONTENT_LENGTH_HEADER_SIZE 16
+// "application/json" is the longest prefix needed to classify a content type
+#define HTTP_CONTENT_TYPE_VALUE_SIZE 16
+#define HTTP_CONTENT_LENGTH_MAX_DIGITS 10
+
 // Pseudo TCP sequence number representing a segment with a FIN or RST flags set
 // For more information see `http_seen_before`
 #define HTTP_TERMINATING 0xFFFFFFFF
@@ -39,12 +51,40 @@ typedef enum
     HTTP_PATCH
 } http_method_t;
 
+// Normalized value of the Content-Type header of a response
+typedef enum
+{
+    HTTP_CONTENT_TYPE_UNKNOWN,
+    HTTP_CONTENT_TYPE_JSON,
+    HTTP_CONTENT_TYPE_HTML,
+    HTTP_CONTENT_TYPE_GRPC,
+    HTTP_CONTENT_TYPE_OCTET_STREAM
+} http_content_type_t;
+
+// Bucket of the Content-Length header of a response
+typedef enum
+{
+    HTTP_SIZE_CLASS_UNKNOWN,
+    // less than 1KiB
+    HTTP_SIZE_CLASS_SMALL,
+    // less than 100KiB
+    HTTP_SIZE_CLASS_MEDIUM,
+    // less than 1MiB
+    HTTP_SIZE_CLASS_LARGE,
+    HTTP_SIZE_CLASS_HUGE
+} http_size_class_t;
+
 // HTTP transaction information associated to a certain socket (tuple_t)
 typedef struct {
     conn_tuple_t tup;
     __u64 request_started;
     __u8  request_method;
+    __u8  response_content_type;
     __u16 response_status_code;
+    __u8  response_size_class;
+    // number of transactions kept out of HTTP_SAMPLING_SCALE when the transaction was sent to userspace, 0 when the
+    // transactions weren't sampled
+    __u16 sampling_rate;
     __u64 response_last_seen;
     char request_fragment[HTTP_BUFFER_SIZE] __attribute__ ((aligned (8)));
 
@@ -52,9 +92,22 @@ typedef struct {
     // we populate it with the TCP seq number of the request and then the response segments
     __u32 tcp_seq;
 
+    // TLS version and cipher suite negotiated by the connection, 0 when unknown or not encrypted
+    __u16 tls_version;
+    __u16 tls_cipher_suite;
+
     __u64 tags;
+
+    // cgroup v2 ID of the process owning the socket, 0 when unknown
+    __u64 cgroup_id;
 } http_transaction_t;
 
+// TLS parameters negotiated by a connection, as read from its ServerHello
+typedef struct {
+    __u16 version;
+    __u16 cipher_suite;
+} tls_info_t;
+
 // OpenSSL types
 typedef struct {
     void *ctx;
@@ -83,6 +136,13 @@ typedef struct {
     __u32 fd;
 } ssl_sock_t;
 
+// BIO pointers are only unique within a process, the key holds the tgid
+// so that the entries of exited processes can be purged from userspace
+typedef struct {
+    __u64 tgid;
+    void *bio;
+} ssl_bio_key_t;
+
 #define LIB_PATH_MAX_SIZE 120
 
 typedef struct {
@@ -24,12 +24,23 @@
 // The maximum index which may be in the static table.
 #define MAX_STATIC_TABLE_INDEX 61
 
+// HTTP2_MAX_VAR_INT_CONTINUATION_BYTES is the maximum number of continuation bytes of a variable length integer we skip.
+#define HTTP2_MAX_VAR_INT_CONTINUATION_BYTES 4
+
 // The flag which will be sent in the data/header frame that indicates end of stream.
 #define HTTP2_END_OF_STREAM 0x1
 
 // Http2 max batch size.
 #define HTTP2_BATCH_SIZE 10
 
+// MAX_4_BITS represents the maximum number that can be represented with 4 bits or less.
+// 1 << 4 - 1
+#define MAX_4_BITS 15
+
+// MAX_5_BITS represents the maximum number that can be represented with 5 bits or less.
+// 1 << 5 - 1
+#define MAX_5_BITS 31
+
 // MAX_6_BITS represents the maximum number that can be represented with 6 bits or less.
 // 1 << 6 - 1
 #define MAX_6_BITS 63
@@ -66,8 +77,23 @@ typedef struct {
 typedef struct {
     char buffer[HTTP2_MAX_PATH_LEN] __attribute__ ((aligned (8)));
     __u8 string_len;
+    // is_raw is true when the value isn't Huffman encoded
+    bool is_raw;
+    // is_truncated is true when only the beginning of the value is in the buffer
+    bool is_truncated;
 } dynamic_table_entry_t;
 
+// dynamic_table_state_t holds the HPACK dynamic table bookkeeping of a connection. The entries of the dynamic
+// table are indexed by their insertion order, so that the HPACK index of an entry, which is relative to the most
+// recent insertion, doesn't change when older entries are evicted.
+typedef struct {
+    // next_index is the internal index of the next entry inserted in the dynamic table
+    __u64 next_index;
+    // oldest_index is the internal index of the oldest entry which may still be in the dynamic table. Entries
+    // are evicted all at once by a dynamic table size update to zero.
+    __u64 oldest_index;
+} dynamic_table_state_t;
+
 typedef struct {
     __u64 index;
     conn_tuple_t tup;
@@ -87,6 +113,10 @@ typedef struct {
     __u8 request_method;
     __u8 path_size;
     bool request_end_of_stream;
+    // request_path_raw is true when the path isn't Huffman encoded
+    bool request_path_raw;
+    // request_path_truncated is true when the path is longer than HTTP2_MAX_PATH_LEN
+    bool request_path_truncated;
 
     __u8 request_path[HTTP2_MAX_PATH_LEN] __attribute__ ((aligned (8)));
 } http2_stream_t;
@@ -100,6 +130,8 @@ typedef enum {
     kStaticHeader  = 0,
     kExistingDynamicHeader = 1,
     kNewDynamicHeader = 2,
+    // kLiteralHeader is a literal header field without indexing or never indexed, which isn't added to the dynamic table.
+    kLiteralHeader = 3,
 } __attribute__ ((packed)) http2_header_type_t;
 
 typedef struct {
@@ -107,6 +139,7 @@ typedef struct {
     __u32 new_dynamic_value_offset;
     __u32 new_dynamic_value_size;
     http2_header_type_t type;
+    bool is_huffman_encoded;
 } http2_header_t;
 
 typedef struct {
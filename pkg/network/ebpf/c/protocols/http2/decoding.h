@@ -54,7 +54,7 @@ static __always_inline bool read_var_int_with_given_current_char(struct __sk_buf
         bpf_skb_load_bytes(skb, skb_info->data_off, &next_char, sizeof(next_char));
         if ((next_char & 128 ) == 0) {
             skb_info->data_off++;
-            *out = current_char_as_number + next_char & 127;
+            *out = current_char_as_number + (next_char & 127);
             return true;
         }
     }
@@ -80,20 +80,53 @@ static __always_inline bool read_var_int(struct __sk_buff *skb, skb_info_t *skb_
     return read_var_int_with_given_current_char(skb, skb_info, current_char_as_number, max_number_for_bits, out);
 }
 
-//get_dynamic_counter returns the current dynamic counter by the conn tup.
-static __always_inline __u64* get_dynamic_counter(conn_tuple_t *tup) {
-    // global counter is the counter which help us with the calc of the index in our internal hpack dynamic table
-    __u64 *counter_ptr = bpf_map_lookup_elem(&http2_dynamic_counter_table, tup);
-    if (counter_ptr != NULL) {
-        return counter_ptr;
+// read_string_length reads the length of an HPACK string literal, and whether the string is Huffman encoded.
+// https://httpwg.org/specs/rfc7541.html#rfc.section.5.2
+static __always_inline bool read_string_length(struct __sk_buff *skb, skb_info_t *skb_info, __u8 *out, bool *is_huffman_encoded) {
+    if (skb_info->data_off > skb->len) {
+        return false;
+    }
+    __u8 current_char_as_number = 0;
+    bpf_skb_load_bytes(skb, skb_info->data_off, &current_char_as_number, sizeof(current_char_as_number));
+    skb_info->data_off++;
+
+    *is_huffman_encoded = (current_char_as_number & 128) != 0;
+    return read_var_int_with_given_current_char(skb, skb_info, current_char_as_number, MAX_7_BITS, out);
+}
+
+// skip_var_int skips the continuation bytes of a variable length integer whose prefix is the given character.
+static __always_inline void skip_var_int(struct __sk_buff *skb, skb_info_t *skb_info, __u8 current_char_as_number, __u8 max_number_for_bits) {
+    if ((current_char_as_number & max_number_for_bits) < max_number_for_bits) {
+        return;
     }
-    __u64 counter = 0;
-    bpf_map_update_elem(&http2_dynamic_counter_table, tup, &counter, BPF_ANY);
+
+    __u8 next_char = 0;
+#pragma unroll (HTTP2_MAX_VAR_INT_CONTINUATION_BYTES)
+    for (__u8 i = 0; i < HTTP2_MAX_VAR_INT_CONTINUATION_BYTES; ++i) {
+        if (skb_info->data_off >= skb->len) {
+            return;
+        }
+        bpf_skb_load_bytes(skb, skb_info->data_off, &next_char, sizeof(next_char));
+        skb_info->data_off++;
+        if ((next_char & 128) == 0) {
+            return;
+        }
+    }
+}
+
+// get_dynamic_table_state returns the state of the dynamic table of the connection.
+static __always_inline dynamic_table_state_t* get_dynamic_table_state(conn_tuple_t *tup) {
+    dynamic_table_state_t *state_ptr = bpf_map_lookup_elem(&http2_dynamic_counter_table, tup);
+    if (state_ptr != NULL) {
+        return state_ptr;
+    }
+    dynamic_table_state_t state = {};
+    bpf_map_update_elem(&http2_dynamic_counter_table, tup, &state, BPF_ANY);
     return bpf_map_lookup_elem(&http2_dynamic_counter_table, tup);
 }
 
 // parse_field_indexed is handling the case which the header frame is part of the static table.
-static __always_inline void parse_field_indexed(dynamic_table_index_t *dynamic_index, http2_header_t *headers_to_process, __u8 index, __u64 global_dynamic_counter, __u8 *interesting_headers_counter){
+static __always_inline void parse_field_indexed(dynamic_table_index_t *dynamic_index, http2_header_t *headers_to_process, __u8 index, dynamic_table_state_t *state, __u8 *interesting_headers_counter){
     if (headers_to_process == NULL) {
         return;
     }
@@ -110,7 +143,12 @@ static __always_inline void parse_field_indexed(dynamic_table_index_t *dynamic_i
 
     // we change the index to fit our internal dynamic table implementation index.
     // the index is starting from 1 so we decrease 62 in order to be equal to the given index.
-    dynamic_index->index = global_dynamic_counter - (index - MAX_STATIC_TABLE_INDEX);
+    const __u64 relative_index = index - MAX_STATIC_TABLE_INDEX;
+    if (relative_index > state->next_index - state->oldest_index) {
+        // the entry was evicted, or was inserted before we started to track the connection.
+        return;
+    }
+    dynamic_index->index = state->next_index - relative_index;
 
     if (bpf_map_lookup_elem(&http2_dynamic_table, dynamic_index) == NULL) {
         return;
@@ -124,37 +162,50 @@ static __always_inline void parse_field_indexed(dynamic_table_index_t *dynamic_i
 
 READ_INTO_BUFFER(path, HTTP2_MAX_PATH_LEN, BLK_SIZE)
 
-// parse_field_literal handling the case when the key is part of the static table and the value is a dynamic string
-// which will be stored in the dynamic table.
-static __always_inline bool parse_field_literal(struct __sk_buff *skb, skb_info_t *skb_info, http2_header_t *headers_to_process, __u8 index, __u64 global_dynamic_counter, __u8 *interesting_headers_counter){
+// parse_field_literal handling the case when the value is a literal string. When is_indexing is set, the header
+// is inserted into the dynamic table (https://httpwg.org/specs/rfc7541.html#rfc.section.6.2.1), otherwise it is
+// a literal without indexing or never indexed (https://httpwg.org/specs/rfc7541.html#rfc.section.6.2.2).
+static __always_inline bool parse_field_literal(struct __sk_buff *skb, skb_info_t *skb_info, dynamic_table_index_t *dynamic_index, http2_header_t *headers_to_process, __u8 index, dynamic_table_state_t *state, bool is_indexing, __u8 *interesting_headers_counter){
+    __u64 new_dynamic_index = 0;
+    if (is_indexing) {
+        new_dynamic_index = state->next_index;
+        state->next_index++;
+    }
+
     __u8 str_len = 0;
-    if (!read_var_int(skb, skb_info, MAX_6_BITS, &str_len)) {
+    bool is_huffman_encoded = false;
+    if (!read_string_length(skb, skb_info, &str_len, &is_huffman_encoded)) {
         return false;
     }
-    // The key is new and inserted into the dynamic table. So we are skipping the new value.
-
+    // The key is new, so we are skipping it and its value.
     if (index == 0) {
         skb_info->data_off += str_len;
         str_len = 0;
-        if (!read_var_int(skb, skb_info, MAX_6_BITS, &str_len)) {
+        if (!read_string_length(skb, skb_info, &str_len, &is_huffman_encoded)) {
             return false;
         }
-        goto end;
+        goto not_interesting;
     }
-    if (str_len > HTTP2_MAX_PATH_LEN || index != kIndexPath || headers_to_process == NULL){
-        goto end;
+    if ((index != kEmptyPath && index != kIndexPath) || headers_to_process == NULL){
+        goto not_interesting;
     }
-
-    __u32 final_size = str_len < HTTP2_MAX_PATH_LEN ? str_len : HTTP2_MAX_PATH_LEN;
-    if (skb_info->data_off + final_size > skb->len) {
-        goto end;
+    if (skb_info->data_off >= skb->len) {
+        goto not_interesting;
     }
 
-    headers_to_process->index = global_dynamic_counter - 1;
-    headers_to_process->type = kNewDynamicHeader;
+    headers_to_process->index = new_dynamic_index;
+    headers_to_process->type = is_indexing ? kNewDynamicHeader : kLiteralHeader;
     headers_to_process->new_dynamic_value_offset = skb_info->data_off;
     headers_to_process->new_dynamic_value_size = str_len;
+    headers_to_process->is_huffman_encoded = is_huffman_encoded;
     (*interesting_headers_counter)++;
+    goto end;
+not_interesting:
+    if (is_indexing) {
+        // removing a stale entry of the slot, as the header is not stored.
+        dynamic_index->index = new_dynamic_index;
+        bpf_map_delete_elem(&http2_dynamic_table, dynamic_index);
+    }
 end:
     skb_info->data_off += str_len;
     return true;
@@ -169,11 +220,12 @@ static __always_inline __u8 filter_relevant_headers(struct __sk_buff *skb, skb_i
     const __u32 end = frame_end < skb->len + 1 ? frame_end : skb->len + 1;
     bool is_literal = false;
     bool is_indexed = false;
+    bool is_size_update = false;
     __u8 max_bits = 0;
     __u8 index = 0;
 
-    __u64 *global_dynamic_counter = get_dynamic_counter(tup);
-    if (global_dynamic_counter == NULL) {
+    dynamic_table_state_t *dynamic_table_state = get_dynamic_table_state(tup);
+    if (dynamic_table_state == NULL) {
         return 0;
     }
 
@@ -187,13 +239,28 @@ static __always_inline __u8 filter_relevant_headers(struct __sk_buff *skb, skb_i
 
         is_indexed = (current_ch&128) != 0;
         is_literal = (current_ch&192) == 64;
+        is_size_update = (current_ch&224) == 32;
+
+        if (is_size_update) {
+            // 6.3 Dynamic Table Size Update
+            // top three bits are 001
+            // https://httpwg.org/specs/rfc7541.html#rfc.section.6.3
+            // We don't track the size of the entries, so we only handle the eviction of all the entries, which is
+            // done by an update to zero.
+            if ((current_ch & MAX_5_BITS) == 0) {
+                dynamic_table_state->oldest_index = dynamic_table_state->next_index;
+            }
+            skip_var_int(skb, skb_info, current_ch, MAX_5_BITS);
+            continue;
+        }
 
         if (is_indexed) {
             max_bits = MAX_7_BITS;
         } else if (is_literal) {
             max_bits = MAX_6_BITS;
         } else {
-            continue;
+            // literal without indexing (0000) or never indexed (0001).
+            max_bits = MAX_4_BITS;
         }
 
         index = 0;
@@ -210,15 +277,9 @@ static __always_inline __u8 filter_relevant_headers(struct __sk_buff *skb, skb_i
             // Indexed representation.
             // MSB bit set.
             // https://httpwg.org/specs/rfc7541.html#rfc.section.6.1
-            parse_field_indexed(dynamic_index, current_header, index, *global_dynamic_counter, &interesting_headers);
-        } else {
-            (*global_dynamic_counter)++;
-            // 6.2.1 Literal Header Field with Incremental Indexing
-            // top two bits are 11
-            // https://httpwg.org/specs/rfc7541.html#rfc.section.6.2.1
-            if (!parse_field_literal(skb, skb_info, current_header, index, *global_dynamic_counter, &interesting_headers)) {
-                break;
-            }
+            parse_field_indexed(dynamic_index, current_header, index, dynamic_table_state, &interesting_headers);
+        } else if (!parse_field_literal(skb, skb_info, dynamic_index, current_header, index, dynamic_table_state, is_literal, &interesting_headers)) {
+            break;
         }
     }
 
@@ -260,14 +321,23 @@ static __always_inline void process_headers(struct __sk_buff *skb, dynamic_table
                 break;
             }
             current_stream->path_size = dynamic_value->string_len;
+            current_stream->request_path_raw = dynamic_value->is_raw;
+            current_stream->request_path_truncated = dynamic_value->is_truncated;
             bpf_memcpy(current_stream->request_path, dynamic_value->buffer, HTTP2_MAX_PATH_LEN);
         } else {
-            dynamic_value.string_len = current_header->new_dynamic_value_size;
+            // values longer than the buffer are truncated, the user mode decodes what it can of them.
+            dynamic_value.is_truncated = current_header->new_dynamic_value_size > HTTP2_MAX_PATH_LEN;
+            dynamic_value.string_len = dynamic_value.is_truncated ? HTTP2_MAX_PATH_LEN : current_header->new_dynamic_value_size;
+            dynamic_value.is_raw = !current_header->is_huffman_encoded;
 
-            // create the new dynamic value which will be added to the internal table.
             read_into_buffer_path(dynamic_value.buffer, skb, current_header->new_dynamic_value_offset);
-            bpf_map_update_elem(&http2_dynamic_table, dynamic_index, &dynamic_value, BPF_ANY);
-            current_stream->path_size = current_header->new_dynamic_value_size;
+            if (current_header->type == kNewDynamicHeader) {
+                // create the new dynamic value which will be added to the internal table.
+                bpf_map_update_elem(&http2_dynamic_table, dynamic_index, &dynamic_value, BPF_ANY);
+            }
+            current_stream->path_size = dynamic_value.string_len;
+            current_stream->request_path_raw = dynamic_value.is_raw;
+            current_stream->request_path_truncated = dynamic_value.is_truncated;
             bpf_memcpy(current_stream->request_path, dynamic_value.buffer, HTTP2_MAX_PATH_LEN);
         }
     }
@@ -8,10 +8,10 @@ BPF_HASH_MAP(http2_static_table, u8, static_table_entry_t, 20)
    tcp_con and it is value is the buffer which contains the dynamic string. */
 BPF_LRU_MAP(http2_dynamic_table, dynamic_table_index_t, dynamic_table_entry_t, 1024)
 
-/* http2_dynamic_counter_table is a map that holding the current dynamic values amount, in order to use for the
-   internal calculation of the internal index in the http2_dynamic_table, it is hold by conn_tup to support different
-   clients and the value is the current counter. */
-BPF_LRU_MAP(http2_dynamic_counter_table, conn_tuple_t, u64, 1024)
+/* http2_dynamic_counter_table is a map holding the state of the dynamic table of each connection, in order to use for
+   the internal calculation of the internal index in the http2_dynamic_table, it is hold by conn_tup to support different
+   clients and the value holds the index of the next inserted entry and of the oldest entry which wasn't evicted. */
+BPF_LRU_MAP(http2_dynamic_counter_table, conn_tuple_t, dynamic_table_state_t, 1024)
 
 /* This map is used to keep track of in-flight HTTP2 transactions for each TCP connection */
 BPF_LRU_MAP(http2_in_flight, http2_stream_key_t, http2_stream_t, 0)
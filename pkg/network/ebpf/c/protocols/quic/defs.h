@@ -0,0 +1,21 @@
+#ifndef __QUIC_DEFS_H
+#define __QUIC_DEFS_H
+
+// https://www.rfc-editor.org/rfc/rfc9000.html#name-long-header-packets
+// Header form (1 bit) + fixed bit (1 bit) + version (32 bits) + DCID length (8 bits)
+#define QUIC_MIN_LONG_HEADER_SIZE 6
+
+#define QUIC_HEADER_FORM_LONG 0x80
+#define QUIC_FIXED_BIT 0x40
+
+// Connection IDs are limited to 20 bytes in QUIC v1 and v2
+#define QUIC_MAX_CID_LENGTH 20
+
+#define QUIC_VERSION_NEGOTIATION 0x00000000
+#define QUIC_VERSION_1 0x00000001
+#define QUIC_VERSION_2 0x6b3343cf
+// Draft versions are encoded as 0xff0000XX
+#define QUIC_VERSION_DRAFT_MASK 0xffffff00
+#define QUIC_VERSION_DRAFT_PREFIX 0xff000000
+
+#endif
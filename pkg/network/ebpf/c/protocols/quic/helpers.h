@@ -0,0 +1,41 @@
+#ifndef __QUIC_HELPERS_H
+#define __QUIC_HELPERS_H
+
+#include "protocols/classification/common.h"
+#include "protocols/quic/defs.h"
+
+static __always_inline bool is_valid_quic_version(__u32 version) {
+    return (version == QUIC_VERSION_1) ||
+        (version == QUIC_VERSION_2) ||
+        (version == QUIC_VERSION_NEGOTIATION) ||
+        ((version & QUIC_VERSION_DRAFT_MASK) == QUIC_VERSION_DRAFT_PREFIX);
+}
+
+// Checks if the given UDP payload starts with a QUIC long header packet.
+// Short header packets (1-RTT) carry no version and are indistinguishable from
+// random bytes, hence we rely on catching the handshake of a connection.
+// https://www.rfc-editor.org/rfc/rfc8999.html#name-long-header
+static __always_inline bool is_quic(const char *buf, __u32 buf_size) {
+    CHECK_PRELIMINARY_BUFFER_CONDITIONS(buf, buf_size, QUIC_MIN_LONG_HEADER_SIZE);
+
+    __u8 first_byte = buf[0];
+    if ((first_byte & QUIC_HEADER_FORM_LONG) == 0) {
+        return false;
+    }
+
+    // The version is encoded in network byte order right after the first byte
+    __u32 version = ((__u32)(__u8)buf[1] << 24) | ((__u32)(__u8)buf[2] << 16) | ((__u32)(__u8)buf[3] << 8) | (__u32)(__u8)buf[4];
+    if (!is_valid_quic_version(version)) {
+        return false;
+    }
+
+    // The fixed bit must be set for every version but the version negotiation packet.
+    if (version != QUIC_VERSION_NEGOTIATION && (first_byte & QUIC_FIXED_BIT) == 0) {
+        return false;
+    }
+
+    __u8 dcid_length = buf[5];
+    return dcid_length <= QUIC_MAX_CID_LENGTH;
+}
+
+#endif
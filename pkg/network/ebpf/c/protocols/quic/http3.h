@@ -0,0 +1,459 @@
+#ifndef __HTTP3_H
+#define __HTTP3_H
+
+#include "bpf_builtins.h"
+#include "bpf_telemetry.h"
+
+#include "protocols/http/http.h"
+#include "protocols/http/types.h"
+#include "protocols/quic/maps.h"
+#include "protocols/quic/qpack.h"
+#include "protocols/quic/types.h"
+#include "protocols/tls/tags-types.h"
+
+// Maximum number of headers inspected when looking for pseudo-headers.
+// Pseudo-headers must precede regular headers, so we don't need to go further.
+#define HTTP3_MAX_HEADERS 8
+
+// The status code is the only header we decode out of QPACK.
+// nghttp3 tokens match the index of the header name in the QPACK static table.
+// https://www.rfc-editor.org/rfc/rfc9204.html#name-static-table-2
+#define NGHTTP3_QPACK_TOKEN__STATUS 24
+#define NGHTTP3_QPACK_DECODE_FLAG_EMIT 0x01
+
+#define HTTP3_METHOD_MAX_SIZE 7
+#define HTTP3_STATUS_SIZE 3
+// Leave room for the method and its trailing space within the request fragment.
+#define HTTP3_PATH_MAX_SIZE (HTTP_BUFFER_SIZE - 16)
+
+_Static_assert(HTTP3_PATH_MAX_SIZE > 0, "HTTP_BUFFER_SIZE is too small for HTTP/3 requests.");
+
+static __always_inline bool http3_is_pseudo_header(nghttp3_nv_t *nv, const char *name, __u64 name_size) {
+    if (nv->namelen != name_size) {
+        return false;
+    }
+
+    char buf[8] = {0};
+    if (bpf_probe_read_user(buf, name_size & 0x7, nv->name) < 0) {
+        return false;
+    }
+
+    return bpf_memcmp(buf, name, name_size & 0x7) == 0;
+}
+
+#define HTTP3_METHOD_MATCHES(buf, size, name) ((size) == sizeof(name)-1 && bpf_memcmp((buf), (name), sizeof(name)-1) == 0)
+
+static __always_inline http_method_t http3_parse_method(const __u8 *value, __u64 value_size) {
+    if (value_size == 0 || value_size > HTTP3_METHOD_MAX_SIZE) {
+        return HTTP_METHOD_UNKNOWN;
+    }
+
+    char m[HTTP3_METHOD_MAX_SIZE+1] = {0};
+    __u64 size = value_size & 0x7;
+    if (bpf_probe_read_user(m, size, value) < 0) {
+        return HTTP_METHOD_UNKNOWN;
+    }
+
+    if (HTTP3_METHOD_MATCHES(m, size, "GET")) {
+        return HTTP_GET;
+    } else if (HTTP3_METHOD_MATCHES(m, size, "POST")) {
+        return HTTP_POST;
+    } else if (HTTP3_METHOD_MATCHES(m, size, "PUT")) {
+        return HTTP_PUT;
+    } else if (HTTP3_METHOD_MATCHES(m, size, "DELETE")) {
+        return HTTP_DELETE;
+    } else if (HTTP3_METHOD_MATCHES(m, size, "HEAD")) {
+        return HTTP_HEAD;
+    } else if (HTTP3_METHOD_MATCHES(m, size, "OPTIONS")) {
+        return HTTP_OPTIONS;
+    } else if (HTTP3_METHOD_MATCHES(m, size, "PATCH")) {
+        return HTTP_PATCH;
+    }
+
+    return HTTP_METHOD_UNKNOWN;
+}
+
+// http3_write_method writes the request method into the fragment and
+// returns the offset at which the path should be written, so the userspace
+// program can parse HTTP/3 requests as if they were HTTP/1.1 ones.
+static __always_inline __u32 http3_write_method(char *fragment, http_method_t method) {
+    switch (method) {
+    case HTTP_GET:
+        bpf_memcpy(fragment, "GET ", 4);
+        return 4;
+    case HTTP_POST:
+        bpf_memcpy(fragment, "POST ", 5);
+        return 5;
+    case HTTP_PUT:
+        bpf_memcpy(fragment, "PUT ", 4);
+        return 4;
+    case HTTP_DELETE:
+        bpf_memcpy(fragment, "DELETE ", 7);
+        return 7;
+    case HTTP_HEAD:
+        bpf_memcpy(fragment, "HEAD ", 5);
+        return 5;
+    case HTTP_OPTIONS:
+        bpf_memcpy(fragment, "OPTIONS ", 8);
+        return 8;
+    case HTTP_PATCH:
+        bpf_memcpy(fragment, "PATCH ", 6);
+        return 6;
+    default:
+        return 0;
+    }
+}
+
+// Since the QUIC libraries don't know about the UDP socket carrying their
+// packets, we rely on the same heuristic as `map_ssl_ctx_to_sock`: the
+// connection a thread is about to send packets of is associated to the socket
+// of the next `udp_sendmsg` call made within the same thread. That is the
+// thread submitting a request for nghttp3, the worker thread draining the
+// operations of the connection for msquic, and the thread writing the
+// datagrams of the connection for quic-go.
+static __always_inline void map_http3_conn_to_sock(struct sock *skp) {
+    u64 pid_tgid = bpf_get_current_pid_tgid();
+    void **conn_map_val = bpf_map_lookup_elem(&http3_conn_by_pid_tgid, &pid_tgid);
+    if (conn_map_val == NULL) {
+        return;
+    }
+    // copy map value to stack. required for older kernels
+    void *conn = *conn_map_val;
+    bpf_map_delete_elem(&http3_conn_by_pid_tgid, &pid_tgid);
+
+    conn_tuple_t t = {};
+    if (!read_conn_tuple(&t, skp, pid_tgid, CONN_TYPE_UDP)) {
+        return;
+    }
+    t.netns = 0;
+    t.pid = 0;
+    normalize_tuple(&t);
+
+    bpf_map_update_with_telemetry(http3_tuple_by_conn, &conn, &t, BPF_ANY);
+}
+
+// http3_begin_transaction starts tracking the request of the given stream.
+// The request fragment is written as if it was an HTTP/1.1 request, so the
+// userspace program parses HTTP/3 requests as HTTP/1.1 ones. A path of size 0
+// is the `/` path.
+static __always_inline void http3_begin_transaction(http3_stream_key_t *key, http_method_t method, const __u8 *path, __u64 path_size) {
+    http_transaction_t http;
+    bpf_memset(&http, 0, sizeof(http));
+    bpf_map_update_with_telemetry(http3_in_flight, key, &http, BPF_ANY);
+    // We write the request fragment directly into the map value, since the
+    // verifier rejects variable offset writes to the stack on older kernels.
+    http_transaction_t *tx = bpf_map_lookup_elem(&http3_in_flight, key);
+    if (tx == NULL) {
+        return;
+    }
+
+    tx->request_method = method;
+    tx->request_started = bpf_ktime_get_ns();
+    tx->tags = HTTP3;
+
+    __u32 offset = http3_write_method(tx->request_fragment, method);
+    if (path_size == 0) {
+        tx->request_fragment[offset & 0xf] = '/';
+        return;
+    }
+    if (path_size > HTTP3_PATH_MAX_SIZE) {
+        path_size = HTTP3_PATH_MAX_SIZE;
+    }
+    bpf_probe_read_user(&tx->request_fragment[offset & 0xf], path_size, path);
+}
+
+// http3_begin_transaction_from_headers starts tracking the request whose
+// pseudo-header fields were decoded out of the field section at `src`.
+static __always_inline bool http3_begin_transaction_from_headers(http3_stream_key_t *key, qpack_pseudo_headers_t *headers, const __u8 *src) {
+    http_method_t method = headers->method;
+    if (method == HTTP_METHOD_UNKNOWN && headers->method_size > 0) {
+        method = http3_parse_method(src + headers->method_offset, headers->method_size);
+    }
+    if (method == HTTP_METHOD_UNKNOWN || !headers->has_path) {
+        return false;
+    }
+
+    http3_begin_transaction(key, method, src + headers->path_offset, headers->path_size);
+    return true;
+}
+
+static __always_inline void http3_set_response_status(http_transaction_t *tx, __u16 status_code) {
+    tx->response_status_code = status_code;
+    tx->response_last_seen = bpf_ktime_get_ns();
+}
+
+// http3_end_transaction enqueues the transaction of the given stream, once
+// the tuple of the UDP connection carrying the QUIC connection `conn` is known.
+static __always_inline void http3_end_transaction(struct pt_regs *ctx, http3_stream_key_t *key, void *conn) {
+    http_transaction_t *tx = bpf_map_lookup_elem(&http3_in_flight, key);
+    if (tx == NULL) {
+        return;
+    }
+
+    conn_tuple_t *t = bpf_map_lookup_elem(&http3_tuple_by_conn, &conn);
+    if (t == NULL) {
+        log_debug("http3_end_transaction: conn=%llx: no conn tuple\n", conn);
+        goto cleanup;
+    }
+
+    bpf_memcpy(&tx->tup, t, sizeof(conn_tuple_t));
+    http_batch_enqueue(tx);
+    http_batch_flush(ctx);
+cleanup:
+    bpf_map_delete_elem(&http3_in_flight, key);
+}
+
+SEC("kprobe/udp_sendmsg")
+int kprobe__udp_sendmsg(struct pt_regs *ctx) {
+    map_http3_conn_to_sock((struct sock *)PT_REGS_PARM1(ctx));
+    return 0;
+}
+
+// int nghttp3_conn_submit_request(nghttp3_conn *conn, int64_t stream_id,
+//                                 const nghttp3_nv *nva, size_t nvlen,
+//                                 const nghttp3_data_reader *dr,
+//                                 void *stream_user_data);
+SEC("uprobe/nghttp3_conn_submit_request")
+int uprobe__nghttp3_conn_submit_request(struct pt_regs *ctx) {
+    u64 pid_tgid = bpf_get_current_pid_tgid();
+    http3_stream_key_t key = {
+        .conn = (void *)PT_REGS_PARM1(ctx),
+        .stream_id = (__s64)PT_REGS_PARM2(ctx),
+    };
+    nghttp3_nv_t *nva = (nghttp3_nv_t *)PT_REGS_PARM3(ctx);
+    __u64 nvlen = (__u64)PT_REGS_PARM4(ctx);
+    log_debug("uprobe/nghttp3_conn_submit_request: pid_tgid=%llx conn=%llx stream_id=%lld\n", pid_tgid, key.conn, key.stream_id);
+
+    bpf_map_update_with_telemetry(http3_conn_by_pid_tgid, &pid_tgid, &key.conn, BPF_ANY);
+
+    nghttp3_nv_t nv = {0};
+    nghttp3_nv_t path = {0};
+    http_method_t method = HTTP_METHOD_UNKNOWN;
+#pragma unroll(HTTP3_MAX_HEADERS)
+    for (int i = 0; i < HTTP3_MAX_HEADERS; i++) {
+        if (i >= nvlen) {
+            break;
+        }
+        if (bpf_probe_read_user(&nv, sizeof(nv), &nva[i]) < 0) {
+            break;
+        }
+        if (http3_is_pseudo_header(&nv, ":method", sizeof(":method")-1)) {
+            method = http3_parse_method(nv.value, nv.valuelen);
+        } else if (http3_is_pseudo_header(&nv, ":path", sizeof(":path")-1)) {
+            path = nv;
+        }
+    }
+
+    if (method == HTTP_METHOD_UNKNOWN || path.value == NULL || path.valuelen == 0) {
+        return 0;
+    }
+
+    http3_begin_transaction(&key, method, path.value, path.valuelen);
+    return 0;
+}
+
+// int nghttp3_conn_read_stream(nghttp3_conn *conn, int64_t stream_id,
+//                              const uint8_t *src, size_t srclen, int fin);
+SEC("uprobe/nghttp3_conn_read_stream")
+int uprobe__nghttp3_conn_read_stream(struct pt_regs *ctx) {
+    u64 pid_tgid = bpf_get_current_pid_tgid();
+    http3_stream_key_t key = {
+        .conn = (void *)PT_REGS_PARM1(ctx),
+        .stream_id = (__s64)PT_REGS_PARM2(ctx),
+    };
+    bpf_map_update_with_telemetry(http3_read_stream_args, &pid_tgid, &key, BPF_ANY);
+    return 0;
+}
+
+SEC("uretprobe/nghttp3_conn_read_stream")
+int uretprobe__nghttp3_conn_read_stream(struct pt_regs *ctx) {
+    u64 pid_tgid = bpf_get_current_pid_tgid();
+    bpf_map_delete_elem(&http3_read_stream_args, &pid_tgid);
+    return 0;
+}
+
+// nghttp3_ssize nghttp3_qpack_decoder_read_request(nghttp3_qpack_decoder *decoder,
+//                                                  nghttp3_qpack_stream_context *sctx,
+//                                                  nghttp3_qpack_nv *nv, uint8_t *pflags,
+//                                                  const uint8_t *src, size_t srclen, int fin);
+SEC("uprobe/nghttp3_qpack_decoder_read_request")
+int uprobe__nghttp3_qpack_decoder_read_request(struct pt_regs *ctx) {
+    u64 pid_tgid = bpf_get_current_pid_tgid();
+    if (bpf_map_lookup_elem(&http3_read_stream_args, &pid_tgid) == NULL) {
+        return 0;
+    }
+
+    http3_qpack_decode_args_t args = {
+        .nv = (nghttp3_qpack_nv_t *)PT_REGS_PARM3(ctx),
+        .pflags = (__u8 *)PT_REGS_PARM4(ctx),
+    };
+    bpf_map_update_with_telemetry(http3_qpack_decode_args, &pid_tgid, &args, BPF_ANY);
+    return 0;
+}
+
+SEC("uretprobe/nghttp3_qpack_decoder_read_request")
+int uretprobe__nghttp3_qpack_decoder_read_request(struct pt_regs *ctx) {
+    u64 pid_tgid = bpf_get_current_pid_tgid();
+    http3_qpack_decode_args_t *args = bpf_map_lookup_elem(&http3_qpack_decode_args, &pid_tgid);
+    if (args == NULL) {
+        return 0;
+    }
+
+    __u8 flags = 0;
+    nghttp3_qpack_nv_t nv = {0};
+    nghttp3_rcbuf_t value = {0};
+    char status[HTTP3_STATUS_SIZE] = {0};
+    if ((long)PT_REGS_RC(ctx) < 0 ||
+        bpf_probe_read_user(&flags, sizeof(flags), args->pflags) < 0 ||
+        !(flags & NGHTTP3_QPACK_DECODE_FLAG_EMIT) ||
+        bpf_probe_read_user(&nv, sizeof(nv), args->nv) < 0 ||
+        nv.token != NGHTTP3_QPACK_TOKEN__STATUS ||
+        bpf_probe_read_user(&value, sizeof(value), nv.value) < 0 ||
+        value.len != HTTP3_STATUS_SIZE ||
+        bpf_probe_read_user(status, sizeof(status), value.base) < 0) {
+        goto cleanup;
+    }
+
+    http3_stream_key_t *key = bpf_map_lookup_elem(&http3_read_stream_args, &pid_tgid);
+    if (key == NULL) {
+        goto cleanup;
+    }
+
+    http_transaction_t *tx = bpf_map_lookup_elem(&http3_in_flight, key);
+    if (tx == NULL) {
+        goto cleanup;
+    }
+
+    http3_set_response_status(tx, (status[0]-'0')*100 + (status[1]-'0')*10 + (status[2]-'0'));
+cleanup:
+    bpf_map_delete_elem(&http3_qpack_decode_args, &pid_tgid);
+    return 0;
+}
+
+// int nghttp3_conn_close_stream(nghttp3_conn *conn, int64_t stream_id,
+//                               uint64_t app_error_code);
+SEC("uprobe/nghttp3_conn_close_stream")
+int uprobe__nghttp3_conn_close_stream(struct pt_regs *ctx) {
+    http3_stream_key_t key = {
+        .conn = (void *)PT_REGS_PARM1(ctx),
+        .stream_id = (__s64)PT_REGS_PARM2(ctx),
+    };
+    http3_end_transaction(ctx, &key, key.conn);
+    return 0;
+}
+
+// msquic only implements the transport, so the HTTP/3 frames are decoded out
+// of the stream data. The request HEADERS frame is the first data sent on a
+// request stream, and the response HEADERS frame the first data received.
+//
+// The operations of a msquic connection, sending its packets and indicating
+// the events of its streams included, are processed by a worker thread
+// draining them. The connection being drained by the thread is therefore the
+// one of the streams whose events are indicated.
+
+// BOOLEAN QuicConnDrainOperations(QUIC_CONNECTION* Connection, BOOLEAN* StillHasPriorityWork);
+SEC("uprobe/QuicConnDrainOperations")
+int uprobe__QuicConnDrainOperations(struct pt_regs *ctx) {
+    u64 pid_tgid = bpf_get_current_pid_tgid();
+    void *conn = (void *)PT_REGS_PARM1(ctx);
+    bpf_map_update_with_telemetry(http3_msquic_conn_by_pid_tgid, &pid_tgid, &conn, BPF_ANY);
+    bpf_map_update_with_telemetry(http3_conn_by_pid_tgid, &pid_tgid, &conn, BPF_ANY);
+    return 0;
+}
+
+SEC("uretprobe/QuicConnDrainOperations")
+int uretprobe__QuicConnDrainOperations(struct pt_regs *ctx) {
+    u64 pid_tgid = bpf_get_current_pid_tgid();
+    bpf_map_delete_elem(&http3_msquic_conn_by_pid_tgid, &pid_tgid);
+    bpf_map_delete_elem(&http3_conn_by_pid_tgid, &pid_tgid);
+    return 0;
+}
+
+// QUIC_STATUS MsQuicStreamSend(HQUIC Stream, const QUIC_BUFFER* const Buffers,
+//                              uint32_t BufferCount, QUIC_SEND_FLAGS Flags,
+//                              void* ClientSendContext);
+SEC("uprobe/MsQuicStreamSend")
+int uprobe__MsQuicStreamSend(struct pt_regs *ctx) {
+    u64 pid_tgid = bpf_get_current_pid_tgid();
+    http3_stream_key_t key = {
+        .conn = (void *)PT_REGS_PARM1(ctx),
+    };
+    msquic_buffer_t *buffers = (msquic_buffer_t *)PT_REGS_PARM2(ctx);
+    __u32 buffer_count = (__u32)PT_REGS_PARM3(ctx);
+    if (buffer_count == 0 || bpf_map_lookup_elem(&http3_in_flight, &key) != NULL) {
+        return 0;
+    }
+
+    msquic_buffer_t buffer = {0};
+    qpack_buffer_t buf = {0};
+    if (bpf_probe_read_user(&buffer, sizeof(buffer), buffers) < 0 ||
+        !qpack_read_buffer(&buf, buffer.buffer, buffer.length)) {
+        return 0;
+    }
+    __u32 offset = http3_headers_frame_payload(&buf);
+    if (offset == 0) {
+        return 0;
+    }
+
+    qpack_pseudo_headers_t headers = {0};
+    qpack_parse_pseudo_headers(&buf, offset, &headers);
+    if (!http3_begin_transaction_from_headers(&key, &headers, buffer.buffer)) {
+        return 0;
+    }
+    log_debug("uprobe/MsQuicStreamSend: pid_tgid=%llx stream=%llx\n", pid_tgid, key.conn);
+    return 0;
+}
+
+// QUIC_STATUS QuicStreamIndicateEvent(QUIC_STREAM* Stream, QUIC_STREAM_EVENT* Event);
+//
+// The stream handles given to the application are the addresses of the
+// streams, so `Stream` matches the `HQUIC` given to `MsQuicStreamSend`.
+SEC("uprobe/QuicStreamIndicateEvent")
+int uprobe__QuicStreamIndicateEvent(struct pt_regs *ctx) {
+    http3_stream_key_t key = {
+        .conn = (void *)PT_REGS_PARM1(ctx),
+    };
+    http_transaction_t *tx = bpf_map_lookup_elem(&http3_in_flight, &key);
+    if (tx == NULL) {
+        return 0;
+    }
+
+    msquic_stream_event_t event = {0};
+    if (bpf_probe_read_user(&event, sizeof(event), (void *)PT_REGS_PARM2(ctx)) < 0) {
+        return 0;
+    }
+
+    if (event.type == MSQUIC_STREAM_EVENT_SHUTDOWN_COMPLETE) {
+        u64 pid_tgid = bpf_get_current_pid_tgid();
+        void **conn = bpf_map_lookup_elem(&http3_msquic_conn_by_pid_tgid, &pid_tgid);
+        if (conn == NULL) {
+            bpf_map_delete_elem(&http3_in_flight, &key);
+            return 0;
+        }
+        http3_end_transaction(ctx, &key, *conn);
+        return 0;
+    }
+    if (event.type != MSQUIC_STREAM_EVENT_RECEIVE || event.buffer_count == 0 || tx->response_status_code != 0) {
+        return 0;
+    }
+
+    msquic_buffer_t buffer = {0};
+    qpack_buffer_t buf = {0};
+    if (bpf_probe_read_user(&buffer, sizeof(buffer), event.buffers) < 0 ||
+        !qpack_read_buffer(&buf, buffer.buffer, buffer.length)) {
+        return 0;
+    }
+    __u32 offset = http3_headers_frame_payload(&buf);
+    if (offset == 0) {
+        return 0;
+    }
+
+    qpack_pseudo_headers_t headers = {0};
+    qpack_parse_pseudo_headers(&buf, offset, &headers);
+    if (headers.status_code != 0) {
+        http3_set_response_status(tx, headers.status_code);
+    }
+    return 0;
+}
+
+#endif
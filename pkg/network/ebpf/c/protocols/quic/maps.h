@@ -0,0 +1,28 @@
+#ifndef __QUIC_MAPS_H
+#define __QUIC_MAPS_H
+
+#include "bpf_helpers.h"
+#include "map-defs.h"
+
+#include "protocols/http/types.h"
+#include "protocols/quic/types.h"
+
+/* This map is used to keep track of in-flight HTTP/3 requests for each nghttp3 stream.
+   Map size is set to 1 as HTTP/3 monitoring is optional, this will be overwritten to MaxTrackedConnections
+   if HTTP/3 monitoring is enabled. */
+BPF_LRU_MAP(http3_in_flight, http3_stream_key_t, http_transaction_t, 1)
+
+/* Associates a nghttp3 connection to the UDP connection tuple carrying it. */
+BPF_LRU_MAP(http3_tuple_by_conn, void *, conn_tuple_t, 1)
+
+/* Best-effort mechanism to find the UDP socket used by a QUIC connection. See `map_http3_conn_to_sock`. */
+BPF_LRU_MAP(http3_conn_by_pid_tgid, __u64, void *, 1024)
+
+/* Associates a worker thread to the msquic connection whose operations it is draining. */
+BPF_LRU_MAP(http3_msquic_conn_by_pid_tgid, __u64, void *, 1024)
+
+BPF_LRU_MAP(http3_read_stream_args, __u64, http3_stream_key_t, 1024)
+
+BPF_LRU_MAP(http3_qpack_decode_args, __u64, http3_qpack_decode_args_t, 1024)
+
+#endif
@@ -0,0 +1,273 @@
+#ifndef __QPACK_H
+#define __QPACK_H
+
+#include "bpf_builtins.h"
+
+#include "protocols/http/types.h"
+
+// https://www.rfc-editor.org/rfc/rfc9114.html#name-headers
+#define HTTP3_FRAME_TYPE_HEADERS 0x01
+
+// The pseudo-header fields precede the regular fields, so only the beginning
+// of a field section is inspected.
+#define QPACK_BUFFER_SIZE 64
+#define QPACK_BUFFER_MASK (QPACK_BUFFER_SIZE - 1)
+#define QPACK_MAX_FIELD_LINES 6
+
+// Indices of the QPACK static table.
+// https://www.rfc-editor.org/rfc/rfc9204.html#name-static-table-2
+#define QPACK_STATIC_PATH 1
+#define QPACK_STATIC_METHOD_FIRST 15
+#define QPACK_STATIC_METHOD_LAST 21
+
+typedef struct {
+    __u8 data[QPACK_BUFFER_SIZE];
+    __u32 size;
+} qpack_buffer_t;
+
+// The pseudo-header fields decoded out of a field section. The literal values
+// are referenced by their offset in the field section, as they may not fit in
+// the inspected buffer.
+typedef struct {
+    http_method_t method;
+    __u32 method_offset;
+    __u32 method_size;
+    __u32 path_offset;
+    // A path of size 0 is the `/` path of the static table
+    __u32 path_size;
+    __u16 status_code;
+    bool has_path;
+} qpack_pseudo_headers_t;
+
+static __always_inline bool qpack_read_buffer(qpack_buffer_t *buf, const __u8 *src, __u64 size) {
+    if (size == 0) {
+        return false;
+    }
+    if (size > QPACK_BUFFER_SIZE) {
+        size = QPACK_BUFFER_SIZE;
+    }
+    buf->size = size;
+    return bpf_probe_read_user(buf->data, size, src) == 0;
+}
+
+// http3_headers_frame_payload returns the offset of the field section of the
+// HEADERS frame starting the buffer, or 0 if the buffer doesn't start with one.
+static __always_inline __u32 http3_headers_frame_payload(qpack_buffer_t *buf) {
+    if (buf->size < 3 || buf->data[0] != HTTP3_FRAME_TYPE_HEADERS) {
+        return 0;
+    }
+
+    // The frame length is a variable-length integer, whose two most
+    // significant bits encode its size.
+    // https://www.rfc-editor.org/rfc/rfc9000.html#name-variable-length-integer-enc
+    switch (buf->data[1] >> 6) {
+    case 0:
+        return 2;
+    case 1:
+        return 3;
+    case 2:
+        return 5;
+    default:
+        return 9;
+    }
+}
+
+// qpack_read_int decodes the prefixed integer at the given offset, and returns
+// the number of bytes it spans, or 0 if it can't be decoded. The indices and
+// lengths we are interested in are small, so only the integers encoded on at
+// most two bytes are supported.
+// https://www.rfc-editor.org/rfc/rfc7541.html#section-5.1
+static __always_inline __u32 qpack_read_int(qpack_buffer_t *buf, __u32 offset, __u8 prefix_mask, __u64 *value) {
+    if (offset + 1 > buf->size) {
+        return 0;
+    }
+    __u8 prefix = buf->data[offset & QPACK_BUFFER_MASK] & prefix_mask;
+    if (prefix < prefix_mask) {
+        *value = prefix;
+        return 1;
+    }
+
+    if (offset + 2 > buf->size) {
+        return 0;
+    }
+    __u8 next = buf->data[(offset + 1) & QPACK_BUFFER_MASK];
+    if (next & 0x80) {
+        return 0;
+    }
+    *value = prefix_mask + next;
+    return 2;
+}
+
+static __always_inline http_method_t qpack_static_method(__u64 index) {
+    switch (index) {
+    case 16:
+        return HTTP_DELETE;
+    case 17:
+        return HTTP_GET;
+    case 18:
+        return HTTP_HEAD;
+    case 19:
+        return HTTP_OPTIONS;
+    case 20:
+        return HTTP_POST;
+    case 21:
+        return HTTP_PUT;
+    default:
+        return HTTP_METHOD_UNKNOWN;
+    }
+}
+
+static __always_inline __u16 qpack_static_status_code(__u64 index) {
+    switch (index) {
+    case 24:
+        return 103;
+    case 25:
+        return 200;
+    case 26:
+        return 304;
+    case 27:
+        return 404;
+    case 28:
+        return 503;
+    case 63:
+        return 100;
+    case 64:
+        return 204;
+    case 65:
+        return 206;
+    case 66:
+        return 302;
+    case 67:
+        return 400;
+    case 68:
+        return 403;
+    case 69:
+        return 421;
+    case 70:
+        return 425;
+    case 71:
+        return 500;
+    default:
+        return 0;
+    }
+}
+
+static __always_inline __u16 qpack_literal_status_code(qpack_buffer_t *buf, __u32 offset) {
+    if (offset + 3 > buf->size) {
+        return 0;
+    }
+
+    __u16 status_code = 0;
+#pragma unroll
+    for (int i = 0; i < 3; i++) {
+        __u8 digit = buf->data[(offset + i) & QPACK_BUFFER_MASK];
+        if (digit < '0' || digit > '9') {
+            return 0;
+        }
+        status_code = status_code * 10 + (digit - '0');
+    }
+    return status_code;
+}
+
+static __always_inline void qpack_static_field(qpack_pseudo_headers_t *headers, __u64 index) {
+    if (index == QPACK_STATIC_PATH) {
+        headers->has_path = true;
+        headers->path_size = 0;
+        return;
+    }
+
+    http_method_t method = qpack_static_method(index);
+    if (method != HTTP_METHOD_UNKNOWN) {
+        headers->method = method;
+        return;
+    }
+
+    __u16 status_code = qpack_static_status_code(index);
+    if (status_code != 0) {
+        headers->status_code = status_code;
+    }
+}
+
+static __always_inline void qpack_literal_field(qpack_buffer_t *buf, qpack_pseudo_headers_t *headers, __u64 name_index, __u32 offset, __u32 size) {
+    if (name_index == QPACK_STATIC_PATH) {
+        headers->has_path = size > 0;
+        headers->path_offset = offset;
+        headers->path_size = size;
+    } else if (name_index >= QPACK_STATIC_METHOD_FIRST && name_index <= QPACK_STATIC_METHOD_LAST) {
+        headers->method_offset = offset;
+        headers->method_size = size;
+    } else if (qpack_static_status_code(name_index) != 0 && size == 3) {
+        headers->status_code = qpack_literal_status_code(buf, offset);
+    }
+}
+
+// qpack_parse_pseudo_headers decodes the pseudo-header fields of the field
+// section starting at the given offset. Encoders represent them with field
+// lines referencing the static table, which are the only ones decoded. The
+// Huffman encoded literal values are ignored.
+// https://www.rfc-editor.org/rfc/rfc9204.html#name-field-line-representations
+static __always_inline void qpack_parse_pseudo_headers(qpack_buffer_t *buf, __u32 offset, qpack_pseudo_headers_t *headers) {
+    __u64 value = 0;
+    // Encoded field section prefix: the required insert count and the delta
+    // base, which only matter for the dynamic table.
+    __u32 size = qpack_read_int(buf, offset, 0xff, &value);
+    if (size == 0) {
+        return;
+    }
+    offset += size;
+    size = qpack_read_int(buf, offset, 0x7f, &value);
+    if (size == 0) {
+        return;
+    }
+    offset += size;
+
+#pragma unroll(QPACK_MAX_FIELD_LINES)
+    for (int i = 0; i < QPACK_MAX_FIELD_LINES; i++) {
+        if (offset >= buf->size) {
+            break;
+        }
+
+        __u8 first = buf->data[offset & QPACK_BUFFER_MASK];
+        __u64 index = 0;
+        if (first & 0x80) {
+            // Indexed field line: 1Txxxxxx
+            size = qpack_read_int(buf, offset, 0x3f, &index);
+            if (size == 0) {
+                break;
+            }
+            offset += size;
+            if (first & 0x40) {
+                qpack_static_field(headers, index);
+            }
+            continue;
+        }
+
+        // The literal field lines with a literal name and the post-base
+        // representations don't carry pseudo-header fields.
+        if (!(first & 0x40)) {
+            break;
+        }
+
+        // Literal field line with name reference: 01NTxxxx
+        size = qpack_read_int(buf, offset, 0x0f, &index);
+        if (size == 0 || offset + size >= buf->size) {
+            break;
+        }
+        offset += size;
+
+        bool huffman = buf->data[offset & QPACK_BUFFER_MASK] & 0x80;
+        __u64 value_size = 0;
+        size = qpack_read_int(buf, offset, 0x7f, &value_size);
+        if (size == 0) {
+            break;
+        }
+        offset += size;
+
+        if ((first & 0x10) && !huffman) {
+            qpack_literal_field(buf, headers, index, offset, value_size);
+        }
+        offset += value_size;
+    }
+}
+
+#endif
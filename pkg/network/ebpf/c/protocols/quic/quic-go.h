@@ -0,0 +1,265 @@
+#ifndef __QUIC_GO_H
+#define __QUIC_GO_H
+
+#include "bpf_helpers.h"
+#include "bpf_telemetry.h"
+#include "map-defs.h"
+
+#include "protocols/quic/http3.h"
+#include "protocols/quic/qpack.h"
+#include "protocols/tls/go-tls-goid.h"
+#include "protocols/tls/go-tls-location.h"
+#include "protocols/tls/go-tls-types.h"
+#include "protocols/tls/https.h"
+
+// The quic-go probes read their arguments out of the registers assigned by the
+// register based calling convention, which userspace checks the hooked
+// binaries use. https://go.dev/s/regabi
+#if defined(__TARGET_ARCH_x86)
+// RAX, RBX, RCX, RDI
+#define GO_REGABI_ARG_1 0
+#define GO_REGABI_ARG_2 3
+#define GO_REGABI_ARG_3 2
+#define GO_REGABI_ARG_4 5
+#elif defined(__TARGET_ARCH_arm64)
+// R0, R1, R2, R3
+#define GO_REGABI_ARG_1 0
+#define GO_REGABI_ARG_2 1
+#define GO_REGABI_ARG_3 2
+#define GO_REGABI_ARG_4 3
+#endif
+
+// Offsets of the fields of net/http.Request and net/url.URL we read, which
+// haven't changed since Go 1.0.
+#define GO_HTTP_REQUEST_METHOD_OFFSET 0
+#define GO_HTTP_REQUEST_URL_OFFSET 16
+#define GO_URL_PATH_OFFSET 56
+
+typedef struct {
+    __u8 *ptr;
+    __u64 len;
+} go_string_t;
+
+/* Associates the goroutine sending a request with quic-go to its stream, as
+   the response headers are decoded by the same goroutine. */
+BPF_LRU_MAP(http3_quic_go_stream_by_goroutine, go_tls_function_args_key_t, http3_stream_key_t, 1024)
+
+/* Associates a goroutine to the quic-go connection it works on: the goroutine
+   opening a request stream, or the one running the connection. */
+BPF_LRU_MAP(http3_quic_go_conn_by_goroutine, go_tls_function_args_key_t, void *, 1024)
+
+/* Associates the send queue of a quic-go connection to the connection. */
+BPF_LRU_MAP(http3_quic_go_conn_by_send_queue, void *, void *, 1024)
+
+/* Associates the goroutine writing the datagrams queued by a quic-go
+   connection to the send queue. */
+BPF_LRU_MAP(http3_quic_go_send_queue_by_goroutine, go_tls_function_args_key_t, void *, 1024)
+
+static __always_inline int quic_go_call_key(struct pt_regs *ctx, go_tls_function_args_key_t *call_key) {
+    tls_offsets_data_t *od = get_offsets_data();
+    if (od == NULL) {
+        return 1;
+    }
+
+    call_key->pid = bpf_get_current_pid_tgid() >> 32;
+    return read_goroutine_id(ctx, &od->goroutine_id, &call_key->goroutine_id);
+}
+
+// quic_go_track_conn associates the calling goroutine to the quic-go
+// connection given as the receiver of the hooked method.
+static __always_inline int quic_go_track_conn(struct pt_regs *ctx) {
+    go_tls_function_args_key_t call_key = {0};
+    if (quic_go_call_key(ctx, &call_key)) {
+        return 0;
+    }
+
+    void *conn = NULL;
+    if (read_register(ctx, GO_REGABI_ARG_1, &conn)) {
+        return 0;
+    }
+    bpf_map_update_with_telemetry(http3_quic_go_conn_by_goroutine, &call_key, &conn, BPF_ANY);
+    return 0;
+}
+
+// The HTTP/3 client opens the stream of a request, and then writes the request
+// headers on it, within the same goroutine.
+//
+// func (s *connection) OpenStreamSync(ctx context.Context) (Stream, error)
+SEC("uprobe/github.com/quic-go/quic-go.(*connection).OpenStreamSync")
+int uprobe__quic_go_connection_OpenStreamSync(struct pt_regs *ctx) {
+    return quic_go_track_conn(ctx);
+}
+
+// The packets of a connection are packed by the goroutine running it, and
+// queued to the goroutine writing them to the UDP socket, which is started by
+// the connection for its send queue.
+//
+// func (s *connection) sendPackets(now time.Time) error
+SEC("uprobe/github.com/quic-go/quic-go.(*connection).sendPackets")
+int uprobe__quic_go_connection_sendPackets(struct pt_regs *ctx) {
+    return quic_go_track_conn(ctx);
+}
+
+// func (h *sendQueue) Send(p *packetBuffer, gsoSize uint16, ecn protocol.ECN)
+SEC("uprobe/github.com/quic-go/quic-go.(*sendQueue).Send")
+int uprobe__quic_go_sendQueue_Send(struct pt_regs *ctx) {
+    go_tls_function_args_key_t call_key = {0};
+    if (quic_go_call_key(ctx, &call_key)) {
+        return 0;
+    }
+
+    void **conn = bpf_map_lookup_elem(&http3_quic_go_conn_by_goroutine, &call_key);
+    if (conn == NULL) {
+        return 0;
+    }
+    // copy map value to stack. required for older kernels
+    void *conn_ptr = *conn;
+
+    void *send_queue = NULL;
+    if (read_register(ctx, GO_REGABI_ARG_1, &send_queue)) {
+        return 0;
+    }
+    bpf_map_update_with_telemetry(http3_quic_go_conn_by_send_queue, &send_queue, &conn_ptr, BPF_ANY);
+    return 0;
+}
+
+// func (h *sendQueue) Run() error
+SEC("uprobe/github.com/quic-go/quic-go.(*sendQueue).Run")
+int uprobe__quic_go_sendQueue_Run(struct pt_regs *ctx) {
+    go_tls_function_args_key_t call_key = {0};
+    if (quic_go_call_key(ctx, &call_key)) {
+        return 0;
+    }
+
+    void *send_queue = NULL;
+    if (read_register(ctx, GO_REGABI_ARG_1, &send_queue)) {
+        return 0;
+    }
+    bpf_map_update_with_telemetry(http3_quic_go_send_queue_by_goroutine, &call_key, &send_queue, BPF_ANY);
+    return 0;
+}
+
+// The datagrams are written by the send queue, or directly by the goroutine
+// running the connection when closing it. Go makes the system call out of the
+// thread running the goroutine, so the connection is associated to the socket
+// of the next `udp_sendmsg` call of the thread.
+//
+// func (c *sconn) Write(p []byte, gsoSize uint16, ecn protocol.ECN) error
+SEC("uprobe/github.com/quic-go/quic-go.(*sconn).Write")
+int uprobe__quic_go_sconn_Write(struct pt_regs *ctx) {
+    go_tls_function_args_key_t call_key = {0};
+    if (quic_go_call_key(ctx, &call_key)) {
+        return 0;
+    }
+
+    void **conn = bpf_map_lookup_elem(&http3_quic_go_conn_by_goroutine, &call_key);
+    if (conn == NULL) {
+        void **send_queue = bpf_map_lookup_elem(&http3_quic_go_send_queue_by_goroutine, &call_key);
+        if (send_queue == NULL) {
+            return 0;
+        }
+        // copy map value to stack. required for older kernels
+        void *send_queue_ptr = *send_queue;
+        conn = bpf_map_lookup_elem(&http3_quic_go_conn_by_send_queue, &send_queue_ptr);
+        if (conn == NULL) {
+            return 0;
+        }
+    }
+    // copy map value to stack. required for older kernels
+    void *conn_ptr = *conn;
+
+    u64 pid_tgid = bpf_get_current_pid_tgid();
+    bpf_map_update_with_telemetry(http3_conn_by_pid_tgid, &pid_tgid, &conn_ptr, BPF_ANY);
+    return 0;
+}
+
+// func (w *requestWriter) WriteRequestHeader(str quic.Stream, req *http.Request, gzip bool) error
+SEC("uprobe/github.com/quic-go/quic-go/http3.(*requestWriter).WriteRequestHeader")
+int uprobe__quic_go_http3_requestWriter_WriteRequestHeader(struct pt_regs *ctx) {
+    u64 pid_tgid = bpf_get_current_pid_tgid();
+    go_tls_function_args_key_t call_key = {0};
+    if (quic_go_call_key(ctx, &call_key)) {
+        log_debug("[quic-go-write-request-header] failed reading go routine id for pid %d\n", pid_tgid >> 32);
+        return 0;
+    }
+
+    // The streams are identified by their connection and their own address
+    void **conn = bpf_map_lookup_elem(&http3_quic_go_conn_by_goroutine, &call_key);
+    if (conn == NULL) {
+        return 0;
+    }
+    http3_stream_key_t key = {
+        .conn = *conn,
+    };
+
+    // `str` is an interface, whose data pointer is the address of the stream
+    void *str = NULL;
+    void *req = NULL;
+    if (read_register(ctx, GO_REGABI_ARG_3, &str) || read_register(ctx, GO_REGABI_ARG_4, &req)) {
+        return 0;
+    }
+    key.stream_id = (__s64)str;
+
+    go_string_t method = {0};
+    go_string_t path = {0};
+    void *url = NULL;
+    if (bpf_probe_read_user(&method, sizeof(method), req + GO_HTTP_REQUEST_METHOD_OFFSET) < 0 ||
+        bpf_probe_read_user(&url, sizeof(url), req + GO_HTTP_REQUEST_URL_OFFSET) < 0 ||
+        bpf_probe_read_user(&path, sizeof(path), url + GO_URL_PATH_OFFSET) < 0) {
+        return 0;
+    }
+
+    // An empty method means GET for net/http
+    http_method_t request_method = method.len == 0 ? HTTP_GET : http3_parse_method(method.ptr, method.len);
+    if (request_method == HTTP_METHOD_UNKNOWN) {
+        return 0;
+    }
+
+    http3_begin_transaction(&key, request_method, path.ptr, path.len);
+    bpf_map_update_with_telemetry(http3_quic_go_stream_by_goroutine, &call_key, &key, BPF_ANY);
+    return 0;
+}
+
+// func (d *Decoder) DecodeFull(p []byte) ([]HeaderField, error)
+SEC("uprobe/github.com/quic-go/qpack.(*Decoder).DecodeFull")
+int uprobe__quic_go_qpack_Decoder_DecodeFull(struct pt_regs *ctx) {
+    go_tls_function_args_key_t call_key = {0};
+    if (quic_go_call_key(ctx, &call_key)) {
+        return 0;
+    }
+
+    http3_stream_key_t *stream = bpf_map_lookup_elem(&http3_quic_go_stream_by_goroutine, &call_key);
+    if (stream == NULL) {
+        return 0;
+    }
+    // copy map value to stack. required for older kernels
+    http3_stream_key_t key = *stream;
+    bpf_map_delete_elem(&http3_quic_go_stream_by_goroutine, &call_key);
+
+    http_transaction_t *tx = bpf_map_lookup_elem(&http3_in_flight, &key);
+    if (tx == NULL) {
+        return 0;
+    }
+
+    __u8 *p = NULL;
+    __u64 p_len = 0;
+    qpack_buffer_t buf = {0};
+    if (read_register(ctx, GO_REGABI_ARG_2, &p) || read_register(ctx, GO_REGABI_ARG_3, &p_len) ||
+        !qpack_read_buffer(&buf, p, p_len)) {
+        return 0;
+    }
+
+    qpack_pseudo_headers_t headers = {0};
+    qpack_parse_pseudo_headers(&buf, 0, &headers);
+    if (headers.status_code == 0) {
+        return 0;
+    }
+
+    // quic-go doesn't expose the end of the streams, so the transaction ends
+    // with the response headers.
+    http3_set_response_status(tx, headers.status_code);
+    http3_end_transaction(ctx, &key, key.conn);
+    return 0;
+}
+
+#endif
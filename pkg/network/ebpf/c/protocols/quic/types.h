@@ -0,0 +1,61 @@
+#ifndef __QUIC_TYPES_H
+#define __QUIC_TYPES_H
+
+#include "ktypes.h"
+
+// Identifies an HTTP/3 request stream within a nghttp3 connection. The
+// streams of msquic are identified by their own address, with a stream_id of
+// 0, and those of quic-go by their connection, with their address as stream_id.
+typedef struct {
+    void *conn;
+    __s64 stream_id;
+} http3_stream_key_t;
+
+// Mirrors `nghttp3_nv` from nghttp3.h
+typedef struct {
+    __u8 *name;
+    __u8 *value;
+    __u64 namelen;
+    __u64 valuelen;
+    __u8 flags;
+} nghttp3_nv_t;
+
+// Mirrors `nghttp3_qpack_nv` from nghttp3.h
+typedef struct {
+    void *name;
+    void *value;
+    __s32 token;
+    __u8 flags;
+} nghttp3_qpack_nv_t;
+
+// Mirrors the leading fields of `nghttp3_rcbuf` from nghttp3_rcbuf.h
+typedef struct {
+    void *mem;
+    __u8 *base;
+    __u64 len;
+} nghttp3_rcbuf_t;
+
+typedef struct {
+    nghttp3_qpack_nv_t *nv;
+    __u8 *pflags;
+} http3_qpack_decode_args_t;
+
+// Mirrors `QUIC_BUFFER` from msquic.h
+typedef struct {
+    __u32 length;
+    __u8 *buffer;
+} msquic_buffer_t;
+
+#define MSQUIC_STREAM_EVENT_RECEIVE 1
+#define MSQUIC_STREAM_EVENT_SHUTDOWN_COMPLETE 7
+
+// Mirrors the leading fields of the `RECEIVE` event of `QUIC_STREAM_EVENT` from msquic.h
+typedef struct {
+    __u32 type;
+    __u64 absolute_offset;
+    __u64 total_buffer_length;
+    msquic_buffer_t *buffers;
+    __u32 buffer_count;
+} msquic_stream_event_t;
+
+#endif
@@ -21,11 +21,13 @@
 #include "protocols/classification/dispatcher-helpers.h"
 #include "protocols/classification/dispatcher-maps.h"
 #include "protocols/http/buffer.h"
+#include "protocols/http/cgroup.h"
 #include "protocols/http/types.h"
 #include "protocols/http/maps.h"
 #include "protocols/http/http.h"
 #include "protocols/tls/tags-types.h"
 #include "protocols/tls/go-tls-types.h"
+#include "protocols/tls/tls-info.h"
 
 #define HTTPS_PORT 443
 
@@ -49,10 +51,20 @@ static __always_inline void classify_decrypted_payload(conn_tuple_t *t, void *bu
 
 static __always_inline bool http_process(http_transaction_t *http_stack, skb_info_t *skb_info, __u64 tags);
 
+// service_mesh_tag returns the SERVICE_MESH tag when the current process is a sidecar proxy of a service mesh
+static __always_inline __u64 service_mesh_tag() {
+    __u32 tgid = bpf_get_current_pid_tgid() >> 32;
+    return bpf_map_lookup_elem(&service_mesh_pids, &tgid) != NULL ? SERVICE_MESH : NO_TAGS;
+}
+
 static __always_inline void https_process(conn_tuple_t *t, void *buffer, size_t len, __u64 tags) {
+    tags |= service_mesh_tag();
+
     http_transaction_t http;
     bpf_memset(&http, 0, sizeof(http));
     bpf_memcpy(&http.tup, t, sizeof(conn_tuple_t));
+    http.cgroup_id = http_current_cgroup_id();
+    tls_attach_info(t, &http);
     read_into_buffer(http.request_fragment, buffer, len);
     http_process(&http, NULL, tags);
     classify_decrypted_payload(&http.tup, http.request_fragment, len);
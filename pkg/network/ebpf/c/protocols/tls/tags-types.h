@@ -9,6 +9,8 @@ enum static_tags {
     GO = (1<<2),
     JAVA_TLS = (1<<3),
     CONN_TLS = (1<<4),
+    HTTP3 = (1<<5),
+    SERVICE_MESH = (1<<6),
 };
 
 #endif
@@ -0,0 +1,134 @@
+#ifndef __TLS_INFO_H
+#define __TLS_INFO_H
+
+#include "ktypes.h"
+#include "bpf_helpers.h"
+#include "bpf_telemetry.h"
+#include "ip.h"
+#include "port_range.h"
+
+#include "protocols/helpers/big_endian.h"
+#include "protocols/http/maps.h"
+#include "protocols/http/types.h"
+#include "protocols/tls/tls.h"
+
+#define TLS_HANDSHAKE_SERVER_HELLO 0x02
+#define TLS_EXTENSION_SUPPORTED_VERSIONS 0x002b
+#define TLS_MAX_SESSION_ID_LENGTH 32
+
+// record header (5) + handshake type (1) + handshake length (3)
+#define TLS_SERVER_HELLO_VERSION_OFFSET 9
+// server version (2) + server random (32)
+#define TLS_SERVER_HELLO_SESSION_ID_OFFSET (TLS_SERVER_HELLO_VERSION_OFFSET + 34)
+// maximum number of extensions of a ServerHello walked to find the supported_versions extension
+#define TLS_SERVER_HELLO_MAX_EXTENSIONS 8
+
+// The negotiated TLS parameters are read from the ServerHello, which is sent in clear text, rather than from the
+// structs of the TLS libraries, whose layout changes across versions. The socket filter records them per
+// connection, and the TLS hooks attach them to the HTTP transactions they decrypt.
+static __always_inline bool is_tls_info_enabled() {
+    __u64 val = 0;
+    LOAD_CONSTANT("tls_info_enabled", val);
+    return val > 0;
+}
+
+// tls_read_server_hello reads the version and the cipher suite negotiated by a ServerHello starting at the given
+// offset. The version of a TLS 1.3 ServerHello is frozen to TLS 1.2, the negotiated version being held by its
+// supported_versions extension.
+static __always_inline bool tls_read_server_hello(struct __sk_buff *skb, __u32 offset, tls_info_t *info) {
+    // record type (1) + record version (2) + record length (2) + handshake type (1)
+    __u8 header[6];
+    if (offset + sizeof(header) > skb->len) {
+        return false;
+    }
+    bpf_skb_load_bytes_with_telemetry(skb, offset, header, sizeof(header));
+    if (header[0] != TLS_HANDSHAKE || header[5] != TLS_HANDSHAKE_SERVER_HELLO) {
+        return false;
+    }
+
+    s16 version = 0;
+    if (!read_big_endian_s16(skb, offset + TLS_SERVER_HELLO_VERSION_OFFSET, &version) || !is_valid_tls_version(version)) {
+        return false;
+    }
+
+    __u32 off = offset + TLS_SERVER_HELLO_SESSION_ID_OFFSET;
+    __u8 session_id_length = 0;
+    if (off + sizeof(session_id_length) > skb->len) {
+        return false;
+    }
+    bpf_skb_load_bytes_with_telemetry(skb, off, &session_id_length, sizeof(session_id_length));
+    if (session_id_length > TLS_MAX_SESSION_ID_LENGTH) {
+        return false;
+    }
+    off += sizeof(session_id_length) + session_id_length;
+
+    s16 cipher_suite = 0;
+    if (!read_big_endian_s16(skb, off, &cipher_suite)) {
+        return false;
+    }
+    info->version = version;
+    info->cipher_suite = cipher_suite;
+
+    // cipher suite (2) + compression method (1)
+    off += 3;
+    s16 extensions_length = 0;
+    if (!read_big_endian_s16(skb, off, &extensions_length)) {
+        // the extensions are optional before TLS 1.3
+        return true;
+    }
+    off += sizeof(extensions_length);
+    const __u32 extensions_end = off + (__u16)extensions_length;
+
+#pragma unroll(TLS_SERVER_HELLO_MAX_EXTENSIONS)
+    for (int i = 0; i < TLS_SERVER_HELLO_MAX_EXTENSIONS; i++) {
+        s16 extension_type = 0;
+        s16 extension_length = 0;
+        if (off + 4 > extensions_end || !read_big_endian_s16(skb, off, &extension_type) || !read_big_endian_s16(skb, off + 2, &extension_length)) {
+            break;
+        }
+        if ((__u16)extension_type == TLS_EXTENSION_SUPPORTED_VERSIONS) {
+            s16 selected_version = 0;
+            if (read_big_endian_s16(skb, off + 4, &selected_version) && is_valid_tls_version(selected_version)) {
+                info->version = selected_version;
+            }
+            break;
+        }
+        off += 4 + (__u16)extension_length;
+    }
+
+    return true;
+}
+
+// tls_record_server_hello records the TLS parameters of a connection when the given segment holds its ServerHello
+static __always_inline void tls_record_server_hello(struct __sk_buff *skb, skb_info_t *skb_info, conn_tuple_t *skb_tup) {
+    tls_info_t info = {0};
+    if (!tls_read_server_hello(skb, skb_info->data_off, &info)) {
+        return;
+    }
+
+    conn_tuple_t t = *skb_tup;
+    normalize_tuple(&t);
+    bpf_map_update_with_telemetry(tls_info_by_tuple, &t, &info, BPF_ANY);
+}
+
+// tls_attach_info sets the TLS parameters recorded for the connection of a transaction decrypted by a TLS hook
+static __always_inline void tls_attach_info(conn_tuple_t *tup, http_transaction_t *http) {
+    if (!is_tls_info_enabled()) {
+        return;
+    }
+
+    // same as the tuples read from the socket filter, see tup_from_ssl_ctx
+    conn_tuple_t t = *tup;
+    t.netns = 0;
+    t.pid = 0;
+    normalize_tuple(&t);
+
+    tls_info_t *info = bpf_map_lookup_elem(&tls_info_by_tuple, &t);
+    if (info == NULL) {
+        return;
+    }
+    http->tls_version = info->version;
+    http->tls_cipher_suite = info->cipher_suite;
+}
+
+#endif
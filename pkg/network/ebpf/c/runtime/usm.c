@@ -24,6 +24,9 @@
 #include "protocols/tls/tags-types.h"
 #include "protocols/tls/java-tls-erpc.h"
 #include "protocols/kafka/kafka-parsing.h"
+#include "protocols/dns/dns-parsing.h"
+#include "protocols/quic/http3.h"
+#include "protocols/quic/quic-go.h"
 
 #define SO_SUFFIX_SIZE 3
 
@@ -42,11 +45,26 @@ int socket__protocol_dispatcher_kafka(struct __sk_buff *skb) {
     return 0;
 }
 
+// The cgroup_skb programs of the cgroup attach mode, attached to the cgroups of the selected workloads.
+// They let all the packets through.
+SEC("cgroup_skb/ingress")
+int cgroup_skb__usm_ingress(struct __sk_buff *skb) {
+    usm_cgroup_record_tuple(skb);
+    return 1;
+}
+
+SEC("cgroup_skb/egress")
+int cgroup_skb__usm_egress(struct __sk_buff *skb) {
+    usm_cgroup_record_tuple(skb);
+    return 1;
+}
+
 SEC("kprobe/tcp_sendmsg")
 int BPF_KPROBE(kprobe__tcp_sendmsg, struct sock *sk) {
     log_debug("kprobe/tcp_sendmsg: sk=%llx\n", sk);
     // map connection tuple during SSL_do_handshake(ctx)
     map_ssl_ctx_to_sock(sk);
+    http_record_cgroup_id(sk);
 
     return 0;
 }
@@ -59,6 +77,7 @@ int tracepoint__net__netif_receive_skb(struct pt_regs* ctx) {
     http_batch_flush(ctx);
     http2_batch_flush(ctx);
     kafka_batch_flush(ctx);
+    dns_batch_flush(ctx);
     return 0;
 }
 
@@ -129,7 +148,8 @@ int uretprobe__BIO_new_socket(struct pt_regs *ctx) {
         goto cleanup;
     }
     u32 fd = *socket_fd; // copy map value into stack (required by older Kernels)
-    bpf_map_update_with_telemetry(fd_by_ssl_bio, &bio, &fd, BPF_ANY);
+    ssl_bio_key_t key = { .tgid = pid_tgid >> 32, .bio = bio };
+    bpf_map_update_with_telemetry(fd_by_ssl_bio, &key, &fd, BPF_ANY);
 cleanup:
     bpf_map_delete_elem(&bio_new_socket_args, &pid_tgid);
     return 0;
@@ -140,12 +160,13 @@ int uprobe__SSL_set_bio(struct pt_regs *ctx) {
     void *ssl_ctx = (void *)PT_REGS_PARM1(ctx);
     void *bio = (void *)PT_REGS_PARM2(ctx);
     log_debug("uprobe/SSL_set_bio: ctx=%llx bio=%llx\n", ssl_ctx, bio);
-    u32 *socket_fd = bpf_map_lookup_elem(&fd_by_ssl_bio, &bio);
+    ssl_bio_key_t key = { .tgid = bpf_get_current_pid_tgid() >> 32, .bio = bio };
+    u32 *socket_fd = bpf_map_lookup_elem(&fd_by_ssl_bio, &key);
     if (socket_fd == NULL) {
         return 0;
     }
     init_ssl_sock(ssl_ctx, *socket_fd);
-    bpf_map_delete_elem(&fd_by_ssl_bio, &bio);
+    bpf_map_delete_elem(&fd_by_ssl_bio, &key);
     return 0;
 }
 
@@ -23,6 +23,11 @@ static __always_inline struct inet_sock *inet_sk(const struct sock *sk)
     return (struct inet_sock *)sk;
 }
 
+static __always_inline struct inet_connection_sock *inet_csk(const struct sock *sk)
+{
+    return (struct inet_connection_sock *)sk;
+}
+
 // source include/net/inet_sock.h
 #define inet_daddr sk.__sk_common.skc_daddr
 #define inet_rcv_saddr sk.__sk_common.skc_rcv_saddr
@@ -79,6 +84,39 @@ static __always_inline void get_tcp_segment_counts(struct sock* skp, __u32* pack
 #endif
 }
 
+#if defined(COMPILE_CORE) || defined(COMPILE_RUNTIME)
+// read_accept_queue reads the current length and the limit of the accept queue of a listening socket.
+// sk_ack_backlog and sk_max_ack_backlog changed width across kernel versions, so on CO-RE we let
+// the loader relocate their size.
+static __always_inline void read_accept_queue(struct sock *skp, __u32 *len, __u32 *max) {
+#ifdef COMPILE_CORE
+    *len = BPF_CORE_READ_BITFIELD_PROBED(skp, sk_ack_backlog);
+    *max = BPF_CORE_READ_BITFIELD_PROBED(skp, sk_max_ack_backlog);
+#else
+    typeof(skp->sk_ack_backlog) ack_backlog = 0;
+    typeof(skp->sk_max_ack_backlog) max_ack_backlog = 0;
+    BPF_CORE_READ_INTO(&ack_backlog, skp, sk_ack_backlog);
+    BPF_CORE_READ_INTO(&max_ack_backlog, skp, sk_max_ack_backlog);
+    *len = ack_backlog;
+    *max = max_ack_backlog;
+#endif
+}
+
+// read_syn_backlog_len reads the number of pending request sockets (half-open connections) of a listening socket
+static __always_inline __u32 read_syn_backlog_len(struct sock *skp) {
+    int qlen = 0;
+    BPF_CORE_READ_INTO(&qlen, inet_csk(skp), icsk_accept_queue.qlen.counter);
+    return qlen < 0 ? 0 : (__u32)qlen;
+}
+
+// read_sk_err reads the pending error of a socket, e.g. the reason a connection attempt failed
+static __always_inline int read_sk_err(struct sock *skp) {
+    int err = 0;
+    BPF_CORE_READ_INTO(&err, skp, sk_err);
+    return err;
+}
+#endif // COMPILE_CORE || COMPILE_RUNTIME
+
 static __always_inline u16 read_sport(struct sock* skp) {
     // try skc_num, then inet_sport
     u16 sport = 0;
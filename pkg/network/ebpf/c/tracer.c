@@ -829,6 +829,139 @@ int kretprobe__tcp_retransmit_skb(struct pt_regs *ctx) {
     return handle_retransmit(sk, retrans_out-retrans_out_pre);
 }
 
+static __always_inline listen_overflow_stats_t *get_listen_overflow_stats(struct sock *sk) {
+    port_binding_t pb = {};
+    pb.netns = get_netns_from_sock(sk);
+    pb.port = read_sport(sk);
+    if (pb.port == 0) {
+        return NULL;
+    }
+
+    listen_overflow_stats_t *stats = bpf_map_lookup_elem(&tcp_listen_overflows, &pb);
+    if (stats) {
+        return stats;
+    }
+
+    listen_overflow_stats_t empty = {};
+    bpf_map_update_with_telemetry(tcp_listen_overflows, &pb, &empty, BPF_NOEXIST);
+    return bpf_map_lookup_elem(&tcp_listen_overflows, &pb);
+}
+
+// tcp_conn_request is called for every SYN received by a listening socket, both for IPv4 and IPv6.
+// It drops the SYN if the accept queue is full, or if the SYN backlog is full and syncookies are not used.
+SEC("kprobe/tcp_conn_request")
+int kprobe__tcp_conn_request(struct pt_regs *ctx) {
+    struct sock *sk = (struct sock *)PT_REGS_PARM3(ctx);
+    listen_overflow_stats_t *stats = get_listen_overflow_stats(sk);
+    if (!stats) {
+        return 0;
+    }
+
+    __u32 len = 0, max = 0;
+    read_accept_queue(sk, &len, &max);
+    stats->accept_queue_len = len;
+    stats->accept_queue_max = max;
+
+    // mirrors sk_acceptq_is_full() and inet_csk_reqsk_queue_is_full() from the kernel
+    if (len > max) {
+        __sync_fetch_and_add(&stats->accept_queue_drops, 1);
+    } else if (read_syn_backlog_len(sk) >= max) {
+        __sync_fetch_and_add(&stats->syn_backlog_drops, 1);
+    }
+
+    return 0;
+}
+
+static __always_inline int handle_syn_recv_sock(struct sock *sk) {
+    __u32 len = 0, max = 0;
+    read_accept_queue(sk, &len, &max);
+    if (len <= max) {
+        return 0;
+    }
+
+    // the three-way handshake completed but there is no room left in the accept queue
+    listen_overflow_stats_t *stats = get_listen_overflow_stats(sk);
+    if (!stats) {
+        return 0;
+    }
+    stats->accept_queue_len = len;
+    stats->accept_queue_max = max;
+    __sync_fetch_and_add(&stats->accept_queue_drops, 1);
+    return 0;
+}
+
+SEC("kprobe/tcp_v4_syn_recv_sock")
+int kprobe__tcp_v4_syn_recv_sock(struct pt_regs *ctx) {
+    return handle_syn_recv_sock((struct sock *)PT_REGS_PARM1(ctx));
+}
+
+SEC("kprobe/tcp_v6_syn_recv_sock")
+int kprobe__tcp_v6_syn_recv_sock(struct pt_regs *ctx) {
+    return handle_syn_recv_sock((struct sock *)PT_REGS_PARM1(ctx));
+}
+
+// errno values from include/uapi/asm-generic/errno.h, which aren't available to CO-RE programs
+#ifndef ECONNRESET
+#define ECONNRESET 104
+#endif
+#ifndef ETIMEDOUT
+#define ETIMEDOUT 110
+#endif
+#ifndef ECONNREFUSED
+#define ECONNREFUSED 111
+#endif
+
+// tcp_done is called when a socket moves to TCP_CLOSE because of an error, e.g. when a RST is received
+// or the SYN retransmits are exhausted. sk_err holds the error reported to the application.
+SEC("kprobe/tcp_done")
+int kprobe__tcp_done(struct pt_regs *ctx) {
+    struct sock *sk = (struct sock *)PT_REGS_PARM1(ctx);
+    // only connections that were never established are tracked; the entry is deleted by tcp_close
+    u64 *pid_tgid_p = bpf_map_lookup_elem(&tcp_ongoing_connect_pid, &sk);
+    if (!pid_tgid_p) {
+        return 0;
+    }
+
+    int err = read_sk_err(sk);
+    if (err != ECONNREFUSED && err != ETIMEDOUT && err != ECONNRESET) {
+        return 0;
+    }
+
+    conn_tuple_t t = {};
+    if (!read_conn_tuple(&t, sk, *pid_tgid_p, CONN_TYPE_TCP)) {
+        return 0;
+    }
+    // failures are aggregated by destination
+    t.saddr_h = 0;
+    t.saddr_l = 0;
+    t.sport = 0;
+    t.pid = 0;
+
+    tcp_failure_stats_t *stats = bpf_map_lookup_elem(&tcp_failures, &t);
+    if (!stats) {
+        tcp_failure_stats_t empty = {};
+        bpf_map_update_with_telemetry(tcp_failures, &t, &empty, BPF_NOEXIST);
+        stats = bpf_map_lookup_elem(&tcp_failures, &t);
+        if (!stats) {
+            return 0;
+        }
+    }
+
+    log_debug("kprobe/tcp_done: failed connection, netns: %u, dport: %u, err: %d\n", t.netns, t.dport, err);
+    switch (err) {
+    case ECONNREFUSED:
+        __sync_fetch_and_add(&stats->refused, 1);
+        break;
+    case ETIMEDOUT:
+        __sync_fetch_and_add(&stats->timed_out, 1);
+        break;
+    case ECONNRESET:
+        __sync_fetch_and_add(&stats->reset, 1);
+        break;
+    }
+    return 0;
+}
+
 #endif // COMPILE_CORE || COMPILE_RUNTIME
 
 SEC("kprobe/tcp_set_state")
@@ -928,6 +1061,7 @@ int kprobe__inet_csk_listen_stop(struct pt_regs *ctx) {
     pb.netns = get_netns_from_sock(skp);
     pb.port = lport;
     remove_port_bind(&pb, &port_bindings);
+    bpf_map_delete_elem(&tcp_listen_overflows, &pb);
 
     log_debug("kprobe/inet_csk_listen_stop: net ns: %u, lport: %u\n", pb.netns, pb.port);
     return 0;
@@ -942,6 +1076,11 @@ static __always_inline int handle_udp_destroy_sock(struct sock *skp) {
     if (valid_tuple) {
         cleanup_conn(&tup, skp);
         lport = tup.sport;
+        // Delete the protocol stack of the flow of a connected socket, e.g. classified as QUIC. Unlike TCP,
+        // no packet is sent once the socket is destroyed, so it is deleted right away.
+        if (is_protocol_classification_supported()) {
+            clean_protocol_classification(&tup);
+        }
     } else {
         lport = read_sport(skp);
     }
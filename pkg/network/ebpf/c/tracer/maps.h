@@ -81,6 +81,18 @@ BPF_HASH_MAP(port_bindings, port_binding_t, __u32, 0)
  */
 BPF_HASH_MAP(udp_port_bindings, port_binding_t, __u32, 0)
 
+/* This map tracks SYN backlog and accept queue overflows of listening TCP sockets.
+ * Key: the network namespace inode together with the listening port
+ * Value: the overflow counters and the last observed accept queue occupancy
+ */
+BPF_HASH_MAP(tcp_listen_overflows, port_binding_t, listen_overflow_stats_t, 4096)
+
+/* This map tracks failed outgoing TCP connection attempts.
+ * Key: the destination of the connection, a conn_tuple_t whose source address, source port and pid are zeroed
+ * Value: the number of attempts that failed, by reason
+ */
+BPF_HASH_MAP(tcp_failures, conn_tuple_t, tcp_failure_stats_t, 4096)
+
 /* Similar to pending_sockets this is used for capturing state between the call and return of the bind() system call.
  *
  * Keys: the PID returned by bpf_get_current_pid_tgid()
@@ -116,6 +116,25 @@ typedef struct {
     __u16 port;
 } port_binding_t;
 
+typedef struct {
+    // number of SYNs seen while the SYN (request socket) backlog was full
+    __u64 syn_backlog_drops;
+    // number of connections dropped because the accept queue was full
+    __u64 accept_queue_drops;
+    // accept queue length and limit observed on the last incoming SYN
+    __u32 accept_queue_len;
+    __u32 accept_queue_max;
+} listen_overflow_stats_t;
+
+typedef struct {
+    // connection attempts answered with a RST (ECONNREFUSED)
+    __u64 refused;
+    // connection attempts that ran out of SYN retransmits (ETIMEDOUT)
+    __u64 timed_out;
+    // connection attempts reset before being established (ECONNRESET)
+    __u64 reset;
+} tcp_failure_stats_t;
+
 typedef struct {
     struct sock *sk;
     struct msghdr *msg;
@@ -24,6 +24,8 @@ type Conn C.conn_t
 type Batch C.batch_t
 type Telemetry C.telemetry_t
 type PortBinding C.port_binding_t
+type ListenOverflowStats C.listen_overflow_stats_t
+type TCPFailureStats C.tcp_failure_stats_t
 type PIDFD C.pid_fd_t
 type UDPRecvSock C.udp_recv_sock_t
 type BindSyscallArgs C.bind_syscall_args_t
@@ -61,6 +61,17 @@ type PortBinding struct {
 	Port      uint16
 	Pad_cgo_0 [2]byte
 }
+type ListenOverflowStats struct {
+	Syn_backlog_drops  uint64
+	Accept_queue_drops uint64
+	Accept_queue_len   uint32
+	Accept_queue_max   uint32
+}
+type TCPFailureStats struct {
+	Refused   uint64
+	Timed_out uint64
+	Reset     uint64
+}
 type PIDFD struct {
 	Pid uint32
 	Fd  uint32
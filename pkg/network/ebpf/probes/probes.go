@@ -147,6 +147,16 @@ const (
 	// TCPRetransmitRet traces the return value for the tcp_retransmit_skb() system call
 	TCPRetransmitRet ProbeFuncName = "kretprobe__tcp_retransmit_skb"
 
+	// TCPConnRequest traces the tcp_conn_request() kernel function, called for SYNs received on listening sockets
+	TCPConnRequest ProbeFuncName = "kprobe__tcp_conn_request"
+	// TCPv4SynRecvSock traces the tcp_v4_syn_recv_sock() kernel function, which fails when the accept queue is full
+	TCPv4SynRecvSock ProbeFuncName = "kprobe__tcp_v4_syn_recv_sock"
+	// TCPv6SynRecvSock traces the tcp_v6_syn_recv_sock() kernel function, which fails when the accept queue is full
+	TCPv6SynRecvSock ProbeFuncName = "kprobe__tcp_v6_syn_recv_sock"
+
+	// TCPDone traces the tcp_done() kernel function, called when a socket is closed because of an error
+	TCPDone ProbeFuncName = "kprobe__tcp_done"
+
 	// InetCskAcceptReturn traces the return value for the inet_csk_accept syscall
 	InetCskAcceptReturn ProbeFuncName = "kretprobe__inet_csk_accept"
 
@@ -189,6 +199,8 @@ const (
 	ConntrackStatusMap                BPFMapName = "conntrack_status"
 	PortBindingsMap                   BPFMapName = "port_bindings"
 	UDPPortBindingsMap                BPFMapName = "udp_port_bindings"
+	TCPListenOverflowsMap             BPFMapName = "tcp_listen_overflows"
+	TCPFailuresMap                    BPFMapName = "tcp_failures"
 	TelemetryMap                      BPFMapName = "telemetry"
 	ConnCloseBatchMap                 BPFMapName = "conn_close_batch"
 	ConntrackMap                      BPFMapName = "conntrack"
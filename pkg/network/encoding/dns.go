@@ -13,6 +13,8 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/network"
 	"github.com/DataDog/datadog-agent/pkg/network/dns"
+	usmdns "github.com/DataDog/datadog-agent/pkg/network/protocols/dns"
+	"github.com/DataDog/datadog-agent/pkg/network/types"
 )
 
 var dnsPool = sync.Pool{
@@ -26,6 +28,8 @@ type dnsFormatter struct {
 	ipc       ipCache
 	domainSet map[string]int
 	seen      map[dns.Key]struct{}
+	// usmStats holds the stats of the DNS monitoring by connection, which carry no domain
+	usmStats map[types.ConnectionKey]map[dns.Hostname]map[dns.QueryType]dns.Stats
 
 	// Configuration flags
 	queryTypeEnabled  bool
@@ -38,6 +42,7 @@ func newDNSFormatter(conns *network.Connections, ipc ipCache) *dnsFormatter {
 		ipc:               ipc,
 		domainSet:         make(map[string]int),
 		seen:              make(map[dns.Key]struct{}),
+		usmStats:          usmDNSStatsByConnection(conns.USMDNS),
 		queryTypeEnabled:  config.SystemProbe.GetBool("network_config.enable_dns_by_querytype"),
 		dnsDomainsEnabled: config.SystemProbe.GetBool("system_probe_config.collect_dns_domains"),
 	}
@@ -56,6 +61,10 @@ func (f *dnsFormatter) FormatConnectionDNS(nc network.ConnectionStats, mc *model
 
 	// Retrieve DNS information for this particular connection
 	stats, ok := f.conns.DNSStats[key]
+	if !ok {
+		// fall back on the stats of the DNS monitoring, which are only used when the DNS snooper has none
+		stats, ok = f.getUSMStats(nc)
+	}
 	if !ok {
 		return
 	}
@@ -92,6 +101,51 @@ func (f *dnsFormatter) FormatConnectionDNS(nc network.ConnectionStats, mc *model
 
 }
 
+func (f *dnsFormatter) getUSMStats(nc network.ConnectionStats) (map[dns.Hostname]map[dns.QueryType]dns.Stats, bool) {
+	for _, key := range network.ConnectionKeysFromConnectionStats(nc) {
+		if stats, ok := f.usmStats[key]; ok {
+			return stats, true
+		}
+	}
+	return nil, false
+}
+
+// usmDNSStatsByConnection converts the stats of the DNS monitoring to the stats of the DNS snooper, under an
+// empty domain
+func usmDNSStatsByConnection(usmStats map[usmdns.Key]*usmdns.RequestStat) map[types.ConnectionKey]map[dns.Hostname]map[dns.QueryType]dns.Stats {
+	if len(usmStats) == 0 {
+		return nil
+	}
+
+	byConnection := make(map[types.ConnectionKey]map[dns.Hostname]map[dns.QueryType]dns.Stats)
+	for key, stat := range usmStats {
+		byDomain, ok := byConnection[key.ConnectionKey]
+		if !ok {
+			byDomain = map[dns.Hostname]map[dns.QueryType]dns.Stats{dns.ToHostname(""): {}}
+			byConnection[key.ConnectionKey] = byDomain
+		}
+		byType := byDomain[dns.ToHostname("")]
+
+		countByRcode := make(map[uint32]uint32, len(stat.ResponseCodes)+1)
+		failed := 0
+		for rcode, count := range stat.ResponseCodes {
+			countByRcode[uint32(rcode)] = uint32(count)
+			failed += count
+		}
+		if succeeded := stat.Count - failed; succeeded > 0 {
+			countByRcode[network.DNSResponseCodeNoError] = uint32(succeeded)
+		}
+
+		// the latencies of the snooper are in microseconds
+		byType[dns.QueryType(key.QueryType)] = dns.Stats{
+			CountByRcode:      countByRcode,
+			SuccessLatencySum: uint64(stat.SuccessLatencySum / 1000),
+			FailureLatencySum: uint64(stat.FailureLatencySum / 1000),
+		}
+	}
+	return byConnection
+}
+
 func (f *dnsFormatter) DNS() map[string]*model.DNSEntry {
 	if f.conns.DNS == nil {
 		return nil
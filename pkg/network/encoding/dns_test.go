@@ -16,6 +16,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/network"
 	"github.com/DataDog/datadog-agent/pkg/network/dns"
+	usmdns "github.com/DataDog/datadog-agent/pkg/network/protocols/dns"
 	"github.com/DataDog/datadog-agent/pkg/process/util"
 )
 
@@ -171,3 +172,47 @@ func TestDNSPIDCollision(t *testing.T) {
 	assert.NotNil(t, out1.DnsStatsByDomain)
 	assert.Nil(t, out2.DnsStatsByDomain)
 }
+
+func TestFormatConnectionUSMDNS(t *testing.T) {
+	client := util.AddressFromString("10.1.1.1")
+	server := util.AddressFromString("8.8.8.8")
+	stats := new(usmdns.RequestStat)
+	stats.AddRequest(0, 2000)
+	stats.AddRequest(0, 4000)
+	stats.AddRequest(3, 5000)
+	payload := &network.Connections{
+		BufferedData: network.BufferedData{
+			Conns: []network.ConnectionStats{
+				{
+					Source:    client,
+					Dest:      server,
+					SPort:     1000,
+					DPort:     53,
+					Type:      network.UDP,
+					Family:    network.AFINET,
+					Direction: network.OUTGOING,
+				},
+			},
+		},
+		USMDNS: map[usmdns.Key]*usmdns.RequestStat{
+			usmdns.NewKey(client, server, 1000, 53, uint16(dns.TypeA)): stats,
+		},
+	}
+
+	config.SystemProbe.Set("system_probe_config.collect_dns_domains", false)
+	config.SystemProbe.Set("network_config.enable_dns_by_querytype", true)
+
+	formatter := newDNSFormatter(payload, make(ipCache))
+	out := new(model.Connection)
+	formatter.FormatConnectionDNS(payload.Conns[0], out)
+
+	assert.Equal(t, uint32(2), out.DnsSuccessfulResponses)
+	assert.Equal(t, uint32(1), out.DnsFailedResponses)
+	assert.Equal(t, map[uint32]uint32{0: 2, 3: 1}, out.DnsCountByRcode)
+	assert.Equal(t, uint64(6), out.DnsSuccessLatencySum)
+	assert.Equal(t, uint64(5), out.DnsFailureLatencySum)
+	// the stats of the DNS monitoring have no domain
+	assert.Equal(t, []string{""}, formatter.Domains())
+	assert.Len(t, out.DnsStatsByDomainByQueryType, 1)
+	assert.Contains(t, out.DnsStatsByDomainByQueryType[0].DnsStatsByQueryType, int32(dns.TypeA))
+}
@@ -82,6 +82,7 @@ func modelConnections(conns *network.Connections) *model.Connections {
 	for i, conn := range conns.Conns {
 		agentConns[i] = FormatConnection(conn, routeIndex, httpEncoder, http2Encoder, kafkaEncoder, dnsFormatter, ipc, tagsSet)
 	}
+	agentConns = append(agentConns, FormatTCPFailures(conns.TCPFailures, ipc, tagsSet)...)
 
 	if http2Encoder != nil && http2Encoder.orphanEntries > 0 {
 		log.Debugf(
@@ -104,6 +104,37 @@ func FormatConnection(
 	return c
 }
 
+// FormatTCPFailures converts the failed TCP connection attempts into outgoing connections without a local address,
+// one per destination and failure reason. The reason is given by the `tcp_failure` tag and the number of attempts
+// by the number of closed connections.
+func FormatTCPFailures(failures map[network.TCPFailureKey]network.TCPFailureCounts, ipc ipCache, tagsSet *network.TagsSet) []*model.Connection {
+	var conns []*model.Connection
+	for key, counts := range failures {
+		for reason, count := range map[string]uint64{
+			"refused":   counts.Refused,
+			"timed_out": counts.TimedOut,
+			"reset":     counts.Reset,
+		} {
+			if count == 0 {
+				continue
+			}
+
+			c := connPool.Get().(*model.Connection)
+			c.Raddr = formatAddr(key.Dest, key.DPort, "", ipc)
+			c.Family = formatFamily(key.Family)
+			c.Type = model.ConnectionType_tcp
+			c.Direction = model.ConnectionDirection_outgoing
+			c.NetNS = key.NetNS
+			c.LastTcpClosed = uint32(count)
+			c.Tags, c.TagsChecksum = formatTags(tagsSet, network.ConnectionStats{
+				Tags: map[string]struct{}{"tcp_failure:" + reason: {}},
+			}, nil)
+			conns = append(conns, c)
+		}
+	}
+	return conns
+}
+
 // FormatCompilationTelemetry converts telemetry from its internal representation to a protobuf message
 func FormatCompilationTelemetry(telByAsset map[string]network.RuntimeCompilationTelemetry) map[string]*model.RuntimeCompilationTelemetry {
 	if telByAsset == nil {
@@ -10,9 +10,11 @@ import (
 	"testing"
 
 	model "github.com/DataDog/agent-payload/v5/process"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/DataDog/datadog-agent/pkg/network"
+	"github.com/DataDog/datadog-agent/pkg/process/util"
 )
 
 func TestFormatRouteIdx(t *testing.T) {
@@ -90,3 +92,31 @@ func BenchmarkConnectionReset(b *testing.B) {
 	}
 	runtime.KeepAlive(c)
 }
+
+func TestFormatTCPFailures(t *testing.T) {
+	failures := map[network.TCPFailureKey]network.TCPFailureCounts{
+		{Dest: util.AddressFromString("10.0.0.2"), DPort: 5432, NetNS: 1, Family: network.AFINET}: {Refused: 3, TimedOut: 1},
+	}
+
+	tagsSet := network.NewTagsSet()
+	conns := FormatTCPFailures(failures, make(ipCache), tagsSet)
+	require.Len(t, conns, 2)
+
+	byTag := make(map[string]*model.Connection)
+	for _, c := range conns {
+		require.Len(t, c.Tags, 1)
+		byTag[tagsSet.GetStrings()[c.Tags[0]]] = c
+	}
+	require.Contains(t, byTag, "tcp_failure:refused")
+	require.Contains(t, byTag, "tcp_failure:timed_out")
+
+	refused := byTag["tcp_failure:refused"]
+	assert.Nil(t, refused.Laddr)
+	assert.Equal(t, &model.Addr{Ip: "10.0.0.2", Port: 5432}, refused.Raddr)
+	assert.Equal(t, model.ConnectionType_tcp, refused.Type)
+	assert.Equal(t, model.ConnectionDirection_outgoing, refused.Direction)
+	assert.Equal(t, model.ConnectionFamily_v4, refused.Family)
+	assert.Equal(t, uint32(1), refused.NetNS)
+	assert.Equal(t, uint32(3), refused.LastTcpClosed)
+	assert.Equal(t, uint32(1), byTag["tcp_failure:timed_out"].LastTcpClosed)
+}
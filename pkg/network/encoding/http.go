@@ -105,6 +105,13 @@ func (e *httpEncoder) encodeData(connectionData *USMConnectionData[http.Key, *ht
 			for _, dynamicTag := range s.DynamicTags {
 				dynamicTags[dynamicTag] = struct{}{}
 			}
+			for _, responseTag := range s.ResponseTags.Tags() {
+				dynamicTags[responseTag] = struct{}{}
+			}
+		}
+
+		if key.ContainerID != "" {
+			dynamicTags["container_id:"+key.ContainerID] = struct{}{}
 		}
 
 		e.aggregations.EndpointAggregations = append(e.aggregations.EndpointAggregations, ms)
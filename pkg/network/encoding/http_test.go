@@ -195,6 +195,43 @@ func testFormatHTTPStatsByPath(t *testing.T, aggregateByStatusCode bool) {
 	assert.False(t, exists)
 }
 
+func TestFormatHTTPStatsContainerID(t *testing.T) {
+	httpReqStats := http.NewRequestStats(true)
+	httpReqStats.AddRequest(200, 12.5, 0, nil)
+
+	key := http.NewKey(
+		util.AddressFromString("10.1.1.1"),
+		util.AddressFromString("10.2.2.2"),
+		60000,
+		80,
+		"/testpath",
+		true,
+		http.MethodGet,
+	)
+	key.ContainerID = "abcdef"
+
+	payload := &network.Connections{
+		BufferedData: network.BufferedData{
+			Conns: []network.ConnectionStats{
+				{
+					Source: util.AddressFromString("10.1.1.1"),
+					Dest:   util.AddressFromString("10.2.2.2"),
+					SPort:  60000,
+					DPort:  80,
+				},
+			},
+		},
+		HTTP: map[http.Key]*http.RequestStats{
+			key: httpReqStats,
+		},
+	}
+	httpEncoder := newHTTPEncoder(payload)
+	httpAggregations, _, dynamicTags := getHTTPAggregations(t, httpEncoder, payload.Conns[0])
+
+	require.Len(t, httpAggregations.EndpointAggregations, 1)
+	assert.Contains(t, dynamicTags, "container_id:abcdef")
+}
+
 func TestIDCollisionRegression(t *testing.T) {
 	t.Run("status code", func(t *testing.T) {
 		testIDCollisionRegression(t, true)
@@ -83,6 +83,12 @@ func formatProtocol(proto protocols.ProtocolType) model.ProtocolType {
 		return model.ProtocolType_protocolRedis
 	case protocols.MySQL:
 		return model.ProtocolType_protocolMySQL
+	case protocols.QUIC:
+		// QUIC has no protobuf representation yet
+		return model.ProtocolType_protocolUnknown
+	case protocols.DNS:
+		// DNS has no protobuf representation yet, and isn't part of the protocol stack of the UDP connections
+		return model.ProtocolType_protocolUnknown
 	default:
 		log.Warnf("missing protobuf representation for protocol %d", proto)
 		return model.ProtocolType_protocolUnknown
@@ -15,6 +15,7 @@ import (
 
 	"github.com/DataDog/datadog-agent/pkg/network/dns"
 	"github.com/DataDog/datadog-agent/pkg/network/protocols"
+	usmdns "github.com/DataDog/datadog-agent/pkg/network/protocols/dns"
 	"github.com/DataDog/datadog-agent/pkg/network/protocols/http"
 	"github.com/DataDog/datadog-agent/pkg/network/protocols/kafka"
 	"github.com/DataDog/datadog-agent/pkg/process/util"
@@ -136,7 +137,27 @@ type Connections struct {
 	HTTP                        map[http.Key]*http.RequestStats
 	HTTP2                       map[http.Key]*http.RequestStats
 	Kafka                       map[kafka.Key]*kafka.RequestStat
+	USMDNS                      map[usmdns.Key]*usmdns.RequestStat
 	DNSStats                    dns.StatsByKeyByNameByType
+	TCPFailures                 map[TCPFailureKey]TCPFailureCounts
+}
+
+// TCPFailureKey identifies the destination of failed TCP connection attempts
+type TCPFailureKey struct {
+	Dest   util.Address
+	DPort  uint16
+	NetNS  uint32
+	Family ConnectionFamily
+}
+
+// TCPFailureCounts holds the number of failed TCP connection attempts to a destination, by reason
+type TCPFailureCounts struct {
+	// Refused is the number of attempts answered with a RST
+	Refused uint64
+	// TimedOut is the number of attempts that got no answer
+	TimedOut uint64
+	// Reset is the number of attempts reset before the connection was established
+	Reset uint64
 }
 
 // ConnTelemetryType enumerates the connection telemetry gathered by the system-probe
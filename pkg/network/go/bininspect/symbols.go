@@ -158,6 +158,12 @@ func getSymbolsUnified(f *elf.File, typ elf.SectionType, wantedSymbols common.St
 		if err := fillSymbol(&symbol, symbolSection.ReaderAt, f.ByteOrder, symbolName, readLocation+4, allocatedBufferForSymbolRead, is64Bit); err != nil {
 			continue
 		}
+		// The symbols imported from another library have no address, the definition of the symbol is looked for in
+		// the rest of the section. The names of the dynamic symbols don't hold their version (found in the
+		// .gnu.version section, absent from the libraries built for musl), the first definition is used.
+		if symbol.Section == elf.SHN_UNDEF {
+			continue
+		}
 		symbols = append(symbols, symbol)
 
 		// If no symbols left, stop running.
@@ -70,6 +70,9 @@ type realConntracker struct {
 	// The maximum size the state map will grow before we reject new entries
 	maxStateSize int
 
+	// destinations restricts the tracked translations when partial tracking is enabled
+	destinations *destinationFilter
+
 	compactTicker *time.Ticker
 	exit          chan struct{}
 }
@@ -83,6 +86,8 @@ var conntrackerTelemetry = struct {
 	unregistersTotal    telemetry.Counter
 	evictsTotal         telemetry.Counter
 	registersDropped    telemetry.Counter
+	partialTracked      telemetry.Counter
+	partialSkipped      telemetry.Counter
 	stateSize           telemetry.Gauge
 	orphanSize          telemetry.Gauge
 }{
@@ -94,6 +99,8 @@ var conntrackerTelemetry = struct {
 	telemetry.NewCounter(telemetryModuleName, "unregisters_total", []string{}, "Counter measuring the total number of attempts to delete connection tuples from the map"),
 	telemetry.NewCounter(telemetryModuleName, "evicts_total", []string{}, "Counter measuring the number of evictions from the conntrack cache"),
 	telemetry.NewCounter(telemetryModuleName, "registers_dropped", []string{}, "Counter measuring the number of skipped registers due to a non-NAT connection"),
+	telemetry.NewCounter(telemetryModuleName, "partial_tracked", []string{}, "Counter measuring the number of NAT translations tracked because their destination matches the partial tracking CIDRs"),
+	telemetry.NewCounter(telemetryModuleName, "partial_skipped", []string{}, "Counter measuring the number of NAT translations skipped because their destination doesn't match the partial tracking CIDRs"),
 	telemetry.NewGauge(telemetryModuleName, "state_size", []string{}, "Gauge measuring the current size of the conntrack cache"),
 	telemetry.NewGauge(telemetryModuleName, "orphan_size", []string{}, "Gauge measuring the number of orphaned items in the conntrack cache"),
 }
@@ -133,6 +140,10 @@ func newConntrackerOnce(cfg *config.Config) (Conntracker, error) {
 		compactTicker: time.NewTicker(compactInterval),
 		exit:          make(chan struct{}),
 		decoder:       NewDecoder(),
+		destinations:  newDestinationFilter(cfg.ConntrackPartialTrackingCIDRs),
+	}
+	if ctr.destinations != nil {
+		log.Infof("conntrack partial tracking enabled, only tracking translations for destinations in %v", cfg.ConntrackPartialTrackingCIDRs)
 	}
 
 	for _, family := range []uint8{unix.AF_INET, unix.AF_INET6} {
@@ -224,7 +235,7 @@ func (ctr *realConntracker) loadInitialState(events <-chan Event) {
 	for e := range events {
 		conns := ctr.decoder.DecodeAndReleaseEvent(e)
 		for _, c := range conns {
-			if !IsNAT(c) {
+			if !IsNAT(c) || !ctr.shouldTrack(c) {
 				continue
 			}
 			then := time.Now()
@@ -246,6 +257,9 @@ func (ctr *realConntracker) register(c Con) int {
 		conntrackerTelemetry.registersDropped.Inc()
 		return 0
 	}
+	if !ctr.shouldTrack(c) {
+		return 0
+	}
 	then := time.Now()
 
 	ctr.Lock()
@@ -260,6 +274,20 @@ func (ctr *realConntracker) register(c Con) int {
 	return 0
 }
 
+// shouldTrack returns whether the translation of the given NAT connection should be stored
+// when partial tracking is enabled
+func (ctr *realConntracker) shouldTrack(c Con) bool {
+	if ctr.destinations == nil {
+		return true
+	}
+	if !ctr.destinations.matches(c) {
+		conntrackerTelemetry.partialSkipped.Inc()
+		return false
+	}
+	conntrackerTelemetry.partialTracked.Inc()
+	return true
+}
+
 func (ctr *realConntracker) run() error {
 	events, err := ctr.consumer.Events()
 	if err != nil {
@@ -121,6 +121,54 @@ func TestRegisterNat(t *testing.T) {
 
 }
 
+func TestRegisterNatPartialTracking(t *testing.T) {
+	rt := newConntracker(10000)
+	rt.destinations = newDestinationFilter([]string{"50.30.0.0/16", "invalid", "2001:db8::1"})
+	require.NotNil(t, rt.destinations)
+
+	tracked := makeTranslatedConn(netip.MustParseAddr("10.0.0.0"), netip.MustParseAddr("20.0.0.0"), netip.MustParseAddr("50.30.40.10"), 6, 12345, 80, 80)
+	skipped := makeTranslatedConn(netip.MustParseAddr("10.0.0.0"), netip.MustParseAddr("20.0.0.0"), netip.MustParseAddr("60.30.40.10"), 6, 12346, 80, 80)
+	trackedV6 := makeTranslatedConn(netip.MustParseAddr("2001:db8::2"), netip.MustParseAddr("2001:db8::1"), netip.MustParseAddr("2001:db8::3"), 6, 12347, 80, 80)
+
+	rt.register(tracked)
+	rt.register(skipped)
+	rt.register(trackedV6)
+	// each tracked translation is stored for both directions
+	assert.Equal(t, 4, rt.cache.Len())
+
+	assert.NotNil(t, rt.GetTranslationForConn(
+		network.ConnectionStats{
+			Source: util.AddressFromString("10.0.0.0"),
+			SPort:  12345,
+			Dest:   util.AddressFromString("50.30.40.10"),
+			DPort:  80,
+			Type:   network.TCP,
+		},
+	))
+	assert.Nil(t, rt.GetTranslationForConn(
+		network.ConnectionStats{
+			Source: util.AddressFromString("10.0.0.0"),
+			SPort:  12346,
+			Dest:   util.AddressFromString("60.30.40.10"),
+			DPort:  80,
+			Type:   network.TCP,
+		},
+	))
+}
+
+func TestNewDestinationFilter(t *testing.T) {
+	assert.Nil(t, newDestinationFilter(nil))
+	assert.Nil(t, newDestinationFilter([]string{"invalid", "10.0.0.0/33"}))
+
+	f := newDestinationFilter([]string{"10.1.2.3/8", "192.168.1.10"})
+	require.NotNil(t, f)
+	assert.Equal(t, []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8"), netip.MustParsePrefix("192.168.1.10/32")}, f.prefixes)
+	assert.True(t, f.contains(netip.MustParseAddr("10.200.0.1")))
+	assert.True(t, f.contains(netip.MustParseAddr("::ffff:10.200.0.1")))
+	assert.True(t, f.contains(netip.MustParseAddr("192.168.1.10")))
+	assert.False(t, f.contains(netip.MustParseAddr("192.168.1.11")))
+}
+
 func TestRegisterNatUDP(t *testing.T) {
 	rt := newConntracker(10000)
 	c := makeTranslatedConn(netip.MustParseAddr("10.0.0.0"), netip.MustParseAddr("20.0.0.0"), netip.MustParseAddr("50.30.40.10"), 17, 12345, 80, 80)
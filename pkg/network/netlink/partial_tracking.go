@@ -0,0 +1,71 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package netlink
+
+import (
+	"net/netip"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// destinationFilter restricts the translations stored by the conntracker to the
+// connections whose destination belongs to one of the configured CIDRs. It is used
+// to bound the memory of the conntracker on hosts with very large conntrack tables.
+type destinationFilter struct {
+	prefixes []netip.Prefix
+}
+
+// newDestinationFilter returns a destinationFilter matching the given CIDRs or IPs.
+// Invalid entries are ignored. It returns nil if no valid entry was given, in which case
+// all translations are tracked.
+func newDestinationFilter(cidrs []string) *destinationFilter {
+	var prefixes []netip.Prefix
+	for _, cidr := range cidrs {
+		var prefix netip.Prefix
+		var err error
+		if strings.ContainsRune(cidr, '/') {
+			prefix, err = netip.ParsePrefix(cidr)
+		} else {
+			var addr netip.Addr
+			if addr, err = netip.ParseAddr(cidr); err == nil {
+				prefix = netip.PrefixFrom(addr, addr.BitLen())
+			}
+		}
+		if err != nil {
+			log.Errorf("ignoring invalid conntrack partial tracking CIDR %q: %s", cidr, err)
+			continue
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+
+	if len(prefixes) == 0 {
+		return nil
+	}
+	return &destinationFilter{prefixes: prefixes}
+}
+
+// matches returns whether the translation of the given connection should be tracked. Both the
+// original destination of the connection and the destination it was translated to are considered.
+func (f *destinationFilter) matches(c Con) bool {
+	if f == nil {
+		return true
+	}
+	return f.contains(c.Origin.Dst.Addr()) || f.contains(c.Reply.Src.Addr())
+}
+
+func (f *destinationFilter) contains(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, prefix := range f.prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
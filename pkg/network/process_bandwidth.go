@@ -0,0 +1,58 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package network
+
+import "sort"
+
+// ProcessBandwidth is the number of bytes sent and received by a process over the last interval
+type ProcessBandwidth struct {
+	Pid         uint32 `json:"pid"`
+	SentBytes   uint64 `json:"sent_bytes"`
+	RecvBytes   uint64 `json:"recv_bytes"`
+	Connections int    `json:"connections"`
+}
+
+// TotalBytes returns the number of bytes sent and received by the process
+func (b ProcessBandwidth) TotalBytes() uint64 {
+	return b.SentBytes + b.RecvBytes
+}
+
+// TopProcessesByBandwidth sums the bytes sent and received by each process over the last interval,
+// i.e. the Last counters of the connections, and returns the n processes which sent and received
+// the most bytes, from the biggest consumer to the smallest. All the processes are returned when
+// n is not positive. Connections without any traffic over the interval are ignored.
+func TopProcessesByBandwidth(conns []ConnectionStats, n int) []ProcessBandwidth {
+	byPID := make(map[uint32]*ProcessBandwidth)
+	for i := range conns {
+		c := &conns[i]
+		if c.Last.SentBytes == 0 && c.Last.RecvBytes == 0 {
+			continue
+		}
+		b, ok := byPID[c.Pid]
+		if !ok {
+			b = &ProcessBandwidth{Pid: c.Pid}
+			byPID[c.Pid] = b
+		}
+		b.SentBytes += c.Last.SentBytes
+		b.RecvBytes += c.Last.RecvBytes
+		b.Connections++
+	}
+
+	result := make([]ProcessBandwidth, 0, len(byPID))
+	for _, b := range byPID {
+		result = append(result, *b)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].TotalBytes() != result[j].TotalBytes() {
+			return result[i].TotalBytes() > result[j].TotalBytes()
+		}
+		return result[i].Pid < result[j].Pid
+	})
+	if n > 0 && len(result) > n {
+		result = result[:n]
+	}
+	return result
+}
@@ -0,0 +1,36 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package network
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTopProcessesByBandwidth(t *testing.T) {
+	conns := []ConnectionStats{
+		{Pid: 1, Last: StatCounters{SentBytes: 100, RecvBytes: 50}, Monotonic: StatCounters{SentBytes: 10000}},
+		{Pid: 1, Last: StatCounters{SentBytes: 10, RecvBytes: 5}},
+		{Pid: 2, Last: StatCounters{RecvBytes: 1000}},
+		{Pid: 3, Last: StatCounters{SentBytes: 165}},
+		// idle over the last interval
+		{Pid: 4, Monotonic: StatCounters{SentBytes: 1 << 20}},
+	}
+
+	assert.Equal(t, []ProcessBandwidth{
+		{Pid: 2, RecvBytes: 1000, Connections: 1},
+		{Pid: 1, SentBytes: 110, RecvBytes: 55, Connections: 2},
+		{Pid: 3, SentBytes: 165, Connections: 1},
+	}, TopProcessesByBandwidth(conns, 0))
+
+	assert.Equal(t, []ProcessBandwidth{
+		{Pid: 2, RecvBytes: 1000, Connections: 1},
+		{Pid: 1, SentBytes: 110, RecvBytes: 55, Connections: 2},
+	}, TopProcessesByBandwidth(conns, 2))
+
+	assert.Empty(t, TopProcessesByBandwidth(nil, 10))
+}
@@ -0,0 +1,103 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package debugging
+
+import (
+	"github.com/DataDog/sketches-go/ddsketch"
+	"github.com/google/gopacket/layers"
+
+	"github.com/DataDog/datadog-agent/pkg/network/protocols/dns"
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+)
+
+// RequestSummary represents a (debug-friendly) aggregated view of the DNS queries
+// sent by a client to a server
+type RequestSummary struct {
+	Client      Address
+	Server      Address
+	ByQueryType map[string]Stats
+}
+
+// Address represents represents a IP:Port
+type Address struct {
+	IP   string
+	Port uint16
+}
+
+// Stats consolidates query count, response code and latency information for a certain query type
+type Stats struct {
+	Count              int
+	ResponseCodes      map[string]int `json:",omitempty"`
+	FirstLatencySample float64
+	LatencyP50         float64
+}
+
+// DNS returns a debug-friendly representation of map[dns.Key]dns.RequestStat
+func DNS(stats map[dns.Key]*dns.RequestStat) []RequestSummary {
+	summaries := make(map[dns.Key]*RequestSummary, len(stats))
+
+	for key, requestStat := range stats {
+		connKey := dns.Key{ConnectionKey: key.ConnectionKey}
+		summary, ok := summaries[connKey]
+		if !ok {
+			clientAddr := formatIP(key.SrcIPLow, key.SrcIPHigh)
+			serverAddr := formatIP(key.DstIPLow, key.DstIPHigh)
+			summary = &RequestSummary{
+				Client: Address{
+					IP:   clientAddr.String(),
+					Port: key.SrcPort,
+				},
+				Server: Address{
+					IP:   serverAddr.String(),
+					Port: key.DstPort,
+				},
+				ByQueryType: make(map[string]Stats),
+			}
+			summaries[connKey] = summary
+		}
+
+		var responseCodes map[string]int
+		for rcode, count := range requestStat.ResponseCodes {
+			if responseCodes == nil {
+				responseCodes = make(map[string]int)
+			}
+			responseCodes[layers.DNSResponseCode(rcode).String()] = count
+		}
+
+		summary.ByQueryType[layers.DNSType(key.QueryType).String()] = Stats{
+			Count:              requestStat.Count,
+			ResponseCodes:      responseCodes,
+			FirstLatencySample: requestStat.FirstLatencySample,
+			LatencyP50:         getSketchQuantile(requestStat.Latencies, 0.5),
+		}
+	}
+
+	all := make([]RequestSummary, 0, len(summaries))
+	for _, summary := range summaries {
+		all = append(all, *summary)
+	}
+	return all
+}
+
+func formatIP(low, high uint64) util.Address {
+	// TODO: this is  not correct, but we don't have socket family information
+	// for DNS at the moment, so given this is purely debugging code I think it's fine
+	// to assume for now that it's only IPv6 if higher order bits are set.
+	if high > 0 || (low>>32) > 0 {
+		return util.V6Address(low, high)
+	}
+
+	return util.V4Address(uint32(low))
+}
+
+func getSketchQuantile(sketch *ddsketch.DDSketch, percentile float64) float64 {
+	if sketch == nil {
+		return 0.0
+	}
+
+	val, _ := sketch.GetValueAtQuantile(percentile)
+	return val
+}
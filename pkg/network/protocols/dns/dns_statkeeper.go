@@ -0,0 +1,61 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux_bpf
+// +build linux_bpf
+
+package dns
+
+import (
+	"sync"
+
+	"github.com/DataDog/datadog-agent/pkg/network/config"
+)
+
+// StatKeeper aggregates the DNS transactions by connection and query type
+type StatKeeper struct {
+	stats      map[Key]*RequestStat
+	statsMutex sync.Mutex
+	maxEntries int
+	telemetry  *Telemetry
+}
+
+// NewStatkeeper returns a new StatKeeper
+func NewStatkeeper(c *config.Config, telemetry *Telemetry) *StatKeeper {
+	return &StatKeeper{
+		stats:      make(map[Key]*RequestStat),
+		maxEntries: c.MaxUSMDNSStatsBuffered,
+		telemetry:  telemetry,
+	}
+}
+
+// Process adds a transaction to the stats
+func (statKeeper *StatKeeper) Process(tx *EbpfDNSTx) {
+	key := Key{
+		QueryType:     tx.QueryType(),
+		ConnectionKey: tx.ConnTuple(),
+	}
+	statKeeper.statsMutex.Lock()
+	defer statKeeper.statsMutex.Unlock()
+	requestStats, ok := statKeeper.stats[key]
+	if !ok {
+		if len(statKeeper.stats) >= statKeeper.maxEntries {
+			statKeeper.telemetry.dropped.Add(1)
+			return
+		}
+		requestStats = new(RequestStat)
+		statKeeper.stats[key] = requestStats
+	}
+	requestStats.AddRequest(tx.ResponseCode(), tx.RequestLatency())
+}
+
+// GetAndResetAllStats returns the stats aggregated since the last call
+func (statKeeper *StatKeeper) GetAndResetAllStats() map[Key]*RequestStat {
+	statKeeper.statsMutex.Lock()
+	defer statKeeper.statsMutex.Unlock()
+	ret := statKeeper.stats // No deep copy needed since `statKeeper.stats` gets reset
+	statKeeper.stats = make(map[Key]*RequestStat)
+	return ret
+}
@@ -0,0 +1,133 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package dns
+
+import (
+	"github.com/DataDog/sketches-go/ddsketch"
+
+	"github.com/DataDog/datadog-agent/pkg/network/types"
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// RelativeAccuracy defines the acceptable error in quantile values calculated by DDSketch.
+// For example, if the actual value at p50 is 100, with a relative accuracy of 0.01 the value calculated
+// will be between 99 and 101
+const RelativeAccuracy = 0.01
+
+// Key is an identifier for a group of DNS transactions
+type Key struct {
+	QueryType uint16
+	types.ConnectionKey
+}
+
+// NewKey generates a new Key
+func NewKey(saddr, daddr util.Address, sport, dport uint16, queryType uint16) Key {
+	return Key{
+		ConnectionKey: types.NewConnectionKey(saddr, daddr, sport, dport),
+		QueryType:     queryType,
+	}
+}
+
+// RequestStat stores stats for the DNS queries to a particular key
+type RequestStat struct {
+	Count int
+	// ResponseCodes holds the number of queries per response code, for the queries which failed
+	ResponseCodes map[uint8]int
+	// Latencies holds the latencies of the queries, in nanoseconds
+	Latencies *ddsketch.DDSketch
+	// FirstLatencySample holds the latency of the first query, the sketch being only created once
+	// there is more than one latency sample
+	FirstLatencySample float64
+	// SuccessLatencySum and FailureLatencySum hold the sum of the latencies of the queries which respectively
+	// succeeded and failed, in nanoseconds
+	SuccessLatencySum float64
+	FailureLatencySum float64
+}
+
+// AddRequest takes information about a DNS transaction and adds it to the request stats
+func (r *RequestStat) AddRequest(responseCode uint8, latency float64) {
+	if responseCode != 0 {
+		if r.ResponseCodes == nil {
+			r.ResponseCodes = make(map[uint8]int)
+		}
+		r.ResponseCodes[responseCode]++
+		r.FailureLatencySum += latency
+	} else {
+		r.SuccessLatencySum += latency
+	}
+	r.addLatency(latency)
+}
+
+// addLatency counts a query along with its latency
+func (r *RequestStat) addLatency(latency float64) {
+	r.Count++
+	if r.Count == 1 {
+		// We postpone the creation of histograms when we have only one latency sample
+		r.FirstLatencySample = latency
+		return
+	}
+
+	if r.Latencies == nil {
+		if err := r.initSketch(); err != nil {
+			return
+		}
+		// we now have enough latencies to use the sketch, adding the first sample to it
+		if err := r.Latencies.Add(r.FirstLatencySample); err != nil {
+			log.Debugf("could not add dns query latency to ddsketch: %v", err)
+		}
+	}
+	if err := r.Latencies.Add(latency); err != nil {
+		log.Debugf("could not add dns query latency to ddsketch: %v", err)
+	}
+}
+
+func (r *RequestStat) initSketch() (err error) {
+	r.Latencies, err = ddsketch.NewDefaultDDSketch(RelativeAccuracy)
+	if err != nil {
+		log.Debugf("error recording dns transaction latency: could not create new ddsketch: %v", err)
+	}
+	return
+}
+
+// CombineWith merges the data in 2 RequestStats objects
+// newStats is kept as it is, while the method receiver gets mutated
+func (r *RequestStat) CombineWith(newStats *RequestStat) {
+	for responseCode, count := range newStats.ResponseCodes {
+		if r.ResponseCodes == nil {
+			r.ResponseCodes = make(map[uint8]int)
+		}
+		r.ResponseCodes[responseCode] += count
+	}
+	r.SuccessLatencySum += newStats.SuccessLatencySum
+	r.FailureLatencySum += newStats.FailureLatencySum
+
+	// every query has a latency sample
+	switch {
+	case newStats.Count == 0:
+		return
+	case newStats.Count == 1:
+		// The other stats have a single latency sample, so we "manually" add it
+		r.addLatency(newStats.FirstLatencySample)
+		return
+	case r.Latencies == nil:
+		latencies := newStats.Latencies.Copy()
+		if r.Count == 1 {
+			if err := latencies.Add(r.FirstLatencySample); err != nil {
+				log.Debugf("could not add dns query latency to ddsketch: %v", err)
+			}
+		}
+		r.Latencies = latencies
+	default:
+		if err := r.Latencies.MergeWith(newStats.Latencies); err != nil {
+			log.Debugf("error merging dns queries: %v", err)
+		}
+	}
+	if r.Count == 0 {
+		r.FirstLatencySample = newStats.FirstLatencySample
+	}
+	r.Count += newStats.Count
+}
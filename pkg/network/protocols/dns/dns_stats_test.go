@@ -0,0 +1,134 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux_bpf
+// +build linux_bpf
+
+package dns
+
+import (
+	"testing"
+
+	"github.com/google/gopacket/layers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/network/config"
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+)
+
+func TestAddRequest(t *testing.T) {
+	stats := new(RequestStat)
+	stats.AddRequest(0, 10.0)
+	assert.Equal(t, 1, stats.Count)
+	assert.Equal(t, 10.0, stats.FirstLatencySample)
+	assert.Nil(t, stats.Latencies)
+
+	stats.AddRequest(0, 15.0)
+	stats.AddRequest(uint8(layers.DNSResponseCodeNXDomain), 20.0)
+
+	assert.Equal(t, 3, stats.Count)
+	assert.Equal(t, map[uint8]int{uint8(layers.DNSResponseCodeNXDomain): 1}, stats.ResponseCodes)
+	require.NotNil(t, stats.Latencies)
+	assert.Equal(t, 3.0, stats.Latencies.GetCount())
+	verifyQuantile(t, stats, 0.0, 10.0)
+	verifyQuantile(t, stats, 1.0, 20.0)
+}
+
+func TestCombineWith(t *testing.T) {
+	stats := new(RequestStat)
+	stats.CombineWith(new(RequestStat))
+	assert.Equal(t, 0, stats.Count)
+
+	single := new(RequestStat)
+	single.AddRequest(0, 10.0)
+	stats.CombineWith(single)
+	assert.Equal(t, 1, stats.Count)
+	assert.Equal(t, 10.0, stats.FirstLatencySample)
+	assert.Nil(t, stats.Latencies)
+
+	multiple := new(RequestStat)
+	multiple.AddRequest(uint8(layers.DNSResponseCodeNXDomain), 20.0)
+	multiple.AddRequest(0, 30.0)
+	stats.CombineWith(multiple)
+	stats.CombineWith(multiple)
+
+	assert.Equal(t, 5, stats.Count)
+	assert.Equal(t, 70.0, stats.SuccessLatencySum)
+	assert.Equal(t, 40.0, stats.FailureLatencySum)
+	assert.Equal(t, map[uint8]int{uint8(layers.DNSResponseCodeNXDomain): 2}, stats.ResponseCodes)
+	require.NotNil(t, stats.Latencies)
+	assert.Equal(t, 5.0, stats.Latencies.GetCount())
+	verifyQuantile(t, stats, 0.0, 10.0)
+	verifyQuantile(t, stats, 1.0, 30.0)
+	// the merged stats are left untouched
+	assert.Equal(t, 2, multiple.Count)
+	assert.Equal(t, 2.0, multiple.Latencies.GetCount())
+}
+
+func TestProcessByQueryType(t *testing.T) {
+	cfg := config.New()
+	cfg.MaxUSMDNSStatsBuffered = 1000
+	sk := NewStatkeeper(cfg, NewTelemetry())
+
+	client := util.AddressFromString("1.1.1.1")
+	server := util.AddressFromString("8.8.8.8")
+	for _, queryType := range []layers.DNSType{layers.DNSTypeA, layers.DNSTypeAAAA, layers.DNSTypeA} {
+		tx := generateIPv4DNSTransaction(client, server, 55000, 53, uint16(queryType), 0, 1000)
+		sk.Process(tx)
+	}
+	sk.Process(generateIPv4DNSTransaction(client, server, 55000, 53, uint16(layers.DNSTypeA), uint8(layers.DNSResponseCodeServFail), 3000))
+
+	stats := sk.GetAndResetAllStats()
+	require.Len(t, stats, 2)
+
+	aStats := stats[NewKey(client, server, 55000, 53, uint16(layers.DNSTypeA))]
+	require.NotNil(t, aStats)
+	assert.Equal(t, 3, aStats.Count)
+	assert.Equal(t, map[uint8]int{uint8(layers.DNSResponseCodeServFail): 1}, aStats.ResponseCodes)
+	verifyQuantile(t, aStats, 1.0, 3000)
+
+	aaaaStats := stats[NewKey(client, server, 55000, 53, uint16(layers.DNSTypeAAAA))]
+	require.NotNil(t, aaaaStats)
+	assert.Equal(t, 1, aaaaStats.Count)
+	assert.Equal(t, 1000.0, aaaaStats.FirstLatencySample)
+
+	assert.Empty(t, sk.GetAndResetAllStats())
+}
+
+func TestProcessMaxEntries(t *testing.T) {
+	cfg := config.New()
+	cfg.MaxUSMDNSStatsBuffered = 1
+	sk := NewStatkeeper(cfg, NewTelemetry())
+
+	client := util.AddressFromString("1.1.1.1")
+	server := util.AddressFromString("8.8.8.8")
+	sk.Process(generateIPv4DNSTransaction(client, server, 55000, 53, uint16(layers.DNSTypeA), 0, 1000))
+	sk.Process(generateIPv4DNSTransaction(client, server, 55000, 53, uint16(layers.DNSTypeAAAA), 0, 1000))
+
+	assert.Len(t, sk.GetAndResetAllStats(), 1)
+}
+
+func generateIPv4DNSTransaction(client, server util.Address, cport, sport uint16, queryType uint16, rcode uint8, latencyNS uint64) *EbpfDNSTx {
+	var tx EbpfDNSTx
+	tx.Tup.Saddr_l, tx.Tup.Saddr_h = util.ToLowHigh(client)
+	tx.Tup.Daddr_l, tx.Tup.Daddr_h = util.ToLowHigh(server)
+	tx.Tup.Sport = cport
+	tx.Tup.Dport = sport
+	tx.Query_type = queryType
+	tx.Rcode = rcode
+	tx.Request_started = 1
+	tx.Response_last_seen = tx.Request_started + latencyNS
+	return &tx
+}
+
+func verifyQuantile(t *testing.T, stats *RequestStat, q float64, expectedValue float64) {
+	val, err := stats.Latencies.GetValueAtQuantile(q)
+	assert.Nil(t, err)
+
+	acceptableError := expectedValue * RelativeAccuracy
+	assert.True(t, val >= expectedValue-acceptableError)
+	assert.True(t, val <= expectedValue+acceptableError)
+}
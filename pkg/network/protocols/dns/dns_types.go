@@ -0,0 +1,19 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build ignore
+// +build ignore
+
+package dns
+
+/*
+#include "../../ebpf/c/conn_tuple.h"
+#include "../../ebpf/c/protocols/dns/types.h"
+*/
+import "C"
+
+type dnsConnTuple C.conn_tuple_t
+
+type EbpfDNSTx C.dns_transaction_batch_entry_t
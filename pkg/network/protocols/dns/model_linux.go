@@ -0,0 +1,41 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux_bpf
+// +build linux_bpf
+
+package dns
+
+import "github.com/DataDog/datadog-agent/pkg/network/types"
+
+// ConnTuple returns the tuple of the query, from the client to the server
+func (tx *EbpfDNSTx) ConnTuple() types.ConnectionKey {
+	return types.ConnectionKey{
+		SrcIPHigh: tx.Tup.Saddr_h,
+		SrcIPLow:  tx.Tup.Saddr_l,
+		DstIPHigh: tx.Tup.Daddr_h,
+		DstIPLow:  tx.Tup.Daddr_l,
+		SrcPort:   tx.Tup.Sport,
+		DstPort:   tx.Tup.Dport,
+	}
+}
+
+// QueryType returns the type of the question of the query, such as A or AAAA
+func (tx *EbpfDNSTx) QueryType() uint16 {
+	return tx.Query_type
+}
+
+// ResponseCode returns the response code, 0 if the query succeeded
+func (tx *EbpfDNSTx) ResponseCode() uint8 {
+	return tx.Rcode
+}
+
+// RequestLatency returns the latency of the query in nanoseconds
+func (tx *EbpfDNSTx) RequestLatency() float64 {
+	if tx.Request_started == 0 || tx.Response_last_seen < tx.Request_started {
+		return 0
+	}
+	return float64(tx.Response_last_seen - tx.Request_started)
+}
@@ -32,6 +32,9 @@ const (
 	ProgramHTTP  ProgramType = C.PROG_HTTP
 	ProgramHTTP2 ProgramType = C.PROG_HTTP2
 	ProgramKafka ProgramType = C.PROG_KAFKA
+	ProgramDNS   ProgramType = C.PROG_DNS
+
+	ProgramHTTPResponseHeaders ProgramType = C.PROG_HTTP_RESPONSE_HEADERS
 )
 
 func Application(protoNum uint8) ProtocolType {
@@ -73,6 +76,10 @@ func toProtocolType(protoNum uint8, layerBit uint16) ProtocolType {
 		return Redis
 	case C.PROTOCOL_MYSQL:
 		return MySQL
+	case C.PROTOCOL_QUIC:
+		return QUIC
+	case C.PROTOCOL_DNS:
+		return DNS
 	default:
 		log.Errorf("unknown eBPF protocol type: %x", protocol)
 		return Unknown
@@ -0,0 +1,112 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux_bpf
+// +build linux_bpf
+
+package events
+
+import (
+	"sync"
+	"time"
+	"unsafe"
+)
+
+// defaultBatchFlushInterval is the flush interval used when BatchOptions.FlushInterval isn't set
+const defaultBatchFlushInterval = time.Second
+
+// BatchOptions configures the batching mode of a Consumer
+type BatchOptions struct {
+	// Size is the maximum number of events handed at once to the callback
+	Size int
+	// FlushInterval is the maximum time an event is held before being handed to the callback
+	FlushInterval time.Duration
+}
+
+// eventBatcher is the untyped interface of a batcher used by the Consumer
+type eventBatcher interface {
+	add(data []byte)
+	flush()
+	interval() time.Duration
+}
+
+// batcher decodes the events read from eBPF into a batch of values of type V, and executes the callback once for
+// every batch of events. The batches are pooled, so that no allocation is made per event.
+type batcher[V any] struct {
+	size          int
+	flushInterval time.Duration
+	callback      func([]V)
+	pool          sync.Pool
+
+	mux     sync.Mutex
+	current *[]V
+}
+
+func newBatcher[V any](opts BatchOptions, callback func([]V)) *batcher[V] {
+	if opts.Size <= 0 {
+		opts.Size = 1
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = defaultBatchFlushInterval
+	}
+
+	b := &batcher[V]{
+		size:          opts.Size,
+		flushInterval: opts.FlushInterval,
+		callback:      callback,
+	}
+	b.pool.New = func() interface{} {
+		batch := make([]V, 0, b.size)
+		return &batch
+	}
+	return b
+}
+
+// add decodes an event in the current batch, and executes the callback if the batch is full
+func (b *batcher[V]) add(data []byte) {
+	b.mux.Lock()
+	batch := b.current
+	if batch == nil {
+		batch = b.pool.Get().(*[]V)
+		b.current = batch
+	}
+
+	*batch = append(*batch, *(*V)(unsafe.Pointer(&data[0])))
+
+	full := len(*batch) >= b.size
+	if full {
+		b.current = nil
+	}
+	b.mux.Unlock()
+
+	if full {
+		b.dispatch(batch)
+	}
+}
+
+// flush executes the callback for the events of the current batch, even if the batch isn't full
+func (b *batcher[V]) flush() {
+	b.mux.Lock()
+	batch := b.current
+	b.current = nil
+	b.mux.Unlock()
+
+	if batch != nil {
+		b.dispatch(batch)
+	}
+}
+
+func (b *batcher[V]) interval() time.Duration {
+	return b.flushInterval
+}
+
+func (b *batcher[V]) dispatch(batch *[]V) {
+	if len(*batch) > 0 {
+		b.callback(*batch)
+	}
+
+	*batch = (*batch)[:0]
+	b.pool.Put(batch)
+}
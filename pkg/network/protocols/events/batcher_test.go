@@ -0,0 +1,67 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux_bpf
+// +build linux_bpf
+
+package events
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatcher(t *testing.T) {
+	var batches [][]uint64
+	b := newBatcher(BatchOptions{Size: 3}, func(events []uint64) {
+		batches = append(batches, append([]uint64(nil), events...))
+	})
+	assert.Equal(t, defaultBatchFlushInterval, b.interval())
+
+	event := make([]byte, 8)
+	add := func(v uint64) {
+		binary.LittleEndian.PutUint64(event, v)
+		b.add(event)
+	}
+
+	// the events are decoded, so reusing the event buffer doesn't alter the batch
+	for i := uint64(0); i < 5; i++ {
+		add(i)
+	}
+	require.Len(t, batches, 1)
+	assert.Equal(t, []uint64{0, 1, 2}, batches[0])
+
+	// the partial batch is handed on flush
+	b.flush()
+	require.Len(t, batches, 2)
+	assert.Equal(t, []uint64{3, 4}, batches[1])
+
+	// nothing is handed when no event is pending
+	b.flush()
+	assert.Len(t, batches, 2)
+
+	// the batches are reused
+	for i := uint64(5); i < 8; i++ {
+		add(i)
+	}
+	require.Len(t, batches, 3)
+	assert.Equal(t, []uint64{5, 6, 7}, batches[2])
+}
+
+type benchmarkEvent [256]byte
+
+func BenchmarkBatcher(b *testing.B) {
+	batcher := newBatcher(BatchOptions{Size: 128}, func([]benchmarkEvent) {})
+	event := make([]byte, 256)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		batcher.add(event)
+	}
+}
@@ -35,6 +35,7 @@ type Consumer struct {
 	handler     *ddebpf.PerfHandler
 	batchReader *batchReader
 	callback    func([]byte)
+	batcher     eventBatcher
 
 	// termination
 	eventLoopWG sync.WaitGroup
@@ -53,6 +54,32 @@ type Consumer struct {
 // 1) copy the data it wishes to hold since the underlying byte array is reclaimed;
 // 2) be thread-safe, as the callback may be executed concurrently from multiple go-routines;
 func NewConsumer(proto string, ebpf *manager.Manager, callback func([]byte)) (*Consumer, error) {
+	c, err := newConsumer(proto, ebpf)
+	if err != nil {
+		return nil, err
+	}
+
+	c.callback = callback
+	return c, nil
+}
+
+// NewBatchConsumer instantiates a new event Consumer which decodes the events into values of type V, and hands
+// them to `callback` in batches of `opts.Size` events, or at the latest every `opts.FlushInterval`.
+// The batches are reused once `callback` returns, so `callback` must:
+// 1) copy the values it wishes to hold;
+// 2) be thread-safe, as the callback may be executed concurrently from multiple go-routines;
+func NewBatchConsumer[V any](proto string, ebpf *manager.Manager, callback func([]V), opts BatchOptions) (*Consumer, error) {
+	c, err := newConsumer(proto, ebpf)
+	if err != nil {
+		return nil, err
+	}
+
+	c.batcher = newBatcher(opts, callback)
+	c.callback = c.batcher.add
+	return c, nil
+}
+
+func newConsumer(proto string, ebpf *manager.Manager) (*Consumer, error) {
 	batchMapName := proto + batchMapSuffix
 	batchMap, found, _ := ebpf.GetMap(batchMapName)
 	if !found {
@@ -91,7 +118,6 @@ func NewConsumer(proto string, ebpf *manager.Manager, callback func([]byte)) (*C
 
 	return &Consumer{
 		proto:       proto,
-		callback:    callback,
 		syncRequest: make(chan chan struct{}),
 		offsets:     offsets,
 		handler:     handler,
@@ -111,6 +137,15 @@ func (c *Consumer) Start() {
 	c.eventLoopWG.Add(1)
 	go func() {
 		defer c.eventLoopWG.Done()
+
+		// a nil channel is never ready, so the flush case is disabled when the events aren't batched
+		var flush <-chan time.Time
+		if c.batcher != nil {
+			ticker := time.NewTicker(c.batcher.interval())
+			defer ticker.Stop()
+			flush = ticker.C
+		}
+
 		for {
 			select {
 			case dataEvent, ok := <-c.handler.DataChannel:
@@ -136,8 +171,11 @@ func (c *Consumer) Start() {
 				c.batchReader.ReadAll(func(cpu int, b *batch) {
 					c.process(cpu, b, true)
 				})
+				c.flush()
 				c.log()
 				close(done)
+			case <-flush:
+				c.flush()
 			}
 		}
 	}()
@@ -174,6 +212,20 @@ func (c *Consumer) Stop() {
 	c.handler.Stop()
 	c.eventLoopWG.Wait()
 	close(c.syncRequest)
+	c.flush()
+}
+
+// flush hands the pending events to the callback when the events are batched
+func (c *Consumer) flush() {
+	if c.batcher != nil {
+		c.batcher.flush()
+	}
+}
+
+// Counts returns the number of events captured so far, and the number of events dropped on their way to userspace,
+// either by eBPF because the batches were full or by the perf buffer because they weren't read fast enough
+func (c *Consumer) Counts() (captured int64, dropped int64) {
+	return c.eventsCount.Get(), c.kernelDropsCount.Get() + c.missesCount.Get()
 }
 
 func (c *Consumer) process(cpu int, b *batch, syncing bool) {
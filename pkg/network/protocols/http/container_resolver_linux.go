@@ -0,0 +1,194 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux_bpf
+// +build linux_bpf
+
+package http
+
+import (
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/network/config"
+	"github.com/DataDog/datadog-agent/pkg/util/cgroups"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	// cgroupRescanInterval is the minimum interval between two scans of the cgroup filesystem, which are
+	// triggered by the transactions of unknown cgroups
+	cgroupRescanInterval = 10 * time.Second
+	// unknownCgroupTTL is how long a cgroup which wasn't found to belong to a container doesn't trigger scans
+	unknownCgroupTTL = 5 * time.Minute
+)
+
+// cgroupContainerResolver resolves the cgroup v2 ID recorded by the eBPF programs, which is the inode number of
+// the cgroup directory, to the ID of the container owning it. The cgroup filesystem is scanned in the background
+// when an unknown cgroup is met, at most every cgroupRescanInterval, so the transactions of new containers are
+// attributed once the next scan ran. The cgroups which don't belong to a container after a scan, such as the ones
+// of the host processes, are cached for unknownCgroupTTL so they don't trigger scans.
+type cgroupContainerResolver struct {
+	scan func() (map[uint64]string, error)
+
+	mux          sync.Mutex
+	containerIDs map[uint64]string
+	// unknown holds the expiration time of the cgroups which don't belong to a container
+	unknown map[uint64]time.Time
+	// pending holds the unknown cgroups met since the last scan started
+	pending  map[uint64]struct{}
+	scanning bool
+	lastScan time.Time
+}
+
+func newContainerResolver(c *config.Config) containerResolver {
+	if !CgroupIDSupported() {
+		return nil
+	}
+
+	hostPrefix := ""
+	if strings.HasPrefix(c.ProcRoot, "/host") {
+		hostPrefix = "/host"
+	}
+	scanner := newCgroupScanner()
+	reader, err := cgroups.NewReader(
+		cgroups.WithProcPath(c.ProcRoot),
+		cgroups.WithHostPrefix(hostPrefix),
+		cgroups.WithReaderFilter(scanner.filter),
+	)
+	if err != nil {
+		log.Warnf("http container attribution disabled, unable to read the cgroups: %s", err)
+		return nil
+	}
+	// the cgroup ID of the eBPF programs only identifies the cgroups of the v2 hierarchy
+	if reader.CgroupVersion() != 2 {
+		log.Infof("http container attribution disabled, cgroup v2 isn't used")
+		return nil
+	}
+
+	return newCgroupContainerResolver(func() (map[uint64]string, error) {
+		scanner.reset()
+		if err := reader.RefreshCgroups(0); err != nil {
+			return nil, err
+		}
+		return scanner.containerIDs, nil
+	})
+}
+
+func newCgroupContainerResolver(scan func() (map[uint64]string, error)) *cgroupContainerResolver {
+	return &cgroupContainerResolver{
+		scan:         scan,
+		containerIDs: make(map[uint64]string),
+		unknown:      make(map[uint64]time.Time),
+		pending:      make(map[uint64]struct{}),
+	}
+}
+
+// ContainerID returns the ID of the container of a cgroup, or an empty string when it isn't a container or the
+// cgroup isn't known yet. It never blocks on a scan of the cgroup filesystem.
+func (r *cgroupContainerResolver) ContainerID(cgroupID uint64) string {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	if containerID, ok := r.containerIDs[cgroupID]; ok {
+		return containerID
+	}
+
+	now := time.Now()
+	if expiration, ok := r.unknown[cgroupID]; ok && now.Before(expiration) {
+		return ""
+	}
+	r.pending[cgroupID] = struct{}{}
+
+	if !r.scanning && now.Sub(r.lastScan) >= cgroupRescanInterval {
+		r.scanning = true
+		r.lastScan = now
+		go r.rescan()
+	}
+	return ""
+}
+
+func (r *cgroupContainerResolver) rescan() {
+	r.mux.Lock()
+	pending := r.pending
+	r.pending = make(map[uint64]struct{})
+	r.mux.Unlock()
+
+	containerIDs, err := r.scan()
+
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.scanning = false
+	if err != nil {
+		log.Debugf("error scanning the cgroups: %s", err)
+		return
+	}
+	r.containerIDs = containerIDs
+
+	now := time.Now()
+	for cgroupID, expiration := range r.unknown {
+		if !now.Before(expiration) {
+			delete(r.unknown, cgroupID)
+		}
+	}
+	for cgroupID := range pending {
+		if _, ok := containerIDs[cgroupID]; !ok {
+			r.unknown[cgroupID] = now.Add(unknownCgroupTTL)
+		}
+	}
+}
+
+// cgroupScanner collects the inode numbers of the container cgroups while the cgroup filesystem is walked
+// by a cgroups.Reader. The processes of a container may live in a child cgroup of the container cgroup, such
+// as when systemd runs in the container, so the children inherit the container ID of their parent.
+type cgroupScanner struct {
+	mux          sync.Mutex
+	byPath       map[string]string
+	containerIDs map[uint64]string
+}
+
+func newCgroupScanner() *cgroupScanner {
+	s := &cgroupScanner{}
+	s.reset()
+	return s
+}
+
+func (s *cgroupScanner) reset() {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.byPath = make(map[string]string)
+	s.containerIDs = make(map[uint64]string)
+}
+
+// filter is the cgroups.ReaderFilter of the reader. The directories are walked before their children.
+func (s *cgroupScanner) filter(path, name string) (string, error) {
+	containerID, err := cgroups.ContainerFilter(path, name)
+	if err != nil {
+		return "", err
+	}
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	inherited := containerID == ""
+	if inherited {
+		if containerID = s.byPath[filepath.Dir(path)]; containerID == "" {
+			return "", nil
+		}
+	}
+
+	var stat syscall.Stat_t
+	if err := syscall.Stat(path, &stat); err == nil {
+		s.containerIDs[stat.Ino] = containerID
+		s.byPath[path] = containerID
+	}
+	if inherited {
+		// the reader only tracks the container cgroups themselves
+		return "", nil
+	}
+	return containerID, nil
+}
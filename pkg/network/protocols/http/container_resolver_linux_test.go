@@ -0,0 +1,136 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux_bpf
+// +build linux_bpf
+
+package http
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
+
+	"github.com/DataDog/datadog-agent/pkg/network/config"
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+)
+
+const testContainerID = "3c5f0f5e6b1e5c2b8b4f2b1e0f6a5d4c3b2a19081726354453627180a9b8c7d6"
+
+func inode(t *testing.T, path string) uint64 {
+	var stat syscall.Stat_t
+	require.NoError(t, syscall.Stat(path, &stat))
+	return stat.Ino
+}
+
+func TestCgroupScanner(t *testing.T) {
+	root := t.TempDir()
+	containerCgroup := filepath.Join(root, "kubepods.slice", "cri-containerd-"+testContainerID+".scope")
+	childCgroup := filepath.Join(containerCgroup, "init.scope")
+	require.NoError(t, os.MkdirAll(childCgroup, 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "system.slice", "sshd.service"), 0755))
+
+	scanner := newCgroupScanner()
+	var tracked []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		require.NoError(t, err)
+		id, err := scanner.filter(path, d.Name())
+		if id != "" {
+			tracked = append(tracked, id)
+		}
+		return err
+	})
+	require.NoError(t, err)
+
+	// the child cgroup inherits the container ID, but isn't tracked by the reader
+	assert.Equal(t, []string{testContainerID}, tracked)
+	assert.Equal(t, map[uint64]string{
+		inode(t, containerCgroup): testContainerID,
+		inode(t, childCgroup):     testContainerID,
+	}, scanner.containerIDs)
+
+	scanner.reset()
+	assert.Empty(t, scanner.containerIDs)
+}
+
+func TestCgroupContainerResolver(t *testing.T) {
+	scans := atomic.NewInt32(0)
+	resolver := newCgroupContainerResolver(func() (map[uint64]string, error) {
+		scans.Inc()
+		return map[uint64]string{42: testContainerID}, nil
+	})
+	waitScan := func() {
+		require.Eventually(t, func() bool {
+			resolver.mux.Lock()
+			defer resolver.mux.Unlock()
+			return !resolver.scanning
+		}, time.Second, time.Millisecond)
+	}
+
+	// an unknown cgroup triggers a scan in the background
+	assert.Equal(t, "", resolver.ContainerID(42))
+	waitScan()
+	assert.Equal(t, int32(1), scans.Load())
+	assert.Equal(t, testContainerID, resolver.ContainerID(42))
+	assert.Equal(t, int32(1), scans.Load())
+
+	// the scans are rate limited
+	assert.Equal(t, "", resolver.ContainerID(43))
+	waitScan()
+	assert.Equal(t, int32(1), scans.Load())
+
+	resolver.lastScan = time.Now().Add(-2 * cgroupRescanInterval)
+	assert.Equal(t, "", resolver.ContainerID(43))
+	waitScan()
+	assert.Equal(t, int32(2), scans.Load())
+
+	// the cgroup which isn't a container is cached, and doesn't trigger scans anymore
+	resolver.lastScan = time.Now().Add(-2 * cgroupRescanInterval)
+	assert.Equal(t, "", resolver.ContainerID(43))
+	waitScan()
+	assert.Equal(t, int32(2), scans.Load())
+
+	// until its entry expires
+	resolver.unknown[43] = time.Now().Add(-time.Second)
+	assert.Equal(t, "", resolver.ContainerID(43))
+	waitScan()
+	assert.Equal(t, int32(3), scans.Load())
+}
+
+type staticContainerResolver map[uint64]string
+
+func (r staticContainerResolver) ContainerID(cgroupID uint64) string {
+	return r[cgroupID]
+}
+
+func TestProcessHTTPTransactionsByContainer(t *testing.T) {
+	cfg := config.New()
+	cfg.MaxHTTPStatsBuffered = 1000
+	tel, err := NewTelemetry()
+	require.NoError(t, err)
+	sk := NewHTTPStatkeeper(cfg, tel)
+	sk.containerResolver = staticContainerResolver{42: testContainerID}
+
+	sourceIP := util.AddressFromString("1.1.1.1")
+	destIP := util.AddressFromString("2.2.2.2")
+	for _, cgroupID := range []uint64{0, 42, 42, 43} {
+		tx := generateIPv4HTTPTransaction(sourceIP, destIP, 1234, 8080, "/testpath", 200, time.Millisecond)
+		tx.(*EbpfHttpTx).Cgroup_id = cgroupID
+		sk.Process(tx)
+	}
+
+	counts := make(map[string]int)
+	for key, stats := range sk.GetAndResetAllStats() {
+		counts[key.ContainerID] += stats.Data[200].Count
+	}
+	assert.Equal(t, map[string]int{"": 2, testContainerID: 2}, counts)
+}
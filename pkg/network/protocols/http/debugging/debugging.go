@@ -24,6 +24,7 @@ type RequestSummary struct {
 	ByStatus    map[uint16]Stats
 	StaticTags  uint64
 	DynamicTags []string
+	ContainerID string `json:",omitempty"`
 }
 
 // Address represents represents a IP:Port
@@ -37,6 +38,7 @@ type Stats struct {
 	Count              int
 	FirstLatencySample float64
 	LatencyP50         float64
+	ResponseTags       []string `json:",omitempty"`
 }
 
 // HTTP returns a debug-friendly representation of map[http.Key]http.RequestStats
@@ -55,10 +57,11 @@ func HTTP(stats map[http.Key]*http.RequestStats, dns map[util.Address][]dns.Host
 				IP:   serverAddr.String(),
 				Port: k.DstPort,
 			},
-			DNS:      getDNS(dns, serverAddr),
-			Path:     k.Path.Content,
-			Method:   k.Method.String(),
-			ByStatus: make(map[uint16]Stats),
+			DNS:         getDNS(dns, serverAddr),
+			Path:        k.Path.Content,
+			Method:      k.Method.String(),
+			ContainerID: k.ContainerID,
+			ByStatus:    make(map[uint16]Stats),
 		}
 
 		for status, stat := range v.Data {
@@ -69,6 +72,7 @@ func HTTP(stats map[http.Key]*http.RequestStats, dns map[util.Address][]dns.Host
 				Count:              stat.Count,
 				FirstLatencySample: stat.FirstLatencySample,
 				LatencyP50:         getSketchQuantile(stat.Latencies, 0.5),
+				ResponseTags:       stat.ResponseTags.Tags(),
 			}
 		}
 
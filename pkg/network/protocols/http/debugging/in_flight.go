@@ -0,0 +1,68 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux_bpf
+
+package debugging
+
+import (
+	"github.com/DataDog/datadog-agent/pkg/network/protocols/http"
+)
+
+// InFlightTransaction represents a (debug-friendly) view of a transaction still held by eBPF, either waiting
+// for its response or not flushed to userspace yet
+type InFlightTransaction struct {
+	Protocol   string
+	Client     Address
+	Server     Address
+	Method     string
+	Path       string
+	StatusCode uint16 `json:",omitempty"`
+	StaticTags uint64 `json:",omitempty"`
+
+	// RequestAge is the time (in nanoseconds) elapsed since the request started, 0 when the request wasn't seen
+	RequestAge int64
+	// ResponseAge is the time (in nanoseconds) elapsed since the response was last seen, 0 when no response was seen
+	ResponseAge int64
+}
+
+// InFlight returns a debug-friendly representation of the in-flight transactions of a protocol. now is the current
+// monotonic time (in nanoseconds) of the eBPF timestamps of the transactions.
+func InFlight(protocol string, txs []http.HttpTX, now int64) []InFlightTransaction {
+	all := make([]InFlightTransaction, 0, len(txs))
+	buffer := make([]byte, http.HTTPBufferSize)
+	for _, tx := range txs {
+		key := tx.ConnTuple()
+		clientAddr := formatIP(key.SrcIPLow, key.SrcIPHigh)
+		serverAddr := formatIP(key.DstIPLow, key.DstIPHigh)
+		path, _ := tx.Path(buffer)
+
+		all = append(all, InFlightTransaction{
+			Protocol: protocol,
+			Client: Address{
+				IP:   clientAddr.String(),
+				Port: key.SrcPort,
+			},
+			Server: Address{
+				IP:   serverAddr.String(),
+				Port: key.DstPort,
+			},
+			Method:      tx.Method().String(),
+			Path:        string(path),
+			StatusCode:  tx.StatusCode(),
+			StaticTags:  tx.StaticTags(),
+			RequestAge:  age(now, tx.RequestStarted()),
+			ResponseAge: age(now, tx.ResponseLastSeen()),
+		})
+	}
+	return all
+}
+
+func age(now int64, timestamp uint64) int64 {
+	if timestamp == 0 || int64(timestamp) > now {
+		return 0
+	}
+	return now - int64(timestamp)
+}
@@ -0,0 +1,67 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux_bpf
+
+package debugging
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/network/protocols/http"
+)
+
+func TestInFlight(t *testing.T) {
+	// a request waiting for its response
+	httpTx := &http.EbpfHttpTx{
+		Request_started: 1000,
+		Request_method:  uint8(http.MethodGet),
+	}
+	httpTx.Tup.Saddr_l = 0x0100007f // 127.0.0.1
+	httpTx.Tup.Daddr_l = 0x0200007f // 127.0.0.2
+	httpTx.Tup.Sport = 52000
+	httpTx.Tup.Dport = 8080
+	copy(httpTx.Request_fragment[:], "GET /api/v1/users?id=1 HTTP/1.1")
+
+	http2Tx := &http.EbpfHttp2Tx{
+		Request_started:      1000,
+		Response_last_seen:   4000,
+		Request_method:       http.PostValue,
+		Response_status_code: uint16(http.K500Value),
+		Path_size:            5,
+		Request_path_raw:     true,
+	}
+	http2Tx.Tup.Saddr_l = 0x0100007f
+	http2Tx.Tup.Daddr_l = 0x0100007f
+	http2Tx.Tup.Sport = 52001
+	http2Tx.Tup.Dport = 443
+	copy(http2Tx.Request_path[:], "/grpc")
+
+	now := int64(5000)
+	assert.Equal(t, []InFlightTransaction{
+		{
+			Protocol:   "http",
+			Client:     Address{IP: "127.0.0.1", Port: 52000},
+			Server:     Address{IP: "127.0.0.2", Port: 8080},
+			Method:     "GET",
+			Path:       "/api/v1/users",
+			RequestAge: 4000,
+		},
+	}, InFlight("http", []http.HttpTX{httpTx}, now))
+	assert.Equal(t, []InFlightTransaction{
+		{
+			Protocol:    "http2",
+			Client:      Address{IP: "127.0.0.1", Port: 52001},
+			Server:      Address{IP: "127.0.0.1", Port: 443},
+			Method:      "POST",
+			Path:        "/grpc",
+			StatusCode:  500,
+			RequestAge:  4000,
+			ResponseAge: 1000,
+		},
+	}, InFlight("http2", []http.HttpTX{http2Tx}, now))
+}
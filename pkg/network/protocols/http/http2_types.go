@@ -16,6 +16,7 @@ import "C"
 
 type http2ConnTuple = C.conn_tuple_t
 type EbpfHttp2Tx C.http2_stream_t
+type HTTP2StreamKey C.http2_stream_key_t
 
 type StaticTableEnumKey = C.static_table_key_t
 
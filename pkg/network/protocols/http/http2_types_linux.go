@@ -15,16 +15,23 @@ type http2ConnTuple = struct {
 	Metadata uint32
 }
 type EbpfHttp2Tx struct {
-	Tup                   http2ConnTuple
-	Response_last_seen    uint64
-	Request_started       uint64
-	Response_status_code  uint16
-	Request_method        uint8
-	Path_size             uint8
-	Request_end_of_stream bool
-	Pad_cgo_0             [3]byte
-	Request_path          [30]uint8
-	Pad_cgo_1             [2]byte
+	Tup                    http2ConnTuple
+	Response_last_seen     uint64
+	Request_started        uint64
+	Response_status_code   uint16
+	Request_method         uint8
+	Path_size              uint8
+	Request_end_of_stream  bool
+	Request_path_raw       bool
+	Request_path_truncated bool
+	Pad_cgo_0              [1]byte
+	Request_path           [30]uint8
+	Pad_cgo_1              [2]byte
+}
+type HTTP2StreamKey struct {
+	Tup       http2ConnTuple
+	Stream_id uint32
+	Pad_cgo_0 [4]byte
 }
 
 type StaticTableEnumKey = uint8
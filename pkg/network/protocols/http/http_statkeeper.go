@@ -29,6 +29,9 @@ type HttpStatKeeper struct {
 	// replace rules for HTTP path
 	replaceRules []*config.ReplaceRule
 
+	// quantizes the identifiers of the HTTP paths, nil when disabled
+	quantizer *pathQuantizer
+
 	// http path buffer
 	buffer []byte
 
@@ -37,6 +40,15 @@ type HttpStatKeeper struct {
 	interned map[string]string
 
 	oversizedLogLimit *util.LogLimit
+
+	// resolves the cgroup of the transactions to a container, nil when not supported
+	containerResolver containerResolver
+}
+
+// containerResolver resolves the cgroup ID of a transaction to the ID of the container it belongs to
+type containerResolver interface {
+	// ContainerID returns the ID of the container of a cgroup, or an empty string when it isn't a container
+	ContainerID(cgroupID uint64) string
 }
 
 func NewHTTPStatkeeper(c *config.Config, telemetry *Telemetry) *HttpStatKeeper {
@@ -45,11 +57,13 @@ func NewHTTPStatkeeper(c *config.Config, telemetry *Telemetry) *HttpStatKeeper {
 		incomplete:                      newIncompleteBuffer(c, telemetry),
 		maxEntries:                      c.MaxHTTPStatsBuffered,
 		replaceRules:                    c.HTTPReplaceRules,
+		quantizer:                       newPathQuantizer(c),
 		enableHTTPStatusCodeAggregation: c.EnableHTTPStatsByStatusCode,
 		buffer:                          make([]byte, getPathBufferSize(c)),
 		interned:                        make(map[string]string),
 		telemetry:                       telemetry,
 		oversizedLogLimit:               util.NewLogLimit(10, time.Minute*10),
+		containerResolver:               newContainerResolver(c),
 	}
 }
 
@@ -57,6 +71,10 @@ func (h *HttpStatKeeper) Process(tx HttpTX) {
 	h.mux.Lock()
 	defer h.mux.Unlock()
 
+	h.process(tx)
+}
+
+func (h *HttpStatKeeper) process(tx HttpTX) {
 	if tx.Incomplete() {
 		h.incomplete.Add(tx)
 		return
@@ -119,7 +137,8 @@ func (h *HttpStatKeeper) add(tx HttpTX) {
 		h.stats[key] = stats
 	}
 
-	stats.AddRequest(tx.StatusCode(), latency, tx.StaticTags(), tx.DynamicTags())
+	stats.AddWeightedRequest(tx.StatusCode(), latency, tx.StaticTags(), tx.DynamicTags(), tx.SamplingWeight())
+	stats.AddResponseTags(tx.StatusCode(), NewResponseTag(tx.ContentType(), tx.SizeClass()))
 }
 
 func (h *HttpStatKeeper) newKey(tx HttpTX, path string, fullPath bool) Key {
@@ -129,10 +148,19 @@ func (h *HttpStatKeeper) newKey(tx HttpTX, path string, fullPath bool) Key {
 			Content:  path,
 			FullPath: fullPath,
 		},
-		Method: tx.Method(),
+		Method:      tx.Method(),
+		ContainerID: h.containerID(tx),
 	}
 }
 
+func (h *HttpStatKeeper) containerID(tx HttpTX) string {
+	cgroupID := tx.CgroupID()
+	if h.containerResolver == nil || cgroupID == 0 {
+		return ""
+	}
+	return h.containerResolver.ContainerID(cgroupID)
+}
+
 func pathIsMalformed(fullPath []byte) bool {
 	for _, r := range fullPath {
 		if !strconv.IsPrint(rune(r)) {
@@ -166,6 +194,13 @@ func (h *HttpStatKeeper) processHTTPPath(tx HttpTX, path []byte) (pathStr string
 		h.telemetry.malformed.Add(1)
 		return "", true
 	}
+
+	if h.quantizer != nil {
+		var quantized bool
+		if path, quantized = h.quantizer.quantize(path); quantized {
+			h.telemetry.quantized.Add(1)
+		}
+	}
 	return h.intern(path), false
 }
 
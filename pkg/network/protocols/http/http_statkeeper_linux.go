@@ -15,3 +15,14 @@ import (
 func getPathBufferSize(c *config.Config) int {
 	return int(HTTPBufferSize)
 }
+
+// ProcessBatch counts and processes a batch of transactions decoded from eBPF, in a single pass under the lock
+func (h *HttpStatKeeper) ProcessBatch(txs []EbpfHttpTx) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+
+	for i := range txs {
+		h.telemetry.Count(&txs[i])
+		h.process(&txs[i])
+	}
+}
@@ -194,6 +194,43 @@ func TestPathProcessing(t *testing.T) {
 	})
 }
 
+func TestPathQuantization(t *testing.T) {
+	var (
+		sourceIP   = util.AddressFromString("1.1.1.1")
+		sourcePort = 1234
+		destIP     = util.AddressFromString("2.2.2.2")
+		destPort   = 8080
+		statusCode = 200
+		latency    = time.Second
+	)
+	cfg := config.New()
+	cfg.MaxHTTPStatsBuffered = 1000
+	cfg.EnableHTTPPathQuantization = true
+	tel, err := NewTelemetry()
+	require.NoError(t, err)
+	sk := NewHTTPStatkeeper(cfg, tel)
+
+	transactions := []HttpTX{
+		generateIPv4HTTPTransaction(sourceIP, destIP, sourcePort, destPort, "/users/1/orders/10", statusCode, latency),
+		generateIPv4HTTPTransaction(sourceIP, destIP, sourcePort, destPort, "/users/2/orders/20", statusCode, latency),
+		generateIPv4HTTPTransaction(sourceIP, destIP, sourcePort, destPort, "/users/123e4567-e89b-12d3-a456-426614174000/orders/30", statusCode, latency),
+		generateIPv4HTTPTransaction(sourceIP, destIP, sourcePort, destPort, "/users", statusCode, latency),
+	}
+	for _, tx := range transactions {
+		sk.Process(tx)
+	}
+	stats := sk.GetAndResetAllStats()
+
+	require.Len(t, stats, 2)
+	counts := make(map[string]int)
+	for key, metrics := range stats {
+		s := metrics.Data[uint16(statusCode)]
+		require.NotNil(t, s)
+		counts[key.Path.Content] = s.Count
+	}
+	assert.Equal(t, map[string]int{"/users/*/orders/*": 3, "/users": 1}, counts)
+}
+
 func TestHTTPCorrectness(t *testing.T) {
 	t.Run("wrong path format", func(t *testing.T) {
 		cfg := config.New()
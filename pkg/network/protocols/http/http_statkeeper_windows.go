@@ -15,3 +15,7 @@ import (
 func getPathBufferSize(c *config.Config) int {
 	return int(c.HTTPMaxRequestFragment)
 }
+
+func newContainerResolver(c *config.Config) containerResolver {
+	return nil
+}
@@ -72,6 +72,8 @@ type Path struct {
 type Key struct {
 	// this field order is intentional to help the GC pointer tracking
 	Path Path
+	// ContainerID is the ID of the container owning the socket, empty when unknown
+	ContainerID string
 	types.ConnectionKey
 	Method Method
 }
@@ -108,6 +110,9 @@ type RequestStat struct {
 
 	// Dynamic tags (if attached)
 	DynamicTags []string
+
+	// Content types and size classes of the responses
+	ResponseTags ResponseTag
 }
 
 func (r *RequestStat) initSketch() (err error) {
@@ -118,6 +123,23 @@ func (r *RequestStat) initSketch() (err error) {
 	return
 }
 
+// addDynamicTags adds the dynamic tags which aren't already attached, as the transactions of a single
+// connection all carry the same tags
+func (r *RequestStat) addDynamicTags(tags []string) {
+	for _, tag := range tags {
+		found := false
+		for _, existing := range r.DynamicTags {
+			if existing == tag {
+				found = true
+				break
+			}
+		}
+		if !found {
+			r.DynamicTags = append(r.DynamicTags, tag)
+		}
+	}
+}
+
 type RequestStats struct {
 	aggregateByStatusCode bool
 	Data                  map[uint16]*RequestStat
@@ -155,6 +177,7 @@ func (r *RequestStats) CombineWith(newStats *RequestStats) {
 		if newRequests.Count == 1 {
 			// The other bucket has a single latency sample, so we "manually" add it
 			r.AddRequest(statusCode, newRequests.FirstLatencySample, newRequests.StaticTags, newRequests.DynamicTags)
+			r.AddResponseTags(statusCode, newRequests.ResponseTags)
 			continue
 		}
 
@@ -183,12 +206,21 @@ func (r *RequestStats) CombineWith(newStats *RequestStats) {
 			}
 		}
 		stats.Count += newRequests.Count
+		stats.StaticTags |= newRequests.StaticTags
+		stats.addDynamicTags(newRequests.DynamicTags)
+		stats.ResponseTags |= newRequests.ResponseTags
 	}
 }
 
 // AddRequest takes information about a HTTP transaction and adds it to the request stats
 func (r *RequestStats) AddRequest(statusCode uint16, latency float64, staticTags uint64, dynamicTags []string) {
-	if !r.isValid(statusCode) {
+	r.AddWeightedRequest(statusCode, latency, staticTags, dynamicTags, 1)
+}
+
+// AddWeightedRequest adds a HTTP transaction standing for `weight` transactions to the request stats, the other
+// transactions having been dropped by the sampling
+func (r *RequestStats) AddWeightedRequest(statusCode uint16, latency float64, staticTags uint64, dynamicTags []string, weight int) {
+	if !r.isValid(statusCode) || weight <= 0 {
 		return
 	}
 
@@ -201,11 +233,9 @@ func (r *RequestStats) AddRequest(statusCode uint16, latency float64, staticTags
 	}
 
 	stats.StaticTags |= staticTags
-	if len(dynamicTags) != 0 {
-		stats.DynamicTags = append(stats.DynamicTags, dynamicTags...)
-	}
+	stats.addDynamicTags(dynamicTags)
 
-	stats.Count++
+	stats.Count += weight
 	if stats.Count == 1 {
 		// We postpone the creation of histograms when we have only one latency sample
 		stats.FirstLatencySample = latency
@@ -217,17 +247,31 @@ func (r *RequestStats) AddRequest(statusCode uint16, latency float64, staticTags
 			return
 		}
 
-		// Add the deferred latency sample
-		if err := stats.Latencies.Add(stats.FirstLatencySample); err != nil {
-			log.Debugf("could not add request latency to ddsketch: %v", err)
+		// Add the deferred latency sample, if any
+		if stats.Count > weight {
+			if err := stats.Latencies.Add(stats.FirstLatencySample); err != nil {
+				log.Debugf("could not add request latency to ddsketch: %v", err)
+			}
 		}
 	}
 
-	if err := stats.Latencies.Add(latency); err != nil {
+	if err := stats.Latencies.AddWithCount(latency, float64(weight)); err != nil {
 		log.Debugf("could not add request latency to ddsketch: %v", err)
 	}
 }
 
+// AddResponseTags records the content type and size class of a response in the stats of its status code.
+// It must be called after AddRequest.
+func (r *RequestStats) AddResponseTags(statusCode uint16, tags ResponseTag) {
+	if tags == 0 || !r.isValid(statusCode) {
+		return
+	}
+
+	if stats, exists := r.Data[r.NormalizeStatusCode(statusCode)]; exists {
+		stats.ResponseTags |= tags
+	}
+}
+
 // HalfAllCounts sets the count of all stats for each status class to half their current value.
 // This is used to remove duplicates from the count in the context of Windows localhost traffic.
 func (r *RequestStats) HalfAllCounts() {
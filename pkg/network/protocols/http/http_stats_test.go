@@ -95,3 +95,78 @@ func verifyQuantile(t *testing.T, sketch *ddsketch.DDSketch, q float64, expected
 	assert.True(t, val >= expectedValue-acceptableError)
 	assert.True(t, val <= expectedValue+acceptableError)
 }
+
+func TestResponseTags(t *testing.T) {
+	stats := NewRequestStats(false)
+	stats.AddResponseTags(200, NewResponseTag(ContentTypeJSON, SizeClassSmall))
+	assert.Nil(t, stats.Data[200], "response tags must be added after the request")
+
+	stats.AddRequest(200, 10.0, 0, nil)
+	stats.AddResponseTags(200, NewResponseTag(ContentTypeJSON, SizeClassSmall))
+	stats.AddRequest(201, 10.0, 0, nil)
+	stats.AddResponseTags(201, NewResponseTag(ContentTypeUnknown, SizeClassLarge))
+	assert.Equal(t, []string{"http.content_type:json", "http.response_size:large", "http.response_size:small"}, stats.Data[200].ResponseTags.Tags())
+
+	other := NewRequestStats(false)
+	other.AddRequest(500, 10.0, 0, nil)
+	other.AddResponseTags(500, NewResponseTag(ContentTypeHTML, SizeClassHuge))
+	stats.CombineWith(other)
+	assert.Equal(t, []string{"http.content_type:html", "http.response_size:huge"}, stats.Data[500].ResponseTags.Tags())
+
+	other.AddRequest(500, 20.0, 0, nil)
+	other.AddResponseTags(500, NewResponseTag(ContentTypeOctetStream, SizeClassUnknown))
+	stats.CombineWith(other)
+	assert.Equal(t, []string{"http.content_type:html", "http.content_type:octet-stream", "http.response_size:huge"}, stats.Data[500].ResponseTags.Tags())
+
+	assert.Empty(t, NewResponseTag(ContentTypeUnknown, SizeClassUnknown).Tags())
+}
+
+func TestTLSTags(t *testing.T) {
+	tx := &EbpfHttpTx{Tls_version: 0x0304, Tls_cipher_suite: 0x1301}
+	assert.Equal(t, []string{"tls.version:tls1.3", "tls.cipher_suite:TLS_AES_128_GCM_SHA256"}, tx.DynamicTags())
+	assert.Empty(t, (&EbpfHttpTx{}).DynamicTags())
+
+	stats := NewRequestStats(false)
+	stats.AddRequest(200, 10.0, 0, tx.DynamicTags())
+	stats.AddRequest(200, 20.0, 0, tx.DynamicTags())
+	assert.Equal(t, []string{"tls.version:tls1.3", "tls.cipher_suite:TLS_AES_128_GCM_SHA256"}, stats.Data[200].DynamicTags)
+
+	other := NewRequestStats(false)
+	other.AddRequest(200, 10.0, 0, tlsTags(0x0303, 0xc02f))
+	other.AddRequest(200, 20.0, 0, tlsTags(0x0303, 0xc02f))
+	stats.CombineWith(other)
+	assert.ElementsMatch(t, []string{
+		"tls.version:tls1.3",
+		"tls.cipher_suite:TLS_AES_128_GCM_SHA256",
+		"tls.version:tls1.2",
+		"tls.cipher_suite:TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256",
+	}, stats.Data[200].DynamicTags)
+}
+
+func TestAddWeightedRequest(t *testing.T) {
+	tx := &EbpfHttpTx{Sampling_rate: HTTPSamplingScale / 4}
+	assert.Equal(t, 4, tx.SamplingWeight())
+	assert.Equal(t, 1, (&EbpfHttpTx{}).SamplingWeight())
+
+	stats := NewRequestStats(false)
+	stats.AddWeightedRequest(200, 10.0, 0, nil, tx.SamplingWeight())
+	s := stats.Data[200]
+	if assert.NotNil(t, s) {
+		assert.Equal(t, 4, s.Count)
+		assert.Equal(t, 4.0, s.Latencies.GetCount())
+	}
+
+	stats.AddRequest(200, 20.0, 0, nil)
+	assert.Equal(t, 5, s.Count)
+	assert.Equal(t, 5.0, s.Latencies.GetCount())
+
+	// the deferred latency sample of a single request is kept
+	stats.AddRequest(404, 10.0, 0, nil)
+	stats.AddWeightedRequest(404, 20.0, 0, nil, 2)
+	s = stats.Data[400]
+	if assert.NotNil(t, s) {
+		assert.Equal(t, 3, s.Count)
+		assert.Equal(t, 3.0, s.Latencies.GetCount())
+		verifyQuantile(t, s.Latencies, 0.0, 10.0)
+	}
+}
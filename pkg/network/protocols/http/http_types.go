@@ -18,13 +18,15 @@ import "C"
 type httpConnTuple = C.conn_tuple_t
 type SslSock C.ssl_sock_t
 type SslReadArgs C.ssl_read_args_t
+type SslBioKey C.ssl_bio_key_t
 
 type EbpfHttpTx C.http_transaction_t
 
 type LibPath C.lib_path_t
 
 const (
-	HTTPBufferSize = C.HTTP_BUFFER_SIZE
+	HTTPBufferSize    = C.HTTP_BUFFER_SIZE
+	HTTPSamplingScale = C.HTTP_SAMPLING_SCALE
 
 	libPathMaxSize = C.LIB_PATH_MAX_SIZE
 )
@@ -32,19 +34,23 @@ const (
 type ConnTag = uint64
 
 const (
-	GnuTLS  ConnTag = C.LIBGNUTLS
-	OpenSSL ConnTag = C.LIBSSL
-	Go      ConnTag = C.GO
-	Java    ConnTag = C.JAVA_TLS
-	TLS     ConnTag = C.CONN_TLS
+	GnuTLS      ConnTag = C.LIBGNUTLS
+	OpenSSL     ConnTag = C.LIBSSL
+	Go          ConnTag = C.GO
+	Java        ConnTag = C.JAVA_TLS
+	TLS         ConnTag = C.CONN_TLS
+	HTTP3       ConnTag = C.HTTP3
+	ServiceMesh ConnTag = C.SERVICE_MESH
 )
 
 var (
 	StaticTags = map[ConnTag]string{
-		GnuTLS:  "tls.library:gnutls",
-		OpenSSL: "tls.library:openssl",
-		Go:      "tls.library:go",
-		Java:    "tls.library:java",
-		TLS:     "tls.connection:encrypted",
+		GnuTLS:      "tls.library:gnutls",
+		OpenSSL:     "tls.library:openssl",
+		Go:          "tls.library:go",
+		Java:        "tls.library:java",
+		TLS:         "tls.connection:encrypted",
+		HTTP3:       "http.protocol:http3",
+		ServiceMesh: "via_service_mesh:istio",
 	}
 )
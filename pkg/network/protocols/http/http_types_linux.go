@@ -23,16 +23,26 @@ type SslReadArgs struct {
 	Ctx *byte
 	Buf *byte
 }
+type SslBioKey struct {
+	Tgid uint64
+	Bio  *byte
+}
 
 type EbpfHttpTx struct {
-	Tup                  httpConnTuple
-	Request_started      uint64
-	Request_method       uint8
-	Response_status_code uint16
-	Response_last_seen   uint64
-	Request_fragment     [160]byte
-	Tcp_seq              uint32
-	Tags                 uint64
+	Tup                   httpConnTuple
+	Request_started       uint64
+	Request_method        uint8
+	Response_content_type uint8
+	Response_status_code  uint16
+	Response_size_class   uint8
+	Sampling_rate         uint16
+	Response_last_seen    uint64
+	Request_fragment      [160]byte
+	Tcp_seq               uint32
+	Tls_version           uint16
+	Tls_cipher_suite      uint16
+	Tags                  uint64
+	Cgroup_id             uint64
 }
 
 type LibPath struct {
@@ -42,7 +52,8 @@ type LibPath struct {
 }
 
 const (
-	HTTPBufferSize = 0xa0
+	HTTPBufferSize    = 0xa0
+	HTTPSamplingScale = 0x400
 
 	libPathMaxSize = 0x78
 )
@@ -50,19 +61,23 @@ const (
 type ConnTag = uint64
 
 const (
-	GnuTLS  ConnTag = 0x1
-	OpenSSL ConnTag = 0x2
-	Go      ConnTag = 0x4
-	Java    ConnTag = 0x8
-	TLS     ConnTag = 0x10
+	GnuTLS      ConnTag = 0x1
+	OpenSSL     ConnTag = 0x2
+	Go          ConnTag = 0x4
+	Java        ConnTag = 0x8
+	TLS         ConnTag = 0x10
+	HTTP3       ConnTag = 0x20
+	ServiceMesh ConnTag = 0x40
 )
 
 var (
 	StaticTags = map[ConnTag]string{
-		GnuTLS:  "tls.library:gnutls",
-		OpenSSL: "tls.library:openssl",
-		Go:      "tls.library:go",
-		Java:    "tls.library:java",
-		TLS:     "tls.connection:encrypted",
+		GnuTLS:      "tls.library:gnutls",
+		OpenSSL:     "tls.library:openssl",
+		Go:          "tls.library:go",
+		Java:        "tls.library:java",
+		TLS:         "tls.connection:encrypted",
+		HTTP3:       "http.protocol:http3",
+		ServiceMesh: "via_service_mesh:istio",
 	}
 )
@@ -134,6 +134,7 @@ func (b *incompleteBuffer) Flush(now time.Time) []HttpTX {
 
 			// Merge response into request
 			request.SetStatusCode(response.StatusCode())
+			request.SetResponseContent(response.ContentType(), response.SizeClass())
 			request.SetResponseLastSeen(response.ResponseLastSeen())
 			joined = append(joined, request)
 			i++
@@ -10,6 +10,7 @@ package http
 
 import (
 	"golang.org/x/net/http2/hpack"
+	"strings"
 	"testing"
 	"time"
 
@@ -43,8 +44,10 @@ func TestOrphanEntries(t *testing.T) {
 		assert.Len(t, complete, 0)
 
 		response := &EbpfHttpTx{
-			Response_status_code: 200,
-			Response_last_seen:   uint64(now.UnixNano()),
+			Response_status_code:  200,
+			Response_last_seen:    uint64(now.UnixNano()),
+			Response_content_type: uint8(ContentTypeJSON),
+			Response_size_class:   uint8(SizeClassMedium),
 		}
 		response.Tup.Sport = 60000
 		buffer.Add(response)
@@ -55,6 +58,8 @@ func TestOrphanEntries(t *testing.T) {
 		path, _ := completeTX.Path(make([]byte, 256))
 		assert.Equal(t, "/foo/bar", string(path))
 		assert.Equal(t, uint16(200), completeTX.StatusCode())
+		assert.Equal(t, ContentTypeJSON, completeTX.ContentType())
+		assert.Equal(t, SizeClassMedium, completeTX.SizeClass())
 	})
 
 	t.Run("orphan entries are not kept indefinitely", func(t *testing.T) {
@@ -115,4 +120,40 @@ func TestHTTP2Path(t *testing.T) {
 		assert.Equal(t, ok, false)
 		assert.Nil(t, path)
 	})
+
+	t.Run("raw path", func(t *testing.T) {
+		var arr [http2MaxPathLen]uint8
+		n := copy(arr[:], "/api/v1/users")
+		request := &EbpfHttp2Tx{
+			Request_path:     arr,
+			Path_size:        uint8(n),
+			Request_path_raw: true,
+		}
+		outBuf := make([]byte, decompressedPathLen)
+
+		path, ok := request.Path(outBuf)
+		assert.True(t, ok)
+		assert.Equal(t, "/api/v1/users", string(path))
+	})
+
+	t.Run("truncated huffman path", func(t *testing.T) {
+		fullPath := "/hello.HelloService/SayHelloAgainAndAgainAndAgain"
+		encoded := hpack.AppendHuffmanString(nil, fullPath)
+		require.Greater(t, len(encoded), http2MaxPathLen)
+
+		var arr [http2MaxPathLen]uint8
+		copy(arr[:], encoded)
+		request := &EbpfHttp2Tx{
+			Request_path:           arr,
+			Path_size:              http2MaxPathLen,
+			Request_path_truncated: true,
+		}
+		outBuf := make([]byte, len(fullPath))
+
+		path, ok := request.Path(outBuf)
+		assert.False(t, ok)
+		require.NotEmpty(t, path)
+		assert.True(t, strings.HasPrefix(fullPath, string(path)))
+		assert.Greater(t, len(path), http2MaxPathLen)
+	})
 }
@@ -58,3 +58,27 @@ func HTTP2Supported() bool {
 
 	return kversion >= HTTP2MinimumKernelVersion
 }
+
+// ResponseHeadersSupported returns true if the Content-Type and Content-Length headers of the responses can be parsed.
+// The parsing program exceeds the instructions limit of the kernels < 5.2.0.
+func ResponseHeadersSupported() bool {
+	kversion, err := kernel.HostVersion()
+	if err != nil {
+		log.Warn("could not determine the current kernel version. http response headers parsing disabled.")
+		return false
+	}
+
+	return kversion >= HTTP2MinimumKernelVersion
+}
+
+// CgroupIDSupported returns true if the HTTP transactions can be attributed to the cgroup of the process owning the
+// socket. bpf_get_current_cgroup_id was added in kernel 4.18.0.
+func CgroupIDSupported() bool {
+	kversion, err := kernel.HostVersion()
+	if err != nil {
+		log.Warn("could not determine the current kernel version. http container attribution disabled.")
+		return false
+	}
+
+	return kversion >= kernel.VersionCode(4, 18, 0)
+}
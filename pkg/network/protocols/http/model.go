@@ -19,6 +19,9 @@ type HttpTX interface {
 	SetRequestMethod(Method)
 	StatusCode() uint16
 	SetStatusCode(uint16)
+	ContentType() ContentType
+	SizeClass() SizeClass
+	SetResponseContent(ContentType, SizeClass)
 	StaticTags() uint64
 	DynamicTags() []string
 	String() string
@@ -27,4 +30,6 @@ type HttpTX interface {
 	ResponseLastSeen() uint64
 	SetResponseLastSeen(ls uint64)
 	RequestStarted() uint64
+	CgroupID() uint64
+	SamplingWeight() int
 }
@@ -22,10 +22,15 @@ func (tx *EbpfHttp2Tx) Path(buffer []byte) ([]byte, bool) {
 		return nil, false
 	}
 
-	// trim null byte + after
-	str, err := hpack.HuffmanDecodeToString(tx.Request_path[:tx.Path_size])
-	if err != nil {
-		return nil, false
+	var str string
+	if tx.Request_path_raw {
+		str = string(tx.Request_path[:tx.Path_size])
+	} else {
+		var err error
+		str, err = decodeHuffmanPath(tx.Request_path[:tx.Path_size], tx.Request_path_truncated)
+		if err != nil {
+			return nil, false
+		}
 	}
 
 	// ensure we found a '/' in the beginning of the path
@@ -35,7 +40,23 @@ func (tx *EbpfHttp2Tx) Path(buffer []byte) ([]byte, bool) {
 
 	n := copy(buffer, str)
 	// indicate if we knowingly captured the entire path
-	return buffer[:n], true
+	return buffer[:n], !tx.Request_path_truncated && n == len(str)
+}
+
+// decodeHuffmanPath decodes the Huffman encoded path. A truncated path usually ends in the middle of a
+// Huffman code, in which case we decode the longest prefix ending on a code boundary.
+func decodeHuffmanPath(encoded []byte, truncated bool) (string, error) {
+	str, err := hpack.HuffmanDecodeToString(encoded)
+	if err == nil || !truncated {
+		return str, err
+	}
+	// the padding of a Huffman string is at most 7 bits, so dropping a byte drops at most a code
+	for end := len(encoded) - 1; end > 0; end-- {
+		if str, err = hpack.HuffmanDecodeToString(encoded[:end]); err == nil {
+			return str, nil
+		}
+	}
+	return "", err
 }
 
 // RequestLatency returns the latency of the request in nanoseconds
@@ -95,6 +116,18 @@ func (tx *EbpfHttp2Tx) SetStatusCode(code uint16) {
 	tx.Response_status_code = code
 }
 
+// ContentType is not captured for http2 transactions
+func (tx *EbpfHttp2Tx) ContentType() ContentType {
+	return ContentTypeUnknown
+}
+
+// SizeClass is not captured for http2 transactions
+func (tx *EbpfHttp2Tx) SizeClass() SizeClass {
+	return SizeClassUnknown
+}
+
+func (tx *EbpfHttp2Tx) SetResponseContent(ContentType, SizeClass) {}
+
 func (tx *EbpfHttp2Tx) ResponseLastSeen() uint64 {
 	return tx.Response_last_seen
 }
@@ -107,6 +140,16 @@ func (tx *EbpfHttp2Tx) RequestStarted() uint64 {
 	return tx.Request_started
 }
 
+// CgroupID returns 0, the HTTP/2 transactions aren't attributed to a cgroup
+func (tx *EbpfHttp2Tx) CgroupID() uint64 {
+	return 0
+}
+
+// SamplingWeight returns 1, the HTTP/2 transactions aren't sampled
+func (tx *EbpfHttp2Tx) SamplingWeight() int {
+	return 1
+}
+
 func (tx *EbpfHttp2Tx) SetRequestMethod(m Method) {
 	tx.Request_method = uint8(m)
 }
@@ -84,6 +84,21 @@ func (tx *EbpfHttpTx) SetStatusCode(code uint16) {
 	tx.Response_status_code = code
 }
 
+// ContentType returns the normalized content type of the response
+func (tx *EbpfHttpTx) ContentType() ContentType {
+	return ContentType(tx.Response_content_type)
+}
+
+// SizeClass returns the size class of the body of the response
+func (tx *EbpfHttpTx) SizeClass() SizeClass {
+	return SizeClass(tx.Response_size_class)
+}
+
+func (tx *EbpfHttpTx) SetResponseContent(ct ContentType, sc SizeClass) {
+	tx.Response_content_type = uint8(ct)
+	tx.Response_size_class = uint8(sc)
+}
+
 func (tx *EbpfHttpTx) ResponseLastSeen() uint64 {
 	return tx.Response_last_seen
 }
@@ -96,6 +111,21 @@ func (tx *EbpfHttpTx) RequestStarted() uint64 {
 	return tx.Request_started
 }
 
+// CgroupID returns the cgroup v2 ID of the process owning the socket, 0 when unknown
+func (tx *EbpfHttpTx) CgroupID() uint64 {
+	return tx.Cgroup_id
+}
+
+// SamplingWeight returns the number of transactions the transaction stands for, which is more than 1 when the
+// transactions of its connection were sampled by eBPF. The sampling rates set by userspace are powers of two, so
+// that the weight is exact.
+func (tx *EbpfHttpTx) SamplingWeight() int {
+	if tx.Sampling_rate == 0 {
+		return 1
+	}
+	return HTTPSamplingScale / int(tx.Sampling_rate)
+}
+
 func (tx *EbpfHttpTx) SetRequestMethod(m Method) {
 	tx.Request_method = uint8(m)
 }
@@ -106,8 +136,10 @@ func (tx *EbpfHttpTx) StaticTags() uint64 {
 	return tx.Tags
 }
 
+// DynamicTags returns the tags of the TLS version and cipher suite negotiated by the connection, when the
+// transaction was decrypted by the TLS hooks
 func (tx *EbpfHttpTx) DynamicTags() []string {
-	return nil
+	return tlsTags(tx.Tls_version, tx.Tls_cipher_suite)
 }
 
 func (tx *EbpfHttpTx) String() string {
@@ -153,6 +153,18 @@ func (tx *WinHttpTransaction) SetStatusCode(code uint16) {
 	tx.Txn.ResponseStatusCode = code
 }
 
+// ContentType is not part of windows driver http transactions
+func (tx *WinHttpTransaction) ContentType() ContentType {
+	return ContentTypeUnknown
+}
+
+// SizeClass is not part of windows driver http transactions
+func (tx *WinHttpTransaction) SizeClass() SizeClass {
+	return SizeClassUnknown
+}
+
+func (tx *WinHttpTransaction) SetResponseContent(ContentType, SizeClass) {}
+
 func (tx *WinHttpTransaction) ResponseLastSeen() uint64 {
 	return tx.Txn.ResponseLastSeen
 }
@@ -165,6 +177,16 @@ func (tx *WinHttpTransaction) RequestStarted() uint64 {
 	return tx.Txn.RequestStarted
 }
 
+// CgroupID returns 0, as there are no cgroups on Windows
+func (tx *WinHttpTransaction) CgroupID() uint64 {
+	return 0
+}
+
+// SamplingWeight returns 1, the transactions aren't sampled on Windows
+func (tx *WinHttpTransaction) SamplingWeight() int {
+	return 1
+}
+
 func (tx *WinHttpTransaction) SetRequestMethod(m Method) {
 	tx.Txn.RequestMethod = uint32(m)
 }
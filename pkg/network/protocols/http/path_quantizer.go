@@ -0,0 +1,146 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build (windows && npm) || linux_bpf
+// +build windows,npm linux_bpf
+
+package http
+
+import (
+	"bytes"
+
+	"github.com/DataDog/datadog-agent/pkg/network/config"
+)
+
+// quantizedSegment is the placeholder replacing the path segments holding identifiers
+const quantizedSegment = "*"
+
+// minHexIDLength is the minimum length of the hexadecimal path segments quantized as identifiers, such as hashes
+const minHexIDLength = 16
+
+// pathQuantizer replaces the segments of the HTTP paths holding identifiers, such as /users/123/orders/456, with a
+// placeholder, so that the requests to the same endpoint are aggregated together. The user-defined rules are matched
+// against each segment first, a segment matching a rule being replaced by the replacement of the rule, or by the
+// placeholder when the rule has none. The segments matching no rule are then replaced by the placeholder when they
+// are numbers, UUIDs, or long hexadecimal strings holding at least one digit.
+//
+// The quantizer isn't safe for concurrent use, its buffer being reused across the paths.
+type pathQuantizer struct {
+	rules  []*config.ReplaceRule
+	buffer []byte
+}
+
+func newPathQuantizer(c *config.Config) *pathQuantizer {
+	if !c.EnableHTTPPathQuantization {
+		return nil
+	}
+	return &pathQuantizer{
+		rules: c.HTTPPathQuantizationRules,
+	}
+}
+
+// quantize returns the quantized path and whether it differs from the given path. The returned path is only valid
+// until the next call.
+func (q *pathQuantizer) quantize(path []byte) ([]byte, bool) {
+	q.buffer = q.buffer[:0]
+	quantized := false
+	for start := 0; start <= len(path); {
+		end := bytes.IndexByte(path[start:], '/')
+		if end < 0 {
+			end = len(path)
+		} else {
+			end += start
+		}
+
+		segment := path[start:end]
+		if replacement, ok := q.quantizeSegment(segment); ok {
+			q.buffer = append(q.buffer, replacement...)
+			quantized = true
+		} else {
+			q.buffer = append(q.buffer, segment...)
+		}
+		if end < len(path) {
+			q.buffer = append(q.buffer, '/')
+		}
+		start = end + 1
+	}
+	if !quantized {
+		return path, false
+	}
+	return q.buffer, true
+}
+
+func (q *pathQuantizer) quantizeSegment(segment []byte) (string, bool) {
+	if len(segment) == 0 {
+		return "", false
+	}
+	for _, r := range q.rules {
+		if r.Re.Match(segment) {
+			if r.Repl == "" {
+				return quantizedSegment, true
+			}
+			return r.Repl, true
+		}
+	}
+	if isNumber(segment) || isUUID(segment) || isHexID(segment) {
+		return quantizedSegment, true
+	}
+	return "", false
+}
+
+func isNumber(segment []byte) bool {
+	for _, c := range segment {
+		if !isDigit(c) {
+			return false
+		}
+	}
+	return true
+}
+
+// isUUID returns whether the segment is a UUID, such as 123e4567-e89b-12d3-a456-426614174000
+func isUUID(segment []byte) bool {
+	if len(segment) != 36 {
+		return false
+	}
+	for i, c := range segment {
+		switch i {
+		case 8, 13, 18, 23:
+			if c != '-' {
+				return false
+			}
+		default:
+			if !isHexDigit(c) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// isHexID returns whether the segment is a long hexadecimal string, such as a hash. The segment must hold at least a
+// digit, so that long words made of the letters a to f aren't quantized.
+func isHexID(segment []byte) bool {
+	if len(segment) < minHexIDLength {
+		return false
+	}
+	digits := 0
+	for _, c := range segment {
+		if !isHexDigit(c) {
+			return false
+		}
+		if isDigit(c) {
+			digits++
+		}
+	}
+	return digits > 0
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isHexDigit(c byte) bool {
+	return isDigit(c) || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
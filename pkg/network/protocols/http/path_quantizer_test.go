@@ -0,0 +1,64 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build (windows && npm) || linux_bpf
+// +build windows,npm linux_bpf
+
+package http
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/network/config"
+)
+
+func TestPathQuantizer(t *testing.T) {
+	cfg := config.New()
+	cfg.EnableHTTPPathQuantization = true
+	cfg.HTTPPathQuantizationRules = []*config.ReplaceRule{
+		{
+			Re:   regexp.MustCompile("^[a-z]{2}-[A-Z]{2}$"),
+			Repl: "{locale}",
+		},
+		{
+			Re: regexp.MustCompile("^user-"),
+		},
+	}
+	q := newPathQuantizer(cfg)
+	require.NotNil(t, q)
+
+	for _, tt := range []struct {
+		path     string
+		expected string
+	}{
+		{"/", "/"},
+		{"/users", "/users"},
+		{"/users/123/orders/456", "/users/*/orders/*"},
+		{"/users/123/", "/users/*/"},
+		{"/v1/users/123", "/v1/users/*"},
+		{"/sessions/123e4567-e89b-12d3-a456-426614174000", "/sessions/*"},
+		{"/sessions/123e4567-e89b-12d3-a456-42661417400", "/sessions/123e4567-e89b-12d3-a456-42661417400"},
+		{"/blobs/9f86d081884c7d659a2feaa0c55ad015", "/blobs/*"},
+		{"/blobs/deadbeefdeadbeef", "/blobs/deadbeefdeadbeef"},
+		{"/blobs/9f86d081", "/blobs/9f86d081"},
+		{"/en-US/docs", "/{locale}/docs"},
+		{"/profiles/user-ana", "/profiles/*"},
+		{"12/34", "*/*"},
+	} {
+		quantized, ok := q.quantize([]byte(tt.path))
+		assert.Equal(t, tt.expected, string(quantized), tt.path)
+		assert.Equal(t, tt.expected != tt.path, ok, tt.path)
+	}
+}
+
+func TestPathQuantizerDisabled(t *testing.T) {
+	cfg := config.New()
+	cfg.EnableHTTPPathQuantization = false
+	assert.Nil(t, newPathQuantizer(cfg))
+}
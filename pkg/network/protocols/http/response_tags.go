@@ -0,0 +1,87 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package http
+
+import "sort"
+
+// ContentType is the normalized value of the Content-Type header of a response
+type ContentType uint8
+
+const (
+	// ContentTypeUnknown represents a missing or unsupported content type
+	ContentTypeUnknown ContentType = iota
+	// ContentTypeJSON represents the application/json content type
+	ContentTypeJSON
+	// ContentTypeHTML represents the text/html content type
+	ContentTypeHTML
+	// ContentTypeGRPC represents the application/grpc content type and its variants
+	ContentTypeGRPC
+	// ContentTypeOctetStream represents the application/octet-stream content type
+	ContentTypeOctetStream
+)
+
+// SizeClass is the bucket of the Content-Length header of a response
+type SizeClass uint8
+
+const (
+	// SizeClassUnknown represents a missing Content-Length header
+	SizeClassUnknown SizeClass = iota
+	// SizeClassSmall represents a body of less than 1KiB
+	SizeClassSmall
+	// SizeClassMedium represents a body of less than 100KiB
+	SizeClassMedium
+	// SizeClassLarge represents a body of less than 1MiB
+	SizeClassLarge
+	// SizeClassHuge represents a body of 1MiB or more
+	SizeClassHuge
+)
+
+// ResponseTag is a bitfield of the content types and size classes of the responses of a group of transactions
+type ResponseTag uint16
+
+const sizeClassTagsShift = 8
+
+var responseTags = map[ResponseTag]string{
+	contentTypeTag(ContentTypeJSON):        "http.content_type:json",
+	contentTypeTag(ContentTypeHTML):        "http.content_type:html",
+	contentTypeTag(ContentTypeGRPC):        "http.content_type:grpc",
+	contentTypeTag(ContentTypeOctetStream): "http.content_type:octet-stream",
+	sizeClassTag(SizeClassSmall):           "http.response_size:small",
+	sizeClassTag(SizeClassMedium):          "http.response_size:medium",
+	sizeClassTag(SizeClassLarge):           "http.response_size:large",
+	sizeClassTag(SizeClassHuge):            "http.response_size:huge",
+}
+
+func contentTypeTag(ct ContentType) ResponseTag {
+	if ct == ContentTypeUnknown {
+		return 0
+	}
+	return 1 << (ct - 1)
+}
+
+func sizeClassTag(sc SizeClass) ResponseTag {
+	if sc == SizeClassUnknown {
+		return 0
+	}
+	return 1 << (sizeClassTagsShift + sc - 1)
+}
+
+// NewResponseTag returns the tag of a response with the given content type and size class
+func NewResponseTag(ct ContentType, sc SizeClass) ResponseTag {
+	return contentTypeTag(ct) | sizeClassTag(sc)
+}
+
+// Tags returns the sorted string list of the tags of the bitfield
+func (t ResponseTag) Tags() []string {
+	var tags []string
+	for tag, str := range responseTags {
+		if t&tag > 0 {
+			tags = append(tags, str)
+		}
+	}
+	sort.Strings(tags)
+	return tags
+}
@@ -24,6 +24,7 @@ type Telemetry struct {
 	dropped      *libtelemetry.Metric // this happens when httpStatKeeper reaches capacity
 	rejected     *libtelemetry.Metric // this happens when an user-defined reject-filter matches a request
 	malformed    *libtelemetry.Metric // this happens when the request doesn't have the expected format
+	quantized    *libtelemetry.Metric // this happens when the path quantization replaces identifiers of the path
 	aggregations *libtelemetry.Metric
 }
 
@@ -42,6 +43,7 @@ func NewTelemetry() (*Telemetry, error) {
 		hits4XX:      metricGroup.NewMetric("hits4xx"),
 		hits5XX:      metricGroup.NewMetric("hits5xx"),
 		aggregations: metricGroup.NewMetric("aggregations"),
+		quantized:    metricGroup.NewMetric("quantized"),
 
 		// these metrics are also exported as statsd metrics
 		totalHits: metricGroup.NewMetric("total_hits", libtelemetry.OptStatsd),
@@ -0,0 +1,45 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package http
+
+import (
+	"crypto/tls"
+	"sync"
+)
+
+var tlsVersionTags = map[uint16]string{
+	tls.VersionTLS10: "tls.version:tls1.0",
+	tls.VersionTLS11: "tls.version:tls1.1",
+	tls.VersionTLS12: "tls.version:tls1.2",
+	tls.VersionTLS13: "tls.version:tls1.3",
+}
+
+// tlsTagsCache holds the tags of every pair of TLS version and cipher suite seen so far, so that the tags
+// of a transaction aren't built again for every transaction
+var tlsTagsCache sync.Map
+
+// tlsTags returns the tags of the TLS version and cipher suite negotiated by the connection of a transaction,
+// or nil if they're unknown. The returned slice is shared and must not be modified.
+func tlsTags(version uint16, cipherSuite uint16) []string {
+	if version == 0 {
+		return nil
+	}
+
+	key := uint32(version)<<16 | uint32(cipherSuite)
+	if tags, ok := tlsTagsCache.Load(key); ok {
+		return tags.([]string)
+	}
+
+	var tags []string
+	if versionTag, ok := tlsVersionTags[version]; ok {
+		tags = append(tags, versionTag)
+	}
+	if cipherSuite != 0 {
+		tags = append(tags, "tls.cipher_suite:"+tls.CipherSuiteName(cipherSuite))
+	}
+	tlsTagsCache.Store(key, tags)
+	return tags
+}
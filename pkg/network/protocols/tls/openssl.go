@@ -6,6 +6,7 @@
 package tls
 
 import (
+	"path/filepath"
 	"regexp"
 	"testing"
 
@@ -33,3 +34,15 @@ func RunClientOpenssl(t *testing.T, addr string, port string, args string) bool
 	}
 	return protocolsUtils.RunHostServer(t, command, []string{}, regexp.MustCompile("Verify return code"))
 }
+
+// RunNginxAlpine runs an HTTPS nginx server on Alpine, whose libssl is built for musl, with the certificate of the
+// HTTP test servers
+func RunNginxAlpine(t *testing.T, serverPort string) error {
+	t.Helper()
+	dir, _ := testutil.CurDir()
+	env := []string{
+		"NGINX_PORT=" + serverPort,
+		"CERTS_DIR=" + filepath.Join(dir, "../http/testutil/testdata"),
+	}
+	return protocolsUtils.RunDockerServer(t, "nginx-alpine", dir+"/testdata/nginx-alpine/docker-compose.yml", env, regexp.MustCompile("ready for start up"), protocolsUtils.DefaultTimeout)
+}
@@ -18,6 +18,8 @@ const (
 	AMQP
 	Redis
 	MySQL
+	QUIC
+	DNS
 )
 
 func (p ProtocolType) String() string {
@@ -42,6 +44,10 @@ func (p ProtocolType) String() string {
 		return "Redis"
 	case MySQL:
 		return "MySQL"
+	case QUIC:
+		return "QUIC"
+	case DNS:
+		return "DNS"
 	default:
 		// shouldn't happen
 		return "Invalid"
@@ -0,0 +1,201 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package network
+
+import (
+	"net/netip"
+	"sort"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+	"github.com/DataDog/datadog-agent/pkg/telemetry"
+)
+
+const serviceDependenciesTelemetryModuleName = "network_tracer__service_dependencies"
+
+// unknownService is the service of the connections whose process doesn't set DD_SERVICE
+const unknownService = "unknown"
+
+var serviceDependenciesTelemetry = struct {
+	dependencies  telemetry.Gauge
+	rollups       telemetry.Counter
+	rolledUpConns telemetry.Counter
+	removedConns  telemetry.Counter
+}{
+	telemetry.NewGauge(serviceDependenciesTelemetryModuleName, "dependencies", []string{}, "Gauge measuring the number of service dependencies computed by the last request"),
+	telemetry.NewCounter(serviceDependenciesTelemetryModuleName, "rollups", []string{}, "Counter measuring the number of connections payloads rolled up because they exceeded the rollup threshold"),
+	telemetry.NewCounter(serviceDependenciesTelemetryModuleName, "rolled_up_conns", []string{}, "Counter measuring the number of connections of the payloads which were rolled up"),
+	telemetry.NewCounter(serviceDependenciesTelemetryModuleName, "rolled_up_removed_conns", []string{}, "Counter measuring the number of connections removed from the payloads by merging them into another connection"),
+}
+
+// ServiceDependency is the traffic between a client service and a server service over the last interval.
+// Clients and servers which aren't running on the host are identified by their address.
+type ServiceDependency struct {
+	Client      string `json:"client"`
+	Server      string `json:"server"`
+	Connections int    `json:"connections"`
+	// SentBytes is the number of bytes sent by the client to the server
+	SentBytes uint64 `json:"sent_bytes"`
+	// RecvBytes is the number of bytes received by the client from the server
+	RecvBytes uint64 `json:"recv_bytes"`
+}
+
+// TotalBytes returns the number of bytes exchanged between the client and the server
+func (d ServiceDependency) TotalBytes() uint64 {
+	return d.SentBytes + d.RecvBytes
+}
+
+type serviceDependencyKey struct {
+	client string
+	server string
+}
+
+// ServiceDependencies rolls the connections up into the dependencies between the services, using the
+// Last counters of the connections. The server of an outgoing connection is resolved after NAT, so that
+// e.g. a connection to a Kubernetes service is attributed to the pod which received it. A connection
+// between two processes of the host is only counted once, from the client side. Dependencies are
+// returned from the one exchanging the most bytes to the one exchanging the least.
+func ServiceDependencies(conns []ConnectionStats) []ServiceDependency {
+	// the services listening on the host, by address
+	servers := make(map[netip.AddrPort]string)
+	for i := range conns {
+		c := &conns[i]
+		if c.Direction == INCOMING {
+			servers[netip.AddrPortFrom(c.Source.Addr, c.SPort)] = connectionService(c)
+		}
+	}
+
+	byKey := make(map[serviceDependencyKey]*ServiceDependency)
+	for i := range conns {
+		c := &conns[i]
+
+		var key serviceDependencyKey
+		var sent, recv uint64
+		switch c.Direction {
+		case OUTGOING:
+			serverAddr := netip.AddrPortFrom(c.Dest.Addr, c.DPort)
+			if c.IPTranslation != nil {
+				serverAddr = netip.AddrPortFrom(c.IPTranslation.ReplSrcIP.Addr, c.IPTranslation.ReplSrcPort)
+			}
+			server, ok := servers[serverAddr]
+			if !ok {
+				server = serverAddr.String()
+			}
+			key = serviceDependencyKey{client: connectionService(c), server: server}
+			sent, recv = c.Last.SentBytes, c.Last.RecvBytes
+		case INCOMING:
+			if c.IntraHost {
+				// counted with the outgoing connection of the client
+				continue
+			}
+			// the port of the client is ephemeral
+			key = serviceDependencyKey{client: c.Dest.String(), server: connectionService(c)}
+			sent, recv = c.Last.RecvBytes, c.Last.SentBytes
+		default:
+			continue
+		}
+
+		d, ok := byKey[key]
+		if !ok {
+			d = &ServiceDependency{Client: key.client, Server: key.server}
+			byKey[key] = d
+		}
+		d.Connections++
+		d.SentBytes += sent
+		d.RecvBytes += recv
+	}
+
+	result := make([]ServiceDependency, 0, len(byKey))
+	for _, d := range byKey {
+		result = append(result, *d)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].TotalBytes() != result[j].TotalBytes() {
+			return result[i].TotalBytes() > result[j].TotalBytes()
+		}
+		if result[i].Client != result[j].Client {
+			return result[i].Client < result[j].Client
+		}
+		return result[i].Server < result[j].Server
+	})
+	serviceDependenciesTelemetry.dependencies.Set(float64(len(result)))
+	return result
+}
+
+type rollupKey struct {
+	pid       uint32
+	netNS     uint32
+	family    ConnectionFamily
+	connType  ConnectionType
+	direction ConnectionDirection
+	client    util.Address
+	server    netip.AddrPort
+}
+
+// RollUpConnections reduces the size of the connections payloads of very dense hosts, by merging the
+// connections of a process with the same server into a single connection without the ephemeral port
+// of the client. The server of an outgoing connection is resolved after NAT, so that the connections to
+// a Kubernetes service remain split by the pod which received them. The counters of the merged connections
+// are summed. The USM and DNS stats of the merged connections aren't matched anymore, as they're keyed by
+// the client port. The connections are rolled up in place, and the rolled up slice is returned.
+func RollUpConnections(conns []ConnectionStats) []ConnectionStats {
+	byKey := make(map[rollupKey]int, len(conns))
+	n := 0
+	for i := range conns {
+		c := conns[i]
+
+		var key rollupKey
+		switch c.Direction {
+		case OUTGOING:
+			server := netip.AddrPortFrom(c.Dest.Addr, c.DPort)
+			if c.IPTranslation != nil {
+				server = netip.AddrPortFrom(c.IPTranslation.ReplSrcIP.Addr, c.IPTranslation.ReplSrcPort)
+				// the translation is shared with the conntrack cache
+				translation := *c.IPTranslation
+				translation.ReplDstPort = 0
+				c.IPTranslation = &translation
+			}
+			key = rollupKey{client: c.Source, server: server}
+			c.SPort = 0
+		case INCOMING:
+			key = rollupKey{client: c.Dest, server: netip.AddrPortFrom(c.Source.Addr, c.SPort)}
+			c.DPort = 0
+		default:
+			conns[n] = c
+			n++
+			continue
+		}
+		key.pid, key.netNS, key.family, key.connType, key.direction = c.Pid, c.NetNS, c.Family, c.Type, c.Direction
+
+		if j, ok := byKey[key]; ok {
+			merged := &conns[j]
+			merged.Monotonic = merged.Monotonic.Add(c.Monotonic)
+			merged.Last = merged.Last.Add(c.Last)
+			if c.LastUpdateEpoch > merged.LastUpdateEpoch {
+				merged.LastUpdateEpoch = c.LastUpdateEpoch
+			}
+			continue
+		}
+		byKey[key] = n
+		conns[n] = c
+		n++
+	}
+
+	serviceDependenciesTelemetry.rollups.Inc()
+	serviceDependenciesTelemetry.rolledUpConns.Add(float64(len(conns)))
+	serviceDependenciesTelemetry.removedConns.Add(float64(len(conns) - n))
+	return conns[:n]
+}
+
+// connectionService returns the service of the process which owns the connection
+func connectionService(c *ConnectionStats) string {
+	for tag := range c.Tags {
+		if service := strings.TrimPrefix(tag, "service:"); service != tag {
+			return service
+		}
+	}
+	return unknownService
+}
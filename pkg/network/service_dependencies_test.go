@@ -0,0 +1,171 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package network
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+)
+
+func TestServiceDependencies(t *testing.T) {
+	frontendTags := map[string]struct{}{"service:frontend": {}, "env:prod": {}}
+	backendTags := map[string]struct{}{"service:backend": {}}
+
+	conns := []ConnectionStats{
+		// frontend -> backend through a Kubernetes service, counted from the frontend side
+		{
+			Source: util.AddressFromString("10.0.0.1"), SPort: 40000,
+			Dest: util.AddressFromString("172.16.0.10"), DPort: 80,
+			IPTranslation: &IPTranslation{
+				ReplSrcIP: util.AddressFromString("10.0.0.2"), ReplSrcPort: 8080,
+				ReplDstIP: util.AddressFromString("10.0.0.1"), ReplDstPort: 40000,
+			},
+			Direction: OUTGOING, IntraHost: true, Tags: frontendTags,
+			Last: StatCounters{SentBytes: 100, RecvBytes: 1000},
+		},
+		{
+			Source: util.AddressFromString("10.0.0.1"), SPort: 40001,
+			Dest: util.AddressFromString("172.16.0.10"), DPort: 80,
+			IPTranslation: &IPTranslation{
+				ReplSrcIP: util.AddressFromString("10.0.0.2"), ReplSrcPort: 8080,
+				ReplDstIP: util.AddressFromString("10.0.0.1"), ReplDstPort: 40001,
+			},
+			Direction: OUTGOING, IntraHost: true, Tags: frontendTags,
+			Last: StatCounters{SentBytes: 50, RecvBytes: 500},
+		},
+		{
+			Source: util.AddressFromString("10.0.0.2"), SPort: 8080,
+			Dest: util.AddressFromString("10.0.0.1"), DPort: 40000,
+			Direction: INCOMING, IntraHost: true, Tags: backendTags,
+			Last: StatCounters{SentBytes: 1000, RecvBytes: 100},
+		},
+		// remote client -> frontend
+		{
+			Source: util.AddressFromString("10.0.0.1"), SPort: 443,
+			Dest: util.AddressFromString("192.168.1.1"), DPort: 51000,
+			Direction: INCOMING, Tags: frontendTags,
+			Last: StatCounters{SentBytes: 300, RecvBytes: 30},
+		},
+		// backend -> remote database, without DD_SERVICE
+		{
+			Source: util.AddressFromString("10.0.0.2"), SPort: 41000,
+			Dest: util.AddressFromString("10.1.0.5"), DPort: 5432,
+			Direction: OUTGOING, Tags: backendTags,
+			Last: StatCounters{SentBytes: 10, RecvBytes: 20},
+		},
+		{
+			Source: util.AddressFromString("10.0.0.3"), SPort: 42000,
+			Dest: util.AddressFromString("10.1.0.5"), DPort: 5432,
+			Direction: OUTGOING,
+		},
+		// no direction
+		{
+			Source: util.AddressFromString("10.0.0.3"), SPort: 53,
+			Dest: util.AddressFromString("10.1.0.6"), DPort: 53,
+			Direction: NONE,
+		},
+	}
+
+	assert.Equal(t, []ServiceDependency{
+		{Client: "frontend", Server: "backend", Connections: 2, SentBytes: 150, RecvBytes: 1500},
+		{Client: "192.168.1.1", Server: "frontend", Connections: 1, SentBytes: 30, RecvBytes: 300},
+		{Client: "backend", Server: "10.1.0.5:5432", Connections: 1, SentBytes: 10, RecvBytes: 20},
+		{Client: "unknown", Server: "10.1.0.5:5432", Connections: 1},
+	}, ServiceDependencies(conns))
+
+	assert.Empty(t, ServiceDependencies(nil))
+}
+
+func TestRollUpConnections(t *testing.T) {
+	translation := &IPTranslation{
+		ReplSrcIP: util.AddressFromString("10.0.0.2"), ReplSrcPort: 8080,
+		ReplDstIP: util.AddressFromString("10.0.0.1"), ReplDstPort: 40000,
+	}
+	conns := []ConnectionStats{
+		// two connections of the same process to the same pod, through a Kubernetes service
+		{
+			Source: util.AddressFromString("10.0.0.1"), SPort: 40000,
+			Dest: util.AddressFromString("172.16.0.10"), DPort: 80,
+			IPTranslation: translation,
+			Pid:           1, Direction: OUTGOING,
+			Monotonic: StatCounters{SentBytes: 100, TCPEstablished: 1}, Last: StatCounters{SentBytes: 10},
+			LastUpdateEpoch: 1,
+		},
+		{
+			Source: util.AddressFromString("10.0.0.1"), SPort: 40001,
+			Dest: util.AddressFromString("172.16.0.10"), DPort: 80,
+			IPTranslation: &IPTranslation{
+				ReplSrcIP: util.AddressFromString("10.0.0.2"), ReplSrcPort: 8080,
+				ReplDstIP: util.AddressFromString("10.0.0.1"), ReplDstPort: 40001,
+			},
+			Pid: 1, Direction: OUTGOING,
+			Monotonic: StatCounters{SentBytes: 50, TCPEstablished: 1}, Last: StatCounters{SentBytes: 5},
+			LastUpdateEpoch: 2,
+		},
+		// same service, but the connection was routed to another pod
+		{
+			Source: util.AddressFromString("10.0.0.1"), SPort: 40002,
+			Dest: util.AddressFromString("172.16.0.10"), DPort: 80,
+			IPTranslation: &IPTranslation{
+				ReplSrcIP: util.AddressFromString("10.0.0.3"), ReplSrcPort: 8080,
+				ReplDstIP: util.AddressFromString("10.0.0.1"), ReplDstPort: 40002,
+			},
+			Pid: 1, Direction: OUTGOING,
+		},
+		// another process
+		{
+			Source: util.AddressFromString("10.0.0.1"), SPort: 40003,
+			Dest: util.AddressFromString("172.16.0.10"), DPort: 80,
+			Pid: 2, Direction: OUTGOING,
+		},
+		// two connections of the same client to a local server
+		{
+			Source: util.AddressFromString("10.0.0.1"), SPort: 443,
+			Dest: util.AddressFromString("192.168.1.1"), DPort: 51000,
+			Pid: 3, Direction: INCOMING,
+			Monotonic: StatCounters{RecvBytes: 30},
+		},
+		{
+			Source: util.AddressFromString("10.0.0.1"), SPort: 443,
+			Dest: util.AddressFromString("192.168.1.1"), DPort: 51001,
+			Pid: 3, Direction: INCOMING,
+			Monotonic: StatCounters{RecvBytes: 20},
+		},
+		// no direction
+		{
+			Source: util.AddressFromString("10.0.0.3"), SPort: 53,
+			Dest: util.AddressFromString("10.1.0.6"), DPort: 53,
+			Direction: NONE,
+		},
+	}
+
+	rolledUp := RollUpConnections(conns)
+	assert.Len(t, rolledUp, 5)
+
+	assert.Equal(t, uint16(0), rolledUp[0].SPort)
+	assert.Equal(t, uint16(80), rolledUp[0].DPort)
+	assert.Equal(t, StatCounters{SentBytes: 150, TCPEstablished: 2}, rolledUp[0].Monotonic)
+	assert.Equal(t, StatCounters{SentBytes: 15}, rolledUp[0].Last)
+	assert.Equal(t, uint64(2), rolledUp[0].LastUpdateEpoch)
+	assert.Equal(t, uint16(0), rolledUp[0].IPTranslation.ReplDstPort)
+	// the translation shared with the conntrack cache isn't modified
+	assert.Equal(t, uint16(40000), translation.ReplDstPort)
+
+	assert.Equal(t, "10.0.0.3", rolledUp[1].IPTranslation.ReplSrcIP.String())
+	assert.Equal(t, uint32(2), rolledUp[2].Pid)
+
+	assert.Equal(t, uint16(443), rolledUp[3].SPort)
+	assert.Equal(t, uint16(0), rolledUp[3].DPort)
+	assert.Equal(t, StatCounters{RecvBytes: 50}, rolledUp[3].Monotonic)
+
+	assert.Equal(t, NONE, rolledUp[4].Direction)
+	assert.Equal(t, uint16(53), rolledUp[4].SPort)
+
+	assert.Empty(t, RollUpConnections(nil))
+}
@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/network/dns"
+	usmdns "github.com/DataDog/datadog-agent/pkg/network/protocols/dns"
 	"github.com/DataDog/datadog-agent/pkg/network/protocols/http"
 	"github.com/DataDog/datadog-agent/pkg/network/protocols/kafka"
 	nettelemetry "github.com/DataDog/datadog-agent/pkg/network/telemetry"
@@ -35,6 +36,7 @@ var stateTelemetry = struct {
 	httpStatsDropped      *nettelemetry.StatCounterWrapper
 	http2StatsDropped     *nettelemetry.StatCounterWrapper
 	kafkaStatsDropped     *nettelemetry.StatCounterWrapper
+	usmDNSStatsDropped    *nettelemetry.StatCounterWrapper
 	dnsPidCollisions      *nettelemetry.StatCounterWrapper
 }{
 	nettelemetry.NewStatCounterWrapper(stateModuleName, "closed_conn_dropped", []string{}, "Counter measuring the number of dropped closed connections"),
@@ -46,6 +48,7 @@ var stateTelemetry = struct {
 	nettelemetry.NewStatCounterWrapper(stateModuleName, "http_stats_dropped", []string{}, "Counter measuring the number of http stats dropped"),
 	nettelemetry.NewStatCounterWrapper(stateModuleName, "http2_stats_dropped", []string{}, "Counter measuring the number of http2 stats dropped"),
 	nettelemetry.NewStatCounterWrapper(stateModuleName, "kafka_stats_dropped", []string{}, "Counter measuring the number of kafka stats dropped"),
+	nettelemetry.NewStatCounterWrapper(stateModuleName, "usm_dns_stats_dropped", []string{}, "Counter measuring the number of DNS stats of the DNS monitoring dropped"),
 	nettelemetry.NewStatCounterWrapper(stateModuleName, "dns_pid_collisions", []string{}, "Counter measuring the number of DNS PID collisions"),
 }
 
@@ -77,6 +80,7 @@ type State interface {
 		http map[http.Key]*http.RequestStats,
 		http2 map[http.Key]*http.RequestStats,
 		kafka map[kafka.Key]*kafka.RequestStat,
+		usmDNS map[usmdns.Key]*usmdns.RequestStat,
 	) Delta
 
 	// GetTelemetryDelta returns the telemetry delta since last time the given client requested telemetry data.
@@ -85,6 +89,13 @@ type State interface {
 		telemetry map[ConnTelemetryType]int64,
 	) map[ConnTelemetryType]int64
 
+	// GetTCPFailuresDelta returns the failed TCP connection attempts by destination since the last time
+	// the given client requested them.
+	GetTCPFailuresDelta(
+		id string,
+		failures map[TCPFailureKey]TCPFailureCounts,
+	) map[TCPFailureKey]TCPFailureCounts
+
 	// RegisterClient starts tracking stateful data for the given client
 	// If the client is already registered, it does nothing.
 	RegisterClient(clientID string)
@@ -114,6 +125,7 @@ type Delta struct {
 	HTTP     map[http.Key]*http.RequestStats
 	HTTP2    map[http.Key]*http.RequestStats
 	Kafka    map[kafka.Key]*kafka.RequestStat
+	USMDNS   map[usmdns.Key]*usmdns.RequestStat
 	DNSStats dns.StatsByKeyByNameByType
 }
 
@@ -127,6 +139,7 @@ type lastStateTelemetry struct {
 	httpStatsDropped      int64
 	http2StatsDropped     int64
 	kafkaStatsDropped     int64
+	usmDNSStatsDropped    int64
 	dnsPidCollisions      int64
 }
 
@@ -140,11 +153,13 @@ type client struct {
 	closedConnections []ConnectionStats
 	stats             map[uint32]StatCounters
 	// maps by dns key the domain (string) to stats structure
-	dnsStats        dns.StatsByKeyByNameByType
-	httpStatsDelta  map[http.Key]*http.RequestStats
-	http2StatsDelta map[http.Key]*http.RequestStats
-	kafkaStatsDelta map[kafka.Key]*kafka.RequestStat
-	lastTelemetries map[ConnTelemetryType]int64
+	dnsStats         dns.StatsByKeyByNameByType
+	httpStatsDelta   map[http.Key]*http.RequestStats
+	http2StatsDelta  map[http.Key]*http.RequestStats
+	kafkaStatsDelta  map[kafka.Key]*kafka.RequestStat
+	usmDNSStatsDelta map[usmdns.Key]*usmdns.RequestStat
+	lastTelemetries  map[ConnTelemetryType]int64
+	lastTCPFailures  map[TCPFailureKey]TCPFailureCounts
 }
 
 func (c *client) Reset(active map[uint32]*ConnectionStats) {
@@ -159,6 +174,7 @@ func (c *client) Reset(active map[uint32]*ConnectionStats) {
 	c.httpStatsDelta = make(map[http.Key]*http.RequestStats)
 	c.http2StatsDelta = make(map[http.Key]*http.RequestStats)
 	c.kafkaStatsDelta = make(map[kafka.Key]*kafka.RequestStat)
+	c.usmDNSStatsDelta = make(map[usmdns.Key]*usmdns.RequestStat)
 
 	// XXX: we should change the way we clean this map once
 	// https://github.com/golang/go/issues/20135 is solved
@@ -188,12 +204,13 @@ type networkState struct {
 	maxDNSStats    int
 	maxHTTPStats   int
 	maxKafkaStats  int
+	maxUSMDNSStats int
 
 	mergeStatsBuffers [2][]byte
 }
 
 // NewState creates a new network state
-func NewState(clientExpiry time.Duration, maxClosedConns, maxClientStats int, maxDNSStats int, maxHTTPStats int, maxKafkaStats int) State {
+func NewState(clientExpiry time.Duration, maxClosedConns, maxClientStats int, maxDNSStats int, maxHTTPStats int, maxKafkaStats int, maxUSMDNSStats int) State {
 	return &networkState{
 		clients:        map[string]*client{},
 		clientExpiry:   clientExpiry,
@@ -202,6 +219,7 @@ func NewState(clientExpiry time.Duration, maxClosedConns, maxClientStats int, ma
 		maxDNSStats:    maxDNSStats,
 		maxHTTPStats:   maxHTTPStats,
 		maxKafkaStats:  maxKafkaStats,
+		maxUSMDNSStats: maxUSMDNSStats,
 		mergeStatsBuffers: [2][]byte{
 			make([]byte, ConnectionByteKeyMaxLen),
 			make([]byte, ConnectionByteKeyMaxLen),
@@ -237,6 +255,36 @@ func (ns *networkState) GetTelemetryDelta(
 	return nil
 }
 
+// GetTCPFailuresDelta computes the delta of the monotonic failure counters, only returning
+// the destinations with new failures.
+func (ns *networkState) GetTCPFailuresDelta(
+	id string,
+	failures map[TCPFailureKey]TCPFailureCounts,
+) map[TCPFailureKey]TCPFailureCounts {
+	ns.Lock()
+	defer ns.Unlock()
+
+	if len(failures) == 0 {
+		return nil
+	}
+
+	client := ns.getClient(id)
+	res := make(map[TCPFailureKey]TCPFailureCounts)
+	for key, counts := range failures {
+		prev := client.lastTCPFailures[key]
+		delta := TCPFailureCounts{
+			Refused:  counts.Refused - prev.Refused,
+			TimedOut: counts.TimedOut - prev.TimedOut,
+			Reset:    counts.Reset - prev.Reset,
+		}
+		if delta != (TCPFailureCounts{}) {
+			res[key] = delta
+		}
+	}
+	client.lastTCPFailures = failures
+	return res
+}
+
 // GetDelta returns the connections for the given client
 // If the client is not registered yet, we register it and return the connections we have in the global state
 // Otherwise we return both the connections with last stats and the closed connections for this client
@@ -248,6 +296,7 @@ func (ns *networkState) GetDelta(
 	httpStats map[http.Key]*http.RequestStats,
 	http2Stats map[http.Key]*http.RequestStats,
 	kafkaStats map[kafka.Key]*kafka.RequestStat,
+	usmDNSStats map[usmdns.Key]*usmdns.RequestStat,
 ) Delta {
 	ns.Lock()
 	defer ns.Unlock()
@@ -279,6 +328,10 @@ func (ns *networkState) GetDelta(
 		ns.storeHTTP2Stats(http2Stats)
 	}
 
+	if len(usmDNSStats) > 0 {
+		ns.storeUSMDNSStats(usmDNSStats)
+	}
+
 	return Delta{
 		BufferedData: BufferedData{
 			Conns:  conns,
@@ -288,6 +341,7 @@ func (ns *networkState) GetDelta(
 		HTTP2:    client.http2StatsDelta,
 		DNSStats: client.dnsStats,
 		Kafka:    client.kafkaStatsDelta,
+		USMDNS:   client.usmDNSStatsDelta,
 	}
 }
 
@@ -340,11 +394,13 @@ func (ns *networkState) logTelemetry() {
 	httpStatsDroppedDelta := stateTelemetry.httpStatsDropped.Load() - ns.lastTelemetry.httpStatsDropped
 	http2StatsDroppedDelta := stateTelemetry.http2StatsDropped.Load() - ns.lastTelemetry.http2StatsDropped
 	kafkaStatsDroppedDelta := stateTelemetry.kafkaStatsDropped.Load() - ns.lastTelemetry.kafkaStatsDropped
+	usmDNSStatsDroppedDelta := stateTelemetry.usmDNSStatsDropped.Load() - ns.lastTelemetry.usmDNSStatsDropped
 	dnsPidCollisionsDelta := stateTelemetry.dnsPidCollisions.Load() - ns.lastTelemetry.dnsPidCollisions
 
 	// Flush log line if any metric is non-zero
 	if statsUnderflowsDelta > 0 || statsCookieCollisionsDelta > 0 || closedConnDroppedDelta > 0 || connDroppedDelta > 0 || timeSyncCollisionsDelta > 0 ||
-		dnsStatsDroppedDelta > 0 || httpStatsDroppedDelta > 0 || http2StatsDroppedDelta > 0 || kafkaStatsDroppedDelta > 0 || dnsPidCollisionsDelta > 0 {
+		dnsStatsDroppedDelta > 0 || httpStatsDroppedDelta > 0 || http2StatsDroppedDelta > 0 || kafkaStatsDroppedDelta > 0 || usmDNSStatsDroppedDelta > 0 ||
+		dnsPidCollisionsDelta > 0 {
 		s := "state telemetry: "
 		s += " [%d stats stats_underflows]"
 		s += " [%d stats cookie collisions]"
@@ -354,6 +410,7 @@ func (ns *networkState) logTelemetry() {
 		s += " [%d HTTP stats dropped]"
 		s += " [%d HTTP2 stats dropped]"
 		s += " [%d Kafka stats dropped]"
+		s += " [%d USM DNS stats dropped]"
 		s += " [%d DNS pid collisions]"
 		s += " [%d time sync collisions]"
 		log.Warnf(s,
@@ -365,6 +422,7 @@ func (ns *networkState) logTelemetry() {
 			httpStatsDroppedDelta,
 			http2StatsDroppedDelta,
 			kafkaStatsDroppedDelta,
+			usmDNSStatsDroppedDelta,
 			dnsPidCollisionsDelta,
 			timeSyncCollisionsDelta)
 	}
@@ -378,6 +436,7 @@ func (ns *networkState) logTelemetry() {
 	ns.lastTelemetry.httpStatsDropped = stateTelemetry.httpStatsDropped.Load()
 	ns.lastTelemetry.http2StatsDropped = stateTelemetry.http2StatsDropped.Load()
 	ns.lastTelemetry.kafkaStatsDropped = stateTelemetry.kafkaStatsDropped.Load()
+	ns.lastTelemetry.usmDNSStatsDropped = stateTelemetry.usmDNSStatsDropped.Load()
 	ns.lastTelemetry.dnsPidCollisions = stateTelemetry.dnsPidCollisions.Load()
 }
 
@@ -605,6 +664,37 @@ func (ns *networkState) storeKafkaStats(allStats map[kafka.Key]*kafka.RequestSta
 	}
 }
 
+// storeUSMDNSStats stores the latest stats of the DNS monitoring for all clients
+func (ns *networkState) storeUSMDNSStats(allStats map[usmdns.Key]*usmdns.RequestStat) {
+	if len(ns.clients) == 1 {
+		for _, client := range ns.clients {
+			if len(client.usmDNSStatsDelta) == 0 {
+				// optimization for the common case:
+				// if there is only one client and no previous state, no memory allocation is needed
+				client.usmDNSStatsDelta = allStats
+				return
+			}
+		}
+	}
+
+	for key, stats := range allStats {
+		for _, client := range ns.clients {
+			prevStats, ok := client.usmDNSStatsDelta[key]
+			if !ok && len(client.usmDNSStatsDelta) >= ns.maxUSMDNSStats {
+				stateTelemetry.usmDNSStatsDropped.Inc()
+				continue
+			}
+
+			if prevStats != nil {
+				prevStats.CombineWith(stats)
+				client.usmDNSStatsDelta[key] = prevStats
+			} else {
+				client.usmDNSStatsDelta[key] = stats
+			}
+		}
+	}
+}
+
 func (ns *networkState) getClient(clientID string) *client {
 	if c, ok := ns.clients[clientID]; ok {
 		return c
@@ -619,7 +709,9 @@ func (ns *networkState) getClient(clientID string) *client {
 		httpStatsDelta:        map[http.Key]*http.RequestStats{},
 		http2StatsDelta:       map[http.Key]*http.RequestStats{},
 		kafkaStatsDelta:       map[kafka.Key]*kafka.RequestStat{},
+		usmDNSStatsDelta:      map[usmdns.Key]*usmdns.RequestStat{},
 		lastTelemetries:       make(map[ConnTelemetryType]int64),
+		lastTCPFailures:       make(map[TCPFailureKey]TCPFailureCounts),
 	}
 	ns.clients[clientID] = c
 	return c
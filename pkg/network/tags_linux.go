@@ -18,6 +18,7 @@ const (
 	ConnTagGo      = http.Go
 	ConnTagJava    = http.Java
 	ConnTagTLS     = http.TLS
+	ConnTagHTTP3   = http.HTTP3
 )
 
 // GetStaticTags return the string list of static tags from network.ConnectionStats.Tags
@@ -11,11 +11,13 @@ package telemetry
 import (
 	"fmt"
 	"hash/fnv"
+	"sync"
 	"syscall"
 	"unsafe"
 
 	"github.com/cilium/ebpf"
 	"github.com/cilium/ebpf/asm"
+	"golang.org/x/sys/unix"
 
 	"github.com/DataDog/datadog-agent/pkg/telemetry"
 	"github.com/DataDog/datadog-agent/pkg/util/kernel"
@@ -36,6 +38,8 @@ const (
 	perfEventOutput
 )
 
+// The error tag holds the symbolic errno name (e.g. E2BIG when a hash map is full, ENOMEM when
+// the memory limit is hit), which is stable across kernels and locales.
 var ebpfMapOpsErrorsGauge = telemetry.NewGauge("ebpf_map_ops", "errors", []string{"map_name", "error"}, "Failures of map operations for a specific ebpf map reported per error.")
 var ebpfHelperErrorsGauge = telemetry.NewGauge("ebpf_helpers", "errors", []string{"helper", "probe_name", "error"}, "Failures of bpf helper operations reported per helper per error for each probe.")
 
@@ -44,6 +48,7 @@ var helperNames = map[int]string{readIndx: "bpf_probe_read", readUserIndx: "bpf_
 // EBPFTelemetry struct contains all the maps that
 // are registered to have their telemetry collected.
 type EBPFTelemetry struct {
+	mtx          sync.Mutex
 	MapErrMap    *ebpf.Map
 	HelperErrMap *ebpf.Map
 	mapKeys      map[string]uint64
@@ -64,6 +69,9 @@ func (b *EBPFTelemetry) RegisterEBPFTelemetry(m *manager.Manager) error {
 		return nil
 	}
 
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
 	if err := b.initializeMapErrTelemetryMap(m.Maps); err != nil {
 		return err
 	}
@@ -80,6 +88,9 @@ func (b *EBPFTelemetry) GetMapsTelemetry() map[string]interface{} {
 		return nil
 	}
 
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
 	var val MapErrTelemetry
 	t := make(map[string]interface{})
 
@@ -106,6 +117,9 @@ func (b *EBPFTelemetry) GetHelperTelemetry() map[string]interface{} {
 		return nil
 	}
 
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
 	var val HelperErrTelemetry
 	helperTelemMap := make(map[string]interface{})
 
@@ -150,7 +164,7 @@ func getErrCount(v *HelperErrTelemetry, indx int) map[string]uint64 {
 				continue
 			}
 
-			errCount[syscall.Errno(i).Error()] = count
+			errCount[errnoName(i)] = count
 		}
 	}
 
@@ -169,12 +183,27 @@ func getMapErrCount(v *MapErrTelemetry) map[string]uint64 {
 			errCount[maxErrnoStr] = count
 			continue
 		}
-		errCount[syscall.Errno(i).Error()] = count
+		errCount[errnoName(i)] = count
 	}
 
 	return errCount
 }
 
+// errnoName returns the symbolic name of an errno value, falling back to its
+// description for values without a name
+func errnoName(errno int) string {
+	if name := unix.ErrnoName(syscall.Errno(errno)); name != "" {
+		return name
+	}
+	return syscall.Errno(errno).Error()
+}
+
+// RefreshTelemetry updates the agent metrics with the current map and helper error counts
+func (b *EBPFTelemetry) RefreshTelemetry() {
+	b.GetMapsTelemetry()
+	b.GetHelperTelemetry()
+}
+
 // BuildTelemetryKeys returns the keys used to index the maps holding telemetry
 // information for bpf helper errors.
 func BuildTelemetryKeys(mgr *manager.Manager) []manager.ConstantEditor {
@@ -163,6 +163,24 @@ func dumpMapsHandler(manager *manager.Manager, mapName string, currentMap *ebpf.
 			output.WriteString(spew.Sdump(key, value))
 		}
 
+	case probes.TCPListenOverflowsMap: // maps/tcp_listen_overflows (BPF_MAP_TYPE_HASH), key portBindingTuple, value C.listen_overflow_stats_t
+		output.WriteString("Map: '" + mapName + "', key: 'portBindingTuple', value: 'C.listen_overflow_stats_t'\n")
+		iter := currentMap.Iterate()
+		var key ddebpf.PortBinding
+		var value ddebpf.ListenOverflowStats
+		for iter.Next(unsafe.Pointer(&key), unsafe.Pointer(&value)) {
+			output.WriteString(spew.Sdump(key, value))
+		}
+
+	case probes.TCPFailuresMap: // maps/tcp_failures (BPF_MAP_TYPE_HASH), key C.conn_tuple_t, value C.tcp_failure_stats_t
+		output.WriteString("Map: '" + mapName + "', key: 'C.conn_tuple_t', value: 'C.tcp_failure_stats_t'\n")
+		iter := currentMap.Iterate()
+		var key ddebpf.ConnTuple
+		var value ddebpf.TCPFailureStats
+		for iter.Next(unsafe.Pointer(&key), unsafe.Pointer(&value)) {
+			output.WriteString(spew.Sdump(key, value))
+		}
+
 	case "pending_bind": // maps/pending_bind (BPF_MAP_TYPE_HASH), key C.__u64, value C.bind_syscall_args_t
 		output.WriteString("Map: '" + mapName + "', key: 'C.__u64', value: 'C.bind_syscall_args_t'\n")
 		iter := currentMap.Iterate()
@@ -13,22 +13,28 @@ import (
 	"fmt"
 
 	manager "github.com/DataDog/ebpf-manager"
+	"github.com/cilium/ebpf/btf"
 
 	ddebpf "github.com/DataDog/datadog-agent/pkg/ebpf"
 	"github.com/DataDog/datadog-agent/pkg/ebpf/bytecode"
 	"github.com/DataDog/datadog-agent/pkg/network/config"
 	errtelemetry "github.com/DataDog/datadog-agent/pkg/network/telemetry"
 	"github.com/DataDog/datadog-agent/pkg/util/fargate"
+	"github.com/DataDog/datadog-agent/pkg/util/kernel"
 )
 
 const probeUID = "net"
 
-var ErrorNotSupported = errors.New("fentry tracer is only supported on Fargate")
+// ErrorNotSupported is returned when the fentry tracer can't be used on the host
+var ErrorNotSupported = errors.New("fentry tracer is not supported")
+
+// minimumKernelVersion is the first kernel supporting fentry/fexit programs
+var minimumKernelVersion = kernel.VersionCode(5, 5, 0)
 
 // LoadTracer loads a new tracer
 func LoadTracer(config *config.Config, m *manager.Manager, mgrOpts manager.Options, perfHandlerTCP *ddebpf.PerfHandler) (func(), error) {
-	if !fargate.IsFargateInstance() {
-		return nil, ErrorNotSupported
+	if err := checkSupported(config.EnableFentry, fargate.IsFargateInstance(), kernel.HostVersion, hasKernelBTF); err != nil {
+		return nil, err
 	}
 
 	filename := "tracer-fentry.o"
@@ -82,3 +88,33 @@ func LoadTracer(config *config.Config, m *manager.Manager, mgrOpts manager.Optio
 
 	return nil, nil
 }
+
+// checkSupported returns an error wrapping ErrorNotSupported if the fentry tracer can't be used.
+// It is always used on Fargate, and elsewhere when it is enabled and the kernel supports fentry/fexit
+// programs, which requires kernel 5.5+ and the kernel BTF to resolve the traced functions.
+func checkSupported(enabled bool, isFargate bool, hostVersion func() (kernel.Version, error), kernelBTF func() error) error {
+	if isFargate {
+		return nil
+	}
+	if !enabled {
+		return fmt.Errorf("%w: network_config.enable_fentry is disabled", ErrorNotSupported)
+	}
+
+	kv, err := hostVersion()
+	if err != nil {
+		return fmt.Errorf("%w: could not determine the kernel version: %s", ErrorNotSupported, err)
+	}
+	if kv < minimumKernelVersion {
+		return fmt.Errorf("%w: kernel %s is older than %s", ErrorNotSupported, kv, minimumKernelVersion)
+	}
+
+	if err := kernelBTF(); err != nil {
+		return fmt.Errorf("%w: kernel BTF is not available: %s", ErrorNotSupported, err)
+	}
+	return nil
+}
+
+func hasKernelBTF() error {
+	_, err := btf.LoadKernelSpec()
+	return err
+}
@@ -0,0 +1,62 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux_bpf
+// +build linux_bpf
+
+package fentry
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/util/kernel"
+)
+
+func TestCheckSupported(t *testing.T) {
+	hostVersion := func(major, minor, patch byte) func() (kernel.Version, error) {
+		return func() (kernel.Version, error) {
+			return kernel.VersionCode(major, minor, patch), nil
+		}
+	}
+	withBTF := func() error { return nil }
+	withoutBTF := func() error { return errors.New("no BTF") }
+
+	tests := []struct {
+		name        string
+		enabled     bool
+		isFargate   bool
+		hostVersion func() (kernel.Version, error)
+		kernelBTF   func() error
+		supported   bool
+	}{
+		{name: "fargate", isFargate: true, hostVersion: hostVersion(4, 14, 0), kernelBTF: withoutBTF, supported: true},
+		{name: "disabled", hostVersion: hostVersion(5, 15, 0), kernelBTF: withBTF},
+		{name: "supported kernel", enabled: true, hostVersion: hostVersion(5, 5, 0), kernelBTF: withBTF, supported: true},
+		{name: "old kernel", enabled: true, hostVersion: hostVersion(5, 4, 0), kernelBTF: withBTF},
+		{name: "no BTF", enabled: true, hostVersion: hostVersion(5, 15, 0), kernelBTF: withoutBTF},
+		{
+			name:    "unknown kernel version",
+			enabled: true,
+			hostVersion: func() (kernel.Version, error) {
+				return 0, errors.New("unknown")
+			},
+			kernelBTF: withBTF,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkSupported(tt.enabled, tt.isFargate, tt.hostVersion, tt.kernelBTF)
+			if tt.supported {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrorNotSupported)
+			}
+		})
+	}
+}
@@ -65,6 +65,20 @@ func enabledProbes(c *config.Config, runtimeTracer, coreTracer bool) (map[probes
 		enableProbe(enabled, selectVersionBasedProbe(runtimeTracer || coreTracer, kv, probes.TCPRetransmit, probes.TCPRetransmitPre470, kv470))
 		enableProbe(enabled, probes.TCPRetransmitRet)
 
+		// listen overflow probes rely on struct sock fields we don't offset-guess
+		if c.CollectTCPListenOverflows && (runtimeTracer || coreTracer) {
+			enableProbe(enabled, probes.TCPConnRequest)
+			enableProbe(enabled, probes.TCPv4SynRecvSock)
+			if c.CollectTCPv6Conns {
+				enableProbe(enabled, probes.TCPv6SynRecvSock)
+			}
+		}
+
+		// the socket error is read from struct sock, which isn't offset-guessed either
+		if c.CollectTCPFailedConnections && (runtimeTracer || coreTracer) {
+			enableProbe(enabled, probes.TCPDone)
+		}
+
 		missing, err := ebpf.VerifyKernelFuncs("sockfd_lookup_light")
 		if err == nil && len(missing) == 0 {
 			enableProbe(enabled, probes.SockFDLookup)
@@ -54,6 +54,10 @@ var mainProbes = []probes.ProbeFuncName{
 	probes.UDPv6RecvMsgReturn,
 	probes.TCPRetransmit,
 	probes.TCPRetransmitRet,
+	probes.TCPConnRequest,
+	probes.TCPv4SynRecvSock,
+	probes.TCPv6SynRecvSock,
+	probes.TCPDone,
 	probes.InetCskAcceptReturn,
 	probes.InetCskListenStop,
 	probes.UDPDestroySock,
@@ -80,6 +84,8 @@ func initManager(mgr *manager.Manager, config *config.Config, closedHandler *ebp
 		{Name: "udpv6_recv_sock"},
 		{Name: probes.PortBindingsMap},
 		{Name: probes.UDPPortBindingsMap},
+		{Name: probes.TCPListenOverflowsMap},
+		{Name: probes.TCPFailuresMap},
 		{Name: "pending_bind"},
 		{Name: probes.TelemetryMap},
 		{Name: probes.SockByPidFDMap},
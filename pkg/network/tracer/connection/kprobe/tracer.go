@@ -176,6 +176,7 @@ func loadTracerFromAsset(buf bytecode.AssetReader, runtimeTracer, coreTracer boo
 	var closeProtocolClassifierSocketFilterFn func()
 	classificationSupported := ClassificationSupported(config)
 	addBoolConst(&mgrOpts, classificationSupported, "protocol_classification_enabled")
+	addBoolConst(&mgrOpts, classificationSupported && config.EnableHTTP3Monitoring, "quic_classification_enabled")
 
 	if classificationSupported {
 		socketFilterProbe, _ := m.GetProbe(manager.ProbeIdentificationPair{
@@ -12,6 +12,7 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"strconv"
 	"sync"
 	"time"
 	"unsafe"
@@ -46,6 +47,21 @@ const (
 	TracerTypeFentry
 )
 
+func (t TracerType) String() string {
+	switch t {
+	case TracerTypeKProbePrebuilt:
+		return "kprobe_prebuilt"
+	case TracerTypeKProbeRuntimeCompiled:
+		return "kprobe_runtime_compiled"
+	case TracerTypeKProbeCORE:
+		return "kprobe_core"
+	case TracerTypeFentry:
+		return "fentry"
+	default:
+		return "unknown"
+	}
+}
+
 // Tracer is the common interface implemented by all connection tracers.
 type Tracer interface {
 	// Start begins collecting network connection data.
@@ -62,6 +78,9 @@ type Tracer interface {
 	Remove(conn *network.ConnectionStats) error
 	// RefreshProbeTelemetry sets the prometheus objects to the current values of the underlying stats
 	RefreshProbeTelemetry()
+	// GetTCPFailures returns the number of failed TCP connection attempts by destination since the tracer started.
+	// It returns nil if the collection of failed connections is disabled or unsupported.
+	GetTCPFailures() (map[network.TCPFailureKey]network.TCPFailureCounts, error)
 	// GetMap returns the underlying named map. This is useful if any maps are shared with other eBPF components.
 	// An individual tracer implementation may choose which maps to expose via this function.
 	GetMap(string) *ebpf.Map
@@ -90,6 +109,13 @@ var ConnTracerTelemetry = struct {
 	UdpSendsMissed    *nettelemetry.StatGaugeWrapper
 	UdpDroppedConns   telemetry.Gauge
 	PidCollisions     *nettelemetry.StatCounterWrapper
+
+	listenSynBacklogDrops  telemetry.Counter
+	listenAcceptQueueDrops telemetry.Counter
+	listenAcceptQueueLen   telemetry.Gauge
+	listenAcceptQueueMax   telemetry.Gauge
+
+	tracerType telemetry.Gauge
 }{
 	telemetry.NewGauge(connTracerModuleName, "connections", []string{"ip_proto", "family"}, "Gauge measuring the number of active connections in the EBPF map"),
 	telemetry.NewGauge(connTracerModuleName, "tcp_failed_connects", []string{}, "Gauge measuring the number of failed TCP connections in the EBPF map"),
@@ -100,6 +126,12 @@ var ConnTracerTelemetry = struct {
 	nettelemetry.NewStatGaugeWrapper(connTracerModuleName, "udp_sends_missed", []string{}, "Gauge measuring failures to process UDP sends in EBPF"),
 	telemetry.NewGauge(connTracerModuleName, "udp_dropped_conns", []string{}, "Gauge measuring the number of dropped UDP connections in the EBPF map"),
 	nettelemetry.NewStatCounterWrapper(connTracerModuleName, "pid_collisions", []string{}, "Counter measuring number of process collisions"),
+	telemetry.NewCounter(connTracerModuleName, "tcp_listen_syn_backlog_drops", []string{"port"}, "Counter measuring the number of SYNs received while the SYN backlog of a listening port was full"),
+	telemetry.NewCounter(connTracerModuleName, "tcp_listen_accept_queue_drops", []string{"port"}, "Counter measuring the number of connections dropped because the accept queue of a listening port was full"),
+	telemetry.NewGauge(connTracerModuleName, "tcp_listen_accept_queue_len", []string{"port"}, "Gauge measuring the accept queue length of a listening port"),
+	telemetry.NewGauge(connTracerModuleName, "tcp_listen_accept_queue_max", []string{"port"}, "Gauge measuring the accept queue limit of a listening port"),
+
+	telemetry.NewGauge(connTracerModuleName, "tracer_type", []string{"type"}, "Gauge set to 1 for the type of the connection tracer in use, e.g. fentry or kprobe_core"),
 }
 
 type tracer struct {
@@ -119,6 +151,16 @@ type tracer struct {
 
 	ebpfTracerType TracerType
 
+	// listenOverflows is only set when listen overflow collection is enabled and supported
+	listenOverflows *ebpf.Map
+	// lastListenOverflows holds the values read on the previous telemetry refresh, used to compute counter deltas
+	lastListenOverflows map[netebpf.PortBinding]netebpf.ListenOverflowStats
+	// listenOverflowPorts holds the ports reported on the previous telemetry refresh
+	listenOverflowPorts map[uint16]struct{}
+
+	// tcpFailures is only set when failed connection collection is enabled and supported
+	tcpFailures *ebpf.Map
+
 	exitTelemetry chan struct{}
 }
 
@@ -165,14 +207,27 @@ func NewTracer(config *config.Config, constants []manager.ConstantEditor, bpfTel
 	var closeTracerFn func()
 	closeTracerFn, err := fentry.LoadTracer(config, m, fentryOptions, perfHandlerTCP)
 	if err != nil && !errors.Is(err, fentry.ErrorNotSupported) {
-		// failed to load fentry tracer
-		return nil, err
+		if !config.EnableFentry {
+			// failed to load fentry tracer
+			return nil, err
+		}
+		// the fentry tracer was opted into, the kprobe tracer is used instead of failing
+		log.Warnf("failed to load fentry tracer, falling back to kprobe tracer: %s", err)
+		// the fentry manager may have been initialized before failing, its maps and programs are released
+		// before it's replaced
+		if stopErr := m.Stop(manager.CleanAll); stopErr != nil && !errors.Is(stopErr, manager.ErrManagerNotInitialized) {
+			log.Warnf("failed to stop fentry tracer manager: %s", stopErr)
+		}
+		m = &manager.Manager{
+			DumpHandler: dumpMapsHandler,
+		}
+	} else if err != nil {
+		log.Infof("%s, falling back to kprobe tracer", err)
 	}
 
 	if err != nil {
 		mgrOptions.ConstantEditors = constants
 		// load the kprobe tracer
-		log.Info("fentry tracer not supported, falling back to kprobe tracer")
 		var kprobeTracerType kprobe.TracerType
 		closeTracerFn, kprobeTracerType, err = kprobe.LoadTracer(config, m, mgrOptions, perfHandlerTCP)
 		if err != nil {
@@ -180,6 +235,8 @@ func NewTracer(config *config.Config, constants []manager.ConstantEditor, bpfTel
 		}
 		tracerType = TracerType(kprobeTracerType)
 	}
+	log.Infof("using %s connection tracer", tracerType)
+	ConnTracerTelemetry.tracerType.Set(1, tracerType.String())
 
 	batchMgr, err := newConnBatchManager(m)
 	if err != nil {
@@ -213,6 +270,32 @@ func NewTracer(config *config.Config, constants []manager.ConstantEditor, bpfTel
 		return nil, fmt.Errorf("error retrieving the bpf %s map: %s", probes.TCPStatsMap, err)
 	}
 
+	if config.CollectTCPListenOverflows {
+		if tracerType == TracerTypeKProbeRuntimeCompiled || tracerType == TracerTypeKProbeCORE {
+			tr.listenOverflows, _, err = m.GetMap(probes.TCPListenOverflowsMap)
+			if err != nil {
+				tr.Stop()
+				return nil, fmt.Errorf("error retrieving the bpf %s map: %s", probes.TCPListenOverflowsMap, err)
+			}
+			tr.lastListenOverflows = make(map[netebpf.PortBinding]netebpf.ListenOverflowStats)
+			tr.listenOverflowPorts = make(map[uint16]struct{})
+		} else {
+			log.Warn("tcp listen overflow collection is only supported by the runtime compiled and CO-RE tracers")
+		}
+	}
+
+	if config.CollectTCPFailedConnections {
+		if tracerType == TracerTypeKProbeRuntimeCompiled || tracerType == TracerTypeKProbeCORE {
+			tr.tcpFailures, _, err = m.GetMap(probes.TCPFailuresMap)
+			if err != nil {
+				tr.Stop()
+				return nil, fmt.Errorf("error retrieving the bpf %s map: %s", probes.TCPFailuresMap, err)
+			}
+		} else {
+			log.Warn("tcp failed connection collection is only supported by the runtime compiled and CO-RE tracers")
+		}
+	}
+
 	if bpfTelemetry != nil {
 		bpfTelemetry.MapErrMap = tr.GetMap(probes.MapErrTelemetryMap)
 		bpfTelemetry.HelperErrMap = tr.GetMap(probes.HelperErrTelemetryMap)
@@ -453,6 +536,105 @@ func (t *tracer) refreshProbeTelemetry() {
 	ConnTracerTelemetry.UdpSendsProcessed.Set(int64(telemetry.Udp_sends_processed))
 	ConnTracerTelemetry.UdpSendsMissed.Set(int64(telemetry.Udp_sends_missed))
 	ConnTracerTelemetry.UdpDroppedConns.Set(float64(telemetry.Udp_dropped_conns))
+
+	if t.listenOverflows != nil {
+		t.refreshListenOverflowTelemetry()
+	}
+}
+
+// refreshListenOverflowTelemetry reports the listen overflow counters and accept queue gauges, aggregated by port
+// across network namespaces
+func (t *tracer) refreshListenOverflowTelemetry() {
+	type portStats struct {
+		synBacklogDrops  uint64
+		acceptQueueDrops uint64
+		acceptQueueLen   uint64
+		acceptQueueMax   uint64
+	}
+
+	byPort := make(map[uint16]*portStats)
+	current := make(map[netebpf.PortBinding]netebpf.ListenOverflowStats, len(t.lastListenOverflows))
+
+	key, stats := netebpf.PortBinding{}, netebpf.ListenOverflowStats{}
+	entries := t.listenOverflows.Iterate()
+	for entries.Next(unsafe.Pointer(&key), unsafe.Pointer(&stats)) {
+		current[key] = stats
+
+		// entries are deleted when the socket stops listening, so a value lower than
+		// the previous one means the port was re-bound and counting restarted from 0
+		last := t.lastListenOverflows[key]
+		if last.Syn_backlog_drops > stats.Syn_backlog_drops || last.Accept_queue_drops > stats.Accept_queue_drops {
+			last = netebpf.ListenOverflowStats{}
+		}
+
+		ps, ok := byPort[key.Port]
+		if !ok {
+			ps = &portStats{}
+			byPort[key.Port] = ps
+		}
+		ps.synBacklogDrops += stats.Syn_backlog_drops - last.Syn_backlog_drops
+		ps.acceptQueueDrops += stats.Accept_queue_drops - last.Accept_queue_drops
+		ps.acceptQueueLen += uint64(stats.Accept_queue_len)
+		ps.acceptQueueMax += uint64(stats.Accept_queue_max)
+	}
+	if err := entries.Err(); err != nil {
+		log.Warnf("failed to iterate over %s map: %s", probes.TCPListenOverflowsMap, err)
+		return
+	}
+
+	for port, ps := range byPort {
+		p := strconv.Itoa(int(port))
+		ConnTracerTelemetry.listenSynBacklogDrops.Add(float64(ps.synBacklogDrops), p)
+		ConnTracerTelemetry.listenAcceptQueueDrops.Add(float64(ps.acceptQueueDrops), p)
+		ConnTracerTelemetry.listenAcceptQueueLen.Set(float64(ps.acceptQueueLen), p)
+		ConnTracerTelemetry.listenAcceptQueueMax.Set(float64(ps.acceptQueueMax), p)
+	}
+
+	// stop reporting gauges for ports that are no longer listening
+	for port := range t.listenOverflowPorts {
+		if _, ok := byPort[port]; !ok {
+			p := strconv.Itoa(int(port))
+			ConnTracerTelemetry.listenAcceptQueueLen.Delete(p)
+			ConnTracerTelemetry.listenAcceptQueueMax.Delete(p)
+			delete(t.listenOverflowPorts, port)
+		}
+	}
+	for port := range byPort {
+		t.listenOverflowPorts[port] = struct{}{}
+	}
+
+	t.lastListenOverflows = current
+}
+
+// GetTCPFailures returns the number of failed TCP connection attempts by destination since the tracer started
+func (t *tracer) GetTCPFailures() (map[network.TCPFailureKey]network.TCPFailureCounts, error) {
+	if t.tcpFailures == nil {
+		return nil, nil
+	}
+
+	failures := make(map[network.TCPFailureKey]network.TCPFailureCounts)
+	key, stats := netebpf.ConnTuple{}, netebpf.TCPFailureStats{}
+	entries := t.tcpFailures.Iterate()
+	for entries.Next(unsafe.Pointer(&key), unsafe.Pointer(&stats)) {
+		k := network.TCPFailureKey{
+			Dest:   key.DestAddress(),
+			DPort:  key.Dport,
+			NetNS:  key.Netns,
+			Family: network.AFINET,
+		}
+		if key.Family() == netebpf.IPv6 {
+			k.Family = network.AFINET6
+		}
+		failures[k] = network.TCPFailureCounts{
+			Refused:  stats.Refused,
+			TimedOut: stats.Timed_out,
+			Reset:    stats.Reset,
+		}
+	}
+	if err := entries.Err(); err != nil {
+		return nil, fmt.Errorf("unable to iterate over the %s map: %w", probes.TCPFailuresMap, err)
+	}
+	return failures, nil
 }
 
 // DumpMaps (for debugging purpose) returns all maps content by default or selected maps from maps parameter.
@@ -12,6 +12,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/netip"
 	"sync"
 	"time"
 
@@ -48,6 +49,13 @@ import (
 const defaultUDPConnTimeoutNanoSeconds = uint64(time.Duration(120) * time.Second)
 const tracerModuleName = "network_tracer"
 
+// correlationIndexRefreshInterval is the minimum time between two scans of the connections looking up the correlation
+// ID of a connection established since the last check
+const correlationIndexRefreshInterval = time.Second
+
+// errUSMDisabled is returned when universal service monitoring isn't running
+var errUSMDisabled = errors.New("universal service monitoring is not enabled")
+
 // Telemetry
 // Will track the count of expired TCP connections
 // We are manually expiring TCP connections because it seems that we are losing some TCP close events
@@ -86,6 +94,10 @@ type Tracer struct {
 
 	activeBuffer *network.ConnectionBuffer
 	bufferLock   sync.Mutex
+	// dnsServerStats holds the health stats of the DNS servers collected by the last check
+	dnsServerStats map[util.Address]*dns.ServerStats
+	// correlationIndex indexes the correlation IDs of the active connections, when connection correlation is enabled
+	correlationIndex *network.CorrelationIndex
 
 	// Connections for the tracer to exclude
 	sourceExcludes []*network.ConnectionFilter
@@ -198,6 +210,7 @@ func newTracer(cfg *config.Config) (*Tracer, error) {
 		cfg.MaxDNSStatsBuffered,
 		cfg.MaxHTTPStatsBuffered,
 		cfg.MaxKafkaStatsBuffered,
+		cfg.MaxUSMDNSStatsBuffered,
 	)
 
 	gwLookup := newGatewayLookup(cfg)
@@ -220,6 +233,7 @@ func newTracer(cfg *config.Config) (*Tracer, error) {
 		ebpfTracer:                 ebpfTracer,
 		bpfTelemetry:               bpfTelemetry,
 		lastCheck:                  atomic.NewInt64(time.Now().Unix()),
+		correlationIndex:           network.NewCorrelationIndex(correlationIndexRefreshInterval),
 		exitTelemetry:              make(chan struct{}),
 	}
 
@@ -248,6 +262,7 @@ func newTracer(cfg *config.Config) (*Tracer, error) {
 			select {
 			case <-ticker.C:
 				ddebpf.GetProbeStats()
+				tr.bpfTelemetry.RefreshTelemetry()
 			case <-tr.exitTelemetry:
 				return
 			}
@@ -281,11 +296,14 @@ func newConntracker(cfg *config.Config, bpfTelemetry *nettelemetry.EBPFTelemetry
 
 	var c netlink.Conntracker
 	var err error
-	if c, err = NewEBPFConntracker(cfg, bpfTelemetry, constants); err == nil {
+	if len(cfg.ConntrackPartialTrackingCIDRs) > 0 {
+		// partial tracking is only supported by the netlink conntracker
+		if c, err = netlink.NewConntracker(cfg); err == nil {
+			return c, nil
+		}
+	} else if c, err = NewEBPFConntracker(cfg, bpfTelemetry, constants); err == nil {
 		return c, nil
-	}
-
-	if cfg.AllowNetlinkConntrackerFallback {
+	} else if cfg.AllowNetlinkConntrackerFallback {
 		log.Warnf("error initializing ebpf conntracker, falling back to netlink version: %s", err)
 		if c, err = netlink.NewConntracker(cfg); err == nil {
 			return c, nil
@@ -365,6 +383,9 @@ func (t *Tracer) storeClosedConnections(connections []network.ConnectionStats) {
 		t.addProcessInfo(cs)
 	}
 
+	// the skipped connections are evicted as well, as they were monitored by USM regardless
+	t.usmMonitor.HandleClosedConnections(connections)
+
 	connections = connections[rejected:]
 	tracerTelemetry.closedConns.Add(int64(len(connections)))
 	tracerTelemetry.skippedConns.Add(float64(rejected))
@@ -408,6 +429,34 @@ func (t *Tracer) addProcessInfo(c *network.ConnectionStats) {
 	}
 }
 
+// GetCorrelationID returns the correlation ID of the active connection between laddr and raddr, as seen from the
+// local endpoint. pid is the process ID in the host PID namespace, 0 matches any process.
+func (t *Tracer) GetCorrelationID(pid uint32, laddr, raddr netip.AddrPort) (string, error) {
+	if id, found := t.correlationIndex.Lookup(pid, laddr, raddr); found {
+		return id, nil
+	}
+
+	// the connection may have been established since the index was built
+	now := time.Now()
+	if !t.correlationIndex.ShouldRefresh(now) {
+		return "", network.ErrConnectionNotFound
+	}
+	builder := make(network.CorrelationIndexBuilder)
+	err := t.ebpfTracer.GetConnections(network.NewConnectionBuffer(1, 1), func(c *network.ConnectionStats) bool {
+		builder.Add(c)
+		return false
+	})
+	if err != nil {
+		return "", err
+	}
+	t.correlationIndex.Update(builder, now)
+
+	if id, found := t.correlationIndex.Lookup(pid, laddr, raddr); found {
+		return id, nil
+	}
+	return "", network.ErrConnectionNotFound
+}
+
 // Stop stops the tracer
 func (t *Tracer) Stop() {
 	if t.gwLookup != nil {
@@ -434,7 +483,7 @@ func (t *Tracer) GetActiveConnections(clientID string) (*network.Connections, er
 	}
 	active := t.activeBuffer.Connections()
 
-	delta := t.state.GetDelta(clientID, latestTime, active, t.reverseDNS.GetDNSStats(), t.usmMonitor.GetHTTPStats(), t.usmMonitor.GetHTTP2Stats(), t.usmMonitor.GetKafkaStats())
+	delta := t.state.GetDelta(clientID, latestTime, active, t.reverseDNS.GetDNSStats(), t.usmMonitor.GetHTTPStats(), t.usmMonitor.GetHTTP2Stats(), t.usmMonitor.GetKafkaStats(), t.usmMonitor.GetDNSStats())
 	t.activeBuffer.Reset()
 
 	ips := make([]util.Address, 0, len(delta.Conns)*2)
@@ -442,7 +491,14 @@ func (t *Tracer) GetActiveConnections(clientID string) (*network.Connections, er
 		ips = append(ips, conn.Source, conn.Dest)
 	}
 	names := t.reverseDNS.Resolve(ips)
+	// the DNS servers stats are reset, and reported as telemetry, on the check cadence
+	t.dnsServerStats = t.reverseDNS.GetServerStats()
 	ctm := t.state.GetTelemetryDelta(clientID, t.getConnTelemetry(len(active)))
+	failures, err := t.ebpfTracer.GetTCPFailures()
+	if err != nil {
+		log.Warnf("error retrieving failed tcp connections: %s", err)
+	}
+	tcpFailures := t.state.GetTCPFailuresDelta(clientID, failures)
 	rctm := t.getRuntimeCompilationTelemetry()
 	khfr := int32(kernel.HeaderProvider.GetResult())
 	coretm := ddebpf.GetCORETelemetryByAsset()
@@ -456,11 +512,13 @@ func (t *Tracer) GetActiveConnections(clientID string) (*network.Connections, er
 		HTTP:                        delta.HTTP,
 		HTTP2:                       delta.HTTP2,
 		Kafka:                       delta.Kafka,
+		USMDNS:                      delta.USMDNS,
 		ConnTelemetry:               ctm,
 		KernelHeaderFetchResult:     khfr,
 		CompilationTelemetryByAsset: rctm,
 		CORETelemetryByAsset:        coretm,
 		PrebuiltAssets:              pbassets,
+		TCPFailures:                 tcpFailures,
 	}, nil
 }
 
@@ -566,6 +624,13 @@ func (t *Tracer) getConnections(activeBuffer *network.ConnectionBuffer) (latestU
 		t.connVia(&active[i])
 		t.addProcessInfo(&active[i])
 	}
+	if t.config.EnableConnectionCorrelation {
+		builder := make(network.CorrelationIndexBuilder, len(active))
+		for i := range active {
+			builder.Add(&active[i])
+		}
+		t.correlationIndex.Update(builder, time.Now())
+	}
 
 	entryCount := len(active)
 	if entryCount >= int(t.config.MaxTrackedConnections) {
@@ -603,6 +668,11 @@ func (t *Tracer) removeEntries(entries []network.ConnectionStats) {
 			continue
 		}
 
+		// USM evicts the state of the translated tuple as well, so the translation is looked up before being deleted
+		if t.usmMonitor != nil && entry.Type == network.TCP {
+			entry.IPTranslation = t.conntracker.GetTranslationForConn(*entry)
+		}
+
 		// Delete conntrack entry for this connection
 		t.conntracker.DeleteTranslation(*entry)
 
@@ -611,6 +681,7 @@ func (t *Tracer) removeEntries(entries []network.ConnectionStats) {
 	}
 
 	t.state.RemoveConnections(toRemove)
+	t.usmMonitor.HandleExpiredConnections(entries)
 
 	log.Debugf("Removed %d connection entries in %s", len(toRemove), time.Now().Sub(now))
 }
@@ -659,6 +730,8 @@ var allStats = []statsComp{
 	stateStats,
 	tracerStats,
 	httpStats,
+	bpfMapStats,
+	bpfHelperStats,
 }
 
 func (t *Tracer) getStats(comps ...statsComp) (map[string]interface{}, error) {
@@ -679,9 +752,14 @@ func (t *Tracer) getStats(comps ...statsComp) (map[string]interface{}, error) {
 			tracerStats := make(map[string]interface{})
 			tracerStats["last_check"] = t.lastCheck.Load()
 			tracerStats["runtime"] = runtime.Tracer.GetTelemetry()
+			tracerStats["core_load_diagnostics"] = ddebpf.GetLoadDiagnosticsByAsset()
 			ret["tracer"] = tracerStats
 		case httpStats:
 			ret["universal_service_monitoring"] = t.usmMonitor.GetUSMStats()
+		case bpfMapStats:
+			ret["ebpf_map_errors"] = t.bpfTelemetry.GetMapsTelemetry()
+		case bpfHelperStats:
+			ret["ebpf_helper_errors"] = t.bpfTelemetry.GetHelperTelemetry()
 		}
 	}
 
@@ -721,6 +799,38 @@ func (t *Tracer) DebugNetworkMaps() (*network.Connections, error) {
 
 }
 
+// SetUSMProtocolEnabled enables or disables the monitoring of a USM protocol at runtime
+func (t *Tracer) SetUSMProtocolEnabled(protocol string, enabled bool) error {
+	if t.usmMonitor == nil {
+		return errUSMDisabled
+	}
+	return t.usmMonitor.SetProtocolEnabled(protocol, enabled)
+}
+
+// GetUSMProtocols returns whether the monitoring of each USM protocol enabled at startup is currently enabled
+func (t *Tracer) GetUSMProtocols() (map[string]bool, error) {
+	if t.usmMonitor == nil {
+		return nil, errUSMDisabled
+	}
+	return t.usmMonitor.GetProtocols(), nil
+}
+
+// GetDNSServerStats returns the health stats of the DNS servers seen by the DNS snooper during the interval
+// preceding the last check
+func (t *Tracer) GetDNSServerStats() (map[util.Address]*dns.ServerStats, error) {
+	t.bufferLock.Lock()
+	defer t.bufferLock.Unlock()
+	return t.dnsServerStats, nil
+}
+
+// DebugUSMInFlightTransactions returns the HTTP and HTTP/2 transactions held by the in-flight eBPF maps
+func (t *Tracer) DebugUSMInFlightTransactions() (interface{}, error) {
+	if t.usmMonitor == nil {
+		return nil, errUSMDisabled
+	}
+	return t.usmMonitor.DumpInFlightTransactions()
+}
+
 // DebugEBPFMaps returns all maps registered in the eBPF manager
 func (t *Tracer) DebugEBPFMaps(maps ...string) (string, error) {
 	tracerMaps, err := t.ebpfTracer.DumpMaps(maps...)
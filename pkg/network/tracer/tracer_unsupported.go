@@ -10,10 +10,13 @@ package tracer
 
 import (
 	"context"
+	"net/netip"
 
 	"github.com/DataDog/datadog-agent/pkg/ebpf"
 	"github.com/DataDog/datadog-agent/pkg/network"
 	"github.com/DataDog/datadog-agent/pkg/network/config"
+	"github.com/DataDog/datadog-agent/pkg/network/dns"
+	"github.com/DataDog/datadog-agent/pkg/process/util"
 )
 
 // Tracer is not implemented
@@ -42,6 +45,11 @@ func (t *Tracer) GetStats() (map[string]interface{}, error) {
 	return nil, ebpf.ErrNotImplemented
 }
 
+// GetCorrelationID is not implemented on this OS for Tracer
+func (t *Tracer) GetCorrelationID(_ uint32, _, _ netip.AddrPort) (string, error) {
+	return "", ebpf.ErrNotImplemented
+}
+
 // DebugNetworkState is not implemented on this OS for Tracer
 func (t *Tracer) DebugNetworkState(clientID string) (map[string]interface{}, error) {
 	return nil, ebpf.ErrNotImplemented
@@ -52,6 +60,26 @@ func (t *Tracer) DebugNetworkMaps() (*network.Connections, error) {
 	return nil, ebpf.ErrNotImplemented
 }
 
+// SetUSMProtocolEnabled is not implemented on this OS for Tracer
+func (t *Tracer) SetUSMProtocolEnabled(_ string, _ bool) error {
+	return ebpf.ErrNotImplemented
+}
+
+// GetUSMProtocols is not implemented on this OS for Tracer
+func (t *Tracer) GetUSMProtocols() (map[string]bool, error) {
+	return nil, ebpf.ErrNotImplemented
+}
+
+// GetDNSServerStats is not implemented on this OS for Tracer
+func (t *Tracer) GetDNSServerStats() (map[util.Address]*dns.ServerStats, error) {
+	return nil, ebpf.ErrNotImplemented
+}
+
+// DebugUSMInFlightTransactions is not implemented on this OS for Tracer
+func (t *Tracer) DebugUSMInFlightTransactions() (interface{}, error) {
+	return nil, ebpf.ErrNotImplemented
+}
+
 // DebugEBPFMaps is not implemented on this OS for Tracer
 func (t *Tracer) DebugEBPFMaps(maps ...string) (string, error) {
 	return "", ebpf.ErrNotImplemented
@@ -26,12 +26,15 @@ import (
 	"syscall"
 	"testing"
 	"time"
+	"unsafe"
 
 	"github.com/stretchr/testify/require"
 	"golang.org/x/sys/unix"
 
 	"github.com/DataDog/datadog-agent/pkg/network"
 	"github.com/DataDog/datadog-agent/pkg/network/config"
+	netebpf "github.com/DataDog/datadog-agent/pkg/network/ebpf"
+	"github.com/DataDog/datadog-agent/pkg/network/ebpf/probes"
 	javatestutil "github.com/DataDog/datadog-agent/pkg/network/java/testutil"
 	netlink "github.com/DataDog/datadog-agent/pkg/network/netlink/testutil"
 	"github.com/DataDog/datadog-agent/pkg/network/protocols"
@@ -473,6 +476,58 @@ func TestOpenSSLVersionsSlowStart(t *testing.T) {
 	}
 }
 
+// TestOpenSSLAlpine checks the TLS traffic of the processes of the musl based distributions is captured, with an
+// nginx server running on Alpine
+func TestOpenSSLAlpine(t *testing.T) {
+	if !httpsSupported() {
+		t.Skip("HTTPS feature not available/supported for this setup")
+	}
+
+	cfg := testConfig()
+	cfg.EnableHTTPSMonitoring = true
+	cfg.EnableHTTPMonitoring = true
+	tr := setupTracer(t, cfg)
+
+	const serverPort = "8443"
+	require.NoError(t, prototls.RunNginxAlpine(t, serverPort))
+	// Giving the tracer time to install the hooks
+	time.Sleep(time.Second)
+
+	client := &nethttp.Client{
+		Transport: &nethttp.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+	}
+	var requests []*nethttp.Request
+	for i := 0; i < 10; i++ {
+		req, err := nethttp.NewRequest(nethttp.MethodGet, fmt.Sprintf("https://localhost:%s/200/request-%d", serverPort, i), nil)
+		require.NoError(t, err)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		require.Equal(t, nethttp.StatusOK, resp.StatusCode)
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		requests = append(requests, req)
+	}
+	client.CloseIdleConnections()
+
+	requestsExist := make([]bool, len(requests))
+	require.Eventually(t, func() bool {
+		conns := getConnections(t, tr)
+		for reqIndex, req := range requests {
+			if !requestsExist[reqIndex] {
+				requestsExist[reqIndex] = isRequestIncluded(conns.HTTP, req)
+			}
+		}
+		for _, exists := range requestsExist {
+			if !exists {
+				return false
+			}
+		}
+		return true
+	}, 3*time.Second, time.Second, "the requests to nginx on Alpine were not captured")
+}
+
 var (
 	statusCodes = []int{nethttp.StatusOK, nethttp.StatusMultipleChoices, nethttp.StatusBadRequest, nethttp.StatusInternalServerError}
 )
@@ -554,6 +609,130 @@ func TestProtocolClassification(t *testing.T) {
 	})
 }
 
+// quicInitialPacket returns a client Initial packet of QUIC version 1, padded
+// to the minimum size of the datagrams sent by clients.
+func quicInitialPacket() []byte {
+	packet := []byte{
+		0xc3,                   // long header, fixed bit, Initial packet, 4 bytes packet number
+		0x00, 0x00, 0x00, 0x01, // version 1
+		0x08, 1, 2, 3, 4, 5, 6, 7, 8, // destination connection ID
+		0x00,       // source connection ID length
+		0x00,       // token length
+		0x44, 0x9e, // length of the remainder of the packet: 1182
+	}
+	return append(packet, make([]byte, 1200-len(packet))...)
+}
+
+func TestQUICProtocolClassification(t *testing.T) {
+	cfg := testConfig()
+	if !classificationSupported(cfg) {
+		t.Skip("Classification is not supported")
+	}
+
+	cfg.EnableHTTP3Monitoring = true
+	tr, err := NewTracer(cfg)
+	require.NoError(t, err)
+	t.Cleanup(tr.Stop)
+
+	tests := []struct {
+		name     string
+		payloads [][]byte
+		expected protocols.Stack
+	}{
+		{
+			name:     "quic",
+			payloads: [][]byte{quicInitialPacket(), quicInitialPacket()},
+			expected: protocols.Stack{Encryption: protocols.QUIC},
+		},
+		{
+			name:     "quic after other datagrams",
+			payloads: [][]byte{[]byte("not a quic packet"), quicInitialPacket()},
+			expected: protocols.Stack{Encryption: protocols.QUIC},
+		},
+		{
+			name:     "not quic",
+			payloads: [][]byte{[]byte("not a quic packet"), []byte("still not a quic packet")},
+			expected: protocols.Stack{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			initTracerState(t, tr)
+
+			server, err := net.ListenPacket("udp", "127.0.0.1:0")
+			require.NoError(t, err)
+			t.Cleanup(func() { server.Close() })
+			go func() {
+				buf := make([]byte, 1500)
+				for {
+					n, addr, err := server.ReadFrom(buf)
+					if err != nil {
+						return
+					}
+					_, _ = server.WriteTo(buf[:n], addr)
+				}
+			}()
+
+			client, err := net.Dial("udp", server.LocalAddr().String())
+			require.NoError(t, err)
+			t.Cleanup(func() { client.Close() })
+
+			buf := make([]byte, 1500)
+			for _, payload := range tt.payloads {
+				_, err = client.Write(payload)
+				require.NoError(t, err)
+				require.NoError(t, client.SetReadDeadline(time.Now().Add(time.Second)))
+				_, err = client.Read(buf)
+				require.NoError(t, err)
+			}
+
+			require.Eventually(t, func() bool {
+				conns := searchConnections(getConnections(t, tr), func(cs network.ConnectionStats) bool {
+					return cs.Type == network.UDP && fmt.Sprintf("%s:%d", cs.Dest, cs.DPort) == server.LocalAddr().String()
+				})
+				for _, c := range conns {
+					if c.ProtocolStack != tt.expected {
+						return false
+					}
+				}
+				return len(conns) > 0
+			}, 3*time.Second, 100*time.Millisecond, "could not find a UDP connection with the protocol stack %+v", tt.expected)
+
+			// Only the QUIC flows have a protocol stack, which is deleted once the client socket is destroyed
+			serverPort := uint16(server.LocalAddr().(*net.UDPAddr).Port)
+			if tt.expected.Encryption == protocols.QUIC {
+				require.Equal(t, 1, udpConnectionProtocolEntries(t, tr, serverPort))
+			} else {
+				require.Zero(t, udpConnectionProtocolEntries(t, tr, serverPort))
+			}
+			require.NoError(t, client.Close())
+			require.Eventually(t, func() bool {
+				return udpConnectionProtocolEntries(t, tr, serverPort) == 0
+			}, 3*time.Second, 100*time.Millisecond, "the protocol stack of the UDP flow wasn't deleted")
+		})
+	}
+}
+
+// udpConnectionProtocolEntries returns the number of entries of the connection_protocol map for the UDP flows
+// from or to the given port.
+func udpConnectionProtocolEntries(t *testing.T, tr *Tracer, port uint16) int {
+	connectionProtocolMap := tr.ebpfTracer.GetMap(probes.ConnectionProtocolMap)
+	require.NotNil(t, connectionProtocolMap)
+
+	entries := 0
+	var key netebpf.ConnTuple
+	var stack netebpf.ProtocolStack
+	iter := connectionProtocolMap.Iterate()
+	for iter.Next(unsafe.Pointer(&key), unsafe.Pointer(&stack)) {
+		if key.Type() == netebpf.UDP && (key.Sport == port || key.Dport == port) {
+			entries++
+		}
+	}
+	require.NoError(t, iter.Err())
+	return entries
+}
+
 func testProtocolConnectionProtocolMapCleanup(t *testing.T, tr *Tracer, clientHost, targetHost, serverHost string) {
 	t.Run("protocol cleanup", func(t *testing.T) {
 		if tr.ebpfTracer.Type() == connection.TracerTypeFentry {
@@ -12,6 +12,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/netip"
 	"runtime"
 	"sync"
 	"syscall"
@@ -47,6 +48,8 @@ type Tracer struct {
 	activeBuffer *network.ConnectionBuffer
 	closedBuffer *network.ConnectionBuffer
 	connLock     sync.Mutex
+	// dnsServerStats holds the health stats of the DNS servers collected by the last check
+	dnsServerStats map[util.Address]*dns.ServerStats
 
 	timerInterval int
 
@@ -79,6 +82,7 @@ func NewTracer(config *config.Config) (*Tracer, error) {
 		config.MaxDNSStatsBuffered,
 		config.MaxHTTPStatsBuffered,
 		config.MaxKafkaStatsBuffered,
+		config.MaxUSMDNSStatsBuffered,
 	)
 
 	reverseDNS := dns.NewNullReverseDNS()
@@ -176,9 +180,9 @@ func (t *Tracer) GetActiveConnections(clientID string) (*network.Connections, er
 
 	var delta network.Delta
 	if t.usmMonitor != nil { //nolint
-		delta = t.state.GetDelta(clientID, uint64(time.Now().Nanosecond()), activeConnStats, t.reverseDNS.GetDNSStats(), t.usmMonitor.GetHTTPStats(), nil, nil)
+		delta = t.state.GetDelta(clientID, uint64(time.Now().Nanosecond()), activeConnStats, t.reverseDNS.GetDNSStats(), t.usmMonitor.GetHTTPStats(), nil, nil, nil)
 	} else {
-		delta = t.state.GetDelta(clientID, uint64(time.Now().Nanosecond()), activeConnStats, t.reverseDNS.GetDNSStats(), nil, nil, nil)
+		delta = t.state.GetDelta(clientID, uint64(time.Now().Nanosecond()), activeConnStats, t.reverseDNS.GetDNSStats(), nil, nil, nil, nil)
 	}
 
 	t.activeBuffer.Reset()
@@ -189,6 +193,8 @@ func (t *Tracer) GetActiveConnections(clientID string) (*network.Connections, er
 		ips = append(ips, conn.Source, conn.Dest)
 	}
 	names := t.reverseDNS.Resolve(ips)
+	// the DNS servers stats are reset, and reported as telemetry, on the check cadence
+	t.dnsServerStats = t.reverseDNS.GetServerStats()
 	telemetryDelta := t.state.GetTelemetryDelta(clientID, t.getConnTelemetry())
 	return &network.Connections{
 		BufferedData:  delta.BufferedData,
@@ -219,6 +225,11 @@ func (t *Tracer) GetStats() (map[string]interface{}, error) {
 	return stats, nil
 }
 
+// GetCorrelationID is not implemented on this OS for Tracer
+func (t *Tracer) GetCorrelationID(_ uint32, _, _ netip.AddrPort) (string, error) {
+	return "", ebpf.ErrNotImplemented
+}
+
 // DebugNetworkState returns a map with the current tracer's internal state, for debugging
 func (t *Tracer) DebugNetworkState(_ string) (map[string]interface{}, error) {
 	return nil, ebpf.ErrNotImplemented
@@ -229,6 +240,29 @@ func (t *Tracer) DebugNetworkMaps() (*network.Connections, error) {
 	return nil, ebpf.ErrNotImplemented
 }
 
+// SetUSMProtocolEnabled is not implemented on this OS for Tracer
+func (t *Tracer) SetUSMProtocolEnabled(_ string, _ bool) error {
+	return ebpf.ErrNotImplemented
+}
+
+// GetUSMProtocols is not implemented on this OS for Tracer
+func (t *Tracer) GetUSMProtocols() (map[string]bool, error) {
+	return nil, ebpf.ErrNotImplemented
+}
+
+// GetDNSServerStats returns the health stats of the DNS servers seen by the DNS snooper during the interval
+// preceding the last check
+func (t *Tracer) GetDNSServerStats() (map[util.Address]*dns.ServerStats, error) {
+	t.connLock.Lock()
+	defer t.connLock.Unlock()
+	return t.dnsServerStats, nil
+}
+
+// DebugUSMInFlightTransactions is not implemented on this OS for Tracer
+func (t *Tracer) DebugUSMInFlightTransactions() (interface{}, error) {
+	return nil, ebpf.ErrNotImplemented
+}
+
 // DebugEBPFMaps is not implemented on this OS for Tracer
 func (t *Tracer) DebugEBPFMaps(maps ...string) (string, error) {
 	return "", ebpf.ErrNotImplemented
@@ -0,0 +1,229 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux_bpf
+// +build linux_bpf
+
+package usm
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cilium/ebpf"
+	"github.com/cilium/ebpf/link"
+
+	manager "github.com/DataDog/ebpf-manager"
+
+	"github.com/DataDog/datadog-agent/pkg/network/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	// cgroup_skb programs of the cgroup attach mode, recording the tuples of the selected workloads
+	cgroupAttachIngressFunction = "cgroup_skb__usm_ingress"
+	cgroupAttachEgressFunction  = "cgroup_skb__usm_egress"
+
+	// map holding the tuples of the connections of the selected workloads
+	usmCgroupTuplesMap = "usm_cgroup_tuples"
+
+	// cgroupAttachRescanInterval is the interval between two matches of the cgroups, which attaches the programs
+	// to the cgroups of the workloads started since the previous match
+	cgroupAttachRescanInterval = 30 * time.Second
+)
+
+var cgroupAttachFunctions = []string{cgroupAttachIngressFunction, cgroupAttachEgressFunction}
+
+// cgroupAttacher attaches the cgroup_skb programs of the cgroup attach mode to the cgroups matching the patterns
+// of the configuration. The cgroups are matched periodically, so that the programs are attached to the cgroups of
+// the new workloads, and detached from the cgroups which were removed.
+type cgroupAttacher struct {
+	root     string
+	patterns []string
+	programs map[ebpf.AttachType]*ebpf.Program
+
+	mux   sync.Mutex
+	links map[string][]link.Link
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+func newCgroupAttacher(c *config.Config, mgr *manager.Manager) (*cgroupAttacher, error) {
+	root, err := findCgroup2Root(c.ProcRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	programs := make(map[ebpf.AttachType]*ebpf.Program, len(cgroupAttachFunctions))
+	for attachType, funcName := range map[ebpf.AttachType]string{
+		ebpf.AttachCGroupInetIngress: cgroupAttachIngressFunction,
+		ebpf.AttachCGroupInetEgress:  cgroupAttachEgressFunction,
+	} {
+		progs, found, err := mgr.GetProgram(manager.ProbeIdentificationPair{EBPFFuncName: funcName})
+		if err != nil || !found || len(progs) == 0 {
+			return nil, fmt.Errorf("could not find program %s: %w", funcName, err)
+		}
+		programs[attachType] = progs[0]
+	}
+
+	if len(c.USMCgroupAttachPaths) == 0 {
+		log.Warnf("usm cgroup attach mode enabled without any cgroup path, no traffic will be monitored")
+	}
+
+	return &cgroupAttacher{
+		root:     root,
+		patterns: c.USMCgroupAttachPaths,
+		programs: programs,
+		links:    make(map[string][]link.Link),
+		done:     make(chan struct{}),
+	}, nil
+}
+
+// Start attaches the programs to the cgroups matching the patterns, then matches them again periodically
+func (a *cgroupAttacher) Start() {
+	a.sync()
+
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		ticker := time.NewTicker(cgroupAttachRescanInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				a.sync()
+			case <-a.done:
+				return
+			}
+		}
+	}()
+}
+
+// Stop detaches the programs from all the cgroups
+func (a *cgroupAttacher) Stop() {
+	close(a.done)
+	a.wg.Wait()
+
+	a.mux.Lock()
+	defer a.mux.Unlock()
+	for path, links := range a.links {
+		closeLinks(links)
+		delete(a.links, path)
+	}
+}
+
+// sync attaches the programs to the matched cgroups they aren't attached to yet, and detaches them from the
+// cgroups which don't match anymore
+func (a *cgroupAttacher) sync() {
+	matched, err := matchCgroups(a.root, a.patterns)
+	if err != nil {
+		log.Warnf("error matching the cgroups of the usm cgroup attach mode: %s", err)
+		return
+	}
+
+	a.mux.Lock()
+	defer a.mux.Unlock()
+	for path, links := range a.links {
+		if _, ok := matched[path]; !ok {
+			log.Debugf("detaching usm programs from cgroup %s", path)
+			closeLinks(links)
+			delete(a.links, path)
+		}
+	}
+
+	for path := range matched {
+		if _, ok := a.links[path]; ok {
+			continue
+		}
+		links, err := a.attach(path)
+		if err != nil {
+			log.Warnf("could not attach usm programs to cgroup %s: %s", path, err)
+			continue
+		}
+		log.Debugf("attached usm programs to cgroup %s", path)
+		a.links[path] = links
+	}
+}
+
+func (a *cgroupAttacher) attach(path string) ([]link.Link, error) {
+	links := make([]link.Link, 0, len(a.programs))
+	for attachType, program := range a.programs {
+		l, err := link.AttachCgroup(link.CgroupOptions{
+			Path:    path,
+			Attach:  attachType,
+			Program: program,
+		})
+		if err != nil {
+			closeLinks(links)
+			return nil, err
+		}
+		links = append(links, l)
+	}
+	return links, nil
+}
+
+func closeLinks(links []link.Link) {
+	for _, l := range links {
+		if err := l.Close(); err != nil {
+			log.Debugf("error detaching usm program from cgroup: %s", err)
+		}
+	}
+}
+
+// matchCgroups returns the cgroup directories matching the patterns, which are glob patterns relative to the
+// root of the cgroup v2 hierarchy
+func matchCgroups(root string, patterns []string) (map[string]struct{}, error) {
+	matched := make(map[string]struct{})
+	for _, pattern := range patterns {
+		paths, err := filepath.Glob(filepath.Join(root, pattern))
+		if err != nil {
+			return nil, fmt.Errorf("invalid cgroup pattern %q: %w", pattern, err)
+		}
+		for _, path := range paths {
+			if info, err := os.Stat(path); err == nil && info.IsDir() {
+				matched[path] = struct{}{}
+			}
+		}
+	}
+	return matched, nil
+}
+
+// findCgroup2Root returns the mount point of the cgroup v2 hierarchy, which the cgroup_skb programs require.
+// On the hybrid hosts, it is mounted alongside the cgroup v1 hierarchies.
+func findCgroup2Root(procRoot string) (string, error) {
+	hostPrefix := ""
+	if strings.HasPrefix(procRoot, "/host") {
+		hostPrefix = "/host"
+	}
+
+	f, err := os.Open(filepath.Join(procRoot, "self", "mounts"))
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 || fields[2] != "cgroup2" {
+			continue
+		}
+		// mounts are duplicated when /sys/fs is mounted inside a container (like /host/sys/fs)
+		if strings.HasPrefix(fields[1], hostPrefix) {
+			return fields[1], nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", errors.New("the cgroup v2 hierarchy isn't mounted")
+}
@@ -0,0 +1,70 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux_bpf
+// +build linux_bpf
+
+package usm
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchCgroups(t *testing.T) {
+	root := t.TempDir()
+	podA := filepath.Join(root, "kubepods.slice", "kubepods-besteffort.slice", "kubepods-besteffort-poda.slice")
+	podB := filepath.Join(root, "kubepods.slice", "kubepods-burstable.slice", "kubepods-burstable-podb.slice")
+	service := filepath.Join(root, "system.slice", "nginx.service")
+	for _, path := range []string{podA, podB, service} {
+		require.NoError(t, os.MkdirAll(path, 0755))
+	}
+	// only the directories are cgroups
+	require.NoError(t, os.WriteFile(filepath.Join(root, "system.slice", "cgroup.procs"), nil, 0644))
+
+	matched, err := matchCgroups(root, []string{"kubepods.slice/*/kubepods-*-poda.slice", "system.slice/*"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]struct{}{podA: {}, service: {}}, matched)
+
+	matched, err = matchCgroups(root, []string{"kubepods.slice/*/*"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]struct{}{podA: {}, podB: {}}, matched)
+
+	_, err = matchCgroups(root, []string{"[kubepods.slice"})
+	assert.Error(t, err)
+}
+
+func TestFindCgroup2Root(t *testing.T) {
+	writeMounts := func(t *testing.T, mounts string) string {
+		procRoot := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(procRoot, "self"), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(procRoot, "self", "mounts"), []byte(mounts), 0644))
+		return procRoot
+	}
+
+	t.Run("cgroup v2", func(t *testing.T) {
+		procRoot := writeMounts(t, "proc /proc proc rw 0 0\ncgroup2 /sys/fs/cgroup cgroup2 rw,nosuid 0 0\n")
+		root, err := findCgroup2Root(procRoot)
+		require.NoError(t, err)
+		assert.Equal(t, "/sys/fs/cgroup", root)
+	})
+
+	t.Run("hybrid", func(t *testing.T) {
+		procRoot := writeMounts(t, "cgroup /sys/fs/cgroup/memory cgroup rw,memory 0 0\ncgroup2 /sys/fs/cgroup/unified cgroup2 rw 0 0\n")
+		root, err := findCgroup2Root(procRoot)
+		require.NoError(t, err)
+		assert.Equal(t, "/sys/fs/cgroup/unified", root)
+	})
+
+	t.Run("cgroup v1", func(t *testing.T) {
+		procRoot := writeMounts(t, "cgroup /sys/fs/cgroup/memory cgroup rw,memory 0 0\n")
+		_, err := findCgroup2Root(procRoot)
+		assert.Error(t, err)
+	})
+}
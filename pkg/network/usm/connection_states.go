@@ -0,0 +1,159 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux_bpf
+// +build linux_bpf
+
+package usm
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"unsafe"
+
+	"github.com/cilium/ebpf"
+
+	manager "github.com/DataDog/ebpf-manager"
+
+	"github.com/DataDog/datadog-agent/pkg/network"
+	netebpf "github.com/DataDog/datadog-agent/pkg/network/ebpf"
+	libtelemetry "github.com/DataDog/datadog-agent/pkg/network/protocols/telemetry"
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// connectionStatesEvictor deletes the entries of the connection_states map as soon as the tracer reports their
+// connection as closed or expired, instead of leaving them until the map is full. Otherwise, the stale sequence
+// number of a closed connection would cause the first segment of a new connection reusing its tuple to be skipped.
+type connectionStatesEvictor struct {
+	states *ebpf.Map
+
+	mux sync.Mutex
+	// keys is reused across the evictions to build the batches of keys to delete
+	keys []netebpf.ConnTuple
+	// batchUnsupported is set once the kernel rejected a batch deletion, the keys being deleted one by one from then
+	batchUnsupported bool
+
+	evictedClosed  *libtelemetry.Metric
+	evictedExpired *libtelemetry.Metric
+}
+
+func newConnectionStatesEvictor(mgr *manager.Manager) (*connectionStatesEvictor, error) {
+	states, _, err := mgr.GetMap(connectionStatesMap)
+	if err != nil {
+		return nil, fmt.Errorf("could not find map %s: %w", connectionStatesMap, err)
+	}
+
+	metricGroup := libtelemetry.NewMetricGroup(
+		"usm.connection_states",
+		libtelemetry.OptExpvar,
+		libtelemetry.OptMonotonic,
+	)
+
+	return &connectionStatesEvictor{
+		states:         states,
+		evictedClosed:  metricGroup.NewMetric("evicted_closed"),
+		evictedExpired: metricGroup.NewMetric("evicted_expired"),
+	}, nil
+}
+
+// evictClosed deletes the entries of the connections closed by the tracer
+func (e *connectionStatesEvictor) evictClosed(conns []network.ConnectionStats) {
+	e.evict(conns, e.evictedClosed)
+}
+
+// evictExpired deletes the entries of the connections expired by the tracer, such as the idle connections whose
+// close wasn't captured
+func (e *connectionStatesEvictor) evictExpired(conns []network.ConnectionStats) {
+	e.evict(conns, e.evictedExpired)
+}
+
+// evict deletes the entries of the given connections in as few syscalls as possible
+func (e *connectionStatesEvictor) evict(conns []network.ConnectionStats, counter *libtelemetry.Metric) {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+
+	e.keys = e.keys[:0]
+	for i := range conns {
+		e.keys = appendConnectionStatesKeys(e.keys, &conns[i])
+	}
+	if len(e.keys) == 0 {
+		return
+	}
+
+	counter.Add(int64(e.delete(e.keys)))
+}
+
+// appendConnectionStatesKeys appends the keys under which the packets of a TCP connection may have been recorded.
+// The map is keyed by the tuple of the packets, which isn't normalized, so both directions are deleted. The packets
+// of a NAT'd connection are seen with the translated tuple as well, depending on the interface they go through.
+func appendConnectionStatesKeys(keys []netebpf.ConnTuple, conn *network.ConnectionStats) []netebpf.ConnTuple {
+	if conn.Type != network.TCP {
+		return keys
+	}
+
+	metadata := uint32(netebpf.TCP)
+	if conn.Family == network.AFINET6 {
+		metadata |= uint32(netebpf.IPv6)
+	} else {
+		metadata |= uint32(netebpf.IPv4)
+	}
+
+	keys = appendBothDirections(keys, conn.Source, conn.Dest, conn.SPort, conn.DPort, metadata)
+	if t := conn.IPTranslation; t != nil {
+		keys = appendBothDirections(keys, t.ReplSrcIP, t.ReplDstIP, t.ReplSrcPort, t.ReplDstPort, metadata)
+	}
+	return keys
+}
+
+func appendBothDirections(keys []netebpf.ConnTuple, saddr, daddr util.Address, sport, dport uint16, metadata uint32) []netebpf.ConnTuple {
+	key := netebpf.ConnTuple{
+		Sport:    sport,
+		Dport:    dport,
+		Metadata: metadata,
+	}
+	key.Saddr_l, key.Saddr_h = util.ToLowHigh(saddr)
+	key.Daddr_l, key.Daddr_h = util.ToLowHigh(daddr)
+	keys = append(keys, key)
+
+	key.Sport, key.Dport = key.Dport, key.Sport
+	key.Saddr_l, key.Daddr_l = key.Daddr_l, key.Saddr_l
+	key.Saddr_h, key.Daddr_h = key.Daddr_h, key.Saddr_h
+	return append(keys, key)
+}
+
+// delete deletes the given keys, returning the number of entries which were actually deleted
+func (e *connectionStatesEvictor) delete(keys []netebpf.ConnTuple) int {
+	deleted := 0
+	for len(keys) > 0 && !e.batchUnsupported {
+		n, err := e.states.BatchDelete(keys, nil)
+		deleted += n
+		if err == nil {
+			return deleted
+		}
+		if errors.Is(err, ebpf.ErrNotSupported) {
+			log.Debugf("batch deletions aren't supported for %s, deleting its entries one by one: %s", connectionStatesMap, err)
+			e.batchUnsupported = true
+			keys = keys[n:]
+			break
+		}
+		if !errors.Is(err, ebpf.ErrKeyNotExist) {
+			log.Debugf("could not delete %s from %s: %s", keys[n], connectionStatesMap, err)
+		}
+		// the kernel stops the batch at the first key which couldn't be deleted, so the batch resumes after it
+		keys = keys[n+1:]
+	}
+
+	for i := range keys {
+		err := e.states.Delete(unsafe.Pointer(&keys[i]))
+		if err == nil {
+			deleted++
+		} else if !errors.Is(err, ebpf.ErrKeyNotExist) {
+			log.Debugf("could not delete %s from %s: %s", keys[i], connectionStatesMap, err)
+		}
+	}
+	return deleted
+}
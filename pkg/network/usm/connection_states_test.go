@@ -0,0 +1,143 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux_bpf
+// +build linux_bpf
+
+package usm
+
+import (
+	"testing"
+	"unsafe"
+
+	"github.com/cilium/ebpf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/network"
+	netebpf "github.com/DataDog/datadog-agent/pkg/network/ebpf"
+	libtelemetry "github.com/DataDog/datadog-agent/pkg/network/protocols/telemetry"
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+)
+
+func TestConnectionStatesEviction(t *testing.T) {
+	states, err := ebpf.NewMap(&ebpf.MapSpec{
+		Type:       ebpf.Hash,
+		KeySize:    uint32(unsafe.Sizeof(netebpf.ConnTuple{})),
+		ValueSize:  4,
+		MaxEntries: 16,
+	})
+	if err != nil {
+		t.Skipf("could not create the connection_states map: %s", err)
+	}
+	t.Cleanup(func() { states.Close() })
+
+	metricGroup := libtelemetry.NewMetricGroup("usm.connection_states.test")
+	evictor := &connectionStatesEvictor{
+		states:         states,
+		evictedClosed:  metricGroup.NewMetric("evicted_closed"),
+		evictedExpired: metricGroup.NewMetric("evicted_expired"),
+	}
+
+	conn := network.ConnectionStats{
+		Source: util.AddressFromString("10.0.0.1"),
+		Dest:   util.AddressFromString("10.0.0.2"),
+		SPort:  45678,
+		DPort:  80,
+		Type:   network.TCP,
+		Family: network.AFINET,
+		Pid:    1234,
+		NetNS:  5678,
+	}
+
+	// the entries are keyed by the tuples of the packets of both directions, without pid nor netns
+	request := netebpf.ConnTuple{Sport: 45678, Dport: 80, Metadata: uint32(netebpf.TCP) | uint32(netebpf.IPv4)}
+	request.Saddr_l, request.Saddr_h = util.ToLowHigh(conn.Source)
+	request.Daddr_l, request.Daddr_h = util.ToLowHigh(conn.Dest)
+	response := request
+	response.Sport, response.Dport = request.Dport, request.Sport
+	response.Saddr_l, response.Daddr_l = request.Daddr_l, request.Saddr_l
+	response.Saddr_h, response.Daddr_h = request.Daddr_h, request.Saddr_h
+
+	seq := uint32(42)
+	for _, key := range []netebpf.ConnTuple{request, response} {
+		key := key
+		require.NoError(t, states.Put(unsafe.Pointer(&key), unsafe.Pointer(&seq)))
+	}
+
+	evictor.evictClosed([]network.ConnectionStats{conn})
+	for _, key := range []netebpf.ConnTuple{request, response} {
+		key := key
+		assert.ErrorIs(t, states.Lookup(unsafe.Pointer(&key), unsafe.Pointer(&seq)), ebpf.ErrKeyNotExist)
+	}
+	assert.EqualValues(t, 2, evictor.evictedClosed.Get())
+
+	// the missing entries aren't counted
+	evictor.evictExpired([]network.ConnectionStats{conn})
+	assert.Zero(t, evictor.evictedExpired.Get())
+
+	// the udp connections have no entries
+	require.NoError(t, states.Put(unsafe.Pointer(&request), unsafe.Pointer(&seq)))
+	udp := conn
+	udp.Type = network.UDP
+	evictor.evictExpired([]network.ConnectionStats{udp})
+	assert.NoError(t, states.Lookup(unsafe.Pointer(&request), unsafe.Pointer(&seq)))
+}
+
+func TestConnectionStatesEvictionTranslated(t *testing.T) {
+	states, err := ebpf.NewMap(&ebpf.MapSpec{
+		Type:       ebpf.Hash,
+		KeySize:    uint32(unsafe.Sizeof(netebpf.ConnTuple{})),
+		ValueSize:  4,
+		MaxEntries: 16,
+	})
+	if err != nil {
+		t.Skipf("could not create the connection_states map: %s", err)
+	}
+	t.Cleanup(func() { states.Close() })
+
+	metricGroup := libtelemetry.NewMetricGroup("usm.connection_states.translated.test")
+	evictor := &connectionStatesEvictor{
+		states:         states,
+		evictedClosed:  metricGroup.NewMetric("evicted_closed"),
+		evictedExpired: metricGroup.NewMetric("evicted_expired"),
+	}
+
+	conn := network.ConnectionStats{
+		Source: util.AddressFromString("10.0.0.1"),
+		Dest:   util.AddressFromString("10.96.0.10"),
+		SPort:  45678,
+		DPort:  80,
+		Type:   network.TCP,
+		Family: network.AFINET,
+		IPTranslation: &network.IPTranslation{
+			ReplSrcIP:   util.AddressFromString("10.244.0.5"),
+			ReplDstIP:   util.AddressFromString("10.0.0.1"),
+			ReplSrcPort: 8080,
+			ReplDstPort: 45678,
+		},
+	}
+
+	keys := appendConnectionStatesKeys(nil, &conn)
+	require.Len(t, keys, 4)
+
+	// only the request and the translated response were recorded, so the batch has missing keys in between
+	seq := uint32(42)
+	for _, i := range []int{0, 2} {
+		require.NoError(t, states.Put(unsafe.Pointer(&keys[i]), unsafe.Pointer(&seq)))
+	}
+	other := conn
+	other.SPort = 45679
+	other.IPTranslation = nil
+	otherKeys := appendConnectionStatesKeys(nil, &other)
+	require.NoError(t, states.Put(unsafe.Pointer(&otherKeys[1]), unsafe.Pointer(&seq)))
+
+	evictor.evictClosed([]network.ConnectionStats{conn, other})
+	for _, key := range append(keys, otherKeys...) {
+		key := key
+		assert.ErrorIs(t, states.Lookup(unsafe.Pointer(&key), unsafe.Pointer(&seq)), ebpf.ErrKeyNotExist)
+	}
+	assert.EqualValues(t, 3, evictor.evictedClosed.Get())
+}
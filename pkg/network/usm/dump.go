@@ -9,6 +9,7 @@
 package usm
 
 import (
+	"fmt"
 	"strings"
 	"unsafe"
 
@@ -18,17 +19,57 @@ import (
 
 	ddebpf "github.com/DataDog/datadog-agent/pkg/network/ebpf"
 	"github.com/DataDog/datadog-agent/pkg/network/protocols/http"
+	httpdebugging "github.com/DataDog/datadog-agent/pkg/network/protocols/http/debugging"
 )
 
 func dumpMapsHandler(manager *manager.Manager, mapName string, currentMap *ebpf.Map) string {
 	var output strings.Builder
 
 	switch mapName {
-	case httpInFlightMap: // maps/http_in_flight (BPF_MAP_TYPE_HASH), key ConnTuple, value httpTX
-		output.WriteString("Map: '" + mapName + "', key: 'ConnTuple', value: 'httpTX'\n")
+	case httpInFlightMap: // maps/http_in_flight (BPF_MAP_TYPE_HASH), key ConnTuple, value C.http_transaction_t
+		output.WriteString("Map: '" + mapName + "', key: 'ConnTuple', value: 'C.http_transaction_t'\n")
 		iter := currentMap.Iterate()
 		var key ddebpf.ConnTuple
-		var value http.HttpTX
+		var value http.EbpfHttpTx
+		for iter.Next(unsafe.Pointer(&key), unsafe.Pointer(&value)) {
+			output.WriteString(spew.Sdump(key, value))
+		}
+
+	case http2InFlightMap: // maps/http2_in_flight (BPF_MAP_TYPE_LRU_HASH), key C.http2_stream_key_t, value C.http2_stream_t
+		output.WriteString("Map: '" + mapName + "', key: 'C.http2_stream_key_t', value: 'C.http2_stream_t'\n")
+		iter := currentMap.Iterate()
+		var key http.HTTP2StreamKey
+		var value http.EbpfHttp2Tx
+		for iter.Next(unsafe.Pointer(&key), unsafe.Pointer(&value)) {
+			output.WriteString(spew.Sdump(key, value))
+		}
+
+	case httpCgroupIDByTupleMap: // maps/http_cgroup_id_by_tuple (BPF_MAP_TYPE_LRU_HASH), key ConnTuple, value C.__u64
+		output.WriteString("Map: '" + mapName + "', key: 'ConnTuple', value: 'C.__u64'\n")
+		iter := currentMap.Iterate()
+		var key ddebpf.ConnTuple
+		var value uint64
+		for iter.Next(unsafe.Pointer(&key), unsafe.Pointer(&value)) {
+			output.WriteString(spew.Sdump(key, value))
+		}
+
+	case tlsInfoByTupleMap: // maps/tls_info_by_tuple (BPF_MAP_TYPE_LRU_HASH), key ConnTuple, value C.tls_info_t
+		output.WriteString("Map: '" + mapName + "', key: 'ConnTuple', value: 'C.tls_info_t'\n")
+		iter := currentMap.Iterate()
+		var key ddebpf.ConnTuple
+		var value struct {
+			Version     uint16
+			CipherSuite uint16
+		}
+		for iter.Next(unsafe.Pointer(&key), unsafe.Pointer(&value)) {
+			output.WriteString(spew.Sdump(key, value))
+		}
+
+	case serviceMeshPIDsMap: // maps/service_mesh_pids (BPF_MAP_TYPE_HASH), key C.__u32, value C.__u8
+		output.WriteString("Map: '" + mapName + "', key: 'C.__u32', value: 'C.__u8'\n")
+		iter := currentMap.Iterate()
+		var key uint32
+		var value uint8
 		for iter.Next(unsafe.Pointer(&key), unsafe.Pointer(&value)) {
 			output.WriteString(spew.Sdump(key, value))
 		}
@@ -42,7 +83,7 @@ func dumpMapsHandler(manager *manager.Manager, mapName string, currentMap *ebpf.
 			output.WriteString(spew.Sdump(key, value))
 		}
 
-	case "ssl_read_args": // maps/ssl_read_args (BPF_MAP_TYPE_HASH), key C.__u64, value C.ssl_read_args_t
+	case sslReadArgsMap: // maps/ssl_read_args (BPF_MAP_TYPE_HASH), key C.__u64, value C.ssl_read_args_t
 		output.WriteString("Map: '" + mapName + "', key: 'C.__u64', value: 'C.ssl_read_args_t'\n")
 		iter := currentMap.Iterate()
 		var key uint64
@@ -51,7 +92,7 @@ func dumpMapsHandler(manager *manager.Manager, mapName string, currentMap *ebpf.
 			output.WriteString(spew.Sdump(key, value))
 		}
 
-	case "bio_new_socket_args": // maps/bio_new_socket_args (BPF_MAP_TYPE_HASH), key C.__u64, value C.__u32
+	case bioNewSocketArgsMap: // maps/bio_new_socket_args (BPF_MAP_TYPE_HASH), key C.__u64, value C.__u32
 		output.WriteString("Map: '" + mapName + "', key: 'C.__u64', value: 'C.__u32'\n")
 		iter := currentMap.Iterate()
 		var key uint64
@@ -60,16 +101,16 @@ func dumpMapsHandler(manager *manager.Manager, mapName string, currentMap *ebpf.
 			output.WriteString(spew.Sdump(key, value))
 		}
 
-	case "fd_by_ssl_bio": // maps/fd_by_ssl_bio (BPF_MAP_TYPE_HASH), key C.__u32, value uintptr // C.void *
-		output.WriteString("Map: '" + mapName + "', key: 'C.__u32', value: 'uintptr // C.void *'\n")
+	case fdBySSLBioMap: // maps/fd_by_ssl_bio (BPF_MAP_TYPE_HASH), key C.ssl_bio_key_t, value C.__u32
+		output.WriteString("Map: '" + mapName + "', key: 'C.ssl_bio_key_t', value: 'C.__u32'\n")
 		iter := currentMap.Iterate()
-		var key uint32
-		var value uintptr // C.void *
+		var key http.SslBioKey
+		var value uint32
 		for iter.Next(unsafe.Pointer(&key), unsafe.Pointer(&value)) {
 			output.WriteString(spew.Sdump(key, value))
 		}
 
-	case "ssl_ctx_by_pid_tgid": // maps/ssl_ctx_by_pid_tgid (BPF_MAP_TYPE_HASH), key C.__u64, value uintptr // C.void *
+	case sslCtxByPIDTGIDMap: // maps/ssl_ctx_by_pid_tgid (BPF_MAP_TYPE_HASH), key C.__u64, value uintptr // C.void *
 		output.WriteString("Map: '" + mapName + "', key: 'C.__u64', value: 'uintptr // C.void *'\n")
 		iter := currentMap.Iterate()
 		var key uint64
@@ -77,6 +118,53 @@ func dumpMapsHandler(manager *manager.Manager, mapName string, currentMap *ebpf.
 		for iter.Next(unsafe.Pointer(&key), unsafe.Pointer(&value)) {
 			output.WriteString(spew.Sdump(key, value))
 		}
+
+	case http3TupleByConnMap: // maps/http3_tuple_by_conn (BPF_MAP_TYPE_LRU_HASH), key uintptr // C.void *, value C.conn_tuple_t
+		output.WriteString("Map: '" + mapName + "', key: 'uintptr // C.void *', value: 'C.conn_tuple_t'\n")
+		iter := currentMap.Iterate()
+		var key uintptr // C.void *
+		var value ddebpf.ConnTuple
+		for iter.Next(unsafe.Pointer(&key), unsafe.Pointer(&value)) {
+			output.WriteString(spew.Sdump(key, value))
+		}
 	}
 	return output.String()
 }
+
+// dumpInFlightTransactions decodes the transactions of the HTTP and HTTP/2 in-flight maps which are loaded. now is the
+// current monotonic time (in nanoseconds) of the timestamps of the transactions.
+func dumpInFlightTransactions(manager *manager.Manager, now int64) ([]httpdebugging.InFlightTransaction, error) {
+	var all []httpdebugging.InFlightTransaction
+
+	if currentMap, found, _ := manager.GetMap(httpInFlightMap); found {
+		var txs []http.HttpTX
+		iter := currentMap.Iterate()
+		var key ddebpf.ConnTuple
+		var value http.EbpfHttpTx
+		for iter.Next(unsafe.Pointer(&key), unsafe.Pointer(&value)) {
+			tx := value
+			txs = append(txs, &tx)
+		}
+		if err := iter.Err(); err != nil {
+			return nil, fmt.Errorf("error iterating %s map: %w", httpInFlightMap, err)
+		}
+		all = append(all, httpdebugging.InFlight(httpProtocol, txs, now)...)
+	}
+
+	if currentMap, found, _ := manager.GetMap(http2InFlightMap); found {
+		var txs []http.HttpTX
+		iter := currentMap.Iterate()
+		var key http.HTTP2StreamKey
+		var value http.EbpfHttp2Tx
+		for iter.Next(unsafe.Pointer(&key), unsafe.Pointer(&value)) {
+			tx := value
+			txs = append(txs, &tx)
+		}
+		if err := iter.Err(); err != nil {
+			return nil, fmt.Errorf("error iterating %s map: %w", http2InFlightMap, err)
+		}
+		all = append(all, httpdebugging.InFlight(http2Protocol, txs, now)...)
+	}
+
+	return all, nil
+}
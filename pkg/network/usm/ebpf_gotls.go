@@ -219,12 +219,25 @@ func (*GoTLSProgram) GetAllUndefinedProbes() []manager.ProbeIdentificationPair {
 	return probeList
 }
 
-func (p *GoTLSProgram) Start() {
+// Name returns the name of the subprogram, as reported in the usm status
+func (*GoTLSProgram) Name() string {
+	return "go-tls"
+}
+
+// EBPFFunctions returns the eBPF functions of the subprogram, which is dropped when they can't be loaded
+func (p *GoTLSProgram) EBPFFunctions() []string {
+	var funcNames []string
+	for _, identifier := range p.GetAllUndefinedProbes() {
+		funcNames = append(funcNames, identifier.EBPFFuncName)
+	}
+	return funcNames
+}
+
+func (p *GoTLSProgram) Start() error {
 	var err error
 	p.offsetsDataMap, _, err = p.manager.GetMap(offsetsDataMap)
 	if err != nil {
-		log.Errorf("could not get offsets_data map: %s", err)
-		return
+		return fmt.Errorf("could not get offsets_data map: %w", err)
 	}
 
 	mon := monitor.GetProcessMonitor()
@@ -234,8 +247,7 @@ func (p *GoTLSProgram) Start() {
 		Callback: p.handleProcessStart,
 	})
 	if err != nil {
-		log.Errorf("failed to subscribe Exec process monitor error: %s", err)
-		return
+		return fmt.Errorf("failed to subscribe Exec process monitor: %w", err)
 	}
 	p.procMonitor.cleanupExit, err = mon.Subscribe(&monitor.ProcessCallback{
 		Event:    monitor.EXIT,
@@ -243,21 +255,21 @@ func (p *GoTLSProgram) Start() {
 		Callback: p.handleProcessStop,
 	})
 	if err != nil {
-		log.Errorf("failed to subscribe Exit process monitor error: %s", err)
-
-		if p.procMonitor.cleanupExec != nil {
-			p.procMonitor.cleanupExec()
-		}
-		if p.procMonitor.cleanupExit != nil {
-			p.procMonitor.cleanupExit()
-		}
+		p.procMonitor.cleanupExec()
+		p.procMonitor.cleanupExec = nil
+		return fmt.Errorf("failed to subscribe Exit process monitor: %w", err)
 	}
 
+	return nil
 }
 
 func (p *GoTLSProgram) Stop() {
-	p.procMonitor.cleanupExec()
-	p.procMonitor.cleanupExit()
+	if p.procMonitor.cleanupExec != nil {
+		p.procMonitor.cleanupExec()
+	}
+	if p.procMonitor.cleanupExit != nil {
+		p.procMonitor.cleanupExit()
+	}
 }
 
 func (p *GoTLSProgram) handleProcessStart(pid pid) {
@@ -364,6 +376,14 @@ func (p *GoTLSProgram) hookNewBinary(binID binaryID, binPath string, pid pid, bi
 		return
 	}
 
+	if p.cfg.EnableHTTP3Monitoring {
+		quicGoProbeIDs, quicGoErr := p.attachQuicGoHooks(elfFile, inspectionResult, binPath)
+		if quicGoErr != nil {
+			log.Debugf("could not attach the quic-go hooks on %s: %s", binPath, quicGoErr)
+		}
+		probeIDs = append(probeIDs, quicGoProbeIDs...)
+	}
+
 	bin.probeIDs = probeIDs
 
 	elapsed := time.Since(start)
@@ -0,0 +1,280 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux_bpf
+// +build linux_bpf
+
+package usm
+
+import (
+	"debug/elf"
+	"fmt"
+	"regexp"
+
+	"github.com/cilium/ebpf"
+
+	manager "github.com/DataDog/ebpf-manager"
+
+	"github.com/DataDog/datadog-agent/pkg/network/config"
+	"github.com/DataDog/datadog-agent/pkg/network/go/bininspect"
+	"github.com/DataDog/datadog-agent/pkg/network/protocols/http"
+	errtelemetry "github.com/DataDog/datadog-agent/pkg/network/telemetry"
+	"github.com/DataDog/datadog-agent/pkg/util/common"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	http3InFlightMap     = "http3_in_flight"
+	http3TupleByConnMap  = "http3_tuple_by_conn"
+	http3UDPSendMsgProbe = "kprobe__udp_sendmsg"
+)
+
+// nghttp3Probes hooks the HTTP/3 layer of nghttp3, which is used by most
+// userspace QUIC stacks written in C (ngtcp2, curl, nginx-quic through
+// libngtcp2 bindings, etc).
+var nghttp3Probes = []manager.ProbesSelector{
+	&manager.AllOf{
+		Selectors: []manager.ProbesSelector{
+			&manager.ProbeSelector{
+				ProbeIdentificationPair: manager.ProbeIdentificationPair{
+					EBPFFuncName: "uprobe__nghttp3_conn_submit_request",
+				},
+			},
+			&manager.ProbeSelector{
+				ProbeIdentificationPair: manager.ProbeIdentificationPair{
+					EBPFFuncName: "uprobe__nghttp3_conn_read_stream",
+				},
+			},
+			&manager.ProbeSelector{
+				ProbeIdentificationPair: manager.ProbeIdentificationPair{
+					EBPFFuncName: "uretprobe__nghttp3_conn_read_stream",
+				},
+			},
+			&manager.ProbeSelector{
+				ProbeIdentificationPair: manager.ProbeIdentificationPair{
+					EBPFFuncName: "uprobe__nghttp3_qpack_decoder_read_request",
+				},
+			},
+			&manager.ProbeSelector{
+				ProbeIdentificationPair: manager.ProbeIdentificationPair{
+					EBPFFuncName: "uretprobe__nghttp3_qpack_decoder_read_request",
+				},
+			},
+			&manager.ProbeSelector{
+				ProbeIdentificationPair: manager.ProbeIdentificationPair{
+					EBPFFuncName: "uprobe__nghttp3_conn_close_stream",
+				},
+			},
+		},
+	},
+}
+
+// msquicProbes hooks the stream API of msquic, out of which the HTTP/3 frames
+// are decoded since msquic only implements the QUIC transport, and the worker
+// threads draining the operations of the connections, which send their
+// packets. QuicStreamIndicateEvent and QuicConnDrainOperations aren't
+// exported, so the builds of libmsquic whose symbol table was stripped can't
+// be hooked.
+var msquicProbes = []manager.ProbesSelector{
+	&manager.AllOf{
+		Selectors: []manager.ProbesSelector{
+			&manager.ProbeSelector{
+				ProbeIdentificationPair: manager.ProbeIdentificationPair{
+					EBPFFuncName: "uprobe__MsQuicStreamSend",
+				},
+			},
+			&manager.ProbeSelector{
+				ProbeIdentificationPair: manager.ProbeIdentificationPair{
+					EBPFFuncName: "uprobe__QuicStreamIndicateEvent",
+				},
+			},
+			&manager.ProbeSelector{
+				ProbeIdentificationPair: manager.ProbeIdentificationPair{
+					EBPFFuncName: "uprobe__QuicConnDrainOperations",
+				},
+			},
+			&manager.ProbeSelector{
+				ProbeIdentificationPair: manager.ProbeIdentificationPair{
+					EBPFFuncName: "uretprobe__QuicConnDrainOperations",
+				},
+			},
+		},
+	},
+}
+
+// quicGoFunctionToProbes maps the quic-go functions hooked in the Go binaries
+// to their probes, see attachQuicGoHooks. The connection, send queue and
+// socket methods associate the streams to the UDP socket of their connection.
+var quicGoFunctionToProbes = map[string]string{
+	"github.com/quic-go/quic-go/http3.(*requestWriter).WriteRequestHeader": "uprobe__quic_go_http3_requestWriter_WriteRequestHeader",
+	"github.com/quic-go/qpack.(*Decoder).DecodeFull":                       "uprobe__quic_go_qpack_Decoder_DecodeFull",
+	"github.com/quic-go/quic-go.(*connection).OpenStreamSync":              "uprobe__quic_go_connection_OpenStreamSync",
+	"github.com/quic-go/quic-go.(*connection).sendPackets":                 "uprobe__quic_go_connection_sendPackets",
+	"github.com/quic-go/quic-go.(*sendQueue).Send":                         "uprobe__quic_go_sendQueue_Send",
+	"github.com/quic-go/quic-go.(*sendQueue).Run":                          "uprobe__quic_go_sendQueue_Run",
+	"github.com/quic-go/quic-go.(*sconn).Write":                            "uprobe__quic_go_sconn_Write",
+}
+
+// http3Program captures HTTP/3 request metadata out of userspace QUIC
+// libraries. Transactions are reported through the HTTP stats pipeline, and
+// are tagged with `http.protocol:http3`.
+type http3Program struct {
+	cfg *config.Config
+}
+
+var _ subprogram = &http3Program{}
+
+func newHTTP3Program(c *config.Config) *http3Program {
+	if !c.EnableHTTP3Monitoring || !c.EnableHTTPSMonitoring || !http.HTTPSSupported(c) {
+		log.Info("http3 monitoring is not enabled")
+		return nil
+	}
+
+	log.Info("http3 monitoring is enabled")
+	return &http3Program{
+		cfg: c,
+	}
+}
+
+func (p *http3Program) ConfigureManager(m *errtelemetry.Manager) {
+	m.Maps = append(m.Maps, []*manager.Map{
+		{Name: http3InFlightMap},
+		{Name: http3TupleByConnMap},
+	}...)
+
+	m.Probes = append(m.Probes,
+		&manager.Probe{
+			ProbeIdentificationPair: manager.ProbeIdentificationPair{
+				EBPFFuncName: http3UDPSendMsgProbe,
+				UID:          probeUID,
+			},
+			KProbeMaxActive: maxActive,
+		},
+	)
+}
+
+func (p *http3Program) ConfigureOptions(options *manager.Options) {
+	options.MapSpecEditors[http3InFlightMap] = manager.MapSpecEditor{
+		Type:       ebpf.LRUHash,
+		MaxEntries: uint32(p.cfg.MaxTrackedConnections),
+		EditorFlag: manager.EditMaxEntries,
+	}
+	options.MapSpecEditors[http3TupleByConnMap] = manager.MapSpecEditor{
+		Type:       ebpf.LRUHash,
+		MaxEntries: uint32(p.cfg.MaxTrackedConnections),
+		EditorFlag: manager.EditMaxEntries,
+	}
+	options.ActivatedProbes = append(options.ActivatedProbes,
+		&manager.ProbeSelector{
+			ProbeIdentificationPair: manager.ProbeIdentificationPair{
+				EBPFFuncName: http3UDPSendMsgProbe,
+				UID:          probeUID,
+			},
+		})
+}
+
+// Start is a no-op, as the uprobes are attached by the shared libraries
+// watcher of the SSL subprogram (see `soRules`) and by the go-tls subprogram
+// (see `attachQuicGoHooks`).
+func (p *http3Program) Start() error { return nil }
+
+// Name returns the name of the subprogram, as reported in the usm status
+func (*http3Program) Name() string {
+	return "http3"
+}
+
+// Stop is a no-op, see Start.
+func (p *http3Program) Stop() {}
+
+// soRules returns the rules registering the nghttp3 and msquic hooks whenever
+// the libraries are loaded by a process.
+func (p *http3Program) soRules(m *errtelemetry.Manager) []soRule {
+	return []soRule{
+		{
+			re:           regexp.MustCompile(`libnghttp3.so`),
+			registerCB:   addHooks(m, nghttp3Probes),
+			unregisterCB: removeHooks(m, nghttp3Probes),
+		},
+		{
+			re:           regexp.MustCompile(`libmsquic.so`),
+			registerCB:   addHooks(m, msquicProbes),
+			unregisterCB: removeHooks(m, msquicProbes),
+		},
+	}
+}
+
+func (*http3Program) GetAllUndefinedProbes() []manager.ProbeIdentificationPair {
+	probeList := []manager.ProbeIdentificationPair{{EBPFFuncName: http3UDPSendMsgProbe}}
+	for _, probes := range [][]manager.ProbesSelector{nghttp3Probes, msquicProbes} {
+		for _, singleProbe := range probes {
+			for _, identifier := range singleProbe.GetProbesIdentificationPairList() {
+				probeList = append(probeList, manager.ProbeIdentificationPair{
+					EBPFFuncName: identifier.EBPFFuncName,
+				})
+			}
+		}
+	}
+	for _, ebpfFunctionName := range quicGoFunctionToProbes {
+		probeList = append(probeList, manager.ProbeIdentificationPair{EBPFFuncName: ebpfFunctionName})
+	}
+
+	return probeList
+}
+
+// attachQuicGoHooks hooks the HTTP/3 client of quic-go when the inspected Go
+// binary embeds it, and returns no probe otherwise. The probes identify the
+// goroutines with the metadata the go-tls subprogram stores for the binary, and
+// read their arguments out of the registers, so only the binaries built with
+// the register ABI are hooked.
+func (p *GoTLSProgram) attachQuicGoHooks(elfFile *elf.File, result *bininspect.Result, binPath string) (probeIDs []manager.ProbeIdentificationPair, err error) {
+	symbolsSet := make(common.StringSet, len(quicGoFunctionToProbes))
+	for function := range quicGoFunctionToProbes {
+		symbolsSet[function] = struct{}{}
+	}
+	symbols, err := bininspect.GetAllSymbolsByName(elfFile, symbolsSet)
+	if err != nil {
+		// the binary doesn't embed the HTTP/3 client of quic-go
+		return nil, nil
+	}
+	if result.ABI != bininspect.GoABIRegister {
+		return nil, fmt.Errorf("quic-go hooks require the register ABI, the binary uses the %s ABI", result.ABI)
+	}
+
+	pathID, err := newPathIdentifier(binPath)
+	if err != nil {
+		return nil, fmt.Errorf("can't create path identifier for path %s : %s", binPath, err)
+	}
+	uid := getUID(pathID)
+	defer func() {
+		if err != nil {
+			p.detachHooks(probeIDs)
+			probeIDs = nil
+		}
+	}()
+
+	for function, ebpfFunctionName := range quicGoFunctionToProbes {
+		var offset uint32
+		offset, err = bininspect.SymbolToOffset(elfFile, symbols[function])
+		if err != nil {
+			return probeIDs, fmt.Errorf("could not find location for function %q: %w", function, err)
+		}
+
+		probeID := manager.ProbeIdentificationPair{
+			EBPFFuncName: ebpfFunctionName,
+			UID:          uid,
+		}
+		err = p.manager.AddHook("", &manager.Probe{
+			BinaryPath:              binPath,
+			UprobeOffset:            uint64(offset),
+			ProbeIdentificationPair: probeID,
+		})
+		if err != nil {
+			return probeIDs, fmt.Errorf("could not add hook for %q in offset %d due to: %w", ebpfFunctionName, offset, err)
+		}
+		probeIDs = append(probeIDs, probeID)
+	}
+
+	return probeIDs, nil
+}
@@ -53,6 +53,12 @@ var (
 	// if matching the agent-usm.jar would or not injected
 	javaAgentAllowRegex *regexp.Regexp
 	javaAgentBlockRegex *regexp.Regexp
+
+	// kprobe receiving the payloads of the injected java agent
+	javaTLSIoctlProbe = manager.ProbeIdentificationPair{
+		EBPFFuncName: "kprobe__do_vfs_ioctl",
+		UID:          probeUID,
+	}
 )
 
 type JavaTLSProgram struct {
@@ -116,11 +122,9 @@ func (p *JavaTLSProgram) ConfigureManager(m *nettelemetry.Manager) {
 	}...)
 
 	p.manager.Probes = append(m.Probes,
-		&manager.Probe{ProbeIdentificationPair: manager.ProbeIdentificationPair{
-			EBPFFuncName: "kprobe__do_vfs_ioctl",
-			UID:          probeUID,
-		},
-			KProbeMaxActive: maxActive,
+		&manager.Probe{
+			ProbeIdentificationPair: javaTLSIoctlProbe,
+			KProbeMaxActive:         maxActive,
 		},
 	)
 	rand.Seed(int64(os.Getpid()) + time.Now().UnixMicro())
@@ -133,11 +137,14 @@ func (p *JavaTLSProgram) ConfigureOptions(options *manager.Options) {
 		MaxEntries: uint32(p.cfg.MaxTrackedConnections),
 		EditorFlag: manager.EditMaxEntries,
 	}
+	// the kprobe is activated on a best effort basis, so that an attach failure only disables java tls
+	// instead of the whole usm program. Start reports the failure.
 	options.ActivatedProbes = append(options.ActivatedProbes,
-		&manager.ProbeSelector{
-			ProbeIdentificationPair: manager.ProbeIdentificationPair{
-				EBPFFuncName: "kprobe__do_vfs_ioctl",
-				UID:          probeUID,
+		&manager.BestEffort{
+			Selectors: []manager.ProbesSelector{
+				&manager.ProbeSelector{
+					ProbeIdentificationPair: javaTLSIoctlProbe,
+				},
 			},
 		})
 }
@@ -206,7 +213,20 @@ func newJavaProcess(pid uint32) {
 	}
 }
 
-func (p *JavaTLSProgram) Start() {
+// Name returns the name of the subprogram, as reported in the usm status
+func (*JavaTLSProgram) Name() string {
+	return "java-tls"
+}
+
+func (p *JavaTLSProgram) Start() error {
+	probe, found := p.manager.GetProbe(javaTLSIoctlProbe)
+	if !found {
+		return fmt.Errorf("could not find probe %s", javaTLSIoctlProbe.EBPFFuncName)
+	}
+	if !probe.IsRunning() {
+		return fmt.Errorf("could not attach probe %s: %v", javaTLSIoctlProbe.EBPFFuncName, probe.GetLastError())
+	}
+
 	var err error
 	p.cleanupExec, err = p.processMonitor.Subscribe(&monitor.ProcessCallback{
 		Event:    monitor.EXEC,
@@ -215,8 +235,9 @@ func (p *JavaTLSProgram) Start() {
 		Callback: newJavaProcess,
 	})
 	if err != nil {
-		log.Errorf("process monitor Subscribe() error: %s", err)
+		return fmt.Errorf("process monitor Subscribe() error: %w", err)
 	}
+	return nil
 }
 
 func (p *JavaTLSProgram) Stop() {
@@ -9,8 +9,12 @@
 package usm
 
 import (
+	"errors"
 	"fmt"
 	"math"
+	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/cilium/ebpf"
 	"golang.org/x/sys/unix"
@@ -26,13 +30,29 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/network/protocols/events"
 	"github.com/DataDog/datadog-agent/pkg/network/protocols/http"
 	errtelemetry "github.com/DataDog/datadog-agent/pkg/network/telemetry"
+	"github.com/DataDog/datadog-agent/pkg/telemetry"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
+const usmModuleName = "usm"
+
+var protocolTelemetry = struct {
+	enabled telemetry.Gauge
+	toggles telemetry.Counter
+}{
+	telemetry.NewGauge(usmModuleName, "protocol_enabled", []string{"protocol"}, "Gauge set to 1 when the monitoring of a protocol loaded at startup is enabled, and to 0 when it's disabled at runtime"),
+	telemetry.NewCounter(usmModuleName, "protocol_toggles", []string{"protocol", "enabled"}, "Counter measuring the number of times the monitoring of a protocol was enabled or disabled at runtime"),
+}
+
 const (
 	httpInFlightMap  = "http_in_flight"
 	http2InFlightMap = "http2_in_flight"
 
+	// map associating the TCP connections to the cgroup of the process owning them
+	httpCgroupIDByTupleMap = "http_cgroup_id_by_tuple"
+	tlsInfoByTupleMap      = "tls_info_by_tuple"
+	httpSamplingRateMap    = "http_sampling_rate"
+
 	// ELF section of the BPF_PROG_TYPE_SOCKET_FILTER program used
 	// to classify protocols and dispatch the correct handlers.
 	protocolDispatcherSocketFilterFunction   = "socket__protocol_dispatcher"
@@ -40,6 +60,9 @@ const (
 	protocolDispatcherClassificationPrograms = "dispatcher_classification_progs"
 	connectionStatesMap                      = "connection_states"
 
+	// program parsing the headers of the HTTP responses, tail called by socket__http_filter
+	httpResponseHeadersFunction = "socket__http_response_headers"
+
 	// maxActive configures the maximum number of instances of the
 	// kretprobe-probed functions handled simultaneously.  This value should be
 	// enough for typical workloads (e.g. some amount of processes blocked on
@@ -48,6 +71,14 @@ const (
 	probeUID  = "http"
 
 	kafkaLastTCPSeqPerConnectionMap = "kafka_last_tcp_seq_per_connection"
+
+	dnsInFlightMap = "dns_in_flight"
+
+	// names of the protocols which can be toggled at runtime
+	httpProtocol  = "http"
+	http2Protocol = "http2"
+	kafkaProtocol = "kafka"
+	dnsProtocol   = "dns"
 )
 
 type ebpfProgram struct {
@@ -56,9 +87,27 @@ type ebpfProgram struct {
 	offsets               []manager.ConstantEditor
 	subprograms           []subprogram
 	probesResolvers       []probeResolver
-	mapCleaner            *ddebpf.MapCleaner
+	mapCleaners           []*ddebpf.MapCleaner
+	cgroupAttacher        *cgroupAttacher
 	tailCallRouter        []manager.TailCallRoute
 	connectionProtocolMap *ebpf.Map
+
+	// protocolTailCalls holds the tail calls of the protocols loaded at startup, by protocol
+	protocolTailCalls map[string][]manager.TailCallRoute
+	protocolsMux      sync.Mutex
+	// disabledProtocols holds the protocols disabled at runtime
+	disabledProtocols map[string]struct{}
+
+	// subprogramsStatus holds the status of each subprogram, by name. A subprogram failing to start is
+	// disabled on its own, the rest of the monitoring keeps running.
+	subprogramsStatus map[string]subprogramStatus
+	subprogramsMux    sync.Mutex
+}
+
+// subprogramStatus reports whether a subprogram is running, and the error which disabled it otherwise
+type subprogramStatus struct {
+	State string `json:"state"`
+	Error string `json:"error,omitempty"`
 }
 
 type probeResolver interface {
@@ -88,12 +137,24 @@ type probeResolver interface {
 }
 
 type subprogram interface {
+	// Name returns the name of the subprogram, as reported in the usm status
+	Name() string
 	ConfigureManager(*errtelemetry.Manager)
 	ConfigureOptions(*manager.Options)
-	Start()
+	// Start starts the subprogram. On error, the subprogram must release what it acquired, as it is
+	// disabled without being stopped.
+	Start() error
 	Stop()
 }
 
+// bestEffortSubprogram is implemented by the subprograms which are dropped when their eBPF programs can't be
+// loaded, instead of failing the whole usm program.
+type bestEffortSubprogram interface {
+	subprogram
+	// EBPFFunctions returns the eBPF functions of the subprogram, which are excluded once it is dropped
+	EBPFFunctions() []string
+}
+
 var http2TailCall = manager.TailCallRoute{
 	ProgArrayName: protocolDispatcherProgramsMap,
 	Key:           uint32(protocols.ProgramHTTP2),
@@ -106,14 +167,19 @@ func newEBPFProgram(c *config.Config, offsets []manager.ConstantEditor, connecti
 	mgr := &manager.Manager{
 		Maps: []*manager.Map{
 			{Name: httpInFlightMap},
+			{Name: httpCgroupIDByTupleMap},
+			{Name: tlsInfoByTupleMap},
+			{Name: httpSamplingRateMap},
+			{Name: serviceMeshPIDsMap},
 			{Name: sslSockByCtxMap},
 			{Name: protocolDispatcherProgramsMap},
-			{Name: "ssl_read_args"},
-			{Name: "bio_new_socket_args"},
-			{Name: "fd_by_ssl_bio"},
-			{Name: "ssl_ctx_by_pid_tgid"},
+			{Name: sslReadArgsMap},
+			{Name: bioNewSocketArgsMap},
+			{Name: fdBySSLBioMap},
+			{Name: sslCtxByPIDTGIDMap},
 			{Name: connectionStatesMap},
 			{Name: protocolDispatcherClassificationPrograms},
+			{Name: usmCgroupTuplesMap},
 		},
 		Probes: []*manager.Probe{
 			{
@@ -142,8 +208,8 @@ func newEBPFProgram(c *config.Config, offsets []manager.ConstantEditor, connecti
 		mgr.Maps = append(mgr.Maps, &manager.Map{Name: "http2_dynamic_table"}, &manager.Map{Name: "http2_static_table"})
 	}
 
-	subprogramProbesResolvers := make([]probeResolver, 0, 3)
-	subprograms := make([]subprogram, 0, 3)
+	subprogramProbesResolvers := make([]probeResolver, 0, 4)
+	subprograms := make([]subprogram, 0, 4)
 
 	goTLSProg := newGoTLSProgram(c)
 	subprogramProbesResolvers = append(subprogramProbesResolvers, goTLSProg)
@@ -155,43 +221,88 @@ func newEBPFProgram(c *config.Config, offsets []manager.ConstantEditor, connecti
 	if javaTLSProg != nil {
 		subprograms = append(subprograms, javaTLSProg)
 	}
-	openSSLProg := newSSLProgram(c, sockFD)
+	http3Prog := newHTTP3Program(c)
+	subprogramProbesResolvers = append(subprogramProbesResolvers, http3Prog)
+	if http3Prog != nil {
+		subprograms = append(subprograms, http3Prog)
+	}
+	// the node and Envoy processes must be subscribed to before the SSL subprogram initializes the process monitor
+	nodeJSProg := newNodeJSProgram(c)
+	if nodeJSProg != nil {
+		subprograms = append(subprograms, nodeJSProg)
+	}
+	istioProg := newIstioProgram(c)
+	if istioProg != nil {
+		subprograms = append(subprograms, istioProg)
+	}
+	openSSLProg := newSSLProgram(c, sockFD, http3Prog)
 	subprogramProbesResolvers = append(subprogramProbesResolvers, openSSLProg)
 	if openSSLProg != nil {
 		subprograms = append(subprograms, openSSLProg)
 	}
 
-	tailCalls := []manager.TailCallRoute{
-		{
+	protocolTailCalls := map[string][]manager.TailCallRoute{
+		httpProtocol: {
+			{
+				ProgArrayName: protocolDispatcherProgramsMap,
+				Key:           uint32(protocols.ProgramHTTP),
+				ProbeIdentificationPair: manager.ProbeIdentificationPair{
+					EBPFFuncName: "socket__http_filter",
+				},
+			},
+		},
+	}
+
+	if http.ResponseHeadersSupported() {
+		protocolTailCalls[httpProtocol] = append(protocolTailCalls[httpProtocol], manager.TailCallRoute{
 			ProgArrayName: protocolDispatcherProgramsMap,
-			Key:           uint32(protocols.ProgramHTTP),
+			Key:           uint32(protocols.ProgramHTTPResponseHeaders),
 			ProbeIdentificationPair: manager.ProbeIdentificationPair{
-				EBPFFuncName: "socket__http_filter",
+				EBPFFuncName: httpResponseHeadersFunction,
 			},
-		},
+		})
 	}
 
 	if c.EnableHTTP2Monitoring {
-		tailCalls = append(tailCalls, http2TailCall)
+		protocolTailCalls[http2Protocol] = []manager.TailCallRoute{http2TailCall}
 	}
 
 	// If Kafka monitoring is enabled, the kafka parsing function and the Kafka dispatching function are added to the dispatcher mechanism.
 	if c.EnableKafkaMonitoring {
-		tailCalls = append(tailCalls,
-			manager.TailCallRoute{
+		protocolTailCalls[kafkaProtocol] = []manager.TailCallRoute{
+			{
 				ProgArrayName: protocolDispatcherProgramsMap,
 				Key:           uint32(protocols.ProgramKafka),
 				ProbeIdentificationPair: manager.ProbeIdentificationPair{
 					EBPFFuncName: "socket__kafka_filter",
 				},
 			},
-			manager.TailCallRoute{
+			{
 				ProgArrayName: protocolDispatcherClassificationPrograms,
 				Key:           uint32(protocols.DispatcherKafkaProg),
 				ProbeIdentificationPair: manager.ProbeIdentificationPair{
 					EBPFFuncName: "socket__protocol_dispatcher_kafka",
 				},
-			})
+			},
+		}
+	}
+
+	// The UDP datagrams are classified by the dispatcher itself, so DNS only requires its parsing function.
+	if c.EnableDNSMonitoring {
+		protocolTailCalls[dnsProtocol] = []manager.TailCallRoute{
+			{
+				ProgArrayName: protocolDispatcherProgramsMap,
+				Key:           uint32(protocols.ProgramDNS),
+				ProbeIdentificationPair: manager.ProbeIdentificationPair{
+					EBPFFuncName: "socket__dns_filter",
+				},
+			},
+		}
+	}
+
+	var tailCalls []manager.TailCallRoute
+	for _, protocol := range []string{httpProtocol, http2Protocol, kafkaProtocol, dnsProtocol} {
+		tailCalls = append(tailCalls, protocolTailCalls[protocol]...)
 	}
 
 	program := &ebpfProgram{
@@ -202,6 +313,9 @@ func newEBPFProgram(c *config.Config, offsets []manager.ConstantEditor, connecti
 		probesResolvers:       subprogramProbesResolvers,
 		tailCallRouter:        tailCalls,
 		connectionProtocolMap: connectionProtocolMap,
+		protocolTailCalls:     protocolTailCalls,
+		disabledProtocols:     make(map[string]struct{}),
+		subprogramsStatus:     make(map[string]subprogramStatus, len(subprograms)),
 	}
 
 	return program, nil
@@ -217,6 +331,12 @@ func (e *ebpfProgram) Init() error {
 		undefinedProbes = append(undefinedProbes, s.GetAllUndefinedProbes()...)
 	}
 
+	if e.cfg.EnableUSMCgroupAttach {
+		for _, funcName := range cgroupAttachFunctions {
+			undefinedProbes = append(undefinedProbes, manager.ProbeIdentificationPair{EBPFFuncName: funcName})
+		}
+	}
+
 	e.DumpHandler = dumpMapsHandler
 	e.InstructionPatcher = func(m *manager.Manager) error {
 		return errtelemetry.PatchEBPFTelemetry(m, true, undefinedProbes)
@@ -259,28 +379,89 @@ func (e *ebpfProgram) Start() error {
 		return err
 	}
 
-	for _, s := range e.subprograms {
-		s.Start()
+	e.startSubprograms()
+
+	e.setupMapCleaners()
+
+	if e.cfg.EnableUSMCgroupAttach {
+		e.cgroupAttacher, err = newCgroupAttacher(e.cfg, e.Manager.Manager)
+		if err != nil {
+			return fmt.Errorf("could not enable the usm cgroup attach mode: %w", err)
+		}
+		e.cgroupAttacher.Start()
 	}
 
-	e.setupMapCleaner()
+	for protocol := range e.protocolTailCalls {
+		protocolTelemetry.enabled.Set(1, protocol)
+	}
 
 	return nil
 }
 
 func (e *ebpfProgram) Close() error {
-	e.mapCleaner.Stop()
-	for _, s := range e.subprograms {
-		s.Stop()
+	if e.cgroupAttacher != nil {
+		e.cgroupAttacher.Stop()
+	}
+	for _, cleaner := range e.mapCleaners {
+		cleaner.Stop()
 	}
+	e.stopSubprograms()
 	return e.Stop(manager.CleanAll)
 }
 
+// startSubprograms starts each subprogram independently, so that a failing subprogram (for instance, a probe
+// which couldn't be attached) only disables itself instead of the whole monitoring.
+func (e *ebpfProgram) startSubprograms() {
+	e.subprogramsMux.Lock()
+	defer e.subprogramsMux.Unlock()
+
+	for _, s := range e.subprograms {
+		if err := s.Start(); err != nil {
+			log.Errorf("could not start usm subprogram %s, disabling it: %s", s.Name(), err)
+			e.subprogramsStatus[s.Name()] = subprogramStatus{State: NotRunning, Error: err.Error()}
+			continue
+		}
+		e.subprogramsStatus[s.Name()] = subprogramStatus{State: Running}
+	}
+}
+
+// stopSubprograms stops the subprograms which were successfully started
+func (e *ebpfProgram) stopSubprograms() {
+	e.subprogramsMux.Lock()
+	defer e.subprogramsMux.Unlock()
+
+	for _, s := range e.subprograms {
+		if status, ok := e.subprogramsStatus[s.Name()]; ok && status.State == Running {
+			s.Stop()
+		}
+		delete(e.subprogramsStatus, s.Name())
+	}
+}
+
+// getSubprogramsStatus returns a copy of the status of the subprograms
+func (e *ebpfProgram) getSubprogramsStatus() map[string]subprogramStatus {
+	e.subprogramsMux.Lock()
+	defer e.subprogramsMux.Unlock()
+
+	status := make(map[string]subprogramStatus, len(e.subprogramsStatus))
+	for name, s := range e.subprogramsStatus {
+		status[name] = s
+	}
+	return status
+}
+
 func (e *ebpfProgram) initCORE() error {
 	assetName := getAssetName("usm", e.cfg.BPFDebug)
 	return ddebpf.LoadCOREAsset(&e.cfg.Config, assetName, e.init)
 }
 
+// GetLastLoadDiagnostics returns the diagnostics of the last CO-RE load of the USM asset, such as the verifier log
+// when the load failed. There are none when CO-RE isn't enabled.
+func (e *ebpfProgram) GetLastLoadDiagnostics() (ddebpf.LoadDiagnostics, bool) {
+	assetName := getAssetName("usm", e.cfg.BPFDebug)
+	return ddebpf.GetLoadDiagnosticsForAsset(strings.TrimSuffix(assetName, ".o"))
+}
+
 func (e *ebpfProgram) initRuntimeCompiler() error {
 	bc, err := getRuntimeCompiledUSM(e.cfg)
 	if err != nil {
@@ -299,12 +480,32 @@ func (e *ebpfProgram) initPrebuilt() error {
 	return e.init(bc, manager.Options{})
 }
 
-func (e *ebpfProgram) setupMapCleaner() {
+// setupMapCleaners starts the cleaners deleting the stale entries of the maps tracking the HTTP transactions
+// and the SSL calls in flight, which leak when a connection or a thread is closed before they are completed.
+func (e *ebpfProgram) setupMapCleaners() {
+	e.addMapCleaner(e.setupHTTPMapCleaner())
+	if e.cfg.EnableHTTP2Monitoring {
+		e.addMapCleaner(e.setupHTTP2MapCleaner())
+	}
+	if e.cfg.EnableHTTPSMonitoring && http.HTTPSSupported(e.cfg) {
+		for _, mapName := range pidTGIDArgsMaps {
+			e.addMapCleaner(e.setupPIDTGIDMapCleaner(mapName.name, mapName.value))
+		}
+	}
+}
+
+func (e *ebpfProgram) addMapCleaner(cleaner *ddebpf.MapCleaner) {
+	if cleaner != nil {
+		e.mapCleaners = append(e.mapCleaners, cleaner)
+	}
+}
+
+func (e *ebpfProgram) setupHTTPMapCleaner() *ddebpf.MapCleaner {
 	httpMap, _, _ := e.GetMap(httpInFlightMap)
 	httpMapCleaner, err := ddebpf.NewMapCleaner(httpMap, new(netebpf.ConnTuple), new(http.EbpfHttpTx))
 	if err != nil {
 		log.Errorf("error creating map cleaner: %s", err)
-		return
+		return nil
 	}
 
 	ttl := e.cfg.HTTPIdleConnectionTTL.Nanoseconds()
@@ -314,15 +515,82 @@ func (e *ebpfProgram) setupMapCleaner() {
 			return false
 		}
 
-		if updated := int64(httpTxn.ResponseLastSeen()); updated > 0 {
-			return (now - updated) > ttl
+		return isTransactionStale(now, ttl, httpTxn.ResponseLastSeen(), httpTxn.RequestStarted())
+	})
+
+	return httpMapCleaner
+}
+
+// setProtocolEnabled enables or disables the monitoring of a protocol at runtime, by adding or removing the tail
+// calls of the protocol from the program arrays of the protocol dispatcher. Only the protocols enabled at startup
+// can be toggled, as the programs of the other protocols aren't loaded.
+// The protocol is left in its previous state if any of its tail calls can't be updated.
+func (e *ebpfProgram) setProtocolEnabled(protocol string, enabled bool) error {
+	e.protocolsMux.Lock()
+	defer e.protocolsMux.Unlock()
+
+	routes, ok := e.protocolTailCalls[protocol]
+	if !ok {
+		return fmt.Errorf("%w: %s", errProtocolNotLoaded, protocol)
+	}
+	if _, disabled := e.disabledProtocols[protocol]; disabled != enabled {
+		return nil
+	}
+
+	if enabled {
+		if err := e.UpdateTailCallRoutes(routes...); err != nil {
+			if rollbackErr := e.deleteTailCallRoutes(routes); rollbackErr != nil {
+				log.Errorf("could not roll back the tail calls of protocol %s: %s", protocol, rollbackErr)
+			}
+			return fmt.Errorf("could not enable protocol %s: %w", protocol, err)
 		}
+		delete(e.disabledProtocols, protocol)
+	} else {
+		if err := e.deleteTailCallRoutes(routes); err != nil {
+			if rollbackErr := e.UpdateTailCallRoutes(routes...); rollbackErr != nil {
+				log.Errorf("could not roll back the tail calls of protocol %s: %s", protocol, rollbackErr)
+			}
+			return fmt.Errorf("could not disable protocol %s: %w", protocol, err)
+		}
+		e.disabledProtocols[protocol] = struct{}{}
+	}
 
-		started := int64(httpTxn.RequestStarted())
-		return started > 0 && (now-started) > ttl
-	})
+	enabledValue := float64(0)
+	if enabled {
+		enabledValue = 1
+	}
+	protocolTelemetry.enabled.Set(enabledValue, protocol)
+	protocolTelemetry.toggles.Inc(protocol, strconv.FormatBool(enabled))
+	return nil
+}
 
-	e.mapCleaner = httpMapCleaner
+// deleteTailCallRoutes removes tail calls from their program arrays. They're removed in the reverse order of
+// their addition, so that a classification program is removed before the parsing program it dispatches to.
+func (e *ebpfProgram) deleteTailCallRoutes(routes []manager.TailCallRoute) error {
+	for i := len(routes) - 1; i >= 0; i-- {
+		route := routes[i]
+		progArray, _, err := e.GetMap(route.ProgArrayName)
+		if err != nil {
+			return err
+		}
+		if err := progArray.Delete(route.Key); err != nil && !errors.Is(err, ebpf.ErrKeyNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// protocolsState returns whether the monitoring of each protocol loaded at startup is enabled
+func (e *ebpfProgram) protocolsState() map[string]bool {
+	e.protocolsMux.Lock()
+	defer e.protocolsMux.Unlock()
+
+	state := make(map[string]bool, len(e.protocolTailCalls))
+	for protocol := range e.protocolTailCalls {
+		_, disabled := e.disabledProtocols[protocol]
+		state[protocol] = !disabled
+	}
+	return state
 }
 
 func addBoolConst(options *manager.Options, flag bool, name string) {
@@ -356,6 +624,17 @@ func (e *ebpfProgram) init(buf bytecode.AssetReader, options manager.Options) er
 			MaxEntries: uint32(e.cfg.MaxTrackedConnections),
 			EditorFlag: manager.EditMaxEntries,
 		},
+		// kept as an LRU, as there is no cleaner removing the entries of the closed connections
+		httpCgroupIDByTupleMap: {
+			Type:       ebpf.LRUHash,
+			MaxEntries: uint32(e.cfg.MaxTrackedConnections),
+			EditorFlag: manager.EditMaxEntries,
+		},
+		tlsInfoByTupleMap: {
+			Type:       ebpf.LRUHash,
+			MaxEntries: uint32(e.cfg.MaxTrackedConnections),
+			EditorFlag: manager.EditMaxEntries,
+		},
 		http2InFlightMap: {
 			Type:       ebpf.Hash,
 			MaxEntries: uint32(e.cfg.MaxTrackedConnections),
@@ -371,6 +650,18 @@ func (e *ebpfProgram) init(buf bytecode.AssetReader, options manager.Options) er
 			MaxEntries: uint32(e.cfg.MaxTrackedConnections),
 			EditorFlag: manager.EditMaxEntries,
 		},
+		// kept as an LRU, as there is no cleaner removing the entries of the closed connections
+		usmCgroupTuplesMap: {
+			Type:       ebpf.LRUHash,
+			MaxEntries: uint32(e.cfg.MaxTrackedConnections),
+			EditorFlag: manager.EditMaxEntries,
+		},
+		// kept as an LRU, as the queries whose response is lost are never completed
+		dnsInFlightMap: {
+			Type:       ebpf.LRUHash,
+			MaxEntries: uint32(e.cfg.MaxTrackedConnections),
+			EditorFlag: manager.EditMaxEntries,
+		},
 	}
 	if e.connectionProtocolMap != nil {
 		if options.MapEditors == nil {
@@ -409,7 +700,11 @@ func (e *ebpfProgram) init(buf bytecode.AssetReader, options manager.Options) er
 	options.ConstantEditors = e.offsets
 	addBoolConst(&options, e.cfg.EnableHTTPMonitoring, "http_monitoring_enabled")
 	addBoolConst(&options, e.cfg.EnableHTTP2Monitoring, "http2_monitoring_enabled")
+	addBoolConst(&options, e.cfg.EnableHTTPMonitoring && http.CgroupIDSupported(), "http_cgroup_id_enabled")
+	addBoolConst(&options, e.cfg.EnableHTTPMonitoring && e.cfg.EnableHTTPSMonitoring, "tls_info_enabled")
 	addBoolConst(&options, e.cfg.EnableKafkaMonitoring, "kafka_monitoring_enabled")
+	addBoolConst(&options, e.cfg.EnableDNSMonitoring, "dns_monitoring_enabled")
+	addBoolConst(&options, e.cfg.EnableUSMCgroupAttach, "usm_cgroup_attach_enabled")
 	options.DefaultKprobeAttachMethod = kprobeAttachMethod
 	options.VerifierOptions.Programs.LogSize = 2 * 1024 * 1024
 
@@ -419,6 +714,9 @@ func (e *ebpfProgram) init(buf bytecode.AssetReader, options manager.Options) er
 
 	// Configure event streams
 	events.Configure("http", e.Manager.Manager, &options)
+	if !http.ResponseHeadersSupported() {
+		options.ExcludedFunctions = append(options.ExcludedFunctions, httpResponseHeadersFunction)
+	}
 
 	if e.cfg.EnableHTTP2Monitoring {
 		events.Configure("http2", e.Manager.Manager, &options)
@@ -433,7 +731,82 @@ func (e *ebpfProgram) init(buf bytecode.AssetReader, options manager.Options) er
 		options.ExcludedFunctions = append(options.ExcludedFunctions, "socket__kafka_filter", "socket__protocol_dispatcher_kafka")
 	}
 
-	return e.InitWithOptions(buf, options)
+	if e.cfg.EnableDNSMonitoring {
+		events.Configure("dns", e.Manager.Manager, &options)
+	} else {
+		options.ExcludedFunctions = append(options.ExcludedFunctions, "socket__dns_filter")
+	}
+
+	if !e.cfg.EnableUSMCgroupAttach {
+		options.ExcludedFunctions = append(options.ExcludedFunctions, cgroupAttachFunctions...)
+	}
+
+	for {
+		err := e.InitWithOptions(buf, options)
+		if err == nil || !e.dropFailingSubprograms(err, &options) {
+			return err
+		}
+	}
+}
+
+// dropFailingSubprograms drops the best effort subprograms whose eBPF functions caused the load error, and excludes
+// their functions from the options. It returns false when no subprogram was dropped.
+func (e *ebpfProgram) dropFailingSubprograms(loadErr error, options *manager.Options) bool {
+	e.subprogramsMux.Lock()
+	defer e.subprogramsMux.Unlock()
+
+	excluded := make(map[string]struct{})
+	subprograms := e.subprograms[:0]
+	for _, s := range e.subprograms {
+		bestEffort, ok := s.(bestEffortSubprogram)
+		if !ok || !mentionsAny(loadErr.Error(), bestEffort.EBPFFunctions()) {
+			subprograms = append(subprograms, s)
+			continue
+		}
+
+		log.Errorf("could not load usm subprogram %s, disabling it: %s", s.Name(), loadErr)
+		e.subprogramsStatus[s.Name()] = subprogramStatus{State: NotRunning, Error: loadErr.Error()}
+		for _, funcName := range bestEffort.EBPFFunctions() {
+			if _, ok := excluded[funcName]; !ok {
+				excluded[funcName] = struct{}{}
+				options.ExcludedFunctions = append(options.ExcludedFunctions, funcName)
+			}
+		}
+	}
+	if len(excluded) == 0 {
+		return false
+	}
+	e.subprograms = subprograms
+
+	// the probes of the excluded functions must be removed, the manager failing to match them with a program
+	probes := e.Manager.Probes[:0]
+	for _, p := range e.Manager.Probes {
+		if _, ok := excluded[p.EBPFFuncName]; !ok {
+			probes = append(probes, p)
+		}
+	}
+	e.Manager.Probes = probes
+	return true
+}
+
+func mentionsAny(msg string, funcNames []string) bool {
+	for _, funcName := range funcNames {
+		if strings.Contains(msg, funcName) {
+			return true
+		}
+	}
+	return false
+}
+
+// probesFunctions returns the eBPF functions of the probes selected by the selectors
+func probesFunctions(selectors []manager.ProbesSelector) []string {
+	var funcNames []string
+	for _, selector := range selectors {
+		for _, identifier := range selector.GetProbesIdentificationPairList() {
+			funcNames = append(funcNames, identifier.EBPFFuncName)
+		}
+	}
+	return funcNames
 }
 
 func getAssetName(module string, debug bool) string {
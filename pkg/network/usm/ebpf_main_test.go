@@ -0,0 +1,109 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux_bpf
+// +build linux_bpf
+
+package usm
+
+import (
+	"errors"
+	"testing"
+
+	manager "github.com/DataDog/ebpf-manager"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	errtelemetry "github.com/DataDog/datadog-agent/pkg/network/telemetry"
+)
+
+type fakeSubprogram struct {
+	name     string
+	startErr error
+	started  bool
+	stopped  bool
+}
+
+func (f *fakeSubprogram) Name() string                           { return f.name }
+func (f *fakeSubprogram) ConfigureManager(*errtelemetry.Manager) {}
+func (f *fakeSubprogram) ConfigureOptions(*manager.Options)      {}
+func (f *fakeSubprogram) Stop()                                  { f.stopped = true }
+func (f *fakeSubprogram) Start() error {
+	if f.startErr != nil {
+		return f.startErr
+	}
+	f.started = true
+	return nil
+}
+
+func TestSubprogramsIsolation(t *testing.T) {
+	goTLS := &fakeSubprogram{name: "go-tls"}
+	javaTLS := &fakeSubprogram{name: "java-tls", startErr: errors.New("could not attach probe kprobe__do_vfs_ioctl")}
+	openSSL := &fakeSubprogram{name: "openssl"}
+
+	e := &ebpfProgram{
+		subprograms:       []subprogram{goTLS, javaTLS, openSSL},
+		subprogramsStatus: make(map[string]subprogramStatus),
+	}
+
+	e.startSubprograms()
+	// the subprograms following the failing one are started nonetheless
+	assert.True(t, goTLS.started)
+	assert.False(t, javaTLS.started)
+	assert.True(t, openSSL.started)
+	assert.Equal(t, map[string]subprogramStatus{
+		"go-tls":   {State: Running},
+		"java-tls": {State: NotRunning, Error: "could not attach probe kprobe__do_vfs_ioctl"},
+		"openssl":  {State: Running},
+	}, e.getSubprogramsStatus())
+
+	// only the running subprograms are stopped
+	e.stopSubprograms()
+	assert.True(t, goTLS.stopped)
+	assert.False(t, javaTLS.stopped)
+	assert.True(t, openSSL.stopped)
+	assert.Empty(t, e.getSubprogramsStatus())
+}
+
+type fakeBestEffortSubprogram struct {
+	fakeSubprogram
+	funcNames []string
+}
+
+func (f *fakeBestEffortSubprogram) EBPFFunctions() []string { return f.funcNames }
+
+func TestDropFailingSubprograms(t *testing.T) {
+	javaTLS := &fakeSubprogram{name: "java-tls"}
+	goTLS := &fakeBestEffortSubprogram{fakeSubprogram: fakeSubprogram{name: "go-tls"}, funcNames: []string{"uprobe__crypto_tls_Conn_Read"}}
+	openSSL := &fakeBestEffortSubprogram{fakeSubprogram: fakeSubprogram{name: "openssl"}, funcNames: []string{"uprobe__SSL_read", "kprobe__do_sys_open"}}
+
+	e := &ebpfProgram{
+		Manager: errtelemetry.NewManager(&manager.Manager{
+			Probes: []*manager.Probe{
+				{ProbeIdentificationPair: manager.ProbeIdentificationPair{EBPFFuncName: "kprobe__tcp_sendmsg"}},
+				{ProbeIdentificationPair: manager.ProbeIdentificationPair{EBPFFuncName: "kprobe__do_sys_open"}},
+			},
+		}, nil),
+		subprograms:       []subprogram{javaTLS, goTLS, openSSL},
+		subprogramsStatus: make(map[string]subprogramStatus),
+	}
+
+	// an error unrelated to the best effort subprograms fails the program
+	options := manager.Options{}
+	assert.False(t, e.dropFailingSubprograms(errors.New("program kprobe__tcp_sendmsg: load program: permission denied"), &options))
+	assert.Len(t, e.subprograms, 3)
+	assert.Empty(t, options.ExcludedFunctions)
+
+	// the failing subprogram is dropped, and its functions and probes excluded
+	loadErr := errors.New("program uprobe__SSL_read: load program: permission denied")
+	assert.True(t, e.dropFailingSubprograms(loadErr, &options))
+	assert.Equal(t, []subprogram{javaTLS, goTLS}, e.subprograms)
+	assert.Equal(t, []string{"uprobe__SSL_read", "kprobe__do_sys_open"}, options.ExcludedFunctions)
+	require.Len(t, e.Manager.Probes, 1)
+	assert.Equal(t, "kprobe__tcp_sendmsg", e.Manager.Probes[0].EBPFFuncName)
+	assert.Equal(t, map[string]subprogramStatus{
+		"openssl": {State: NotRunning, Error: loadErr.Error()},
+	}, e.getSubprogramsStatus())
+}
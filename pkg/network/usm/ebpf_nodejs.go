@@ -0,0 +1,174 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux_bpf
+// +build linux_bpf
+
+package usm
+
+import (
+	"debug/elf"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+
+	manager "github.com/DataDog/ebpf-manager"
+
+	"github.com/DataDog/datadog-agent/pkg/network/config"
+	"github.com/DataDog/datadog-agent/pkg/network/go/bininspect"
+	"github.com/DataDog/datadog-agent/pkg/network/protocols/http"
+	errtelemetry "github.com/DataDog/datadog-agent/pkg/network/telemetry"
+	"github.com/DataDog/datadog-agent/pkg/process/monitor"
+	"github.com/DataDog/datadog-agent/pkg/util/common"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const nodeJSLibrary = "nodejs"
+
+// nodeJSBinaryRegex matches the executable of Node.js, named nodejs on Debian based distributions
+var nodeJSBinaryRegex = regexp.MustCompile(`/node(js)?$`)
+
+// nodeJSProbes are the probes attached to the OpenSSL library statically linked into the node binary.
+// Node.js feeds OpenSSL through memory BIOs rather than through the socket, so like for Envoy the tuple is
+// guessed from tcp_sendmsg, and the BoringSSL probes, which don't require SSL_set_fd, are attached.
+var nodeJSProbes = newBoringSSLProbes()
+
+// nodeJSProgram hooks the node binaries, so that the HTTPS traffic of the Node.js services is decoded.
+// Node.js links OpenSSL statically and exports its symbols for the native addons, so the hooks are
+// attached to the binary itself once per inode, when the first node process using it is started,
+// and detached when the last one exits.
+type nodeJSProgram struct {
+	procRoot string
+	registry *soRegistry
+	rule     soRule
+
+	// Process monitor channels
+	procMonitor struct {
+		cleanupExec func()
+		cleanupExit func()
+	}
+}
+
+// Static evaluation to make sure we are not breaking the interface.
+var _ subprogram = &nodeJSProgram{}
+
+func newNodeJSProgram(c *config.Config) *nodeJSProgram {
+	if !c.EnableNodeJSMonitoring || !c.EnableHTTPSMonitoring || !http.HTTPSSupported(c) {
+		return nil
+	}
+
+	return &nodeJSProgram{
+		procRoot: c.ProcRoot,
+		registry: newSORegistry("nodejs", c),
+	}
+}
+
+// ConfigureManager sets up the hooks of the node binaries. The eBPF programs and maps are those of
+// the SSL subprogram.
+func (p *nodeJSProgram) ConfigureManager(m *errtelemetry.Manager) {
+	p.rule = soRule{
+		re:           nodeJSBinaryRegex,
+		registerCB:   addNodeJSHooks(m),
+		unregisterCB: removeHooks(m, nodeJSProbes),
+	}
+}
+
+// ConfigureOptions is a no-op, the options are set by the SSL subprogram
+func (p *nodeJSProgram) ConfigureOptions(*manager.Options) {}
+
+// Start subscribes to the node processes events. It must be called before the process monitor is
+// initialized, for the node processes already running to be hooked.
+func (p *nodeJSProgram) Start() error {
+	var err error
+	mon := monitor.GetProcessMonitor()
+	p.procMonitor.cleanupExec, err = mon.Subscribe(&monitor.ProcessCallback{
+		Event:    monitor.EXEC,
+		Metadata: monitor.EXE,
+		Regex:    nodeJSBinaryRegex,
+		Callback: p.handleProcessExec,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to subscribe Exec process monitor: %w", err)
+	}
+
+	p.procMonitor.cleanupExit, err = mon.Subscribe(&monitor.ProcessCallback{
+		Event:    monitor.EXIT,
+		Metadata: monitor.EXE,
+		Regex:    nodeJSBinaryRegex,
+		Callback: p.registry.unregister,
+	})
+	if err != nil {
+		p.procMonitor.cleanupExec()
+		p.procMonitor.cleanupExec = nil
+		return fmt.Errorf("failed to subscribe Exit process monitor: %w", err)
+	}
+
+	log.Info("nodejs tls monitoring is enabled")
+	return nil
+}
+
+// Name returns the name of the subprogram, as reported in the usm status
+func (*nodeJSProgram) Name() string {
+	return "nodejs-tls"
+}
+
+// EBPFFunctions returns the eBPF functions of the subprogram, which is dropped when they can't be loaded
+func (*nodeJSProgram) EBPFFunctions() []string {
+	return probesFunctions(nodeJSProbes)
+}
+
+// Stop unsubscribes from the process monitor and detaches the hooks of all the node binaries
+func (p *nodeJSProgram) Stop() {
+	if p.procMonitor.cleanupExec != nil {
+		p.procMonitor.cleanupExec()
+	}
+	if p.procMonitor.cleanupExit != nil {
+		p.procMonitor.cleanupExit()
+	}
+	p.registry.cleanup()
+}
+
+// addNodeJSHooks returns the registration callback of the node binaries. The node binaries of some distributions
+// link OpenSSL dynamically, in which case its libssl is hooked by the SSL subprogram and the binary is skipped.
+func addNodeJSHooks(m *errtelemetry.Manager) func(pathIdentifier, string, string) error {
+	registerCB := withHooksTelemetry(nodeJSLibrary, addHooks(m, nodeJSProbes))
+	return func(id pathIdentifier, root string, path string) error {
+		if !definesOpenSSL(root + path) {
+			log.Debugf("%s doesn't link OpenSSL statically, skipping it", path)
+			return nil
+		}
+		return registerCB(id, root, path)
+	}
+}
+
+// definesOpenSSL returns true if the binary at the given path defines the OpenSSL functions, rather than
+// importing them from a shared library
+func definesOpenSSL(path string) bool {
+	elfFile, err := elf.Open(path)
+	if err != nil {
+		return false
+	}
+	defer elfFile.Close()
+
+	symbols, err := bininspect.GetAllSymbolsByName(elfFile, common.StringSet{"SSL_read": struct{}{}})
+	if err != nil {
+		return false
+	}
+	return symbols["SSL_read"].Section != elf.SHN_UNDEF
+}
+
+func (p *nodeJSProgram) handleProcessExec(pid uint32) {
+	procPid := filepath.Join(p.procRoot, strconv.FormatUint(uint64(pid), 10))
+	binPath, err := os.Readlink(filepath.Join(procPid, "exe"))
+	if err != nil {
+		// the process already exited
+		return
+	}
+
+	// the binary path is relative to the process' mount namespace
+	p.registry.register(filepath.Join(procPid, "root"), binPath, pid, p.rule)
+}
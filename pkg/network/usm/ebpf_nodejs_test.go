@@ -0,0 +1,98 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux_bpf
+// +build linux_bpf
+
+package usm
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
+
+	"github.com/DataDog/datadog-agent/pkg/network/config"
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+)
+
+func TestNodeJSBinaryRegex(t *testing.T) {
+	assert.True(t, nodeJSBinaryRegex.MatchString("/usr/local/bin/node"))
+	assert.True(t, nodeJSBinaryRegex.MatchString("/usr/bin/nodejs"))
+	assert.True(t, nodeJSBinaryRegex.MatchString("/root/.nvm/versions/node/v18.16.0/bin/node"))
+	assert.False(t, nodeJSBinaryRegex.MatchString("/usr/local/bin/node-gyp"))
+	assert.False(t, nodeJSBinaryRegex.MatchString("/usr/local/bin/nodemon"))
+}
+
+func TestNodeJSProgram(t *testing.T) {
+	nodePath := copyBinaryAs(t, "/bin/sleep", "node")
+	nodePathID, err := newPathIdentifier(nodePath)
+	require.NoError(t, err)
+
+	registered := atomic.NewInt32(0)
+	unregistered := atomic.NewInt32(0)
+	p := &nodeJSProgram{
+		procRoot: util.GetProcRoot(),
+		registry: newSORegistry("test", config.New()),
+		rule: soRule{
+			re: nodeJSBinaryRegex,
+			registerCB: func(id pathIdentifier, root string, path string) error {
+				assert.Equal(t, nodePathID, id)
+				assert.Equal(t, nodePath, path)
+				registered.Inc()
+				return nil
+			},
+			unregisterCB: func(id pathIdentifier) error {
+				assert.Equal(t, nodePathID, id)
+				unregistered.Inc()
+				return nil
+			},
+		},
+	}
+
+	// the hooks are attached once for all the processes running the same binary
+	node1 := startSleepingBinary(t, nodePath)
+	node2 := startSleepingBinary(t, nodePath)
+	p.handleProcessExec(node1)
+	p.handleProcessExec(node2)
+	assert.Equal(t, int32(1), registered.Load())
+
+	// and detached once all the node processes exited
+	p.registry.unregister(node1)
+	assert.Equal(t, int32(0), unregistered.Load())
+	p.registry.unregister(node2)
+	assert.Equal(t, int32(1), unregistered.Load())
+	assert.Empty(t, p.registry.byID)
+}
+
+func TestDefinesOpenSSL(t *testing.T) {
+	gcc, err := exec.LookPath("gcc")
+	if err != nil {
+		t.Skip("gcc is required to build the fake binaries")
+	}
+	dir := t.TempDir()
+
+	// a binary linking OpenSSL statically defines its functions
+	static := filepath.Join(dir, "static.c")
+	require.NoError(t, os.WriteFile(static, []byte("int SSL_read(void) { return 0; }\nint main(void) { return SSL_read(); }\n"), 0644))
+	out, err := exec.Command(gcc, "-rdynamic", "-o", filepath.Join(dir, "static"), static).CombinedOutput()
+	require.NoError(t, err, string(out))
+	assert.True(t, definesOpenSSL(filepath.Join(dir, "static")))
+
+	// a binary linking OpenSSL dynamically imports them
+	lib := buildFakeSSLLibrary(t, "SSL_read")
+	dynamic := filepath.Join(dir, "dynamic.c")
+	require.NoError(t, os.WriteFile(dynamic, []byte("int SSL_read(void);\nint main(void) { return SSL_read(); }\n"), 0644))
+	out, err = exec.Command(gcc, "-o", filepath.Join(dir, "dynamic"), dynamic, lib).CombinedOutput()
+	require.NoError(t, err, string(out))
+	assert.False(t, definesOpenSSL(filepath.Join(dir, "dynamic")))
+
+	assert.False(t, definesOpenSSL("/bin/sleep"))
+	assert.False(t, definesOpenSSL(filepath.Join(dir, "missing")))
+}
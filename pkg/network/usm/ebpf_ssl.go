@@ -10,8 +10,8 @@ package usm
 
 import (
 	"debug/elf"
+	"fmt"
 	"os"
-	"regexp"
 	"strings"
 
 	manager "github.com/DataDog/ebpf-manager"
@@ -114,6 +114,9 @@ var openSSLProbes = []manager.ProbesSelector{
 	},
 }
 
+// boringSSLProbes are attached to the shared builds of BoringSSL, see newBoringSSLProbes
+var boringSSLProbes = newBoringSSLProbes()
+
 var cryptoProbes = []manager.ProbesSelector{
 	&manager.AllOf{
 		Selectors: []manager.ProbesSelector{
@@ -195,6 +198,10 @@ var gnuTLSProbes = []manager.ProbesSelector{
 
 const (
 	sslSockByCtxMap        = "ssl_sock_by_ctx"
+	sslCtxByPIDTGIDMap     = "ssl_ctx_by_pid_tgid"
+	sslReadArgsMap         = "ssl_read_args"
+	bioNewSocketArgsMap    = "bio_new_socket_args"
+	fdBySSLBioMap          = "fd_by_ssl_bio"
 	sharedLibrariesPerfMap = "shared_libraries"
 )
 
@@ -212,11 +219,13 @@ type sslProgram struct {
 	watcher                 *soWatcher
 	manager                 *errtelemetry.Manager
 	sysOpenHooksIdentifiers []manager.ProbeIdentificationPair
+	http3Prog               *http3Program
+	mapCleaner              *sslMapCleaner
 }
 
 var _ subprogram = &sslProgram{}
 
-func newSSLProgram(c *config.Config, sockFDMap *ebpf.Map) *sslProgram {
+func newSSLProgram(c *config.Config, sockFDMap *ebpf.Map, http3Prog *http3Program) *sslProgram {
 	if !c.EnableHTTPSMonitoring || !http.HTTPSSupported(c) {
 		return nil
 	}
@@ -226,6 +235,7 @@ func newSSLProgram(c *config.Config, sockFDMap *ebpf.Map) *sslProgram {
 		sockFDMap:               sockFDMap,
 		perfHandler:             ddebpf.NewPerfHandler(100),
 		sysOpenHooksIdentifiers: getSysOpenHooksIdentifiers(),
+		http3Prog:               http3Prog,
 	}
 }
 
@@ -260,13 +270,17 @@ func (o *sslProgram) ConfigureOptions(options *manager.Options) {
 		EditorFlag: manager.EditMaxEntries,
 	}
 
+	// The hooks of the open syscalls are best effort, the shared libraries being detected with inotify when they
+	// can't be attached
+	sysOpenHooks := make([]manager.ProbesSelector, 0, len(o.sysOpenHooksIdentifiers))
 	for _, identifier := range o.sysOpenHooksIdentifiers {
-		options.ActivatedProbes = append(options.ActivatedProbes,
+		sysOpenHooks = append(sysOpenHooks,
 			&manager.ProbeSelector{
 				ProbeIdentificationPair: identifier,
 			},
 		)
 	}
+	options.ActivatedProbes = append(options.ActivatedProbes, &manager.BestEffort{Selectors: sysOpenHooks})
 
 	if options.MapEditors == nil {
 		options.MapEditors = make(map[string]*ebpf.Map)
@@ -275,34 +289,81 @@ func (o *sslProgram) ConfigureOptions(options *manager.Options) {
 	options.MapEditors[probes.SockByPidFDMap] = o.sockFDMap
 }
 
-func (o *sslProgram) Start() {
+// Name returns the name of the subprogram, as reported in the usm status
+func (*sslProgram) Name() string {
+	return "openssl"
+}
+
+// EBPFFunctions returns the eBPF functions of the subprogram, which is dropped when they can't be loaded
+func (o *sslProgram) EBPFFunctions() []string {
+	var funcNames []string
+	for _, identifier := range o.GetAllUndefinedProbes() {
+		funcNames = append(funcNames, identifier.EBPFFuncName)
+	}
+	return funcNames
+}
+
+func (o *sslProgram) Start() error {
+	ctxByPIDTGIDMap, _, err := o.manager.GetMap(sslCtxByPIDTGIDMap)
+	if err != nil {
+		return fmt.Errorf("could not get %s map: %w", sslCtxByPIDTGIDMap, err)
+	}
+	fdByBioMap, _, err := o.manager.GetMap(fdBySSLBioMap)
+	if err != nil {
+		return fmt.Errorf("could not get %s map: %w", fdBySSLBioMap, err)
+	}
+
 	// Setup shared library watcher and configure the appropriate callbacks
-	o.watcher = newSOWatcher(o.perfHandler,
-		soRule{
-			re:           regexp.MustCompile(`libssl.so`),
-			registerCB:   addHooks(o.manager, openSSLProbes),
-			unregisterCB: removeHooks(o.manager, openSSLProbes),
+	rules := []soRule{
+		{
+			// OpenSSL, LibreSSL and the shared builds of BoringSSL
+			re:           sslLibraryRegex,
+			registerCB:   addSSLHooks(o.manager, openSSLProbes, boringSSLProbes),
+			unregisterCB: removeSSLHooks(o.manager, openSSLProbes, boringSSLProbes),
 		},
-		soRule{
-			re:           regexp.MustCompile(`libcrypto.so`),
-			registerCB:   addHooks(o.manager, cryptoProbes),
+		{
+			re:           cryptoLibraryRegex,
+			registerCB:   withHooksTelemetry(cryptoLibrary, addHooks(o.manager, cryptoProbes)),
 			unregisterCB: removeHooks(o.manager, cryptoProbes),
 		},
-		soRule{
-			re:           regexp.MustCompile(`libgnutls.so`),
-			registerCB:   addHooks(o.manager, gnuTLSProbes),
+		{
+			re:           gnuTLSLibraryRegex,
+			registerCB:   withHooksTelemetry(gnuTLSLibrary, addHooks(o.manager, gnuTLSProbes)),
 			unregisterCB: removeHooks(o.manager, gnuTLSProbes),
 		},
-	)
-
+	}
+	if o.http3Prog != nil {
+		rules = append(rules, o.http3Prog.soRules(o.manager)...)
+	}
+	o.watcher = newSOWatcher(o.cfg, o.perfHandler, rules...)
+	if !o.sysOpenHooksRunning() {
+		log.Warn("could not attach the hooks of the open syscalls, falling back to inotify to detect the shared libraries")
+		o.watcher.useInotify = true
+	}
 	o.watcher.Start()
+
+	o.mapCleaner = newSSLMapCleaner(ctxByPIDTGIDMap, fdByBioMap)
+	o.mapCleaner.Start()
+	return nil
+}
+
+// sysOpenHooksRunning returns whether the hooks of the open syscalls, streaming the shared libraries being opened,
+// are attached
+func (o *sslProgram) sysOpenHooksRunning() bool {
+	for _, identifier := range o.sysOpenHooksIdentifiers {
+		probe, found := o.manager.GetProbe(identifier)
+		if !found || !probe.IsRunning() {
+			return false
+		}
+	}
+	return true
 }
 
 func (o *sslProgram) Stop() {
 	// Detaching the hooks.
 	for _, identifier := range o.sysOpenHooksIdentifiers {
 		probe, found := o.manager.GetProbe(identifier)
-		if !found {
+		if !found || !probe.IsRunning() {
 			continue
 		}
 		if err := probe.Stop(); err != nil {
@@ -313,6 +374,9 @@ func (o *sslProgram) Stop() {
 	// we might try to send events over the perfHandler.
 	o.watcher.Stop()
 	o.perfHandler.Stop()
+	if o.mapCleaner != nil {
+		o.mapCleaner.Stop()
+	}
 }
 
 func addHooks(m *errtelemetry.Manager, probes []manager.ProbesSelector) func(pathIdentifier, string, string) error {
@@ -8,6 +8,12 @@
 
 package usm
 
+import "errors"
+
+// errProtocolNotLoaded indicates that the programs of a protocol weren't loaded at startup, so the protocol can't be
+// enabled without restarting system-probe
+var errProtocolNotLoaded = errors.New("protocol not loaded, enabling it requires a restart of system-probe")
+
 // errNotSupported indicates that the current host doesn't fulfill the requirements for USM monitoring
 type errNotSupported struct {
 	error
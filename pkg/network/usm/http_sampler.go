@@ -0,0 +1,136 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux_bpf
+// +build linux_bpf
+
+package usm
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cilium/ebpf"
+
+	manager "github.com/DataDog/ebpf-manager"
+
+	"github.com/DataDog/datadog-agent/pkg/network/protocols/http"
+	libtelemetry "github.com/DataDog/datadog-agent/pkg/network/protocols/telemetry"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	httpSamplingInterval = 10 * time.Second
+	// httpSamplingMinRate is the lowest sampling rate, keeping 1 transaction out of 64
+	httpSamplingMinRate = http.HTTPSamplingScale / 64
+)
+
+// eventCounter counts the events read from eBPF, such as events.Consumer
+type eventCounter interface {
+	Counts() (captured int64, dropped int64)
+}
+
+// httpSampler adjusts the sampling rate of the HTTP transactions in eBPF, to bound the CPU spent on the transactions
+// of very hot hosts. The rate is halved whenever the ratio of the transactions dropped on their way to userspace
+// during an interval exceeds the threshold, and doubled back once no transaction is dropped. The rates are powers of
+// two, so that the counts of the sampled transactions are scaled back up exactly.
+type httpSampler struct {
+	rateMap   *ebpf.Map
+	threshold float64
+	events    eventCounter
+
+	rate         uint32
+	lastCaptured int64
+	lastDropped  int64
+	rateMetric   *libtelemetry.Metric
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+func newHTTPSampler(mgr *manager.Manager, threshold float64) (*httpSampler, error) {
+	rateMap, _, err := mgr.GetMap(httpSamplingRateMap)
+	if err != nil {
+		return nil, fmt.Errorf("could not find map %s: %w", httpSamplingRateMap, err)
+	}
+
+	metricGroup := libtelemetry.NewMetricGroup(
+		"usm.http_sampling",
+		libtelemetry.OptStatsd,
+		libtelemetry.OptExpvar,
+	)
+
+	s := &httpSampler{
+		rateMap:    rateMap,
+		threshold:  threshold,
+		rate:       http.HTTPSamplingScale,
+		rateMetric: metricGroup.NewMetric("rate"),
+		done:       make(chan struct{}),
+	}
+	s.rateMetric.Set(int64(s.rate))
+	return s, nil
+}
+
+// Start adjusts the sampling rate periodically, from the counts of the given events
+func (s *httpSampler) Start(events eventCounter) {
+	if s == nil {
+		return
+	}
+
+	s.events = events
+	s.lastCaptured, s.lastDropped = events.Counts()
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(httpSamplingInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.update()
+			case <-s.done:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops adjusting the sampling rate
+func (s *httpSampler) Stop() {
+	if s == nil {
+		return
+	}
+	close(s.done)
+	s.wg.Wait()
+}
+
+func (s *httpSampler) update() {
+	captured, dropped := s.events.Counts()
+	capturedDelta, droppedDelta := captured-s.lastCaptured, dropped-s.lastDropped
+	s.lastCaptured, s.lastDropped = captured, dropped
+
+	rate := s.rate
+	if total := capturedDelta + droppedDelta; total > 0 && float64(droppedDelta)/float64(total) > s.threshold {
+		if rate > httpSamplingMinRate {
+			rate /= 2
+		}
+	} else if droppedDelta == 0 && rate < http.HTTPSamplingScale {
+		rate *= 2
+	}
+	if rate == s.rate {
+		return
+	}
+
+	key := uint32(0)
+	if err := s.rateMap.Put(&key, &rate); err != nil {
+		log.Warnf("could not update the sampling rate of the HTTP transactions: %s", err)
+		return
+	}
+	log.Debugf("sampling rate of the HTTP transactions changed from %d to %d out of %d", s.rate, rate, http.HTTPSamplingScale)
+	s.rate = rate
+	s.rateMetric.Set(int64(rate))
+}
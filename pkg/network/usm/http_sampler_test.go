@@ -0,0 +1,76 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux_bpf
+// +build linux_bpf
+
+package usm
+
+import (
+	"testing"
+
+	"github.com/cilium/ebpf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/network/protocols/http"
+	libtelemetry "github.com/DataDog/datadog-agent/pkg/network/protocols/telemetry"
+)
+
+type fakeEventCounter struct {
+	captured, dropped int64
+}
+
+func (c *fakeEventCounter) Counts() (int64, int64) {
+	return c.captured, c.dropped
+}
+
+func TestHTTPSampler(t *testing.T) {
+	rateMap, err := ebpf.NewMap(&ebpf.MapSpec{
+		Type:       ebpf.Array,
+		KeySize:    4,
+		ValueSize:  4,
+		MaxEntries: 1,
+	})
+	if err != nil {
+		t.Skipf("could not create the http_sampling_rate map: %s", err)
+	}
+	t.Cleanup(func() { rateMap.Close() })
+
+	events := &fakeEventCounter{}
+	sampler := &httpSampler{
+		rateMap:    rateMap,
+		threshold:  0.1,
+		events:     events,
+		rate:       http.HTTPSamplingScale,
+		rateMetric: libtelemetry.NewMetricGroup("usm.http_sampling.test").NewMetric("rate"),
+	}
+	rate := func() uint32 {
+		var rate uint32
+		require.NoError(t, rateMap.Lookup(uint32(0), &rate))
+		return rate
+	}
+
+	// drops below the threshold keep the sampling disabled
+	events.captured, events.dropped = 100, 5
+	sampler.update()
+	assert.Zero(t, rate())
+
+	// drops above the threshold halve the rate, down to the minimum
+	for i := 0; i < 10; i++ {
+		events.captured += 100
+		events.dropped += 50
+		sampler.update()
+	}
+	assert.EqualValues(t, httpSamplingMinRate, rate())
+	assert.EqualValues(t, httpSamplingMinRate, sampler.rateMetric.Get())
+
+	// no drops double the rate, until every transaction is kept
+	for i := 0; i < 10; i++ {
+		events.captured += 100
+		sampler.update()
+	}
+	assert.EqualValues(t, http.HTTPSamplingScale, rate())
+}
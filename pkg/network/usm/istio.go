@@ -0,0 +1,187 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux_bpf
+// +build linux_bpf
+
+package usm
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/cilium/ebpf"
+
+	manager "github.com/DataDog/ebpf-manager"
+
+	"github.com/DataDog/datadog-agent/pkg/network/config"
+	"github.com/DataDog/datadog-agent/pkg/network/protocols/http"
+	errtelemetry "github.com/DataDog/datadog-agent/pkg/network/telemetry"
+	"github.com/DataDog/datadog-agent/pkg/process/monitor"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const serviceMeshPIDsMap = "service_mesh_pids"
+
+// envoyBinaryRegex matches the executable of the Envoy proxies injected by Istio as sidecars
+var envoyBinaryRegex = regexp.MustCompile(`/envoy$`)
+
+// envoyProbes are the BoringSSL probes attached to the binary of Envoy, into which BoringSSL is statically linked
+var envoyProbes = newBoringSSLProbes()
+
+// istioProgram hooks the Envoy binaries, so that the service mesh traffic encrypted by the
+// sidecars with mTLS is decoded. Unlike OpenSSL, BoringSSL is statically linked into Envoy,
+// so the hooks are attached to the binary itself once per inode, when the first Envoy process
+// using it is started, and detached when the last one exits.
+//
+// The Envoy processes started by Istio as sidecars, told apart from the standalone Envoy proxies
+// by their command line, are added to the service_mesh_pids map, so that their transactions are
+// tagged as going through the service mesh.
+type istioProgram struct {
+	procRoot string
+	registry *soRegistry
+	rule     soRule
+	manager  *errtelemetry.Manager
+	pidsMap  *ebpf.Map
+
+	// Process monitor channels
+	procMonitor struct {
+		cleanupExec func()
+		cleanupExit func()
+	}
+}
+
+// Static evaluation to make sure we are not breaking the interface.
+var _ subprogram = &istioProgram{}
+
+func newIstioProgram(c *config.Config) *istioProgram {
+	if !c.EnableIstioMonitoring || !c.EnableHTTPSMonitoring || !http.HTTPSSupported(c) {
+		return nil
+	}
+
+	return &istioProgram{
+		procRoot: c.ProcRoot,
+		registry: newSORegistry("istio", c),
+	}
+}
+
+// ConfigureManager sets up the hooks of the Envoy binaries. The eBPF programs are those of the SSL
+// subprogram.
+func (p *istioProgram) ConfigureManager(m *errtelemetry.Manager) {
+	p.manager = m
+	p.rule = soRule{
+		re:           envoyBinaryRegex,
+		registerCB:   withHooksTelemetry(boringSSLLibrary, addHooks(m, envoyProbes)),
+		unregisterCB: removeHooks(m, envoyProbes),
+	}
+}
+
+// ConfigureOptions is a no-op, the options are set by the SSL subprogram
+func (p *istioProgram) ConfigureOptions(*manager.Options) {}
+
+// Start subscribes to the Envoy processes events. It must be called before the process monitor
+// is initialized, for the Envoy processes already running to be hooked.
+func (p *istioProgram) Start() error {
+	var err error
+	p.pidsMap, _, err = p.manager.GetMap(serviceMeshPIDsMap)
+	if err != nil {
+		return fmt.Errorf("could not get %s map: %w", serviceMeshPIDsMap, err)
+	}
+
+	mon := monitor.GetProcessMonitor()
+	p.procMonitor.cleanupExec, err = mon.Subscribe(&monitor.ProcessCallback{
+		Event:    monitor.EXEC,
+		Metadata: monitor.EXE,
+		Regex:    envoyBinaryRegex,
+		Callback: p.handleProcessExec,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to subscribe Exec process monitor: %w", err)
+	}
+	p.procMonitor.cleanupExit, err = mon.Subscribe(&monitor.ProcessCallback{
+		Event:    monitor.EXIT,
+		Metadata: monitor.EXE,
+		Regex:    envoyBinaryRegex,
+		Callback: p.handleProcessExit,
+	})
+	if err != nil {
+		p.procMonitor.cleanupExec()
+		p.procMonitor.cleanupExec = nil
+		return fmt.Errorf("failed to subscribe Exit process monitor: %w", err)
+	}
+
+	log.Info("istio monitoring is enabled")
+	return nil
+}
+
+// Name returns the name of the subprogram, as reported in the usm status
+func (*istioProgram) Name() string {
+	return "istio"
+}
+
+// EBPFFunctions returns the eBPF functions of the subprogram, which is dropped when they can't be loaded
+func (*istioProgram) EBPFFunctions() []string {
+	return probesFunctions(envoyProbes)
+}
+
+// Stop unsubscribes from the process monitor and detaches the hooks of all the Envoy binaries
+func (p *istioProgram) Stop() {
+	if p.procMonitor.cleanupExec != nil {
+		p.procMonitor.cleanupExec()
+	}
+	if p.procMonitor.cleanupExit != nil {
+		p.procMonitor.cleanupExit()
+	}
+	p.registry.cleanup()
+}
+
+func (p *istioProgram) handleProcessExec(pid uint32) {
+	procPid := filepath.Join(p.procRoot, strconv.FormatUint(uint64(pid), 10))
+	binPath, err := os.Readlink(filepath.Join(procPid, "exe"))
+	if err != nil {
+		// the process already exited
+		return
+	}
+
+	// the binary path is relative to the process' mount namespace
+	p.registry.register(filepath.Join(procPid, "root"), binPath, pid, p.rule)
+
+	if p.pidsMap == nil {
+		return
+	}
+	cmdline, err := os.ReadFile(filepath.Join(procPid, "cmdline"))
+	if err != nil || !isIstioSidecar(strings.Split(string(bytes.TrimRight(cmdline, "\x00")), "\x00")) {
+		return
+	}
+	value := uint8(1)
+	if err := p.pidsMap.Put(&pid, &value); err != nil {
+		log.Debugf("could not mark the envoy process %d as a service mesh sidecar: %s", pid, err)
+	}
+}
+
+func (p *istioProgram) handleProcessExit(pid uint32) {
+	p.registry.unregister(pid)
+	if p.pidsMap != nil {
+		// the process is most likely not a sidecar, in which case it isn't in the map
+		_ = p.pidsMap.Delete(&pid)
+	}
+}
+
+// isIstioSidecar returns whether the command line of an Envoy process is the one of an Istio sidecar, which is
+// started by the pilot-agent with the bootstrap configuration it generates under /etc/istio/proxy, and, by the
+// older versions of Istio, with a `sidecar~` service node
+func isIstioSidecar(args []string) bool {
+	for _, arg := range args {
+		if strings.Contains(arg, "istio/proxy/envoy-rev") || strings.HasPrefix(arg, "sidecar~") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,174 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux_bpf
+// +build linux_bpf
+
+package usm
+
+import (
+	"io"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/cilium/ebpf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
+
+	"github.com/DataDog/datadog-agent/pkg/network/config"
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+)
+
+func TestEnvoyBinaryRegex(t *testing.T) {
+	assert.True(t, envoyBinaryRegex.MatchString("/usr/local/bin/envoy"))
+	assert.False(t, envoyBinaryRegex.MatchString("/usr/local/bin/pilot-agent"))
+	assert.False(t, envoyBinaryRegex.MatchString("/usr/local/bin/envoy-wrapper"))
+	assert.False(t, envoyBinaryRegex.MatchString("/usr/local/bin/not-envoy"))
+}
+
+func TestIstioMonitor(t *testing.T) {
+	envoyPath := copyAsEnvoy(t, "/bin/sleep")
+	envoyPathID, err := newPathIdentifier(envoyPath)
+	require.NoError(t, err)
+
+	registered := atomic.NewInt32(0)
+	unregistered := atomic.NewInt32(0)
+	m := &istioProgram{
+		procRoot: util.GetProcRoot(),
+		registry: newSORegistry("test", config.New()),
+		rule: soRule{
+			re: envoyBinaryRegex,
+			registerCB: func(id pathIdentifier, root string, path string) error {
+				assert.Equal(t, envoyPathID, id)
+				assert.Equal(t, envoyPath, path)
+				registered.Inc()
+				return nil
+			},
+			unregisterCB: func(id pathIdentifier) error {
+				assert.Equal(t, envoyPathID, id)
+				unregistered.Inc()
+				return nil
+			},
+		},
+	}
+
+	// the process monitor calls back the istio monitor with the Envoy processes events, the hooks
+	// are attached once for all the processes running the same binary
+	envoy1 := startSleepingBinary(t, envoyPath)
+	envoy2 := startSleepingBinary(t, envoyPath)
+	m.handleProcessExec(envoy1)
+	m.handleProcessExec(envoy2)
+	assert.True(t, checkIstioPIDAssociatedWithPathID(m, envoyPathID, envoy1))
+	assert.True(t, checkIstioPIDAssociatedWithPathID(m, envoyPathID, envoy2))
+	assert.Equal(t, int32(1), registered.Load())
+
+	// the hooks are detached once all the Envoy processes exited
+	m.handleProcessExit(envoy1)
+	assert.False(t, checkIstioPIDAssociatedWithPathID(m, envoyPathID, envoy1))
+	assert.Equal(t, int32(0), unregistered.Load())
+
+	m.handleProcessExit(envoy2)
+	assert.Equal(t, int32(1), unregistered.Load())
+	assert.Empty(t, m.registry.byID)
+	assert.Empty(t, m.registry.byPID)
+
+	// processes exiting before being registered are ignored
+	m.handleProcessExec(math.MaxInt32)
+	assert.Equal(t, int32(1), registered.Load())
+}
+
+func TestIsIstioSidecar(t *testing.T) {
+	assert.True(t, isIstioSidecar([]string{"/usr/local/bin/envoy", "-c", "etc/istio/proxy/envoy-rev.json", "--drain-time-s", "45"}))
+	assert.True(t, isIstioSidecar([]string{"/usr/local/bin/envoy", "-c", "/etc/istio/proxy/envoy-rev0.json"}))
+	assert.True(t, isIstioSidecar([]string{"/usr/local/bin/envoy", "--service-node", "sidecar~10.0.0.1~app.default~default.svc.cluster.local"}))
+	assert.False(t, isIstioSidecar([]string{"/usr/local/bin/envoy", "-c", "/etc/envoy/envoy.yaml"}))
+	assert.False(t, isIstioSidecar([]string{"/usr/local/bin/envoy", "--service-node", "router~10.0.0.1~gateway.istio-system~istio-system.svc.cluster.local"}))
+}
+
+func TestIstioServiceMeshPIDs(t *testing.T) {
+	pidsMap, err := ebpf.NewMap(&ebpf.MapSpec{
+		Type:       ebpf.Hash,
+		KeySize:    4,
+		ValueSize:  1,
+		MaxEntries: 16,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { pidsMap.Close() })
+
+	m := &istioProgram{
+		procRoot: util.GetProcRoot(),
+		registry: newSORegistry("test", config.New()),
+		pidsMap:  pidsMap,
+		rule: soRule{
+			re:           envoyBinaryRegex,
+			registerCB:   func(pathIdentifier, string, string) error { return nil },
+			unregisterCB: func(pathIdentifier) error { return nil },
+		},
+	}
+
+	// a copy of the shell named envoy, whose command line is the one of a sidecar or of a standalone proxy
+	envoyPath := copyAsEnvoy(t, "/bin/sh")
+	startEnvoy := func(args ...string) uint32 {
+		cmd := exec.Command(envoyPath, append([]string{"-c", "sleep 30; true"}, args...)...)
+		require.NoError(t, cmd.Start())
+		registerProcessTerminationUponCleanup(t, cmd)
+		return uint32(cmd.Process.Pid)
+	}
+	isMarked := func(pid uint32) bool {
+		var value uint8
+		return pidsMap.Lookup(&pid, &value) == nil
+	}
+
+	sidecar := startEnvoy("sidecar~10.0.0.1~app.default~default.svc.cluster.local")
+	standalone := startEnvoy("router")
+	m.handleProcessExec(sidecar)
+	m.handleProcessExec(standalone)
+	assert.True(t, isMarked(sidecar))
+	assert.False(t, isMarked(standalone))
+
+	m.handleProcessExit(sidecar)
+	m.handleProcessExit(standalone)
+	assert.False(t, isMarked(sidecar))
+}
+
+// startSleepingBinary runs the given copy of sleep for 30 seconds
+func startSleepingBinary(t *testing.T, path string) uint32 {
+	cmd := exec.Command(path, "30")
+	require.NoError(t, cmd.Start())
+	registerProcessTerminationUponCleanup(t, cmd)
+	return uint32(cmd.Process.Pid)
+}
+
+// copyAsEnvoy copies the given binary to a temporary `envoy` file
+func copyAsEnvoy(t *testing.T, path string) string {
+	return copyBinaryAs(t, path, "envoy")
+}
+
+// copyBinaryAs copies the given binary to a temporary file with the given name
+func copyBinaryAs(t *testing.T, path string, name string) string {
+	src, err := os.Open(path)
+	require.NoError(t, err)
+	defer src.Close()
+
+	dstPath := filepath.Join(t.TempDir(), name)
+	dst, err := os.OpenFile(dstPath, os.O_CREATE|os.O_WRONLY, 0755)
+	require.NoError(t, err)
+	defer dst.Close()
+
+	_, err = io.Copy(dst, src)
+	require.NoError(t, err)
+	return dstPath
+}
+
+func checkIstioPIDAssociatedWithPathID(m *istioProgram, pathID pathIdentifier, pid uint32) bool {
+	m.registry.m.RLock()
+	defer m.registry.m.RUnlock()
+	_, ok := m.registry.byPID[pid][pathID]
+	return ok
+}
@@ -0,0 +1,149 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux_bpf
+// +build linux_bpf
+
+package usm
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+
+	ddebpf "github.com/DataDog/datadog-agent/pkg/ebpf"
+	"github.com/DataDog/datadog-agent/pkg/network/protocols/http"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// pidTGIDArgsMaps are the maps holding the arguments of the SSL calls in flight, keyed by pid_tgid,
+// with the type of their values.
+var pidTGIDArgsMaps = []struct {
+	name  string
+	value interface{}
+}{
+	{name: sslReadArgsMap, value: new(http.SslReadArgs)},
+	{name: bioNewSocketArgsMap, value: new(uint32)},
+	{name: sslCtxByPIDTGIDMap, value: new(uintptr)}, // C.void *
+}
+
+// isTransactionStale returns true when a transaction was last updated more than ttl nanoseconds ago.
+func isTransactionStale(now, ttl int64, responseLastSeen, requestStarted uint64) bool {
+	if updated := int64(responseLastSeen); updated > 0 {
+		return (now - updated) > ttl
+	}
+
+	started := int64(requestStarted)
+	return started > 0 && (now-started) > ttl
+}
+
+func (e *ebpfProgram) setupHTTP2MapCleaner() *ddebpf.MapCleaner {
+	http2Map, _, err := e.GetMap(http2InFlightMap)
+	if err != nil {
+		log.Errorf("error getting %s map: %s", http2InFlightMap, err)
+		return nil
+	}
+	http2MapCleaner, err := ddebpf.NewMapCleaner(http2Map, new(http.HTTP2StreamKey), new(http.EbpfHttp2Tx))
+	if err != nil {
+		log.Errorf("error creating map cleaner: %s", err)
+		return nil
+	}
+
+	ttl := e.cfg.HTTPIdleConnectionTTL.Nanoseconds()
+	http2MapCleaner.Clean(e.cfg.HTTPMapCleanerInterval, func(now int64, key, val interface{}) bool {
+		http2Txn, ok := val.(*http.EbpfHttp2Tx)
+		if !ok {
+			return false
+		}
+
+		return isTransactionStale(now, ttl, http2Txn.ResponseLastSeen(), http2Txn.RequestStarted())
+	})
+
+	return http2MapCleaner
+}
+
+// setupPIDTGIDMapCleaner starts the cleaner of a map holding the arguments of the SSL calls in flight.
+func (e *ebpfProgram) setupPIDTGIDMapCleaner(mapName string, value interface{}) *ddebpf.MapCleaner {
+	m, _, err := e.GetMap(mapName)
+	if err != nil {
+		log.Errorf("error getting %s map: %s", mapName, err)
+		return nil
+	}
+	mapCleaner, err := ddebpf.NewMapCleaner(m, new(uint64), value)
+	if err != nil {
+		log.Errorf("error creating map cleaner: %s", err)
+		return nil
+	}
+
+	tracker := newPIDTGIDEntryTracker(e.cfg.ProcRoot, e.cfg.HTTPIdleConnectionTTL.Nanoseconds())
+	mapCleaner.Clean(e.cfg.HTTPMapCleanerInterval, func(now int64, key, _ interface{}) bool {
+		pidTGID, ok := key.(*uint64)
+		if !ok {
+			return false
+		}
+		return tracker.isStale(now, *pidTGID)
+	})
+
+	return mapCleaner
+}
+
+// pidTGIDEntryTracker tracks the age of the entries of a map keyed by pid_tgid, whose values hold no timestamp.
+// The age of an entry is counted from the first cleaner pass it is seen in. As a thread may block in SSL_read
+// on an idle connection for longer than the TTL, an entry is only stale once it is older than the TTL and its
+// thread exited. It isn't safe for concurrent use, the passes of a MapCleaner being sequential.
+type pidTGIDEntryTracker struct {
+	procRoot string
+	ttl      int64
+
+	// pass is the timestamp of the current cleaner pass
+	pass      int64
+	firstSeen map[uint64]int64
+	lastSeen  map[uint64]int64
+}
+
+func newPIDTGIDEntryTracker(procRoot string, ttl int64) *pidTGIDEntryTracker {
+	return &pidTGIDEntryTracker{
+		procRoot:  procRoot,
+		ttl:       ttl,
+		firstSeen: make(map[uint64]int64),
+		lastSeen:  make(map[uint64]int64),
+	}
+}
+
+func (t *pidTGIDEntryTracker) isStale(now int64, pidTGID uint64) bool {
+	if now != t.pass {
+		// a new pass started, forget the entries deleted from the map since the previous one
+		for key, lastSeen := range t.lastSeen {
+			if lastSeen != t.pass {
+				delete(t.firstSeen, key)
+				delete(t.lastSeen, key)
+			}
+		}
+		t.pass = now
+	}
+
+	t.lastSeen[pidTGID] = now
+	firstSeen, ok := t.firstSeen[pidTGID]
+	if !ok {
+		t.firstSeen[pidTGID] = now
+		return false
+	}
+	if now-firstSeen <= t.ttl || t.threadExists(pidTGID) {
+		return false
+	}
+
+	delete(t.firstSeen, pidTGID)
+	delete(t.lastSeen, pidTGID)
+	return true
+}
+
+// threadExists returns true if the thread of the pid_tgid key, whose upper 32 bits are the process ID and
+// lower 32 bits the thread ID, is still running.
+func (t *pidTGIDEntryTracker) threadExists(pidTGID uint64) bool {
+	pid := strconv.FormatUint(pidTGID>>32, 10)
+	tid := strconv.FormatUint(pidTGID&0xffffffff, 10)
+	_, err := os.Stat(filepath.Join(t.procRoot, pid, "task", tid))
+	return err == nil
+}
@@ -0,0 +1,58 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux_bpf
+// +build linux_bpf
+
+package usm
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsTransactionStale(t *testing.T) {
+	ttl := (30 * time.Second).Nanoseconds()
+	now := (time.Hour).Nanoseconds()
+
+	assert.False(t, isTransactionStale(now, ttl, 0, 0))
+	assert.False(t, isTransactionStale(now, ttl, 0, uint64(now-ttl/2)))
+	assert.True(t, isTransactionStale(now, ttl, 0, uint64(now-2*ttl)))
+	// the last response seen takes precedence over the start of the request
+	assert.False(t, isTransactionStale(now, ttl, uint64(now-ttl/2), uint64(now-2*ttl)))
+	assert.True(t, isTransactionStale(now, ttl, uint64(now-2*ttl), uint64(now-3*ttl)))
+}
+
+func TestPIDTGIDEntryTracker(t *testing.T) {
+	procRoot := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(procRoot, "42", "task", "43"), 0755))
+
+	const ttl = 10
+	aliveThread := uint64(42)<<32 | 43
+	exitedThread := uint64(42)<<32 | 44
+	tracker := newPIDTGIDEntryTracker(procRoot, ttl)
+
+	// the entries are tracked from the first pass they are seen in
+	assert.False(t, tracker.isStale(100, aliveThread))
+	assert.False(t, tracker.isStale(100, exitedThread))
+	assert.False(t, tracker.isStale(105, aliveThread))
+	assert.False(t, tracker.isStale(105, exitedThread))
+
+	// past the TTL, only the entries of the exited threads are stale
+	assert.False(t, tracker.isStale(120, aliveThread))
+	assert.True(t, tracker.isStale(120, exitedThread))
+	assert.NotContains(t, tracker.firstSeen, exitedThread)
+
+	// an entry missing from a pass is forgotten, and tracked again from scratch
+	assert.False(t, tracker.isStale(130, exitedThread))
+	assert.False(t, tracker.isStale(140, exitedThread))
+	assert.NotContains(t, tracker.firstSeen, aliveThread)
+	assert.True(t, tracker.isStale(150, exitedThread))
+}
@@ -0,0 +1,207 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux_bpf
+// +build linux_bpf
+
+package usm
+
+import (
+	"errors"
+	"sync"
+	"time"
+	"unsafe"
+
+	"github.com/DataDog/datadog-go/v5/statsd"
+	"github.com/cilium/ebpf"
+
+	"github.com/DataDog/datadog-agent/pkg/network/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	mapUsageSamplingInterval = 30 * time.Second
+	mapUsageMetric           = "datadog.system_probe.usm.map_usage"
+)
+
+// mapUsage is the utilization of an eBPF map at the time it was last sampled
+type mapUsage struct {
+	Protocol   string  `json:"protocol"`
+	Entries    uint32  `json:"entries"`
+	MaxEntries uint32  `json:"max_entries"`
+	Usage      float64 `json:"usage"`
+}
+
+type sampledMap struct {
+	name     string
+	protocol string
+	m        *ebpf.Map
+}
+
+// mapUsageSampler periodically counts the entries of the hash maps of USM, to give visibility on how full they are
+// relatively to their maximum number of entries, most of them being sized by MaxTrackedConnections.
+//
+// There is no syscall returning the number of entries of a map, so the keys are walked one by one. The sampling
+// interval is thus kept long, and each map is walked at most MaxEntries times.
+type mapUsageSampler struct {
+	maps   []sampledMap
+	client statsd.ClientInterface
+
+	mux   sync.Mutex
+	usage map[string]mapUsage
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// usmMaps returns the names of the hash maps of each protocol enabled in the configuration
+func usmMaps(c *config.Config) map[string][]string {
+	maps := map[string][]string{
+		"dispatcher": {connectionStatesMap},
+		httpProtocol: {httpInFlightMap, httpCgroupIDByTupleMap},
+	}
+	if c.EnableHTTP2Monitoring {
+		maps[http2Protocol] = []string{http2InFlightMap, "http2_dynamic_table", "http2_dynamic_counter_table", "http2_iterations"}
+	}
+	if c.EnableKafkaMonitoring {
+		maps[kafkaProtocol] = []string{kafkaLastTCPSeqPerConnectionMap}
+	}
+	if c.EnableUSMCgroupAttach {
+		maps["cgroup_attach"] = []string{usmCgroupTuplesMap}
+	}
+	if c.EnableDNSMonitoring {
+		maps[dnsProtocol] = []string{dnsInFlightMap}
+	}
+	if c.EnableHTTPSMonitoring {
+		maps["tls"] = []string{tlsInfoByTupleMap, sslSockByCtxMap, sslReadArgsMap, bioNewSocketArgsMap, fdBySSLBioMap, sslCtxByPIDTGIDMap}
+		if c.EnableIstioMonitoring {
+			maps["tls"] = append(maps["tls"], serviceMeshPIDsMap)
+		}
+	}
+	return maps
+}
+
+func newMapUsageSampler(c *config.Config, e *ebpfProgram, client statsd.ClientInterface) *mapUsageSampler {
+	s := &mapUsageSampler{
+		client: client,
+		usage:  make(map[string]mapUsage),
+		done:   make(chan struct{}),
+	}
+
+	for protocol, names := range usmMaps(c) {
+		for _, name := range names {
+			m, _, err := e.GetMap(name)
+			if err != nil || m == nil {
+				log.Debugf("could not sample the usage of map %s: %s", name, err)
+				continue
+			}
+			s.maps = append(s.maps, sampledMap{name: name, protocol: protocol, m: m})
+		}
+	}
+	return s
+}
+
+// Start samples the usage of the maps periodically
+func (s *mapUsageSampler) Start() {
+	if s == nil {
+		return
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(mapUsageSamplingInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.sample()
+			case <-s.done:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops sampling the usage of the maps
+func (s *mapUsageSampler) Stop() {
+	if s == nil {
+		return
+	}
+	close(s.done)
+	s.wg.Wait()
+}
+
+// Usage returns the usage of each map at the time it was last sampled
+func (s *mapUsageSampler) Usage() map[string]mapUsage {
+	if s == nil {
+		return nil
+	}
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	usage := make(map[string]mapUsage, len(s.usage))
+	for name, u := range s.usage {
+		usage[name] = u
+	}
+	return usage
+}
+
+func (s *mapUsageSampler) sample() {
+	usage := make(map[string]mapUsage, len(s.maps))
+	for _, sm := range s.maps {
+		entries, err := countEntries(sm.m)
+		if err != nil {
+			log.Debugf("could not count the entries of map %s: %s", sm.name, err)
+			continue
+		}
+
+		u := mapUsage{
+			Protocol:   sm.protocol,
+			Entries:    entries,
+			MaxEntries: sm.m.MaxEntries(),
+		}
+		if u.MaxEntries > 0 {
+			u.Usage = float64(u.Entries) / float64(u.MaxEntries)
+		}
+		usage[sm.name] = u
+
+		if s.client == nil {
+			continue
+		}
+		tags := []string{"map:" + sm.name, "protocol:" + sm.protocol}
+		if err := s.client.Gauge(mapUsageMetric, u.Usage, tags, 1.0); err != nil && !errors.Is(err, statsd.ErrNoClient) {
+			log.Debugf("error submitting the usage of map %s to statsd: %s", sm.name, err)
+		}
+	}
+
+	s.mux.Lock()
+	s.usage = usage
+	s.mux.Unlock()
+}
+
+// countEntries returns the number of entries of a map, by walking its keys. The walk restarts from the first key
+// when the current one is deleted concurrently, so it is bounded by the maximum number of entries of the map.
+func countEntries(m *ebpf.Map) (uint32, error) {
+	maxEntries := m.MaxEntries()
+	key := make([]byte, m.KeySize())
+	nextKey := make([]byte, m.KeySize())
+
+	var count uint32
+	var prev interface{}
+	for count < maxEntries {
+		err := m.NextKey(prev, unsafe.Pointer(&nextKey[0]))
+		if errors.Is(err, ebpf.ErrKeyNotExist) {
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+		count++
+		key, nextKey = nextKey, key
+		prev = key
+	}
+	return count, nil
+}
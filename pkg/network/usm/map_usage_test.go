@@ -0,0 +1,49 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux_bpf
+// +build linux_bpf
+
+package usm
+
+import (
+	"testing"
+
+	"github.com/cilium/ebpf"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCountEntries(t *testing.T) {
+	m, err := ebpf.NewMap(&ebpf.MapSpec{
+		Type:       ebpf.Hash,
+		KeySize:    4,
+		ValueSize:  4,
+		MaxEntries: 8,
+	})
+	if err != nil {
+		t.Skipf("could not create map: %s", err)
+	}
+	t.Cleanup(func() { m.Close() })
+
+	count, err := countEntries(m)
+	require.NoError(t, err)
+	require.Zero(t, count)
+
+	for i := uint32(0); i < 5; i++ {
+		require.NoError(t, m.Put(i, i))
+	}
+	count, err = countEntries(m)
+	require.NoError(t, err)
+	require.Equal(t, uint32(5), count)
+
+	s := &mapUsageSampler{
+		maps:  []sampledMap{{name: "test", protocol: httpProtocol, m: m}},
+		usage: make(map[string]mapUsage),
+	}
+	s.sample()
+	require.Equal(t, map[string]mapUsage{
+		"test": {Protocol: httpProtocol, Entries: 5, MaxEntries: 8, Usage: 0.625},
+	}, s.Usage())
+}
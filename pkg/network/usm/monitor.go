@@ -16,17 +16,23 @@ import (
 
 	"github.com/DataDog/datadog-agent/pkg/network/ebpf/probes"
 
+	"github.com/DataDog/datadog-go/v5/statsd"
 	"github.com/cilium/ebpf"
 
 	manager "github.com/DataDog/ebpf-manager"
 
+	ddebpf "github.com/DataDog/datadog-agent/pkg/ebpf"
+	"github.com/DataDog/datadog-agent/pkg/network"
 	"github.com/DataDog/datadog-agent/pkg/network/config"
 	filterpkg "github.com/DataDog/datadog-agent/pkg/network/filter"
+	"github.com/DataDog/datadog-agent/pkg/network/protocols/dns"
 	"github.com/DataDog/datadog-agent/pkg/network/protocols/events"
 	"github.com/DataDog/datadog-agent/pkg/network/protocols/http"
+	httpdebugging "github.com/DataDog/datadog-agent/pkg/network/protocols/http/debugging"
 	"github.com/DataDog/datadog-agent/pkg/network/protocols/kafka"
 	errtelemetry "github.com/DataDog/datadog-agent/pkg/network/telemetry"
 	"github.com/DataDog/datadog-agent/pkg/process/monitor"
+	processstatsd "github.com/DataDog/datadog-agent/pkg/process/statsd"
 	"github.com/DataDog/datadog-agent/pkg/util/kernel"
 )
 
@@ -56,28 +62,33 @@ type Monitor struct {
 	httpStatkeeper  *http.HttpStatKeeper
 	http2Statkeeper *http.HttpStatKeeper
 	processMonitor  *monitor.ProcessMonitor
+	mapUsage        *mapUsageSampler
+	staticTable     *staticTableLoader
+	statesEvictor   *connectionStatesEvictor
+	httpSampler     *httpSampler
 
 	http2Enabled   bool
 	httpTLSEnabled bool
 
+	// httpBatchOptions batches the HTTP events when its size is set
+	httpBatchOptions events.BatchOptions
+
 	// Kafka related
 	kafkaEnabled    bool
 	kafkaConsumer   *events.Consumer
 	kafkaTelemetry  *kafka.Telemetry
 	kafkaStatkeeper *kafka.KafkaStatKeeper
+
+	// DNS related
+	dnsEnabled    bool
+	dnsConsumer   *events.Consumer
+	dnsTelemetry  *dns.Telemetry
+	dnsStatkeeper *dns.StatKeeper
+
 	// termination
 	closeFilterFn func()
 }
 
-// The staticTableEntry represents an entry in the static table that contains an index in the table and a value.
-// The value itself contains both the key and the corresponding value in the static table.
-// For instance, index 2 in the static table has a value of method: GET, and index 3 has a value of method: POST.
-// It is not possible to save the index by the key because we need to distinguish between the values attached to the key.
-type staticTableEntry struct {
-	Index uint64
-	Value http.StaticTableValue
-}
-
 // NewMonitor returns a new Monitor instance
 func NewMonitor(c *config.Config, offsets []manager.ConstantEditor, connectionProtocolMap, sockFD *ebpf.Map, bpfTelemetry *errtelemetry.EBPFTelemetry) (m *Monitor, err error) {
 	defer func() {
@@ -114,9 +125,11 @@ func NewMonitor(c *config.Config, offsets []manager.ConstantEditor, connectionPr
 		return nil, fmt.Errorf("error initializing http ebpf program: %w", err)
 	}
 
+	var staticTable *staticTableLoader
 	if c.EnableHTTP2Monitoring {
-		err := m.createStaticTable(mgr)
-		if err != nil {
+		staticTable = newStaticTableLoader(http2StaticTableEntries)
+		m, _, _ := mgr.GetMap(probes.StaticTableMap)
+		if err := staticTable.Load(m); err != nil {
 			return nil, fmt.Errorf("error creating a static table for http2 monitoring: %w", err)
 		}
 	}
@@ -126,6 +139,19 @@ func NewMonitor(c *config.Config, offsets []manager.ConstantEditor, connectionPr
 		return nil, fmt.Errorf("error retrieving socket filter")
 	}
 
+	statesEvictor, err := newConnectionStatesEvictor(mgr.Manager.Manager)
+	if err != nil {
+		return nil, err
+	}
+
+	var sampler *httpSampler
+	if c.EnableHTTPSampling {
+		sampler, err = newHTTPSampler(mgr.Manager.Manager, c.HTTPSamplingDropThreshold)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	closeFilterFn, err := filterpkg.HeadlessSocketFilter(c, filter)
 	if err != nil {
 		return nil, fmt.Errorf("error enabling HTTP traffic inspection: %s", err)
@@ -152,6 +178,11 @@ func NewMonitor(c *config.Config, offsets []manager.ConstantEditor, connectionPr
 		http2Statkeeper = http.NewHTTPStatkeeper(c, http2Telemetry)
 	}
 
+	var statsdClient statsd.ClientInterface
+	if processstatsd.Client != nil {
+		statsdClient = processstatsd.Client
+	}
+
 	state = Running
 
 	httpMonitor := &Monitor{
@@ -164,6 +195,14 @@ func NewMonitor(c *config.Config, offsets []manager.ConstantEditor, connectionPr
 		http2Enabled:    c.EnableHTTP2Monitoring,
 		http2Statkeeper: http2Statkeeper,
 		httpTLSEnabled:  c.EnableHTTPSMonitoring,
+		mapUsage:        newMapUsageSampler(c, mgr, statsdClient),
+		staticTable:     staticTable,
+		statesEvictor:   statesEvictor,
+		httpSampler:     sampler,
+		httpBatchOptions: events.BatchOptions{
+			Size:          c.HTTPEventsBatchSize,
+			FlushInterval: c.HTTPEventsFlushInterval,
+		},
 	}
 
 	if c.EnableKafkaMonitoring {
@@ -175,6 +214,13 @@ func NewMonitor(c *config.Config, offsets []manager.ConstantEditor, connectionPr
 		httpMonitor.kafkaStatkeeper = kafkaStatkeeper
 	}
 
+	if c.EnableDNSMonitoring {
+		dnsTelemetry := dns.NewTelemetry()
+		httpMonitor.dnsEnabled = true
+		httpMonitor.dnsTelemetry = dnsTelemetry
+		httpMonitor.dnsStatkeeper = dns.NewStatkeeper(c, dnsTelemetry)
+	}
+
 	return httpMonitor, nil
 }
 
@@ -199,15 +245,25 @@ func (m *Monitor) Start() error {
 		}
 	}()
 
-	m.httpConsumer, err = events.NewConsumer(
-		"http",
-		m.ebpfProgram.Manager.Manager,
-		m.processHTTP,
-	)
+	if m.httpBatchOptions.Size > 0 {
+		m.httpConsumer, err = events.NewBatchConsumer(
+			"http",
+			m.ebpfProgram.Manager.Manager,
+			m.httpStatkeeper.ProcessBatch,
+			m.httpBatchOptions,
+		)
+	} else {
+		m.httpConsumer, err = events.NewConsumer(
+			"http",
+			m.ebpfProgram.Manager.Manager,
+			m.processHTTP,
+		)
+	}
 	if err != nil {
 		return err
 	}
 	m.httpConsumer.Start()
+	m.httpSampler.Start(m.httpConsumer)
 
 	if m.http2Enabled {
 		m.http2Consumer, err = events.NewConsumer(
@@ -233,10 +289,23 @@ func (m *Monitor) Start() error {
 		m.kafkaConsumer.Start()
 	}
 
+	if m.dnsEnabled {
+		m.dnsConsumer, err = events.NewConsumer(
+			"dns",
+			m.ebpfProgram.Manager.Manager,
+			m.dnsProcess,
+		)
+		if err != nil {
+			return err
+		}
+		m.dnsConsumer.Start()
+	}
+
 	err = m.ebpfProgram.Start()
 	if err != nil {
 		return err
 	}
+	m.mapUsage.Start()
 
 	// Need to explicitly save the error in `err` so the defer function could save the startup error.
 	if m.httpTLSEnabled {
@@ -256,6 +325,15 @@ func (m *Monitor) GetUSMStats() map[string]interface{} {
 
 	if m != nil {
 		response["last_check"] = m.httpTelemetry.LastCheck.Load()
+		response["protocols"] = m.GetProtocols()
+		response["map_usage"] = m.mapUsage.Usage()
+		response["subprograms"] = m.ebpfProgram.getSubprogramsStatus()
+		if diagnostics, ok := m.ebpfProgram.GetLastLoadDiagnostics(); ok {
+			response["load_diagnostics"] = diagnostics
+		}
+		if m.http2Enabled {
+			response["http2_static_table"] = m.staticTable.Status()
+		}
 	}
 	return response
 }
@@ -295,6 +373,36 @@ func (m *Monitor) GetKafkaStats() map[kafka.Key]*kafka.RequestStat {
 	return m.kafkaStatkeeper.GetAndResetAllStats()
 }
 
+// GetDNSStats returns a map of the DNS stats of the queries sent over UDP, by connection and query type
+func (m *Monitor) GetDNSStats() map[dns.Key]*dns.RequestStat {
+	if m == nil || !m.dnsEnabled {
+		return nil
+	}
+
+	m.dnsConsumer.Sync()
+	m.dnsTelemetry.Log()
+	return m.dnsStatkeeper.GetAndResetAllStats()
+}
+
+// HandleClosedConnections evicts the state kept on eBPF side for the connections closed by the tracer, so that it
+// doesn't apply to the new connections reusing their tuples
+func (m *Monitor) HandleClosedConnections(conns []network.ConnectionStats) {
+	if m == nil {
+		return
+	}
+
+	m.statesEvictor.evictClosed(conns)
+}
+
+// HandleExpiredConnections evicts the state kept on eBPF side for the connections expired by the tracer
+func (m *Monitor) HandleExpiredConnections(conns []network.ConnectionStats) {
+	if m == nil {
+		return
+	}
+
+	m.statesEvictor.evictExpired(conns)
+}
+
 // Stop HTTP monitoring
 func (m *Monitor) Stop() {
 	if m == nil {
@@ -302,6 +410,8 @@ func (m *Monitor) Stop() {
 	}
 
 	m.processMonitor.Stop()
+	m.mapUsage.Stop()
+	m.httpSampler.Stop()
 	m.ebpfProgram.Close()
 
 	m.httpConsumer.Stop()
@@ -311,6 +421,9 @@ func (m *Monitor) Stop() {
 	if m.kafkaEnabled {
 		m.kafkaConsumer.Stop()
 	}
+	if m.dnsEnabled {
+		m.dnsConsumer.Stop()
+	}
 	m.closeFilterFn()
 }
 
@@ -333,103 +446,34 @@ func (m *Monitor) kafkaProcess(data []byte) {
 	m.kafkaStatkeeper.Process(tx)
 }
 
-// DumpMaps dumps the maps associated with the monitor
-func (m *Monitor) DumpMaps(maps ...string) (string, error) {
-	return m.ebpfProgram.DumpMaps(maps...)
+func (m *Monitor) dnsProcess(data []byte) {
+	tx := (*dns.EbpfDNSTx)(unsafe.Pointer(&data[0]))
+	m.dnsTelemetry.Count(tx)
+	m.dnsStatkeeper.Process(tx)
 }
 
-// createStaticTable creates a static table for http2 monitor.
-func (m *Monitor) createStaticTable(mgr *ebpfProgram) error {
-	staticTable, _, _ := mgr.GetMap(probes.StaticTableMap)
-	if staticTable == nil {
-		return errors.New("http2 static table is null")
-	}
-	staticTableEntries := []staticTableEntry{
-		{
-			Index: 2,
-			Value: http.StaticTableValue{
-				Key:   http.MethodKey,
-				Value: http.GetValue,
-			},
-		},
-		{
-			Index: 3,
-			Value: http.StaticTableValue{
-				Key:   http.MethodKey,
-				Value: http.PostValue,
-			},
-		},
-		{
-			Index: 4,
-			Value: http.StaticTableValue{
-				Key:   http.PathKey,
-				Value: http.EmptyPathValue,
-			},
-		},
-		{
-			Index: 5,
-			Value: http.StaticTableValue{
-				Key:   http.PathKey,
-				Value: http.IndexPathValue,
-			},
-		},
-		{
-			Index: 8,
-			Value: http.StaticTableValue{
-				Key:   http.StatusKey,
-				Value: http.K200Value,
-			},
-		},
-		{
-			Index: 9,
-			Value: http.StaticTableValue{
-				Key:   http.StatusKey,
-				Value: http.K204Value,
-			},
-		},
-		{
-			Index: 10,
-			Value: http.StaticTableValue{
-				Key:   http.StatusKey,
-				Value: http.K206Value,
-			},
-		},
-		{
-			Index: 11,
-			Value: http.StaticTableValue{
-				Key:   http.StatusKey,
-				Value: http.K304Value,
-			},
-		},
-		{
-			Index: 12,
-			Value: http.StaticTableValue{
-				Key:   http.StatusKey,
-				Value: http.K400Value,
-			},
-		},
-		{
-			Index: 13,
-			Value: http.StaticTableValue{
-				Key:   http.StatusKey,
-				Value: http.K404Value,
-			},
-		},
-		{
-			Index: 14,
-			Value: http.StaticTableValue{
-				Key:   http.StatusKey,
-				Value: http.K500Value,
-			},
-		},
-	}
+// SetProtocolEnabled enables or disables the monitoring of a protocol at runtime, without restarting system-probe.
+// Only the protocols enabled at startup can be toggled.
+func (m *Monitor) SetProtocolEnabled(protocol string, enabled bool) error {
+	return m.ebpfProgram.setProtocolEnabled(protocol, enabled)
+}
 
-	for _, entry := range staticTableEntries {
-		err := staticTable.Put(unsafe.Pointer(&entry.Index), unsafe.Pointer(&entry.Value))
+// GetProtocols returns whether the monitoring of each protocol enabled at startup is currently enabled
+func (m *Monitor) GetProtocols() map[string]bool {
+	return m.ebpfProgram.protocolsState()
+}
 
-		if err != nil {
-			return err
-		}
+// DumpInFlightTransactions returns the HTTP and HTTP/2 transactions held by the in-flight maps, to diagnose the
+// requests missing from the stats
+func (m *Monitor) DumpInFlightTransactions() ([]httpdebugging.InFlightTransaction, error) {
+	now, err := ddebpf.NowNanoseconds()
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	return dumpInFlightTransactions(m.ebpfProgram.Manager.Manager, now)
+}
+
+// DumpMaps dumps the maps associated with the monitor
+func (m *Monitor) DumpMaps(maps ...string) (string, error) {
+	return m.ebpfProgram.DumpMaps(maps...)
 }
@@ -399,6 +399,35 @@ func TestKeepAliveWithIncompleteResponseRegression(t *testing.T) {
 	assertAllRequestsExists(t, monitor, []*nethttp.Request{{URL: url, Method: "GET"}})
 }
 
+func TestHTTPMonitorToggleProtocol(t *testing.T) {
+	monitor := newHTTPMonitor(t)
+	serverAddr := "localhost:8082"
+	srvDoneFn := testutil.HTTPServer(t, serverAddr, testutil.Options{
+		EnableKeepAlive: false,
+	})
+	t.Cleanup(srvDoneFn)
+	requestFn := requestGenerator(t, serverAddr, emptyBody)
+
+	require.NoError(t, monitor.SetProtocolEnabled(httpProtocol, false))
+	require.False(t, monitor.GetProtocols()[httpProtocol])
+	// disabling a disabled protocol is a no-op
+	require.NoError(t, monitor.SetProtocolEnabled(httpProtocol, false))
+
+	req := requestFn()
+	time.Sleep(100 * time.Millisecond)
+	requestNotIncluded(t, monitor.GetHTTPStats(), req)
+
+	require.NoError(t, monitor.SetProtocolEnabled(httpProtocol, true))
+	require.True(t, monitor.GetProtocols()[httpProtocol])
+
+	req = requestFn()
+	assertAllRequestsExists(t, monitor, []*nethttp.Request{req})
+
+	// Kafka monitoring was not enabled at startup, so it cannot be turned on at runtime
+	err := monitor.SetProtocolEnabled(kafkaProtocol, true)
+	require.ErrorIs(t, err, errProtocolNotLoaded)
+}
+
 func assertAllRequestsExists(t *testing.T, monitor *Monitor, requests []*nethttp.Request) {
 	requestsExist := make([]bool, len(requests))
 	for i := 0; i < 10; i++ {
@@ -17,14 +17,18 @@ import (
 	"regexp"
 	"sync"
 	"syscall"
+	"time"
 	"unsafe"
 
 	"github.com/DataDog/gopsutil/process"
+	"github.com/hashicorp/golang-lru/v2/simplelru"
 	"github.com/twmb/murmur3"
 	"golang.org/x/sys/unix"
 
 	ddebpf "github.com/DataDog/datadog-agent/pkg/ebpf"
+	"github.com/DataDog/datadog-agent/pkg/network/config"
 	"github.com/DataDog/datadog-agent/pkg/network/protocols/http"
+	libtelemetry "github.com/DataDog/datadog-agent/pkg/network/protocols/telemetry"
 	"github.com/DataDog/datadog-agent/pkg/process/monitor"
 	"github.com/DataDog/datadog-agent/pkg/process/util"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
@@ -103,20 +107,54 @@ type soWatcher struct {
 	loadEvents     *ddebpf.PerfHandler
 	processMonitor *monitor.ProcessMonitor
 	registry       *soRegistry
+
+	// useInotify enables the detection of the libraries with inotify, as a fallback when the eBPF hooks of the open
+	// syscalls, feeding loadEvents, can't be attached
+	useInotify bool
 }
 
 type pathIdentifierSet = map[pathIdentifier]struct{}
 
+// blocklistPurgeInterval is the minimum time between two purges of the expired entries of the blocklist
+const blocklistPurgeInterval = time.Minute
+
 type soRegistry struct {
 	m     sync.RWMutex
 	byID  map[pathIdentifier]*soRegistration
 	byPID map[uint32]pathIdentifierSet
 
-	// if we can't register a uprobe we don't try more than once
-	blocklistByID pathIdentifierSet
+	// maxRegistrations caps byID, the libraries opened beyond it aren't registered until others are released.
+	// No cap is applied when it isn't positive.
+	maxRegistrations int
+
+	// if we can't register a uprobe we don't try again until the entry expires, or is evicted as the least
+	// recently blocked one when the blocklist is full. The values are the times the libraries were blocked.
+	blocklistByID      *simplelru.LRU[pathIdentifier, time.Time]
+	blocklistTTL       time.Duration
+	lastBlocklistPurge time.Time
+
+	telemetry *soRegistryTelemetry
 }
 
-func newSOWatcher(perfHandler *ddebpf.PerfHandler, rules ...soRule) *soWatcher {
+// soRegistryTelemetry counts the outcome of the registrations of a soRegistry
+type soRegistryTelemetry struct {
+	registered *libtelemetry.Metric
+	blocked    *libtelemetry.Metric
+	evicted    *libtelemetry.Metric
+	dropped    *libtelemetry.Metric
+}
+
+func newSORegistryTelemetry(program string) *soRegistryTelemetry {
+	tag := "program:" + program
+	return &soRegistryTelemetry{
+		registered: libtelemetry.NewMetric("usm.shared_libraries.registered", tag, libtelemetry.OptStatsd, libtelemetry.OptMonotonic),
+		blocked:    libtelemetry.NewMetric("usm.shared_libraries.blocked", tag, libtelemetry.OptStatsd, libtelemetry.OptMonotonic),
+		evicted:    libtelemetry.NewMetric("usm.shared_libraries.evicted", tag, libtelemetry.OptStatsd, libtelemetry.OptMonotonic),
+		dropped:    libtelemetry.NewMetric("usm.shared_libraries.dropped", tag, libtelemetry.OptStatsd, libtelemetry.OptMonotonic),
+	}
+}
+
+func newSOWatcher(c *config.Config, perfHandler *ddebpf.PerfHandler, rules ...soRule) *soWatcher {
 	return &soWatcher{
 		wg:             sync.WaitGroup{},
 		done:           make(chan struct{}),
@@ -124,12 +162,29 @@ func newSOWatcher(perfHandler *ddebpf.PerfHandler, rules ...soRule) *soWatcher {
 		rules:          rules,
 		loadEvents:     perfHandler,
 		processMonitor: monitor.GetProcessMonitor(),
-		registry: &soRegistry{
-			byID:          make(map[pathIdentifier]*soRegistration),
-			byPID:         make(map[uint32]pathIdentifierSet),
-			blocklistByID: make(pathIdentifierSet),
-		},
+		registry:       newSORegistry("shared_libraries", c),
+	}
+}
+
+// newSORegistry returns a registry limited by the configuration, its telemetry being tagged with the given program
+func newSORegistry(program string, c *config.Config) *soRegistry {
+	r := &soRegistry{
+		byID:               make(map[pathIdentifier]*soRegistration),
+		byPID:              make(map[uint32]pathIdentifierSet),
+		maxRegistrations:   c.MaxSharedLibrariesRegistrations,
+		blocklistTTL:       c.SharedLibrariesBlocklistTTL,
+		lastBlocklistPurge: time.Now(),
+		telemetry:          newSORegistryTelemetry(program),
 	}
+
+	maxBlocklistEntries := c.MaxSharedLibrariesBlocklistEntries
+	if maxBlocklistEntries < 1 {
+		// a library which can't be registered must be remembered at least until its next opening
+		maxBlocklistEntries = 1
+	}
+	// the size is positive, the creation can't fail
+	r.blocklistByID, _ = simplelru.NewLRU[pathIdentifier, time.Time](maxBlocklistEntries, nil)
+	return r
 }
 
 type soRegistration struct {
@@ -179,39 +234,18 @@ func (w *soWatcher) Start() {
 		log.Warnf("soWatcher Start can't get root namespace pid %s", err)
 	}
 
-	_ = util.WithAllProcs(w.procRoot, func(pid int) error {
-		if pid == thisPID { // don't scan ourself
-			return nil
-		}
+	w.scanProcesses(thisPID)
 
-		// report silently parsing /proc error as this could happen
-		// just exit processes
-		proc, err := process.NewProcess(int32(pid))
-		if err != nil {
-			log.Debugf("process %d parsing failed %s", pid, err)
-			return nil
-		}
-		mmaps, err := proc.MemoryMaps(true)
-		if err != nil {
-			log.Tracef("process %d maps parsing failed %s", pid, err)
-			return nil
-		}
-
-		root := fmt.Sprintf("%s/%d/root", w.procRoot, pid)
-		for _, m := range *mmaps {
-			for _, r := range w.rules {
-				if r.re.MatchString(m.Path) {
-					w.registry.register(root, m.Path, uint32(pid), r)
-					break
-				}
-			}
-		}
-
-		return nil
-	})
+	var inotify *inotifyWatcher
+	if w.useInotify {
+		inotify = w.newInotifyWatcher()
+	}
 
 	if err := w.processMonitor.Initialize(); err != nil {
 		log.Errorf("can't initialize process monitor %s", err)
+		if inotify != nil {
+			inotify.Close()
+		}
 		return
 	}
 	cleanupExit, err := w.processMonitor.Subscribe(&monitor.ProcessCallback{
@@ -221,14 +255,52 @@ func (w *soWatcher) Start() {
 	})
 	if err != nil {
 		log.Errorf("can't subscribe to process monitor exit event %s", err)
+		if inotify != nil {
+			inotify.Close()
+		}
 		return
 	}
+	// The processes are scanned as soon as they start, instead of waiting for the opening of their libraries, to
+	// hook the binaries matching the rules and the libraries already mapped by the dynamic loader
+	cleanupExec, err := w.processMonitor.Subscribe(&monitor.ProcessCallback{
+		Event:    monitor.EXEC,
+		Metadata: monitor.ANY,
+		Callback: func(pid uint32) {
+			if int(pid) == thisPID {
+				return
+			}
+			w.scanProcess(int(pid))
+		},
+	})
+	if err != nil {
+		log.Errorf("can't subscribe to process monitor exec event %s", err)
+		cleanupExit()
+		if inotify != nil {
+			inotify.Close()
+		}
+		return
+	}
+
+	var inotifyOpened <-chan struct{}
+	if inotify != nil {
+		inotifyOpened = inotify.opened
+		w.wg.Add(1)
+		go func() {
+			defer w.wg.Done()
+			inotify.run()
+		}()
+	}
 
 	w.wg.Add(1)
 	go func() {
 		defer func() {
-			// Removing the registration of our hook.
+			// Removing the registration of our hooks.
+			cleanupExec()
 			cleanupExit()
+			// Stopping the inotify events reader, and removing the registration of its hook.
+			if inotify != nil {
+				inotify.Close()
+			}
 			// Stopping the process monitor (if we're the last instance)
 			w.processMonitor.Stop()
 			// cleaning up all active hooks.
@@ -273,11 +345,99 @@ func (w *soWatcher) Start() {
 			case <-w.loadEvents.LostChannel:
 				// Nothing to do in this case
 				break
+			case <-inotifyOpened:
+				// The libraries opened are found in the memory mappings of the processes, once they are mapped
+				select {
+				case <-time.After(inotifyScanDelay):
+				case <-w.done:
+					return
+				}
+				for _, pid := range inotify.recentExecs(time.Now()) {
+					if int(pid) != thisPID {
+						w.scanProcess(int(pid))
+					}
+				}
 			}
 		}
 	}()
 }
 
+// newInotifyWatcher returns an inotify watcher watching the library directories of the host and of the containers, or
+// nil if inotify can't be used
+func (w *soWatcher) newInotifyWatcher() *inotifyWatcher {
+	inotify, err := newInotifyWatcher(w.rules)
+	if err != nil {
+		log.Errorf("can't detect the shared libraries with inotify %s", err)
+		return nil
+	}
+
+	_ = util.WithAllProcs(w.procRoot, func(pid int) error {
+		inotify.watchRoot(fmt.Sprintf("%s/%d/root", w.procRoot, pid))
+		return nil
+	})
+	// the containers started later are watched when their first process starts, and the processes started later are
+	// scanned on the openings of the libraries until inotifyExecWindow elapses
+	inotify.cleanupExec, err = w.processMonitor.Subscribe(&monitor.ProcessCallback{
+		Event:    monitor.EXEC,
+		Metadata: monitor.ANY,
+		Callback: func(pid uint32) {
+			inotify.addExec(pid, time.Now())
+			inotify.watchRoot(fmt.Sprintf("%s/%d/root", w.procRoot, pid))
+		},
+	})
+	if err != nil {
+		log.Errorf("can't subscribe to process monitor exec event %s", err)
+		inotify.Close()
+		return nil
+	}
+
+	log.Info("detecting the shared libraries with inotify")
+	return inotify
+}
+
+// scanProcesses registers the libraries matching the rules in the memory mappings of all processes
+func (w *soWatcher) scanProcesses(thisPID int) {
+	_ = util.WithAllProcs(w.procRoot, func(pid int) error {
+		if pid == thisPID { // don't scan ourself
+			return nil
+		}
+		w.scanProcess(pid)
+		return nil
+	})
+}
+
+// scanProcess registers the libraries matching the rules in the memory mappings of the process, which include its
+// binary
+func (w *soWatcher) scanProcess(pid int) {
+	// report silently parsing /proc error as this could happen
+	// just exit processes
+	proc, err := process.NewProcess(int32(pid))
+	if err != nil {
+		log.Debugf("process %d parsing failed %s", pid, err)
+		return
+	}
+	mmaps, err := proc.MemoryMaps(true)
+	if err != nil {
+		log.Tracef("process %d maps parsing failed %s", pid, err)
+		return
+	}
+
+	root := fmt.Sprintf("%s/%d/root", w.procRoot, pid)
+	for _, m := range *mmaps {
+		w.registerMatching(root, m.Path, uint32(pid))
+	}
+}
+
+// registerMatching registers the library with the first rule matching its path, if any
+func (w *soWatcher) registerMatching(root, libPath string, pid uint32) {
+	for _, r := range w.rules {
+		if r.re.MatchString(libPath) {
+			w.registry.register(root, libPath, pid, r)
+			return
+		}
+	}
+}
+
 // cleanup removes all registrations
 func (r *soRegistry) cleanup() {
 	r.m.Lock()
@@ -330,8 +490,14 @@ func (r *soRegistry) register(root, libPath string, pid uint32, rule soRule) {
 
 	r.m.Lock()
 	defer r.m.Unlock()
-	if _, found := r.blocklistByID[pathID]; found {
-		return
+	now := time.Now()
+	r.purgeBlocklist(now)
+	if blockedAt, found := r.blocklistByID.Peek(pathID); found {
+		if now.Sub(blockedAt) < r.blocklistTTL {
+			return
+		}
+		// the registration is attempted again
+		r.blocklistByID.Remove(pathID)
 	}
 
 	if reg, found := r.byID[pathID]; found {
@@ -346,6 +512,12 @@ func (r *soRegistry) register(root, libPath string, pid uint32, rule soRule) {
 		return
 	}
 
+	if r.maxRegistrations > 0 && len(r.byID) >= r.maxRegistrations {
+		log.Debugf("can't register library %s path %s by pid %d: the limit of %d registrations is reached", pathID.String(), hostLibPath, pid, r.maxRegistrations)
+		r.telemetry.dropped.Add(1)
+		return
+	}
+
 	if err := rule.registerCB(pathID, root, libPath); err != nil {
 		log.Debugf("error registering library (adding to blocklist) %s path %s by pid %d : %s", pathID.String(), hostLibPath, pid, err)
 		// we are calling unregisterCB here as some uprobes could be already attached, unregisterCB cleanup those entries
@@ -356,7 +528,11 @@ func (r *soRegistry) register(root, libPath string, pid uint32, rule soRule) {
 		}
 		// save sentinel value, so we don't attempt to re-register shared
 		// libraries that are problematic for some reason
-		r.blocklistByID[pathID] = struct{}{}
+		if evicted := r.blocklistByID.Add(pathID, now); evicted {
+			// only the evictions of the full blocklist are counted, not the removals of the expired entries
+			r.telemetry.evicted.Add(1)
+		}
+		r.telemetry.blocked.Add(1)
 		return
 	}
 
@@ -366,5 +542,24 @@ func (r *soRegistry) register(root, libPath string, pid uint32, rule soRule) {
 		r.byPID[pid] = pathIdentifierSet{}
 	}
 	r.byPID[pid][pathID] = struct{}{}
+	r.telemetry.registered.Add(1)
 	log.Debugf("registering library %s path %s by pid %d", pathID.String(), hostLibPath, pid)
 }
+
+// purgeBlocklist evicts the expired entries of the blocklist, at most once per blocklistPurgeInterval. The entries are
+// never refreshed, the least recently used ones being the oldest blocked.
+// Must be called with the registry lock held.
+func (r *soRegistry) purgeBlocklist(now time.Time) {
+	if now.Sub(r.lastBlocklistPurge) < blocklistPurgeInterval {
+		return
+	}
+	r.lastBlocklistPurge = now
+
+	for {
+		_, blockedAt, found := r.blocklistByID.GetOldest()
+		if !found || now.Sub(blockedAt) < r.blocklistTTL {
+			return
+		}
+		r.blocklistByID.RemoveOldest()
+	}
+}
@@ -0,0 +1,285 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux_bpf
+// +build linux_bpf
+
+package usm
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+	"unsafe"
+
+	"github.com/hashicorp/golang-lru/v2/simplelru"
+	"golang.org/x/sys/unix"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	// inotifyScanDelay is the delay between the opening of a library and the scan of the processes, leaving the time
+	// to the process to map the library into its memory
+	inotifyScanDelay = 500 * time.Millisecond
+	// maxInotifyWatches is the maximum number of library directories watched, across the host and the containers.
+	// Only the directories holding a library matching the rules are watched, usually one or two per root.
+	maxInotifyWatches = 1024
+	// maxInotifyRoots is the maximum number of roots remembered as already watched, so that the library directories
+	// of a root aren't listed again on the start of each of its processes
+	maxInotifyRoots = 4096
+	// inotifyExecWindow is the time during which a process which started may still load the libraries reported
+	// opened, e.g. with dlopen. Only these processes are scanned on an opening, the other ones having been scanned
+	// when they started or when the soWatcher started.
+	inotifyExecWindow = time.Minute
+	// inotifyLibraryNotifyInterval is the minimum time between two notifications of the opening of a same library,
+	// which may be opened by each process starting on the host
+	inotifyLibraryNotifyInterval = time.Second
+)
+
+// libraryDirs are the directories the shared libraries are usually loaded from, relative to the root of the host or
+// of a container
+var libraryDirs = []string{
+	"/lib",
+	"/lib64",
+	"/usr/lib",
+	"/usr/lib64",
+	"/usr/local/lib",
+	"/lib/x86_64-linux-gnu",
+	"/usr/lib/x86_64-linux-gnu",
+	"/lib/aarch64-linux-gnu",
+	"/usr/lib/aarch64-linux-gnu",
+	// the musl libraries installed on the glibc based distributions
+	"/usr/lib/x86_64-linux-musl",
+	"/usr/lib/aarch64-linux-musl",
+	// the default prefix of OpenSSL built from source, e.g. on Alpine images shipping their own version
+	"/usr/local/ssl/lib",
+	"/usr/local/ssl/lib64",
+}
+
+// inotifyWatcher is the fallback of the soWatcher when the eBPF hooks of the open syscalls can't be attached, as on
+// some restricted kernels. It watches the opening of the libraries matching the rules in the library directories of
+// the host and of the containers. inotify doesn't report the process opening the file, so each opening is notified
+// on the opened channel, the soWatcher scanning the memory mappings of the recently started processes to find the
+// ones using it.
+type inotifyWatcher struct {
+	// fd is kept along the file, as calling file.Fd() would switch the file to blocking mode, preventing Close from
+	// interrupting a pending Read
+	fd    int
+	file  *os.File
+	rules []soRule
+
+	mux          sync.Mutex
+	watchedByID  map[pathIdentifier]int32
+	idByWD       map[int32]pathIdentifier
+	watchedRoots *simplelru.LRU[pathIdentifier, struct{}]
+	// execs holds the start time of the processes which started within the last inotifyExecWindow, by PID
+	execs map[uint32]time.Time
+
+	// lastNotifiedByLib holds the last time the opening of each library was notified. It is only accessed by run.
+	lastNotifiedByLib map[string]time.Time
+
+	// opened is notified, without blocking, when a library matching a rule is opened. Its capacity of 1 coalesces
+	// the notifications until the soWatcher handles them.
+	opened chan struct{}
+
+	// cleanupExec removes the subscription to the process starts, watching the roots of the new containers
+	cleanupExec func()
+}
+
+func newInotifyWatcher(rules []soRule) (*inotifyWatcher, error) {
+	fd, err := unix.InotifyInit1(unix.IN_CLOEXEC | unix.IN_NONBLOCK)
+	if err != nil {
+		return nil, fmt.Errorf("could not initialize inotify: %w", err)
+	}
+
+	// the size is positive, the creation can't fail
+	watchedRoots, _ := simplelru.NewLRU[pathIdentifier, struct{}](maxInotifyRoots, nil)
+	return &inotifyWatcher{
+		fd:                fd,
+		file:              os.NewFile(uintptr(fd), "inotify"),
+		rules:             rules,
+		watchedByID:       make(map[pathIdentifier]int32),
+		idByWD:            make(map[int32]pathIdentifier),
+		watchedRoots:      watchedRoots,
+		execs:             make(map[uint32]time.Time),
+		lastNotifiedByLib: make(map[string]time.Time),
+		opened:            make(chan struct{}, 1),
+	}, nil
+}
+
+// watchRoot watches the library directories under the given root, e.g. /proc/<pid>/root for the processes running in
+// containers. The roots and the directories already watched, such as the ones of the host, are skipped.
+func (w *inotifyWatcher) watchRoot(root string) {
+	rootID, err := newPathIdentifier(root)
+	if err != nil {
+		// the process already exited
+		return
+	}
+	w.mux.Lock()
+	_, found := w.watchedRoots.Get(rootID)
+	w.watchedRoots.Add(rootID, struct{}{})
+	w.mux.Unlock()
+	if found {
+		return
+	}
+
+	for _, dir := range libraryDirs {
+		w.watch(root + dir)
+	}
+}
+
+// watch watches the given directory, if it holds a library matching the rules
+func (w *inotifyWatcher) watch(dir string) {
+	pathID, err := newPathIdentifier(dir)
+	if err != nil {
+		// the directory doesn't exist under this root
+		return
+	}
+
+	w.mux.Lock()
+	_, found := w.watchedByID[pathID]
+	w.mux.Unlock()
+	if found || !w.holdsMatchingLibrary(dir) {
+		return
+	}
+
+	w.mux.Lock()
+	defer w.mux.Unlock()
+	if _, found := w.watchedByID[pathID]; found {
+		return
+	}
+	if len(w.watchedByID) >= maxInotifyWatches {
+		log.Debugf("could not watch the libraries of %s: the limit of %d watched directories is reached", dir, maxInotifyWatches)
+		return
+	}
+
+	wd, err := unix.InotifyAddWatch(w.fd, dir, unix.IN_OPEN|unix.IN_ONLYDIR)
+	if err != nil {
+		log.Debugf("could not watch the libraries of %s: %s", dir, err)
+		return
+	}
+	w.watchedByID[pathID] = int32(wd)
+	w.idByWD[int32(wd)] = pathID
+}
+
+func (w *inotifyWatcher) holdsMatchingLibrary(dir string) bool {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false
+	}
+	for _, entry := range entries {
+		if w.matches([]byte(entry.Name())) {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *inotifyWatcher) matches(name []byte) bool {
+	for _, r := range w.rules {
+		if r.re.Match(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// addExec records the start of a process, which is scanned on the next openings notified within inotifyExecWindow
+func (w *inotifyWatcher) addExec(pid uint32, now time.Time) {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+	w.execs[pid] = now
+}
+
+// recentExecs returns the processes which started within inotifyExecWindow, and forgets the other ones
+func (w *inotifyWatcher) recentExecs(now time.Time) []uint32 {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+	pids := make([]uint32, 0, len(w.execs))
+	for pid, startTime := range w.execs {
+		if now.Sub(startTime) > inotifyExecWindow {
+			delete(w.execs, pid)
+			continue
+		}
+		pids = append(pids, pid)
+	}
+	return pids
+}
+
+// run reads the inotify events until the watcher is closed
+func (w *inotifyWatcher) run() {
+	buffer := make([]byte, 64*(unix.SizeofInotifyEvent+unix.NAME_MAX+1))
+	for {
+		n, err := w.file.Read(buffer)
+		if err != nil {
+			if !errors.Is(err, os.ErrClosed) {
+				log.Errorf("could not read the inotify events: %s", err)
+			}
+			return
+		}
+		w.handleEvents(buffer[:n], time.Now())
+	}
+}
+
+func (w *inotifyWatcher) handleEvents(buffer []byte, now time.Time) {
+	for offset := 0; offset+unix.SizeofInotifyEvent <= len(buffer); {
+		event := (*unix.InotifyEvent)(unsafe.Pointer(&buffer[offset]))
+		nameStart := offset + unix.SizeofInotifyEvent
+		nameEnd := nameStart + int(event.Len)
+		if nameEnd > len(buffer) {
+			return
+		}
+		name := bytes.TrimRight(buffer[nameStart:nameEnd], "\x00")
+		offset = nameEnd
+
+		switch {
+		case event.Mask&unix.IN_Q_OVERFLOW != 0:
+			// some openings were missed, the processes must be scanned
+			w.notifyOpened()
+		case event.Mask&unix.IN_IGNORED != 0:
+			// the directory was removed, or the filesystem of the container unmounted
+			w.forget(event.Wd)
+		case event.Mask&unix.IN_OPEN != 0 && event.Mask&unix.IN_ISDIR == 0:
+			if !w.matches(name) {
+				continue
+			}
+			if lastNotified, ok := w.lastNotifiedByLib[string(name)]; ok && now.Sub(lastNotified) < inotifyLibraryNotifyInterval {
+				continue
+			}
+			w.lastNotifiedByLib[string(name)] = now
+			w.notifyOpened()
+		}
+	}
+}
+
+func (w *inotifyWatcher) notifyOpened() {
+	select {
+	case w.opened <- struct{}{}:
+	default:
+	}
+}
+
+func (w *inotifyWatcher) forget(wd int32) {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+	if pathID, found := w.idByWD[wd]; found {
+		delete(w.watchedByID, pathID)
+		delete(w.idByWD, wd)
+	}
+}
+
+// Close removes the subscription to the process starts, releases the inotify instance and interrupts run
+func (w *inotifyWatcher) Close() {
+	if w.cleanupExec != nil {
+		w.cleanupExec()
+	}
+	if err := w.file.Close(); err != nil {
+		log.Debugf("could not close inotify: %s", err)
+	}
+}
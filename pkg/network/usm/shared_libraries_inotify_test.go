@@ -0,0 +1,125 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux_bpf
+// +build linux_bpf
+
+package usm
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestInotifyWatcher(t *testing.T) *inotifyWatcher {
+	watcher, err := newInotifyWatcher([]soRule{{re: regexp.MustCompile(`foo.so`)}})
+	require.NoError(t, err)
+
+	done := make(chan struct{})
+	go func() {
+		watcher.run()
+		close(done)
+	}()
+	t.Cleanup(func() {
+		watcher.Close()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Error("the inotify watcher wasn't interrupted by Close")
+		}
+	})
+	return watcher
+}
+
+func openTestFile(t *testing.T, path string) {
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+}
+
+func TestInotifyWatcherLibraryOpened(t *testing.T) {
+	dir := t.TempDir()
+	openTestFile(t, filepath.Join(dir, "libfoo.so.1"))
+	watcher := newTestInotifyWatcher(t)
+	watcher.watch(dir)
+
+	openTestFile(t, filepath.Join(dir, "bar.so"))
+	assert.Never(t, func() bool {
+		return len(watcher.opened) > 0
+	}, 200*time.Millisecond, 10*time.Millisecond, "the opening of a library matching no rule was notified")
+
+	openTestFile(t, filepath.Join(dir, "libfoo.so.1"))
+	select {
+	case <-watcher.opened:
+	case <-time.After(time.Second):
+		require.Fail(t, "the opening of a library matching a rule wasn't notified")
+	}
+
+	// the openings of a same library are notified at most once per interval
+	openTestFile(t, filepath.Join(dir, "libfoo.so.1"))
+	assert.Never(t, func() bool {
+		return len(watcher.opened) > 0
+	}, 200*time.Millisecond, 10*time.Millisecond, "the opening of a library was notified twice within the interval")
+}
+
+func TestInotifyWatcherWatchRoot(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "usr/lib"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "usr/lib64"), 0755))
+	require.NoError(t, os.Symlink("usr/lib", filepath.Join(root, "lib")))
+	openTestFile(t, filepath.Join(root, "usr/lib", "libfoo.so"))
+	watcher := newTestInotifyWatcher(t)
+
+	// the library directories are deduplicated by dev/inode, including when reached through a symlink or another
+	// root, and the ones holding no library matching the rules aren't watched
+	watcher.watchRoot(root)
+	watcher.watch(filepath.Join(root, "lib"))
+	watcher.mux.Lock()
+	assert.Len(t, watcher.watchedByID, 1)
+	watcher.mux.Unlock()
+
+	openTestFile(t, filepath.Join(root, "lib", "libfoo.so"))
+	select {
+	case <-watcher.opened:
+	case <-time.After(time.Second):
+		require.Fail(t, "the opening of a library matching a rule wasn't notified")
+	}
+
+	// the watch is forgotten once the directory is removed
+	require.NoError(t, os.RemoveAll(filepath.Join(root, "usr")))
+	assert.Eventually(t, func() bool {
+		watcher.mux.Lock()
+		defer watcher.mux.Unlock()
+		return len(watcher.watchedByID) == 0 && len(watcher.idByWD) == 0
+	}, time.Second, 10*time.Millisecond)
+
+	// a root is only listed once
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "usr/lib"), 0755))
+	openTestFile(t, filepath.Join(root, "usr/lib", "libfoo.so"))
+	watcher.watchRoot(root)
+	watcher.mux.Lock()
+	assert.Empty(t, watcher.watchedByID)
+	watcher.mux.Unlock()
+}
+
+func TestInotifyWatcherRecentExecs(t *testing.T) {
+	watcher := newTestInotifyWatcher(t)
+	now := time.Now()
+	watcher.addExec(1, now.Add(-2*inotifyExecWindow))
+	watcher.addExec(2, now.Add(-inotifyExecWindow/2))
+	watcher.addExec(3, now)
+
+	assert.ElementsMatch(t, []uint32{2, 3}, watcher.recentExecs(now))
+	// the processes which started before the window are forgotten
+	watcher.mux.Lock()
+	assert.Len(t, watcher.execs, 2)
+	watcher.mux.Unlock()
+}
@@ -9,6 +9,7 @@
 package usm
 
 import (
+	"errors"
 	"fmt"
 	"math"
 	"os"
@@ -32,6 +33,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/network/ebpf/probes"
 	"github.com/DataDog/datadog-agent/pkg/network/protocols/http"
 	"github.com/DataDog/datadog-agent/pkg/network/protocols/http/testutil"
+	libtelemetry "github.com/DataDog/datadog-agent/pkg/network/protocols/telemetry"
 	errtelemetry "github.com/DataDog/datadog-agent/pkg/network/telemetry"
 )
 
@@ -61,7 +63,7 @@ func TestSharedLibraryDetection(t *testing.T) {
 		return nil
 	}
 
-	watcher := newSOWatcher(perfHandler,
+	watcher := newSOWatcher(config.New(), perfHandler,
 		soRule{
 			re:         regexp.MustCompile(`foo.so`),
 			registerCB: callback,
@@ -125,7 +127,7 @@ func TestSharedLibraryDetectionWithPIDAndRootNameSpace(t *testing.T) {
 		return nil
 	}
 
-	watcher := newSOWatcher(perfHandler,
+	watcher := newSOWatcher(config.New(), perfHandler,
 		soRule{
 			re:         regexp.MustCompile(`fooroot.so`),
 			registerCB: callback,
@@ -169,7 +171,7 @@ func TestSameInodeRegression(t *testing.T) {
 		return nil
 	}
 
-	watcher := newSOWatcher(perfHandler,
+	watcher := newSOWatcher(config.New(), perfHandler,
 		soRule{
 			re:         regexp.MustCompile(`foo.so`),
 			registerCB: callback,
@@ -212,7 +214,7 @@ func TestSoWatcherLeaks(t *testing.T) {
 	registerCB := func(id pathIdentifier, root string, path string) error { return nil }
 	unregisterCB := func(id pathIdentifier) error { return nil }
 
-	watcher := newSOWatcher(perfHandler,
+	watcher := newSOWatcher(config.New(), perfHandler,
 		soRule{
 			re:           regexp.MustCompile(`foo.so`),
 			registerCB:   registerCB,
@@ -292,7 +294,7 @@ func TestSoWatcherProcessAlreadyHoldingReferences(t *testing.T) {
 	registerCB := func(id pathIdentifier, root string, path string) error { return nil }
 	unregisterCB := func(id pathIdentifier) error { return nil }
 
-	watcher := newSOWatcher(perfHandler,
+	watcher := newSOWatcher(config.New(), perfHandler,
 		soRule{
 			re:           regexp.MustCompile(`foo.so`),
 			registerCB:   registerCB,
@@ -351,6 +353,100 @@ func TestSoWatcherProcessAlreadyHoldingReferences(t *testing.T) {
 	checkWatcherStateIsClean(t, watcher)
 }
 
+func TestSoWatcherProcessExec(t *testing.T) {
+	// the open hooks aren't attached, the binary started after the watcher can only be detected at exec time
+	perfHandler := ddebpf.NewPerfHandler(10)
+	binPath := copyBinaryAs(t, "/bin/sleep", "foo-bin")
+	binPathID, err := newPathIdentifier(binPath)
+	require.NoError(t, err)
+
+	watcher := newSOWatcher(config.New(), perfHandler,
+		soRule{
+			re:         regexp.MustCompile(`foo-bin`),
+			registerCB: func(pathIdentifier, string, string) error { return nil },
+		},
+	)
+	watcher.Start()
+	t.Cleanup(watcher.Stop)
+
+	command := exec.Command(binPath, "10")
+	require.NoError(t, command.Start())
+	registerProcessTerminationUponCleanup(t, command)
+
+	require.Eventually(t, func() bool {
+		return checkPIDAssociatedWithPathID(watcher, binPathID, uint32(command.Process.Pid))
+	}, time.Second*5, 100*time.Millisecond)
+}
+
+func TestSORegistryMaxRegistrations(t *testing.T) {
+	libtelemetry.Clear()
+	fooPath1, fooPathID1 := createTempTestFile(t, "foo1.so")
+	fooPath2, fooPathID2 := createTempTestFile(t, "foo2.so")
+
+	cfg := config.New()
+	cfg.MaxSharedLibrariesRegistrations = 1
+	registry := newSORegistry("test", cfg)
+	rule := soRule{
+		registerCB: func(pathIdentifier, string, string) error { return nil },
+	}
+
+	registry.register("", fooPath1, 1, rule)
+	registry.register("", fooPath2, 2, rule)
+	require.Contains(t, registry.byID, fooPathID1)
+	require.NotContains(t, registry.byID, fooPathID2)
+	require.Equal(t, int64(1), registry.telemetry.registered.Get())
+	require.Equal(t, int64(1), registry.telemetry.dropped.Get())
+
+	// the library dropped is registered once another one is released
+	registry.unregister(1)
+	registry.register("", fooPath2, 2, rule)
+	require.NotContains(t, registry.byID, fooPathID1)
+	require.Contains(t, registry.byID, fooPathID2)
+	require.Equal(t, int64(2), registry.telemetry.registered.Get())
+}
+
+func TestSORegistryBlocklist(t *testing.T) {
+	libtelemetry.Clear()
+	fooPath1, fooPathID1 := createTempTestFile(t, "foo1.so")
+	fooPath2, fooPathID2 := createTempTestFile(t, "foo2.so")
+
+	cfg := config.New()
+	cfg.MaxSharedLibrariesBlocklistEntries = 1
+	cfg.SharedLibrariesBlocklistTTL = time.Hour
+	registry := newSORegistry("test", cfg)
+	attempts := 0
+	rule := soRule{
+		registerCB: func(pathIdentifier, string, string) error {
+			attempts++
+			return errors.New("can't attach the hooks")
+		},
+	}
+
+	// the registration of a blocked library isn't attempted again
+	registry.register("", fooPath1, 1, rule)
+	registry.register("", fooPath1, 2, rule)
+	require.Equal(t, 1, attempts)
+	require.True(t, registry.blocklistByID.Contains(fooPathID1))
+	require.Equal(t, int64(1), registry.telemetry.blocked.Get())
+
+	// the least recently blocked library is evicted when the blocklist is full
+	registry.register("", fooPath2, 3, rule)
+	require.Equal(t, 2, attempts)
+	require.False(t, registry.blocklistByID.Contains(fooPathID1))
+	require.True(t, registry.blocklistByID.Contains(fooPathID2))
+	require.Equal(t, int64(2), registry.telemetry.blocked.Get())
+	require.Equal(t, int64(1), registry.telemetry.evicted.Get())
+
+	// the expired entries are purged periodically
+	registry.purgeBlocklist(time.Now().Add(blocklistPurgeInterval + time.Minute))
+	require.True(t, registry.blocklistByID.Contains(fooPathID2))
+	registry.purgeBlocklist(time.Now().Add(blocklistPurgeInterval + time.Hour))
+	require.Zero(t, registry.blocklistByID.Len())
+	// the expired entries aren't counted as evicted
+	require.Equal(t, int64(1), registry.telemetry.evicted.Get())
+	require.Empty(t, registry.byID)
+}
+
 func buildSOWatcherClientBin(t *testing.T) string {
 	const ClientSrcPath = "sowatcher_client"
 	const ClientBinaryPath = "testutil/sowatcher_client/sowatcher_client"
@@ -0,0 +1,227 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux_bpf
+// +build linux_bpf
+
+package usm
+
+import (
+	"debug/elf"
+	"regexp"
+
+	manager "github.com/DataDog/ebpf-manager"
+
+	"github.com/DataDog/datadog-agent/pkg/network/go/bininspect"
+	libtelemetry "github.com/DataDog/datadog-agent/pkg/network/protocols/telemetry"
+	errtelemetry "github.com/DataDog/datadog-agent/pkg/network/telemetry"
+	"github.com/DataDog/datadog-agent/pkg/util/common"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// The TLS libraries hooked by the SSL subprogram, used to tag the hooks telemetry
+const (
+	openSSLLibrary   = "openssl"
+	libreSSLLibrary  = "libressl"
+	boringSSLLibrary = "boringssl"
+	cryptoLibrary    = "libcrypto"
+	gnuTLSLibrary    = "gnutls"
+)
+
+// The libraries hooked by the SSL subprogram. Their names are the same on the glibc and musl based distributions,
+// such as Alpine, which ship them under /lib (e.g. /lib/libssl.so.3, or /lib/libssl.so.48 for the LibreSSL of
+// the older Alpine releases). The dots are escaped for the libraries sharing the prefix, e.g. the libssl3.so of NSS.
+var (
+	sslLibraryRegex    = regexp.MustCompile(`libssl\.so`)
+	cryptoLibraryRegex = regexp.MustCompile(`libcrypto\.so`)
+	gnuTLSLibraryRegex = regexp.MustCompile(`libgnutls\.so`)
+)
+
+// sslLibraryMarkers are symbols exported only by the libssl of each fork of OpenSSL, as the forks share
+// the name of the library and its API. A library exporting none of them is considered to be OpenSSL.
+var sslLibraryMarkers = []struct {
+	library string
+	symbols []string
+}{
+	{
+		library: boringSSLLibrary,
+		symbols: []string{"SSL_CTX_set_grease_enabled", "SSL_set_enforce_rsa_key_usage"},
+	},
+	{
+		library: libreSSLLibrary,
+		symbols: []string{"SSL_CTX_use_certificate_chain_mem", "SSL_CTX_load_verify_mem"},
+	},
+}
+
+// newBoringSSLProbes returns the OpenSSL probes to attach to BoringSSL.
+// BoringSSL doesn't implement the `_ex` variants of SSL_read and SSL_write, and when it is statically linked,
+// the functions the application doesn't call, such as SSL_set_fd, may have been stripped by the linker. When
+// the socket can't be resolved from SSL_set_fd or SSL_set_bio, the tuple is guessed from tcp_sendmsg (see
+// tup_from_ssl_ctx).
+//
+// A new list is returned on each call, as the selectors are edited when they are attached to a library.
+func newBoringSSLProbes() []manager.ProbesSelector {
+	return []manager.ProbesSelector{
+		&manager.BestEffort{
+			Selectors: []manager.ProbesSelector{
+				&manager.ProbeSelector{
+					ProbeIdentificationPair: manager.ProbeIdentificationPair{
+						EBPFFuncName: "uprobe__SSL_connect",
+					},
+				},
+				&manager.ProbeSelector{
+					ProbeIdentificationPair: manager.ProbeIdentificationPair{
+						EBPFFuncName: "uretprobe__SSL_connect",
+					},
+				},
+				&manager.ProbeSelector{
+					ProbeIdentificationPair: manager.ProbeIdentificationPair{
+						EBPFFuncName: "uprobe__SSL_set_bio",
+					},
+				},
+				&manager.ProbeSelector{
+					ProbeIdentificationPair: manager.ProbeIdentificationPair{
+						EBPFFuncName: "uprobe__SSL_set_fd",
+					},
+				},
+			},
+		},
+		&manager.AllOf{
+			Selectors: []manager.ProbesSelector{
+				&manager.ProbeSelector{
+					ProbeIdentificationPair: manager.ProbeIdentificationPair{
+						EBPFFuncName: "uprobe__SSL_do_handshake",
+					},
+				},
+				&manager.ProbeSelector{
+					ProbeIdentificationPair: manager.ProbeIdentificationPair{
+						EBPFFuncName: "uretprobe__SSL_do_handshake",
+					},
+				},
+				&manager.ProbeSelector{
+					ProbeIdentificationPair: manager.ProbeIdentificationPair{
+						EBPFFuncName: "uprobe__SSL_read",
+					},
+				},
+				&manager.ProbeSelector{
+					ProbeIdentificationPair: manager.ProbeIdentificationPair{
+						EBPFFuncName: "uretprobe__SSL_read",
+					},
+				},
+				&manager.ProbeSelector{
+					ProbeIdentificationPair: manager.ProbeIdentificationPair{
+						EBPFFuncName: "uprobe__SSL_write",
+					},
+				},
+				&manager.ProbeSelector{
+					ProbeIdentificationPair: manager.ProbeIdentificationPair{
+						EBPFFuncName: "uretprobe__SSL_write",
+					},
+				},
+				&manager.ProbeSelector{
+					ProbeIdentificationPair: manager.ProbeIdentificationPair{
+						EBPFFuncName: "uprobe__SSL_shutdown",
+					},
+				},
+			},
+		},
+	}
+}
+
+// sslHooksTelemetry counts the libraries hooked by the SSL subprogram, and the ones which could not be
+type sslHooksTelemetry struct {
+	attached *libtelemetry.Metric
+	failed   *libtelemetry.Metric
+}
+
+func newSSLHooksTelemetry(library string) *sslHooksTelemetry {
+	tag := "library:" + library
+	return &sslHooksTelemetry{
+		attached: libtelemetry.NewMetric("usm.tls.hooks.attached", tag, libtelemetry.OptStatsd, libtelemetry.OptMonotonic),
+		failed:   libtelemetry.NewMetric("usm.tls.hooks.failed", tag, libtelemetry.OptStatsd, libtelemetry.OptMonotonic),
+	}
+}
+
+func (t *sslHooksTelemetry) record(err error) {
+	if err != nil {
+		t.failed.Add(1)
+		return
+	}
+	t.attached.Add(1)
+}
+
+// withHooksTelemetry wraps the registration callback of a library, to count its outcome
+func withHooksTelemetry(library string, registerCB func(pathIdentifier, string, string) error) func(pathIdentifier, string, string) error {
+	telemetry := newSSLHooksTelemetry(library)
+	return func(id pathIdentifier, root string, path string) error {
+		err := registerCB(id, root, path)
+		telemetry.record(err)
+		return err
+	}
+}
+
+// addSSLHooks returns the registration callback of the libssl libraries. The OpenSSL forks share the name of
+// the library, so the fork is detected from the symbols of the library, and the probes matching its API are
+// attached to it.
+func addSSLHooks(m *errtelemetry.Manager, openSSLProbes, boringSSLProbes []manager.ProbesSelector) func(pathIdentifier, string, string) error {
+	addOpenSSLHooks := addHooks(m, openSSLProbes)
+	addBoringSSLHooks := addHooks(m, boringSSLProbes)
+	telemetry := map[string]*sslHooksTelemetry{
+		openSSLLibrary:   newSSLHooksTelemetry(openSSLLibrary),
+		libreSSLLibrary:  newSSLHooksTelemetry(libreSSLLibrary),
+		boringSSLLibrary: newSSLHooksTelemetry(boringSSLLibrary),
+	}
+
+	return func(id pathIdentifier, root string, path string) error {
+		library := detectSSLLibrary(root + path)
+
+		var err error
+		if library == boringSSLLibrary {
+			err = addBoringSSLHooks(id, root, path)
+		} else {
+			// LibreSSL implements the same functions as OpenSSL, the `_ex` variants being best effort
+			err = addOpenSSLHooks(id, root, path)
+		}
+		telemetry[library].record(err)
+		return err
+	}
+}
+
+// removeSSLHooks returns the unregistration callback of the libssl libraries, detaching the probes of any fork
+func removeSSLHooks(m *errtelemetry.Manager, openSSLProbes, boringSSLProbes []manager.ProbesSelector) func(pathIdentifier) error {
+	removeOpenSSLHooks := removeHooks(m, openSSLProbes)
+	removeBoringSSLHooks := removeHooks(m, boringSSLProbes)
+	return func(id pathIdentifier) error {
+		// the probes not attached to the library are skipped
+		if err := removeOpenSSLHooks(id); err != nil {
+			return err
+		}
+		return removeBoringSSLHooks(id)
+	}
+}
+
+// detectSSLLibrary returns the fork of OpenSSL the library at the given path is built from
+func detectSSLLibrary(path string) string {
+	elfFile, err := elf.Open(path)
+	if err != nil {
+		log.Debugf("could not open %s to detect its TLS library: %s", path, err)
+		return openSSLLibrary
+	}
+	defer elfFile.Close()
+
+	return sslLibraryFromELF(elfFile)
+}
+
+func sslLibraryFromELF(elfFile *elf.File) string {
+	for _, marker := range sslLibraryMarkers {
+		for _, symbol := range marker.symbols {
+			// GetAllSymbolsByName fails if any of the symbols is missing, so they are looked up one by one
+			if _, err := bininspect.GetAllSymbolsByName(elfFile, common.StringSet{symbol: struct{}{}}); err == nil {
+				return marker.library
+			}
+		}
+	}
+	return openSSLLibrary
+}
@@ -0,0 +1,120 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux_bpf
+// +build linux_bpf
+
+package usm
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// buildFakeSSLLibrary builds a shared library exporting the given functions
+func buildFakeSSLLibrary(t *testing.T, functions ...string) string {
+	var content string
+	for _, function := range functions {
+		content += "int " + function + "(void) { return 0; }\n"
+	}
+	return buildFakeSSLLibraryFromSource(t, content)
+}
+
+// buildFakeSSLLibraryFromSource builds a shared library from the given C source
+func buildFakeSSLLibraryFromSource(t *testing.T, content string) string {
+	gcc, err := exec.LookPath("gcc")
+	if err != nil {
+		t.Skip("gcc is required to build the fake libraries")
+	}
+
+	dir := t.TempDir()
+	source := filepath.Join(dir, "ssl.c")
+	require.NoError(t, os.WriteFile(source, []byte(content), 0644))
+
+	lib := filepath.Join(dir, "libssl.so.3")
+	out, err := exec.Command(gcc, "-shared", "-fPIC", "-o", lib, source).CombinedOutput()
+	require.NoError(t, err, string(out))
+	return lib
+}
+
+func TestDetectSSLLibrary(t *testing.T) {
+	tests := []struct {
+		name      string
+		functions []string
+		expected  string
+	}{
+		{
+			name:      "openssl",
+			functions: []string{"SSL_read", "SSL_write", "SSL_read_ex", "SSL_write_ex"},
+			expected:  openSSLLibrary,
+		},
+		{
+			name:      "boringssl",
+			functions: []string{"SSL_read", "SSL_write", "SSL_CTX_set_grease_enabled"},
+			expected:  boringSSLLibrary,
+		},
+		{
+			name:      "libressl",
+			functions: []string{"SSL_read", "SSL_write", "SSL_CTX_load_verify_mem"},
+			expected:  libreSSLLibrary,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lib := buildFakeSSLLibrary(t, tt.functions...)
+			assert.Equal(t, tt.expected, detectSSLLibrary(lib))
+		})
+	}
+
+	t.Run("imported marker", func(t *testing.T) {
+		// the symbols imported from another library aren't mistaken for the ones the library defines
+		lib := buildFakeSSLLibraryFromSource(t, `
+extern int SSL_CTX_set_grease_enabled(void);
+int SSL_read(void) { return SSL_CTX_set_grease_enabled(); }
+`)
+		assert.Equal(t, openSSLLibrary, detectSSLLibrary(lib))
+	})
+
+	t.Run("missing library", func(t *testing.T) {
+		assert.Equal(t, openSSLLibrary, detectSSLLibrary(filepath.Join(t.TempDir(), "libssl.so")))
+	})
+}
+
+func TestSSLLibrariesRegex(t *testing.T) {
+	for _, path := range []string{
+		"/usr/lib/x86_64-linux-gnu/libssl.so.3",
+		"/usr/lib64/libssl.so.1.1",
+		// Alpine, and the LibreSSL of its older releases
+		"/lib/libssl.so.3",
+		"/lib/libssl.so.48",
+		"/usr/local/ssl/lib/libssl.so.3",
+	} {
+		assert.True(t, sslLibraryRegex.MatchString(path), path)
+	}
+	// the libssl of NSS
+	assert.False(t, sslLibraryRegex.MatchString("/usr/lib/x86_64-linux-gnu/libssl3.so"))
+
+	assert.True(t, cryptoLibraryRegex.MatchString("/lib/libcrypto.so.3"))
+	assert.True(t, gnuTLSLibraryRegex.MatchString("/usr/lib/libgnutls.so.30"))
+}
+
+func TestHooksTelemetry(t *testing.T) {
+	telemetry := newSSLHooksTelemetry("test")
+	attached, failed := telemetry.attached.Get(), telemetry.failed.Get()
+
+	registerCB := withHooksTelemetry("test", func(pathIdentifier, string, string) error { return nil })
+	require.NoError(t, registerCB(pathIdentifier{}, "", ""))
+	assert.Equal(t, attached+1, telemetry.attached.Get())
+
+	registerCB = withHooksTelemetry("test", func(pathIdentifier, string, string) error { return errors.New("no symbol") })
+	require.Error(t, registerCB(pathIdentifier{}, "", ""))
+	assert.Equal(t, failed+1, telemetry.failed.Get())
+}
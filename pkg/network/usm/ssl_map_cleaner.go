@@ -0,0 +1,159 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux_bpf
+// +build linux_bpf
+
+package usm
+
+import (
+	"sync"
+	"time"
+	"unsafe"
+
+	"github.com/cilium/ebpf"
+
+	"github.com/DataDog/datadog-agent/pkg/network/protocols/http"
+	libtelemetry "github.com/DataDog/datadog-agent/pkg/network/protocols/telemetry"
+	"github.com/DataDog/datadog-agent/pkg/process/monitor"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const sslMapCleanupInterval = 30 * time.Second
+
+// sslMapCleaner purges the entries of exited processes from the ssl_ctx_by_pid_tgid and
+// fd_by_ssl_bio maps. These entries are deleted by the uprobes once the SSL calls return,
+// so they leak when a process exits in the middle of a call, e.g. when it crashes.
+//
+// The exited PIDs are collected from the process monitor and purged in batch every
+// sslMapCleanupInterval, to avoid iterating over the maps on every process exit.
+type sslMapCleaner struct {
+	ctxByPIDTGIDMap *ebpf.Map
+	fdByBioMap      *ebpf.Map
+
+	mu         sync.Mutex
+	exitedPIDs map[uint32]struct{}
+
+	ctxByPIDTGIDEntries *libtelemetry.Metric
+	fdByBioEntries      *libtelemetry.Metric
+	purgedEntries       *libtelemetry.Metric
+
+	unsubscribe func()
+	done        chan struct{}
+	wg          sync.WaitGroup
+}
+
+func newSSLMapCleaner(ctxByPIDTGIDMap, fdByBioMap *ebpf.Map) *sslMapCleaner {
+	return &sslMapCleaner{
+		ctxByPIDTGIDMap:     ctxByPIDTGIDMap,
+		fdByBioMap:          fdByBioMap,
+		exitedPIDs:          make(map[uint32]struct{}),
+		ctxByPIDTGIDEntries: libtelemetry.NewMetric("usm.ssl_ctx_by_pid_tgid.entries", libtelemetry.OptStatsd, libtelemetry.OptGauge),
+		fdByBioEntries:      libtelemetry.NewMetric("usm.fd_by_ssl_bio.entries", libtelemetry.OptStatsd, libtelemetry.OptGauge),
+		purgedEntries:       libtelemetry.NewMetric("usm.ssl_maps.purged_entries", libtelemetry.OptStatsd, libtelemetry.OptMonotonic),
+		done:                make(chan struct{}),
+	}
+}
+
+// Start subscribes to the process exit events and starts purging the maps periodically.
+func (c *sslMapCleaner) Start() {
+	var err error
+	c.unsubscribe, err = monitor.GetProcessMonitor().Subscribe(&monitor.ProcessCallback{
+		Event:    monitor.EXIT,
+		Metadata: monitor.ANY,
+		Callback: c.handleProcessExit,
+	})
+	if err != nil {
+		log.Errorf("failed to subscribe Exit process monitor error: %s", err)
+		return
+	}
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		ticker := time.NewTicker(sslMapCleanupInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				c.purge()
+			case <-c.done:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops purging the maps.
+func (c *sslMapCleaner) Stop() {
+	if c.unsubscribe == nil {
+		return
+	}
+	c.unsubscribe()
+	close(c.done)
+	c.wg.Wait()
+}
+
+func (c *sslMapCleaner) handleProcessExit(pid uint32) {
+	c.mu.Lock()
+	c.exitedPIDs[pid] = struct{}{}
+	c.mu.Unlock()
+}
+
+// purge deletes the entries of the processes which exited since the last purge,
+// and reports the number of entries left in the maps.
+func (c *sslMapCleaner) purge() {
+	c.mu.Lock()
+	exitedPIDs := c.exitedPIDs
+	c.exitedPIDs = make(map[uint32]struct{})
+	c.mu.Unlock()
+
+	var pidTGID uint64
+	var ctx uintptr // C.void *
+	remaining, purged := purgeMap(c.ctxByPIDTGIDMap, unsafe.Pointer(&pidTGID), unsafe.Pointer(&ctx), func() uint32 {
+		return uint32(pidTGID >> 32)
+	}, exitedPIDs)
+	c.ctxByPIDTGIDEntries.Set(int64(remaining))
+	c.purgedEntries.Add(int64(purged))
+
+	var bioKey http.SslBioKey
+	var fd uint32
+	remaining, purged = purgeMap(c.fdByBioMap, unsafe.Pointer(&bioKey), unsafe.Pointer(&fd), func() uint32 {
+		return uint32(bioKey.Tgid)
+	}, exitedPIDs)
+	c.fdByBioEntries.Set(int64(remaining))
+	c.purgedEntries.Add(int64(purged))
+}
+
+// purgeMap deletes the entries of m belonging to one of the exited PIDs. The key and value
+// pointers are used to iterate over the map, and pidOf returns the PID of the current key.
+// It returns the number of entries left in the map and the number of deleted entries.
+func purgeMap(m *ebpf.Map, key, value unsafe.Pointer, pidOf func() uint32, exitedPIDs map[uint32]struct{}) (remaining, purged int) {
+	if m == nil {
+		return 0, 0
+	}
+
+	// keys can't be deleted while iterating over the map without risking to restart the iteration
+	var toDelete [][]byte
+	keySize := int(m.KeySize())
+	iter := m.Iterate()
+	for iter.Next(key, value) {
+		if _, exited := exitedPIDs[pidOf()]; !exited {
+			remaining++
+			continue
+		}
+		toDelete = append(toDelete, append([]byte(nil), unsafe.Slice((*byte)(key), keySize)...))
+	}
+	if err := iter.Err(); err != nil {
+		log.Debugf("failed to iterate over map %s: %s", m, err)
+	}
+
+	for _, k := range toDelete {
+		if err := m.Delete(k); err == nil {
+			purged++
+		}
+	}
+	return remaining, purged
+}
@@ -0,0 +1,53 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux_bpf
+// +build linux_bpf
+
+package usm
+
+import (
+	"testing"
+	"unsafe"
+
+	"github.com/cilium/ebpf"
+	"github.com/cilium/ebpf/rlimit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPurgeMap(t *testing.T) {
+	require.NoError(t, rlimit.RemoveMemlock())
+	m, err := ebpf.NewMap(&ebpf.MapSpec{
+		Type:       ebpf.Hash,
+		KeySize:    8,
+		ValueSize:  8,
+		MaxEntries: 16,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { m.Close() })
+
+	pidTGID := func(tgid, pid uint32) uint64 { return uint64(tgid)<<32 | uint64(pid) }
+	for _, key := range []uint64{pidTGID(10, 10), pidTGID(10, 11), pidTGID(20, 20), pidTGID(30, 30)} {
+		require.NoError(t, m.Put(key, uint64(0xdeadbeef)))
+	}
+
+	var key uint64
+	var value uintptr
+	remaining, purged := purgeMap(m, unsafe.Pointer(&key), unsafe.Pointer(&value), func() uint32 {
+		return uint32(key >> 32)
+	}, map[uint32]struct{}{10: {}, 30: {}})
+	assert.Equal(t, 1, remaining)
+	assert.Equal(t, 3, purged)
+
+	var keys []uint64
+	var fd uint64
+	iter := m.Iterate()
+	for iter.Next(&key, &fd) {
+		keys = append(keys, key)
+	}
+	require.NoError(t, iter.Err())
+	assert.Equal(t, []uint64{pidTGID(20, 20)}, keys)
+}
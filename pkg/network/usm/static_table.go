@@ -0,0 +1,189 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux_bpf
+// +build linux_bpf
+
+package usm
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+	"unsafe"
+
+	"github.com/cilium/ebpf"
+	"golang.org/x/sys/unix"
+
+	"github.com/DataDog/datadog-agent/pkg/network/protocols/http"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	// hpackStaticTableSize is the number of entries of the HPACK static table
+	// https://httpwg.org/specs/rfc7541.html#static.table.definition
+	hpackStaticTableSize = 61
+
+	staticTableLoadRetries       = 3
+	staticTableLoadRetryInterval = 10 * time.Millisecond
+)
+
+type staticTableState = string
+
+const (
+	staticTableNotLoaded staticTableState = "Not Loaded"
+	staticTableLoaded    staticTableState = "Loaded"
+	staticTableFailed    staticTableState = "Failed"
+)
+
+// The staticTableEntry represents an entry in the static table that contains an index in the table and a value.
+// The value itself contains both the key and the corresponding value in the static table.
+// For instance, index 2 in the static table has a value of method: GET, and index 3 has a value of method: POST.
+// It is not possible to save the index by the key because we need to distinguish between the values attached to the key.
+type staticTableEntry struct {
+	Index uint8
+	Value http.StaticTableValue
+}
+
+// http2StaticTableEntries are the entries of the HPACK static table supported by the http2 decoding
+var http2StaticTableEntries = []staticTableEntry{
+	{Index: 2, Value: http.StaticTableValue{Key: http.MethodKey, Value: http.GetValue}},
+	{Index: 3, Value: http.StaticTableValue{Key: http.MethodKey, Value: http.PostValue}},
+	{Index: 4, Value: http.StaticTableValue{Key: http.PathKey, Value: http.EmptyPathValue}},
+	{Index: 5, Value: http.StaticTableValue{Key: http.PathKey, Value: http.IndexPathValue}},
+	{Index: 8, Value: http.StaticTableValue{Key: http.StatusKey, Value: http.K200Value}},
+	{Index: 9, Value: http.StaticTableValue{Key: http.StatusKey, Value: http.K204Value}},
+	{Index: 10, Value: http.StaticTableValue{Key: http.StatusKey, Value: http.K206Value}},
+	{Index: 11, Value: http.StaticTableValue{Key: http.StatusKey, Value: http.K304Value}},
+	{Index: 12, Value: http.StaticTableValue{Key: http.StatusKey, Value: http.K400Value}},
+	{Index: 13, Value: http.StaticTableValue{Key: http.StatusKey, Value: http.K404Value}},
+	{Index: 14, Value: http.StaticTableValue{Key: http.StatusKey, Value: http.K500Value}},
+}
+
+// staticTableStatus is the outcome of the seeding of the static table, reported by `system-probe status`
+type staticTableStatus struct {
+	State   staticTableState `json:"state"`
+	Entries int              `json:"entries"`
+	Retries int              `json:"retries,omitempty"`
+	Error   string           `json:"error,omitempty"`
+}
+
+// staticTableLoader seeds the http2 static table map. All the entries are validated before the map is written, and
+// the insertions failing with a transient error are retried a few times before giving up.
+type staticTableLoader struct {
+	entries       []staticTableEntry
+	retries       int
+	retryInterval time.Duration
+
+	mux    sync.Mutex
+	status staticTableStatus
+}
+
+func newStaticTableLoader(entries []staticTableEntry) *staticTableLoader {
+	return &staticTableLoader{
+		entries:       entries,
+		retries:       staticTableLoadRetries,
+		retryInterval: staticTableLoadRetryInterval,
+		status:        staticTableStatus{State: staticTableNotLoaded},
+	}
+}
+
+// Load validates the entries of the loader and inserts them in the given map
+func (l *staticTableLoader) Load(m *ebpf.Map) error {
+	var retries int
+	err := l.load(m, &retries)
+
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	l.status.Retries = retries
+	if err != nil {
+		log.Errorf("error loading the http2 static table: %s", err)
+		l.status.State = staticTableFailed
+		l.status.Error = err.Error()
+		return err
+	}
+
+	log.Debugf("loaded %d entries in the http2 static table", len(l.entries))
+	l.status.State = staticTableLoaded
+	l.status.Entries = len(l.entries)
+	l.status.Error = ""
+	return nil
+}
+
+// Status returns the outcome of the last load
+func (l *staticTableLoader) Status() staticTableStatus {
+	if l == nil {
+		return staticTableStatus{State: staticTableNotLoaded}
+	}
+
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	return l.status
+}
+
+func (l *staticTableLoader) load(m *ebpf.Map, retries *int) error {
+	if m == nil {
+		return errors.New("http2 static table is null")
+	}
+	if err := l.validate(m); err != nil {
+		return err
+	}
+
+	for _, entry := range l.entries {
+		entry := entry
+		err := m.Put(unsafe.Pointer(&entry.Index), unsafe.Pointer(&entry.Value))
+		for attempt := 0; err != nil && isTransientMapError(err) && attempt < l.retries; attempt++ {
+			log.Debugf("transient error inserting index %d in the http2 static table, retrying: %s", entry.Index, err)
+			*retries++
+			time.Sleep(l.retryInterval)
+			err = m.Put(unsafe.Pointer(&entry.Index), unsafe.Pointer(&entry.Value))
+		}
+		if err != nil {
+			return fmt.Errorf("could not insert index %d in the http2 static table: %w", entry.Index, err)
+		}
+	}
+	return nil
+}
+
+// validate checks that all the entries fit in the map before any of them is inserted
+func (l *staticTableLoader) validate(m *ebpf.Map) error {
+	var entry staticTableEntry
+	if keySize := uint32(unsafe.Sizeof(entry.Index)); m.KeySize() != keySize {
+		return fmt.Errorf("http2 static table key size is %d, expected %d", m.KeySize(), keySize)
+	}
+	if valueSize := uint32(unsafe.Sizeof(entry.Value)); m.ValueSize() != valueSize {
+		return fmt.Errorf("http2 static table value size is %d, expected %d", m.ValueSize(), valueSize)
+	}
+	if len(l.entries) > int(m.MaxEntries()) {
+		return fmt.Errorf("http2 static table can hold %d entries, got %d", m.MaxEntries(), len(l.entries))
+	}
+	return validateStaticTableEntries(l.entries)
+}
+
+func validateStaticTableEntries(entries []staticTableEntry) error {
+	seen := make(map[uint8]struct{}, len(entries))
+	for _, entry := range entries {
+		if entry.Index == 0 || entry.Index > hpackStaticTableSize {
+			return fmt.Errorf("invalid http2 static table index %d", entry.Index)
+		}
+		if _, ok := seen[entry.Index]; ok {
+			return fmt.Errorf("duplicate http2 static table index %d", entry.Index)
+		}
+		seen[entry.Index] = struct{}{}
+
+		switch entry.Value.Key {
+		case http.MethodKey, http.PathKey, http.StatusKey:
+		default:
+			return fmt.Errorf("invalid key %d for http2 static table index %d", entry.Value.Key, entry.Index)
+		}
+	}
+	return nil
+}
+
+// isTransientMapError returns true if the insertion of an element in a map may succeed when retried
+func isTransientMapError(err error) bool {
+	return errors.Is(err, unix.EAGAIN) || errors.Is(err, unix.EBUSY) || errors.Is(err, unix.ENOMEM)
+}
@@ -0,0 +1,100 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux_bpf
+// +build linux_bpf
+
+package usm
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/cilium/ebpf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+
+	"github.com/DataDog/datadog-agent/pkg/network/protocols/http"
+)
+
+func newStaticTableMap(t *testing.T, maxEntries uint32) *ebpf.Map {
+	m, err := ebpf.NewMap(&ebpf.MapSpec{
+		Type:       ebpf.Hash,
+		KeySize:    1,
+		ValueSize:  2,
+		MaxEntries: maxEntries,
+	})
+	if err != nil {
+		t.Skipf("could not create map: %s", err)
+	}
+	t.Cleanup(func() { m.Close() })
+	return m
+}
+
+func TestValidateStaticTableEntries(t *testing.T) {
+	require.NoError(t, validateStaticTableEntries(http2StaticTableEntries))
+
+	get := http.StaticTableValue{Key: http.MethodKey, Value: http.GetValue}
+	tests := []struct {
+		name    string
+		entries []staticTableEntry
+	}{
+		{name: "zero index", entries: []staticTableEntry{{Index: 0, Value: get}}},
+		{name: "index out of the static table", entries: []staticTableEntry{{Index: hpackStaticTableSize + 1, Value: get}}},
+		{name: "duplicate index", entries: []staticTableEntry{{Index: 2, Value: get}, {Index: 2, Value: get}}},
+		{name: "unknown key", entries: []staticTableEntry{{Index: 2, Value: http.StaticTableValue{Key: 1, Value: http.GetValue}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Error(t, validateStaticTableEntries(tt.entries))
+		})
+	}
+}
+
+func TestStaticTableLoader(t *testing.T) {
+	t.Run("load", func(t *testing.T) {
+		m := newStaticTableMap(t, 20)
+		l := newStaticTableLoader(http2StaticTableEntries)
+		require.Equal(t, staticTableNotLoaded, l.Status().State)
+
+		require.NoError(t, l.Load(m))
+		require.Equal(t, staticTableStatus{State: staticTableLoaded, Entries: len(http2StaticTableEntries)}, l.Status())
+
+		for _, entry := range http2StaticTableEntries {
+			var value http.StaticTableValue
+			require.NoError(t, m.Lookup(entry.Index, &value))
+			assert.Equal(t, entry.Value, value)
+		}
+	})
+
+	t.Run("too many entries", func(t *testing.T) {
+		m := newStaticTableMap(t, 2)
+		l := newStaticTableLoader(http2StaticTableEntries)
+
+		require.Error(t, l.Load(m))
+		status := l.Status()
+		assert.Equal(t, staticTableFailed, status.State)
+		assert.NotEmpty(t, status.Error)
+
+		// nothing is inserted when the validation fails
+		count, err := countEntries(m)
+		require.NoError(t, err)
+		assert.Zero(t, count)
+	})
+
+	t.Run("nil map", func(t *testing.T) {
+		l := newStaticTableLoader(http2StaticTableEntries)
+		require.Error(t, l.Load(nil))
+		assert.Equal(t, staticTableFailed, l.Status().State)
+	})
+}
+
+func TestIsTransientMapError(t *testing.T) {
+	assert.True(t, isTransientMapError(fmt.Errorf("update: %w", unix.EAGAIN)))
+	assert.True(t, isTransientMapError(unix.ENOMEM))
+	assert.False(t, isTransientMapError(unix.E2BIG))
+	assert.False(t, isTransientMapError(ebpf.ErrKeyNotExist))
+}
@@ -6,13 +6,25 @@
 package checks
 
 import (
+	"errors"
 	"sync"
 
 	procmodel "github.com/DataDog/agent-payload/v5/process"
+
+	"github.com/DataDog/datadog-agent/pkg/process/procutil"
 )
 
 var checkOutputs sync.Map
 
+// ErrProcessCheckNotRunning is returned by InspectProcess when the process check isn't running
+var ErrProcessCheckNotRunning = errors.New("process check is not running")
+
+var (
+	processInspectorMu sync.RWMutex
+	// processInspector is the process check, which inspects the processes on demand, once initialized
+	processInspector *ProcessCheck
+)
+
 // StoreCheckOutput stores the output of a check. We use helpers instead of checkOutputs directly to preserve type safety.
 func StoreCheckOutput(checkName string, message []procmodel.MessageBody) {
 	if message == nil {
@@ -29,3 +41,21 @@ func GetCheckOutput(checkName string) (value []procmodel.MessageBody, ok bool) {
 	}
 	return nil, false
 }
+
+// storeProcessInspector sets the process check inspecting the processes on demand, nil once it's cleaned up
+func storeProcessInspector(p *ProcessCheck) {
+	processInspectorMu.Lock()
+	defer processInspectorMu.Unlock()
+	processInspector = p
+}
+
+// InspectProcess returns the extended data of a single process, collected on demand by the process check for the
+// process details feature. It returns ErrProcessCheckNotRunning when the process check isn't running.
+func InspectProcess(pid int32) (*procutil.ProcessDetails, error) {
+	processInspectorMu.RLock()
+	defer processInspectorMu.RUnlock()
+	if processInspector == nil {
+		return nil, ErrProcessCheckNotRunning
+	}
+	return processInspector.InspectProcess(pid)
+}
@@ -0,0 +1,27 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"strconv"
+
+	"github.com/DataDog/datadog-go/v5/statsd"
+
+	"github.com/DataDog/datadog-agent/pkg/process/procutil"
+)
+
+// reportGPUStats sends the GPU usage of the processes running on a GPU as gauges tagged by process,
+// as the process payload has no field for it. Only the processes running on a GPU are reported.
+func reportGPUStats(client statsd.ClientInterface, procs map[int32]*procutil.Process) {
+	for pid, proc := range procs {
+		if proc.Stats == nil || proc.Stats.GPUStat == nil {
+			continue
+		}
+		tags := []string{"pid:" + strconv.Itoa(int(pid)), "process_name:" + proc.Name}
+		client.Gauge("datadog.process.gpu.memory_bytes", float64(proc.Stats.GPUStat.MemoryBytes), tags, 1) //nolint:errcheck
+		client.Gauge("datadog.process.gpu.utilization_pct", proc.Stats.GPUStat.UtilizationPct, tags, 1)    //nolint:errcheck
+	}
+}
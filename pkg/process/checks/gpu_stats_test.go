@@ -0,0 +1,27 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"testing"
+
+	mock_statsd "github.com/DataDog/datadog-go/v5/statsd/mocks"
+	"github.com/golang/mock/gomock"
+
+	"github.com/DataDog/datadog-agent/pkg/process/procutil"
+)
+
+func TestReportGPUStats(t *testing.T) {
+	client := mock_statsd.NewMockClientInterface(gomock.NewController(t))
+	tags := []string{"pid:1", "process_name:python"}
+	client.EXPECT().Gauge("datadog.process.gpu.memory_bytes", float64(1024), tags, float64(1)).Return(nil).Times(1)
+	client.EXPECT().Gauge("datadog.process.gpu.utilization_pct", float64(45), tags, float64(1)).Return(nil).Times(1)
+
+	reportGPUStats(client, map[int32]*procutil.Process{
+		1: {Pid: 1, Name: "python", Stats: &procutil.Stats{GPUStat: &procutil.GPUStat{MemoryBytes: 1024, UtilizationPct: 45}}},
+		2: {Pid: 2, Name: "bash", Stats: &procutil.Stats{}},
+	})
+}
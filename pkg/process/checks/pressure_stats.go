@@ -0,0 +1,62 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"time"
+
+	"github.com/DataDog/datadog-go/v5/statsd"
+
+	"github.com/DataDog/datadog-agent/pkg/process/procutil"
+)
+
+// reportPressureStats sends the run queue wait time and the IO pressure of the processes as gauges, as the
+// process payload has no field for them. To bound the cardinality, the stats are aggregated by container,
+// and the processes running outside of a container are reported together without a container_id tag.
+func reportPressureStats(client statsd.ClientInterface, procs, lastProcs map[int32]*procutil.Process, ctrByProc map[int]string, elapsed time.Duration) {
+	if elapsed <= 0 {
+		return
+	}
+
+	waitTimeNs := make(map[string]uint64)
+	ioPressure := make(map[string]*procutil.PressureStat)
+	for pid, proc := range procs {
+		if proc.Stats == nil {
+			continue
+		}
+		cid := ctrByProc[int(pid)]
+
+		if proc.Stats.SchedStat != nil {
+			// the processes which weren't there at the last run have no wait time to compare with
+			if last, ok := lastProcs[pid]; ok && last.Stats != nil && last.Stats.SchedStat != nil &&
+				proc.Stats.SchedStat.WaitTimeNs >= last.Stats.SchedStat.WaitTimeNs {
+				waitTimeNs[cid] += proc.Stats.SchedStat.WaitTimeNs - last.Stats.SchedStat.WaitTimeNs
+			}
+		}
+
+		// the PSI is per cgroup, so every process of a container has the same one
+		if cid != "" && proc.Stats.IOPressure != nil {
+			ioPressure[cid] = proc.Stats.IOPressure
+		}
+	}
+
+	for cid, wait := range waitTimeNs {
+		// the wait time of several threads may exceed the elapsed time
+		client.Gauge("datadog.process.sched.wait_time_pct", float64(wait)/float64(elapsed.Nanoseconds())*100, containerTags(cid), 1) //nolint:errcheck
+	}
+	for cid, pressure := range ioPressure {
+		tags := containerTags(cid)
+		client.Gauge("datadog.process.io_pressure.some_avg10", pressure.Some.Avg10, tags, 1) //nolint:errcheck
+		client.Gauge("datadog.process.io_pressure.full_avg10", pressure.Full.Avg10, tags, 1) //nolint:errcheck
+	}
+}
+
+func containerTags(cid string) []string {
+	if cid == "" {
+		return []string{}
+	}
+	return []string{"container_id:" + cid}
+}
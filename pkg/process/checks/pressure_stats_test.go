@@ -0,0 +1,49 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"testing"
+	"time"
+
+	mock_statsd "github.com/DataDog/datadog-go/v5/statsd/mocks"
+	"github.com/golang/mock/gomock"
+
+	"github.com/DataDog/datadog-agent/pkg/process/procutil"
+)
+
+func TestReportPressureStats(t *testing.T) {
+	withSchedStat := func(waitTimeNs uint64, pressure *procutil.PressureStat) *procutil.Process {
+		return &procutil.Process{Stats: &procutil.Stats{SchedStat: &procutil.SchedStat{WaitTimeNs: waitTimeNs}, IOPressure: pressure}}
+	}
+	pressure := &procutil.PressureStat{Some: procutil.PressureLineStat{Avg10: 2}, Full: procutil.PressureLineStat{Avg10: 1}}
+
+	lastProcs := map[int32]*procutil.Process{
+		1: withSchedStat(1000, nil),
+		2: withSchedStat(0, pressure),
+		3: withSchedStat(0, pressure),
+	}
+	procs := map[int32]*procutil.Process{
+		1: withSchedStat(1000+nanoseconds(500*time.Millisecond), nil),
+		2: withSchedStat(nanoseconds(time.Second), pressure),
+		3: withSchedStat(nanoseconds(time.Second), pressure),
+		// new processes are only reported from the next run
+		4: withSchedStat(nanoseconds(time.Hour), pressure),
+	}
+	ctrByProc := map[int]string{2: "ctr", 3: "ctr", 4: "ctr"}
+
+	client := mock_statsd.NewMockClientInterface(gomock.NewController(t))
+	client.EXPECT().Gauge("datadog.process.sched.wait_time_pct", float64(5), []string{}, float64(1)).Return(nil).Times(1)
+	client.EXPECT().Gauge("datadog.process.sched.wait_time_pct", float64(20), []string{"container_id:ctr"}, float64(1)).Return(nil).Times(1)
+	client.EXPECT().Gauge("datadog.process.io_pressure.some_avg10", float64(2), []string{"container_id:ctr"}, float64(1)).Return(nil).Times(1)
+	client.EXPECT().Gauge("datadog.process.io_pressure.full_avg10", float64(1), []string{"container_id:ctr"}, float64(1)).Return(nil).Times(1)
+
+	reportPressureStats(client, procs, lastProcs, ctrByProc, 10*time.Second)
+}
+
+func nanoseconds(d time.Duration) uint64 {
+	return uint64(d.Nanoseconds())
+}
@@ -126,6 +126,7 @@ func (p *ProcessCheck) Init(syscfg *SysProbeConfig, info *HostInfo) error {
 	p.disallowList = initDisallowList(p.config)
 
 	p.initConnRates()
+	storeProcessInspector(p)
 	return nil
 }
 
@@ -176,7 +177,9 @@ func (p *ProcessCheck) Realtime() bool { return false }
 func (p *ProcessCheck) ShouldSaveLastRun() bool { return true }
 
 // Cleanup frees any resource held by the ProcessCheck before the agent exits
-func (p *ProcessCheck) Cleanup() {}
+func (p *ProcessCheck) Cleanup() {
+	storeProcessInspector(nil)
+}
 
 func (p *ProcessCheck) run(groupID int32, collectRealTime bool) (RunResult, error) {
 	start := time.Now()
@@ -242,6 +245,8 @@ func (p *ProcessCheck) run(groupID int32, collectRealTime bool) (RunResult, erro
 	procsByCtr := fmtProcesses(p.scrubber, p.disallowList, procs, p.lastProcs, pidToCid, cpuTimes[0], p.lastCPUTime, p.lastRun, connsRates, p.lookupIdProbe)
 	messages, totalProcs, totalContainers := createProcCtrMessages(p.hostInfo, procsByCtr, containers, p.maxBatchSize, p.maxBatchBytes, groupID, p.networkID, collectorProcHints)
 
+	reportPressureStats(statsd.Client, procs, p.lastProcs, pidToCid, time.Since(p.lastRun))
+
 	// Store the last state for comparison on the next run.
 	// Note: not storing the filtered in case there are new processes that haven't had a chance to show up twice.
 	p.lastProcs = procs
@@ -283,6 +288,7 @@ func (p *ProcessCheck) run(groupID int32, collectRealTime bool) (RunResult, erro
 
 	statsd.Client.Gauge("datadog.process.containers.host_count", float64(totalContainers), []string{}, 1) //nolint:errcheck
 	statsd.Client.Gauge("datadog.process.processes.host_count", float64(totalProcs), []string{}, 1)       //nolint:errcheck
+	reportGPUStats(statsd.Client, procs)
 	log.Debugf("collected processes in %s", time.Now().Sub(start))
 
 	return result, nil
@@ -324,6 +330,15 @@ func (p *ProcessCheck) Run(nextGroupID func() int32, options *RunOptions) (RunRe
 	return nil, errors.New("invalid run options for check")
 }
 
+// InspectProcess returns the extended data of a single process, collected on demand for the process details feature.
+// The inspections are rate limited by process_config.process_details.max_per_second, see procutil.ErrInspectionRateLimited.
+func (p *ProcessCheck) InspectProcess(pid int32) (*procutil.ProcessDetails, error) {
+	if p.probe == nil {
+		return nil, errors.New("process check is not initialized")
+	}
+	return p.probe.InspectProcess(pid)
+}
+
 func createProcCtrMessages(
 	hostInfo *HostInfo,
 	procsByCtr map[string][]*model.Process,
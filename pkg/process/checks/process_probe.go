@@ -14,5 +14,11 @@ import (
 )
 
 func newProcessProbe(config config.ConfigReader, options ...procutil.Option) procutil.Probe {
+	options = append(options,
+		procutil.WithGPUStats(config.GetBool("process_config.gpu_stats.enabled")),
+		procutil.WithPressureStats(config.GetBool("process_config.pressure_stats.enabled")),
+		procutil.WithInspectionRateLimit(config.GetFloat64("process_config.process_details.max_per_second")),
+		procutil.WithInspectionEnvAllowlist(config.GetStringSlice("process_config.process_details.env_allowlist")),
+	)
 	return procutil.NewProcessProbe(options...)
 }
@@ -438,3 +438,32 @@ func BenchmarkProcessCheck(b *testing.B) {
 		require.NoError(b, err)
 	}
 }
+
+func TestProcessCheckInspectProcess(t *testing.T) {
+	processCheck, probe := processCheckWithMockProbe(t)
+
+	details := &procutil.ProcessDetails{Pid: 1, Cwd: "/"}
+	probe.On("InspectProcess", int32(1)).Return(details, nil)
+	probe.On("InspectProcess", int32(2)).Return(nil, procutil.ErrInspectionRateLimited)
+
+	actual, err := processCheck.InspectProcess(1)
+	require.NoError(t, err)
+	assert.Equal(t, details, actual)
+
+	_, err = processCheck.InspectProcess(2)
+	assert.ErrorIs(t, err, procutil.ErrInspectionRateLimited)
+
+	_, err = NewProcessCheck(ddconfig.Mock(t)).InspectProcess(1)
+	assert.Error(t, err)
+
+	// the processes are inspected by the process check while it's running
+	_, err = InspectProcess(1)
+	assert.ErrorIs(t, err, ErrProcessCheckNotRunning)
+	storeProcessInspector(processCheck)
+	actual, err = InspectProcess(1)
+	require.NoError(t, err)
+	assert.Equal(t, details, actual)
+	processCheck.Cleanup()
+	_, err = InspectProcess(1)
+	assert.ErrorIs(t, err, ErrProcessCheckNotRunning)
+}
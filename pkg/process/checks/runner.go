@@ -16,6 +16,9 @@ import (
 type RunnerConfig struct {
 	CheckInterval time.Duration
 	RtInterval    time.Duration
+	// RtBudget is the time a realtime run can take before realtime runs are backed off.
+	// Defaults to half of the realtime interval.
+	RtBudget time.Duration
 
 	ExitChan       chan struct{}
 	RtIntervalChan chan time.Duration
@@ -29,6 +32,12 @@ type runnerWithRealTime struct {
 	counter    int
 	newTicker  func(d time.Duration) *time.Ticker
 	stopTicker func(t *time.Ticker)
+	now        func() time.Time
+
+	// rtBackoff is the number of realtime ticks between two realtime runs, 1 meaning no backoff
+	rtBackoff int
+	// rtSkip is the number of realtime ticks left to skip before the next realtime run
+	rtSkip int
 }
 
 // NewRunnerWithRealTime creates a runner func for CheckWithRealTime
@@ -54,6 +63,10 @@ func (r *runnerWithRealTime) run() {
 	if err != nil {
 		return
 	}
+	if r.now == nil {
+		r.now = time.Now
+	}
+	r.resetBackoff()
 
 	// Run the check the first time to prime the caches.
 	r.RunCheck(RunOptions{
@@ -69,11 +82,13 @@ func (r *runnerWithRealTime) run() {
 			}
 
 			rtEnabled := r.RtEnabled()
-			if rtEnabled || r.counter == 0 {
+			if r.counter == 0 {
 				r.RunCheck(RunOptions{
-					RunStandard: r.counter == 0,
+					RunStandard: true,
 					RunRealtime: rtEnabled,
 				})
+			} else if rtEnabled {
+				r.runRealTime()
 			}
 
 			r.counter++
@@ -90,6 +105,7 @@ func (r *runnerWithRealTime) run() {
 
 			r.ratio = newRatio
 			r.counter = 0
+			r.resetBackoff()
 		case _, ok := <-r.ExitChan:
 			if !ok {
 				return
@@ -98,6 +114,50 @@ func (r *runnerWithRealTime) run() {
 	}
 }
 
+// runRealTime performs a realtime only run, backing off when runs exceed the realtime budget so that
+// realtime collection doesn't monopolize the CPU on large hosts
+func (r *runnerWithRealTime) runRealTime() {
+	if r.rtSkip > 0 {
+		r.rtSkip--
+		return
+	}
+
+	start := r.now()
+	r.RunCheck(RunOptions{
+		RunRealtime: true,
+	})
+	elapsed := r.now().Sub(start)
+
+	if r.rtBackoff < 1 {
+		r.rtBackoff = 1
+	}
+
+	budget := r.rtBudget()
+	if elapsed > budget && r.rtBackoff < r.ratio {
+		r.rtBackoff *= 2
+		if r.rtBackoff > r.ratio {
+			r.rtBackoff = r.ratio
+		}
+		log.Infof("realtime run took %s, exceeding the %s budget, running realtime every %s", elapsed, budget, time.Duration(r.rtBackoff)*r.RtInterval)
+	} else if elapsed <= budget/2 && r.rtBackoff > 1 {
+		r.rtBackoff /= 2
+		log.Infof("realtime run took %s, running realtime every %s", elapsed, time.Duration(r.rtBackoff)*r.RtInterval)
+	}
+	r.rtSkip = r.rtBackoff - 1
+}
+
+func (r *runnerWithRealTime) rtBudget() time.Duration {
+	if r.RtBudget > 0 {
+		return r.RtBudget
+	}
+	return r.RtInterval / 2
+}
+
+func (r *runnerWithRealTime) resetBackoff() {
+	r.rtBackoff = 1
+	r.rtSkip = 0
+}
+
 func getRtRatio(checkInterval, rtInterval time.Duration) (int, error) {
 	if checkInterval < rtInterval {
 		return -1, errors.New("check interval should be larger or equal to RT interval")
@@ -153,3 +153,71 @@ func TestRunnerWithRealTime_ReconfigureInterval(t *testing.T) {
 	assert.Equal(t, 10, r.ratio)
 	assert.Equal(t, 0, r.counter)
 }
+
+func TestRunnerWithRealTime_AdaptiveBackoff(t *testing.T) {
+	exitChan := make(chan struct{})
+
+	rtIntervalChan := make(chan time.Duration)
+	defer close(rtIntervalChan)
+
+	tickerCh := make(chan time.Time)
+	defer close(tickerCh)
+	ticker := &time.Ticker{
+		C: tickerCh,
+	}
+
+	// the first two realtime runs exceed the 1s budget, the following ones are fast
+	var clock time.Time
+	var runs []RunOptions
+	var backoffs []int
+	r := &runnerWithRealTime{
+		RunnerConfig: RunnerConfig{
+			CheckInterval: 30 * time.Second,
+			RtInterval:    2 * time.Second,
+
+			ExitChan:       exitChan,
+			RtIntervalChan: rtIntervalChan,
+			RtEnabled:      func() bool { return true },
+		},
+		newTicker:  func(time.Duration) *time.Ticker { return ticker },
+		stopTicker: func(t *time.Ticker) {},
+	}
+	r.RunCheck = func(options RunOptions) {
+		runs = append(runs, options)
+		if !options.RunStandard {
+			backoffs = append(backoffs, r.rtBackoff)
+			if len(backoffs) <= 2 {
+				clock = clock.Add(3 * time.Second)
+			} else {
+				clock = clock.Add(100 * time.Millisecond)
+			}
+		}
+	}
+	r.now = func() time.Time { return clock }
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		r.run()
+	}()
+
+	for i := 0; i < 11; i++ {
+		tickerCh <- time.Now()
+	}
+
+	close(exitChan)
+	wg.Wait()
+
+	assert.Equal(t, []RunOptions{
+		runOptionsWithStandard,
+		runOptionsWithBoth,
+		runOptionsWithRealTime,
+		runOptionsWithRealTime,
+		runOptionsWithRealTime,
+		runOptionsWithRealTime,
+		runOptionsWithRealTime,
+	}, runs)
+	assert.Equal(t, []int{1, 2, 4, 2, 1}, backoffs)
+	assert.Equal(t, 1, r.rtBackoff)
+}
@@ -0,0 +1,49 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ddconfig "github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/process/procutil"
+)
+
+func TestExtractServiceMetadataInferred(t *testing.T) {
+	procRoot := t.TempDir()
+	for pid, files := range map[string]map[string]string{
+		"1": {"environ": "PATH=/usr/bin\x00DD_SERVICE=billing\x00", "cgroup": "0::/system.slice/nginx.service\n"},
+		"2": {"cgroup": "0::/system.slice/nginx.service\n"},
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Join(procRoot, pid), 0755))
+		for name, content := range files {
+			require.NoError(t, os.WriteFile(filepath.Join(procRoot, pid, name), []byte(content), 0644))
+		}
+	}
+
+	mockConfig := ddconfig.MockSystemProbe(t)
+	mockConfig.Set("service_monitoring_config.process_service_inference.enabled", true)
+	se := NewServiceExtractor(mockConfig)
+	se.procRoot = procRoot
+	se.Extract(map[int32]*procutil.Process{
+		1: {Pid: 1, Cmdline: []string{"/usr/bin/python", "app.py"}},
+		2: {Pid: 2, Cmdline: []string{"/usr/sbin/nginx"}},
+		3: {Pid: 3, Cmdline: []string{"/usr/bin/python", "app.py"}},
+	})
+
+	// the service is taken from the first available of DD_SERVICE, the systemd unit and the command line
+	assert.Equal(t, []string{"process_context:billing"}, se.GetServiceContext(1))
+	assert.Equal(t, []string{"process_context:nginx"}, se.GetServiceContext(2))
+	assert.Equal(t, []string{"process_context:app.py"}, se.GetServiceContext(3))
+}
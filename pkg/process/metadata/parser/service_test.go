@@ -132,6 +132,8 @@ func TestExtractServiceMetadata(t *testing.T) {
 			procsByPid := map[int32]*procutil.Process{proc.Pid: &proc}
 
 			se := NewServiceExtractor(mockConfig)
+			// the service is only inferred from the command line
+			se.procRoot = t.TempDir()
 			se.Extract(procsByPid)
 			assert.Equal(t, []string{tt.expectedServiceTag}, se.GetServiceContext(proc.Pid))
 		})
@@ -11,9 +11,13 @@ package monitor
 import (
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"regexp"
 	"runtime"
+	"strconv"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/DataDog/gopsutil/process"
@@ -58,7 +62,7 @@ type ProcessMonitor struct {
 
 	// callback registration and parallel execution management
 	procEventCallbacks map[ProcessEventType][]*ProcessCallback
-	runningPids        map[uint32]interface{}
+	runningPids        map[uint32]processMetadata
 	callbackRunner     chan func()
 
 	// monitor stats
@@ -79,17 +83,69 @@ type ProcessMetadataField int
 const (
 	ANY ProcessMetadataField = iota
 	NAME
+	EXE
 )
 
-type metadataName struct {
-	Name string
+// processMetadata holds the metadata of a process needed to evaluate the callbacks filters
+type processMetadata struct {
+	Name  string
+	Exe   string
+	PIDNS uint64
 }
 
 type ProcessCallback struct {
 	Event    ProcessEventType
 	Metadata ProcessMetadataField
 	Regex    *regexp.Regexp
-	Callback func(pid uint32)
+	// PIDNamespaces restricts the callback to the processes running in one of the given
+	// PID namespaces (identified by their inode number). All namespaces match if empty.
+	PIDNamespaces []uint64
+	Callback      func(pid uint32)
+}
+
+// hasFilter returns whether the callback requires the process metadata to be evaluated
+func (c *ProcessCallback) hasFilter() bool {
+	return c.Metadata != ANY || len(c.PIDNamespaces) > 0
+}
+
+// matches returns whether the process metadata matches the callback filters
+func (c *ProcessCallback) matches(metadata processMetadata) bool {
+	switch c.Metadata {
+	case NAME:
+		if !c.Regex.MatchString(metadata.Name) {
+			return false
+		}
+	case EXE:
+		if !c.Regex.MatchString(metadata.Exe) {
+			return false
+		}
+	}
+
+	if len(c.PIDNamespaces) == 0 {
+		return true
+	}
+	for _, ns := range c.PIDNamespaces {
+		if ns == metadata.PIDNS {
+			return true
+		}
+	}
+	return false
+}
+
+// sameFilter returns whether both callbacks have the same filters
+func (c *ProcessCallback) sameFilter(other *ProcessCallback) bool {
+	if c.Metadata != other.Metadata || len(c.PIDNamespaces) != len(other.PIDNamespaces) {
+		return false
+	}
+	if c.Metadata != ANY && c.Regex.String() != other.Regex.String() {
+		return false
+	}
+	for i := range c.PIDNamespaces {
+		if c.PIDNamespaces[i] != other.PIDNamespaces[i] {
+			return false
+		}
+	}
+	return true
 }
 
 // GetProcessMonitor create a monitor (only once) that register to netlink process events.
@@ -99,6 +155,8 @@ type ProcessCallback struct {
 // Filter can be applied on :
 //
 //	process name (NAME)
+//	process executable path (EXE)
+//	PID namespaces of the process (PIDNamespaces)
 //	by default ANY is applied
 //
 // Typical initialization:
@@ -123,26 +181,23 @@ func GetProcessMonitor() *ProcessMonitor {
 		processMonitor = &ProcessMonitor{
 			isInitialized:      false,
 			procEventCallbacks: make(map[ProcessEventType][]*ProcessCallback),
-			runningPids:        make(map[uint32]interface{}),
+			runningPids:        make(map[uint32]processMetadata),
 		}
 	})
 
 	return processMonitor
 }
 
-func (pm *ProcessMonitor) enqueueCallback(callback *ProcessCallback, pid uint32, metadata interface{}) {
-	if callback.Event == EXEC && callback.Metadata != ANY {
-		switch callback.Metadata {
-		case NAME:
-			pm.runningPids[pid] = metadata
-		}
+func (pm *ProcessMonitor) enqueueCallback(callback *ProcessCallback, pid uint32, metadata *processMetadata) {
+	if callback.Event == EXEC && metadata != nil {
+		pm.runningPids[pid] = *metadata
 	}
 	pm.callbackRunner <- func() { callback.Callback(pid) }
 }
 
 // evalEXECCallback is a best effort and would not return errors, but report them
 func (pm *ProcessMonitor) evalEXECCallback(c *ProcessCallback, pid uint32) {
-	if c.Metadata == ANY {
+	if !c.hasFilter() {
 		pm.enqueueCallback(c, pid, nil)
 		return
 	}
@@ -166,35 +221,56 @@ func (pm *ProcessMonitor) evalEXECCallback(c *ProcessCallback, pid uint32) {
 		return
 	}
 
+	// the metadata collected for other callbacks are kept
+	metadata := pm.runningPids[pid]
 	switch c.Metadata {
 	case NAME:
-		pname, err := proc.Name()
-		if err != nil {
+		if metadata.Name, err = proc.Name(); err != nil {
 			log.Debugf("process %d name parsing failed %s", pid, err)
 			return
 		}
-		if c.Regex.MatchString(pname) {
-			pm.enqueueCallback(c, pid, metadataName{Name: pname})
+	case EXE:
+		if metadata.Exe, err = proc.Exe(); err != nil {
+			log.Debugf("process %d exe parsing failed %s", pid, err)
+			return
 		}
 	}
+	if len(c.PIDNamespaces) > 0 {
+		if metadata.PIDNS, err = getPIDNamespace(pid); err != nil {
+			log.Debugf("process %d pid namespace parsing failed %s", pid, err)
+			return
+		}
+	}
+
+	if c.matches(metadata) {
+		pm.enqueueCallback(c, pid, &metadata)
+	}
+}
+
+// getPIDNamespace returns the inode number of the PID namespace of the given process
+func getPIDNamespace(pid uint32) (uint64, error) {
+	var stat syscall.Stat_t
+	if err := syscall.Stat(filepath.Join(util.HostProc(), strconv.Itoa(int(pid)), "ns", "pid"), &stat); err != nil {
+		return 0, os.NewSyscallError("stat", err)
+	}
+	return stat.Ino, nil
 }
 
 // evalEXITCallback will evaluate the metadata saved by the Exec callback and the callback accordingly
 // please refer to GetProcessMonitor documentation
 func (pm *ProcessMonitor) evalEXITCallback(c *ProcessCallback, pid uint32) {
-	switch c.Metadata {
-	case NAME:
-		metadata, found := pm.runningPids[pid]
-		if !found {
-			// we can hit here if a process started before the Exec callback has been registered
-			// and the process Exit, so we don't find his metadata
-			return
-		}
-		pname := metadata.(metadataName).Name
-		if c.Regex.MatchString(pname) {
-			pm.enqueueCallback(c, pid, metadata)
-		}
-	case ANY:
+	if !c.hasFilter() {
+		pm.enqueueCallback(c, pid, nil)
+		return
+	}
+
+	metadata, found := pm.runningPids[pid]
+	if !found {
+		// we can hit here if a process started before the Exec callback has been registered
+		// and the process Exit, so we don't find his metadata
+		return
+	}
+	if c.matches(metadata) {
 		pm.enqueueCallback(c, pid, nil)
 	}
 }
@@ -275,6 +351,8 @@ func (pm *ProcessMonitor) Initialize() error {
 
 				switch ev := event.Msg.(type) {
 				case *netlink.ExecProcEvent:
+					// the process image changed, the metadata of the previous one are stale
+					delete(pm.runningPids, ev.ProcessPid)
 					for _, c := range pm.procEventCallbacks[EXEC] {
 						pm.execCount += 1
 						pm.evalEXECCallback(c, ev.ProcessPid)
@@ -325,7 +403,7 @@ func (pm *ProcessMonitor) Initialize() error {
 //
 // By design : 1/ a callback object can be registered only once
 //
-//	2/ Exec callback with a Metadata (!=ANY) must be registered before the sibling Exit metadata,
+//	2/ Exec callback with a Metadata (!=ANY) or PIDNamespaces must be registered before the sibling Exit metadata,
 //	   otherwise the Subscribe() will return an error as no metadata will be saved between Exec and Exit,
 //	   please refer to GetProcessMonitor()
 func (pm *ProcessMonitor) Subscribe(callback *ProcessCallback) (UnSubscribe func(), err error) {
@@ -339,10 +417,10 @@ func (pm *ProcessMonitor) Subscribe(callback *ProcessCallback) (UnSubscribe func
 	}
 
 	// check if the sibling Exec callback exist
-	if callback.Event == EXIT && callback.Metadata != ANY {
+	if callback.Event == EXIT && callback.hasFilter() {
 		foundSibling := false
 		for _, c := range pm.procEventCallbacks[EXEC] {
-			if c.Metadata == callback.Metadata && c.Regex.String() == callback.Regex.String() {
+			if c.sameFilter(callback) {
 				foundSibling = true
 				break
 			}
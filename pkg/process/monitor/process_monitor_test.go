@@ -220,3 +220,130 @@ func TestProcessMonitorInNamespace(t *testing.T) {
 		return captured
 	}, time.Second, 200*time.Millisecond, "did not capture process EXEC from other namespace")
 }
+
+// newTestProcessMonitor returns a ProcessMonitor which is not registered to netlink,
+// callbacks are queued and must be run by runCallbacks
+func newTestProcessMonitor() *ProcessMonitor {
+	return &ProcessMonitor{
+		procEventCallbacks: make(map[ProcessEventType][]*ProcessCallback),
+		runningPids:        make(map[uint32]processMetadata),
+		callbackRunner:     make(chan func(), 100),
+	}
+}
+
+func runCallbacks(pm *ProcessMonitor) {
+	for {
+		select {
+		case call := <-pm.callbackRunner:
+			call()
+		default:
+			return
+		}
+	}
+}
+
+func TestProcessMonitorFilters(t *testing.T) {
+	pid := uint32(os.Getpid())
+	exe, err := os.Executable()
+	require.NoError(t, err)
+	pidNS, err := getPIDNamespace(pid)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		callback ProcessCallback
+		expected bool
+	}{
+		{
+			name:     "exe match",
+			callback: ProcessCallback{Metadata: EXE, Regex: regexp.MustCompile(regexp.QuoteMeta(path.Base(exe)) + "$")},
+			expected: true,
+		},
+		{
+			name:     "exe mismatch",
+			callback: ProcessCallback{Metadata: EXE, Regex: regexp.MustCompile("^/nonexistent/")},
+			expected: false,
+		},
+		{
+			name:     "pid namespace match",
+			callback: ProcessCallback{Metadata: ANY, PIDNamespaces: []uint64{pidNS + 1, pidNS}},
+			expected: true,
+		},
+		{
+			name:     "pid namespace mismatch",
+			callback: ProcessCallback{Metadata: ANY, PIDNamespaces: []uint64{pidNS + 1}},
+			expected: false,
+		},
+		{
+			name:     "exe match and pid namespace mismatch",
+			callback: ProcessCallback{Metadata: EXE, Regex: regexp.MustCompile("."), PIDNamespaces: []uint64{pidNS + 1}},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pm := newTestProcessMonitor()
+
+			execs, exits := 0, 0
+			callbackExec := tt.callback
+			callbackExec.Event = EXEC
+			callbackExec.Callback = func(uint32) { execs++ }
+			callbackExit := tt.callback
+			callbackExit.Event = EXIT
+			callbackExit.Callback = func(uint32) { exits++ }
+
+			_, err := pm.Subscribe(&callbackExit)
+			require.Error(t, err, "exit callback with a filter requires a sibling exec callback")
+			_, err = pm.Subscribe(&callbackExec)
+			require.NoError(t, err)
+			_, err = pm.Subscribe(&callbackExit)
+			require.NoError(t, err)
+
+			pm.evalEXECCallback(&callbackExec, pid)
+			pm.evalEXITCallback(&callbackExit, pid)
+			runCallbacks(pm)
+
+			expectedCount := 0
+			if tt.expected {
+				expectedCount = 1
+			}
+			require.Equal(t, expectedCount, execs)
+			require.Equal(t, expectedCount, exits)
+		})
+	}
+}
+
+func TestProcessMonitorSubscribeBuffered(t *testing.T) {
+	pid := uint32(os.Getpid())
+	pm := newTestProcessMonitor()
+
+	_, err := pm.SubscribeBuffered(ProcessFilter{Metadata: NAME}, []ProcessEventType{EXEC}, 1)
+	require.Error(t, err)
+	_, err = pm.SubscribeBuffered(ProcessFilter{}, []ProcessEventType{EXEC}, 0)
+	require.Error(t, err)
+
+	sub, err := pm.SubscribeBuffered(ProcessFilter{Metadata: EXE, Regex: regexp.MustCompile(".")}, []ProcessEventType{EXIT}, 1)
+	require.NoError(t, err)
+	// a sibling exec callback is registered for the exit events filter
+	require.Len(t, pm.procEventCallbacks[EXEC], 1)
+	require.Len(t, pm.procEventCallbacks[EXIT], 1)
+
+	for i := 0; i < 3; i++ {
+		for _, c := range pm.procEventCallbacks[EXEC] {
+			pm.evalEXECCallback(c, pid)
+		}
+		for _, c := range pm.procEventCallbacks[EXIT] {
+			pm.evalEXITCallback(c, pid)
+		}
+	}
+	runCallbacks(pm)
+
+	require.Len(t, sub.Events(), 1)
+	require.Equal(t, ProcessEvent{Type: EXIT, Pid: pid}, <-sub.Events())
+	require.Equal(t, uint64(2), sub.Dropped())
+
+	sub.Unsubscribe()
+	require.Empty(t, pm.procEventCallbacks[EXEC])
+	require.Empty(t, pm.procEventCallbacks[EXIT])
+}
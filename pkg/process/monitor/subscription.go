@@ -0,0 +1,129 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package monitor
+
+import (
+	"errors"
+	"regexp"
+
+	"go.uber.org/atomic"
+)
+
+// ProcessEvent is an event delivered to a Subscription
+type ProcessEvent struct {
+	Type ProcessEventType
+	Pid  uint32
+}
+
+// ProcessFilter selects the processes a Subscription receives events for
+type ProcessFilter struct {
+	Metadata      ProcessMetadataField
+	Regex         *regexp.Regexp
+	PIDNamespaces []uint64
+}
+
+// Subscription is a buffered subscription to the events of the ProcessMonitor.
+// Events are dropped when the buffer is full so that a slow consumer can't block
+// the other consumers of the ProcessMonitor.
+type Subscription struct {
+	events      chan ProcessEvent
+	dropped     *atomic.Uint64
+	unsubscribe []func()
+}
+
+// SubscribeBuffered registers a subscription to the given event types for the processes matching the filter.
+// Events are delivered on a channel buffered with bufferSize entries.
+//
+// The events channel is never closed, as callbacks may still be running when Unsubscribe returns.
+func (pm *ProcessMonitor) SubscribeBuffered(filter ProcessFilter, eventTypes []ProcessEventType, bufferSize int) (*Subscription, error) {
+	if filter.Metadata != ANY && filter.Regex == nil {
+		return nil, errors.New("a regex is required to filter on process metadata")
+	}
+	if bufferSize <= 0 {
+		return nil, errors.New("buffer size must be positive")
+	}
+
+	s := &Subscription{
+		events:  make(chan ProcessEvent, bufferSize),
+		dropped: atomic.NewUint64(0),
+	}
+
+	hasFilter := filter.Metadata != ANY || len(filter.PIDNamespaces) > 0
+	subscribeExec, subscribeExit := false, false
+	for _, eventType := range eventTypes {
+		switch eventType {
+		case EXEC:
+			subscribeExec = true
+		case EXIT:
+			subscribeExit = true
+		}
+	}
+
+	// Exit callbacks with a filter rely on the metadata saved by a sibling Exec callback
+	if subscribeExec || (subscribeExit && hasFilter) {
+		onExec := func(pid uint32) {}
+		if subscribeExec {
+			onExec = func(pid uint32) { s.push(ProcessEvent{Type: EXEC, Pid: pid}) }
+		}
+		if err := s.subscribe(pm, EXEC, filter, onExec); err != nil {
+			return nil, err
+		}
+	}
+	if subscribeExit {
+		onExit := func(pid uint32) { s.push(ProcessEvent{Type: EXIT, Pid: pid}) }
+		if err := s.subscribe(pm, EXIT, filter, onExit); err != nil {
+			s.Unsubscribe()
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+func (s *Subscription) subscribe(pm *ProcessMonitor, eventType ProcessEventType, filter ProcessFilter, callback func(pid uint32)) error {
+	unsubscribe, err := pm.Subscribe(&ProcessCallback{
+		Event:         eventType,
+		Metadata:      filter.Metadata,
+		Regex:         filter.Regex,
+		PIDNamespaces: filter.PIDNamespaces,
+		Callback:      callback,
+	})
+	if err != nil {
+		return err
+	}
+	s.unsubscribe = append(s.unsubscribe, unsubscribe)
+	return nil
+}
+
+func (s *Subscription) push(event ProcessEvent) {
+	select {
+	case s.events <- event:
+	default:
+		s.dropped.Inc()
+	}
+}
+
+// Events returns the channel the events of the subscription are delivered on
+func (s *Subscription) Events() <-chan ProcessEvent {
+	return s.events
+}
+
+// Dropped returns the number of events dropped because the buffer was full
+func (s *Subscription) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Unsubscribe removes the subscription from the ProcessMonitor
+func (s *Subscription) Unsubscribe() {
+	// Exit callbacks are removed first, as they depend on the Exec ones
+	for i := len(s.unsubscribe) - 1; i >= 0; i-- {
+		s.unsubscribe[i]()
+	}
+	s.unsubscribe = nil
+}
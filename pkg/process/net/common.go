@@ -21,6 +21,7 @@ import (
 
 	model "github.com/DataDog/agent-payload/v5/process"
 
+	"github.com/DataDog/datadog-agent/pkg/network"
 	netEncoding "github.com/DataDog/datadog-agent/pkg/network/encoding"
 	procEncoding "github.com/DataDog/datadog-agent/pkg/process/encoding"
 	reqEncoding "github.com/DataDog/datadog-agent/pkg/process/encoding/request"
@@ -160,6 +161,36 @@ func (r *RemoteSysProbeUtil) GetConnections(clientID string) (*model.Connections
 	return conns, nil
 }
 
+// GetProcessBandwidth returns the limit processes which sent and received the most bytes since the
+// last call with the same client ID, retrieved from the system probe service
+func (r *RemoteSysProbeUtil) GetProcessBandwidth(clientID string, limit int) ([]network.ProcessBandwidth, error) {
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s?client_id=%s&limit=%d", processBandwidthURL, clientID, limit), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("process bandwidth request failed: Probe Path %s, url: %s, status code: %d", r.path, processBandwidthURL, resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var bandwidth []network.ProcessBandwidth
+	if err := json.Unmarshal(body, &bandwidth); err != nil {
+		return nil, err
+	}
+	return bandwidth, nil
+}
+
 // GetStats returns the expvar stats of the system probe
 func (r *RemoteSysProbeUtil) GetStats() (map[string]interface{}, error) {
 	req, err := http.NewRequest("GET", statsURL, nil)
@@ -16,11 +16,12 @@ import (
 )
 
 const (
-	connectionsURL = "http://unix/" + string(sysconfig.NetworkTracerModule) + "/connections"
-	procStatsURL   = "http://unix/" + string(sysconfig.ProcessModule) + "/stats"
-	registerURL    = "http://unix/" + string(sysconfig.NetworkTracerModule) + "/register"
-	statsURL       = "http://unix/debug/stats"
-	netType        = "unix"
+	connectionsURL      = "http://unix/" + string(sysconfig.NetworkTracerModule) + "/connections"
+	procStatsURL        = "http://unix/" + string(sysconfig.ProcessModule) + "/stats"
+	registerURL         = "http://unix/" + string(sysconfig.NetworkTracerModule) + "/register"
+	processBandwidthURL = "http://unix/" + string(sysconfig.NetworkTracerModule) + "/process_bandwidth"
+	statsURL            = "http://unix/debug/stats"
+	netType             = "unix"
 )
 
 // CheckPath is used in conjunction with calling the stats endpoint, since we are calling this
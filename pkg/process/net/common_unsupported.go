@@ -12,6 +12,7 @@ import (
 	model "github.com/DataDog/agent-payload/v5/process"
 
 	"github.com/DataDog/datadog-agent/pkg/ebpf"
+	"github.com/DataDog/datadog-agent/pkg/network"
 )
 
 var _ SysProbeUtil = &RemoteSysProbeUtil{}
@@ -34,6 +35,11 @@ func (r *RemoteSysProbeUtil) GetConnections(clientID string) (*model.Connections
 	return nil, ebpf.ErrNotImplemented
 }
 
+// GetProcessBandwidth is not supported
+func (r *RemoteSysProbeUtil) GetProcessBandwidth(clientID string, limit int) ([]network.ProcessBandwidth, error) {
+	return nil, ebpf.ErrNotImplemented
+}
+
 // GetStats is not supported
 func (r *RemoteSysProbeUtil) GetStats() (map[string]interface{}, error) {
 	return nil, ebpf.ErrNotImplemented
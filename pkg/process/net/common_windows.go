@@ -15,10 +15,11 @@ import (
 )
 
 const (
-	connectionsURL = "http://localhost:3333/" + string(sysconfig.NetworkTracerModule) + "/connections"
-	registerURL    = "http://localhost:3333/" + string(sysconfig.NetworkTracerModule) + "/register"
-	statsURL       = "http://localhost:3333/debug/stats"
-	netType        = "tcp"
+	connectionsURL      = "http://localhost:3333/" + string(sysconfig.NetworkTracerModule) + "/connections"
+	registerURL         = "http://localhost:3333/" + string(sysconfig.NetworkTracerModule) + "/register"
+	processBandwidthURL = "http://localhost:3333/" + string(sysconfig.NetworkTracerModule) + "/process_bandwidth"
+	statsURL            = "http://localhost:3333/debug/stats"
+	netType             = "tcp"
 
 	// procStatsURL is not used in windows, the value is added to avoid compilation error in windows
 	procStatsURL = "http://localhost:3333/" + string(sysconfig.ProcessModule) + "stats"
@@ -0,0 +1,93 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package procutil
+
+import (
+	"errors"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/telemetry"
+)
+
+var (
+	tlmPIDsScanned = telemetry.NewGauge("process", "collection_pids_scanned",
+		nil, "Number of processes found by the last collection")
+	tlmPermissionDenied = telemetry.NewCounter("process", "collection_permission_denied",
+		[]string{"file"}, "Count of process files which couldn't be read by lack of permission")
+	tlmParseFailures = telemetry.NewCounter("process", "collection_parse_failures",
+		[]string{"file"}, "Count of process files which couldn't be parsed")
+	tlmElapsed = telemetry.NewGauge("process", "collection_duration_seconds",
+		nil, "Duration of the last collection of the processes")
+)
+
+// CollectionStats describes a collection of the processes by ProcessesByPID. The process files which couldn't be
+// read or parsed are left empty in the processes collected, these stats tell the partial visibility apart from
+// processes without data.
+type CollectionStats struct {
+	// PIDsScanned is the number of processes found
+	PIDsScanned int
+	// PermissionDenied is the number of files which couldn't be read by lack of permission, by file name, e.g. "exe"
+	PermissionDenied map[string]int
+	// ParseFailures is the number of files which couldn't be parsed, by file name
+	ParseFailures map[string]int
+	// Elapsed is the duration of the collection
+	Elapsed time.Duration
+}
+
+func newCollectionStats() *CollectionStats {
+	return &CollectionStats{
+		PermissionDenied: make(map[string]int),
+		ParseFailures:    make(map[string]int),
+	}
+}
+
+// recordReadError counts the error if it's caused by a lack of permission. The stats can be nil, when the file is
+// read outside of a collection.
+func (s *CollectionStats) recordReadError(file string, err error) {
+	if s == nil || !errors.Is(err, os.ErrPermission) {
+		return
+	}
+	s.PermissionDenied[file]++
+}
+
+// recordParseFailure counts a file which couldn't be parsed. The stats can be nil, when the file is read outside of a
+// collection.
+func (s *CollectionStats) recordParseFailure(file string) {
+	if s == nil {
+		return
+	}
+	s.ParseFailures[file]++
+}
+
+// lastCollectionStats holds the stats of the last collection of a probe
+type lastCollectionStats struct {
+	mu    sync.Mutex
+	stats CollectionStats
+}
+
+// set stores the stats of a collection, which mustn't be modified afterwards, and reports them as telemetry
+func (l *lastCollectionStats) set(stats *CollectionStats) {
+	tlmPIDsScanned.Set(float64(stats.PIDsScanned))
+	tlmElapsed.Set(stats.Elapsed.Seconds())
+	for file, count := range stats.PermissionDenied {
+		tlmPermissionDenied.Add(float64(count), file)
+	}
+	for file, count := range stats.ParseFailures {
+		tlmParseFailures.Add(float64(count), file)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.stats = *stats
+}
+
+func (l *lastCollectionStats) get() CollectionStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.stats
+}
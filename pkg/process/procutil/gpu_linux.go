@@ -0,0 +1,178 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package procutil
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	nvidiaSMITimeout        = 5 * time.Second
+	gpuStatsRefreshInterval = 10 * time.Second
+	bytesPerMiB             = 1024 * 1024
+)
+
+// gpuStatsCollector samples the GPU usage of the processes in the background, as nvidia-smi can take
+// seconds to run. The probe is served the last sample until the next one completes.
+type gpuStatsCollector struct {
+	mu         sync.RWMutex
+	statsByPID map[int32]*GPUStat
+
+	// collect returns a new sample, or nil if it failed
+	collect func() map[int32]*GPUStat
+}
+
+func newGPUStatsCollector(nvidiaSMI string) *gpuStatsCollector {
+	return &gpuStatsCollector{
+		collect: func() map[int32]*GPUStat {
+			return runNvidiaSMIPmon(nvidiaSMI)
+		},
+	}
+}
+
+// run refreshes the sample every interval until exit is closed.
+func (c *gpuStatsCollector) run(interval time.Duration, exit <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		c.refresh()
+		select {
+		case <-ticker.C:
+		case <-exit:
+			return
+		}
+	}
+}
+
+func (c *gpuStatsCollector) refresh() {
+	// a sample is never modified once stored, so it can be shared with the readers
+	statsByPID := c.collect()
+	c.mu.Lock()
+	c.statsByPID = statsByPID
+	c.mu.Unlock()
+}
+
+func (c *gpuStatsCollector) get() map[int32]*GPUStat {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.statsByPID
+}
+
+// startGPUStatsCollector starts sampling the GPU usage of the processes in the background, if GPU
+// stats collection is enabled and nvidia-smi is available on the host.
+func (p *probe) startGPUStatsCollector() {
+	if !p.gpuStats {
+		return
+	}
+
+	nvidiaSMI, err := exec.LookPath("nvidia-smi")
+	if err != nil {
+		log.Infof("GPU stats collection is enabled but nvidia-smi could not be found: %s", err)
+		return
+	}
+
+	p.gpuStatsCollector = newGPUStatsCollector(nvidiaSMI)
+	go p.gpuStatsCollector.run(gpuStatsRefreshInterval, p.exit)
+}
+
+// getGPUStats returns the last sampled GPU usage of every process running on an NVIDIA GPU, indexed
+// by PID. It returns nil when GPU stats collection is disabled or unavailable on the host. The
+// returned map and stats must not be modified.
+func (p *probe) getGPUStats() map[int32]*GPUStat {
+	if p.gpuStatsCollector == nil {
+		return nil
+	}
+	return p.gpuStatsCollector.get()
+}
+
+func runNvidiaSMIPmon(nvidiaSMI string) map[int32]*GPUStat {
+	ctx, cancel := context.WithTimeout(context.Background(), nvidiaSMITimeout)
+	defer cancel()
+
+	// `pmon -s um` samples the utilization and frame buffer memory of every process running on a GPU
+	out, err := exec.CommandContext(ctx, nvidiaSMI, "pmon", "-c", "1", "-s", "um").Output()
+	if err != nil {
+		log.Debugf("unable to collect GPU stats from nvidia-smi: %s", err)
+		return nil
+	}
+
+	return parseNvidiaSMIPmon(out)
+}
+
+// parseNvidiaSMIPmon parses the output of `nvidia-smi pmon -s um`. The column layout depends on the
+// driver version, so columns are located from the header line, e.g.:
+//
+//	# gpu        pid  type    sm   mem   enc   dec    fb   command
+//	# Idx          #   C/G     %     %     %     %    MB   name
+//	    0       1234     C    45    12     -     -  1024   python
+func parseNvidiaSMIPmon(out []byte) map[int32]*GPUStat {
+	pidIdx, smIdx, fbIdx := -1, -1, -1
+	statsByPID := make(map[int32]*GPUStat)
+
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "#") {
+			// only the first header line holds the column names
+			if pidIdx != -1 {
+				continue
+			}
+			for i, column := range strings.Fields(strings.TrimPrefix(line, "#")) {
+				switch column {
+				case "pid":
+					pidIdx = i
+				case "sm":
+					smIdx = i
+				case "fb":
+					fbIdx = i
+				}
+			}
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if pidIdx == -1 || pidIdx >= len(fields) {
+			continue
+		}
+		pid, err := strconv.ParseInt(fields[pidIdx], 10, 32)
+		if err != nil {
+			// idle GPUs are reported with a "-" pid
+			continue
+		}
+
+		stat, ok := statsByPID[int32(pid)]
+		if !ok {
+			stat = &GPUStat{}
+			statsByPID[int32(pid)] = stat
+		}
+		// a process running on several GPUs is reported once per GPU
+		if smIdx != -1 && smIdx < len(fields) {
+			if sm, err := strconv.ParseFloat(fields[smIdx], 64); err == nil {
+				stat.UtilizationPct += sm
+			}
+		}
+		if fbIdx != -1 && fbIdx < len(fields) {
+			if fb, err := strconv.ParseUint(fields[fbIdx], 10, 64); err == nil {
+				stat.MemoryBytes += fb * bytesPerMiB
+			}
+		}
+	}
+
+	return statsByPID
+}
@@ -0,0 +1,72 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package procutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseNvidiaSMIPmon(t *testing.T) {
+	out := []byte(`# gpu        pid  type    sm   mem   enc   dec    fb   command
+# Idx          #   C/G     %     %     %     %    MB   name
+    0       1234     C    45    12     -     -  1024   python
+    0       5678     G     -     -     -     -    64   Xorg
+    1       1234     C    30     8     -     -   512   python
+    2          -     -     -     -     -     -     -   -
+`)
+
+	stats := parseNvidiaSMIPmon(out)
+	assert.Equal(t, map[int32]*GPUStat{
+		1234: {MemoryBytes: 1536 * 1024 * 1024, UtilizationPct: 75},
+		5678: {MemoryBytes: 64 * 1024 * 1024},
+	}, stats)
+}
+
+func TestParseNvidiaSMIPmonColumnOrder(t *testing.T) {
+	// newer drivers report additional columns before the frame buffer usage
+	out := []byte(`# gpu         pid   type     sm    mem    enc    dec    jpg    ofa     fb   command
+# Idx           #    C/G      %      %      %      %      %      %     MB   name
+    0        42     C     99     50      -      -      -      -   2048   train
+`)
+
+	stats := parseNvidiaSMIPmon(out)
+	assert.Equal(t, map[int32]*GPUStat{
+		42: {MemoryBytes: 2048 * 1024 * 1024, UtilizationPct: 99},
+	}, stats)
+}
+
+func TestGetGPUStatsDisabled(t *testing.T) {
+	p := &probe{}
+	assert.Nil(t, p.getGPUStats())
+}
+
+func TestGPUStatsCollector(t *testing.T) {
+	samples := []map[int32]*GPUStat{
+		{1234: {MemoryBytes: 1024 * bytesPerMiB, UtilizationPct: 45}},
+		nil,
+	}
+	c := &gpuStatsCollector{
+		collect: func() map[int32]*GPUStat {
+			sample := samples[0]
+			samples = samples[1:]
+			return sample
+		},
+	}
+	p := &probe{gpuStatsCollector: c}
+	assert.Nil(t, p.getGPUStats())
+
+	c.refresh()
+	assert.Equal(t, map[int32]*GPUStat{1234: {MemoryBytes: 1024 * bytesPerMiB, UtilizationPct: 45}}, p.getGPUStats())
+
+	// a failed sample drops the stats of the previous one
+	c.refresh()
+	assert.Nil(t, p.getGPUStats())
+}
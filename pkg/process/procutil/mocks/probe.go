@@ -19,6 +19,46 @@ func (_m *Probe) Close() {
 	_m.Called()
 }
 
+// InspectProcess provides a mock function with given fields: pid
+func (_m *Probe) InspectProcess(pid int32) (*procutil.ProcessDetails, error) {
+	ret := _m.Called(pid)
+
+	var r0 *procutil.ProcessDetails
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int32) (*procutil.ProcessDetails, error)); ok {
+		return rf(pid)
+	}
+	if rf, ok := ret.Get(0).(func(int32) *procutil.ProcessDetails); ok {
+		r0 = rf(pid)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*procutil.ProcessDetails)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int32) error); ok {
+		r1 = rf(pid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// LastCollectionStats provides a mock function with given fields:
+func (_m *Probe) LastCollectionStats() procutil.CollectionStats {
+	ret := _m.Called()
+
+	var r0 procutil.CollectionStats
+	if rf, ok := ret.Get(0).(func() procutil.CollectionStats); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(procutil.CollectionStats)
+	}
+
+	return r0
+}
+
 // ProcessesByPID provides a mock function with given fields: now, collectStats
 func (_m *Probe) ProcessesByPID(now time.Time, collectStats bool) (map[int32]*procutil.Process, error) {
 	ret := _m.Called(now, collectStats)
@@ -26,3 +26,23 @@ func WithPermission(elevatedPermissions bool) Option {
 func WithBootTimeRefreshInterval(bootTimeRefreshInterval time.Duration) Option {
 	return func(p Probe) {}
 }
+
+// WithGPUStats configures whether the probe collects per-process GPU usage
+func WithGPUStats(enabled bool) Option {
+	return func(p Probe) {}
+}
+
+// WithPressureStats configures whether the probe collects the scheduler wait time and IO pressure of each process
+func WithPressureStats(enabled bool) Option {
+	return func(p Probe) {}
+}
+
+// WithInspectionRateLimit configures the number of processes InspectProcess can inspect per second
+func WithInspectionRateLimit(perSecond float64) Option {
+	return func(p Probe) {}
+}
+
+// WithInspectionEnvAllowlist configures the environment variables returned by InspectProcess
+func WithInspectionEnvAllowlist(names []string) Option {
+	return func(p Probe) {}
+}
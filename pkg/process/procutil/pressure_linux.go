@@ -0,0 +1,170 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package procutil
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// parseSchedstat returns the scheduler statistics of a process, summed across the /proc/(pid)/task/(tid)/schedstat
+// files of its threads, as /proc/(pid)/schedstat only accounts for the main thread.
+// It returns nil when pressure stats collection is disabled or the kernel doesn't expose them.
+func (p *probe) parseSchedstat(pidPath string) *SchedStat {
+	if !p.pressureStats {
+		return nil
+	}
+
+	taskPath := filepath.Join(pidPath, "task")
+	tids, err := os.ReadDir(taskPath)
+	if err != nil {
+		log.Tracef("unable to list the threads of %s: %s", pidPath, err)
+		return nil
+	}
+
+	var total *SchedStat
+	for _, tid := range tids {
+		content, err := os.ReadFile(filepath.Join(taskPath, tid.Name(), "schedstat"))
+		if err != nil {
+			// the thread may have exited since the task directory was listed
+			log.Tracef("unable to read schedstat of thread %s of %s: %s", tid.Name(), pidPath, err)
+			continue
+		}
+		stat := parseSchedstatContent(content)
+		if stat == nil {
+			continue
+		}
+		if total == nil {
+			total = &SchedStat{}
+		}
+		total.RunTimeNs += stat.RunTimeNs
+		total.WaitTimeNs += stat.WaitTimeNs
+		total.Timeslices += stat.Timeslices
+	}
+	return total
+}
+
+// parseSchedstatContent parses the content of a schedstat file, which holds the time spent on a CPU
+// and waiting on a run queue in nanoseconds, followed by the number of timeslices run, e.g.:
+//
+//	2245305316 37152419 4187
+func parseSchedstatContent(content []byte) *SchedStat {
+	fields := strings.Fields(string(content))
+	if len(fields) < 3 {
+		return nil
+	}
+
+	var values [3]uint64
+	for i := range values {
+		v, err := strconv.ParseUint(fields[i], 10, 64)
+		if err != nil {
+			return nil
+		}
+		values[i] = v
+	}
+	return &SchedStat{
+		RunTimeNs:  values[0],
+		WaitTimeNs: values[1],
+		Timeslices: values[2],
+	}
+}
+
+// getIOPressure returns the IO pressure stall information of the cgroup v2 of a process. The PSI files are
+// per cgroup, so they are cached by cgroup path for the duration of a collection.
+// It returns nil when pressure stats collection is disabled, or the process doesn't belong to a cgroup v2.
+func (p *probe) getIOPressure(pidPath string, cache map[string]*PressureStat) *PressureStat {
+	if !p.pressureStats {
+		return nil
+	}
+
+	content, err := os.ReadFile(filepath.Join(pidPath, "cgroup"))
+	if err != nil {
+		return nil
+	}
+	cgroupPath, ok := unifiedCgroupPath(content)
+	if !ok {
+		return nil
+	}
+
+	if stat, ok := cache[cgroupPath]; ok {
+		return stat
+	}
+
+	var stat *PressureStat
+	psi, err := os.ReadFile(filepath.Join(p.cgroupRootLoc, cgroupPath, "io.pressure"))
+	if err != nil {
+		// PSI is only available from kernel 4.20, and may be disabled with psi=0
+		log.Tracef("unable to read IO pressure of cgroup %s: %s", cgroupPath, err)
+	} else {
+		stat = parsePressureContent(psi)
+	}
+	cache[cgroupPath] = stat
+	return stat
+}
+
+// unifiedCgroupPath returns the path of the cgroup v2 of a process from the content of /proc/(pid)/cgroup,
+// which is the only entry with the hierarchy ID 0, e.g. 0::/system.slice/docker.service
+func unifiedCgroupPath(content []byte) (string, bool) {
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		if line := scanner.Text(); strings.HasPrefix(line, "0::") {
+			return strings.TrimPrefix(line, "0::"), true
+		}
+	}
+	return "", false
+}
+
+// parsePressureContent parses the content of a PSI file, e.g.:
+//
+//	some avg10=0.00 avg60=0.00 avg300=0.00 total=167542
+//	full avg10=0.00 avg60=0.00 avg300=0.00 total=160301
+func parsePressureContent(content []byte) *PressureStat {
+	stat := &PressureStat{}
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		var line *PressureLineStat
+		switch fields[0] {
+		case "some":
+			line = &stat.Some
+		case "full":
+			line = &stat.Full
+		default:
+			continue
+		}
+
+		for _, field := range fields[1:] {
+			key, value, ok := strings.Cut(field, "=")
+			if !ok {
+				continue
+			}
+			switch key {
+			case "avg10":
+				line.Avg10, _ = strconv.ParseFloat(value, 64)
+			case "avg60":
+				line.Avg60, _ = strconv.ParseFloat(value, 64)
+			case "avg300":
+				line.Avg300, _ = strconv.ParseFloat(value, 64)
+			case "total":
+				line.TotalUs, _ = strconv.ParseUint(value, 10, 64)
+			}
+		}
+	}
+	return stat
+}
@@ -0,0 +1,93 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package procutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSchedstatContent(t *testing.T) {
+	assert.Equal(t, &SchedStat{
+		RunTimeNs:  2245305316,
+		WaitTimeNs: 37152419,
+		Timeslices: 4187,
+	}, parseSchedstatContent([]byte("2245305316 37152419 4187\n")))
+
+	assert.Nil(t, parseSchedstatContent([]byte("2245305316 37152419\n")))
+	assert.Nil(t, parseSchedstatContent([]byte("2245305316 -1 4187\n")))
+}
+
+func TestParsePressureContent(t *testing.T) {
+	stat := parsePressureContent([]byte(`some avg10=1.50 avg60=0.75 avg300=0.20 total=167542
+full avg10=0.50 avg60=0.25 avg300=0.10 total=160301
+`))
+	assert.Equal(t, &PressureStat{
+		Some: PressureLineStat{Avg10: 1.5, Avg60: 0.75, Avg300: 0.2, TotalUs: 167542},
+		Full: PressureLineStat{Avg10: 0.5, Avg60: 0.25, Avg300: 0.1, TotalUs: 160301},
+	}, stat)
+}
+
+func TestUnifiedCgroupPath(t *testing.T) {
+	path, ok := unifiedCgroupPath([]byte("0::/system.slice/docker.service\n"))
+	assert.True(t, ok)
+	assert.Equal(t, "/system.slice/docker.service", path)
+
+	path, ok = unifiedCgroupPath([]byte("12:pids:/system.slice/docker.service\n1:name=systemd:/system.slice/docker.service\n0::/\n"))
+	assert.True(t, ok)
+	assert.Equal(t, "/", path)
+
+	_, ok = unifiedCgroupPath([]byte("12:pids:/system.slice/docker.service\n"))
+	assert.False(t, ok)
+}
+
+func TestPressureStatsDisabled(t *testing.T) {
+	p := &probe{}
+	assert.Nil(t, p.parseSchedstat("/proc/self"))
+	assert.Nil(t, p.getIOPressure("/proc/self", map[string]*PressureStat{}))
+}
+
+func TestGetIOPressure(t *testing.T) {
+	procDir := t.TempDir()
+	cgroupDir := t.TempDir()
+
+	pidPath := filepath.Join(procDir, "42")
+	require.NoError(t, os.MkdirAll(pidPath, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(pidPath, "cgroup"), []byte("0::/system.slice/app.service\n"), 0644))
+	// the schedstat of the process only accounts for its main thread
+	require.NoError(t, os.WriteFile(filepath.Join(pidPath, "schedstat"), []byte("100 200 3\n"), 0644))
+	for tid, schedstat := range map[string]string{"42": "100 200 3\n", "43": "1000 2000 30\n"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(pidPath, "task", tid), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(pidPath, "task", tid, "schedstat"), []byte(schedstat), 0644))
+	}
+
+	serviceDir := filepath.Join(cgroupDir, "system.slice", "app.service")
+	require.NoError(t, os.MkdirAll(serviceDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(serviceDir, "io.pressure"), []byte("some avg10=2.00 avg60=1.00 avg300=0.50 total=1000\nfull avg10=1.00 avg60=0.50 avg300=0.25 total=500\n"), 0644))
+
+	p := &probe{pressureStats: true, cgroupRootLoc: cgroupDir}
+	assert.Equal(t, &SchedStat{RunTimeNs: 1100, WaitTimeNs: 2200, Timeslices: 33}, p.parseSchedstat(pidPath))
+
+	cache := make(map[string]*PressureStat)
+	expected := &PressureStat{
+		Some: PressureLineStat{Avg10: 2, Avg60: 1, Avg300: 0.5, TotalUs: 1000},
+		Full: PressureLineStat{Avg10: 1, Avg60: 0.5, Avg300: 0.25, TotalUs: 500},
+	}
+	assert.Equal(t, expected, p.getIOPressure(pidPath, cache))
+	assert.Equal(t, map[string]*PressureStat{"/system.slice/app.service": expected}, cache)
+
+	// the PSI of a cgroup is read once per collection
+	require.NoError(t, os.Remove(filepath.Join(serviceDir, "io.pressure")))
+	assert.Equal(t, expected, p.getIOPressure(pidPath, cache))
+	assert.Nil(t, p.getIOPressure(pidPath, map[string]*PressureStat{}))
+}
@@ -15,6 +15,9 @@ type Probe interface {
 	StatsForPIDs(pids []int32, now time.Time) (map[int32]*Stats, error)
 	ProcessesByPID(now time.Time, collectStats bool) (map[int32]*Process, error)
 	StatsWithPermByPID(pids []int32) (map[int32]*StatsWithPerm, error)
+	InspectProcess(pid int32) (*ProcessDetails, error)
+	// LastCollectionStats returns the stats of the last collection of the processes by ProcessesByPID
+	LastCollectionStats() CollectionStats
 }
 
 // Option is config options callback for system-probe
@@ -0,0 +1,40 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package procutil
+
+import (
+	"errors"
+)
+
+// ErrInspectionRateLimited is returned by InspectProcess when too many processes were inspected recently
+var ErrInspectionRateLimited = errors.New("process inspection rate limit exceeded")
+
+// ProcessDetails holds the extended data of a single process. It's too expensive to be collected for
+// all the processes at every check run, so it's only collected on demand by InspectProcess.
+type ProcessDetails struct {
+	Pid       int32
+	Cwd       string
+	Limits    []ResourceLimit
+	Cgroups   []string
+	OpenPorts []OpenPort
+	// Env only holds the environment variables of the allowlist, as the others may contain secrets
+	Env map[string]string
+}
+
+// ResourceLimit holds the soft and hard values of a resource limit, "unlimited" if the resource isn't limited
+type ResourceLimit struct {
+	Name string
+	Soft string
+	Hard string
+	Unit string
+}
+
+// OpenPort is a TCP port listened on, or an UDP port bound, by a process
+type OpenPort struct {
+	Protocol string // tcp, tcp6, udp or udp6
+	Address  string
+	Port     uint16
+}
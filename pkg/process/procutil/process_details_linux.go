@@ -0,0 +1,277 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package procutil
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	// tcpListen is the state of the listening TCP sockets in /proc/(pid)/net/tcp(6)
+	tcpListen = "0A"
+	// udpUnconnected is the state of the bound UDP sockets in /proc/(pid)/net/udp(6)
+	udpUnconnected = "07"
+)
+
+// WithInspectionRateLimit configures the number of processes InspectProcess can inspect per second
+func WithInspectionRateLimit(perSecond float64) Option {
+	return func(p Probe) {
+		if linuxProbe, ok := p.(*probe); ok && perSecond > 0 {
+			linuxProbe.inspectionLimiter = newInspectionLimiter(perSecond)
+		}
+	}
+}
+
+// WithInspectionEnvAllowlist configures the environment variables returned by InspectProcess
+func WithInspectionEnvAllowlist(names []string) Option {
+	return func(p Probe) {
+		if linuxProbe, ok := p.(*probe); ok {
+			linuxProbe.inspectionEnvAllowlist = make(map[string]struct{}, len(names))
+			for _, name := range names {
+				linuxProbe.inspectionEnvAllowlist[name] = struct{}{}
+			}
+		}
+	}
+}
+
+// newInspectionLimiter returns a limiter allowing perSecond inspections per second, with bursts
+// of at most one second of inspections
+func newInspectionLimiter(perSecond float64) *rate.Limiter {
+	return rate.NewLimiter(rate.Limit(perSecond), int(math.Max(1, math.Ceil(perSecond))))
+}
+
+// InspectProcess collects the extended data of a single process. The data that can't be read, e.g.
+// because of missing permissions, is left empty. It returns ErrInspectionRateLimited if too many
+// processes were inspected recently.
+func (p *probe) InspectProcess(pid int32) (*ProcessDetails, error) {
+	if !p.inspectionLimiter.Allow() {
+		return nil, ErrInspectionRateLimited
+	}
+
+	pidPath := filepath.Join(p.procRootLoc, strconv.Itoa(int(pid)))
+	if _, err := os.Stat(pidPath); err != nil {
+		return nil, fmt.Errorf("could not inspect process %d: %w", pid, err)
+	}
+
+	return &ProcessDetails{
+		Pid:       pid,
+		Cwd:       p.getLinkWithAuthCheck(pidPath, "cwd", nil),
+		Limits:    p.parseLimits(pidPath),
+		Cgroups:   p.parseCgroups(pidPath),
+		OpenPorts: p.getOpenPorts(pidPath),
+		Env:       p.getAllowedEnv(pidPath),
+	}, nil
+}
+
+// parseLimits parses /proc/(pid)/limits, whose columns are aligned on the header:
+// Limit                     Soft Limit           Hard Limit           Units
+// Max open files            1024                 1048576              files
+func (p *probe) parseLimits(pidPath string) []ResourceLimit {
+	content, err := p.readFileWithAuthCheck(pidPath, "limits")
+	if err != nil {
+		return nil
+	}
+	return parseLimitsContent(content)
+}
+
+func parseLimitsContent(content []byte) []ResourceLimit {
+	lines := strings.Split(string(content), "\n")
+	header := lines[0]
+	softIdx := strings.Index(header, "Soft Limit")
+	hardIdx := strings.Index(header, "Hard Limit")
+	unitIdx := strings.Index(header, "Units")
+	if softIdx <= 0 || hardIdx <= softIdx || unitIdx <= hardIdx {
+		return nil
+	}
+
+	var limits []ResourceLimit
+	for _, line := range lines[1:] {
+		if len(line) <= hardIdx {
+			continue
+		}
+		limit := ResourceLimit{
+			Name: strings.TrimSpace(line[:softIdx]),
+			Soft: strings.TrimSpace(line[softIdx:hardIdx]),
+		}
+		if len(line) > unitIdx {
+			limit.Hard = strings.TrimSpace(line[hardIdx:unitIdx])
+			limit.Unit = strings.TrimSpace(line[unitIdx:])
+		} else {
+			limit.Hard = strings.TrimSpace(line[hardIdx:])
+		}
+		limits = append(limits, limit)
+	}
+	return limits
+}
+
+// parseCgroups returns the lines of /proc/(pid)/cgroup, e.g. 0::/system.slice/docker.service
+func (p *probe) parseCgroups(pidPath string) []string {
+	content, err := p.readFileWithAuthCheck(pidPath, "cgroup")
+	if err != nil {
+		return nil
+	}
+	cgroups := strings.TrimSpace(string(content))
+	if cgroups == "" {
+		return nil
+	}
+	return strings.Split(cgroups, "\n")
+}
+
+// getAllowedEnv returns the environment variables of the process which are in the allowlist
+func (p *probe) getAllowedEnv(pidPath string) map[string]string {
+	if len(p.inspectionEnvAllowlist) == 0 {
+		return nil
+	}
+
+	content, err := p.readFileWithAuthCheck(pidPath, "environ")
+	if err != nil {
+		return nil
+	}
+
+	env := make(map[string]string)
+	for _, variable := range trimAndSplitBytes(content) {
+		name, value, ok := strings.Cut(variable, "=")
+		if !ok {
+			continue
+		}
+		if _, allowed := p.inspectionEnvAllowlist[name]; allowed {
+			env[name] = value
+		}
+	}
+	return env
+}
+
+// getOpenPorts returns the ports listened on by the TCP sockets of the process, and the ports bound by its UDP sockets.
+// The sockets of the network namespace of the process are listed in /proc/(pid)/net, and matched by inode
+// against the sockets opened by the process.
+func (p *probe) getOpenPorts(pidPath string) []OpenPort {
+	inodes := p.getSocketInodes(pidPath)
+	if len(inodes) == 0 {
+		return nil
+	}
+
+	var ports []OpenPort
+	for _, protocol := range []string{"tcp", "tcp6", "udp", "udp6"} {
+		content, err := p.readFileWithAuthCheck(pidPath, filepath.Join("net", protocol))
+		if err != nil {
+			continue
+		}
+		state := tcpListen
+		if strings.HasPrefix(protocol, "udp") {
+			state = udpUnconnected
+		}
+		ports = append(ports, parseNetContent(content, protocol, state, inodes)...)
+	}
+
+	sort.Slice(ports, func(i, j int) bool {
+		if ports[i].Protocol != ports[j].Protocol {
+			return ports[i].Protocol < ports[j].Protocol
+		}
+		if ports[i].Port != ports[j].Port {
+			return ports[i].Port < ports[j].Port
+		}
+		return ports[i].Address < ports[j].Address
+	})
+	return ports
+}
+
+// getSocketInodes returns the inodes of the sockets opened by the process, whose file descriptors link to socket:[inode]
+func (p *probe) getSocketInodes(pidPath string) map[string]struct{} {
+	fdPath := filepath.Join(pidPath, "fd")
+	if err := p.ensurePathReadable(fdPath); err != nil {
+		return nil
+	}
+
+	fds, err := os.ReadDir(fdPath)
+	if err != nil {
+		return nil
+	}
+
+	inodes := make(map[string]struct{})
+	for _, fd := range fds {
+		link, err := os.Readlink(filepath.Join(fdPath, fd.Name()))
+		if err != nil {
+			continue
+		}
+		if inode := strings.TrimPrefix(link, "socket:["); inode != link {
+			inodes[strings.TrimSuffix(inode, "]")] = struct{}{}
+		}
+	}
+	return inodes
+}
+
+// parseNetContent parses the sockets listed in /proc/(pid)/net/{tcp,tcp6,udp,udp6}, returning the ports of the sockets
+// in the given state and opened by the process:
+// sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+// 0: 0100007F:1F90 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 27132 ...
+func parseNetContent(content []byte, protocol string, state string, inodes map[string]struct{}) []OpenPort {
+	var ports []OpenPort
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	// skip the header
+	scanner.Scan()
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 || fields[3] != state {
+			continue
+		}
+		if _, ok := inodes[fields[9]]; !ok {
+			continue
+		}
+		address, port, err := parseHexAddress(fields[1])
+		if err != nil {
+			continue
+		}
+		ports = append(ports, OpenPort{Protocol: protocol, Address: address, Port: port})
+	}
+	return ports
+}
+
+// parseHexAddress parses an address of /proc/net, e.g. 0100007F:1F90 for 127.0.0.1:8080. The address is
+// made of 32 bits words in host byte order, the port is in network byte order.
+func parseHexAddress(hexAddress string) (string, uint16, error) {
+	hexIP, hexPort, ok := strings.Cut(hexAddress, ":")
+	if !ok {
+		return "", 0, fmt.Errorf("invalid address %q", hexAddress)
+	}
+
+	ip, err := hex.DecodeString(hexIP)
+	if err != nil || (len(ip) != net.IPv4len && len(ip) != net.IPv6len) {
+		return "", 0, fmt.Errorf("invalid IP %q", hexIP)
+	}
+	for i := 0; i < len(ip); i += 4 {
+		ip[i], ip[i+1], ip[i+2], ip[i+3] = ip[i+3], ip[i+2], ip[i+1], ip[i]
+	}
+
+	port, err := strconv.ParseUint(hexPort, 16, 16)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid port %q", hexPort)
+	}
+	return net.IP(ip).String(), uint16(port), nil
+}
+
+// readFileWithAuthCheck reads a file of /proc/(pid) with permission check
+func (p *probe) readFileWithAuthCheck(pidPath string, file string) ([]byte, error) {
+	path := filepath.Join(pidPath, file)
+	if err := p.ensurePathReadable(path); err != nil {
+		return nil, err
+	}
+	return os.ReadFile(path)
+}
@@ -0,0 +1,132 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package procutil
+
+import (
+	"net"
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLimitsContent(t *testing.T) {
+	content := []byte(`Limit                     Soft Limit           Hard Limit           Units
+Max cpu time              unlimited            unlimited            seconds
+Max open files            1024                 1048576              files
+Max realtime timeout      unlimited            unlimited            us
+`)
+
+	assert.Equal(t, []ResourceLimit{
+		{Name: "Max cpu time", Soft: "unlimited", Hard: "unlimited", Unit: "seconds"},
+		{Name: "Max open files", Soft: "1024", Hard: "1048576", Unit: "files"},
+		{Name: "Max realtime timeout", Soft: "unlimited", Hard: "unlimited", Unit: "us"},
+	}, parseLimitsContent(content))
+
+	assert.Nil(t, parseLimitsContent([]byte("invalid")))
+}
+
+func TestParseNetContent(t *testing.T) {
+	content := []byte(`  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 0100007F:1F90 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 1001 1 0000000000000000 100 0 0 10 0
+   1: 00000000:0016 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 1002 1 0000000000000000 100 0 0 10 0
+   2: 0100007F:1F90 0100007F:C350 01 00000000:00000000 00:00000000 00000000     0        0 1003 1 0000000000000000 20 4 30 10 -1
+   3: 00000000:0050 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 2001 1 0000000000000000 100 0 0 10 0
+`)
+	inodes := map[string]struct{}{"1001": {}, "1002": {}, "1003": {}}
+
+	// established connections and the sockets of other processes are ignored
+	assert.Equal(t, []OpenPort{
+		{Protocol: "tcp", Address: "127.0.0.1", Port: 8080},
+		{Protocol: "tcp", Address: "0.0.0.0", Port: 22},
+	}, parseNetContent(content, "tcp", tcpListen, inodes))
+}
+
+func TestParseHexAddress(t *testing.T) {
+	for _, tc := range []struct {
+		hexAddress string
+		address    string
+		port       uint16
+	}{
+		{hexAddress: "0100007F:1F90", address: "127.0.0.1", port: 8080},
+		{hexAddress: "00000000:0035", address: "0.0.0.0", port: 53},
+		{hexAddress: "00000000000000000000000001000000:1F90", address: "::1", port: 8080},
+		{hexAddress: "B80D0120000000000000000001000000:01BB", address: "2001:db8::1", port: 443},
+	} {
+		t.Run(tc.hexAddress, func(t *testing.T) {
+			address, port, err := parseHexAddress(tc.hexAddress)
+			require.NoError(t, err)
+			assert.Equal(t, tc.address, address)
+			assert.Equal(t, tc.port, port)
+		})
+	}
+
+	for _, hexAddress := range []string{"0100007F", "0100:1F90", "0100007F:XYZ", "ZZ00007F:1F90"} {
+		_, _, err := parseHexAddress(hexAddress)
+		assert.Error(t, err, hexAddress)
+	}
+}
+
+func TestInspectProcessLocalFS(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	probe := getProbeWithPermission(
+		WithInspectionEnvAllowlist([]string{"INSPECTED_VAR"}),
+		WithInspectionRateLimit(100),
+	)
+	defer probe.Close()
+
+	// the environment of the process is the one it was started with
+	cmd := exec.Command("sleep", "30")
+	cmd.Env = []string{"INSPECTED_VAR=value", "SECRET_VAR=secret"}
+	cmd.Dir = t.TempDir()
+	require.NoError(t, cmd.Start())
+	defer cmd.Process.Kill()
+
+	details, err := probe.InspectProcess(int32(cmd.Process.Pid))
+	require.NoError(t, err)
+	assert.Equal(t, int32(cmd.Process.Pid), details.Pid)
+	assert.Equal(t, cmd.Dir, details.Cwd)
+	assert.Equal(t, map[string]string{"INSPECTED_VAR": "value"}, details.Env)
+	assert.NotEmpty(t, details.Limits)
+	assert.NotEmpty(t, details.Cgroups)
+	assert.Empty(t, details.OpenPorts)
+
+	details, err = probe.InspectProcess(int32(os.Getpid()))
+	require.NoError(t, err)
+	assert.Contains(t, details.OpenPorts, OpenPort{
+		Protocol: "tcp",
+		Address:  "127.0.0.1",
+		Port:     uint16(listener.Addr().(*net.TCPAddr).Port),
+	})
+}
+
+func TestInspectProcessRateLimit(t *testing.T) {
+	probe := getProbeWithPermission(WithInspectionRateLimit(0.001))
+	defer probe.Close()
+
+	_, err := probe.InspectProcess(int32(os.Getpid()))
+	require.NoError(t, err)
+
+	_, err = probe.InspectProcess(int32(os.Getpid()))
+	assert.ErrorIs(t, err, ErrInspectionRateLimited)
+}
+
+func TestInspectProcessNotFound(t *testing.T) {
+	t.Setenv("HOST_PROC", "resources/test_procfs/proc/")
+	probe := getProbeWithPermission()
+	defer probe.Close()
+
+	_, err := probe.InspectProcess(2)
+	assert.Error(t, err)
+}
@@ -26,6 +26,7 @@ func NewProcessProbe(options ...Option) Probe {
 
 // probe is an implementation of the process probe for platforms other than Windows or Linux
 type probe struct {
+	lastCollectionStats lastCollectionStats
 }
 
 func (p *probe) Close() {}
@@ -39,13 +40,29 @@ func (p *probe) StatsForPIDs(pids []int32, now time.Time) (map[int32]*Stats, err
 }
 
 func (p *probe) ProcessesByPID(now time.Time, collectStats bool) (map[int32]*Process, error) {
+	start := time.Now()
+	collection := newCollectionStats()
+	defer func() {
+		collection.Elapsed = time.Since(start)
+		p.lastCollectionStats.set(collection)
+	}()
+
 	procs, err := process.AllProcesses()
 	if err != nil {
 		return nil, err
 	}
+	collection.PIDsScanned = len(procs)
 	return ConvertAllFilledProcesses(procs), nil
 }
 
+func (p *probe) LastCollectionStats() CollectionStats {
+	return p.lastCollectionStats.get()
+}
+
 func (p *probe) StatsWithPermByPID(pids []int32) (map[int32]*StatsWithPerm, error) {
 	return nil, fmt.Errorf("StatsWithPermByPID is not implemented in this environment")
 }
+
+func (p *probe) InspectProcess(pid int32) (*ProcessDetails, error) {
+	return nil, fmt.Errorf("InspectProcess is not implemented in this environment")
+}
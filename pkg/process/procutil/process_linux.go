@@ -22,7 +22,9 @@ import (
 	"unicode"
 
 	"go.uber.org/atomic"
+	"golang.org/x/time/rate"
 
+	ddconfig "github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/process/util"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
@@ -90,20 +92,51 @@ func WithBootTimeRefreshInterval(bootTimeRefreshInterval time.Duration) Option {
 	}
 }
 
+// WithGPUStats configures whether the probe collects per-process GPU usage from the NVIDIA driver.
+// This requires the nvidia-smi tool to be available on the host.
+func WithGPUStats(enabled bool) Option {
+	return func(p Probe) {
+		if linuxProbe, ok := p.(*probe); ok {
+			linuxProbe.gpuStats = enabled
+		}
+	}
+}
+
+// WithPressureStats configures whether the probe collects the run queue wait time of each process,
+// and the IO pressure stall information of its cgroup. The latter requires cgroup v2 and a kernel >= 4.20.
+func WithPressureStats(enabled bool) Option {
+	return func(p Probe) {
+		if linuxProbe, ok := p.(*probe); ok {
+			linuxProbe.pressureStats = enabled
+		}
+	}
+}
+
 // probe is a service that fetches process related info on current host
 type probe struct {
-	bootTime     *atomic.Uint64
-	procRootLoc  string // ProcFS
-	procRootFile *os.File
-	uid          uint32 // UID
-	euid         uint32 // Effective UID
-	clockTicks   float64
-	exit         chan struct{}
+	bootTime      *atomic.Uint64
+	procRootLoc   string // ProcFS
+	procRootFile  *os.File
+	cgroupRootLoc string // cgroup v2 filesystem
+	uid           uint32 // UID
+	euid          uint32 // Effective UID
+	clockTicks    float64
+	exit          chan struct{}
 
 	// configurations
 	elevatedPermissions     bool
 	returnZeroPermStats     bool
 	bootTimeRefreshInterval time.Duration
+	gpuStats                bool
+	pressureStats           bool
+
+	gpuStatsCollector *gpuStatsCollector
+
+	// on demand inspection of a single process, see InspectProcess
+	inspectionLimiter      *rate.Limiter
+	inspectionEnvAllowlist map[string]struct{}
+
+	lastCollectionStats lastCollectionStats
 }
 
 // NewProcessProbe initializes a new Probe object
@@ -116,13 +149,16 @@ func NewProcessProbe(options ...Option) Probe {
 
 	p := &probe{
 		procRootLoc:             hostProc,
+		cgroupRootLoc:           util.HostSys("fs", "cgroup"),
 		uid:                     uint32(os.Getuid()),
 		euid:                    uint32(os.Geteuid()),
 		clockTicks:              getClockTicks(),
 		exit:                    make(chan struct{}),
 		bootTime:                atomic.NewUint64(0),
 		bootTimeRefreshInterval: time.Minute,
+		inspectionLimiter:       newInspectionLimiter(ddconfig.DefaultProcessDetailsMaxPerSecond),
 	}
+
 	p.bootTime.Store(bootTime)
 
 	for _, o := range options {
@@ -130,6 +166,7 @@ func NewProcessProbe(options ...Option) Probe {
 	}
 
 	go p.syncBootTime()
+	p.startGPUStatsCollector()
 
 	return p
 }
@@ -165,6 +202,8 @@ func (p *probe) syncBootTime() {
 // StatsForPIDs returns a map of stats info indexed by PID using the given PIDs
 func (p *probe) StatsForPIDs(pids []int32, now time.Time) (map[int32]*Stats, error) {
 	statsByPID := make(map[int32]*Stats, len(pids))
+	gpuStatsByPID := p.getGPUStats()
+	ioPressureByCgroup := make(map[string]*PressureStat)
 	for _, pid := range pids {
 		pathForPID := filepath.Join(p.procRootLoc, strconv.Itoa(int(pid)))
 		if !util.PathExists(pathForPID) {
@@ -172,9 +211,9 @@ func (p *probe) StatsForPIDs(pids []int32, now time.Time) (map[int32]*Stats, err
 			continue
 		}
 
-		statusInfo := p.parseStatus(pathForPID)
-		statInfo := p.parseStat(pathForPID, pid, now)
-		memInfoEx := p.parseStatm(pathForPID)
+		statusInfo := p.parseStatus(pathForPID, nil)
+		statInfo := p.parseStat(pathForPID, pid, now, nil)
+		memInfoEx := p.parseStatm(pathForPID, nil)
 
 		stats := &Stats{
 			CreateTime:  statInfo.createTime,    // /proc/[pid]/stat
@@ -187,8 +226,8 @@ func (p *probe) StatsForPIDs(pids []int32, now time.Time) (map[int32]*Stats, err
 			NumThreads:  statusInfo.numThreads,  // /proc/[pid]/status
 		}
 		if p.elevatedPermissions {
-			stats.OpenFdCount = p.getFDCount(pathForPID) // /proc/[pid]/fd, requires permission checks
-			stats.IOStat = p.parseIO(pathForPID)         // /proc/[pid]/io, requires permission checks
+			stats.OpenFdCount = p.getFDCount(pathForPID, nil) // /proc/[pid]/fd, requires permission checks
+			stats.IOStat = p.parseIO(pathForPID, nil)         // /proc/[pid]/io, requires permission checks
 		} else {
 			stats.IOStat = &IOCountersStat{
 				ReadCount:  -1,
@@ -197,6 +236,9 @@ func (p *probe) StatsForPIDs(pids []int32, now time.Time) (map[int32]*Stats, err
 				WriteBytes: -1,
 			} // use -1 values to represent "no permission"
 		}
+		stats.GPUStat = gpuStatsByPID[pid]
+		stats.SchedStat = p.parseSchedstat(pathForPID)                     // /proc/[pid]/schedstat
+		stats.IOPressure = p.getIOPressure(pathForPID, ioPressureByCgroup) // /sys/fs/cgroup/[cgroup]/io.pressure
 		statsByPID[pid] = stats
 	}
 	return statsByPID, nil
@@ -204,10 +246,24 @@ func (p *probe) StatsForPIDs(pids []int32, now time.Time) (map[int32]*Stats, err
 
 // ProcessesByPID returns a map of process info indexed by PID
 func (p *probe) ProcessesByPID(now time.Time, collectStats bool) (map[int32]*Process, error) {
+	start := time.Now()
+	collection := newCollectionStats()
+	defer func() {
+		collection.Elapsed = time.Since(start)
+		p.lastCollectionStats.set(collection)
+	}()
+
 	pids, err := p.getActivePIDs()
 	if err != nil {
 		return nil, err
 	}
+	collection.PIDsScanned = len(pids)
+
+	var gpuStatsByPID map[int32]*GPUStat
+	if collectStats {
+		gpuStatsByPID = p.getGPUStats()
+	}
+	ioPressureByCgroup := make(map[string]*PressureStat)
 
 	procsByPID := make(map[int32]*Process, len(pids))
 	for _, pid := range pids {
@@ -217,9 +273,9 @@ func (p *probe) ProcessesByPID(now time.Time, collectStats bool) (map[int32]*Pro
 			continue
 		}
 
-		cmdline := p.getCmdline(pathForPID)
-		statusInfo := p.parseStatus(pathForPID)
-		statInfo := p.parseStat(pathForPID, pid, now)
+		cmdline := p.getCmdline(pathForPID, collection)
+		statusInfo := p.parseStatus(pathForPID, collection)
+		statInfo := p.parseStat(pathForPID, pid, now, collection)
 
 		if len(cmdline) == 0 {
 			if isKernelThread(statInfo.flags) {
@@ -237,21 +293,21 @@ func (p *probe) ProcessesByPID(now time.Time, collectStats bool) (map[int32]*Pro
 		// createTime to make a bytekey
 		var memInfoEx *MemoryInfoExStat
 		if collectStats {
-			memInfoEx = p.parseStatm(pathForPID)
+			memInfoEx = p.parseStatm(pathForPID, collection)
 		} else {
 			memInfoEx = &MemoryInfoExStat{}
 		}
 
 		proc := &Process{
-			Pid:     pid,                                       // /proc/[pid]
-			Ppid:    statInfo.ppid,                             // /proc/[pid]/stat
-			Cmdline: cmdline,                                   // /proc/[pid]/cmdline
-			Name:    statusInfo.name,                           // /proc/[pid]/status
-			Uids:    statusInfo.uids,                           // /proc/[pid]/status
-			Gids:    statusInfo.gids,                           // /proc/[pid]/status
-			Cwd:     p.getLinkWithAuthCheck(pathForPID, "cwd"), // /proc/[pid]/cwd, requires permission checks
-			Exe:     p.getLinkWithAuthCheck(pathForPID, "exe"), // /proc/[pid]/exe, requires permission checks
-			NsPid:   statusInfo.nspid,                          // /proc/[pid]/status
+			Pid:     pid,                                                   // /proc/[pid]
+			Ppid:    statInfo.ppid,                                         // /proc/[pid]/stat
+			Cmdline: cmdline,                                               // /proc/[pid]/cmdline
+			Name:    statusInfo.name,                                       // /proc/[pid]/status
+			Uids:    statusInfo.uids,                                       // /proc/[pid]/status
+			Gids:    statusInfo.gids,                                       // /proc/[pid]/status
+			Cwd:     p.getLinkWithAuthCheck(pathForPID, "cwd", collection), // /proc/[pid]/cwd, requires permission checks
+			Exe:     p.getLinkWithAuthCheck(pathForPID, "exe", collection), // /proc/[pid]/exe, requires permission checks
+			NsPid:   statusInfo.nspid,                                      // /proc/[pid]/status
 			Stats: &Stats{
 				CreateTime:  statInfo.createTime,    // /proc/[pid]/stat
 				Status:      statusInfo.status,      // /proc/[pid]/status
@@ -264,8 +320,8 @@ func (p *probe) ProcessesByPID(now time.Time, collectStats bool) (map[int32]*Pro
 			},
 		}
 		if p.elevatedPermissions {
-			proc.Stats.OpenFdCount = p.getFDCount(pathForPID) // /proc/[pid]/fd, requires permission checks
-			proc.Stats.IOStat = p.parseIO(pathForPID)         // /proc/[pid]/io, requires permission checks
+			proc.Stats.OpenFdCount = p.getFDCount(pathForPID, collection) // /proc/[pid]/fd, requires permission checks
+			proc.Stats.IOStat = p.parseIO(pathForPID, collection)         // /proc/[pid]/io, requires permission checks
 		} else {
 			proc.Stats.IOStat = &IOCountersStat{
 				ReadCount:  -1,
@@ -274,12 +330,22 @@ func (p *probe) ProcessesByPID(now time.Time, collectStats bool) (map[int32]*Pro
 				WriteBytes: -1,
 			} // use -1 values to represent "no permission"
 		}
+		proc.Stats.GPUStat = gpuStatsByPID[pid]
+		if collectStats {
+			proc.Stats.SchedStat = p.parseSchedstat(pathForPID)                     // /proc/[pid]/schedstat
+			proc.Stats.IOPressure = p.getIOPressure(pathForPID, ioPressureByCgroup) // /sys/fs/cgroup/[cgroup]/io.pressure
+		}
 		procsByPID[pid] = proc
 	}
 
 	return procsByPID, nil
 }
 
+// LastCollectionStats returns the stats of the last collection of the processes by ProcessesByPID
+func (p *probe) LastCollectionStats() CollectionStats {
+	return p.lastCollectionStats.get()
+}
+
 // StatsWithPermByPID returns the stats that require elevated permission to collect for each process
 func (p *probe) StatsWithPermByPID(pids []int32) (map[int32]*StatsWithPerm, error) {
 	statsByPID := make(map[int32]*StatsWithPerm, len(pids))
@@ -290,8 +356,8 @@ func (p *probe) StatsWithPermByPID(pids []int32) (map[int32]*StatsWithPerm, erro
 			continue
 		}
 
-		fds := p.getFDCount(pathForPID)
-		io := p.parseIO(pathForPID)
+		fds := p.getFDCount(pathForPID, nil)
+		io := p.parseIO(pathForPID, nil)
 
 		// don't return entries with all zero values if returnZeroPermStats is disabled
 		if !p.returnZeroPermStats && fds == 0 && io.IsZeroValue() {
@@ -350,9 +416,10 @@ func (p *probe) getActivePIDs() ([]int32, error) {
 }
 
 // getCmdline retrieves the command line text from "cmdline" file for a process in procfs
-func (p *probe) getCmdline(pidPath string) []string {
+func (p *probe) getCmdline(pidPath string, collection *CollectionStats) []string {
 	cmdline, err := os.ReadFile(filepath.Join(pidPath, "cmdline"))
 	if err != nil {
+		collection.recordReadError("cmdline", err)
 		log.Debugf("Unable to read process command line from %s: %s", pidPath, err)
 		return nil
 	}
@@ -365,7 +432,7 @@ func (p *probe) getCmdline(pidPath string) []string {
 }
 
 // parseIO retrieves io info from "io" file for a process in procfs
-func (p *probe) parseIO(pidPath string) *IOCountersStat {
+func (p *probe) parseIO(pidPath string, collection *CollectionStats) *IOCountersStat {
 	path := filepath.Join(pidPath, "io")
 	var err error
 
@@ -377,11 +444,13 @@ func (p *probe) parseIO(pidPath string) *IOCountersStat {
 	}
 
 	if err = p.ensurePathReadable(path); err != nil {
+		collection.recordReadError("io", err)
 		return io
 	}
 
 	f, err := os.Open(path)
 	if err != nil {
+		collection.recordReadError("io", err)
 		return io
 	}
 	defer f.Close()
@@ -434,7 +503,7 @@ func (p *probe) parseIOKV(key, value string, io *IOCountersStat) {
 }
 
 // parseStatus retrieves status info from "status" file for a process in procfs
-func (p *probe) parseStatus(pidPath string) *statusInfo {
+func (p *probe) parseStatus(pidPath string, collection *CollectionStats) *statusInfo {
 	path := filepath.Join(pidPath, "status")
 	var err error
 
@@ -448,6 +517,7 @@ func (p *probe) parseStatus(pidPath string) *statusInfo {
 	content, err := os.ReadFile(path)
 
 	if err != nil {
+		collection.recordReadError("status", err)
 		return sInfo
 	}
 
@@ -546,7 +616,7 @@ func (p *probe) parseStatusKV(key, value string, sInfo *statusInfo) {
 }
 
 // parseStat retrieves stat info from "stat" file for a process in procfs
-func (p *probe) parseStat(pidPath string, pid int32, now time.Time) *statInfo {
+func (p *probe) parseStat(pidPath string, pid int32, now time.Time, collection *CollectionStats) *statInfo {
 	path := filepath.Join(pidPath, "stat")
 	var err error
 
@@ -556,18 +626,20 @@ func (p *probe) parseStat(pidPath string, pid int32, now time.Time) *statInfo {
 
 	contents, err := os.ReadFile(path)
 	if err != nil {
+		collection.recordReadError("stat", err)
 		return sInfo
 	}
 
-	sInfo = p.parseStatContent(contents, sInfo, pid, now)
+	sInfo = p.parseStatContent(contents, sInfo, pid, now, collection)
 	return sInfo
 }
 
 // parseStatContent takes the content of "stat" file and parses the values we care about
-func (p *probe) parseStatContent(statContent []byte, sInfo *statInfo, pid int32, now time.Time) *statInfo {
+func (p *probe) parseStatContent(statContent []byte, sInfo *statInfo, pid int32, now time.Time, collection *CollectionStats) *statInfo {
 	// We want to skip past the executable name, which is wrapped in one or more parenthesis
 	startIndex := bytes.LastIndexByte(statContent, byte(')'))
 	if startIndex == -1 || startIndex+1 >= len(statContent) {
+		collection.recordParseFailure("stat")
 		return sInfo
 	}
 
@@ -603,6 +675,7 @@ func (p *probe) parseStatContent(statContent []byte, sInfo *statInfo, pid int32,
 	}
 
 	if spaces < 20 { // We access index 20 and below, so this is just a safety check.
+		collection.recordParseFailure("stat")
 		return sInfo
 	}
 
@@ -645,7 +718,7 @@ func (p *probe) parseStatContent(statContent []byte, sInfo *statInfo, pid int32,
 }
 
 // parseStatm gets memory info from /proc/(pid)/statm
-func (p *probe) parseStatm(pidPath string) *MemoryInfoExStat {
+func (p *probe) parseStatm(pidPath string, collection *CollectionStats) *MemoryInfoExStat {
 	path := filepath.Join(pidPath, "statm")
 	var err error
 
@@ -653,10 +726,15 @@ func (p *probe) parseStatm(pidPath string) *MemoryInfoExStat {
 
 	contents, err := os.ReadFile(path)
 	if err != nil {
+		collection.recordReadError("statm", err)
 		return memInfoEx
 	}
 
 	fields := strings.Fields(string(contents))
+	if len(fields) < 7 {
+		collection.recordParseFailure("statm")
+		return memInfoEx
+	}
 
 	// the values for the fields are per-page, to get real numbers we multiply by PageSize
 	vms, err := strconv.ParseUint(fields[0], 10, 64)
@@ -692,14 +770,16 @@ func (p *probe) parseStatm(pidPath string) *MemoryInfoExStat {
 }
 
 // getLinkWithAuthCheck fetches the destination of a symlink with permission check
-func (p *probe) getLinkWithAuthCheck(pidPath string, file string) string {
+func (p *probe) getLinkWithAuthCheck(pidPath string, file string, collection *CollectionStats) string {
 	path := filepath.Join(pidPath, file)
 	if err := p.ensurePathReadable(path); err != nil {
+		collection.recordReadError(file, err)
 		return ""
 	}
 
 	str, err := os.Readlink(path)
 	if err != nil {
+		collection.recordReadError(file, err)
 		return ""
 	}
 	return str
@@ -707,15 +787,17 @@ func (p *probe) getLinkWithAuthCheck(pidPath string, file string) string {
 
 // getFDCount gets num_fds from /proc/(pid)/fd WITHOUT using the native Readdirnames(),
 // this will skip the step of returning all file names(we don't need) in a dir which takes a lot of memory
-func (p *probe) getFDCount(pidPath string) int32 {
+func (p *probe) getFDCount(pidPath string, collection *CollectionStats) int32 {
 	path := filepath.Join(pidPath, "fd")
 
 	if err := p.ensurePathReadable(path); err != nil {
+		collection.recordReadError("fd", err)
 		return -1
 	}
 
 	d, err := os.Open(path)
 	if err != nil {
+		collection.recordReadError("fd", err)
 		return -1
 	}
 	defer d.Close()
@@ -124,7 +124,7 @@ func testGetCmdline(t *testing.T) {
 	assert.NoError(t, err)
 
 	for _, pid := range pids {
-		actual := strings.Join(probe.getCmdline(filepath.Join(probe.procRootLoc, strconv.Itoa(int(pid)))), " ")
+		actual := strings.Join(probe.getCmdline(filepath.Join(probe.procRootLoc, strconv.Itoa(int(pid))), nil), " ")
 		expProc, err := process.NewProcess(pid)
 		assert.NoError(t, err)
 
@@ -163,8 +163,8 @@ func testProcessesByPID(t *testing.T) {
 	// make sure the process that has no command line doesn't get included in the output
 	for pid, expectProc := range expectedProcs {
 		pathForPID := filepath.Join(probe.procRootLoc, strconv.Itoa(int(pid)))
-		cmd := strings.Join(probe.getCmdline(pathForPID), " ")
-		statInfo := probe.parseStat(pathForPID, pid, time.Now())
+		cmd := strings.Join(probe.getCmdline(pathForPID, nil), " ")
+		statInfo := probe.parseStat(pathForPID, pid, time.Now(), nil)
 		if cmd == "" && isKernelThread(statInfo.flags) {
 			assert.NotContains(t, procByPID, pid)
 		} else {
@@ -226,6 +226,41 @@ func compareStats(t *testing.T, st1, st2 *Stats) {
 	assert.EqualValues(t, st1.IOStat, st2.IOStat)
 }
 
+func TestProcessesByPIDCollectionStats(t *testing.T) {
+	procRoot := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(procRoot, "stat"), []byte("btime 1684000000\n"), 0644))
+	pidPath := filepath.Join(procRoot, "42")
+	require.NoError(t, os.Mkdir(pidPath, 0755))
+	for file, content := range map[string]string{
+		"cmdline": "sleep\x0010",
+		"status":  "Name:\tsleep\n",
+		"stat":    "42 (sleep",
+		"statm":   "1 2",
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(pidPath, file), []byte(content), 0644))
+	}
+	// readable only by its owner
+	require.NoError(t, os.WriteFile(filepath.Join(pidPath, "io"), nil, 0600))
+	require.NoError(t, os.Symlink("/usr/bin/sleep", filepath.Join(pidPath, "exe")))
+
+	t.Setenv("HOST_PROC", procRoot)
+	probe := getProbeWithPermission()
+	defer probe.Close()
+	// the files aren't owned by the user of the probe
+	probe.uid = uint32(os.Getuid()) + 1
+	probe.euid = probe.uid
+
+	procByPID, err := probe.ProcessesByPID(time.Now(), true)
+	require.NoError(t, err)
+	assert.Contains(t, procByPID, int32(42))
+
+	stats := probe.LastCollectionStats()
+	assert.Equal(t, 1, stats.PIDsScanned)
+	assert.Equal(t, map[string]int{"exe": 1, "io": 1}, stats.PermissionDenied)
+	assert.Equal(t, map[string]int{"stat": 1, "statm": 1}, stats.ParseFailures)
+	assert.Positive(t, stats.Elapsed)
+}
+
 func TestStatsForPIDsTestFS(t *testing.T) {
 	t.Setenv("HOST_PROC", "resources/test_procfs/proc/")
 
@@ -496,7 +531,7 @@ func testParseStatus(t *testing.T) {
 	assert.NoError(t, err)
 
 	for _, pid := range pids {
-		actual := probe.parseStatus(filepath.Join(probe.procRootLoc, strconv.Itoa(int(pid))))
+		actual := probe.parseStatus(filepath.Join(probe.procRootLoc, strconv.Itoa(int(pid))), nil)
 		expProc, err := process.NewProcess(pid)
 		assert.NoError(t, err)
 
@@ -540,19 +575,19 @@ func TestFillNsPidFromStatus(t *testing.T) {
 		// Process started on the host namespace
 		// NSpid:	6320
 		pid := 6320
-		actual := probe.parseStatus(filepath.Join(probe.procRootLoc, strconv.Itoa(pid)))
+		actual := probe.parseStatus(filepath.Join(probe.procRootLoc, strconv.Itoa(pid)), nil)
 		assert.Equal(t, int32(pid), actual.nspid)
 
 		// Process spawned from P1 with its own namespace
 		// NSpid:	6321	1
 		pid = 6321
-		actual = probe.parseStatus(filepath.Join(probe.procRootLoc, strconv.Itoa(pid)))
+		actual = probe.parseStatus(filepath.Join(probe.procRootLoc, strconv.Itoa(pid)), nil)
 		assert.Equal(t, int32(1), actual.nspid)
 
 		// Process spawned from P2 with its own namespace
 		// NSpid:	6322	2	1
 		pid = 6322
-		actual = probe.parseStatus(filepath.Join(probe.procRootLoc, strconv.Itoa(pid)))
+		actual = probe.parseStatus(filepath.Join(probe.procRootLoc, strconv.Itoa(pid)), nil)
 		assert.Equal(t, int32(1), actual.nspid)
 	})
 
@@ -562,19 +597,19 @@ func TestFillNsPidFromStatus(t *testing.T) {
 		// We expect the library not to populate the NsPid field
 		// True NSpid:	8225
 		pid := 8225
-		actual := probe.parseStatus(filepath.Join(probe.procRootLoc, strconv.Itoa(pid)))
+		actual := probe.parseStatus(filepath.Join(probe.procRootLoc, strconv.Itoa(pid)), nil)
 		assert.Equal(t, int32(0), actual.nspid)
 
 		// Process spawned from P1 with its own namespace
 		// True NSpid:	8226	1
 		pid = 8226
-		actual = probe.parseStatus(filepath.Join(probe.procRootLoc, strconv.Itoa(pid)))
+		actual = probe.parseStatus(filepath.Join(probe.procRootLoc, strconv.Itoa(pid)), nil)
 		assert.Equal(t, int32(0), actual.nspid)
 
 		// Process spawned from P2 with its own namespace
 		// True NSpid:	8227	2	1
 		pid = 8227
-		actual = probe.parseStatus(filepath.Join(probe.procRootLoc, strconv.Itoa(pid)))
+		actual = probe.parseStatus(filepath.Join(probe.procRootLoc, strconv.Itoa(pid)), nil)
 		assert.Equal(t, int32(0), actual.nspid)
 	})
 }
@@ -629,7 +664,7 @@ func testParseIO(t *testing.T) {
 	require.NoError(t, err)
 
 	for _, pid := range pids {
-		actual := probe.parseIO(filepath.Join(probe.procRootLoc, strconv.Itoa(int(pid))))
+		actual := probe.parseIO(filepath.Join(probe.procRootLoc, strconv.Itoa(int(pid))), nil)
 		expProc, err := process.NewProcess(pid)
 		require.NoError(t, err)
 		expIO, err := expProc.IOCounters()
@@ -644,12 +679,12 @@ func TestFetchFieldsWithoutPermission(t *testing.T) {
 	defer probe.Close()
 	// PID 1 should be owned by root so we would always get permission error
 	pid := int32(1)
-	actual := probe.parseIO(filepath.Join(probe.procRootLoc, strconv.Itoa(int(pid))))
+	actual := probe.parseIO(filepath.Join(probe.procRootLoc, strconv.Itoa(int(pid))), nil)
 	assert.Equal(t, int64(-1), actual.ReadCount)
 	assert.Equal(t, int64(-1), actual.ReadBytes)
 	assert.Equal(t, int64(-1), actual.WriteCount)
 	assert.Equal(t, int64(-1), actual.WriteBytes)
-	fd := probe.getFDCount(strconv.Itoa(int(pid)))
+	fd := probe.getFDCount(strconv.Itoa(int(pid)), nil)
 	assert.Equal(t, int32(-1), fd)
 }
 
@@ -709,7 +744,7 @@ func TestParseStatContent(t *testing.T) {
 		},
 	} {
 
-		actual := probe.parseStatContent(tc.line, &statInfo{cpuStat: &CPUTimesStat{}}, int32(1), now)
+		actual := probe.parseStatContent(tc.line, &statInfo{cpuStat: &CPUTimesStat{}}, int32(1), now, nil)
 		// nice value is fetched at the run time so we just assign the actual value for the sake for comparison
 		tc.expected.nice = actual.nice
 		assert.EqualValues(t, tc.expected, actual)
@@ -738,7 +773,7 @@ func testParseStat(t *testing.T) {
 	assert.NoError(t, err)
 
 	for _, pid := range pids {
-		actual := probe.parseStat(filepath.Join(probe.procRootLoc, strconv.Itoa(int(pid))), pid, time.Now())
+		actual := probe.parseStat(filepath.Join(probe.procRootLoc, strconv.Itoa(int(pid))), pid, time.Now(), nil)
 		expProc, err := process.NewProcess(pid)
 		assert.NoError(t, err)
 		expCreate, err := expProc.CreateTime()
@@ -811,7 +846,7 @@ func testParseStatm(t *testing.T) {
 	assert.NoError(t, err)
 
 	for _, pid := range pids {
-		actual := probe.parseStatm(filepath.Join(probe.procRootLoc, strconv.Itoa(int(pid))))
+		actual := probe.parseStatm(filepath.Join(probe.procRootLoc, strconv.Itoa(int(pid))), nil)
 		expProc, err := process.NewProcess(pid)
 		assert.NoError(t, err)
 		memInfo, err := expProc.MemoryInfoEx()
@@ -848,8 +883,8 @@ func testParseStatmStatusMatch(t *testing.T) {
 	assert.NoError(t, err)
 
 	for _, pid := range pids {
-		statm := probe.parseStatm(filepath.Join(probe.procRootLoc, strconv.Itoa(int(pid))))
-		status := probe.parseStatus(filepath.Join(probe.procRootLoc, strconv.Itoa(int(pid))))
+		statm := probe.parseStatm(filepath.Join(probe.procRootLoc, strconv.Itoa(int(pid))), nil)
+		status := probe.parseStatus(filepath.Join(probe.procRootLoc, strconv.Itoa(int(pid))), nil)
 		assert.Equal(t, statm.VMS, status.memInfo.VMS)
 		assert.Equal(t, statm.RSS, status.memInfo.RSS)
 	}
@@ -875,8 +910,8 @@ func testGetLinkWithAuthCheck(t *testing.T) {
 
 	for _, pid := range pids {
 		pathForPID := filepath.Join(probe.procRootLoc, strconv.Itoa(int(pid)))
-		cwd := probe.getLinkWithAuthCheck(pathForPID, "cwd")
-		exe := probe.getLinkWithAuthCheck(pathForPID, "exe")
+		cwd := probe.getLinkWithAuthCheck(pathForPID, "cwd", nil)
+		exe := probe.getLinkWithAuthCheck(pathForPID, "exe", nil)
 
 		expProc, err := process.NewProcess(pid)
 		assert.NoError(t, err)
@@ -899,7 +934,7 @@ func TestGetFDCountLocalFS(t *testing.T) {
 
 	for _, pid := range pids {
 		pathForPID := filepath.Join(probe.procRootLoc, strconv.Itoa(int(pid)))
-		fdCount := probe.getFDCount(pathForPID)
+		fdCount := probe.getFDCount(pathForPID, nil)
 		expProc, err := process.NewProcess(pid)
 		assert.NoError(t, err)
 		// test both with and without permission issues
@@ -1022,7 +1057,7 @@ func benchmarkGetCmdProcutil(b *testing.B) {
 
 	for i := 0; i < b.N; i++ {
 		for _, pid := range pids {
-			probe.getCmdline(filepath.Join(probe.procRootLoc, strconv.Itoa(int(pid))))
+			probe.getCmdline(filepath.Join(probe.procRootLoc, strconv.Itoa(int(pid))), nil)
 		}
 	}
 }
@@ -1056,7 +1091,7 @@ func BenchmarkTestFSStatusProcutil(b *testing.B) {
 
 	for i := 0; i < b.N; i++ {
 		for _, pid := range pids {
-			probe.parseStatus(filepath.Join(hostProc, strconv.Itoa(int(pid))))
+			probe.parseStatus(filepath.Join(hostProc, strconv.Itoa(int(pid))), nil)
 		}
 	}
 }
@@ -1084,7 +1119,7 @@ func BenchmarkLocalFSStatusProcutil(b *testing.B) {
 
 	for i := 0; i < b.N; i++ {
 		for _, pid := range pids {
-			probe.parseStatus(filepath.Join(probe.procRootLoc, strconv.Itoa(int(pid))))
+			probe.parseStatus(filepath.Join(probe.procRootLoc, strconv.Itoa(int(pid))), nil)
 		}
 	}
 }
@@ -1148,7 +1183,7 @@ func benchmarkParseIOProcutil(b *testing.B) {
 
 	for i := 0; i < b.N; i++ {
 		for _, pid := range pids {
-			probe.parseIO(filepath.Join(probe.procRootLoc, strconv.Itoa(int(pid))))
+			probe.parseIO(filepath.Join(probe.procRootLoc, strconv.Itoa(int(pid))), nil)
 		}
 	}
 }
@@ -74,6 +74,9 @@ type Stats struct {
 	IOStat      *IOCountersStat
 	IORateStat  *IOCountersRateStat
 	CtxSwitches *NumCtxSwitchesStat
+	GPUStat     *GPUStat
+	SchedStat   *SchedStat
+	IOPressure  *PressureStat
 }
 
 // DeepCopy creates a deep copy of Stats
@@ -113,6 +116,18 @@ func (s *Stats) DeepCopy() *Stats {
 		copy.CtxSwitches = &NumCtxSwitchesStat{}
 		*copy.CtxSwitches = *s.CtxSwitches
 	}
+	if s.GPUStat != nil {
+		copy.GPUStat = &GPUStat{}
+		*copy.GPUStat = *s.GPUStat
+	}
+	if s.SchedStat != nil {
+		copy.SchedStat = &SchedStat{}
+		*copy.SchedStat = *s.SchedStat
+	}
+	if s.IOPressure != nil {
+		copy.IOPressure = &PressureStat{}
+		*copy.IOPressure = *s.IOPressure
+	}
 	return copy
 }
 
@@ -122,6 +137,40 @@ type StatsWithPerm struct {
 	IOStat      *IOCountersStat
 }
 
+// GPUStat holds the GPU usage of a process, summed across all the GPUs it runs on
+type GPUStat struct {
+	// MemoryBytes is the GPU frame buffer memory used by the process
+	MemoryBytes uint64
+	// UtilizationPct is the percentage of time the GPU streaming multiprocessors were busy with the process
+	UtilizationPct float64
+}
+
+// SchedStat holds the scheduler statistics of a process, summed across all its threads
+type SchedStat struct {
+	// RunTimeNs is the time spent running on a CPU
+	RunTimeNs uint64
+	// WaitTimeNs is the time spent runnable but waiting on a run queue
+	WaitTimeNs uint64
+	// Timeslices is the number of timeslices run on a CPU
+	Timeslices uint64
+}
+
+// PressureStat holds the pressure stall information of the cgroup of a process
+type PressureStat struct {
+	// Some is the share of time at least one task of the cgroup was stalled
+	Some PressureLineStat
+	// Full is the share of time all the non-idle tasks of the cgroup were stalled simultaneously
+	Full PressureLineStat
+}
+
+// PressureLineStat holds the stall time ratios over the last 10, 60 and 300 seconds, and the total stall time
+type PressureLineStat struct {
+	Avg10   float64
+	Avg60   float64
+	Avg300  float64
+	TotalUs uint64
+}
+
 // CPUTimesStat holds CPU stat metrics of a process
 type CPUTimesStat struct {
 	User      float64
@@ -55,6 +55,8 @@ type probe struct {
 
 	instanceToPID map[string]int32
 	procs         map[int32]*Process
+
+	lastCollectionStats lastCollectionStats
 }
 
 func (p *probe) init() {
@@ -186,11 +188,19 @@ func (p *probe) StatsForPIDs(pids []int32, now time.Time) (map[int32]*Stats, err
 }
 
 func (p *probe) ProcessesByPID(now time.Time, collectStats bool) (map[int32]*Process, error) {
+	start := time.Now()
+	collection := newCollectionStats()
+	defer func() {
+		collection.Elapsed = time.Since(start)
+		p.lastCollectionStats.set(collection)
+	}()
+
 	// TODO: reuse PIDs slice across runs
 	pids, err := getPIDs()
 	if err != nil {
 		return nil, err
 	}
+	collection.PIDsScanned = len(pids)
 
 	knownPids := make(map[int32]struct{}, len(p.procs))
 	for pid := range p.procs {
@@ -250,6 +260,11 @@ func (p *probe) ProcessesByPID(now time.Time, collectStats bool) (map[int32]*Pro
 	return procsToReturn, nil
 }
 
+// LastCollectionStats returns the stats of the last collection of the processes by ProcessesByPID
+func (p *probe) LastCollectionStats() CollectionStats {
+	return p.lastCollectionStats.get()
+}
+
 func (p *probe) enumCounters(collectMeta bool, collectStats bool) error {
 	// Reuse map's capacity across runs
 	for k := range p.instanceToPID {
@@ -302,6 +317,11 @@ func (p *probe) StatsWithPermByPID(pids []int32) (map[int32]*StatsWithPerm, erro
 	return nil, fmt.Errorf("probe(Windows): StatsWithPermByPID is not implemented")
 }
 
+// InspectProcess is currently not implemented on Windows
+func (p *probe) InspectProcess(pid int32) (*ProcessDetails, error) {
+	return nil, fmt.Errorf("probe(Windows): InspectProcess is not implemented")
+}
+
 func (p *probe) getProc(instance string) *Process {
 	pid, ok := p.instanceToPID[instance]
 	if !ok {
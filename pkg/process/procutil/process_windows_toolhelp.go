@@ -66,6 +66,8 @@ func getProcessIoCounters(h windows.Handle, counters *IO_COUNTERS) (err error) {
 
 type windowsToolhelpProbe struct {
 	cachedProcesses map[uint32]*cachedProcess
+
+	lastCollectionStats lastCollectionStats
 }
 
 // NewWindowsToolhelpProbe provides an implementation of a process probe based on Toolhelp API
@@ -94,7 +96,24 @@ func (p *windowsToolhelpProbe) StatsWithPermByPID(pids []int32) (map[int32]*Stat
 	return nil, fmt.Errorf("windowsToolhelpProbe: StatsWithPermByPID is not implemented")
 }
 
+// InspectProcess is currently not implemented in non-linux environments
+func (p *windowsToolhelpProbe) InspectProcess(pid int32) (*ProcessDetails, error) {
+	return nil, fmt.Errorf("windowsToolhelpProbe: InspectProcess is not implemented")
+}
+
+// LastCollectionStats returns the stats of the last collection of the processes by ProcessesByPID
+func (p *windowsToolhelpProbe) LastCollectionStats() CollectionStats {
+	return p.lastCollectionStats.get()
+}
+
 func (p *windowsToolhelpProbe) ProcessesByPID(now time.Time, collectStats bool) (map[int32]*Process, error) {
+	start := time.Now()
+	collection := newCollectionStats()
+	defer func() {
+		collection.Elapsed = time.Since(start)
+		p.lastCollectionStats.set(collection)
+	}()
+
 	// make sure we get the consistent snapshot by using the same OS thread
 	runtime.LockOSThread()
 	defer runtime.UnlockOSThread()
@@ -124,6 +143,7 @@ func (p *windowsToolhelpProbe) ProcessesByPID(now time.Time, collectStats bool)
 			// want to do.
 			continue
 		}
+		collection.PIDsScanned++
 		cp, ok := p.cachedProcesses[pid]
 		if !ok {
 			// wasn't already in the map.
@@ -0,0 +1,223 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package procutil
+
+import (
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// ServiceNameSource is the process attribute a service name was inferred from
+type ServiceNameSource string
+
+const (
+	// ServiceNameSourceDDService is used when the service name is the DD_SERVICE environment variable of the process
+	ServiceNameSourceDDService ServiceNameSource = "dd_service"
+	// ServiceNameSourceSystemd is used when the service name is the systemd unit running the process
+	ServiceNameSourceSystemd ServiceNameSource = "systemd"
+	// ServiceNameSourceCommandLine is used when the service name is inferred from the command line of the process
+	ServiceNameSourceCommandLine ServiceNameSource = "command_line"
+)
+
+// ServiceName is the service name inferred for a process. It is shared by the products
+// naming processes, e.g. the process_context tag, USM stats and logs auto-configuration,
+// so that all of them agree on the service of a given process.
+type ServiceName struct {
+	Name   string
+	Source ServiceNameSource
+}
+
+type serviceExtractorFn func(args []string) string
+
+const (
+	ddServiceEnvVar  = "DD_SERVICE"
+	javaJarFlag      = "-jar"
+	javaJarExtension = ".jar"
+	javaApachePrefix = "org.apache."
+)
+
+// List of binaries that usually have additional process context of whats running
+var binsWithContext = map[string]serviceExtractorFn{
+	"python":    parseCommandContextPython,
+	"python2.7": parseCommandContextPython,
+	"python3":   parseCommandContextPython,
+	"python3.7": parseCommandContextPython,
+	"ruby2.3":   parseCommandContext,
+	"ruby":      parseCommandContext,
+	"java":      parseCommandContextJava,
+	"java.exe":  parseCommandContextJava,
+	"sudo":      parseCommandContext,
+}
+
+// ServiceNameFromEnv returns the value of DD_SERVICE in the given environment variables, formatted as `KEY=value`
+func ServiceNameFromEnv(envs []string) string {
+	for _, env := range envs {
+		if value := strings.TrimPrefix(env, ddServiceEnvVar+"="); len(value) < len(env) {
+			return value
+		}
+	}
+	return ""
+}
+
+// ServiceNameFromCmdline infers a service name from the command line of a process: the
+// script, module or jar run by interpreters such as python or java, or the executable name.
+func ServiceNameFromCmdline(cmd []string) string {
+	if len(cmd) == 0 || len(cmd[0]) == 0 {
+		return ""
+	}
+
+	exe := cmd[0]
+	// check if all args are packed into the first argument
+	if len(cmd) == 1 {
+		if idx := strings.IndexRune(exe, ' '); idx != -1 {
+			exe = exe[0:idx]
+			cmd = strings.Split(cmd[0], " ")
+		}
+	}
+
+	// trim any quotes from the executable
+	exe = strings.Trim(exe, "\"")
+
+	// Extract executable from commandline args
+	exe = trimColonRight(removeFilePath(exe))
+	if !isRuneLetterAt(exe, 0) {
+		exe = parseExeStartWithSymbol(exe)
+	}
+
+	if contextFn, ok := binsWithContext[exe]; ok {
+		return contextFn(cmd[1:])
+	}
+
+	// trim trailing file extensions
+	if i := strings.LastIndex(exe, "."); i > 0 {
+		exe = exe[:i]
+	}
+
+	return exe
+}
+
+func removeFilePath(s string) string {
+	if s != "" {
+		return filepath.Base(s)
+	}
+	return s
+}
+
+// trimColonRight will remove any colon and it's associated value right of the string
+func trimColonRight(s string) string {
+	if i := strings.Index(s, ":"); i > 0 {
+		return s[:i]
+	}
+
+	return s
+}
+
+func isRuneLetterAt(s string, position int) bool {
+	return len(s) > position && unicode.IsLetter(rune(s[position]))
+}
+
+// parseExeStartWithSymbol deals with exe that starts with special chars like "(", "-" or "["
+func parseExeStartWithSymbol(exe string) string {
+	if exe == "" {
+		return exe
+	}
+	// drop the first character
+	result := exe[1:]
+	// if last character is also special character, also drop it
+	if result != "" && !isRuneLetterAt(result, len(result)-1) {
+		result = result[:len(result)-1]
+	}
+	return result
+}
+
+// In most cases, the best context is the first non-argument / environment variable, if it exists
+func parseCommandContext(args []string) string {
+	var prevArgIsFlag bool
+
+	for _, a := range args {
+		hasFlagPrefix, isEnvVariable := strings.HasPrefix(a, "-"), strings.ContainsRune(a, '=')
+		shouldSkipArg := prevArgIsFlag || hasFlagPrefix || isEnvVariable
+
+		if !shouldSkipArg {
+			if c := trimColonRight(removeFilePath(a)); isRuneLetterAt(c, 0) {
+				return c
+			}
+		}
+
+		prevArgIsFlag = hasFlagPrefix
+	}
+
+	return ""
+}
+
+func parseCommandContextPython(args []string) string {
+	var (
+		prevArgIsFlag bool
+		moduleFlag    bool
+	)
+
+	for _, a := range args {
+		hasFlagPrefix, isEnvVariable := strings.HasPrefix(a, "-"), strings.ContainsRune(a, '=')
+
+		shouldSkipArg := prevArgIsFlag || hasFlagPrefix || isEnvVariable
+
+		if !shouldSkipArg || moduleFlag {
+			if c := trimColonRight(removeFilePath(a)); isRuneLetterAt(c, 0) {
+				return c
+			}
+		}
+
+		if hasFlagPrefix && a == "-m" {
+			moduleFlag = true
+		}
+
+		prevArgIsFlag = hasFlagPrefix
+	}
+
+	return ""
+}
+
+func parseCommandContextJava(args []string) string {
+	prevArgIsFlag := false
+
+	for _, a := range args {
+		hasFlagPrefix := strings.HasPrefix(a, "-")
+		includesAssignment := strings.ContainsRune(a, '=') ||
+			strings.HasPrefix(a, "-X") ||
+			strings.HasPrefix(a, "-javaagent:") ||
+			strings.HasPrefix(a, "-verbose:")
+		shouldSkipArg := prevArgIsFlag || hasFlagPrefix || includesAssignment
+		if !shouldSkipArg {
+			arg := removeFilePath(a)
+
+			if arg = trimColonRight(arg); isRuneLetterAt(arg, 0) {
+				if strings.HasSuffix(arg, javaJarExtension) {
+					return arg[:len(arg)-len(javaJarExtension)]
+				}
+
+				if strings.HasPrefix(arg, javaApachePrefix) {
+					// take the project name after the package 'org.apache.' while stripping off the remaining package
+					// and class name
+					arg = arg[len(javaApachePrefix):]
+					if idx := strings.Index(arg, "."); idx != -1 {
+						return arg[:idx]
+					}
+				}
+				if idx := strings.LastIndex(arg, "."); idx != -1 && idx+1 < len(arg) {
+					// take just the class name without the package
+					return arg[idx+1:]
+				}
+
+				return arg
+			}
+		}
+
+		prevArgIsFlag = hasFlagPrefix && !includesAssignment && a != javaJarFlag
+	}
+
+	return ""
+}
@@ -0,0 +1,74 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package procutil
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const systemdServiceSuffix = ".service"
+
+// InferServiceName returns the service name of a process, taken from the first available of:
+// the DD_SERVICE environment variable of the process, the systemd unit running it, and its command line.
+// procRoot is the location of the procfs of the host, e.g. util.HostProc().
+func InferServiceName(procRoot string, pid int32, cmdline []string) ServiceName {
+	procDir := filepath.Join(procRoot, strconv.Itoa(int(pid)))
+
+	// reading the environment of a process requires the same permissions as ptrace
+	if environ, err := os.ReadFile(filepath.Join(procDir, "environ")); err == nil {
+		if name := ServiceNameFromEnv(strings.Split(string(environ), "\x00")); name != "" {
+			return ServiceName{Name: name, Source: ServiceNameSourceDDService}
+		}
+	}
+
+	if cgroups, err := os.ReadFile(filepath.Join(procDir, "cgroup")); err == nil {
+		if name := serviceNameFromCgroups(cgroups); name != "" {
+			return ServiceName{Name: name, Source: ServiceNameSourceSystemd}
+		}
+	}
+
+	if name := ServiceNameFromCmdline(cmdline); name != "" {
+		return ServiceName{Name: name, Source: ServiceNameSourceCommandLine}
+	}
+	return ServiceName{}
+}
+
+// serviceNameFromCgroups returns the name of the systemd service unit from the content of /proc/<pid>/cgroup,
+// e.g. `nginx` for `0::/system.slice/nginx.service`. Template units are named after their template, and the
+// units of the user managers are ignored as they are the parents of every process of a user session.
+func serviceNameFromCgroups(cgroups []byte) string {
+	scanner := bufio.NewScanner(bytes.NewReader(cgroups))
+	for scanner.Scan() {
+		// hierarchy-ID:controller-list:cgroup-path
+		fields := strings.SplitN(scanner.Text(), ":", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		segments := strings.Split(fields[2], "/")
+		for i := len(segments) - 1; i >= 0; i-- {
+			unit := segments[i]
+			if !strings.HasSuffix(unit, systemdServiceSuffix) || strings.HasPrefix(unit, "user@") {
+				continue
+			}
+			unit = strings.TrimSuffix(unit, systemdServiceSuffix)
+			if idx := strings.IndexByte(unit, '@'); idx != -1 {
+				unit = unit[:idx]
+			}
+			if unit != "" {
+				return unit
+			}
+		}
+	}
+	return ""
+}
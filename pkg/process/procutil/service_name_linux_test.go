@@ -0,0 +1,81 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package procutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInferServiceName(t *testing.T) {
+	tests := []struct {
+		name     string
+		environ  string
+		cgroup   string
+		cmdline  []string
+		expected ServiceName
+	}{
+		{
+			name:     "DD_SERVICE",
+			environ:  "PATH=/usr/bin\x00DD_SERVICE=billing\x00",
+			cgroup:   "0::/system.slice/billing-api.service\n",
+			cmdline:  []string{"/usr/bin/java", "-jar", "billing.jar"},
+			expected: ServiceName{Name: "billing", Source: ServiceNameSourceDDService},
+		},
+		{
+			name:     "systemd unit",
+			environ:  "PATH=/usr/bin\x00",
+			cgroup:   "0::/system.slice/nginx.service\n",
+			cmdline:  []string{"nginx: worker process"},
+			expected: ServiceName{Name: "nginx", Source: ServiceNameSourceSystemd},
+		},
+		{
+			name:     "systemd template unit with cgroup v1",
+			cgroup:   "12:pids:/system.slice/worker@2.service\n1:name=systemd:/system.slice/worker@2.service\n",
+			cmdline:  []string{"/opt/worker/bin/run"},
+			expected: ServiceName{Name: "worker", Source: ServiceNameSourceSystemd},
+		},
+		{
+			name:     "unit of a user manager",
+			cgroup:   "0::/user.slice/user-1000.slice/user@1000.service/app.slice/sync.service\n",
+			cmdline:  []string{"/usr/bin/syncd"},
+			expected: ServiceName{Name: "sync", Source: ServiceNameSourceSystemd},
+		},
+		{
+			name:     "user session",
+			cgroup:   "0::/user.slice/user-1000.slice/user@1000.service/app.slice/app-term.scope\n",
+			cmdline:  []string{"/usr/bin/python3", "server.py"},
+			expected: ServiceName{Name: "server.py", Source: ServiceNameSourceCommandLine},
+		},
+		{
+			name:     "nothing to infer from",
+			expected: ServiceName{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			procRoot := t.TempDir()
+			procDir := filepath.Join(procRoot, "42")
+			require.NoError(t, os.MkdirAll(procDir, 0755))
+			if tt.environ != "" {
+				require.NoError(t, os.WriteFile(filepath.Join(procDir, "environ"), []byte(tt.environ), 0644))
+			}
+			if tt.cgroup != "" {
+				require.NoError(t, os.WriteFile(filepath.Join(procDir, "cgroup"), []byte(tt.cgroup), 0644))
+			}
+
+			assert.Equal(t, tt.expected, InferServiceName(procRoot, 42, tt.cmdline))
+		})
+	}
+}
@@ -0,0 +1,18 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build !linux
+// +build !linux
+
+package procutil
+
+// InferServiceName returns the service name of a process. Only the command line of the process
+// is used on this platform.
+func InferServiceName(_ string, _ int32, cmdline []string) ServiceName {
+	if name := ServiceNameFromCmdline(cmdline); name != "" {
+		return ServiceName{Name: name, Source: ServiceNameSourceCommandLine}
+	}
+	return ServiceName{}
+}
@@ -0,0 +1,68 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package procutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServiceNameFromEnv(t *testing.T) {
+	assert.Equal(t, "billing", ServiceNameFromEnv([]string{"PATH=/usr/bin", "DD_SERVICE=billing", "DD_ENV=prod"}))
+	assert.Equal(t, "", ServiceNameFromEnv([]string{"PATH=/usr/bin", "DD_SERVICE_MAPPING=a:b"}))
+	assert.Equal(t, "", ServiceNameFromEnv(nil))
+}
+
+func TestServiceNameFromCmdline(t *testing.T) {
+	tests := []struct {
+		name     string
+		cmdline  []string
+		expected string
+	}{
+		{
+			name:     "empty",
+			cmdline:  []string{},
+			expected: "",
+		},
+		{
+			name:     "executable",
+			cmdline:  []string{"/usr/local/bin/my-server.sh", "--port", "8080"},
+			expected: "my-server",
+		},
+		{
+			name:     "args packed in the first argument",
+			cmdline:  []string{"/usr/bin/python3 -m gunicorn app:app"},
+			expected: "gunicorn",
+		},
+		{
+			name:     "python module",
+			cmdline:  []string{"python3", "-m", "celery", "worker"},
+			expected: "celery",
+		},
+		{
+			name:     "java jar",
+			cmdline:  []string{"/usr/bin/java", "-Xmx2g", "-Dfoo=bar", "-jar", "/opt/app/payments.jar"},
+			expected: "payments",
+		},
+		{
+			name:     "java apache",
+			cmdline:  []string{"java", "-cp", "/opt/kafka/libs/*", "org.apache.kafka.Kafka", "server.properties"},
+			expected: "kafka",
+		},
+		{
+			name:     "java main class",
+			cmdline:  []string{"java", "-cp", "app.jar", "com.example.OrderService"},
+			expected: "OrderService",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ServiceNameFromCmdline(tt.cmdline))
+		})
+	}
+}
@@ -340,6 +340,7 @@ func (l *CheckRunner) runnerForCheck(c checks.Check) (func(), error) {
 		checks.RunnerConfig{
 			CheckInterval:  interval,
 			RtInterval:     rtInterval,
+			RtBudget:       l.config.GetDuration("process_config.rt_collection_budget"),
 			ExitChan:       l.stop,
 			RtIntervalChan: l.rtIntervalCh,
 			RtEnabled: func() bool {
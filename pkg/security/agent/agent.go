@@ -213,6 +213,14 @@ func (rsa *RuntimeSecurityAgent) DispatchActivityDump(msg *api.ActivityDumpStrea
 	}
 }
 
+// SimulateRules evaluates an event, in the JSON format of EventData, against the rules loaded by the system probe
+func (rsa *RuntimeSecurityAgent) SimulateRules(ctx context.Context, event []byte) (*api.SimulateRulesMessage, error) {
+	if rsa.client == nil {
+		return nil, errors.New("not connected to the system probe")
+	}
+	return rsa.client.SimulateRules(ctx, event)
+}
+
 // GetStatus returns the current status on the agent
 func (rsa *RuntimeSecurityAgent) GetStatus() map[string]interface{} {
 	base := map[string]interface{}{
@@ -105,6 +105,11 @@ func (c *RuntimeSecurityClient) ReloadPolicies() (*api.ReloadPoliciesResultMessa
 	return response, nil
 }
 
+// SimulateRules instructs the system probe to evaluate an event, in the JSON format of EventData, against its rules
+func (c *RuntimeSecurityClient) SimulateRules(ctx context.Context, event []byte) (*api.SimulateRulesMessage, error) {
+	return c.apiClient.SimulateRules(ctx, &api.SimulateRulesParams{Event: event})
+}
+
 // GetEvents returns a stream of events
 func (c *RuntimeSecurityClient) GetEvents() (api.SecurityModule_GetEventsClient, error) {
 	stream, err := c.apiClient.GetEvents(context.Background(), &api.GetEventParams{})
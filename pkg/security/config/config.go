@@ -146,6 +146,15 @@ type RuntimeSecurityConfig struct {
 	// SBOMResolverWorkloadsCacheSize defines the count of SBOMs to keep in memory in order to prevent re-computing
 	// the SBOMs of short-lived and periodical workloads
 	SBOMResolverWorkloadsCacheSize int
+
+	// HashResolverEnabled defines if the hash resolver should be enabled
+	HashResolverEnabled bool
+	// HashResolverMaxFileSize defines the maximum size of the files that the hash resolver is allowed to hash
+	HashResolverMaxFileSize int64
+	// HashResolverMaxHashRate defines the maximum number of files hashed per second
+	HashResolverMaxHashRate int
+	// HashResolverCacheSize defines the number of hashes kept in cache
+	HashResolverCacheSize int
 }
 
 // Config defines a security config
@@ -230,6 +239,12 @@ func NewRuntimeSecurityConfig() (*RuntimeSecurityConfig, error) {
 		SBOMResolverEnabled:            coreconfig.SystemProbe.GetBool("runtime_security_config.sbom.enabled"),
 		SBOMResolverWorkloadsCacheSize: coreconfig.SystemProbe.GetInt("runtime_security_config.sbom.workloads_cache_size"),
 
+		// Hash resolver
+		HashResolverEnabled:     coreconfig.SystemProbe.GetBool("runtime_security_config.hash_resolver.enabled"),
+		HashResolverMaxFileSize: coreconfig.SystemProbe.GetInt64("runtime_security_config.hash_resolver.max_file_size"),
+		HashResolverMaxHashRate: coreconfig.SystemProbe.GetInt("runtime_security_config.hash_resolver.max_hash_rate"),
+		HashResolverCacheSize:   coreconfig.SystemProbe.GetInt("runtime_security_config.hash_resolver.cache_size"),
+
 		// security profiles
 		SecurityProfileEnabled:   coreconfig.SystemProbe.GetBool("runtime_security_config.security_profile.enabled"),
 		SecurityProfileDir:       coreconfig.SystemProbe.GetString("runtime_security_config.security_profile.dir"),
@@ -236,6 +236,30 @@ var (
 	// Tags: -
 	MetricSBOMResolverSBOMCacheMiss = newRuntimeMetric(".sbom_resolver.sbom_cache.miss")
 
+	// Hash resolver metrics
+
+	// MetricHashResolverHashCount is the name of the metric used to report the count of files hashed
+	// Tags: -
+	MetricHashResolverHashCount = newRuntimeMetric(".hash_resolver.hash_count")
+	// MetricHashResolverCacheLen is the name of the metric used to report the count of hashes kept in cache
+	// Tags: -
+	MetricHashResolverCacheLen = newRuntimeMetric(".hash_resolver.cache.len")
+	// MetricHashResolverCacheHit is the name of the metric used to report the count of hashes resolved from cache
+	// Tags: -
+	MetricHashResolverCacheHit = newRuntimeMetric(".hash_resolver.cache.hit")
+	// MetricHashResolverCacheMiss is the name of the metric used to report the count of hashes that weren't in cache
+	// Tags: -
+	MetricHashResolverCacheMiss = newRuntimeMetric(".hash_resolver.cache.miss")
+	// MetricHashResolverRateLimited is the name of the metric used to report the count of files not hashed because of the rate limit
+	// Tags: -
+	MetricHashResolverRateLimited = newRuntimeMetric(".hash_resolver.rate_limited")
+	// MetricHashResolverSizeLimited is the name of the metric used to report the count of files not hashed because of their size
+	// Tags: -
+	MetricHashResolverSizeLimited = newRuntimeMetric(".hash_resolver.size_limited")
+	// MetricHashResolverErrors is the name of the metric used to report the count of files which couldn't be hashed
+	// Tags: -
+	MetricHashResolverErrors = newRuntimeMetric(".hash_resolver.errors")
+
 	// Security Profile metrics
 
 	// MetricSecurityProfileActiveProfiles is the name of the metric used to report the count of active Security Profiles
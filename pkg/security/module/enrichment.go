@@ -0,0 +1,99 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package module
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/rules"
+)
+
+const (
+	// maxEnrichmentMemoryMaps is the maximum number of memory mappings attached to an event
+	maxEnrichmentMemoryMaps = 256
+	// maxEnrichmentOpenFiles is the maximum number of open files attached to an event
+	maxEnrichmentOpenFiles = 256
+	// maxEnrichmentSize is the maximum number of bytes of memory mappings and open files attached to an event
+	maxEnrichmentSize = 32 * 1024
+)
+
+// enrichmentCollector collects the process data with a size budget shared by all the collected data
+type enrichmentCollector struct {
+	enrichment ProcessEnrichment
+	size       int
+}
+
+func (c *enrichmentCollector) add(dst *[]string, entry string, maxEntries int) bool {
+	if len(*dst) >= maxEntries || c.size+len(entry) > maxEnrichmentSize {
+		c.enrichment.Truncated = true
+		return false
+	}
+	*dst = append(*dst, entry)
+	c.size += len(entry)
+	return true
+}
+
+func (c *enrichmentCollector) collectMemoryMaps(pid uint32) error {
+	f, err := os.Open(util.HostProc(strconv.FormatUint(uint64(pid), 10), "maps"))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if !c.add(&c.enrichment.MemoryMaps, scanner.Text(), maxEnrichmentMemoryMaps) {
+			return nil
+		}
+	}
+	return scanner.Err()
+}
+
+func (c *enrichmentCollector) collectOpenFiles(pid uint32) error {
+	fdDir := util.HostProc(strconv.FormatUint(uint64(pid), 10), "fd")
+	entries, err := os.ReadDir(fdDir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		target, err := os.Readlink(filepath.Join(fdDir, entry.Name()))
+		if err != nil {
+			// the file descriptor was closed in the meantime
+			continue
+		}
+		if !c.add(&c.enrichment.OpenFiles, entry.Name()+" -> "+target, maxEnrichmentOpenFiles) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// collectProcessEnrichment collects the data requested by the given 'enrich' action
+// for the given process. Collection is best effort, the process may have exited.
+func collectProcessEnrichment(pid uint32, def *rules.EnrichDefinition) (*ProcessEnrichment, error) {
+	var c enrichmentCollector
+
+	if def.MemoryMaps {
+		if err := c.collectMemoryMaps(pid); err != nil {
+			return nil, err
+		}
+	}
+	if def.OpenFiles {
+		if err := c.collectOpenFiles(pid); err != nil {
+			return nil, err
+		}
+	}
+
+	return &c.enrichment, nil
+}
@@ -23,7 +23,19 @@ type AgentContext struct {
 // easyjson:json
 type Signal struct {
 	AgentContext `json:"agent"`
-	Title        string `json:"title"`
+	Title        string             `json:"title"`
+	Enrichment   *ProcessEnrichment `json:"enrichment,omitempty"`
+	// PolicyAttributes holds the static attributes declared by the policy of the rule
+	PolicyAttributes map[string]string `json:"policy_attributes,omitempty"`
+}
+
+// ProcessEnrichment holds the data collected by the 'enrich' action of a rule
+// about the process which triggered the event
+// easyjson:json
+type ProcessEnrichment struct {
+	MemoryMaps []string `json:"memory_maps,omitempty"`
+	OpenFiles  []string `json:"open_files,omitempty"`
+	Truncated  bool     `json:"truncated,omitempty"`
 }
 
 // Event is the interface that an event must implement to be sent to the backend
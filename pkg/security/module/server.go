@@ -9,6 +9,7 @@
 package module
 
 import (
+	"bytes"
 	"context"
 	json "encoding/json"
 	"errors"
@@ -148,6 +149,29 @@ func (a *APIServer) DumpDiscarders(ctx context.Context, params *api.DumpDiscarde
 	return &api.DumpDiscardersMessage{DumpFilename: filePath}, nil
 }
 
+// SimulateRules evaluates an event against the loaded rules, without running their actions
+func (a *APIServer) SimulateRules(ctx context.Context, params *api.SimulateRulesParams) (*api.SimulateRulesMessage, error) {
+	if a.cwsConsumer == nil {
+		return nil, errors.New("failed to found module in APIServer")
+	}
+
+	ruleSet := a.cwsConsumer.GetRuleSet()
+	if ruleSet == nil {
+		return nil, errors.New("no rule set loaded")
+	}
+
+	event, err := rules.NewEventFromJSON(bytes.NewReader(params.Event))
+	if err != nil {
+		return &api.SimulateRulesMessage{Error: fmt.Sprintf("invalid event: %s", err)}, nil
+	}
+
+	report, err := json.Marshal(rules.NewSimulationReport(ruleSet, event))
+	if err != nil {
+		return nil, err
+	}
+	return &api.SimulateRulesMessage{Report: report}, nil
+}
+
 // DumpProcessCache handles process cache dump requests
 func (a *APIServer) DumpProcessCache(ctx context.Context, params *api.DumpProcessCacheParams) (*api.SecurityDumpProcessCacheMessage, error) {
 	resolvers := a.probe.GetResolvers()
@@ -408,6 +432,18 @@ func (a *APIServer) SendEvent(rule *rules.Rule, event Event, extTagsCb func() []
 	if policy := rule.Definition.Policy; policy != nil {
 		ruleEvent.AgentContext.PolicyName = policy.Name
 		ruleEvent.AgentContext.PolicyVersion = policy.Version
+		ruleEvent.PolicyAttributes = policy.Attributes
+	}
+
+	if enrich := rule.Definition.GetEnrichDefinition(); enrich != nil {
+		if ev, ok := event.(*model.Event); ok && ev.ProcessContext != nil && ev.ProcessContext.Pid != 0 {
+			enrichment, err := collectProcessEnrichment(ev.ProcessContext.Pid, enrich)
+			if err != nil {
+				seclog.Debugf("failed to enrich event for rule `%s`: %v", rule.ID, err)
+			} else {
+				ruleEvent.Enrichment = enrichment
+			}
+		}
 	}
 
 	probeJSON, err := marshalEvent(event, a.probe)
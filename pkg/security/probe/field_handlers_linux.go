@@ -217,6 +217,14 @@ func (fh *FieldHandlers) ResolveProcessEnvsTruncated(ev *model.Event, process *m
 	return truncated
 }
 
+// ResolveProcessFileHash resolves the SHA256 hash of the executable file of the process
+func (fh *FieldHandlers) ResolveProcessFileHash(ev *model.Event, process *model.Process) string {
+	if process.FileHash == "" && fh.resolvers.HashResolver != nil {
+		process.FileHash = fh.resolvers.HashResolver.ComputeHash(process, fh.ResolveFilePath(ev, &process.FileEvent))
+	}
+	return process.FileHash
+}
+
 // ResolveProcessEnvs resolves the envs of the event
 func (fh *FieldHandlers) ResolveProcessEnvs(ev *model.Event, process *model.Process) []string {
 	envs, _ := fh.resolvers.ProcessResolver.GetProcessEnvs(process)
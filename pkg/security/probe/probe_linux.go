@@ -1260,6 +1260,7 @@ func (p *Probe) NewEvaluationSet(eventTypeEnabled map[eval.EventType]bool, ruleS
 
 		ruleOpts.WithLogger(seclog.DefaultLogger)
 		ruleOpts.WithReservedRuleIDs(events.AllCustomRuleIDs())
+		evalOpts.WithCIDRSetsDir(p.Config.RuntimeSecurity.PoliciesDir)
 		if ruleSetTagValue == rules.DefaultRuleSetTagValue {
 			ruleOpts.WithSupportedDiscarders(SupportedDiscarders)
 		}
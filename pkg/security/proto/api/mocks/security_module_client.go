@@ -380,6 +380,39 @@ func (_m *SecurityModuleClient) RunSelfTest(ctx context.Context, in *api.RunSelf
 	return r0, r1
 }
 
+// SimulateRules provides a mock function with given fields: ctx, in, opts
+func (_m *SecurityModuleClient) SimulateRules(ctx context.Context, in *api.SimulateRulesParams, opts ...grpc.CallOption) (*api.SimulateRulesMessage, error) {
+	_va := make([]interface{}, len(opts))
+	for _i := range opts {
+		_va[_i] = opts[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx, in)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 *api.SimulateRulesMessage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *api.SimulateRulesParams, ...grpc.CallOption) (*api.SimulateRulesMessage, error)); ok {
+		return rf(ctx, in, opts...)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *api.SimulateRulesParams, ...grpc.CallOption) *api.SimulateRulesMessage); ok {
+		r0 = rf(ctx, in, opts...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*api.SimulateRulesMessage)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *api.SimulateRulesParams, ...grpc.CallOption) error); ok {
+		r1 = rf(ctx, in, opts...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // StopActivityDump provides a mock function with given fields: ctx, in, opts
 func (_m *SecurityModuleClient) StopActivityDump(ctx context.Context, in *api.ActivityDumpStopParams, opts ...grpc.CallOption) (*api.ActivityDumpStopMessage, error) {
 	_va := make([]interface{}, len(opts))
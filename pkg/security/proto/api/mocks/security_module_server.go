@@ -277,6 +277,32 @@ func (_m *SecurityModuleServer) RunSelfTest(_a0 context.Context, _a1 *api.RunSel
 	return r0, r1
 }
 
+// SimulateRules provides a mock function with given fields: _a0, _a1
+func (_m *SecurityModuleServer) SimulateRules(_a0 context.Context, _a1 *api.SimulateRulesParams) (*api.SimulateRulesMessage, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *api.SimulateRulesMessage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *api.SimulateRulesParams) (*api.SimulateRulesMessage, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *api.SimulateRulesParams) *api.SimulateRulesMessage); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*api.SimulateRulesMessage)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *api.SimulateRulesParams) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // StopActivityDump provides a mock function with given fields: _a0, _a1
 func (_m *SecurityModuleServer) StopActivityDump(_a0 context.Context, _a1 *api.ActivityDumpStopParams) (*api.ActivityDumpStopMessage, error) {
 	ret := _m.Called(_a0, _a1)
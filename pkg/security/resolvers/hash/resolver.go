@@ -0,0 +1,180 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package hash
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/DataDog/datadog-go/v5/statsd"
+	"github.com/hashicorp/golang-lru/v2/simplelru"
+	"go.uber.org/atomic"
+	"golang.org/x/time/rate"
+
+	"github.com/DataDog/datadog-agent/pkg/security/config"
+	"github.com/DataDog/datadog-agent/pkg/security/metrics"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/model"
+	"github.com/DataDog/datadog-agent/pkg/security/seclog"
+	"github.com/DataDog/datadog-agent/pkg/security/utils"
+)
+
+// fileKey identifies a version of a file: the inode of the file on its mount point, and its modification time so that
+// a file modified in place is hashed again
+type fileKey struct {
+	mountID uint32
+	inode   uint64
+	mtime   uint64
+}
+
+// Resolver computes the SHA256 hash of the executed files. The hashes are cached by file, and the hash computations
+// are bounded by the size of the files and rate limited, as the files are read entirely.
+type Resolver struct {
+	maxFileSize  int64
+	limiter      *rate.Limiter
+	statsdClient statsd.ClientInterface
+
+	cacheLock sync.Mutex
+	cache     *simplelru.LRU[fileKey, string]
+
+	hashCount   *atomic.Uint64
+	cacheHit    *atomic.Uint64
+	cacheMiss   *atomic.Uint64
+	rateLimited *atomic.Uint64
+	sizeLimited *atomic.Uint64
+	hashErrors  *atomic.Uint64
+}
+
+// NewResolver returns a new instance of the hash resolver
+func NewResolver(c *config.RuntimeSecurityConfig, statsdClient statsd.ClientInterface) (*Resolver, error) {
+	cache, err := simplelru.NewLRU[fileKey, string](c.HashResolverCacheSize, nil)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't create the hash resolver cache: %w", err)
+	}
+
+	return &Resolver{
+		maxFileSize:  c.HashResolverMaxFileSize,
+		limiter:      rate.NewLimiter(rate.Limit(c.HashResolverMaxHashRate), c.HashResolverMaxHashRate),
+		statsdClient: statsdClient,
+		cache:        cache,
+		hashCount:    atomic.NewUint64(0),
+		cacheHit:     atomic.NewUint64(0),
+		cacheMiss:    atomic.NewUint64(0),
+		rateLimited:  atomic.NewUint64(0),
+		sizeLimited:  atomic.NewUint64(0),
+		hashErrors:   atomic.NewUint64(0),
+	}, nil
+}
+
+// ComputeHash returns the SHA256 hash of the executable file of a process, or an empty string if the hash couldn't
+// be computed. The file is read through the root directory of the process, so that the files of the containers
+// are resolved.
+func (r *Resolver) ComputeHash(process *model.Process, path string) string {
+	if path == "" || process.FileEvent.IsFileless() {
+		return ""
+	}
+
+	key := fileKey{
+		mountID: process.FileEvent.MountID,
+		inode:   process.FileEvent.Inode,
+		mtime:   process.FileEvent.MTime,
+	}
+
+	r.cacheLock.Lock()
+	hash, ok := r.cache.Get(key)
+	r.cacheLock.Unlock()
+	if ok {
+		r.cacheHit.Inc()
+		return hash
+	}
+	r.cacheMiss.Inc()
+
+	if !r.limiter.Allow() {
+		r.rateLimited.Inc()
+		return ""
+	}
+
+	hash, err := r.hashFile(filepath.Join(utils.ProcRootPath(int32(process.Pid)), path))
+	if err != nil {
+		seclog.Debugf("couldn't hash %s of pid %d: %v", path, process.Pid, err)
+		return ""
+	}
+	r.hashCount.Inc()
+
+	r.cacheLock.Lock()
+	r.cache.Add(key, hash)
+	r.cacheLock.Unlock()
+
+	return hash
+}
+
+func (r *Resolver) hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		r.hashErrors.Inc()
+		return "", err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		r.hashErrors.Inc()
+		return "", err
+	}
+	if !info.Mode().IsRegular() {
+		r.hashErrors.Inc()
+		return "", fmt.Errorf("not a regular file")
+	}
+	if info.Size() > r.maxFileSize {
+		r.sizeLimited.Inc()
+		return "", fmt.Errorf("file size %d exceeds the maximum of %d", info.Size(), r.maxFileSize)
+	}
+
+	h := sha256.New()
+	if _, err := io.Copy(h, io.LimitReader(f, r.maxFileSize)); err != nil {
+		r.hashErrors.Inc()
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// SendStats sends the metrics of the hash resolver
+func (r *Resolver) SendStats() error {
+	r.cacheLock.Lock()
+	cacheLen := r.cache.Len()
+	r.cacheLock.Unlock()
+	if val := float64(cacheLen); val > 0 {
+		if err := r.statsdClient.Gauge(metrics.MetricHashResolverCacheLen, val, []string{}, 1.0); err != nil {
+			return fmt.Errorf("couldn't send MetricHashResolverCacheLen: %w", err)
+		}
+	}
+
+	for _, counter := range []struct {
+		metric string
+		value  *atomic.Uint64
+	}{
+		{metrics.MetricHashResolverHashCount, r.hashCount},
+		{metrics.MetricHashResolverCacheHit, r.cacheHit},
+		{metrics.MetricHashResolverCacheMiss, r.cacheMiss},
+		{metrics.MetricHashResolverRateLimited, r.rateLimited},
+		{metrics.MetricHashResolverSizeLimited, r.sizeLimited},
+		{metrics.MetricHashResolverErrors, r.hashErrors},
+	} {
+		if val := int64(counter.value.Swap(0)); val > 0 {
+			if err := r.statsdClient.Count(counter.metric, val, []string{}, 1.0); err != nil {
+				return fmt.Errorf("couldn't send %s: %w", counter.metric, err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package hash
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DataDog/datadog-go/v5/statsd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+
+	"github.com/DataDog/datadog-agent/pkg/security/config"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/model"
+)
+
+func newTestProcess(inode uint64) *model.Process {
+	process := &model.Process{}
+	process.Pid = uint32(os.Getpid())
+	process.FileEvent.MountID = 1
+	process.FileEvent.Inode = inode
+	return process
+}
+
+func TestComputeHash(t *testing.T) {
+	content := []byte("#!/bin/sh\necho hello\n")
+	sum := sha256.Sum256(content)
+	expected := hex.EncodeToString(sum[:])
+
+	dir := t.TempDir()
+	small := filepath.Join(dir, "small")
+	require.NoError(t, os.WriteFile(small, content, 0755))
+	large := filepath.Join(dir, "large")
+	require.NoError(t, os.WriteFile(large, make([]byte, 1024), 0755))
+
+	resolver, err := NewResolver(&config.RuntimeSecurityConfig{
+		HashResolverMaxFileSize: 512,
+		HashResolverMaxHashRate: 2,
+		HashResolverCacheSize:   10,
+	}, &statsd.NoOpClient{})
+	require.NoError(t, err)
+
+	t.Run("hash", func(t *testing.T) {
+		assert.Equal(t, expected, resolver.ComputeHash(newTestProcess(1), small))
+		assert.EqualValues(t, 1, resolver.hashCount.Load())
+	})
+
+	t.Run("cache", func(t *testing.T) {
+		// the file isn't read again, even if it was removed
+		require.NoError(t, os.Remove(small))
+		assert.Equal(t, expected, resolver.ComputeHash(newTestProcess(1), small))
+		assert.EqualValues(t, 1, resolver.cacheHit.Load())
+	})
+
+	t.Run("size", func(t *testing.T) {
+		assert.Empty(t, resolver.ComputeHash(newTestProcess(2), large))
+		assert.EqualValues(t, 1, resolver.sizeLimited.Load())
+	})
+
+	t.Run("rate", func(t *testing.T) {
+		resolver.limiter = rate.NewLimiter(0, 0)
+		assert.Empty(t, resolver.ComputeHash(newTestProcess(3), large))
+		assert.EqualValues(t, 1, resolver.rateLimited.Load())
+	})
+
+	t.Run("fileless", func(t *testing.T) {
+		process := newTestProcess(4)
+		process.FileEvent.MountID = 0
+		assert.Empty(t, resolver.ComputeHash(process, small))
+	})
+}
@@ -25,6 +25,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/security/resolvers/cgroup"
 	"github.com/DataDog/datadog-agent/pkg/security/resolvers/container"
 	"github.com/DataDog/datadog-agent/pkg/security/resolvers/dentry"
+	"github.com/DataDog/datadog-agent/pkg/security/resolvers/hash"
 	"github.com/DataDog/datadog-agent/pkg/security/resolvers/mount"
 	"github.com/DataDog/datadog-agent/pkg/security/resolvers/netns"
 	"github.com/DataDog/datadog-agent/pkg/security/resolvers/path"
@@ -60,6 +61,7 @@ type Resolvers struct {
 	TCResolver        *tc.Resolver
 	PathResolver      path.ResolverInterface
 	SBOMResolver      *sbom.Resolver
+	HashResolver      *hash.Resolver
 }
 
 // NewResolvers creates a new instance of Resolvers
@@ -95,6 +97,14 @@ func NewResolvers(config *config.Config, manager *manager.Manager, statsdClient
 		}
 	}
 
+	var hashResolver *hash.Resolver
+	if config.RuntimeSecurity.HashResolverEnabled {
+		hashResolver, err = hash.NewResolver(config.RuntimeSecurity, statsdClient)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	var tagsResolver tags.Resolver
 	if opts.TagsResolver != nil {
 		tagsResolver = opts.TagsResolver
@@ -147,6 +157,7 @@ func NewResolvers(config *config.Config, manager *manager.Manager, statsdClient
 		ProcessResolver:   processResolver,
 		PathResolver:      pathResolver,
 		SBOMResolver:      sbomResolver,
+		HashResolver:      hashResolver,
 	}
 
 	return resolvers, nil
@@ -27,7 +27,8 @@ Comment = ("#" | "//") { "\u0000"…"\uffff"-"\n" } .
 CIDR = IP "/" digit { digit } .
 IP = (ipv4 | ipv6) .
 Variable = "${" (alpha | "_") { "_" | alpha | digit | "." } "}" .
-Duration = digit { digit } ("ms" | "s" | "m" | "h" | "d") .
+Duration = digit { digit } ("m" ["s"] | "s" | "h" | "d") .
+RelativeTime = "now()" ("-" | "+") digit { digit } ("m" ["s"] | "s" | "h" | "d") .
 Regexp = "r\"" { "\u0000"…"\uffff"-"\""-"\\" | "\\" any } "\"" .
 Ident = (alpha | "_") { "_" | alpha | digit | "." | "[" | "]" } .
 String = "\"" { "\u0000"…"\uffff"-"\""-"\\" | "\\" any } "\"" .
@@ -55,6 +56,7 @@ func buildParser(obj interface{}, lexer lexer.Definition) *participle.Parser {
 		participle.Elide("Whitespace", "Comment"),
 		participle.Unquote("String"),
 		participle.Map(parseDuration, "Duration"),
+		participle.Map(parseRelativeTime, "RelativeTime"),
 		participle.Map(unquotePattern, "Pattern", "Regexp"),
 	)
 	if err != nil {
@@ -70,8 +72,20 @@ func unquotePattern(t lexer.Token) (lexer.Token, error) {
 	return t, nil
 }
 
+func toDuration(s string) (time.Duration, error) {
+	// days aren't supported by time.ParseDuration
+	if strings.HasSuffix(s, "d") {
+		n, err := strconv.Atoi(strings.TrimSuffix(s, "d"))
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(s)
+}
+
 func parseDuration(t lexer.Token) (lexer.Token, error) {
-	duration, err := time.ParseDuration(t.Value)
+	duration, err := toDuration(t.Value)
 	if err != nil {
 		return t, participle.Errorf(t.Pos, "invalid duration string %q: %s", t.Value, err)
 	}
@@ -81,6 +95,23 @@ func parseDuration(t lexer.Token) (lexer.Token, error) {
 	return t, nil
 }
 
+// parseRelativeTime converts a time relative to now, e.g. `now()-5m`, to its signed offset in nanoseconds.
+// It's lexed as a single token as `-5m` would otherwise be lexed as the integer `-5` followed by `m`.
+func parseRelativeTime(t lexer.Token) (lexer.Token, error) {
+	offset := strings.TrimPrefix(t.Value, "now()")
+	duration, err := toDuration(offset[1:])
+	if err != nil {
+		return t, participle.Errorf(t.Pos, "invalid relative time %q: %s", t.Value, err)
+	}
+
+	if offset[0] == '-' {
+		duration = -duration
+	}
+	t.Value = strconv.Itoa(int(duration.Nanoseconds()))
+
+	return t, nil
+}
+
 // ParseRule parses a SECL rule.
 func (pc *ParsingContext) ParseRule(expr string) (*Rule, error) {
 	rule := &Rule{}
@@ -141,9 +172,9 @@ type Expression struct {
 type Comparison struct {
 	Pos lexer.Position
 
-	BitOperation     *BitOperation     `parser:"@@"`
-	ScalarComparison *ScalarComparison `parser:"[ @@"`
-	ArrayComparison  *ArrayComparison  `parser:"| @@ ]"`
+	ArithmeticOperation *ArithmeticOperation `parser:"@@"`
+	ScalarComparison    *ScalarComparison    `parser:"[ @@"`
+	ArrayComparison     *ArrayComparison     `parser:"| @@ ]"`
 }
 
 // ScalarComparison describes a scalar comparison : the operator with the right operand
@@ -162,6 +193,22 @@ type ArrayComparison struct {
 	Array *Array  `parser:"@@ )"`
 }
 
+// ArithmeticOperation describes a chain of additions and subtractions, evaluated from left to right
+type ArithmeticOperation struct {
+	Pos lexer.Position
+
+	First *BitOperation        `parser:"@@"`
+	Rest  []*ArithmeticElement `parser:"{ @@ }"`
+}
+
+// ArithmeticElement describes an operator of an arithmetic operation with its right operand
+type ArithmeticElement struct {
+	Pos lexer.Position
+
+	Op      string        `parser:"@( \"+\" | \"-\" )"`
+	Operand *BitOperation `parser:"@@"`
+}
+
 // BitOperation describes an operation on bits
 type BitOperation struct {
 	Pos lexer.Position
@@ -185,7 +232,9 @@ type Unary struct {
 type Primary struct {
 	Pos lexer.Position
 
-	Ident         *string     `parser:"@Ident"`
+	Now           *string     `parser:"@\"now\" \"(\" \")\""`
+	RelativeTime  *int        `parser:"| @RelativeTime"`
+	Ident         *string     `parser:"| @Ident"`
 	CIDR          *string     `parser:"| @CIDR"`
 	IP            *string     `parser:"| @IP"`
 	Number        *int        `parser:"| @Int"`
@@ -220,6 +269,7 @@ type Array struct {
 
 	CIDR          *string        `parser:"@CIDR"`
 	Variable      *string        `parser:"| @Variable"`
+	CIDRSet       *string        `parser:"| \"cidr_set\" \"(\" @String \")\""`
 	Ident         *string        `parser:"| @Ident"`
 	StringMembers []StringMember `parser:"| \"[\" @@ { \",\" @@ } \"]\""`
 	CIDRMembers   []CIDRMember   `parser:"| \"[\" @@ { \",\" @@ } \"]\""`
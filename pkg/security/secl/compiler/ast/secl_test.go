@@ -8,6 +8,7 @@ package ast
 import (
 	"encoding/json"
 	"testing"
+	"time"
 )
 
 func parseRule(rule string) (*Rule, error) {
@@ -99,6 +100,52 @@ func TestIntAnd(t *testing.T) {
 	print(t, rule)
 }
 
+func TestArithmetic(t *testing.T) {
+	rule, err := parseRule(`process.created_at < now() - 5m + 30s`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	print(t, rule)
+
+	arithmetic := rule.BooleanExpression.Expression.Comparison.ArithmeticOperation
+	if arithmetic.First.Unary.Primary.Ident == nil || len(arithmetic.Rest) != 0 {
+		t.Fatal("unexpected left operand")
+	}
+
+	right := rule.BooleanExpression.Expression.Comparison.ScalarComparison.Next.ArithmeticOperation
+	if right.First.Unary.Primary.Now == nil {
+		t.Fatal("now() not parsed")
+	}
+	if len(right.Rest) != 2 || right.Rest[0].Op != "-" || right.Rest[1].Op != "+" {
+		t.Fatal("arithmetic operators not parsed")
+	}
+}
+
+func TestRelativeTime(t *testing.T) {
+	rule, err := parseRule(`process.created_at < now()-5m`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	print(t, rule)
+
+	right := rule.BooleanExpression.Expression.Comparison.ScalarComparison.Next.ArithmeticOperation
+	if right.First.Unary.Primary.RelativeTime == nil || *right.First.Unary.Primary.RelativeTime != -int(5*time.Minute) {
+		t.Fatal("relative time not parsed")
+	}
+
+	rule, err = parseRule(`process.created_at < now()+2d`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	right = rule.BooleanExpression.Expression.Comparison.ScalarComparison.Next.ArithmeticOperation
+	if right.First.Unary.Primary.RelativeTime == nil || *right.First.Unary.Primary.RelativeTime != int(48*time.Hour) {
+		t.Fatal("relative time not parsed")
+	}
+}
+
 func TestBoolAnd(t *testing.T) {
 	rule, err := parseRule(`true and true`)
 	if err != nil {
@@ -223,12 +270,21 @@ func TestArrayRegexp(t *testing.T) {
 }
 
 func TestDuration(t *testing.T) {
-	rule, err := parseRule(`process.start > 10s`)
-	if err != nil {
-		t.Error(err)
+	for _, expr := range []string{
+		`process.start > 10s`,
+		`process.start > 10ms`,
+		`process.start > 10m`,
+		`process.start > 10h`,
+		`process.start > 2d`,
+	} {
+		rule, err := parseRule(expr)
+		if err != nil {
+			t.Errorf("failed to parse `%s`: %s", expr, err)
+			continue
+		}
+
+		print(t, rule)
 	}
-
-	print(t, rule)
 }
 
 func TestNumberVariable(t *testing.T) {
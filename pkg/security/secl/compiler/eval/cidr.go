@@ -6,8 +6,11 @@
 package eval
 
 import (
+	"bufio"
 	"errors"
+	"fmt"
 	"net"
+	"os"
 	"strings"
 )
 
@@ -31,6 +34,37 @@ func IPNetFromIP(ip net.IP) *net.IPNet {
 	}
 }
 
+// cidrIndex indexes networks of the same address family by prefix length so that
+// a single IP address can be looked up with one map access per prefix length
+type cidrIndex struct {
+	prefixes []int
+	networks map[int]map[string]bool
+}
+
+func (ci *cidrIndex) add(ip net.IP, ones int) {
+	if ci.networks == nil {
+		ci.networks = make(map[int]map[string]bool)
+	}
+
+	networks, exists := ci.networks[ones]
+	if !exists {
+		networks = make(map[string]bool)
+		ci.networks[ones] = networks
+		ci.prefixes = append(ci.prefixes, ones)
+	}
+	networks[string(ip)] = true
+}
+
+func (ci *cidrIndex) contains(ip net.IP) bool {
+	bits := 8 * len(ip)
+	for _, ones := range ci.prefixes {
+		if ci.networks[ones][string(ip.Mask(net.CIDRMask(ones, bits)))] {
+			return true
+		}
+	}
+	return false
+}
+
 // CIDRValues describes a set of CIDRs
 type CIDRValues struct {
 	ipnets []*net.IPNet
@@ -39,6 +73,61 @@ type CIDRValues struct {
 	fieldValues []FieldValue
 
 	exists map[string]bool
+
+	// lookup structures used to match single IP addresses against large sets
+	ipv4Index cidrIndex
+	ipv6Index cidrIndex
+	unindexed []*net.IPNet
+}
+
+// normalizeIPNet returns the network number and the prefix length of the given
+// IPNet, with IPv4 networks using a 4 bytes representation
+func normalizeIPNet(ipnet *net.IPNet) (net.IP, int, bool) {
+	ip := ipnet.IP.To4()
+	if ip == nil {
+		if ip = ipnet.IP; len(ip) != net.IPv6len {
+			return nil, 0, false
+		}
+	}
+
+	mask := ipnet.Mask
+	switch len(mask) {
+	case net.IPv4len:
+		if len(ip) != net.IPv4len {
+			return nil, 0, false
+		}
+	case net.IPv6len:
+		if len(ip) == net.IPv4len {
+			mask = mask[12:]
+		}
+	default:
+		return nil, 0, false
+	}
+
+	ones, bits := mask.Size()
+	if bits == 0 {
+		return nil, 0, false
+	}
+
+	return ip.Mask(mask), ones, true
+}
+
+func (c *CIDRValues) appendIPNet(value string, ipnet *net.IPNet) {
+	c.ipnets = append(c.ipnets, ipnet)
+	c.fieldValues = append(c.fieldValues, FieldValue{Type: IPNetValueType, Value: *ipnet})
+
+	if ip, ones, ok := normalizeIPNet(ipnet); !ok {
+		c.unindexed = append(c.unindexed, ipnet)
+	} else if len(ip) == net.IPv4len {
+		c.ipv4Index.add(ip, ones)
+	} else {
+		c.ipv6Index.add(ip, ones)
+	}
+
+	if c.exists == nil {
+		c.exists = make(map[string]bool)
+	}
+	c.exists[value] = true
 }
 
 // AppendCIDR append a CIDR notation
@@ -52,13 +141,7 @@ func (c *CIDRValues) AppendCIDR(cidr string) error {
 		return err
 	}
 
-	c.ipnets = append(c.ipnets, ipnet)
-	c.fieldValues = append(c.fieldValues, FieldValue{Type: IPNetValueType, Value: *ipnet})
-
-	if c.exists == nil {
-		c.exists = make(map[string]bool)
-	}
-	c.exists[cidr] = true
+	c.appendIPNet(cidr, ipnet)
 
 	return nil
 }
@@ -100,19 +183,67 @@ func (c *CIDRValues) AppendIP(ip string) error {
 		return err
 	}
 
-	c.ipnets = append(c.ipnets, ipnet)
-	c.fieldValues = append(c.fieldValues, FieldValue{Type: IPNetValueType, Value: *ipnet})
+	c.appendIPNet(ip, ipnet)
 
-	if c.exists == nil {
-		c.exists = make(map[string]bool)
+	return nil
+}
+
+// AppendFile appends the IPs and CIDRs listed in the given file, one per line. Empty lines
+// and lines starting with '#' are ignored
+func (c *CIDRValues) AppendFile(filename string) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
 	}
-	c.exists[ip] = true
+	defer f.Close()
 
-	return nil
+	var lineNumber int
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lineNumber++
+
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.Contains(line, "/") {
+			err = c.AppendCIDR(line)
+		} else {
+			err = c.AppendIP(line)
+		}
+		if err != nil {
+			// the line isn't reported, the error being logged while the file may not be a list of IPs
+			return fmt.Errorf("invalid IP or CIDR at line %d", lineNumber)
+		}
+	}
+
+	return scanner.Err()
 }
 
 // Contains returns whether the values match the provided IPNet
 func (c *CIDRValues) Contains(ipnet *net.IPNet) bool {
+	// a single IP address can only match networks containing it, use the indexes
+	if ip, ones, ok := normalizeIPNet(ipnet); ok && ones == 8*len(ip) {
+		index := &c.ipv4Index
+		if len(ip) == net.IPv6len {
+			index = &c.ipv6Index
+		}
+
+		if index.contains(ip) {
+			return true
+		}
+
+		for _, n := range c.unindexed {
+			if IPNetsMatch(n, ipnet) {
+				return true
+			}
+		}
+
+		return false
+	}
+
 	for _, n := range c.ipnets {
 		if IPNetsMatch(n, ipnet) {
 			return true
@@ -124,11 +255,9 @@ func (c *CIDRValues) Contains(ipnet *net.IPNet) bool {
 
 // Match returns whether the values matches the provided IPNets
 func (c *CIDRValues) Match(ipnets []net.IPNet) bool {
-	for _, n := range c.ipnets {
-		for _, ipnet := range ipnets {
-			if IPNetsMatch(n, &ipnet) {
-				return true
-			}
+	for _, ipnet := range ipnets {
+		if c.Contains(&ipnet) {
+			return true
 		}
 	}
 
@@ -117,6 +117,16 @@ func (e ErrFieldNotFound) Error() string {
 	return fmt.Sprintf("field `%s` not found", e.Field)
 }
 
+// ErrFieldNotSupportedOnPlatform error when a field of the model isn't available on the current platform
+type ErrFieldNotSupportedOnPlatform struct {
+	Field    string
+	Platform string
+}
+
+func (e ErrFieldNotSupportedOnPlatform) Error() string {
+	return fmt.Sprintf("field `%s` not supported on %s", e.Field, e.Platform)
+}
+
 // ErrIteratorNotSupported error when a field doesn't support iteration
 type ErrIteratorNotSupported struct {
 	Field string
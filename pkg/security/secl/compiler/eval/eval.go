@@ -10,6 +10,7 @@ package eval
 import (
 	"errors"
 	"fmt"
+	"path/filepath"
 	"reflect"
 	"regexp"
 	"strconv"
@@ -152,6 +153,24 @@ func identToEvaluator(obj *ident, opts *Opts, state *State) (interface{}, lexer.
 	return accessor, obj.Pos, nil
 }
 
+// cidrSetPath returns the path of a cidr_set file, which must be relative to the cidr sets directory
+// and can't point outside of it, so that a policy can't read arbitrary files.
+func cidrSetPath(dir string, name string) (string, error) {
+	if dir == "" {
+		return "", errors.New("no directory to load the cidr_set files from")
+	}
+	if filepath.IsAbs(name) {
+		return "", errors.New("the path must be relative to the cidr_set directory")
+	}
+
+	filename := filepath.Join(dir, name)
+	rel, err := filepath.Rel(dir, filename)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", errors.New("the path must be within the cidr_set directory")
+	}
+	return filename, nil
+}
+
 func arrayToEvaluator(array *ast.Array, opts *Opts, state *State) (interface{}, lexer.Position, error) {
 	if len(array.Numbers) != 0 {
 		var evaluator IntArrayEvaluator
@@ -176,6 +195,22 @@ func arrayToEvaluator(array *ast.Array, opts *Opts, state *State) (interface{},
 			return nil, array.Pos, NewError(array.Pos, "invalid variable name '%s'", *array.Variable)
 		}
 		return evaluatorFromVariable(varName, array.Pos, opts)
+	} else if array.CIDRSet != nil {
+		filename, err := cidrSetPath(opts.CIDRSetsDir, *array.CIDRSet)
+		if err != nil {
+			return nil, array.Pos, NewError(array.Pos, "invalid cidr_set '%s': %s", *array.CIDRSet, err)
+		}
+
+		var values CIDRValues
+		if err := values.AppendFile(filename); err != nil {
+			return nil, array.Pos, NewError(array.Pos, "invalid cidr_set '%s': %s", *array.CIDRSet, err)
+		}
+
+		evaluator := &CIDRValuesEvaluator{
+			Value:     values,
+			ValueType: IPNetValueType,
+		}
+		return evaluator, array.Pos, nil
 	} else if array.CIDR != nil {
 		var values CIDRValues
 		if err := values.AppendCIDR(*array.CIDR); err != nil {
@@ -473,8 +508,41 @@ func nodeToEvaluator(obj interface{}, opts *Opts, state *State) (interface{}, le
 		}
 		return unary, obj.Pos, nil
 
+	case *ast.ArithmeticOperation:
+		unary, pos, err = nodeToEvaluator(obj.First, opts, state)
+		if err != nil {
+			return nil, pos, err
+		}
+
+		for _, element := range obj.Rest {
+			leftInt, ok := unary.(*IntEvaluator)
+			if !ok {
+				return nil, obj.Pos, NewTypeError(obj.Pos, reflect.Int)
+			}
+
+			next, pos, err = nodeToEvaluator(element.Operand, opts, state)
+			if err != nil {
+				return nil, pos, err
+			}
+
+			nextInt, ok := next.(*IntEvaluator)
+			if !ok {
+				return nil, pos, NewTypeError(pos, reflect.Int)
+			}
+
+			switch element.Op {
+			case "+":
+				unary = IntPlus(leftInt, nextInt, state)
+			case "-":
+				unary = IntMinus(leftInt, nextInt, state)
+			default:
+				return nil, element.Pos, NewOpUnknownError(element.Pos, element.Op)
+			}
+		}
+		return unary, obj.Pos, nil
+
 	case *ast.Comparison:
-		unary, pos, err = nodeToEvaluator(obj.BitOperation, opts, state)
+		unary, pos, err = nodeToEvaluator(obj.ArithmeticOperation, opts, state)
 		if err != nil {
 			return nil, pos, err
 		}
@@ -1115,6 +1183,19 @@ func nodeToEvaluator(obj interface{}, opts *Opts, state *State) (interface{}, le
 		return nodeToEvaluator(obj.Primary, opts, state)
 	case *ast.Primary:
 		switch {
+		case obj.Now != nil:
+			return &IntEvaluator{
+				EvalFnc: func(ctx *Context) int {
+					return int(ctx.Now().UnixNano())
+				},
+			}, obj.Pos, nil
+		case obj.RelativeTime != nil:
+			offset := *obj.RelativeTime
+			return &IntEvaluator{
+				EvalFnc: func(ctx *Context) int {
+					return int(ctx.Now().UnixNano()) + offset
+				},
+			}, obj.Pos, nil
 		case obj.Ident != nil:
 			return identToEvaluator(&ident{Pos: obj.Pos, Ident: obj.Ident}, opts, state)
 		case obj.Number != nil:
@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"net"
 	"os"
+	"path/filepath"
 	"runtime"
 	"strings"
 	"syscall"
@@ -1054,6 +1055,98 @@ func TestDuration(t *testing.T) {
 	}
 }
 
+func TestArithmetic(t *testing.T) {
+	event := &testEvent{
+		process: testProcess{
+			uid:       44,
+			createdAt: time.Now().Add(-10 * time.Minute).UnixNano(),
+		},
+	}
+
+	tests := []struct {
+		Expr     string
+		Expected bool
+	}{
+		{Expr: `1 + 2 == 3`, Expected: true},
+		{Expr: `10 - 2 - 3 == 5`, Expected: true},
+		{Expr: `process.uid + 1 == 45`, Expected: true},
+		{Expr: `process.uid - 4 == 40`, Expected: true},
+		{Expr: `45 == process.uid + 1`, Expected: true},
+		{Expr: `process.uid + process.uid == 88`, Expected: true},
+		{Expr: `process.uid - 1 in [43, 44]`, Expected: true},
+		{Expr: `process.created_at < now() - 5m`, Expected: true},
+		{Expr: `process.created_at < now() - 15m`, Expected: false},
+		{Expr: `process.created_at > now() - 1h`, Expected: true},
+		{Expr: `process.created_at < now()-5m`, Expected: true},
+		{Expr: `process.created_at < now()-15m`, Expected: false},
+		{Expr: `process.created_at < now()+1s`, Expected: true},
+		// the sum of two durations is still a duration, compared to the age of the process
+		{Expr: `process.created_at > 5m + 4m`, Expected: true},
+		{Expr: `process.created_at > 5m + 6m`, Expected: false},
+	}
+
+	for _, test := range tests {
+		result, _, err := eval(t, event, test.Expr)
+		if err != nil {
+			t.Fatalf("error while evaluating `%s`: %s", test.Expr, err)
+		}
+
+		if result != test.Expected {
+			t.Errorf("expected result `%t` not found, got `%t`\n%s", test.Expected, result, test.Expr)
+		}
+	}
+
+	for _, expr := range []string{
+		`process.name + 1 == 2`,
+		`process.uid + "a" == 2`,
+	} {
+		if _, _, err := eval(t, event, expr); err == nil {
+			t.Errorf("expected a type error for `%s`", expr)
+		}
+	}
+}
+
+func TestMutableRateVariable(t *testing.T) {
+	now := time.Now()
+	ctx := &Context{now: now}
+
+	variable := NewMutableRateVariable(time.Minute)
+	evaluator := variable.GetEvaluator().(*IntEvaluator)
+
+	for i := 0; i < 3; i++ {
+		ctx.now = now.Add(time.Duration(i) * 20 * time.Second)
+		if err := variable.Append(ctx, 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, test := range []struct {
+		Offset   time.Duration
+		Expected int
+	}{
+		{Offset: 40 * time.Second, Expected: 3},
+		// the first value is out of the window
+		{Offset: time.Minute, Expected: 2},
+		{Offset: 2 * time.Minute, Expected: 0},
+	} {
+		ctx.now = now.Add(test.Offset)
+		if result := evaluator.Eval(ctx); result != test.Expected {
+			t.Errorf("expected `%d` after %s, got `%d`", test.Expected, test.Offset, result)
+		}
+	}
+
+	if err := variable.Set(ctx, 5); err != nil {
+		t.Fatal(err)
+	}
+	if result := evaluator.Eval(ctx); result != 5 {
+		t.Errorf("expected `5` after set, got `%d`", result)
+	}
+
+	if err := variable.Append(ctx, "value"); err == nil {
+		t.Error("expected an error when appending a string")
+	}
+}
+
 func parseCIDR(t *testing.T, ip string) net.IPNet {
 	ipnet, err := ParseCIDR(ip)
 	if err != nil {
@@ -1213,6 +1306,95 @@ func TestIPv6(t *testing.T) {
 	}
 }
 
+func TestCIDRSet(t *testing.T) {
+	dir := t.TempDir()
+
+	content := `# allowed networks
+10.0.0.0/8
+192.168.0.1
+
+2001:0:0eab:dead::/64
+::ffff:172.16.0.0/112
+`
+	if err := os.WriteFile(filepath.Join(dir, "allowed.txt"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "invalid.txt"), []byte("10.0.0.0/8\nnot_an_ip\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "sub", "nested.txt"), []byte("10.0.0.0/8\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		IP       string
+		Expr     string
+		Expected bool
+	}{
+		{IP: "192.168.0.1", Expr: `network.ip in cidr_set("allowed.txt")`, Expected: true},
+		{IP: "192.168.0.2", Expr: `network.ip in cidr_set("allowed.txt")`, Expected: false},
+		{IP: "10.2.3.4", Expr: `network.ip in cidr_set("allowed.txt")`, Expected: true},
+		{IP: "11.2.3.4", Expr: `network.ip not in cidr_set("allowed.txt")`, Expected: true},
+		{IP: "172.16.1.1", Expr: `network.ip in cidr_set("allowed.txt")`, Expected: true},
+		{IP: "172.17.1.1", Expr: `network.ip in cidr_set("allowed.txt")`, Expected: false},
+		{IP: "2001:0:0eab:dead::a0:abcd:4e", Expr: `network.ip in cidr_set("allowed.txt")`, Expected: true},
+		{IP: "2001:0:0eab:beef::a0:abcd:4e", Expr: `network.ip in cidr_set("allowed.txt")`, Expected: false},
+		{IP: "10.2.3.4", Expr: `network.ip in cidr_set("sub/../allowed.txt")`, Expected: true},
+		{IP: "10.2.3.4", Expr: `network.ip in cidr_set("sub/nested.txt")`, Expected: true},
+		{IP: "10.2.3.4", Expr: `network.ips in cidr_set("allowed.txt")`, Expected: true},
+	}
+
+	model := &testModel{}
+	opts := newOptsWithParams(testConstants, nil).WithCIDRSetsDir(dir)
+
+	for _, test := range tests {
+		event := &testEvent{
+			network: testNetwork{
+				ip:  parseCIDR(t, test.IP),
+				ips: []net.IPNet{parseCIDR(t, "127.0.0.1"), parseCIDR(t, test.IP)},
+			},
+		}
+
+		rule, err := parseRule(test.Expr, model, opts)
+		if err != nil {
+			t.Fatalf("error while evaluating `%s`: %s", test.Expr, err)
+		}
+
+		if result := rule.Eval(NewContext(event)); result != test.Expected {
+			t.Errorf("expected result `%v` not found, got `%v`, expression: %s, ip: %s", test.Expected, result, test.Expr, test.IP)
+		}
+	}
+
+	for _, expr := range []string{
+		`network.ip in cidr_set("invalid.txt")`,
+		`network.ip in cidr_set("missing.txt")`,
+		fmt.Sprintf(`network.ip in cidr_set("%s")`, filepath.Join(dir, "allowed.txt")),
+		`network.ip in cidr_set("../allowed.txt")`,
+		`network.ip in cidr_set("sub/../../allowed.txt")`,
+		`network.ip in cidr_set(".")`,
+	} {
+		if _, err := parseRule(expr, model, opts); err == nil {
+			t.Errorf("expected an error for `%s`", expr)
+		}
+	}
+
+	// the content of the invalid lines isn't leaked in the error
+	_, err := parseRule(`network.ip in cidr_set("invalid.txt")`, model, opts)
+	if err == nil || strings.Contains(err.Error(), "not_an_ip") || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// relative paths can't be resolved without a cidr_set directory
+	if _, err := parseRule(`network.ip in cidr_set("allowed.txt")`, model, newOptsWithParams(testConstants, nil)); err == nil {
+		t.Error("expected an error without a cidr_set directory")
+	}
+}
+
 func TestOpOverrides(t *testing.T) {
 	event := &testEvent{
 		process: testProcess{
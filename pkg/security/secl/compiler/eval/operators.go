@@ -283,6 +283,50 @@ func IntNot(a *IntEvaluator, state *State) *IntEvaluator {
 	}
 }
 
+// IntPlus + operator. The result is a duration only if both operands are durations, e.g. `5m + 30s`,
+// a timestamp plus a duration being a timestamp.
+func IntPlus(a *IntEvaluator, b *IntEvaluator, state *State) *IntEvaluator {
+	return intArithmetic(a, b, state, func(a, b int) int { return a + b })
+}
+
+// IntMinus - operator, e.g. `now() - 5m`. The result is a duration only if both operands are durations.
+func IntMinus(a *IntEvaluator, b *IntEvaluator, state *State) *IntEvaluator {
+	return intArithmetic(a, b, state, func(a, b int) int { return a - b })
+}
+
+// intArithmetic doesn't propagate the fields of its operands, as their values can't be used as approvers
+func intArithmetic(a *IntEvaluator, b *IntEvaluator, state *State, op func(a, b int) int) *IntEvaluator {
+	isDc := isArithmDeterministic(a, b, state)
+	isDuration := a.isDuration && b.isDuration
+
+	if a.EvalFnc == nil && b.EvalFnc == nil {
+		return &IntEvaluator{
+			Value:           op(a.Value, b.Value),
+			isDeterministic: isDc,
+			isDuration:      isDuration,
+		}
+	}
+
+	ea, eb := a.EvalFnc, b.EvalFnc
+	if ea == nil {
+		va := a.Value
+		ea = func(ctx *Context) int { return va }
+	}
+	if eb == nil {
+		vb := b.Value
+		eb = func(ctx *Context) int { return vb }
+	}
+
+	return &IntEvaluator{
+		EvalFnc: func(ctx *Context) int {
+			return op(ea(ctx), eb(ctx))
+		},
+		Weight:          a.Weight + b.Weight,
+		isDeterministic: isDc,
+		isDuration:      isDuration,
+	}
+}
+
 // StringEquals evaluates string
 func StringEquals(a *StringEvaluator, b *StringEvaluator, state *State) (*BoolEvaluator, error) {
 	isDc := isArithmDeterministic(a, b, state)
@@ -68,6 +68,7 @@ type Opts struct {
 	Constants     map[string]interface{}
 	VariableStore *VariableStore
 	MacroStore    *MacroStore
+	CIDRSetsDir   string
 }
 
 // WithConstants set constants
@@ -106,6 +107,12 @@ func (o *Opts) WithMacroStore(store *MacroStore) *Opts {
 	return o
 }
 
+// WithCIDRSetsDir set the directory used to resolve relative cidr_set file paths
+func (o *Opts) WithCIDRSetsDir(dir string) *Opts {
+	o.CIDRSetsDir = dir
+	return o
+}
+
 // AddMacro add a macro
 func (o *Opts) AddMacro(macro *Macro) *Opts {
 	if o.MacroStore == nil {
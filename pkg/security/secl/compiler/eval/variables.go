@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"reflect"
 	"regexp"
+	"time"
 )
 
 var (
@@ -231,6 +232,70 @@ func NewMutableIntVariable() *MutableIntVariable {
 	return &MutableIntVariable{}
 }
 
+// MutableRateVariable describes a mutable integer variable evaluating to the sum of the values
+// appended during the last window, e.g. the number of times a rule matched during the last minute
+type MutableRateVariable struct {
+	window time.Duration
+	hits   []rateHit
+}
+
+type rateHit struct {
+	timestamp time.Time
+	value     int
+}
+
+// expire removes the values appended before the window
+func (m *MutableRateVariable) expire(now time.Time) {
+	start := now.Add(-m.window)
+
+	i := 0
+	for i < len(m.hits) && !m.hits[i].timestamp.After(start) {
+		i++
+	}
+	m.hits = m.hits[i:]
+}
+
+// Set the variable with the specified value, discarding the values previously appended
+func (m *MutableRateVariable) Set(ctx *Context, value interface{}) error {
+	i, ok := value.(int)
+	if !ok {
+		return fmt.Errorf("unsupported value type: %s", reflect.TypeOf(value))
+	}
+	m.hits = append(m.hits[:0], rateHit{timestamp: ctx.Now(), value: i})
+	return nil
+}
+
+// Append a value to the current window
+func (m *MutableRateVariable) Append(ctx *Context, value interface{}) error {
+	i, ok := value.(int)
+	if !ok {
+		return errAppendNotSupported
+	}
+	m.expire(ctx.Now())
+	m.hits = append(m.hits, rateHit{timestamp: ctx.Now(), value: i})
+	return nil
+}
+
+// GetEvaluator returns the variable SECL evaluator
+func (m *MutableRateVariable) GetEvaluator() interface{} {
+	return &IntEvaluator{
+		EvalFnc: func(ctx *Context) int {
+			m.expire(ctx.Now())
+
+			var sum int
+			for _, hit := range m.hits {
+				sum += hit.value
+			}
+			return sum
+		},
+	}
+}
+
+// NewMutableRateVariable returns a new mutable rate variable summing the values appended during the specified window
+func NewMutableRateVariable(window time.Duration) *MutableRateVariable {
+	return &MutableRateVariable{window: window}
+}
+
 // MutableBoolVariable describes a mutable boolean variable
 type MutableBoolVariable struct {
 	Value bool
@@ -337,6 +337,31 @@ func parseFieldDef(def string) (seclField, error) {
 	return field, nil
 }
 
+// handleUnsupportedPlatform records the SECL fields of a struct field which isn't available on the platform of the module
+func handleUnsupportedPlatform(module *common.Module, tag reflect.StructTag, aliasPrefix string) {
+	fieldTag, found := tag.Lookup("field")
+	if !found {
+		return
+	}
+
+	for _, fieldDef := range strings.Split(fieldTag, ";") {
+		field, err := parseFieldDef(fieldDef)
+		if err != nil {
+			log.Panicf("unable to parse field definition: %s", err)
+		}
+
+		if field.name == "-" {
+			return
+		}
+
+		name := field.name
+		if aliasPrefix != "" {
+			name = aliasPrefix + "." + name
+		}
+		module.UnsupportedFields[name] = true
+	}
+}
+
 // handleSpecRecursive is a recursive function that walks through the fields of a module
 func handleSpecRecursive(module *common.Module, astFile *ast.File, spec interface{}, prefix, aliasPrefix, event string, iterator *common.StructField, dejavu map[string]bool) {
 	if verbose {
@@ -367,6 +392,7 @@ func handleSpecRecursive(module *common.Module, astFile *ast.File, spec interfac
 			platform := common.Platform(p)
 			compatiblePlatform := platform == common.Unspecified || platform == module.Platform
 			if !compatiblePlatform {
+				handleUnsupportedPlatform(module, tag, aliasPrefix)
 				continue
 			}
 		}
@@ -565,6 +591,7 @@ func parseFile(filename string, pkgName string) (*common.Module, error) {
 		Iterators:              make(map[string]*common.StructField),
 		EventTypes:             make(map[string]*common.EventTypeMetadata),
 		Platform:               common.Unspecified,
+		UnsupportedFields:      make(map[string]bool),
 	}
 
 	if strings.Contains(buildTags, "linux") || strings.Contains(buildTags, "unix") {
@@ -41,6 +41,8 @@ type Module struct {
 	EventTypes             map[string]*EventTypeMetadata
 	Mock                   bool
 	Platform               Platform
+	// UnsupportedFields holds the fields, and the prefixes of the fields, which are only available on other platforms
+	UnsupportedFields map[string]bool
 }
 
 // StructField represents a structure field for which an accessor will be generated
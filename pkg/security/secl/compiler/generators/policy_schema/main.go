@@ -0,0 +1,29 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package main
+
+import (
+	"flag"
+	"os"
+
+	"github.com/DataDog/datadog-agent/pkg/security/secl/rules"
+)
+
+func main() {
+	var output string
+
+	flag.StringVar(&output, "output", "", "Policy JSON schema generated file")
+	flag.Parse()
+
+	schema, err := rules.PolicySchema()
+	if err != nil {
+		panic(err)
+	}
+
+	if err := os.WriteFile(output, append(schema, '\n'), 0664); err != nil {
+		panic(err)
+	}
+}
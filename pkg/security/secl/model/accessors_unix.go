@@ -13,6 +13,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/security/secl/compiler/eval"
 	"net"
 	"reflect"
+	"strings"
 )
 
 func (m *Model) GetIterator(field eval.Field) (eval.Iterator, error) {
@@ -941,6 +942,15 @@ func (m *Model) GetEvaluator(field eval.Field, regID eval.RegisterID) (eval.Eval
 			Field:  field,
 			Weight: eval.HandlerWeight,
 		}, nil
+	case "exec.file.hash":
+		return &eval.StringEvaluator{
+			EvalFnc: func(ctx *eval.Context) string {
+				ev := ctx.Event.(*Event)
+				return ev.FieldHandlers.ResolveProcessFileHash(ev, ev.Exec.Process)
+			},
+			Field:  field,
+			Weight: eval.HandlerWeight,
+		}, nil
 	case "exec.file.in_upper_layer":
 		return &eval.BoolEvaluator{
 			EvalFnc: func(ctx *eval.Context) bool {
@@ -1699,6 +1709,15 @@ func (m *Model) GetEvaluator(field eval.Field, regID eval.RegisterID) (eval.Eval
 			Field:  field,
 			Weight: eval.HandlerWeight,
 		}, nil
+	case "exit.file.hash":
+		return &eval.StringEvaluator{
+			EvalFnc: func(ctx *eval.Context) string {
+				ev := ctx.Event.(*Event)
+				return ev.FieldHandlers.ResolveProcessFileHash(ev, ev.Exit.Process)
+			},
+			Field:  field,
+			Weight: eval.HandlerWeight,
+		}, nil
 	case "exit.file.in_upper_layer":
 		return &eval.BoolEvaluator{
 			EvalFnc: func(ctx *eval.Context) bool {
@@ -4039,6 +4058,27 @@ func (m *Model) GetEvaluator(field eval.Field, regID eval.RegisterID) (eval.Eval
 			}, Field: field,
 			Weight: eval.IteratorWeight,
 		}, nil
+	case "process.ancestors.file.hash":
+		return &eval.StringArrayEvaluator{
+			EvalFnc: func(ctx *eval.Context) []string {
+				ev := ctx.Event.(*Event)
+				if result, ok := ctx.StringCache[field]; ok {
+					return result
+				}
+				var results []string
+				iterator := &ProcessAncestorsIterator{}
+				value := iterator.Front(ctx)
+				for value != nil {
+					element := (*ProcessCacheEntry)(value)
+					result := ev.FieldHandlers.ResolveProcessFileHash(ev, &element.ProcessContext.Process)
+					results = append(results, result)
+					value = iterator.Next()
+				}
+				ctx.StringCache[field] = results
+				return results
+			}, Field: field,
+			Weight: eval.IteratorWeight,
+		}, nil
 	case "process.ancestors.file.in_upper_layer":
 		return &eval.BoolArrayEvaluator{
 			EvalFnc: func(ctx *eval.Context) []bool {
@@ -5389,6 +5429,15 @@ func (m *Model) GetEvaluator(field eval.Field, regID eval.RegisterID) (eval.Eval
 			Field:  field,
 			Weight: eval.HandlerWeight,
 		}, nil
+	case "process.file.hash":
+		return &eval.StringEvaluator{
+			EvalFnc: func(ctx *eval.Context) string {
+				ev := ctx.Event.(*Event)
+				return ev.FieldHandlers.ResolveProcessFileHash(ev, &ev.ProcessContext.Process)
+			},
+			Field:  field,
+			Weight: eval.HandlerWeight,
+		}, nil
 	case "process.file.in_upper_layer":
 		return &eval.BoolEvaluator{
 			EvalFnc: func(ctx *eval.Context) bool {
@@ -6141,6 +6190,18 @@ func (m *Model) GetEvaluator(field eval.Field, regID eval.RegisterID) (eval.Eval
 			Field:  field,
 			Weight: eval.HandlerWeight,
 		}, nil
+	case "process.parent.file.hash":
+		return &eval.StringEvaluator{
+			EvalFnc: func(ctx *eval.Context) string {
+				ev := ctx.Event.(*Event)
+				if !ev.ProcessContext.HasParent() {
+					return ""
+				}
+				return ev.FieldHandlers.ResolveProcessFileHash(ev, ev.ProcessContext.Parent)
+			},
+			Field:  field,
+			Weight: eval.HandlerWeight,
+		}, nil
 	case "process.parent.file.in_upper_layer":
 		return &eval.BoolEvaluator{
 			EvalFnc: func(ctx *eval.Context) bool {
@@ -7347,6 +7408,27 @@ func (m *Model) GetEvaluator(field eval.Field, regID eval.RegisterID) (eval.Eval
 			}, Field: field,
 			Weight: eval.IteratorWeight,
 		}, nil
+	case "ptrace.tracee.ancestors.file.hash":
+		return &eval.StringArrayEvaluator{
+			EvalFnc: func(ctx *eval.Context) []string {
+				ev := ctx.Event.(*Event)
+				if result, ok := ctx.StringCache[field]; ok {
+					return result
+				}
+				var results []string
+				iterator := &ProcessAncestorsIterator{}
+				value := iterator.Front(ctx)
+				for value != nil {
+					element := (*ProcessCacheEntry)(value)
+					result := ev.FieldHandlers.ResolveProcessFileHash(ev, &element.ProcessContext.Process)
+					results = append(results, result)
+					value = iterator.Next()
+				}
+				ctx.StringCache[field] = results
+				return results
+			}, Field: field,
+			Weight: eval.IteratorWeight,
+		}, nil
 	case "ptrace.tracee.ancestors.file.in_upper_layer":
 		return &eval.BoolArrayEvaluator{
 			EvalFnc: func(ctx *eval.Context) []bool {
@@ -8697,6 +8779,15 @@ func (m *Model) GetEvaluator(field eval.Field, regID eval.RegisterID) (eval.Eval
 			Field:  field,
 			Weight: eval.HandlerWeight,
 		}, nil
+	case "ptrace.tracee.file.hash":
+		return &eval.StringEvaluator{
+			EvalFnc: func(ctx *eval.Context) string {
+				ev := ctx.Event.(*Event)
+				return ev.FieldHandlers.ResolveProcessFileHash(ev, &ev.PTrace.Tracee.Process)
+			},
+			Field:  field,
+			Weight: eval.HandlerWeight,
+		}, nil
 	case "ptrace.tracee.file.in_upper_layer":
 		return &eval.BoolEvaluator{
 			EvalFnc: func(ctx *eval.Context) bool {
@@ -9449,6 +9540,18 @@ func (m *Model) GetEvaluator(field eval.Field, regID eval.RegisterID) (eval.Eval
 			Field:  field,
 			Weight: eval.HandlerWeight,
 		}, nil
+	case "ptrace.tracee.parent.file.hash":
+		return &eval.StringEvaluator{
+			EvalFnc: func(ctx *eval.Context) string {
+				ev := ctx.Event.(*Event)
+				if !ev.PTrace.Tracee.HasParent() {
+					return ""
+				}
+				return ev.FieldHandlers.ResolveProcessFileHash(ev, ev.PTrace.Tracee.Parent)
+			},
+			Field:  field,
+			Weight: eval.HandlerWeight,
+		}, nil
 	case "ptrace.tracee.parent.file.in_upper_layer":
 		return &eval.BoolEvaluator{
 			EvalFnc: func(ctx *eval.Context) bool {
@@ -11746,6 +11849,27 @@ func (m *Model) GetEvaluator(field eval.Field, regID eval.RegisterID) (eval.Eval
 			}, Field: field,
 			Weight: eval.IteratorWeight,
 		}, nil
+	case "signal.target.ancestors.file.hash":
+		return &eval.StringArrayEvaluator{
+			EvalFnc: func(ctx *eval.Context) []string {
+				ev := ctx.Event.(*Event)
+				if result, ok := ctx.StringCache[field]; ok {
+					return result
+				}
+				var results []string
+				iterator := &ProcessAncestorsIterator{}
+				value := iterator.Front(ctx)
+				for value != nil {
+					element := (*ProcessCacheEntry)(value)
+					result := ev.FieldHandlers.ResolveProcessFileHash(ev, &element.ProcessContext.Process)
+					results = append(results, result)
+					value = iterator.Next()
+				}
+				ctx.StringCache[field] = results
+				return results
+			}, Field: field,
+			Weight: eval.IteratorWeight,
+		}, nil
 	case "signal.target.ancestors.file.in_upper_layer":
 		return &eval.BoolArrayEvaluator{
 			EvalFnc: func(ctx *eval.Context) []bool {
@@ -13096,6 +13220,15 @@ func (m *Model) GetEvaluator(field eval.Field, regID eval.RegisterID) (eval.Eval
 			Field:  field,
 			Weight: eval.HandlerWeight,
 		}, nil
+	case "signal.target.file.hash":
+		return &eval.StringEvaluator{
+			EvalFnc: func(ctx *eval.Context) string {
+				ev := ctx.Event.(*Event)
+				return ev.FieldHandlers.ResolveProcessFileHash(ev, &ev.Signal.Target.Process)
+			},
+			Field:  field,
+			Weight: eval.HandlerWeight,
+		}, nil
 	case "signal.target.file.in_upper_layer":
 		return &eval.BoolEvaluator{
 			EvalFnc: func(ctx *eval.Context) bool {
@@ -13848,6 +13981,18 @@ func (m *Model) GetEvaluator(field eval.Field, regID eval.RegisterID) (eval.Eval
 			Field:  field,
 			Weight: eval.HandlerWeight,
 		}, nil
+	case "signal.target.parent.file.hash":
+		return &eval.StringEvaluator{
+			EvalFnc: func(ctx *eval.Context) string {
+				ev := ctx.Event.(*Event)
+				if !ev.Signal.Target.HasParent() {
+					return ""
+				}
+				return ev.FieldHandlers.ResolveProcessFileHash(ev, ev.Signal.Target.Parent)
+			},
+			Field:  field,
+			Weight: eval.HandlerWeight,
+		}, nil
 	case "signal.target.parent.file.in_upper_layer":
 		return &eval.BoolEvaluator{
 			EvalFnc: func(ctx *eval.Context) bool {
@@ -15171,8 +15316,29 @@ func (m *Model) GetEvaluator(field eval.Field, regID eval.RegisterID) (eval.Eval
 			Weight: eval.FunctionWeight,
 		}, nil
 	}
+	if isUnsupportedField(field) {
+		return nil, &eval.ErrFieldNotSupportedOnPlatform{Field: field, Platform: "linux"}
+	}
 	return nil, &eval.ErrFieldNotFound{Field: field}
 }
+
+// isUnsupportedField returns whether the field is only available on other platforms
+func isUnsupportedField(field eval.Field) bool {
+	for _, unsupported := range []string{
+		"create",
+		"create_key",
+		"delete",
+		"delete_key",
+		"open_key",
+		"set_key_value",
+		"write",
+	} {
+		if field == unsupported || strings.HasPrefix(field, unsupported+".") {
+			return true
+		}
+	}
+	return false
+}
 func (ev *Event) GetFields() []eval.Field {
 	return []eval.Field{
 		"bind.addr.family",
@@ -15270,6 +15436,7 @@ func (ev *Event) GetFields() []eval.Field {
 		"exec.file.filesystem",
 		"exec.file.gid",
 		"exec.file.group",
+		"exec.file.hash",
 		"exec.file.in_upper_layer",
 		"exec.file.inode",
 		"exec.file.mode",
@@ -15342,6 +15509,7 @@ func (ev *Event) GetFields() []eval.Field {
 		"exit.file.filesystem",
 		"exit.file.gid",
 		"exit.file.group",
+		"exit.file.hash",
 		"exit.file.in_upper_layer",
 		"exit.file.inode",
 		"exit.file.mode",
@@ -15558,6 +15726,7 @@ func (ev *Event) GetFields() []eval.Field {
 		"process.ancestors.file.filesystem",
 		"process.ancestors.file.gid",
 		"process.ancestors.file.group",
+		"process.ancestors.file.hash",
 		"process.ancestors.file.in_upper_layer",
 		"process.ancestors.file.inode",
 		"process.ancestors.file.mode",
@@ -15628,6 +15797,7 @@ func (ev *Event) GetFields() []eval.Field {
 		"process.file.filesystem",
 		"process.file.gid",
 		"process.file.group",
+		"process.file.hash",
 		"process.file.in_upper_layer",
 		"process.file.inode",
 		"process.file.mode",
@@ -15692,6 +15862,7 @@ func (ev *Event) GetFields() []eval.Field {
 		"process.parent.file.filesystem",
 		"process.parent.file.gid",
 		"process.parent.file.group",
+		"process.parent.file.hash",
 		"process.parent.file.in_upper_layer",
 		"process.parent.file.inode",
 		"process.parent.file.mode",
@@ -15770,6 +15941,7 @@ func (ev *Event) GetFields() []eval.Field {
 		"ptrace.tracee.ancestors.file.filesystem",
 		"ptrace.tracee.ancestors.file.gid",
 		"ptrace.tracee.ancestors.file.group",
+		"ptrace.tracee.ancestors.file.hash",
 		"ptrace.tracee.ancestors.file.in_upper_layer",
 		"ptrace.tracee.ancestors.file.inode",
 		"ptrace.tracee.ancestors.file.mode",
@@ -15840,6 +16012,7 @@ func (ev *Event) GetFields() []eval.Field {
 		"ptrace.tracee.file.filesystem",
 		"ptrace.tracee.file.gid",
 		"ptrace.tracee.file.group",
+		"ptrace.tracee.file.hash",
 		"ptrace.tracee.file.in_upper_layer",
 		"ptrace.tracee.file.inode",
 		"ptrace.tracee.file.mode",
@@ -15904,6 +16077,7 @@ func (ev *Event) GetFields() []eval.Field {
 		"ptrace.tracee.parent.file.filesystem",
 		"ptrace.tracee.parent.file.gid",
 		"ptrace.tracee.parent.file.group",
+		"ptrace.tracee.parent.file.hash",
 		"ptrace.tracee.parent.file.in_upper_layer",
 		"ptrace.tracee.parent.file.inode",
 		"ptrace.tracee.parent.file.mode",
@@ -16101,6 +16275,7 @@ func (ev *Event) GetFields() []eval.Field {
 		"signal.target.ancestors.file.filesystem",
 		"signal.target.ancestors.file.gid",
 		"signal.target.ancestors.file.group",
+		"signal.target.ancestors.file.hash",
 		"signal.target.ancestors.file.in_upper_layer",
 		"signal.target.ancestors.file.inode",
 		"signal.target.ancestors.file.mode",
@@ -16171,6 +16346,7 @@ func (ev *Event) GetFields() []eval.Field {
 		"signal.target.file.filesystem",
 		"signal.target.file.gid",
 		"signal.target.file.group",
+		"signal.target.file.hash",
 		"signal.target.file.in_upper_layer",
 		"signal.target.file.inode",
 		"signal.target.file.mode",
@@ -16235,6 +16411,7 @@ func (ev *Event) GetFields() []eval.Field {
 		"signal.target.parent.file.filesystem",
 		"signal.target.parent.file.gid",
 		"signal.target.parent.file.group",
+		"signal.target.parent.file.hash",
 		"signal.target.parent.file.in_upper_layer",
 		"signal.target.parent.file.inode",
 		"signal.target.parent.file.mode",
@@ -16553,6 +16730,8 @@ func (ev *Event) GetFieldValue(field eval.Field) (interface{}, error) {
 		return int(ev.Exec.Process.FileEvent.FileFields.GID), nil
 	case "exec.file.group":
 		return ev.FieldHandlers.ResolveFileFieldsGroup(ev, &ev.Exec.Process.FileEvent.FileFields), nil
+	case "exec.file.hash":
+		return ev.FieldHandlers.ResolveProcessFileHash(ev, ev.Exec.Process), nil
 	case "exec.file.in_upper_layer":
 		return ev.FieldHandlers.ResolveFileFieldsInUpperLayer(ev, &ev.Exec.Process.FileEvent.FileFields), nil
 	case "exec.file.inode":
@@ -16697,6 +16876,8 @@ func (ev *Event) GetFieldValue(field eval.Field) (interface{}, error) {
 		return int(ev.Exit.Process.FileEvent.FileFields.GID), nil
 	case "exit.file.group":
 		return ev.FieldHandlers.ResolveFileFieldsGroup(ev, &ev.Exit.Process.FileEvent.FileFields), nil
+	case "exit.file.hash":
+		return ev.FieldHandlers.ResolveProcessFileHash(ev, ev.Exit.Process), nil
 	case "exit.file.in_upper_layer":
 		return ev.FieldHandlers.ResolveFileFieldsInUpperLayer(ev, &ev.Exit.Process.FileEvent.FileFields), nil
 	case "exit.file.inode":
@@ -17349,6 +17530,18 @@ func (ev *Event) GetFieldValue(field eval.Field) (interface{}, error) {
 			ptr = iterator.Next()
 		}
 		return values, nil
+	case "process.ancestors.file.hash":
+		var values []string
+		ctx := eval.NewContext(ev)
+		iterator := &ProcessAncestorsIterator{}
+		ptr := iterator.Front(ctx)
+		for ptr != nil {
+			element := (*ProcessCacheEntry)(ptr)
+			result := ev.FieldHandlers.ResolveProcessFileHash(ev, &element.ProcessContext.Process)
+			values = append(values, result)
+			ptr = iterator.Next()
+		}
+		return values, nil
 	case "process.ancestors.file.in_upper_layer":
 		var values []bool
 		ctx := eval.NewContext(ev)
@@ -17969,6 +18162,8 @@ func (ev *Event) GetFieldValue(field eval.Field) (interface{}, error) {
 		return int(ev.ProcessContext.Process.FileEvent.FileFields.GID), nil
 	case "process.file.group":
 		return ev.FieldHandlers.ResolveFileFieldsGroup(ev, &ev.ProcessContext.Process.FileEvent.FileFields), nil
+	case "process.file.hash":
+		return ev.FieldHandlers.ResolveProcessFileHash(ev, &ev.ProcessContext.Process), nil
 	case "process.file.in_upper_layer":
 		return ev.FieldHandlers.ResolveFileFieldsInUpperLayer(ev, &ev.ProcessContext.Process.FileEvent.FileFields), nil
 	case "process.file.inode":
@@ -18097,6 +18292,8 @@ func (ev *Event) GetFieldValue(field eval.Field) (interface{}, error) {
 		return int(ev.ProcessContext.Parent.FileEvent.FileFields.GID), nil
 	case "process.parent.file.group":
 		return ev.FieldHandlers.ResolveFileFieldsGroup(ev, &ev.ProcessContext.Parent.FileEvent.FileFields), nil
+	case "process.parent.file.hash":
+		return ev.FieldHandlers.ResolveProcessFileHash(ev, ev.ProcessContext.Parent), nil
 	case "process.parent.file.in_upper_layer":
 		return ev.FieldHandlers.ResolveFileFieldsInUpperLayer(ev, &ev.ProcessContext.Parent.FileEvent.FileFields), nil
 	case "process.parent.file.inode":
@@ -18473,6 +18670,18 @@ func (ev *Event) GetFieldValue(field eval.Field) (interface{}, error) {
 			ptr = iterator.Next()
 		}
 		return values, nil
+	case "ptrace.tracee.ancestors.file.hash":
+		var values []string
+		ctx := eval.NewContext(ev)
+		iterator := &ProcessAncestorsIterator{}
+		ptr := iterator.Front(ctx)
+		for ptr != nil {
+			element := (*ProcessCacheEntry)(ptr)
+			result := ev.FieldHandlers.ResolveProcessFileHash(ev, &element.ProcessContext.Process)
+			values = append(values, result)
+			ptr = iterator.Next()
+		}
+		return values, nil
 	case "ptrace.tracee.ancestors.file.in_upper_layer":
 		var values []bool
 		ctx := eval.NewContext(ev)
@@ -19093,6 +19302,8 @@ func (ev *Event) GetFieldValue(field eval.Field) (interface{}, error) {
 		return int(ev.PTrace.Tracee.Process.FileEvent.FileFields.GID), nil
 	case "ptrace.tracee.file.group":
 		return ev.FieldHandlers.ResolveFileFieldsGroup(ev, &ev.PTrace.Tracee.Process.FileEvent.FileFields), nil
+	case "ptrace.tracee.file.hash":
+		return ev.FieldHandlers.ResolveProcessFileHash(ev, &ev.PTrace.Tracee.Process), nil
 	case "ptrace.tracee.file.in_upper_layer":
 		return ev.FieldHandlers.ResolveFileFieldsInUpperLayer(ev, &ev.PTrace.Tracee.Process.FileEvent.FileFields), nil
 	case "ptrace.tracee.file.inode":
@@ -19221,6 +19432,8 @@ func (ev *Event) GetFieldValue(field eval.Field) (interface{}, error) {
 		return int(ev.PTrace.Tracee.Parent.FileEvent.FileFields.GID), nil
 	case "ptrace.tracee.parent.file.group":
 		return ev.FieldHandlers.ResolveFileFieldsGroup(ev, &ev.PTrace.Tracee.Parent.FileEvent.FileFields), nil
+	case "ptrace.tracee.parent.file.hash":
+		return ev.FieldHandlers.ResolveProcessFileHash(ev, ev.PTrace.Tracee.Parent), nil
 	case "ptrace.tracee.parent.file.in_upper_layer":
 		return ev.FieldHandlers.ResolveFileFieldsInUpperLayer(ev, &ev.PTrace.Tracee.Parent.FileEvent.FileFields), nil
 	case "ptrace.tracee.parent.file.inode":
@@ -19835,6 +20048,18 @@ func (ev *Event) GetFieldValue(field eval.Field) (interface{}, error) {
 			ptr = iterator.Next()
 		}
 		return values, nil
+	case "signal.target.ancestors.file.hash":
+		var values []string
+		ctx := eval.NewContext(ev)
+		iterator := &ProcessAncestorsIterator{}
+		ptr := iterator.Front(ctx)
+		for ptr != nil {
+			element := (*ProcessCacheEntry)(ptr)
+			result := ev.FieldHandlers.ResolveProcessFileHash(ev, &element.ProcessContext.Process)
+			values = append(values, result)
+			ptr = iterator.Next()
+		}
+		return values, nil
 	case "signal.target.ancestors.file.in_upper_layer":
 		var values []bool
 		ctx := eval.NewContext(ev)
@@ -20455,6 +20680,8 @@ func (ev *Event) GetFieldValue(field eval.Field) (interface{}, error) {
 		return int(ev.Signal.Target.Process.FileEvent.FileFields.GID), nil
 	case "signal.target.file.group":
 		return ev.FieldHandlers.ResolveFileFieldsGroup(ev, &ev.Signal.Target.Process.FileEvent.FileFields), nil
+	case "signal.target.file.hash":
+		return ev.FieldHandlers.ResolveProcessFileHash(ev, &ev.Signal.Target.Process), nil
 	case "signal.target.file.in_upper_layer":
 		return ev.FieldHandlers.ResolveFileFieldsInUpperLayer(ev, &ev.Signal.Target.Process.FileEvent.FileFields), nil
 	case "signal.target.file.inode":
@@ -20583,6 +20810,8 @@ func (ev *Event) GetFieldValue(field eval.Field) (interface{}, error) {
 		return int(ev.Signal.Target.Parent.FileEvent.FileFields.GID), nil
 	case "signal.target.parent.file.group":
 		return ev.FieldHandlers.ResolveFileFieldsGroup(ev, &ev.Signal.Target.Parent.FileEvent.FileFields), nil
+	case "signal.target.parent.file.hash":
+		return ev.FieldHandlers.ResolveProcessFileHash(ev, ev.Signal.Target.Parent), nil
 	case "signal.target.parent.file.in_upper_layer":
 		return ev.FieldHandlers.ResolveFileFieldsInUpperLayer(ev, &ev.Signal.Target.Parent.FileEvent.FileFields), nil
 	case "signal.target.parent.file.inode":
@@ -21018,6 +21247,8 @@ func (ev *Event) GetFieldEventType(field eval.Field) (eval.EventType, error) {
 		return "exec", nil
 	case "exec.file.group":
 		return "exec", nil
+	case "exec.file.hash":
+		return "exec", nil
 	case "exec.file.in_upper_layer":
 		return "exec", nil
 	case "exec.file.inode":
@@ -21162,6 +21393,8 @@ func (ev *Event) GetFieldEventType(field eval.Field) (eval.EventType, error) {
 		return "exit", nil
 	case "exit.file.group":
 		return "exit", nil
+	case "exit.file.hash":
+		return "exit", nil
 	case "exit.file.in_upper_layer":
 		return "exit", nil
 	case "exit.file.inode":
@@ -21594,6 +21827,8 @@ func (ev *Event) GetFieldEventType(field eval.Field) (eval.EventType, error) {
 		return "*", nil
 	case "process.ancestors.file.group":
 		return "*", nil
+	case "process.ancestors.file.hash":
+		return "*", nil
 	case "process.ancestors.file.in_upper_layer":
 		return "*", nil
 	case "process.ancestors.file.inode":
@@ -21734,6 +21969,8 @@ func (ev *Event) GetFieldEventType(field eval.Field) (eval.EventType, error) {
 		return "*", nil
 	case "process.file.group":
 		return "*", nil
+	case "process.file.hash":
+		return "*", nil
 	case "process.file.in_upper_layer":
 		return "*", nil
 	case "process.file.inode":
@@ -21862,6 +22099,8 @@ func (ev *Event) GetFieldEventType(field eval.Field) (eval.EventType, error) {
 		return "*", nil
 	case "process.parent.file.group":
 		return "*", nil
+	case "process.parent.file.hash":
+		return "*", nil
 	case "process.parent.file.in_upper_layer":
 		return "*", nil
 	case "process.parent.file.inode":
@@ -22018,6 +22257,8 @@ func (ev *Event) GetFieldEventType(field eval.Field) (eval.EventType, error) {
 		return "ptrace", nil
 	case "ptrace.tracee.ancestors.file.group":
 		return "ptrace", nil
+	case "ptrace.tracee.ancestors.file.hash":
+		return "ptrace", nil
 	case "ptrace.tracee.ancestors.file.in_upper_layer":
 		return "ptrace", nil
 	case "ptrace.tracee.ancestors.file.inode":
@@ -22158,6 +22399,8 @@ func (ev *Event) GetFieldEventType(field eval.Field) (eval.EventType, error) {
 		return "ptrace", nil
 	case "ptrace.tracee.file.group":
 		return "ptrace", nil
+	case "ptrace.tracee.file.hash":
+		return "ptrace", nil
 	case "ptrace.tracee.file.in_upper_layer":
 		return "ptrace", nil
 	case "ptrace.tracee.file.inode":
@@ -22286,6 +22529,8 @@ func (ev *Event) GetFieldEventType(field eval.Field) (eval.EventType, error) {
 		return "ptrace", nil
 	case "ptrace.tracee.parent.file.group":
 		return "ptrace", nil
+	case "ptrace.tracee.parent.file.hash":
+		return "ptrace", nil
 	case "ptrace.tracee.parent.file.in_upper_layer":
 		return "ptrace", nil
 	case "ptrace.tracee.parent.file.inode":
@@ -22680,6 +22925,8 @@ func (ev *Event) GetFieldEventType(field eval.Field) (eval.EventType, error) {
 		return "signal", nil
 	case "signal.target.ancestors.file.group":
 		return "signal", nil
+	case "signal.target.ancestors.file.hash":
+		return "signal", nil
 	case "signal.target.ancestors.file.in_upper_layer":
 		return "signal", nil
 	case "signal.target.ancestors.file.inode":
@@ -22820,6 +23067,8 @@ func (ev *Event) GetFieldEventType(field eval.Field) (eval.EventType, error) {
 		return "signal", nil
 	case "signal.target.file.group":
 		return "signal", nil
+	case "signal.target.file.hash":
+		return "signal", nil
 	case "signal.target.file.in_upper_layer":
 		return "signal", nil
 	case "signal.target.file.inode":
@@ -22948,6 +23197,8 @@ func (ev *Event) GetFieldEventType(field eval.Field) (eval.EventType, error) {
 		return "signal", nil
 	case "signal.target.parent.file.group":
 		return "signal", nil
+	case "signal.target.parent.file.hash":
+		return "signal", nil
 	case "signal.target.parent.file.in_upper_layer":
 		return "signal", nil
 	case "signal.target.parent.file.inode":
@@ -23383,6 +23634,8 @@ func (ev *Event) GetFieldType(field eval.Field) (reflect.Kind, error) {
 		return reflect.Int, nil
 	case "exec.file.group":
 		return reflect.String, nil
+	case "exec.file.hash":
+		return reflect.String, nil
 	case "exec.file.in_upper_layer":
 		return reflect.Bool, nil
 	case "exec.file.inode":
@@ -23527,6 +23780,8 @@ func (ev *Event) GetFieldType(field eval.Field) (reflect.Kind, error) {
 		return reflect.Int, nil
 	case "exit.file.group":
 		return reflect.String, nil
+	case "exit.file.hash":
+		return reflect.String, nil
 	case "exit.file.in_upper_layer":
 		return reflect.Bool, nil
 	case "exit.file.inode":
@@ -23959,6 +24214,8 @@ func (ev *Event) GetFieldType(field eval.Field) (reflect.Kind, error) {
 		return reflect.Int, nil
 	case "process.ancestors.file.group":
 		return reflect.String, nil
+	case "process.ancestors.file.hash":
+		return reflect.String, nil
 	case "process.ancestors.file.in_upper_layer":
 		return reflect.Bool, nil
 	case "process.ancestors.file.inode":
@@ -24099,6 +24356,8 @@ func (ev *Event) GetFieldType(field eval.Field) (reflect.Kind, error) {
 		return reflect.Int, nil
 	case "process.file.group":
 		return reflect.String, nil
+	case "process.file.hash":
+		return reflect.String, nil
 	case "process.file.in_upper_layer":
 		return reflect.Bool, nil
 	case "process.file.inode":
@@ -24227,6 +24486,8 @@ func (ev *Event) GetFieldType(field eval.Field) (reflect.Kind, error) {
 		return reflect.Int, nil
 	case "process.parent.file.group":
 		return reflect.String, nil
+	case "process.parent.file.hash":
+		return reflect.String, nil
 	case "process.parent.file.in_upper_layer":
 		return reflect.Bool, nil
 	case "process.parent.file.inode":
@@ -24383,6 +24644,8 @@ func (ev *Event) GetFieldType(field eval.Field) (reflect.Kind, error) {
 		return reflect.Int, nil
 	case "ptrace.tracee.ancestors.file.group":
 		return reflect.String, nil
+	case "ptrace.tracee.ancestors.file.hash":
+		return reflect.String, nil
 	case "ptrace.tracee.ancestors.file.in_upper_layer":
 		return reflect.Bool, nil
 	case "ptrace.tracee.ancestors.file.inode":
@@ -24523,6 +24786,8 @@ func (ev *Event) GetFieldType(field eval.Field) (reflect.Kind, error) {
 		return reflect.Int, nil
 	case "ptrace.tracee.file.group":
 		return reflect.String, nil
+	case "ptrace.tracee.file.hash":
+		return reflect.String, nil
 	case "ptrace.tracee.file.in_upper_layer":
 		return reflect.Bool, nil
 	case "ptrace.tracee.file.inode":
@@ -24651,6 +24916,8 @@ func (ev *Event) GetFieldType(field eval.Field) (reflect.Kind, error) {
 		return reflect.Int, nil
 	case "ptrace.tracee.parent.file.group":
 		return reflect.String, nil
+	case "ptrace.tracee.parent.file.hash":
+		return reflect.String, nil
 	case "ptrace.tracee.parent.file.in_upper_layer":
 		return reflect.Bool, nil
 	case "ptrace.tracee.parent.file.inode":
@@ -25045,6 +25312,8 @@ func (ev *Event) GetFieldType(field eval.Field) (reflect.Kind, error) {
 		return reflect.Int, nil
 	case "signal.target.ancestors.file.group":
 		return reflect.String, nil
+	case "signal.target.ancestors.file.hash":
+		return reflect.String, nil
 	case "signal.target.ancestors.file.in_upper_layer":
 		return reflect.Bool, nil
 	case "signal.target.ancestors.file.inode":
@@ -25185,6 +25454,8 @@ func (ev *Event) GetFieldType(field eval.Field) (reflect.Kind, error) {
 		return reflect.Int, nil
 	case "signal.target.file.group":
 		return reflect.String, nil
+	case "signal.target.file.hash":
+		return reflect.String, nil
 	case "signal.target.file.in_upper_layer":
 		return reflect.Bool, nil
 	case "signal.target.file.inode":
@@ -25313,6 +25584,8 @@ func (ev *Event) GetFieldType(field eval.Field) (reflect.Kind, error) {
 		return reflect.Int, nil
 	case "signal.target.parent.file.group":
 		return reflect.String, nil
+	case "signal.target.parent.file.hash":
+		return reflect.String, nil
 	case "signal.target.parent.file.in_upper_layer":
 		return reflect.Bool, nil
 	case "signal.target.parent.file.inode":
@@ -26287,6 +26560,16 @@ func (ev *Event) SetFieldValue(field eval.Field, value interface{}) error {
 		}
 		ev.Exec.Process.FileEvent.FileFields.Group = rv
 		return nil
+	case "exec.file.hash":
+		if ev.Exec.Process == nil {
+			ev.Exec.Process = &Process{}
+		}
+		rv, ok := value.(string)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "Exec.Process.FileHash"}
+		}
+		ev.Exec.Process.FileHash = rv
+		return nil
 	case "exec.file.in_upper_layer":
 		if ev.Exec.Process == nil {
 			ev.Exec.Process = &Process{}
@@ -26996,6 +27279,16 @@ func (ev *Event) SetFieldValue(field eval.Field, value interface{}) error {
 		}
 		ev.Exit.Process.FileEvent.FileFields.Group = rv
 		return nil
+	case "exit.file.hash":
+		if ev.Exit.Process == nil {
+			ev.Exit.Process = &Process{}
+		}
+		rv, ok := value.(string)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "Exit.Process.FileHash"}
+		}
+		ev.Exit.Process.FileHash = rv
+		return nil
 	case "exit.file.in_upper_layer":
 		if ev.Exit.Process == nil {
 			ev.Exit.Process = &Process{}
@@ -28722,6 +29015,19 @@ func (ev *Event) SetFieldValue(field eval.Field, value interface{}) error {
 		}
 		ev.ProcessContext.Ancestor.ProcessContext.Process.FileEvent.FileFields.Group = rv
 		return nil
+	case "process.ancestors.file.hash":
+		if ev.ProcessContext == nil {
+			ev.ProcessContext = &ProcessContext{}
+		}
+		if ev.ProcessContext.Ancestor == nil {
+			ev.ProcessContext.Ancestor = &ProcessCacheEntry{}
+		}
+		rv, ok := value.(string)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "ProcessContext.Ancestor.ProcessContext.Process.FileHash"}
+		}
+		ev.ProcessContext.Ancestor.ProcessContext.Process.FileHash = rv
+		return nil
 	case "process.ancestors.file.in_upper_layer":
 		if ev.ProcessContext == nil {
 			ev.ProcessContext = &ProcessContext{}
@@ -29561,6 +29867,16 @@ func (ev *Event) SetFieldValue(field eval.Field, value interface{}) error {
 		}
 		ev.ProcessContext.Process.FileEvent.FileFields.Group = rv
 		return nil
+	case "process.file.hash":
+		if ev.ProcessContext == nil {
+			ev.ProcessContext = &ProcessContext{}
+		}
+		rv, ok := value.(string)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "ProcessContext.Process.FileHash"}
+		}
+		ev.ProcessContext.Process.FileHash = rv
+		return nil
 	case "process.file.in_upper_layer":
 		if ev.ProcessContext == nil {
 			ev.ProcessContext = &ProcessContext{}
@@ -30262,6 +30578,19 @@ func (ev *Event) SetFieldValue(field eval.Field, value interface{}) error {
 		}
 		ev.ProcessContext.Parent.FileEvent.FileFields.Group = rv
 		return nil
+	case "process.parent.file.hash":
+		if ev.ProcessContext == nil {
+			ev.ProcessContext = &ProcessContext{}
+		}
+		if ev.ProcessContext.Parent == nil {
+			ev.ProcessContext.Parent = &Process{}
+		}
+		rv, ok := value.(string)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "ProcessContext.Parent.FileHash"}
+		}
+		ev.ProcessContext.Parent.FileHash = rv
+		return nil
 	case "process.parent.file.in_upper_layer":
 		if ev.ProcessContext == nil {
 			ev.ProcessContext = &ProcessContext{}
@@ -31241,6 +31570,19 @@ func (ev *Event) SetFieldValue(field eval.Field, value interface{}) error {
 		}
 		ev.PTrace.Tracee.Ancestor.ProcessContext.Process.FileEvent.FileFields.Group = rv
 		return nil
+	case "ptrace.tracee.ancestors.file.hash":
+		if ev.PTrace.Tracee == nil {
+			ev.PTrace.Tracee = &ProcessContext{}
+		}
+		if ev.PTrace.Tracee.Ancestor == nil {
+			ev.PTrace.Tracee.Ancestor = &ProcessCacheEntry{}
+		}
+		rv, ok := value.(string)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "PTrace.Tracee.Ancestor.ProcessContext.Process.FileHash"}
+		}
+		ev.PTrace.Tracee.Ancestor.ProcessContext.Process.FileHash = rv
+		return nil
 	case "ptrace.tracee.ancestors.file.in_upper_layer":
 		if ev.PTrace.Tracee == nil {
 			ev.PTrace.Tracee = &ProcessContext{}
@@ -32080,6 +32422,16 @@ func (ev *Event) SetFieldValue(field eval.Field, value interface{}) error {
 		}
 		ev.PTrace.Tracee.Process.FileEvent.FileFields.Group = rv
 		return nil
+	case "ptrace.tracee.file.hash":
+		if ev.PTrace.Tracee == nil {
+			ev.PTrace.Tracee = &ProcessContext{}
+		}
+		rv, ok := value.(string)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "PTrace.Tracee.Process.FileHash"}
+		}
+		ev.PTrace.Tracee.Process.FileHash = rv
+		return nil
 	case "ptrace.tracee.file.in_upper_layer":
 		if ev.PTrace.Tracee == nil {
 			ev.PTrace.Tracee = &ProcessContext{}
@@ -32781,6 +33133,19 @@ func (ev *Event) SetFieldValue(field eval.Field, value interface{}) error {
 		}
 		ev.PTrace.Tracee.Parent.FileEvent.FileFields.Group = rv
 		return nil
+	case "ptrace.tracee.parent.file.hash":
+		if ev.PTrace.Tracee == nil {
+			ev.PTrace.Tracee = &ProcessContext{}
+		}
+		if ev.PTrace.Tracee.Parent == nil {
+			ev.PTrace.Tracee.Parent = &Process{}
+		}
+		rv, ok := value.(string)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "PTrace.Tracee.Parent.FileHash"}
+		}
+		ev.PTrace.Tracee.Parent.FileHash = rv
+		return nil
 	case "ptrace.tracee.parent.file.in_upper_layer":
 		if ev.PTrace.Tracee == nil {
 			ev.PTrace.Tracee = &ProcessContext{}
@@ -34543,6 +34908,19 @@ func (ev *Event) SetFieldValue(field eval.Field, value interface{}) error {
 		}
 		ev.Signal.Target.Ancestor.ProcessContext.Process.FileEvent.FileFields.Group = rv
 		return nil
+	case "signal.target.ancestors.file.hash":
+		if ev.Signal.Target == nil {
+			ev.Signal.Target = &ProcessContext{}
+		}
+		if ev.Signal.Target.Ancestor == nil {
+			ev.Signal.Target.Ancestor = &ProcessCacheEntry{}
+		}
+		rv, ok := value.(string)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "Signal.Target.Ancestor.ProcessContext.Process.FileHash"}
+		}
+		ev.Signal.Target.Ancestor.ProcessContext.Process.FileHash = rv
+		return nil
 	case "signal.target.ancestors.file.in_upper_layer":
 		if ev.Signal.Target == nil {
 			ev.Signal.Target = &ProcessContext{}
@@ -35382,6 +35760,16 @@ func (ev *Event) SetFieldValue(field eval.Field, value interface{}) error {
 		}
 		ev.Signal.Target.Process.FileEvent.FileFields.Group = rv
 		return nil
+	case "signal.target.file.hash":
+		if ev.Signal.Target == nil {
+			ev.Signal.Target = &ProcessContext{}
+		}
+		rv, ok := value.(string)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "Signal.Target.Process.FileHash"}
+		}
+		ev.Signal.Target.Process.FileHash = rv
+		return nil
 	case "signal.target.file.in_upper_layer":
 		if ev.Signal.Target == nil {
 			ev.Signal.Target = &ProcessContext{}
@@ -36083,6 +36471,19 @@ func (ev *Event) SetFieldValue(field eval.Field, value interface{}) error {
 		}
 		ev.Signal.Target.Parent.FileEvent.FileFields.Group = rv
 		return nil
+	case "signal.target.parent.file.hash":
+		if ev.Signal.Target == nil {
+			ev.Signal.Target = &ProcessContext{}
+		}
+		if ev.Signal.Target.Parent == nil {
+			ev.Signal.Target.Parent = &Process{}
+		}
+		rv, ok := value.(string)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "Signal.Target.Parent.FileHash"}
+		}
+		ev.Signal.Target.Parent.FileHash = rv
+		return nil
 	case "signal.target.parent.file.in_upper_layer":
 		if ev.Signal.Target == nil {
 			ev.Signal.Target = &ProcessContext{}
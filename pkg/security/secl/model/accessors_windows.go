@@ -12,6 +12,7 @@ package model
 import (
 	"github.com/DataDog/datadog-agent/pkg/security/secl/compiler/eval"
 	"reflect"
+	"strings"
 )
 
 func (m *Model) GetIterator(field eval.Field) (eval.Iterator, error) {
@@ -22,10 +23,173 @@ func (m *Model) GetIterator(field eval.Field) (eval.Iterator, error) {
 func (m *Model) GetEventTypes() []eval.EventType {
 	return []eval.EventType{
 		eval.EventType(""),
+		eval.EventType("create"),
+		eval.EventType("create_key"),
+		eval.EventType("delete"),
+		eval.EventType("delete_key"),
+		eval.EventType("open_key"),
+		eval.EventType("set_key_value"),
+		eval.EventType("write"),
 	}
 }
 func (m *Model) GetEvaluator(field eval.Field, regID eval.RegisterID) (eval.Evaluator, error) {
 	switch field {
+	case "create.file.name":
+		return &eval.StringEvaluator{
+			OpOverrides: eval.WindowsPathCmp,
+			EvalFnc: func(ctx *eval.Context) string {
+				ev := ctx.Event.(*Event)
+				return ev.CreateNewFile.File.BasenameStr
+			},
+			Field:  field,
+			Weight: eval.FunctionWeight,
+		}, nil
+	case "create.file.name.length":
+		return &eval.IntEvaluator{
+			OpOverrides: eval.WindowsPathCmp,
+			EvalFnc: func(ctx *eval.Context) int {
+				ev := ctx.Event.(*Event)
+				return len(ev.CreateNewFile.File.BasenameStr)
+			},
+			Field:  field,
+			Weight: eval.FunctionWeight,
+		}, nil
+	case "create.file.path":
+		return &eval.StringEvaluator{
+			OpOverrides: eval.WindowsPathCmp,
+			EvalFnc: func(ctx *eval.Context) string {
+				ev := ctx.Event.(*Event)
+				return ev.CreateNewFile.File.PathnameStr
+			},
+			Field:  field,
+			Weight: eval.FunctionWeight,
+		}, nil
+	case "create.file.path.length":
+		return &eval.IntEvaluator{
+			OpOverrides: eval.WindowsPathCmp,
+			EvalFnc: func(ctx *eval.Context) int {
+				ev := ctx.Event.(*Event)
+				return len(ev.CreateNewFile.File.PathnameStr)
+			},
+			Field:  field,
+			Weight: eval.FunctionWeight,
+		}, nil
+	case "create_key.registry.key_name":
+		return &eval.StringEvaluator{
+			EvalFnc: func(ctx *eval.Context) string {
+				ev := ctx.Event.(*Event)
+				return ev.CreateRegistryKey.Registry.KeyName
+			},
+			Field:  field,
+			Weight: eval.FunctionWeight,
+		}, nil
+	case "create_key.registry.key_name.length":
+		return &eval.IntEvaluator{
+			EvalFnc: func(ctx *eval.Context) int {
+				ev := ctx.Event.(*Event)
+				return len(ev.CreateRegistryKey.Registry.KeyName)
+			},
+			Field:  field,
+			Weight: eval.FunctionWeight,
+		}, nil
+	case "create_key.registry.key_path":
+		return &eval.StringEvaluator{
+			OpOverrides: eval.WindowsPathCmp,
+			EvalFnc: func(ctx *eval.Context) string {
+				ev := ctx.Event.(*Event)
+				return ev.CreateRegistryKey.Registry.KeyPath
+			},
+			Field:  field,
+			Weight: eval.FunctionWeight,
+		}, nil
+	case "create_key.registry.key_path.length":
+		return &eval.IntEvaluator{
+			OpOverrides: eval.WindowsPathCmp,
+			EvalFnc: func(ctx *eval.Context) int {
+				ev := ctx.Event.(*Event)
+				return len(ev.CreateRegistryKey.Registry.KeyPath)
+			},
+			Field:  field,
+			Weight: eval.FunctionWeight,
+		}, nil
+	case "delete.file.name":
+		return &eval.StringEvaluator{
+			OpOverrides: eval.WindowsPathCmp,
+			EvalFnc: func(ctx *eval.Context) string {
+				ev := ctx.Event.(*Event)
+				return ev.DeleteFile.File.BasenameStr
+			},
+			Field:  field,
+			Weight: eval.FunctionWeight,
+		}, nil
+	case "delete.file.name.length":
+		return &eval.IntEvaluator{
+			OpOverrides: eval.WindowsPathCmp,
+			EvalFnc: func(ctx *eval.Context) int {
+				ev := ctx.Event.(*Event)
+				return len(ev.DeleteFile.File.BasenameStr)
+			},
+			Field:  field,
+			Weight: eval.FunctionWeight,
+		}, nil
+	case "delete.file.path":
+		return &eval.StringEvaluator{
+			OpOverrides: eval.WindowsPathCmp,
+			EvalFnc: func(ctx *eval.Context) string {
+				ev := ctx.Event.(*Event)
+				return ev.DeleteFile.File.PathnameStr
+			},
+			Field:  field,
+			Weight: eval.FunctionWeight,
+		}, nil
+	case "delete.file.path.length":
+		return &eval.IntEvaluator{
+			OpOverrides: eval.WindowsPathCmp,
+			EvalFnc: func(ctx *eval.Context) int {
+				ev := ctx.Event.(*Event)
+				return len(ev.DeleteFile.File.PathnameStr)
+			},
+			Field:  field,
+			Weight: eval.FunctionWeight,
+		}, nil
+	case "delete_key.registry.key_name":
+		return &eval.StringEvaluator{
+			EvalFnc: func(ctx *eval.Context) string {
+				ev := ctx.Event.(*Event)
+				return ev.DeleteRegistryKey.Registry.KeyName
+			},
+			Field:  field,
+			Weight: eval.FunctionWeight,
+		}, nil
+	case "delete_key.registry.key_name.length":
+		return &eval.IntEvaluator{
+			EvalFnc: func(ctx *eval.Context) int {
+				ev := ctx.Event.(*Event)
+				return len(ev.DeleteRegistryKey.Registry.KeyName)
+			},
+			Field:  field,
+			Weight: eval.FunctionWeight,
+		}, nil
+	case "delete_key.registry.key_path":
+		return &eval.StringEvaluator{
+			OpOverrides: eval.WindowsPathCmp,
+			EvalFnc: func(ctx *eval.Context) string {
+				ev := ctx.Event.(*Event)
+				return ev.DeleteRegistryKey.Registry.KeyPath
+			},
+			Field:  field,
+			Weight: eval.FunctionWeight,
+		}, nil
+	case "delete_key.registry.key_path.length":
+		return &eval.IntEvaluator{
+			OpOverrides: eval.WindowsPathCmp,
+			EvalFnc: func(ctx *eval.Context) int {
+				ev := ctx.Event.(*Event)
+				return len(ev.DeleteRegistryKey.Registry.KeyPath)
+			},
+			Field:  field,
+			Weight: eval.FunctionWeight,
+		}, nil
 	case "event.timestamp":
 		return &eval.IntEvaluator{
 			EvalFnc: func(ctx *eval.Context) int {
@@ -35,37 +199,483 @@ func (m *Model) GetEvaluator(field eval.Field, regID eval.RegisterID) (eval.Eval
 			Field:  field,
 			Weight: eval.HandlerWeight,
 		}, nil
+	case "open_key.registry.key_name":
+		return &eval.StringEvaluator{
+			EvalFnc: func(ctx *eval.Context) string {
+				ev := ctx.Event.(*Event)
+				return ev.OpenRegistryKey.Registry.KeyName
+			},
+			Field:  field,
+			Weight: eval.FunctionWeight,
+		}, nil
+	case "open_key.registry.key_name.length":
+		return &eval.IntEvaluator{
+			EvalFnc: func(ctx *eval.Context) int {
+				ev := ctx.Event.(*Event)
+				return len(ev.OpenRegistryKey.Registry.KeyName)
+			},
+			Field:  field,
+			Weight: eval.FunctionWeight,
+		}, nil
+	case "open_key.registry.key_path":
+		return &eval.StringEvaluator{
+			OpOverrides: eval.WindowsPathCmp,
+			EvalFnc: func(ctx *eval.Context) string {
+				ev := ctx.Event.(*Event)
+				return ev.OpenRegistryKey.Registry.KeyPath
+			},
+			Field:  field,
+			Weight: eval.FunctionWeight,
+		}, nil
+	case "open_key.registry.key_path.length":
+		return &eval.IntEvaluator{
+			OpOverrides: eval.WindowsPathCmp,
+			EvalFnc: func(ctx *eval.Context) int {
+				ev := ctx.Event.(*Event)
+				return len(ev.OpenRegistryKey.Registry.KeyPath)
+			},
+			Field:  field,
+			Weight: eval.FunctionWeight,
+		}, nil
+	case "set_key_value.registry.key_name":
+		return &eval.StringEvaluator{
+			EvalFnc: func(ctx *eval.Context) string {
+				ev := ctx.Event.(*Event)
+				return ev.SetRegistryKeyValue.Registry.KeyName
+			},
+			Field:  field,
+			Weight: eval.FunctionWeight,
+		}, nil
+	case "set_key_value.registry.key_name.length":
+		return &eval.IntEvaluator{
+			EvalFnc: func(ctx *eval.Context) int {
+				ev := ctx.Event.(*Event)
+				return len(ev.SetRegistryKeyValue.Registry.KeyName)
+			},
+			Field:  field,
+			Weight: eval.FunctionWeight,
+		}, nil
+	case "set_key_value.registry.key_path":
+		return &eval.StringEvaluator{
+			OpOverrides: eval.WindowsPathCmp,
+			EvalFnc: func(ctx *eval.Context) string {
+				ev := ctx.Event.(*Event)
+				return ev.SetRegistryKeyValue.Registry.KeyPath
+			},
+			Field:  field,
+			Weight: eval.FunctionWeight,
+		}, nil
+	case "set_key_value.registry.key_path.length":
+		return &eval.IntEvaluator{
+			OpOverrides: eval.WindowsPathCmp,
+			EvalFnc: func(ctx *eval.Context) int {
+				ev := ctx.Event.(*Event)
+				return len(ev.SetRegistryKeyValue.Registry.KeyPath)
+			},
+			Field:  field,
+			Weight: eval.FunctionWeight,
+		}, nil
+	case "set_key_value.value_name":
+		return &eval.StringEvaluator{
+			EvalFnc: func(ctx *eval.Context) string {
+				ev := ctx.Event.(*Event)
+				return ev.SetRegistryKeyValue.ValueName
+			},
+			Field:  field,
+			Weight: eval.FunctionWeight,
+		}, nil
+	case "write.file.name":
+		return &eval.StringEvaluator{
+			OpOverrides: eval.WindowsPathCmp,
+			EvalFnc: func(ctx *eval.Context) string {
+				ev := ctx.Event.(*Event)
+				return ev.WriteFile.File.BasenameStr
+			},
+			Field:  field,
+			Weight: eval.FunctionWeight,
+		}, nil
+	case "write.file.name.length":
+		return &eval.IntEvaluator{
+			OpOverrides: eval.WindowsPathCmp,
+			EvalFnc: func(ctx *eval.Context) int {
+				ev := ctx.Event.(*Event)
+				return len(ev.WriteFile.File.BasenameStr)
+			},
+			Field:  field,
+			Weight: eval.FunctionWeight,
+		}, nil
+	case "write.file.path":
+		return &eval.StringEvaluator{
+			OpOverrides: eval.WindowsPathCmp,
+			EvalFnc: func(ctx *eval.Context) string {
+				ev := ctx.Event.(*Event)
+				return ev.WriteFile.File.PathnameStr
+			},
+			Field:  field,
+			Weight: eval.FunctionWeight,
+		}, nil
+	case "write.file.path.length":
+		return &eval.IntEvaluator{
+			OpOverrides: eval.WindowsPathCmp,
+			EvalFnc: func(ctx *eval.Context) int {
+				ev := ctx.Event.(*Event)
+				return len(ev.WriteFile.File.PathnameStr)
+			},
+			Field:  field,
+			Weight: eval.FunctionWeight,
+		}, nil
+	}
+	if isUnsupportedField(field) {
+		return nil, &eval.ErrFieldNotSupportedOnPlatform{Field: field, Platform: "windows"}
 	}
 	return nil, &eval.ErrFieldNotFound{Field: field}
 }
+
+// isUnsupportedField returns whether the field is only available on other platforms
+func isUnsupportedField(field eval.Field) bool {
+	for _, unsupported := range []string{
+		"bind",
+		"bpf",
+		"capset",
+		"chmod",
+		"chown",
+		"container",
+		"dns",
+		"event.async",
+		"exec",
+		"exit",
+		"link",
+		"load_module",
+		"mkdir",
+		"mmap",
+		"mount",
+		"mprotect",
+		"network",
+		"open",
+		"process",
+		"ptrace",
+		"removexattr",
+		"rename",
+		"rmdir",
+		"selinux",
+		"setgid",
+		"setuid",
+		"setxattr",
+		"signal",
+		"splice",
+		"unlink",
+		"unload_module",
+		"utimes",
+	} {
+		if field == unsupported || strings.HasPrefix(field, unsupported+".") {
+			return true
+		}
+	}
+	return false
+}
 func (ev *Event) GetFields() []eval.Field {
 	return []eval.Field{
+		"create.file.name",
+		"create.file.name.length",
+		"create.file.path",
+		"create.file.path.length",
+		"create_key.registry.key_name",
+		"create_key.registry.key_name.length",
+		"create_key.registry.key_path",
+		"create_key.registry.key_path.length",
+		"delete.file.name",
+		"delete.file.name.length",
+		"delete.file.path",
+		"delete.file.path.length",
+		"delete_key.registry.key_name",
+		"delete_key.registry.key_name.length",
+		"delete_key.registry.key_path",
+		"delete_key.registry.key_path.length",
 		"event.timestamp",
+		"open_key.registry.key_name",
+		"open_key.registry.key_name.length",
+		"open_key.registry.key_path",
+		"open_key.registry.key_path.length",
+		"set_key_value.registry.key_name",
+		"set_key_value.registry.key_name.length",
+		"set_key_value.registry.key_path",
+		"set_key_value.registry.key_path.length",
+		"set_key_value.value_name",
+		"write.file.name",
+		"write.file.name.length",
+		"write.file.path",
+		"write.file.path.length",
 	}
 }
 func (ev *Event) GetFieldValue(field eval.Field) (interface{}, error) {
 	switch field {
+	case "create.file.name":
+		return ev.CreateNewFile.File.BasenameStr, nil
+	case "create.file.name.length":
+		return len(ev.CreateNewFile.File.BasenameStr), nil
+	case "create.file.path":
+		return ev.CreateNewFile.File.PathnameStr, nil
+	case "create.file.path.length":
+		return len(ev.CreateNewFile.File.PathnameStr), nil
+	case "create_key.registry.key_name":
+		return ev.CreateRegistryKey.Registry.KeyName, nil
+	case "create_key.registry.key_name.length":
+		return len(ev.CreateRegistryKey.Registry.KeyName), nil
+	case "create_key.registry.key_path":
+		return ev.CreateRegistryKey.Registry.KeyPath, nil
+	case "create_key.registry.key_path.length":
+		return len(ev.CreateRegistryKey.Registry.KeyPath), nil
+	case "delete.file.name":
+		return ev.DeleteFile.File.BasenameStr, nil
+	case "delete.file.name.length":
+		return len(ev.DeleteFile.File.BasenameStr), nil
+	case "delete.file.path":
+		return ev.DeleteFile.File.PathnameStr, nil
+	case "delete.file.path.length":
+		return len(ev.DeleteFile.File.PathnameStr), nil
+	case "delete_key.registry.key_name":
+		return ev.DeleteRegistryKey.Registry.KeyName, nil
+	case "delete_key.registry.key_name.length":
+		return len(ev.DeleteRegistryKey.Registry.KeyName), nil
+	case "delete_key.registry.key_path":
+		return ev.DeleteRegistryKey.Registry.KeyPath, nil
+	case "delete_key.registry.key_path.length":
+		return len(ev.DeleteRegistryKey.Registry.KeyPath), nil
 	case "event.timestamp":
 		return int(ev.FieldHandlers.ResolveEventTimestamp(ev)), nil
+	case "open_key.registry.key_name":
+		return ev.OpenRegistryKey.Registry.KeyName, nil
+	case "open_key.registry.key_name.length":
+		return len(ev.OpenRegistryKey.Registry.KeyName), nil
+	case "open_key.registry.key_path":
+		return ev.OpenRegistryKey.Registry.KeyPath, nil
+	case "open_key.registry.key_path.length":
+		return len(ev.OpenRegistryKey.Registry.KeyPath), nil
+	case "set_key_value.registry.key_name":
+		return ev.SetRegistryKeyValue.Registry.KeyName, nil
+	case "set_key_value.registry.key_name.length":
+		return len(ev.SetRegistryKeyValue.Registry.KeyName), nil
+	case "set_key_value.registry.key_path":
+		return ev.SetRegistryKeyValue.Registry.KeyPath, nil
+	case "set_key_value.registry.key_path.length":
+		return len(ev.SetRegistryKeyValue.Registry.KeyPath), nil
+	case "set_key_value.value_name":
+		return ev.SetRegistryKeyValue.ValueName, nil
+	case "write.file.name":
+		return ev.WriteFile.File.BasenameStr, nil
+	case "write.file.name.length":
+		return len(ev.WriteFile.File.BasenameStr), nil
+	case "write.file.path":
+		return ev.WriteFile.File.PathnameStr, nil
+	case "write.file.path.length":
+		return len(ev.WriteFile.File.PathnameStr), nil
 	}
 	return nil, &eval.ErrFieldNotFound{Field: field}
 }
 func (ev *Event) GetFieldEventType(field eval.Field) (eval.EventType, error) {
 	switch field {
+	case "create.file.name":
+		return "create", nil
+	case "create.file.name.length":
+		return "create", nil
+	case "create.file.path":
+		return "create", nil
+	case "create.file.path.length":
+		return "create", nil
+	case "create_key.registry.key_name":
+		return "create_key", nil
+	case "create_key.registry.key_name.length":
+		return "create_key", nil
+	case "create_key.registry.key_path":
+		return "create_key", nil
+	case "create_key.registry.key_path.length":
+		return "create_key", nil
+	case "delete.file.name":
+		return "delete", nil
+	case "delete.file.name.length":
+		return "delete", nil
+	case "delete.file.path":
+		return "delete", nil
+	case "delete.file.path.length":
+		return "delete", nil
+	case "delete_key.registry.key_name":
+		return "delete_key", nil
+	case "delete_key.registry.key_name.length":
+		return "delete_key", nil
+	case "delete_key.registry.key_path":
+		return "delete_key", nil
+	case "delete_key.registry.key_path.length":
+		return "delete_key", nil
 	case "event.timestamp":
 		return "", nil
+	case "open_key.registry.key_name":
+		return "open_key", nil
+	case "open_key.registry.key_name.length":
+		return "open_key", nil
+	case "open_key.registry.key_path":
+		return "open_key", nil
+	case "open_key.registry.key_path.length":
+		return "open_key", nil
+	case "set_key_value.registry.key_name":
+		return "set_key_value", nil
+	case "set_key_value.registry.key_name.length":
+		return "set_key_value", nil
+	case "set_key_value.registry.key_path":
+		return "set_key_value", nil
+	case "set_key_value.registry.key_path.length":
+		return "set_key_value", nil
+	case "set_key_value.value_name":
+		return "set_key_value", nil
+	case "write.file.name":
+		return "write", nil
+	case "write.file.name.length":
+		return "write", nil
+	case "write.file.path":
+		return "write", nil
+	case "write.file.path.length":
+		return "write", nil
 	}
 	return "", &eval.ErrFieldNotFound{Field: field}
 }
 func (ev *Event) GetFieldType(field eval.Field) (reflect.Kind, error) {
 	switch field {
+	case "create.file.name":
+		return reflect.String, nil
+	case "create.file.name.length":
+		return reflect.Int, nil
+	case "create.file.path":
+		return reflect.String, nil
+	case "create.file.path.length":
+		return reflect.Int, nil
+	case "create_key.registry.key_name":
+		return reflect.String, nil
+	case "create_key.registry.key_name.length":
+		return reflect.Int, nil
+	case "create_key.registry.key_path":
+		return reflect.String, nil
+	case "create_key.registry.key_path.length":
+		return reflect.Int, nil
+	case "delete.file.name":
+		return reflect.String, nil
+	case "delete.file.name.length":
+		return reflect.Int, nil
+	case "delete.file.path":
+		return reflect.String, nil
+	case "delete.file.path.length":
+		return reflect.Int, nil
+	case "delete_key.registry.key_name":
+		return reflect.String, nil
+	case "delete_key.registry.key_name.length":
+		return reflect.Int, nil
+	case "delete_key.registry.key_path":
+		return reflect.String, nil
+	case "delete_key.registry.key_path.length":
+		return reflect.Int, nil
 	case "event.timestamp":
 		return reflect.Int, nil
+	case "open_key.registry.key_name":
+		return reflect.String, nil
+	case "open_key.registry.key_name.length":
+		return reflect.Int, nil
+	case "open_key.registry.key_path":
+		return reflect.String, nil
+	case "open_key.registry.key_path.length":
+		return reflect.Int, nil
+	case "set_key_value.registry.key_name":
+		return reflect.String, nil
+	case "set_key_value.registry.key_name.length":
+		return reflect.Int, nil
+	case "set_key_value.registry.key_path":
+		return reflect.String, nil
+	case "set_key_value.registry.key_path.length":
+		return reflect.Int, nil
+	case "set_key_value.value_name":
+		return reflect.String, nil
+	case "write.file.name":
+		return reflect.String, nil
+	case "write.file.name.length":
+		return reflect.Int, nil
+	case "write.file.path":
+		return reflect.String, nil
+	case "write.file.path.length":
+		return reflect.Int, nil
 	}
 	return reflect.Invalid, &eval.ErrFieldNotFound{Field: field}
 }
 func (ev *Event) SetFieldValue(field eval.Field, value interface{}) error {
 	switch field {
+	case "create.file.name":
+		rv, ok := value.(string)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "CreateNewFile.File.BasenameStr"}
+		}
+		ev.CreateNewFile.File.BasenameStr = rv
+		return nil
+	case "create.file.name.length":
+		return &eval.ErrFieldReadOnly{Field: "create.file.name.length"}
+	case "create.file.path":
+		rv, ok := value.(string)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "CreateNewFile.File.PathnameStr"}
+		}
+		ev.CreateNewFile.File.PathnameStr = rv
+		return nil
+	case "create.file.path.length":
+		return &eval.ErrFieldReadOnly{Field: "create.file.path.length"}
+	case "create_key.registry.key_name":
+		rv, ok := value.(string)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "CreateRegistryKey.Registry.KeyName"}
+		}
+		ev.CreateRegistryKey.Registry.KeyName = rv
+		return nil
+	case "create_key.registry.key_name.length":
+		return &eval.ErrFieldReadOnly{Field: "create_key.registry.key_name.length"}
+	case "create_key.registry.key_path":
+		rv, ok := value.(string)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "CreateRegistryKey.Registry.KeyPath"}
+		}
+		ev.CreateRegistryKey.Registry.KeyPath = rv
+		return nil
+	case "create_key.registry.key_path.length":
+		return &eval.ErrFieldReadOnly{Field: "create_key.registry.key_path.length"}
+	case "delete.file.name":
+		rv, ok := value.(string)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "DeleteFile.File.BasenameStr"}
+		}
+		ev.DeleteFile.File.BasenameStr = rv
+		return nil
+	case "delete.file.name.length":
+		return &eval.ErrFieldReadOnly{Field: "delete.file.name.length"}
+	case "delete.file.path":
+		rv, ok := value.(string)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "DeleteFile.File.PathnameStr"}
+		}
+		ev.DeleteFile.File.PathnameStr = rv
+		return nil
+	case "delete.file.path.length":
+		return &eval.ErrFieldReadOnly{Field: "delete.file.path.length"}
+	case "delete_key.registry.key_name":
+		rv, ok := value.(string)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "DeleteRegistryKey.Registry.KeyName"}
+		}
+		ev.DeleteRegistryKey.Registry.KeyName = rv
+		return nil
+	case "delete_key.registry.key_name.length":
+		return &eval.ErrFieldReadOnly{Field: "delete_key.registry.key_name.length"}
+	case "delete_key.registry.key_path":
+		rv, ok := value.(string)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "DeleteRegistryKey.Registry.KeyPath"}
+		}
+		ev.DeleteRegistryKey.Registry.KeyPath = rv
+		return nil
+	case "delete_key.registry.key_path.length":
+		return &eval.ErrFieldReadOnly{Field: "delete_key.registry.key_path.length"}
 	case "event.timestamp":
 		rv, ok := value.(int)
 		if !ok {
@@ -73,6 +683,67 @@ func (ev *Event) SetFieldValue(field eval.Field, value interface{}) error {
 		}
 		ev.TimestampRaw = uint64(rv)
 		return nil
+	case "open_key.registry.key_name":
+		rv, ok := value.(string)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "OpenRegistryKey.Registry.KeyName"}
+		}
+		ev.OpenRegistryKey.Registry.KeyName = rv
+		return nil
+	case "open_key.registry.key_name.length":
+		return &eval.ErrFieldReadOnly{Field: "open_key.registry.key_name.length"}
+	case "open_key.registry.key_path":
+		rv, ok := value.(string)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "OpenRegistryKey.Registry.KeyPath"}
+		}
+		ev.OpenRegistryKey.Registry.KeyPath = rv
+		return nil
+	case "open_key.registry.key_path.length":
+		return &eval.ErrFieldReadOnly{Field: "open_key.registry.key_path.length"}
+	case "set_key_value.registry.key_name":
+		rv, ok := value.(string)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "SetRegistryKeyValue.Registry.KeyName"}
+		}
+		ev.SetRegistryKeyValue.Registry.KeyName = rv
+		return nil
+	case "set_key_value.registry.key_name.length":
+		return &eval.ErrFieldReadOnly{Field: "set_key_value.registry.key_name.length"}
+	case "set_key_value.registry.key_path":
+		rv, ok := value.(string)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "SetRegistryKeyValue.Registry.KeyPath"}
+		}
+		ev.SetRegistryKeyValue.Registry.KeyPath = rv
+		return nil
+	case "set_key_value.registry.key_path.length":
+		return &eval.ErrFieldReadOnly{Field: "set_key_value.registry.key_path.length"}
+	case "set_key_value.value_name":
+		rv, ok := value.(string)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "SetRegistryKeyValue.ValueName"}
+		}
+		ev.SetRegistryKeyValue.ValueName = rv
+		return nil
+	case "write.file.name":
+		rv, ok := value.(string)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "WriteFile.File.BasenameStr"}
+		}
+		ev.WriteFile.File.BasenameStr = rv
+		return nil
+	case "write.file.name.length":
+		return &eval.ErrFieldReadOnly{Field: "write.file.name.length"}
+	case "write.file.path":
+		rv, ok := value.(string)
+		if !ok {
+			return &eval.ErrValueTypeMismatch{Field: "WriteFile.File.PathnameStr"}
+		}
+		ev.WriteFile.File.PathnameStr = rv
+		return nil
+	case "write.file.path.length":
+		return &eval.ErrFieldReadOnly{Field: "write.file.path.length"}
 	}
 	return &eval.ErrFieldNotFound{Field: field}
 }
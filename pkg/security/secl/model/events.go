@@ -123,6 +123,20 @@ const (
 	CustomTruncatedParentsEventType
 	// CustomSelfTestEventType is the custom event used to report the results of a self test run
 	CustomSelfTestEventType
+	// CreateNewFileEventType is sent on Windows when a file is created
+	CreateNewFileEventType
+	// WriteFileEventType is sent on Windows when a file is written
+	WriteFileEventType
+	// DeleteFileEventType is sent on Windows when a file is deleted
+	DeleteFileEventType
+	// CreateRegistryKeyEventType is sent on Windows when a registry key is created
+	CreateRegistryKeyEventType
+	// OpenRegistryKeyEventType is sent on Windows when a registry key is opened
+	OpenRegistryKeyEventType
+	// SetRegistryKeyValueEventType is sent on Windows when the value of a registry key is set
+	SetRegistryKeyValueEventType
+	// DeleteRegistryKeyEventType is sent on Windows when a registry key is deleted
+	DeleteRegistryKeyEventType
 	// MaxAllEventType is used internally to get the maximum number of events.
 	MaxAllEventType
 )
@@ -222,6 +236,21 @@ func (t EventType) String() string {
 		return "truncated_parents"
 	case CustomSelfTestEventType:
 		return "self_test"
+
+	case CreateNewFileEventType:
+		return "create"
+	case WriteFileEventType:
+		return "write"
+	case DeleteFileEventType:
+		return "delete"
+	case CreateRegistryKeyEventType:
+		return "create_key"
+	case OpenRegistryKeyEventType:
+		return "open_key"
+	case SetRegistryKeyValueEventType:
+		return "set_key_value"
+	case DeleteRegistryKeyEventType:
+		return "delete_key"
 	default:
 		return "unknown"
 	}
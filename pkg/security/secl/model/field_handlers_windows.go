@@ -24,6 +24,13 @@ func (ev *Event) resolveFields(forADs bool) {
 	switch ev.GetEventType().String() {
 	case "":
 		_ = ev.FieldHandlers.ResolveEventTimestamp(ev)
+	case "create":
+	case "create_key":
+	case "delete":
+	case "delete_key":
+	case "open_key":
+	case "set_key_value":
+	case "write":
 	}
 }
 
@@ -15,6 +15,7 @@ import (
 	"path"
 	"path/filepath"
 	"reflect"
+	"runtime"
 	"strings"
 	"syscall"
 	"time"
@@ -102,7 +103,8 @@ func validatePath(field eval.Field, fieldValue eval.FieldValue) error {
 
 // ValidateField validates the value of a field
 func (m *Model) ValidateField(field eval.Field, fieldValue eval.FieldValue) error {
-	if strings.HasSuffix(field, "path") {
+	// the path constraints come from the resolution of the paths of the Linux probe
+	if strings.HasSuffix(field, "path") && runtime.GOOS != "windows" {
 		if err := validatePath(field, fieldValue); err != nil {
 			return err
 		}
@@ -264,6 +266,17 @@ type Event struct {
 	DNS  DNSEvent  `field:"dns" event:"dns" platform:"linux"`   // [7.36] [Network] A DNS request was sent
 	Bind BindEvent `field:"bind" event:"bind" platform:"linux"` // [7.37] [Network] [Experimental] A bind was executed
 
+	// windows fim events
+	CreateNewFile CreateNewFileEvent `field:"create" event:"create" platform:"windows"` // [7.45] [File] A file was created
+	WriteFile     WriteFileEvent     `field:"write" event:"write" platform:"windows"`   // [7.45] [File] A file was written
+	DeleteFile    DeleteFileEvent    `field:"delete" event:"delete" platform:"windows"` // [7.45] [File] A file was deleted
+
+	// windows registry events
+	CreateRegistryKey   CreateRegistryKeyEvent   `field:"create_key" event:"create_key" platform:"windows"`       // [7.45] [Registry] A registry key was created
+	OpenRegistryKey     OpenRegistryKeyEvent     `field:"open_key" event:"open_key" platform:"windows"`           // [7.45] [Registry] A registry key was opened
+	SetRegistryKeyValue SetRegistryKeyValueEvent `field:"set_key_value" event:"set_key_value" platform:"windows"` // [7.45] [Registry] The value of a registry key was set
+	DeleteRegistryKey   DeleteRegistryKeyEvent   `field:"delete_key" event:"delete_key" platform:"windows"`       // [7.45] [Registry] A registry key was deleted
+
 	// internal usage
 	Umount           UmountEvent           `field:"-" json:"-" platform:"linux"`
 	InvalidateDentry InvalidateDentryEvent `field:"-" json:"-" platform:"linux"`
@@ -530,6 +543,7 @@ type Process struct {
 	PIDContext
 
 	FileEvent FileEvent `field:"file,check:IsNotKworker"`
+	FileHash  string    `field:"file.hash,handler:ResolveProcessFileHash"` // SECLDoc[file.hash] Definition:`[Experimental] SHA256 hash of the executed file, computed only when the hash resolver is enabled` Example:`exec.file.hash == "ed7a51d8c4f4b9d3b7d5f1aa0a2d5b7de7c26d63e03e0bc4f4e7b22b6e7aa6f3"` Description:`Matches the execution of a file with a known SHA256 hash.`
 
 	ContainerID string `field:"container.id"` // SECLDoc[container.id] Definition:`Container ID`
 
@@ -1114,6 +1128,55 @@ type BindEvent struct {
 	AddrFamily uint16        `field:"addr.family"` // SECLDoc[addr.family] Definition:`Address family`
 }
 
+// FimFileEvent represents the file of a file integrity monitoring event on Windows
+type FimFileEvent struct {
+	FileObject  uint64 `field:"-"`                                                  // FileObject of the ETW event, used to match the events of the same file
+	PathnameStr string `field:"path,opts:length" op_override:"eval.WindowsPathCmp"` // SECLDoc[path] Definition:`File's path` Example:`create.file.path == "c:\cmdlog.txt"` Description:`Matches the creation of the file located at c:\cmdlog.txt`
+	BasenameStr string `field:"name,opts:length" op_override:"eval.WindowsPathCmp"` // SECLDoc[name] Definition:`File's basename` Example:`create.file.name == "cmd.bat"` Description:`Matches the creation of any file named cmd.bat.`
+}
+
+// CreateNewFileEvent represents the creation of a file on Windows
+type CreateNewFileEvent struct {
+	File FimFileEvent `field:"file"`
+}
+
+// WriteFileEvent represents a write to a file on Windows
+type WriteFileEvent struct {
+	File FimFileEvent `field:"file"`
+}
+
+// DeleteFileEvent represents the deletion of a file on Windows
+type DeleteFileEvent struct {
+	File FimFileEvent `field:"file"`
+}
+
+// RegistryEvent represents the registry key of a registry event on Windows
+type RegistryEvent struct {
+	KeyName string `field:"key_name,opts:length"`                                   // SECLDoc[key_name] Definition:`Registry's name`
+	KeyPath string `field:"key_path,opts:length" op_override:"eval.WindowsPathCmp"` // SECLDoc[key_path] Definition:`Registry's path` Example:`open_key.registry.key_path == "HKEY_LOCAL_MACHINE\SOFTWARE\Microsoft\Windows\CurrentVersion\Run"` Description:`Matches the opening of the key of the programs run at startup`
+}
+
+// CreateRegistryKeyEvent represents the creation of a registry key on Windows
+type CreateRegistryKeyEvent struct {
+	Registry RegistryEvent `field:"registry"`
+}
+
+// OpenRegistryKeyEvent represents the opening of a registry key on Windows
+type OpenRegistryKeyEvent struct {
+	Registry RegistryEvent `field:"registry"`
+}
+
+// SetRegistryKeyValueEvent represents the setting of the value of a registry key on Windows
+type SetRegistryKeyValueEvent struct {
+	Registry  RegistryEvent `field:"registry"`
+	ValueName string        `field:"value_name"` // SECLDoc[value_name] Definition:`Registry's value name`
+}
+
+// DeleteRegistryKeyEvent represents the deletion of a registry key on Windows
+type DeleteRegistryKeyEvent struct {
+	Registry RegistryEvent `field:"registry"`
+}
+
 // NetDevice represents a network device
 type NetDevice struct {
 	Name        string
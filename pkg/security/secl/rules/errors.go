@@ -48,6 +48,15 @@ var (
 	// ErrNoRuleSetsInEvaluationSet is returned when no rule sets were provided to instantiate an evaluation set
 	ErrNoRuleSetsInEvaluationSet = errors.New("no rule sets provided to instantiate an evaluation set")
 
+	// ErrExceptionWithoutRuleID is returned when an exception doesn't reference a rule
+	ErrExceptionWithoutRuleID = errors.New("no rule ID")
+
+	// ErrExceptionWithoutExpression is returned when an exception has no expression
+	ErrExceptionWithoutExpression = errors.New("no exception expression")
+
+	// ErrExceptionUnknownRule is returned when the rule referenced by an exception doesn't exist
+	ErrExceptionUnknownRule = errors.New("unknown rule")
+
 	// ErrCannotChangeTagAfterLoading is returned when an attempt was made to change the tag on a ruleset that already has rules loaded
 	ErrCannotChangeTagAfterLoading = errors.New("cannot change tag on a rule set that already has rules loaded")
 )
@@ -108,6 +117,16 @@ func (e ErrPolicyLoad) Error() string {
 	return fmt.Sprintf("policy file error `%s`: %s", e.Name, e.Err)
 }
 
+// ErrPolicyValidation is returned when a policy file contains keys that don't match the policy schema
+type ErrPolicyValidation struct {
+	// Errors are formatted as `line <line>: <error>`
+	Errors []string
+}
+
+func (e ErrPolicyValidation) Error() string {
+	return fmt.Sprintf("invalid policy: %s", strings.Join(e.Errors, ", "))
+}
+
 // ErrMacroLoad is on macro definition error
 type ErrMacroLoad struct {
 	Definition *MacroDefinition
@@ -128,6 +147,16 @@ func (e ErrRuleLoad) Error() string {
 	return fmt.Sprintf("rule `%s` error: %s", e.Definition.ID, e.Err)
 }
 
+// ErrExceptionLoad is on exception definition error
+type ErrExceptionLoad struct {
+	Definition *ExceptionDefinition
+	Err        error
+}
+
+func (e ErrExceptionLoad) Error() string {
+	return fmt.Sprintf("exception of rule `%s` error: %s", e.Definition.RuleID, e.Err)
+}
+
 // RuleLoadErrType defines an rule error type
 type RuleLoadErrType string
 
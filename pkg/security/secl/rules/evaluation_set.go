@@ -105,6 +105,24 @@ func (es *EvaluationSet) LoadPolicies(loader *PolicyLoader, opts PolicyLoaderOpt
 		}
 	}
 
+	// exceptions are applied once all the rules are merged, whatever the order of the policies
+	for _, policy := range policies {
+		for _, exception := range policy.Exceptions {
+			found := false
+			for _, index := range rulesIndex {
+				if rule := index[exception.RuleID]; rule != nil {
+					found = true
+					if rule.Expression != "" {
+						rule.AddException(exception)
+					}
+				}
+			}
+			if !found {
+				errs = multierror.Append(errs, &ErrExceptionLoad{Definition: exception, Err: ErrExceptionUnknownRule})
+			}
+		}
+	}
+
 	for ruleSetTagValue, rs := range es.RuleSets {
 		for rulesIndexTagValue, ruleList := range rules {
 			if rulesIndexTagValue == ruleSetTagValue {
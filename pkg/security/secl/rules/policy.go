@@ -3,9 +3,12 @@
 // This product includes software developed at Datadog (https://www.datadoghq.com/).
 // Copyright 2016-present Datadog, Inc.
 
+//go:generate go run github.com/DataDog/datadog-agent/pkg/security/secl/compiler/generators/policy_schema -output ../../../../docs/cloud-workload-security/policy.schema.json
+
 package rules
 
 import (
+	"bytes"
 	"fmt"
 	"github.com/DataDog/datadog-agent/pkg/security/secl/validators"
 	"github.com/hashicorp/go-multierror"
@@ -15,9 +18,12 @@ import (
 
 // PolicyDef represents a policy file definition
 type PolicyDef struct {
-	Version string             `yaml:"version"`
-	Rules   []*RuleDefinition  `yaml:"rules"`
-	Macros  []*MacroDefinition `yaml:"macros"`
+	Version    string                 `yaml:"version"`
+	Tags       map[string]string      `yaml:"tags"`
+	Attributes map[string]string      `yaml:"attributes"`
+	Rules      []*RuleDefinition      `yaml:"rules"`
+	Macros     []*MacroDefinition     `yaml:"macros"`
+	Exceptions []*ExceptionDefinition `yaml:"exceptions"`
 }
 
 // Policy represents a policy file which is composed of a list of rules and macros
@@ -25,8 +31,15 @@ type Policy struct {
 	Name    string
 	Source  string
 	Version string
-	Rules   []*RuleDefinition
-	Macros  []*MacroDefinition
+	// Tags are added to the tags of every rule of the policy, rule tags take precedence
+	Tags map[string]string
+	// Attributes are added to every event generated by the rules of the policy
+	Attributes map[string]string
+	Rules      []*RuleDefinition
+	Macros     []*MacroDefinition
+	// Exceptions are applied to the rules of every policy, so that the tuning of
+	// a set of rules can be shipped in separate policy files
+	Exceptions []*ExceptionDefinition
 }
 
 // AddMacro add a macro to the policy
@@ -34,19 +47,37 @@ func (p *Policy) AddMacro(def *MacroDefinition) {
 	p.Macros = append(p.Macros, def)
 }
 
+// AddException adds an exception to the policy
+func (p *Policy) AddException(def *ExceptionDefinition) {
+	def.Policy = p
+	p.Exceptions = append(p.Exceptions, def)
+}
+
 // AddRule adds a rule to the policy
 func (p *Policy) AddRule(def *RuleDefinition) {
 	def.Policy = p
 	p.Rules = append(p.Rules, def)
+
+	for k, v := range p.Tags {
+		if _, exists := def.Tags[k]; exists {
+			continue
+		}
+		if def.Tags == nil {
+			def.Tags = make(map[string]string, len(p.Tags))
+		}
+		def.Tags[k] = v
+	}
 }
 
 func parsePolicyDef(name string, source string, def *PolicyDef, macroFilters []MacroFilter, ruleFilters []RuleFilter) (*Policy, error) {
 	var errs *multierror.Error
 
 	policy := &Policy{
-		Name:    name,
-		Source:  source,
-		Version: def.Version,
+		Name:       name,
+		Source:     source,
+		Version:    def.Version,
+		Tags:       def.Tags,
+		Attributes: def.Attributes,
 	}
 
 MACROS:
@@ -124,6 +155,19 @@ RULES:
 		policy.AddRule(ruleDef)
 	}
 
+	for _, exceptionDef := range def.Exceptions {
+		if exceptionDef.RuleID == "" {
+			errs = multierror.Append(errs, &ErrExceptionLoad{Definition: exceptionDef, Err: ErrExceptionWithoutRuleID})
+			continue
+		}
+		if exceptionDef.Expression == "" {
+			errs = multierror.Append(errs, &ErrExceptionLoad{Definition: exceptionDef, Err: ErrExceptionWithoutExpression})
+			continue
+		}
+
+		policy.AddException(exceptionDef)
+	}
+
 LOOP:
 	for _, s := range skipped {
 		// For every skipped rule, if it doesn't match an ID of a policy rule, add an error.
@@ -139,14 +183,41 @@ LOOP:
 	return policy, errs.ErrorOrNil()
 }
 
-// LoadPolicy load a policy
+// LoadPolicy load a policy. Unknown keys of the policy file are reported as errors, but don't
+// prevent the policy from being loaded, so that policies written for newer agents can be loaded.
 func LoadPolicy(name string, source string, reader io.Reader, macroFilters []MacroFilter, ruleFilters []RuleFilter) (*Policy, error) {
 	var def PolicyDef
 
-	decoder := yaml.NewDecoder(reader)
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, &ErrPolicyLoad{Name: name, Err: err}
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
 	if err := decoder.Decode(&def); err != nil {
 		return nil, &ErrPolicyLoad{Name: name, Err: err}
 	}
 
-	return parsePolicyDef(name, source, &def, macroFilters, ruleFilters)
+	policy, err := parsePolicyDef(name, source, &def, macroFilters, ruleFilters)
+
+	if verr := validatePolicy(data); verr != nil {
+		err = multierror.Append(&ErrPolicyLoad{Name: name, Err: verr}, err).ErrorOrNil()
+	}
+
+	return policy, err
+}
+
+// validatePolicy decodes the policy strictly, to report the keys which don't match any field
+// of the definitions, e.g. typos, with their line numbers
+func validatePolicy(data []byte) error {
+	var def PolicyDef
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.SetStrict(true)
+	if err := decoder.Decode(&def); err != nil {
+		if typeErr, ok := err.(*yaml.TypeError); ok {
+			return &ErrPolicyValidation{Errors: typeErr.Errors}
+		}
+		return err
+	}
+	return nil
 }
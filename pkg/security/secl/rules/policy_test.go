@@ -9,10 +9,13 @@
 package rules
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"syscall"
 	"testing"
+	"time"
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/hashicorp/go-multierror"
@@ -146,6 +149,80 @@ func TestRuleMerge(t *testing.T) {
 	}
 }
 
+func TestRuleExceptions(t *testing.T) {
+	testPolicy := &PolicyDef{
+		Rules: []*RuleDefinition{{
+			ID:         "test_rule",
+			Expression: `open.file.path =~ "/tmp/*"`,
+		}},
+	}
+
+	exceptionsPolicy := &PolicyDef{
+		Exceptions: []*ExceptionDefinition{
+			{
+				RuleID:     "test_rule",
+				Expression: `process.comm == "backup"`,
+			},
+			{
+				RuleID:     "test_rule",
+				Expression: `open.file.path == "/tmp/cache"`,
+			},
+		},
+	}
+
+	tmpDir := t.TempDir()
+
+	if err := savePolicy(filepath.Join(tmpDir, "test.policy"), testPolicy); err != nil {
+		t.Fatal(err)
+	}
+
+	// the exceptions are applied whatever the order in which the policies are loaded
+	if err := savePolicy(filepath.Join(tmpDir, "a_exceptions.policy"), exceptionsPolicy); err != nil {
+		t.Fatal(err)
+	}
+
+	provider, err := NewPoliciesDirProvider(tmpDir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loader := NewPolicyLoader(provider)
+
+	evaluationSet, _ := newEvaluationSet([]eval.RuleSetTagValue{DefaultRuleSetTagValue})
+	if errs := evaluationSet.LoadPolicies(loader, PolicyLoaderOpts{}); errs.ErrorOrNil() != nil {
+		t.Fatal(errs)
+	}
+
+	rule := evaluationSet.RuleSets[DefaultRuleSetTagValue].GetRules()["test_rule"]
+	if rule == nil {
+		t.Fatal("failed to find test_rule in ruleset")
+	}
+	assert.Equal(t, `((open.file.path =~ "/tmp/*") && !(process.comm == "backup")) && !(open.file.path == "/tmp/cache")`, rule.Expression)
+
+	for _, tt := range []struct {
+		path     string
+		comm     string
+		expected bool
+	}{
+		{path: "/tmp/test", comm: "vim", expected: true},
+		{path: "/tmp/test", comm: "backup", expected: false},
+		{path: "/tmp/cache", comm: "vim", expected: false},
+	} {
+		event := model.NewDefaultEvent()
+		event.SetFieldValue("open.file.path", tt.path)
+		event.SetFieldValue("process.comm", tt.comm)
+		assert.Equal(t, tt.expected, rule.Eval(eval.NewContext(event)), "path %s, comm %s", tt.path, tt.comm)
+	}
+
+	exceptionsPolicy.Exceptions[0].RuleID = "unknown_rule"
+	if err := savePolicy(filepath.Join(tmpDir, "a_exceptions.policy"), exceptionsPolicy); err != nil {
+		t.Fatal(err)
+	}
+
+	if errs := evaluationSet.LoadPolicies(loader, PolicyLoaderOpts{}); errs.ErrorOrNil() == nil {
+		t.Error("expected unknown rule error")
+	}
+}
+
 func TestActionSetVariable(t *testing.T) {
 	testPolicy := &PolicyDef{
 		Rules: []*RuleDefinition{{
@@ -283,6 +360,72 @@ func TestActionSetVariable(t *testing.T) {
 	assert.Equal(t, scopedVariables.Len(), 0)
 }
 
+func TestActionSetRateVariable(t *testing.T) {
+	testPolicy := &PolicyDef{
+		Rules: []*RuleDefinition{{
+			ID:         "test_rule",
+			Expression: `open.file.path == "/tmp/test"`,
+			Actions: []ActionDefinition{{
+				Set: &SetDefinition{
+					Name:   "counter",
+					Value:  1,
+					Append: true,
+					Window: time.Minute,
+				},
+			}},
+		}, {
+			ID:         "test_rule2",
+			Expression: `open.file.path == "/tmp/test2" && ${counter} >= 2`,
+		}},
+	}
+
+	tmpDir := t.TempDir()
+
+	if err := savePolicy(filepath.Join(tmpDir, "test.policy"), testPolicy); err != nil {
+		t.Fatal(err)
+	}
+
+	provider, err := NewPoliciesDirProvider(tmpDir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loader := NewPolicyLoader(provider)
+
+	evaluationSet, _ := newEvaluationSet([]eval.RuleSetTagValue{DefaultRuleSetTagValue})
+	if errs := evaluationSet.LoadPolicies(loader, PolicyLoaderOpts{}); errs.ErrorOrNil() != nil {
+		t.Fatal(errs)
+	}
+	rs := evaluationSet.RuleSets[DefaultRuleSetTagValue]
+
+	event := model.NewDefaultEvent()
+	event.(*model.Event).Type = uint32(model.FileOpenEventType)
+	event.SetFieldValue("open.flags", syscall.O_RDONLY)
+
+	for i, tt := range []struct {
+		path     string
+		expected bool
+	}{
+		{path: "/tmp/test", expected: true},
+		{path: "/tmp/test2", expected: false},
+		{path: "/tmp/test", expected: true},
+		{path: "/tmp/test2", expected: true},
+	} {
+		event.SetFieldValue("open.file.path", tt.path)
+		assert.Equal(t, tt.expected, rs.Evaluate(event), "event %d", i)
+	}
+}
+
+func TestActionSetRateVariableInvalid(t *testing.T) {
+	for _, set := range []*SetDefinition{
+		{Name: "counter", Value: "value", Window: time.Minute},
+		{Name: "counter", Value: 1, Window: -time.Minute},
+		{Name: "counter", Value: 1, Window: time.Minute, Scope: "process"},
+	} {
+		action := ActionDefinition{Set: set}
+		assert.Error(t, action.Check(), "%+v", set)
+	}
+}
+
 func TestActionSetVariableConflict(t *testing.T) {
 	testPolicy := &PolicyDef{
 		Rules: []*RuleDefinition{{
@@ -692,3 +835,177 @@ func TestActionSetVariableInvalid(t *testing.T) {
 		}
 	})
 }
+
+func TestActionEnrich(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		testPolicy := &PolicyDef{
+			Rules: []*RuleDefinition{{
+				ID:         "test_rule",
+				Expression: `open.file.path == "/tmp/test"`,
+				Actions: []ActionDefinition{{
+					Enrich: &EnrichDefinition{
+						MemoryMaps: true,
+					},
+				}, {
+					Enrich: &EnrichDefinition{
+						OpenFiles: true,
+					},
+				}},
+			}},
+		}
+
+		es, err := loadPolicyIntoProbeEvaluationRuleSet(t, testPolicy, PolicyLoaderOpts{})
+		if err.ErrorOrNil() != nil {
+			t.Fatal(err)
+		}
+
+		rule := es.RuleSets[DefaultRuleSetTagValue].GetRules()["test_rule"]
+		assert.NotNil(t, rule)
+		assert.Equal(t, &EnrichDefinition{MemoryMaps: true, OpenFiles: true}, rule.Definition.GetEnrichDefinition())
+	})
+
+	t.Run("nothing-to-collect", func(t *testing.T) {
+		testPolicy := &PolicyDef{
+			Rules: []*RuleDefinition{{
+				ID:         "test_rule",
+				Expression: `open.file.path == "/tmp/test"`,
+				Actions: []ActionDefinition{{
+					Enrich: &EnrichDefinition{},
+				}},
+			}},
+		}
+
+		if _, err := loadPolicyIntoProbeEvaluationRuleSet(t, testPolicy, PolicyLoaderOpts{}); err == nil {
+			t.Error("policy should fail to load")
+		} else {
+			t.Log(err)
+		}
+	})
+
+	t.Run("both-set-and-enrich", func(t *testing.T) {
+		testPolicy := &PolicyDef{
+			Rules: []*RuleDefinition{{
+				ID:         "test_rule",
+				Expression: `open.file.path == "/tmp/test"`,
+				Actions: []ActionDefinition{{
+					Set: &SetDefinition{
+						Name:  "var1",
+						Value: true,
+					},
+					Enrich: &EnrichDefinition{
+						OpenFiles: true,
+					},
+				}},
+			}},
+		}
+
+		if _, err := loadPolicyIntoProbeEvaluationRuleSet(t, testPolicy, PolicyLoaderOpts{}); err == nil {
+			t.Error("policy should fail to load")
+		} else {
+			t.Log(err)
+		}
+	})
+}
+
+func TestPolicyTagsAndAttributes(t *testing.T) {
+	testPolicy := &PolicyDef{
+		Tags: map[string]string{
+			"team":     "security",
+			"severity": "low",
+		},
+		Attributes: map[string]string{
+			"policy_pack": "core",
+		},
+		Rules: []*RuleDefinition{{
+			ID:         "test_rule",
+			Expression: `open.file.path == "/tmp/test"`,
+		}, {
+			ID:         "test_rule_with_tags",
+			Expression: `open.file.path == "/tmp/test2"`,
+			Tags: map[string]string{
+				"severity": "high",
+			},
+		}},
+	}
+
+	es, err := loadPolicyIntoProbeEvaluationRuleSet(t, testPolicy, PolicyLoaderOpts{})
+	if err.ErrorOrNil() != nil {
+		t.Fatal(err)
+	}
+
+	rules := es.RuleSets[DefaultRuleSetTagValue].GetRules()
+
+	rule := rules["test_rule"]
+	if assert.NotNil(t, rule) {
+		assert.Equal(t, map[string]string{"team": "security", "severity": "low"}, rule.Definition.Tags)
+		assert.ElementsMatch(t, []string{"team:security", "severity:low"}, rule.Tags)
+		assert.Equal(t, map[string]string{"policy_pack": "core"}, rule.Definition.Policy.Attributes)
+	}
+
+	rule = rules["test_rule_with_tags"]
+	if assert.NotNil(t, rule) {
+		assert.Equal(t, map[string]string{"team": "security", "severity": "high"}, rule.Definition.Tags)
+		assert.ElementsMatch(t, []string{"team:security", "severity:high"}, rule.Tags)
+	}
+}
+
+func TestLoadPolicyValidation(t *testing.T) {
+	policyYaml := `---
+version: 1.2.3
+rules:
+  - id: test_rule
+    expresion: open.file.path == "/tmp/test"
+  - id: test_rule2
+    expression: open.file.path == "/tmp/test2"
+    tag:
+      severity: high
+`
+
+	policy, err := LoadPolicy("test.policy", "file", strings.NewReader(policyYaml), nil, nil)
+	if assert.NotNil(t, policy) {
+		// the policy is loaded despite the unknown keys
+		assert.Len(t, policy.Rules, 1)
+		assert.Equal(t, "test_rule2", policy.Rules[0].ID)
+	}
+
+	var merr *multierror.Error
+	if assert.ErrorAs(t, err, &merr) && assert.Len(t, merr.Errors, 2) {
+		assert.Equal(t, &ErrPolicyLoad{Name: "test.policy", Err: &ErrPolicyValidation{Errors: []string{
+			"line 5: field expresion not found in type rules.RuleDefinition",
+			"line 8: field tag not found in type rules.RuleDefinition",
+		}}}, merr.Errors[0])
+		// the rule without expression is still reported
+		assert.ErrorContains(t, merr.Errors[1], "rule `test_rule` error: no rule expression")
+	}
+
+	policy, err = LoadPolicy("test.policy", "file", strings.NewReader(`{"rules": [{"id": "test_rule", "disabled": "maybe"}]}`), nil, nil)
+	assert.Nil(t, policy)
+	assert.ErrorContains(t, err, "line 1: cannot unmarshal !!str `maybe` into bool")
+}
+
+func TestPolicySchema(t *testing.T) {
+	data, err := PolicySchema()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var schema struct {
+		Properties struct {
+			Rules struct {
+				Items struct {
+					Properties           map[string]map[string]interface{} `json:"properties"`
+					AdditionalProperties bool                              `json:"additionalProperties"`
+				} `json:"items"`
+			} `json:"rules"`
+		} `json:"properties"`
+	}
+	assert.NoError(t, json.Unmarshal(data, &schema))
+
+	rule := schema.Properties.Rules.Items
+	assert.False(t, rule.AdditionalProperties)
+	assert.Equal(t, "string", rule.Properties["expression"]["type"])
+	assert.Equal(t, "boolean", rule.Properties["disabled"]["type"])
+	assert.Equal(t, "string", rule.Properties["every"]["type"])
+	assert.Equal(t, "array", rule.Properties["actions"]["type"])
+	assert.NotContains(t, rule.Properties, "policy")
+}
@@ -88,7 +88,7 @@ type RuleDefinition struct {
 	Combine                CombinePolicy      `yaml:"combine"`
 	Actions                []ActionDefinition `yaml:"actions"`
 	Every                  time.Duration      `yaml:"every"`
-	Policy                 *Policy
+	Policy                 *Policy            `yaml:"-"`
 }
 
 // GetTag returns the tag value associated with a tag key
@@ -100,6 +100,22 @@ func (rd *RuleDefinition) GetTag(tagKey string) (string, bool) {
 	return "", false
 }
 
+// GetEnrichDefinition returns the union of the 'enrich' actions of the rule, or nil if it has none
+func (rd *RuleDefinition) GetEnrichDefinition() *EnrichDefinition {
+	var enrich *EnrichDefinition
+	for _, action := range rd.Actions {
+		if action.Enrich == nil {
+			continue
+		}
+		if enrich == nil {
+			enrich = &EnrichDefinition{}
+		}
+		enrich.MemoryMaps = enrich.MemoryMaps || action.Enrich.MemoryMaps
+		enrich.OpenFiles = enrich.OpenFiles || action.Enrich.OpenFiles
+	}
+	return enrich
+}
+
 // MergeWith merges rule rd2 into rd
 func (rd *RuleDefinition) MergeWith(rd2 *RuleDefinition) error {
 	switch rd2.Combine {
@@ -114,15 +130,41 @@ func (rd *RuleDefinition) MergeWith(rd2 *RuleDefinition) error {
 	return nil
 }
 
+// AddException excludes the events matching the expression of the exception from the rule
+func (rd *RuleDefinition) AddException(ed *ExceptionDefinition) {
+	rd.Expression = fmt.Sprintf("(%s) && !(%s)", rd.Expression, ed.Expression)
+}
+
+// ExceptionDefinition holds the definition of a rule exception. The events matching the
+// expression of the exception are excluded from the rule with the given ID.
+type ExceptionDefinition struct {
+	RuleID      RuleID  `yaml:"rule_id"`
+	Expression  string  `yaml:"expression"`
+	Description string  `yaml:"description"`
+	Policy      *Policy `yaml:"-"`
+}
+
 // ActionDefinition describes a rule action section
 type ActionDefinition struct {
-	Set *SetDefinition `yaml:"set"`
+	Set    *SetDefinition    `yaml:"set"`
+	Enrich *EnrichDefinition `yaml:"enrich"`
 }
 
 // Check returns an error if the action in invalid
 func (a *ActionDefinition) Check() error {
-	if a.Set == nil {
-		return errors.New("missing 'set' section in action")
+	if a.Set == nil && a.Enrich == nil {
+		return errors.New("missing 'set' or 'enrich' section in action")
+	}
+
+	if a.Set != nil && a.Enrich != nil {
+		return errors.New("only one of 'set' or 'enrich' can be specified in an action")
+	}
+
+	if a.Enrich != nil {
+		if !a.Enrich.MemoryMaps && !a.Enrich.OpenFiles {
+			return errors.New("either 'memory_maps' or 'open_files' must be enabled")
+		}
+		return nil
 	}
 
 	if a.Set.Name == "" {
@@ -133,19 +175,41 @@ func (a *ActionDefinition) Check() error {
 		return errors.New("either 'value' or 'field' must be specified")
 	}
 
+	if a.Set.Window != 0 {
+		if a.Set.Window < 0 {
+			return errors.New("'window' must be positive")
+		}
+		if _, ok := a.Set.Value.(int); !ok {
+			return errors.New("'window' requires an integer 'value'")
+		}
+		if a.Set.Scope != "" {
+			return errors.New("'window' isn't supported for scoped variables")
+		}
+	}
+
 	return nil
 }
 
 // Scope describes the scope variables
 type Scope string
 
-// SetDefinition describes the 'set' section of a rule action
+// SetDefinition describes the 'set' section of a rule action. When a window is specified,
+// the variable is a rate counter evaluating to the sum of the values set during the window.
 type SetDefinition struct {
-	Name   string      `yaml:"name"`
-	Value  interface{} `yaml:"value"`
-	Field  string      `yaml:"field"`
-	Append bool        `yaml:"append"`
-	Scope  Scope       `yaml:"scope"`
+	Name   string        `yaml:"name"`
+	Value  interface{}   `yaml:"value"`
+	Field  string        `yaml:"field"`
+	Append bool          `yaml:"append"`
+	Scope  Scope         `yaml:"scope"`
+	Window time.Duration `yaml:"window"`
+}
+
+// EnrichDefinition describes the 'enrich' section of a rule action. When the
+// rule matches, the requested data about the process that triggered the event
+// is collected and attached to the generated security event.
+type EnrichDefinition struct {
+	MemoryMaps bool `yaml:"memory_maps"`
+	OpenFiles  bool `yaml:"open_files"`
 }
 
 // Rule describes a rule of a ruleset
@@ -180,6 +244,8 @@ type RuleSet struct {
 	fields []string
 	logger log.Logger
 	pool   *eval.ContextPool
+	// simulation holds the state of the rule simulations
+	simulation simulationCache
 }
 
 // ListRuleIDs returns the list of RuleIDs from the ruleset
@@ -300,7 +366,10 @@ func (rs *RuleSet) populateFieldsWithRuleActionsData(policyRules []*RuleDefiniti
 				if action.Set.Value != nil {
 					switch value := action.Set.Value.(type) {
 					case int:
-						action.Set.Value = []int{value}
+						// rate counters sum integers
+						if action.Set.Window == 0 {
+							action.Set.Value = []int{value}
+						}
 					case string:
 						action.Set.Value = []string{value}
 					case []interface{}:
@@ -344,26 +413,30 @@ func (rs *RuleSet) populateFieldsWithRuleActionsData(policyRules []*RuleDefiniti
 				var variable eval.VariableValue
 				var variableProvider VariableProvider
 
-				if action.Set.Scope != "" {
-					stateScopeBuilder := rs.opts.StateScopes[action.Set.Scope]
-					if stateScopeBuilder == nil {
-						errs = multierror.Append(errs, fmt.Errorf("invalid scope '%s'", action.Set.Scope))
-						continue
-					}
+				if action.Set.Window != 0 {
+					variable = eval.NewMutableRateVariable(action.Set.Window)
+				} else {
+					if action.Set.Scope != "" {
+						stateScopeBuilder := rs.opts.StateScopes[action.Set.Scope]
+						if stateScopeBuilder == nil {
+							errs = multierror.Append(errs, fmt.Errorf("invalid scope '%s'", action.Set.Scope))
+							continue
+						}
 
-					if _, found := rs.scopedVariables[action.Set.Scope]; !found {
-						rs.scopedVariables[action.Set.Scope] = stateScopeBuilder()
-					}
+						if _, found := rs.scopedVariables[action.Set.Scope]; !found {
+							rs.scopedVariables[action.Set.Scope] = stateScopeBuilder()
+						}
 
-					variableProvider = rs.scopedVariables[action.Set.Scope]
-				} else {
-					variableProvider = &rs.globalVariables
-				}
+						variableProvider = rs.scopedVariables[action.Set.Scope]
+					} else {
+						variableProvider = &rs.globalVariables
+					}
 
-				variable, err := variableProvider.GetVariable(action.Set.Name, variableValue)
-				if err != nil {
-					errs = multierror.Append(errs, fmt.Errorf("invalid type '%s' for variable '%s': %w", reflect.TypeOf(action.Set.Value), action.Set.Name, err))
-					continue
+					var err error
+					if variable, err = variableProvider.GetVariable(action.Set.Name, variableValue); err != nil {
+						errs = multierror.Append(errs, fmt.Errorf("invalid type '%s' for variable '%s': %w", reflect.TypeOf(action.Set.Value), action.Set.Name, err))
+						continue
+					}
 				}
 
 				if existingVariable := rs.evalOpts.VariableStore.Get(varName); existingVariable != nil && reflect.TypeOf(variable) != reflect.TypeOf(existingVariable) {
@@ -9,6 +9,7 @@
 package rules
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
 	"strings"
@@ -589,3 +590,26 @@ func TestGetRuleEventType(t *testing.T) {
 		t.Fatal("unexpected event type")
 	}
 }
+
+func TestRuleSetPlatformUnsupportedField(t *testing.T) {
+	rule := eval.NewRule("aaa", `create.file.path == "c:\\windows\\cmdlog.txt"`, &eval.Opts{})
+
+	err := rule.GenEvaluator(&model.Model{}, ast.NewParsingContext())
+	if err == nil {
+		t.Fatal("a windows field shouldn't compile on linux")
+	}
+
+	var platformErr *eval.ErrFieldNotSupportedOnPlatform
+	if !errors.As(err, &platformErr) {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if platformErr.Field != "create.file.path" || platformErr.Platform != "linux" {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	rule = eval.NewRule("bbb", `open.file.unknown == "test"`, &eval.Opts{})
+	err = rule.GenEvaluator(&model.Model{}, ast.NewParsingContext())
+	if err == nil || errors.As(err, &platformErr) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
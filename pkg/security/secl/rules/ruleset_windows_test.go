@@ -0,0 +1,75 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build windows
+// +build windows
+
+package rules
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/security/secl/compiler/ast"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/compiler/eval"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/model"
+)
+
+func newWindowsRuleSet(t *testing.T, ruleDefs ...*RuleDefinition) *RuleSet {
+	ruleOpts, evalOpts := NewEvalOpts(map[eval.EventType]bool{"*": true})
+	rs := NewRuleSet(&model.Model{}, model.NewDefaultEvent, ruleOpts, evalOpts)
+	if err := rs.AddRules(ast.NewParsingContext(), ruleDefs); err != nil {
+		t.Fatal(err)
+	}
+	return rs
+}
+
+func TestRuleSetWindowsEvents(t *testing.T) {
+	rs := newWindowsRuleSet(t,
+		&RuleDefinition{ID: "file", Expression: `create.file.path == "C:\\Windows\\Temp\\cmdlog.txt"`},
+		&RuleDefinition{ID: "registry", Expression: `set_key_value.registry.key_path == "HKEY_LOCAL_MACHINE\\SOFTWARE\\Microsoft\\Windows\\CurrentVersion\\Run" && set_key_value.value_name == "updater"`},
+	)
+
+	event := model.NewDefaultEvent().(*model.Event)
+	event.Type = uint32(model.CreateNewFileEventType)
+	event.CreateNewFile.File.PathnameStr = `c:\windows\temp\CMDLOG.txt`
+	if !rs.Evaluate(event) {
+		t.Fatal("windows paths should be case insensitive")
+	}
+
+	event = model.NewDefaultEvent().(*model.Event)
+	event.Type = uint32(model.SetRegistryKeyValueEventType)
+	event.SetRegistryKeyValue.Registry.KeyPath = `HKEY_LOCAL_MACHINE\SOFTWARE\Microsoft\Windows\CurrentVersion\Run`
+	event.SetRegistryKeyValue.ValueName = "other"
+	if rs.Evaluate(event) {
+		t.Fatal("the value name shouldn't match")
+	}
+	event.SetRegistryKeyValue.ValueName = "updater"
+	if !rs.Evaluate(event) {
+		t.Fatal("the registry rule should match")
+	}
+}
+
+func TestRuleSetWindowsLinuxOnlyField(t *testing.T) {
+	ruleOpts, evalOpts := NewEvalOpts(map[eval.EventType]bool{"*": true})
+	rs := NewRuleSet(&model.Model{}, model.NewDefaultEvent, ruleOpts, evalOpts)
+
+	err := rs.AddRules(ast.NewParsingContext(), []*RuleDefinition{
+		{ID: "linux", Expression: `open.file.path == "/etc/passwd"`},
+	})
+	if err == nil {
+		t.Fatal("a linux field shouldn't load on windows")
+	}
+
+	var ruleLoadErr *ErrRuleLoad
+	if len(err.Errors) != 1 || !errors.As(err.Errors[0], &ruleLoadErr) {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var platformErr *eval.ErrFieldNotSupportedOnPlatform
+	if !errors.As(ruleLoadErr.Err, &platformErr) || platformErr.Platform != "windows" {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
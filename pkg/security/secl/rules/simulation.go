@@ -0,0 +1,232 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package rules
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/DataDog/datadog-agent/pkg/security/secl/compiler/ast"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/compiler/eval"
+	"github.com/DataDog/datadog-agent/pkg/security/secl/model"
+)
+
+// EventData defines the structure used to represent an event
+type EventData struct {
+	Type   eval.EventType
+	Values map[string]interface{}
+}
+
+// NewEventFromJSON decodes an event from the JSON representation of its EventData, such as
+// {"type": "open", "values": {"open.file.path": "/etc/passwd", "process.uid": 0}}
+func NewEventFromJSON(r io.Reader) (eval.Event, error) {
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+
+	var eventData EventData
+	if err := decoder.Decode(&eventData); err != nil {
+		return nil, err
+	}
+
+	kind := model.ParseEvalEventType(eventData.Type)
+	if kind == model.UnknownEventType {
+		return nil, errors.New("unknown event type")
+	}
+
+	m := &model.Model{}
+	event := m.NewDefaultEventWithType(kind)
+	event.Init()
+
+	for k, v := range eventData.Values {
+		switch v := v.(type) {
+		case json.Number:
+			value, err := v.Int64()
+			if err != nil {
+				return nil, err
+			}
+			if err := event.SetFieldValue(k, int(value)); err != nil {
+				return nil, err
+			}
+		default:
+			if err := event.SetFieldValue(k, v); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return event, nil
+}
+
+// SimulationReport describes the rules matching an event, and why
+type SimulationReport struct {
+	EventType eval.EventType
+	// Matched are the IDs of the rules matching the event
+	Matched []eval.RuleID
+	// Rules are the evaluations of the rules of the event type
+	Rules []RuleSimulation
+}
+
+// NewSimulationReport evaluates the event against the rules of the rule set, and reports the rules which would match
+func NewSimulationReport(rs *RuleSet, event eval.Event) *SimulationReport {
+	report := &SimulationReport{
+		EventType: event.GetType(),
+		Rules:     rs.Simulate(event),
+	}
+	for _, rule := range report.Rules {
+		if rule.Matched {
+			report.Matched = append(report.Matched, rule.RuleID)
+		}
+	}
+	return report
+}
+
+// RuleSimulation describes the evaluation of an event against a rule
+type RuleSimulation struct {
+	RuleID     eval.RuleID
+	Expression string
+	Matched    bool
+	// SubExpressions are the evaluations of the sub-expressions joined by the boolean operators at the top level of
+	// the rule, explaining why the rule matched or not
+	SubExpressions []SubExpressionSimulation `json:",omitempty"`
+}
+
+// SubExpressionSimulation describes the evaluation of an event against a sub-expression of a rule
+type SubExpressionSimulation struct {
+	Expression string
+	// Operator is the boolean operator joining the sub-expression to the next one, if any
+	Operator string `json:",omitempty"`
+	Matched  bool
+	Error    string `json:",omitempty"`
+}
+
+// simulationCache holds the sub-expressions of the rules compiled for the simulations, so that they are compiled once
+// for the lifetime of the rule set
+type simulationCache struct {
+	sync.Mutex
+	parsingContext *ast.ParsingContext
+	subExpressions map[eval.RuleID][]compiledSubExpression
+}
+
+// compiledSubExpression is a sub-expression of a rule, along with its rule, nil when it couldn't be compiled
+type compiledSubExpression struct {
+	SubExpressionSimulation
+	rule *eval.Rule
+}
+
+// Simulate evaluates the event against the rules of its event type, without notifying the listeners nor running the
+// actions of the rules. The simulations are sorted by rule ID.
+func (rs *RuleSet) Simulate(event eval.Event) []RuleSimulation {
+	bucket, exists := rs.eventRuleBuckets[event.GetType()]
+	if !exists {
+		return nil
+	}
+
+	ctx := rs.pool.Get(event)
+	defer rs.pool.Put(ctx)
+
+	simulations := make([]RuleSimulation, 0, len(bucket.rules))
+	for _, rule := range bucket.rules {
+		simulations = append(simulations, RuleSimulation{
+			RuleID:         rule.ID,
+			Expression:     rule.Expression,
+			Matched:        rule.GetEvaluator().Eval(ctx),
+			SubExpressions: rs.simulateSubExpressions(ctx, rule),
+		})
+	}
+
+	sort.Slice(simulations, func(i, j int) bool {
+		return simulations[i].RuleID < simulations[j].RuleID
+	})
+	return simulations
+}
+
+func (rs *RuleSet) simulateSubExpressions(ctx *eval.Context, rule *Rule) []SubExpressionSimulation {
+	compiled := rs.compileSubExpressions(rule)
+	if len(compiled) == 0 {
+		return nil
+	}
+
+	subExpressions := make([]SubExpressionSimulation, 0, len(compiled))
+	for _, subExpression := range compiled {
+		simulation := subExpression.SubExpressionSimulation
+		if subExpression.rule != nil {
+			simulation.Matched = subExpression.rule.GetEvaluator().Eval(ctx)
+		}
+		subExpressions = append(subExpressions, simulation)
+	}
+	return subExpressions
+}
+
+// compileSubExpressions returns the compiled sub-expressions of a rule, or nil when the rule is its only
+// sub-expression
+func (rs *RuleSet) compileSubExpressions(rule *Rule) []compiledSubExpression {
+	rs.simulation.Lock()
+	defer rs.simulation.Unlock()
+
+	if compiled, ok := rs.simulation.subExpressions[rule.ID]; ok {
+		return compiled
+	}
+
+	var compiled []compiledSubExpression
+	if subExpressions := topLevelSubExpressions(rule.GetAst()); len(subExpressions) >= 2 {
+		if rs.simulation.parsingContext == nil {
+			rs.simulation.parsingContext = ast.NewParsingContext()
+		}
+		compiled = make([]compiledSubExpression, 0, len(subExpressions))
+		for i, subExpression := range subExpressions {
+			subRule := eval.NewRule(fmt.Sprintf("%s_%d", rule.ID, i), subExpression.Expression, rs.evalOpts)
+			if err := subRule.GenEvaluator(rs.model, rs.simulation.parsingContext); err != nil {
+				subExpression.Error = err.Error()
+				subRule = nil
+			}
+			compiled = append(compiled, compiledSubExpression{SubExpressionSimulation: subExpression, rule: subRule})
+		}
+	}
+
+	if rs.simulation.subExpressions == nil {
+		rs.simulation.subExpressions = make(map[eval.RuleID][]compiledSubExpression)
+	}
+	rs.simulation.subExpressions[rule.ID] = compiled
+	return compiled
+}
+
+// topLevelSubExpressions splits the expression of a rule at the boolean operators of its top level, the sub-expressions
+// between parenthesis being kept whole
+func topLevelSubExpressions(rule *ast.Rule) []SubExpressionSimulation {
+	if rule == nil || rule.BooleanExpression == nil {
+		return nil
+	}
+
+	var subExpressions []SubExpressionSimulation
+	for expr := rule.BooleanExpression.Expression; expr != nil && expr.Comparison != nil; {
+		end := len(rule.Expr)
+		var next *ast.Expression
+		if expr.Next != nil {
+			end = expr.Next.Pos.Offset
+			next = expr.Next.Expression
+		}
+
+		start := expr.Comparison.Pos.Offset
+		if start > end || end > len(rule.Expr) {
+			return nil
+		}
+		subExpression := SubExpressionSimulation{
+			Expression: strings.TrimSpace(rule.Expr[start:end]),
+		}
+		if expr.Op != nil {
+			subExpression.Operator = *expr.Op
+			subExpression.Expression = strings.TrimSpace(strings.TrimSuffix(subExpression.Expression, *expr.Op))
+		}
+		subExpressions = append(subExpressions, subExpression)
+		expr = next
+	}
+	return subExpressions
+}
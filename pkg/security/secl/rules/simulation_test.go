@@ -0,0 +1,87 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package rules
+
+import (
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/security/secl/model"
+)
+
+func TestRuleSetSimulate(t *testing.T) {
+	rs := newRuleSet()
+	addRuleExpr(t, rs,
+		`(open.file.path =~ "/sbin/*" || open.file.path =~ "/usr/sbin/*") && process.uid != 0 and open.flags & O_CREAT > 0`,
+		`open.file.path == "/usr/sbin/rootkit"`,
+		`mkdir.file.path == "/usr/sbin/rootkit"`,
+	)
+
+	event := model.NewDefaultEvent()
+	event.(*model.Event).Type = uint32(model.FileOpenEventType)
+	require.NoError(t, event.SetFieldValue("open.file.path", "/usr/sbin/rootkit"))
+	require.NoError(t, event.SetFieldValue("open.flags", syscall.O_RDONLY))
+	require.NoError(t, event.SetFieldValue("process.uid", 1000))
+
+	report := NewSimulationReport(rs, event)
+	assert.Equal(t, "open", report.EventType)
+	assert.Equal(t, []string{"ID1"}, report.Matched)
+	assert.Equal(t, []RuleSimulation{
+		{
+			RuleID:     "ID0",
+			Expression: `(open.file.path =~ "/sbin/*" || open.file.path =~ "/usr/sbin/*") && process.uid != 0 and open.flags & O_CREAT > 0`,
+			Matched:    false,
+			SubExpressions: []SubExpressionSimulation{
+				{Expression: `(open.file.path =~ "/sbin/*" || open.file.path =~ "/usr/sbin/*")`, Operator: "&&", Matched: true},
+				{Expression: `process.uid != 0`, Operator: "and", Matched: true},
+				{Expression: `open.flags & O_CREAT > 0`, Matched: false},
+			},
+		},
+		{
+			RuleID:     "ID1",
+			Expression: `open.file.path == "/usr/sbin/rootkit"`,
+			Matched:    true,
+		},
+	}, report.Rules)
+
+	// the sub-expressions are compiled once
+	require.Len(t, rs.simulation.subExpressions, 2)
+	compiled := rs.simulation.subExpressions["ID0"]
+	require.Len(t, compiled, 3)
+	assert.Equal(t, report.Rules, rs.Simulate(event))
+	assert.Equal(t, compiled[0].rule, rs.simulation.subExpressions["ID0"][0].rule)
+}
+
+func TestRuleSetSimulateNoRule(t *testing.T) {
+	rs := newRuleSet()
+	addRuleExpr(t, rs, `mkdir.file.path == "/usr/sbin/rootkit"`)
+
+	event := model.NewDefaultEvent()
+	event.(*model.Event).Type = uint32(model.FileOpenEventType)
+	assert.Empty(t, rs.Simulate(event))
+}
+
+func TestNewEventFromJSON(t *testing.T) {
+	event, err := NewEventFromJSON(strings.NewReader(`{"type": "open", "values": {"open.file.path": "/etc/passwd", "process.uid": 1000}}`))
+	require.NoError(t, err)
+	assert.Equal(t, "open", event.GetType())
+	value, err := event.GetFieldValue("process.uid")
+	require.NoError(t, err)
+	assert.Equal(t, 1000, value)
+
+	_, err = NewEventFromJSON(strings.NewReader(`{"type": "unknown"}`))
+	assert.Error(t, err)
+
+	_, err = NewEventFromJSON(strings.NewReader(`{"type": "open", "values": {"open.unknown_field": 1}}`))
+	assert.Error(t, err)
+}
@@ -11,6 +11,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/logs"
 	logConfig "github.com/DataDog/datadog-agent/pkg/logs/config"
 	"github.com/DataDog/datadog-agent/pkg/serverless/executioncontext"
@@ -54,9 +55,16 @@ type Daemon struct {
 	flushStrategy flush.Strategy
 
 	// useAdaptiveFlush is set to false when the flush strategy has been forced
-	// through configuration.
+	// through configuration or the flush strategy route.
 	useAdaptiveFlush bool
 
+	// adaptiveFlushSettings tunes the heuristics of the adaptive flush.
+	adaptiveFlushSettings AdaptiveFlushSettings
+
+	// flushStrategyMutex protects the flush strategy and the adaptive flush configuration
+	// which can be updated at runtime through the flush strategy route.
+	flushStrategyMutex sync.Mutex
+
 	// stopped represents whether the Daemon has been stopped
 	stopped bool
 
@@ -125,6 +133,7 @@ func StartDaemon(addr string) *Daemon {
 	mux.Handle("/lambda/start-invocation", &StartInvocation{daemon})
 	mux.Handle("/lambda/end-invocation", &EndInvocation{daemon})
 	mux.Handle("/trace-context", &TraceContext{daemon})
+	mux.Handle("/lambda/flush-strategy", &FlushStrategy{daemon})
 
 	// start the HTTP server used to communicate with the runtime and the Lambda platform
 	go func() {
@@ -160,14 +169,25 @@ func (d *Daemon) HandleRuntimeDone() {
 
 // ShouldFlush indicated whether or a flush is needed
 func (d *Daemon) ShouldFlush(moment flush.Moment, t time.Time) bool {
+	d.flushStrategyMutex.Lock()
+	defer d.flushStrategyMutex.Unlock()
 	return d.flushStrategy.ShouldFlush(moment, t)
 }
 
 // GetFlushStrategy returns the flush stategy
 func (d *Daemon) GetFlushStrategy() string {
+	d.flushStrategyMutex.Lock()
+	defer d.flushStrategyMutex.Unlock()
 	return d.flushStrategy.String()
 }
 
+// IsAdaptiveFlushEnabled returns whether the flush strategy is selected by the adaptive flush
+func (d *Daemon) IsAdaptiveFlushEnabled() bool {
+	d.flushStrategyMutex.Lock()
+	defer d.flushStrategyMutex.Unlock()
+	return d.useAdaptiveFlush
+}
+
 // SetupLogCollectionHandler configures the log collection route handler
 func (d *Daemon) SetupLogCollectionHandler(route string, logsChan chan *logConfig.ChannelMessage, logsEnabled bool, enhancedMetricsEnabled bool, initDurationChan chan<- float64) {
 
@@ -199,16 +219,28 @@ func (d *Daemon) SetColdStartSpanCreator(creator *trace.ColdStartSpanCreator) {
 
 // SetFlushStrategy sets the flush strategy to use.
 func (d *Daemon) SetFlushStrategy(strategy flush.Strategy) {
-	log.Debugf("Set flush strategy: %s (was: %s)", strategy.String(), d.GetFlushStrategy())
+	d.flushStrategyMutex.Lock()
+	defer d.flushStrategyMutex.Unlock()
+	log.Debugf("Set flush strategy: %s (was: %s)", strategy.String(), d.flushStrategy.String())
 	d.flushStrategy = strategy
 }
 
 // UseAdaptiveFlush sets whether we use the adaptive flush or not.
 // Set it to false when the flush strategy has been forced through configuration.
 func (d *Daemon) UseAdaptiveFlush(enabled bool) {
+	d.flushStrategyMutex.Lock()
+	defer d.flushStrategyMutex.Unlock()
 	d.useAdaptiveFlush = enabled
 }
 
+// SetAdaptiveFlushSettings sets the heuristics used by the adaptive flush to select the flush strategy.
+// Zero values keep the default settings.
+func (d *Daemon) SetAdaptiveFlushSettings(settings AdaptiveFlushSettings) {
+	d.flushStrategyMutex.Lock()
+	defer d.flushStrategyMutex.Unlock()
+	d.adaptiveFlushSettings = settings
+}
+
 // TriggerFlush triggers a flush of the aggregated metrics, traces and logs.
 // If the flush times out, the daemon will stop waiting for the flush to complete, but the
 // flush may be continued on the next invocation.
@@ -368,7 +400,7 @@ func (d *Daemon) WaitForDaemon() {
 	// If we are flushing at the end of the invocation, we need to wait for the invocation itself to end
 	// before we finish handling it. Otherwise, the daemon does not actually need to wait for the runtime to
 	// complete the invocation before it is done.
-	if d.flushStrategy.ShouldFlush(flush.Stopping, time.Now()) {
+	if d.ShouldFlush(flush.Stopping, time.Now()) {
 		d.RuntimeWg.Wait()
 	}
 }
@@ -395,11 +427,13 @@ func (d *Daemon) StartLogCollection() {
 	d.logCollector.Start()
 }
 
-// setTraceTags tries to set extra tags to the Trace agent.
+// setTraceTags tries to set extra tags to the Trace agent. The user-defined span tags
+// (DD_SPAN_TAGS and DD_SPAN_TAGS_JSON) are read again at the same time.
 // setTraceTags returns a boolean which indicate whether or not the operation succeed for testing purpose.
 func (d *Daemon) setTraceTags(tagMap map[string]string) bool {
 	if d.TraceAgent != nil && d.TraceAgent.Get() != nil {
 		d.TraceAgent.SetTags(tags.BuildTracerTags(tagMap))
+		d.TraceAgent.SetSpanTags(tags.BuildSpanTags(config.Datadog.GetStringSlice("serverless.span_tags"), config.Datadog.GetString("serverless.span_tags_json")))
 		return true
 	}
 	return false
@@ -20,8 +20,38 @@ const (
 	// defaultFlushInterval is the default interval between flushes when
 	// the extension is flushing telemetry periodically.
 	defaultFlushInterval = 20 * time.Second
+
+	// defaultPeriodicThreshold is the default invocation interval under which
+	// the extension switches to flushing telemetry periodically.
+	defaultPeriodicThreshold = 2 * time.Minute
 )
 
+// AdaptiveFlushSettings tunes the heuristics used by the adaptive flush to select
+// the flush strategy from the invocation interval of the function.
+type AdaptiveFlushSettings struct {
+	// PeriodicThreshold is the invocation interval under which telemetry is flushed periodically
+	// instead of at the end of each invocation.
+	PeriodicThreshold time.Duration
+	// FlushInterval is the interval between flushes when flushing periodically.
+	FlushInterval time.Duration
+}
+
+// periodicThreshold returns the configured periodic threshold, or the default one if unset.
+func (s AdaptiveFlushSettings) periodicThreshold() time.Duration {
+	if s.PeriodicThreshold > 0 {
+		return s.PeriodicThreshold
+	}
+	return defaultPeriodicThreshold
+}
+
+// flushInterval returns the configured flush interval, or the default one if unset.
+func (s AdaptiveFlushSettings) flushInterval() time.Duration {
+	if s.FlushInterval > 0 {
+		return s.FlushInterval
+	}
+	return defaultFlushInterval
+}
+
 // StoreInvocationTime stores the given invocation time in the list of previous
 // invocations. It is used to compute the invocation interval of the current function.
 // It is automatically removing entries when too much have been already stored (more than maxInvocationsStored).
@@ -59,6 +89,10 @@ func (d *Daemon) InvocationInterval() time.Duration {
 // This function doesn't mind if the flush strategy has been overridden through
 // configuration / environment var, the caller is responsible for that.
 func (d *Daemon) AutoSelectStrategy() flush.Strategy {
+	d.flushStrategyMutex.Lock()
+	settings := d.adaptiveFlushSettings
+	d.flushStrategyMutex.Unlock()
+
 	freq := d.InvocationInterval()
 
 	// when not enough data is available, fallback on flush.AtTheEnd strategy
@@ -66,10 +100,11 @@ func (d *Daemon) AutoSelectStrategy() flush.Strategy {
 		return &flush.AtTheEnd{}
 	}
 
-	// if running more than 1 time every 2 minutes, we can switch to the flush strategy
-	// of flushing at least every 20 seconds (at the start of the invocation)
-	if freq.Seconds() < 60*2 {
-		return flush.NewPeriodically(defaultFlushInterval)
+	// if running more often than the periodic threshold (by default 1 time every 2 minutes),
+	// we can switch to the flush strategy of flushing at least every flush interval (by default
+	// 20 seconds) at the start of the invocation
+	if freq < settings.periodicThreshold() {
+		return flush.NewPeriodically(settings.flushInterval())
 	}
 
 	return &flush.AtTheEnd{}
@@ -77,11 +112,16 @@ func (d *Daemon) AutoSelectStrategy() flush.Strategy {
 
 // UpdateStrategy will update the current flushing strategy
 func (d *Daemon) UpdateStrategy() {
-	if d.useAdaptiveFlush {
-		newStrat := d.AutoSelectStrategy()
-		if newStrat.String() != d.flushStrategy.String() {
-			log.Debug("Switching to flush strategy:", newStrat)
-			d.flushStrategy = newStrat
-		}
+	if !d.IsAdaptiveFlushEnabled() {
+		return
+	}
+	newStrat := d.AutoSelectStrategy()
+
+	d.flushStrategyMutex.Lock()
+	defer d.flushStrategyMutex.Unlock()
+	// the flush strategy may have been forced while selecting the new one
+	if d.useAdaptiveFlush && newStrat.String() != d.flushStrategy.String() {
+		log.Debug("Switching to flush strategy:", newStrat)
+		d.flushStrategy = newStrat
 	}
 }
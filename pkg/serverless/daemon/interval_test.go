@@ -138,3 +138,39 @@ func TestUpdateStrategy(t *testing.T) {
 
 	assert.Equal(d.flushStrategy, &flush.AtTheEnd{}, "strategy didn't change when useAdaptiveFlush was true")
 }
+
+func TestAutoSelectStrategyWithSettings(t *testing.T) {
+	assert := assert.New(t)
+	d := Daemon{
+		lastInvocations:  make([]time.Time, 0),
+		flushStrategy:    &flush.AtTheEnd{},
+		useAdaptiveFlush: true,
+	}
+	d.SetAdaptiveFlushSettings(AdaptiveFlushSettings{
+		PeriodicThreshold: 500 * time.Millisecond,
+		FlushInterval:     250 * time.Millisecond,
+	})
+
+	// invoked every 600 milliseconds, above the periodic threshold
+	now := time.Now()
+	for i := 0; i < 20; i++ {
+		d.StoreInvocationTime(now.Add(600 * time.Millisecond * time.Duration(i)))
+	}
+	assert.Equal((&flush.AtTheEnd{}).String(), d.AutoSelectStrategy().String())
+
+	// invoked every 400 milliseconds, below the periodic threshold
+	d.lastInvocations = make([]time.Time, 0)
+	for i := 0; i < 20; i++ {
+		d.StoreInvocationTime(now.Add(400 * time.Millisecond * time.Duration(i)))
+	}
+	assert.Equal("periodically,250", d.AutoSelectStrategy().String())
+
+	d.UpdateStrategy()
+	assert.Equal("periodically,250", d.GetFlushStrategy())
+
+	// the adaptive flush doesn't update a forced strategy
+	d.UseAdaptiveFlush(false)
+	d.SetFlushStrategy(&flush.AtTheEnd{})
+	d.UpdateStrategy()
+	assert.Equal("end", d.GetFlushStrategy())
+}
@@ -6,12 +6,14 @@
 package daemon
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"time"
 
+	"github.com/DataDog/datadog-agent/pkg/serverless/flush"
 	"github.com/DataDog/datadog-agent/pkg/serverless/invocationlifecycle"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
@@ -123,3 +125,54 @@ func (tc *TraceContext) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set(invocationlifecycle.SpanIDHeader, fmt.Sprintf("%v", executionInfo.SpanID))
 	w.Header().Set(invocationlifecycle.SamplingPriorityHeader, fmt.Sprintf("%v", executionInfo.SamplingPriority))
 }
+
+// autoFlushStrategy is the flush strategy value re-enabling the adaptive flush.
+const autoFlushStrategy = "auto"
+
+// flushStrategyPayload is the payload read and returned by the FlushStrategy route.
+type flushStrategyPayload struct {
+	Strategy string `json:"strategy"`
+	Adaptive bool   `json:"adaptive"`
+}
+
+// FlushStrategy is a route used to read the current flush strategy and to override it at runtime.
+// A POST request sets the flush strategy from a `{"strategy": "..."}` payload, using the same format as
+// DD_SERVERLESS_FLUSH_STRATEGY (e.g. "end" or "periodically,500" to flush every 500 milliseconds),
+// or "auto" to go back to the adaptive flush.
+type FlushStrategy struct {
+	daemon *Daemon
+}
+
+func (f *FlushStrategy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	log.Debug("Hit on the serverless.FlushStrategy route.")
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		var payload flushStrategyPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			http.Error(w, fmt.Sprintf("Could not decode FlushStrategy request body: %s", err), http.StatusBadRequest)
+			return
+		}
+		if payload.Strategy == autoFlushStrategy {
+			f.daemon.UseAdaptiveFlush(true)
+			f.daemon.UpdateStrategy()
+			break
+		}
+		strategy, err := flush.StrategyFromString(payload.Strategy)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		f.daemon.UseAdaptiveFlush(false)
+		f.daemon.SetFlushStrategy(strategy)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(flushStrategyPayload{
+		Strategy: f.daemon.GetFlushStrategy(),
+		Adaptive: f.daemon.IsAdaptiveFlushEnabled(),
+	})
+}
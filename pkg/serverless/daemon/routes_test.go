@@ -7,6 +7,7 @@ package daemon
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"testing"
@@ -140,3 +141,47 @@ func TestTraceContext(t *testing.T) {
 		assert.Equal(res.Header.Get("x-datadog-span-id"), fmt.Sprintf("%v", d.InvocationProcessor.GetExecutionInfo().SpanID))
 	}
 }
+
+func TestFlushStrategy(t *testing.T) {
+	assert := assert.New(t)
+	port := testutil.FreeTCPPort(t)
+	d := StartDaemon(fmt.Sprintf("127.0.0.1:%d", port))
+	time.Sleep(100 * time.Millisecond)
+	defer d.Stop()
+
+	client := &http.Client{Timeout: 1 * time.Second}
+	url := fmt.Sprintf("http://127.0.0.1:%d/lambda/flush-strategy", port)
+	do := func(method string, body string) (int, flushStrategyPayload) {
+		request, err := http.NewRequest(method, url, bytes.NewBufferString(body))
+		assert.Nil(err)
+		res, err := client.Do(request)
+		assert.Nil(err)
+		defer res.Body.Close()
+		var payload flushStrategyPayload
+		if res.StatusCode == http.StatusOK {
+			assert.Nil(json.NewDecoder(res.Body).Decode(&payload))
+		}
+		return res.StatusCode, payload
+	}
+
+	status, payload := do(http.MethodGet, "")
+	assert.Equal(http.StatusOK, status)
+	assert.Equal(flushStrategyPayload{Strategy: "end", Adaptive: true}, payload)
+
+	status, payload = do(http.MethodPost, `{"strategy": "periodically,500"}`)
+	assert.Equal(http.StatusOK, status)
+	assert.Equal(flushStrategyPayload{Strategy: "periodically,500", Adaptive: false}, payload)
+	assert.Equal("periodically,500", d.GetFlushStrategy())
+	assert.False(d.IsAdaptiveFlushEnabled())
+
+	status, _ = do(http.MethodPost, `{"strategy": "sometimes"}`)
+	assert.Equal(http.StatusBadRequest, status)
+	assert.Equal("periodically,500", d.GetFlushStrategy())
+
+	status, payload = do(http.MethodPost, `{"strategy": "auto"}`)
+	assert.Equal(http.StatusOK, status)
+	assert.Equal(flushStrategyPayload{Strategy: "end", Adaptive: true}, payload)
+
+	status, _ = do(http.MethodDelete, "")
+	assert.Equal(http.StatusMethodNotAllowed, status)
+}
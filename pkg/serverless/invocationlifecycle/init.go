@@ -15,6 +15,7 @@ import (
 
 	"github.com/aws/aws-lambda-go/events"
 
+	serverlessMetrics "github.com/DataDog/datadog-agent/pkg/serverless/metrics"
 	"github.com/DataDog/datadog-agent/pkg/serverless/trigger"
 	"github.com/DataDog/datadog-agent/pkg/trace/pb"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
@@ -23,6 +24,7 @@ import (
 func (lp *LifecycleProcessor) initFromAPIGatewayEvent(event events.APIGatewayProxyRequest, region string) {
 	if !lp.DetectLambdaLibrary() && lp.InferredSpansEnabled {
 		lp.GetInferredSpan().EnrichInferredSpanWithAPIGatewayRESTEvent(event)
+		lp.requestHandler.authorizerSpan = lp.GetInferredSpan().GenerateAPIGatewayAuthorizerSpan(event.RequestContext.Authorizer)
 	}
 
 	lp.requestHandler.event = event
@@ -45,6 +47,9 @@ func (lp *LifecycleProcessor) initFromAPIGatewayV2Event(event events.APIGatewayV
 func (lp *LifecycleProcessor) initFromAPIGatewayWebsocketEvent(event events.APIGatewayWebsocketProxyRequest, region string) {
 	if !lp.DetectLambdaLibrary() && lp.InferredSpansEnabled {
 		lp.GetInferredSpan().EnrichInferredSpanWithAPIGatewayWebsocketEvent(event)
+		if authorizer, ok := event.RequestContext.Authorizer.(map[string]interface{}); ok {
+			lp.requestHandler.authorizerSpan = lp.GetInferredSpan().GenerateAPIGatewayAuthorizerSpan(authorizer)
+		}
 	}
 
 	lp.requestHandler.event = event
@@ -59,6 +64,31 @@ func (lp *LifecycleProcessor) initFromALBEvent(event events.ALBTargetGroupReques
 	lp.addTags(trigger.GetTagsFromALBTargetGroupRequest(event))
 }
 
+func (lp *LifecycleProcessor) initFromAppSyncResolverEvent(event inferredspan.AppSyncResolverEvent, region string, accountID string) {
+	if !lp.DetectLambdaLibrary() && lp.InferredSpansEnabled {
+		lp.GetInferredSpan().EnrichInferredSpanWithAppSyncResolverEvent(event)
+	}
+
+	lp.requestHandler.event = event
+	lp.addTag("function_trigger.event_source", "appsync")
+	if arn, err := trigger.ExtractAppSyncResolverEventARN(event, region, accountID); err != nil {
+		log.Debugf("Error parsing event ARN from appsync event: %v", err)
+	} else {
+		lp.addTag("function_trigger.event_source_arn", arn)
+	}
+}
+
+func (lp *LifecycleProcessor) initFromCloudFrontEvent(event inferredspan.CloudFrontEvent, region string, accountID string) {
+	if !lp.DetectLambdaLibrary() && lp.InferredSpansEnabled {
+		lp.GetInferredSpan().EnrichInferredSpanWithCloudFrontEvent(event)
+	}
+
+	lp.requestHandler.event = event
+	lp.addTag("function_trigger.event_source", "cloudfront")
+	lp.addTag("function_trigger.event_source_arn", trigger.ExtractCloudFrontEventARN(event, region, accountID))
+	lp.addTags(trigger.GetTagsFromCloudFrontEvent(event))
+}
+
 func (lp *LifecycleProcessor) initFromCloudWatchEvent(event events.CloudWatchEvent) {
 	lp.requestHandler.event = event
 	lp.addTag("function_trigger.event_source", "cloudwatch-events")
@@ -132,6 +162,18 @@ func (lp *LifecycleProcessor) initFromSQSEvent(event events.SQSEvent) {
 	lp.addTag("function_trigger.event_source", "sqs")
 	lp.addTag("function_trigger.event_source_arn", trigger.ExtractSQSEventARN(event))
 
+	startTime := lp.GetExecutionInfo().startTime
+	if latencies := trigger.ExtractSQSLatencies(event, startTime); len(latencies) > 0 {
+		serverlessMetrics.SendSQSLatencyEnhancedMetrics(latencies, lp.ExtraTags.Tags, startTime, lp.Demux)
+		var maxLatency time.Duration
+		for _, latency := range latencies {
+			if latency > maxLatency {
+				maxLatency = latency
+			}
+		}
+		lp.requestHandler.SetMetricsTag("function_trigger.sqs.max_latency_ms", float64(maxLatency.Milliseconds()))
+	}
+
 	// test for SNS
 	var snsEntity events.SNSEntity
 	if err := json.Unmarshal([]byte(event.Records[0].Body), &snsEntity); err != nil {
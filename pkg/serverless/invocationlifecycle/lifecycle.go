@@ -40,9 +40,11 @@ type LifecycleProcessor struct {
 // inferred span, and tags about the current invocation
 // inferred spans may contain a secondary inferred span in certain cases like SNS from SQS
 type RequestHandler struct {
-	executionInfo  *ExecutionStartInfo
-	event          interface{}
-	inferredSpans  [2]*inferredspan.InferredSpan
+	executionInfo *ExecutionStartInfo
+	event         interface{}
+	inferredSpans [2]*inferredspan.InferredSpan
+	// authorizerSpan is the span of the Lambda authorizer of an API Gateway request, child of the API Gateway inferred span
+	authorizerSpan *inferredspan.InferredSpan
 	triggerTags    map[string]string
 	triggerMetrics map[string]float64
 }
@@ -125,6 +127,16 @@ func (lp *LifecycleProcessor) OnInvokeStart(startDetails *InvocationStartDetails
 		if err := json.Unmarshal(payloadBytes, &event); err == nil {
 			lp.initFromALBEvent(event)
 		}
+	case trigger.AppSyncResolverEvent:
+		var event inferredspan.AppSyncResolverEvent
+		if err := json.Unmarshal(payloadBytes, &event); err == nil && arnParseErr == nil {
+			lp.initFromAppSyncResolverEvent(event, region, account)
+		}
+	case trigger.CloudFrontRequestEvent:
+		var event inferredspan.CloudFrontEvent
+		if err := json.Unmarshal(payloadBytes, &event); err == nil && arnParseErr == nil && len(event.Records) > 0 {
+			lp.initFromCloudFrontEvent(event, region, account)
+		}
 	case trigger.CloudWatchEvent:
 		var event events.CloudWatchEvent
 		if err := json.Unmarshal(payloadBytes, &event); err == nil {
@@ -216,6 +228,7 @@ func (lp *LifecycleProcessor) OnInvokeEnd(endDetails *InvocationEndDetails) {
 			endDetails.IsError = true
 		}
 
+		reparentFromResponse(lp.GetExecutionInfo(), lp.GetInferredSpan(), endDetails.ResponseRawPayload, lp.InferredSpansEnabled)
 		endExecutionSpan(lp.GetExecutionInfo(), lp.requestHandler.triggerTags, lp.requestHandler.triggerMetrics, lp.ProcessTrace, endDetails)
 
 		if lp.InferredSpansEnabled {
@@ -229,6 +242,11 @@ func (lp *LifecycleProcessor) OnInvokeEnd(endDetails *InvocationEndDetails) {
 					lp.requestHandler.inferredSpans[1].CompleteInferredSpan(lp.ProcessTrace, lp.getInferredSpanStart(), endDetails.IsError, lp.GetExecutionInfo().TraceID, lp.GetExecutionInfo().SamplingPriority)
 					log.Debug("[lifecycle] The secondary inferred span attributes are %v", lp.requestHandler.inferredSpans[1])
 				}
+				if authorizerSpan := lp.requestHandler.authorizerSpan; authorizerSpan != nil {
+					log.Debug("[lifecycle] Completing the authorizer inferred span")
+					authorizerEndTime := time.Unix(0, authorizerSpan.Span.Start+authorizerSpan.Span.Duration)
+					authorizerSpan.CompleteInferredSpan(lp.ProcessTrace, authorizerEndTime, false, lp.GetExecutionInfo().TraceID, lp.GetExecutionInfo().SamplingPriority)
+				}
 				lp.GetInferredSpan().AddTagToInferredSpan("http.status_code", statusCode)
 				lp.GetInferredSpan().CompleteInferredSpan(lp.ProcessTrace, endDetails.EndTime, endDetails.IsError, lp.GetExecutionInfo().TraceID, lp.GetExecutionInfo().SamplingPriority)
 				log.Debugf("[lifecycle] The inferred span attributes are: %v", lp.GetInferredSpan())
@@ -283,6 +301,7 @@ func (lp *LifecycleProcessor) newRequest(lambdaPayloadString []byte, startTime t
 			SpanID: inferredspan.GenerateSpanId(),
 		},
 	}
+	lp.requestHandler.authorizerSpan = nil
 	lp.requestHandler.triggerTags = make(map[string]string)
 	lp.requestHandler.triggerMetrics = make(map[string]float64)
 }
@@ -20,6 +20,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/trace/sampler"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGenerateEnhancedErrorMetricOnInvocationEnd(t *testing.T) {
@@ -165,6 +166,45 @@ func TestEndExecutionSpanNoLambdaLibrary(t *testing.T) {
 	assert.Equal(t, duration.Nanoseconds(), executionSpan.Duration)
 }
 
+func TestEndExecutionSpanReparentedFromResponse(t *testing.T) {
+	t.Setenv(functionNameEnvVar, "TestFunction")
+	t.Setenv("DD_SERVERLESS_RESPONSE_TRACE_ID_PATH", "$.headers.x-datadog-trace-id")
+	t.Setenv("DD_SERVERLESS_RESPONSE_PARENT_ID_PATH", "$.headers.x-datadog-parent-id")
+
+	demux := aggregator.InitTestAgentDemultiplexerWithFlushInterval(time.Hour)
+	mockDetectLambdaLibrary := func() bool { return false }
+
+	var tracePayload *api.Payload
+	mockProcessTrace := func(payload *api.Payload) {
+		tracePayload = payload
+	}
+	startInvocationTime := time.Now()
+	endDetails := InvocationEndDetails{
+		EndTime:            startInvocationTime.Add(time.Second),
+		ResponseRawPayload: []byte(`{"statusCode":200,"headers":{"x-datadog-trace-id":"5736943178450432258","x-datadog-parent-id":"1480558859903409531"}}`),
+	}
+
+	testProcessor := LifecycleProcessor{
+		ExtraTags:           &logs.Tags{Tags: []string{"functionname:test-function"}},
+		ProcessTrace:        mockProcessTrace,
+		DetectLambdaLibrary: mockDetectLambdaLibrary,
+		Demux:               demux,
+		requestHandler: &RequestHandler{
+			executionInfo: &ExecutionStartInfo{
+				startTime: startInvocationTime,
+				TraceID:   123,
+				SpanID:    1,
+			},
+			triggerTags: make(map[string]string),
+		},
+	}
+	testProcessor.OnInvokeEnd(&endDetails)
+	executionSpan := tracePayload.TracerPayload.Chunks[0].Spans[0]
+	assert.Equal(t, uint64(5736943178450432258), executionSpan.TraceID)
+	assert.Equal(t, uint64(1480558859903409531), executionSpan.ParentID)
+	assert.Equal(t, uint64(1), executionSpan.SpanID)
+}
+
 func TestEndExecutionSpanWithLambdaLibrary(t *testing.T) {
 	extraTags := &logs.Tags{
 		Tags: []string{"functionname:test-function"},
@@ -655,6 +695,54 @@ func TestTriggerTypesLifecycleEventForALB5xxResponse(t *testing.T) {
 	assert.Equal(t, executionSpan.Error, int32(1))
 }
 
+func TestTriggerTypesLifecycleEventForAppSync(t *testing.T) {
+	startDetails := &InvocationStartDetails{
+		InvokeEventRawPayload: getEventFromFile("appsync.json"),
+		InvokedFunctionARN:    "arn:aws:lambda:us-east-1:123456789012:function:my-function",
+	}
+
+	testProcessor := &LifecycleProcessor{
+		DetectLambdaLibrary: func() bool { return false },
+		ProcessTrace:        func(*api.Payload) {},
+	}
+
+	testProcessor.OnInvokeStart(startDetails)
+	testProcessor.OnInvokeEnd(&InvocationEndDetails{
+		RequestID: "test-request-id",
+	})
+	assert.Equal(t, map[string]string{
+		"function_trigger.event_source_arn": "arn:aws:appsync:us-east-1:123456789012:apis/lm2cvrpxfnahxptpbxzfbjrtdi",
+		"request_id":                        "test-request-id",
+		"function_trigger.event_source":     "appsync",
+	}, testProcessor.GetTags())
+}
+
+func TestTriggerTypesLifecycleEventForCloudFront(t *testing.T) {
+	startDetails := &InvocationStartDetails{
+		InvokeEventRawPayload: getEventFromFile("cloudfront-viewer-request.json"),
+		InvokedFunctionARN:    "arn:aws:lambda:us-east-1:123456789012:function:my-function",
+	}
+
+	testProcessor := &LifecycleProcessor{
+		DetectLambdaLibrary: func() bool { return false },
+		ProcessTrace:        func(*api.Payload) {},
+	}
+
+	testProcessor.OnInvokeStart(startDetails)
+	testProcessor.OnInvokeEnd(&InvocationEndDetails{
+		RequestID: "test-request-id",
+	})
+	assert.Equal(t, map[string]string{
+		"function_trigger.event_source_arn": "arn:aws:cloudfront::123456789012:distribution/EDFDVBD6EXAMPLE",
+		"request_id":                        "test-request-id",
+		"http.method":                       "GET",
+		"http.url":                          "d111111abcdef8.cloudfront.net",
+		"http.url_details.path":             "/index.html",
+		"http.useragent":                    "curl/7.66.0",
+		"function_trigger.event_source":     "cloudfront",
+	}, testProcessor.GetTags())
+}
+
 func TestTriggerTypesLifecycleEventForCloudwatch(t *testing.T) {
 	startDetails := &InvocationStartDetails{
 		InvokeEventRawPayload: getEventFromFile("cloudwatch-events.json"),
@@ -788,25 +876,51 @@ func TestTriggerTypesLifecycleEventForSNS(t *testing.T) {
 }
 
 func TestTriggerTypesLifecycleEventForSQS(t *testing.T) {
+	extraTags := &logs.Tags{
+		Tags: []string{"functionname:test-function"},
+	}
+	demux := aggregator.InitTestAgentDemultiplexerWithFlushInterval(time.Hour)
+	// the messages of the batch were sent 2.5 seconds before the invocation start
+	startInvocationTime := time.UnixMilli(1634662094538).Add(2500 * time.Millisecond)
+	var tracePayload *api.Payload
+
 	startDetails := &InvocationStartDetails{
 		InvokeEventRawPayload: getEventFromFile("sqs-batch.json"),
 		InvokedFunctionARN:    "arn:aws:lambda:us-east-1:123456789012:function:my-function",
+		StartTime:             startInvocationTime,
 	}
 
 	testProcessor := &LifecycleProcessor{
 		DetectLambdaLibrary: func() bool { return false },
-		ProcessTrace:        func(*api.Payload) {},
+		ProcessTrace:        func(payload *api.Payload) { tracePayload = payload },
+		ExtraTags:           extraTags,
+		Demux:               demux,
 	}
 
 	testProcessor.OnInvokeStart(startDetails)
 	testProcessor.OnInvokeEnd(&InvocationEndDetails{
 		RequestID: "test-request-id",
+		EndTime:   startInvocationTime.Add(time.Second),
 	})
 	assert.Equal(t, map[string]string{
 		"function_trigger.event_source_arn": "arn:aws:sqs:sa-east-1:425362996713:InferredSpansQueueNode",
 		"request_id":                        "test-request-id",
 		"function_trigger.event_source":     "sqs",
 	}, testProcessor.GetTags())
+
+	generatedMetrics, _ := demux.WaitForNumberOfSamples(2, 0, 250*time.Millisecond)
+	expectedMetric := metrics.MetricSample{
+		Name:       "aws.lambda.enhanced.sqs_latency",
+		Value:      2.5,
+		Mtype:      metrics.DistributionType,
+		Tags:       extraTags.Tags,
+		SampleRate: 1,
+		Timestamp:  float64(startInvocationTime.UnixNano()) / float64(time.Second),
+	}
+	assert.Equal(t, []metrics.MetricSample{expectedMetric, expectedMetric}, generatedMetrics)
+
+	executionSpan := tracePayload.TracerPayload.Chunks[0].Spans[0]
+	assert.Equal(t, 2500.0, executionSpan.Metrics["function_trigger.sqs.max_latency_ms"])
 }
 
 func TestTriggerTypesLifecycleEventForSNSSQS(t *testing.T) {
@@ -827,6 +941,8 @@ func TestTriggerTypesLifecycleEventForSNSSQS(t *testing.T) {
 		DetectLambdaLibrary:  func() bool { return false },
 		ProcessTrace:         func(payload *api.Payload) { tracePayload = payload },
 		InferredSpansEnabled: true,
+		ExtraTags:            &logs.Tags{},
+		Demux:                aggregator.InitTestAgentDemultiplexerWithFlushInterval(time.Hour),
 		requestHandler: &RequestHandler{
 			executionInfo: &ExecutionStartInfo{
 				TraceID:          123,
@@ -848,6 +964,51 @@ func TestTriggerTypesLifecycleEventForSNSSQS(t *testing.T) {
 	assert.Equal(t, snsSpan.SpanID, sqsSpan.ParentID)
 }
 
+func TestTriggerTypesLifecycleEventForAPIGatewayWithAuthorizer(t *testing.T) {
+	startInvocationTime := time.Now()
+	endInvocationTime := startInvocationTime.Add(time.Second)
+
+	var spans []*pb.Span
+	startDetails := &InvocationStartDetails{
+		InvokeEventRawPayload: getEventFromFile("api-gateway-authorizer.json"),
+		InvokedFunctionARN:    "arn:aws:lambda:us-east-1:123456789012:function:my-function",
+		StartTime:             startInvocationTime,
+	}
+
+	testProcessor := &LifecycleProcessor{
+		DetectLambdaLibrary:  func() bool { return false },
+		ProcessTrace:         func(payload *api.Payload) { spans = append(spans, payload.TracerPayload.Chunks[0].Spans...) },
+		InferredSpansEnabled: true,
+		ExtraTags:            &logs.Tags{},
+		Demux:                aggregator.InitTestAgentDemultiplexerWithFlushInterval(time.Hour),
+	}
+
+	testProcessor.OnInvokeStart(startDetails)
+	testProcessor.OnInvokeEnd(&InvocationEndDetails{
+		RequestID: "test-request-id",
+		EndTime:   endInvocationTime,
+		IsError:   true,
+	})
+
+	spansByName := make(map[string]*pb.Span)
+	for _, span := range spans {
+		spansByName[span.Name] = span
+	}
+	require.Contains(t, spansByName, "aws.apigateway")
+	require.Contains(t, spansByName, "aws.apigateway.authorizer")
+	apiGatewaySpan := spansByName["aws.apigateway"]
+	authorizerSpan := spansByName["aws.apigateway.authorizer"]
+
+	assert.Equal(t, apiGatewaySpan.SpanID, authorizerSpan.ParentID)
+	assert.Equal(t, apiGatewaySpan.TraceID, authorizerSpan.TraceID)
+	assert.Equal(t, apiGatewaySpan.Start, authorizerSpan.Start)
+	assert.Equal(t, int64(71*time.Millisecond), authorizerSpan.Duration)
+	// the function error isn't an authorizer error
+	assert.Equal(t, int32(1), apiGatewaySpan.Error)
+	assert.Equal(t, int32(0), authorizerSpan.Error)
+	assert.Equal(t, "a1b2c3d4e5", apiGatewaySpan.Meta["apikey_id"])
+}
+
 func TestTriggerTypesLifecycleEventForEventBridge(t *testing.T) {
 	startDetails := &InvocationStartDetails{
 		InvokeEventRawPayload: getEventFromFile("eventbridge-custom.json"),
@@ -8,11 +8,14 @@ package invocationlifecycle
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"os"
 	"strconv"
 	"time"
 
+	"github.com/oliveagle/jsonpath"
+
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/serverless/trace/inferredspan"
 	"github.com/DataDog/datadog-agent/pkg/trace/api"
@@ -34,6 +37,9 @@ type ExecutionStartInfo struct {
 	parentID         uint64
 	requestPayload   []byte
 	SamplingPriority sampler.SamplingPriority
+	// requestContext is whether the trace context was received with the request, in which case the trace
+	// context found in the response is ignored
+	requestContext bool
 }
 
 type invocationPayload struct {
@@ -59,6 +65,7 @@ func startExecutionSpan(executionContext *ExecutionStartInfo, inferredSpan *infe
 			log.Debug("Unable to parse traceID from payload headers")
 		} else {
 			executionContext.TraceID = traceID
+			executionContext.requestContext = true
 			if inferredSpansEnabled {
 				inferredSpan.Span.TraceID = traceID
 			}
@@ -80,6 +87,7 @@ func startExecutionSpan(executionContext *ExecutionStartInfo, inferredSpan *infe
 			log.Debug("Unable to parse traceID from invokeEventHeaders")
 		} else {
 			executionContext.TraceID = traceID
+			executionContext.requestContext = true
 		}
 
 		parentID, err := strconv.ParseUint(startDetails.InvokeEventHeaders.ParentID, 0, 64)
@@ -92,6 +100,69 @@ func startExecutionSpan(executionContext *ExecutionStartInfo, inferredSpan *infe
 	executionContext.SamplingPriority = getSamplingPriority(payload.Headers[SamplingPriorityHeader], startDetails.InvokeEventHeaders.SamplingPriority)
 }
 
+// reparentFromResponse sets the trace context of the execution span, or of the inferred span when enabled, from the
+// trace and parent IDs found in the response payload at the JSONPaths set by serverless.response_trace_id_path and
+// serverless.response_parent_id_path. It is used with the frameworks attaching the trace context to their response,
+// and does nothing when the trace context was received with the request.
+// It should be called at the end of the invocation, before the spans are completed.
+func reparentFromResponse(executionContext *ExecutionStartInfo, inferredSpan *inferredspan.InferredSpan, responsePayload []byte, inferredSpansEnabled bool) {
+	traceIDPath := config.Datadog.GetString("serverless.response_trace_id_path")
+	parentIDPath := config.Datadog.GetString("serverless.response_parent_id_path")
+	if executionContext.requestContext || (traceIDPath == "" && parentIDPath == "") {
+		return
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(responsePayload))
+	decoder.UseNumber()
+	var response interface{}
+	if err := decoder.Decode(&response); err != nil {
+		log.Debugf("Could not unmarshal the response payload to extract the trace context: %v", err)
+		return
+	}
+	useInferredSpan := inferredSpansEnabled && inferredSpan != nil && inferredSpan.Span != nil && inferredSpan.Span.Start != 0
+
+	if traceID, ok := lookupResponseID(response, traceIDPath); ok {
+		executionContext.TraceID = traceID
+		if useInferredSpan {
+			inferredSpan.Span.TraceID = traceID
+		}
+	}
+	if parentID, ok := lookupResponseID(response, parentIDPath); ok {
+		if useInferredSpan {
+			inferredSpan.Span.ParentID = parentID
+		} else {
+			executionContext.parentID = parentID
+		}
+	}
+}
+
+// lookupResponseID returns the ID found in the response at the given JSONPath, written as a JSON number or string
+func lookupResponseID(response interface{}, path string) (uint64, bool) {
+	if path == "" {
+		return 0, false
+	}
+	value, err := jsonpath.JsonPathLookup(response, path)
+	if err != nil {
+		log.Debugf("Could not find %s in the response payload: %v", path, err)
+		return 0, false
+	}
+
+	var id uint64
+	switch v := value.(type) {
+	case json.Number:
+		id, err = convertStrToUnit64(v.String())
+	case string:
+		id, err = convertStrToUnit64(v)
+	default:
+		err = fmt.Errorf("unexpected type %T", value)
+	}
+	if err != nil || id == 0 {
+		log.Debugf("Invalid ID %v at %s in the response payload", value, path)
+		return 0, false
+	}
+	return id, true
+}
+
 // endExecutionSpan builds the function execution span and sends it to the intake.
 // It should be called at the end of the invocation.
 func endExecutionSpan(executionContext *ExecutionStartInfo, triggerTags map[string]string, triggerMetrics map[string]float64, processTrace func(p *api.Payload), endDetails *InvocationEndDetails) {
@@ -188,6 +259,7 @@ func InjectContext(executionContext *ExecutionStartInfo, headers http.Header) {
 	if value, err := convertStrToUnit64(headers.Get(TraceIDHeader)); err == nil {
 		log.Debugf("injecting traceID = %v", value)
 		executionContext.TraceID = value
+		executionContext.requestContext = true
 	}
 	if value, err := convertStrToUnit64(headers.Get(ParentIDHeader)); err == nil {
 		log.Debugf("injecting parentId = %v", value)
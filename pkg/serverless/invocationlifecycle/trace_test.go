@@ -347,3 +347,60 @@ func TestParseLambdaPayload(t *testing.T) {
 	assert.Equal(t, []byte("{"), ParseLambdaPayload([]byte("{")))
 	assert.Equal(t, []byte("}"), ParseLambdaPayload([]byte("}")))
 }
+
+func TestReparentFromResponse(t *testing.T) {
+	t.Setenv("DD_SERVERLESS_RESPONSE_TRACE_ID_PATH", "$.metadata.datadog.trace_id")
+	t.Setenv("DD_SERVERLESS_RESPONSE_PARENT_ID_PATH", "$.metadata.datadog.parent_id")
+	response := []byte(`{"statusCode":200,"metadata":{"datadog":{"trace_id":"5736943178450432258","parent_id":1480558859903409531}}}`)
+
+	t.Run("without inferred span", func(t *testing.T) {
+		executionContext := &ExecutionStartInfo{}
+		reparentFromResponse(executionContext, nil, response, false)
+		assert.Equal(t, uint64(5736943178450432258), executionContext.TraceID)
+		assert.Equal(t, uint64(1480558859903409531), executionContext.parentID)
+	})
+
+	t.Run("with inferred span", func(t *testing.T) {
+		inferredSpan := &inferredspan.InferredSpan{
+			Span: &pb.Span{
+				TraceID: 2350923428932752492,
+				SpanID:  1304592378509342580,
+				Start:   timeNow().UnixNano(),
+			},
+		}
+		executionContext := &ExecutionStartInfo{
+			TraceID:  2350923428932752492,
+			parentID: 1304592378509342580,
+		}
+		reparentFromResponse(executionContext, inferredSpan, response, true)
+		assert.Equal(t, uint64(5736943178450432258), executionContext.TraceID)
+		assert.Equal(t, uint64(1304592378509342580), executionContext.parentID)
+		assert.Equal(t, uint64(5736943178450432258), inferredSpan.Span.TraceID)
+		assert.Equal(t, uint64(1480558859903409531), inferredSpan.Span.ParentID)
+	})
+
+	t.Run("with the trace context of the request", func(t *testing.T) {
+		executionContext := &ExecutionStartInfo{}
+		headers := http.Header{}
+		headers.Set(TraceIDHeader, "42")
+		headers.Set(ParentIDHeader, "43")
+		InjectContext(executionContext, headers)
+		reparentFromResponse(executionContext, nil, response, false)
+		assert.Equal(t, uint64(42), executionContext.TraceID)
+		assert.Equal(t, uint64(43), executionContext.parentID)
+	})
+
+	t.Run("without trace context in the response", func(t *testing.T) {
+		executionContext := &ExecutionStartInfo{TraceID: 42}
+		reparentFromResponse(executionContext, nil, []byte(`{"statusCode":200,"metadata":{"datadog":{"trace_id":"invalid"}}}`), false)
+		assert.Equal(t, uint64(42), executionContext.TraceID)
+		assert.Equal(t, uint64(0), executionContext.parentID)
+	})
+}
+
+func TestReparentFromResponseDisabled(t *testing.T) {
+	executionContext := &ExecutionStartInfo{TraceID: 42}
+	reparentFromResponse(executionContext, nil, []byte(`{"trace_id":"5736943178450432258","parent_id":"1480558859903409531"}`), false)
+	assert.Equal(t, uint64(42), executionContext.TraceID)
+	assert.Equal(t, uint64(0), executionContext.parentID)
+}
@@ -36,6 +36,7 @@ const (
 	responseLatencyMetric     = "aws.lambda.enhanced.response_latency"
 	responseDurationMetric    = "aws.lambda.enhanced.response_duration"
 	producedBytesMetric       = "aws.lambda.enhanced.produced_bytes"
+	sqsLatencyMetric          = "aws.lambda.enhanced.sqs_latency"
 	// OutOfMemoryMetric is the name of the out of memory enhanced Lambda metric
 	OutOfMemoryMetric = "aws.lambda.enhanced.out_of_memory"
 	timeoutsMetric    = "aws.lambda.enhanced.timeouts"
@@ -201,13 +202,26 @@ func GenerateEnhancedMetricsFromReportLog(args GenerateEnhancedMetricsFromReport
 			Name:       initDurationMetric,
 			Value:      args.InitDurationMs * msToSec,
 			Mtype:      metrics.DistributionType,
-			Tags:       args.Tags,
+			Tags:       withArchitectureTag(args.Tags),
 			SampleRate: 1,
 			Timestamp:  timestamp,
 		})
 	}
 }
 
+// withArchitectureTag makes sure the tags contain the architecture of the function, so that
+// metrics such as the init duration can be compared between x86_64 and arm64 (Graviton) functions
+func withArchitectureTag(tags []string) []string {
+	for _, tag := range tags {
+		if strings.HasPrefix(tag, serverlessTags.ArchitectureKey+":") {
+			return tags
+		}
+	}
+	archTags := make([]string, 0, len(tags)+1)
+	archTags = append(archTags, tags...)
+	return append(archTags, serverlessTags.ArchitectureKey+":"+serverlessTags.ResolveRuntimeArch())
+}
+
 // SendOutOfMemoryEnhancedMetric sends an enhanced metric representing a function running out of memory at a given time
 func SendOutOfMemoryEnhancedMetric(tags []string, t time.Time, demux aggregator.Demultiplexer) {
 	incrementEnhancedMetric(OutOfMemoryMetric, tags, float64(t.UnixNano())/float64(time.Second), demux)
@@ -228,6 +242,26 @@ func SendInvocationEnhancedMetric(tags []string, demux aggregator.Demultiplexer)
 	incrementEnhancedMetric(invocationsMetric, tags, float64(time.Now().UnixNano())/float64(time.Second), demux)
 }
 
+// SendSQSLatencyEnhancedMetrics sends, for each record of an SQS event, the time elapsed between the message
+// being sent to the queue and the start of the invocation processing it
+func SendSQSLatencyEnhancedMetrics(latencies []time.Duration, tags []string, t time.Time, demux aggregator.Demultiplexer) {
+	// TODO - pass config here, instead of directly looking up var
+	if strings.ToLower(os.Getenv(enhancedMetricsEnvVar)) == "false" {
+		return
+	}
+	timestamp := float64(t.UnixNano()) / float64(time.Second)
+	for _, latency := range latencies {
+		demux.AggregateSample(metrics.MetricSample{
+			Name:       sqsLatencyMetric,
+			Value:      latency.Seconds(),
+			Mtype:      metrics.DistributionType,
+			Tags:       tags,
+			SampleRate: 1,
+			Timestamp:  timestamp,
+		})
+	}
+}
+
 // incrementEnhancedMetric sends an enhanced metric with a value of 1 to the metrics channel
 func incrementEnhancedMetric(name string, tags []string, timestamp float64, demux aggregator.Demultiplexer) {
 	// TODO - pass config here, instead of directly looking up var
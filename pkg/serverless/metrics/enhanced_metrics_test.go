@@ -132,7 +132,7 @@ func TestGenerateEnhancedMetricsFromReportLogColdStart(t *testing.T) {
 		Name:       initDurationMetric,
 		Value:      0.1,
 		Mtype:      metrics.DistributionType,
-		Tags:       tags,
+		Tags:       append(tags, "architecture:"+serverlessTags.ResolveRuntimeArch()),
 		SampleRate: 1,
 		Timestamp:  float64(reportLogTime.UnixNano()) / float64(time.Second),
 	}})
@@ -208,6 +208,16 @@ func TestGenerateEnhancedMetricsFromReportLogNoColdStart(t *testing.T) {
 	assert.Len(t, timedMetrics, 0)
 }
 
+func TestWithArchitectureTag(t *testing.T) {
+	tags := []string{"functionname:test-function", "architecture:arm64"}
+	assert.Equal(t, tags, withArchitectureTag(tags))
+
+	tags = []string{"functionname:test-function"}
+	assert.Equal(t, []string{"functionname:test-function", "architecture:" + serverlessTags.ResolveRuntimeArch()}, withArchitectureTag(tags))
+	// the original tags are not modified
+	assert.Equal(t, []string{"functionname:test-function"}, tags)
+}
+
 func TestSendTimeoutEnhancedMetric(t *testing.T) {
 	demux := aggregator.InitTestAgentDemultiplexerWithFlushInterval(time.Hour)
 	defer demux.Stop(false)
@@ -477,3 +487,46 @@ func TestGenerateEnhancedMetricsFromRuntimeDoneLogOK(t *testing.T) {
 	}})
 	assert.Len(t, timedMetrics, 0)
 }
+
+func TestSendSQSLatencyEnhancedMetrics(t *testing.T) {
+	demux := aggregator.InitTestAgentDemultiplexerWithFlushInterval(time.Hour)
+	defer demux.Stop(false)
+	tags := []string{"functionname:test-function"}
+	startTime := time.Now()
+
+	go SendSQSLatencyEnhancedMetrics([]time.Duration{500 * time.Millisecond, 2 * time.Second}, tags, startTime, demux)
+
+	generatedMetrics, timedMetrics := demux.WaitForNumberOfSamples(2, 0, 100*time.Millisecond)
+
+	assert.Equal(t, []metrics.MetricSample{{
+		Name:       sqsLatencyMetric,
+		Value:      0.5,
+		Mtype:      metrics.DistributionType,
+		Tags:       tags,
+		SampleRate: 1,
+		Timestamp:  float64(startTime.UnixNano()) / float64(time.Second),
+	}, {
+		Name:       sqsLatencyMetric,
+		Value:      2,
+		Mtype:      metrics.DistributionType,
+		Tags:       tags,
+		SampleRate: 1,
+		Timestamp:  float64(startTime.UnixNano()) / float64(time.Second),
+	}}, generatedMetrics)
+	assert.Len(t, timedMetrics, 0)
+}
+
+func TestSendSQSLatencyEnhancedMetricsDisabled(t *testing.T) {
+	os.Setenv("DD_ENHANCED_METRICS", "false")
+	defer os.Setenv("DD_ENHANCED_METRICS", "true")
+	demux := aggregator.InitTestAgentDemultiplexerWithFlushInterval(time.Hour)
+	defer demux.Stop(false)
+	tags := []string{"functionname:test-function"}
+
+	go SendSQSLatencyEnhancedMetrics([]time.Duration{time.Second}, tags, time.Now(), demux)
+
+	generatedMetrics, timedMetrics := demux.WaitForNumberOfSamples(1, 0, 100*time.Millisecond)
+
+	assert.Len(t, generatedMetrics, 0)
+	assert.Len(t, timedMetrics, 0)
+}
@@ -6,10 +6,12 @@
 package tags
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"regexp"
 	"strings"
+	"unicode"
 
 	"github.com/DataDog/datadog-agent/pkg/serverless/proc"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
@@ -35,6 +37,8 @@ const (
 	ExecutedVersionKey = "executedversion"
 	// RuntimeKey is the tag key for a function's runtime (e.g node, python)
 	RuntimeKey = "runtime"
+	// RuntimeVersionKey is the tag key for the version of a function's runtime (e.g. 18.x, 3.9)
+	RuntimeVersionKey = "runtime_version"
 	// MemorySizeKey is the tag key for a function's allocated memory size
 	MemorySizeKey = "memorysize"
 	// ArchitectureKey is the tag key for a function's architecture (e.g. x86_64, arm64)
@@ -83,7 +87,9 @@ func BuildTagMap(arn string, configTags []string) map[string]string {
 	architecture := ResolveRuntimeArch()
 	tags = setIfNotEmpty(tags, ArchitectureKey, architecture)
 
-	tags = setIfNotEmpty(tags, RuntimeKey, getRuntime("/proc", "/etc", runtimeVar))
+	runtime := getRuntime("/proc", "/etc", runtimeVar)
+	tags = setIfNotEmpty(tags, RuntimeKey, runtime)
+	tags = setIfNotEmpty(tags, RuntimeVersionKey, getRuntimeVersion(runtime))
 
 	tags = setIfNotEmpty(tags, MemorySizeKey, os.Getenv(memorySizeVar))
 
@@ -156,6 +162,31 @@ func BuildTagsFromMap(tags map[string]string) []string {
 	return MapToArray(tagsMap)
 }
 
+// BuildSpanTags builds the map of user-defined tags applied to every span, including
+// inferred spans, from DD_TAGS-style entries and from a JSON object of tags.
+// Tags defined in the JSON object take precedence.
+func BuildSpanTags(configTags []string, jsonTags string) map[string]string {
+	tagMap := ArrayToMap(configTags)
+	if jsonTags == "" {
+		return tagMap
+	}
+
+	var rawTags map[string]interface{}
+	if err := json.Unmarshal([]byte(jsonTags), &rawTags); err != nil {
+		log.Warnf("Unable to parse span tags JSON: %v", err)
+		return tagMap
+	}
+	for key, value := range rawTags {
+		switch value.(type) {
+		case map[string]interface{}, []interface{}, nil:
+			log.Warnf("Span tag %s has not expected format", key)
+		default:
+			tagMap = setIfNotEmpty(tagMap, strings.ToLower(key), fmt.Sprint(value))
+		}
+	}
+	return tagMap
+}
+
 // BuildTracerTags builds a map of tag from an existing map of tag removing useless tags for traces
 func BuildTracerTags(tags map[string]string) map[string]string {
 	return buildTags(tags, []string{resourceKey})
@@ -238,6 +269,19 @@ func getRuntime(procPath string, osReleasePath string, varName string) string {
 	return runtime
 }
 
+// getRuntimeVersion extracts the version of a language runtime, e.g. nodejs18.x => 18.x,
+// python3.9 => 3.9 or java11 => 11. OS-only runtimes (provided, provided.al2) have no version.
+func getRuntimeVersion(runtime string) string {
+	if strings.HasPrefix(runtime, "provided") {
+		return ""
+	}
+	version := strings.TrimLeftFunc(runtime, unicode.IsLetter)
+	if len(version) == 0 || !unicode.IsDigit(rune(version[0])) {
+		return ""
+	}
+	return version
+}
+
 func cleanRuntimes(runtimes []string) string {
 	filtered := []string{}
 	for i := range runtimes {
@@ -43,6 +43,27 @@ func TestBuildTracerTags(t *testing.T) {
 	assert.Equal(t, "value1", resultTagsMap["key1"])
 }
 
+func TestBuildSpanTags(t *testing.T) {
+	tagsMap := BuildSpanTags([]string{"team:Payments", "cost-center:cc1,owner:me"}, `{"cost-center":"CC2","tier":3,"critical":true}`)
+	assert.Equal(t, map[string]string{
+		"team":        "payments",
+		"cost-center": "cc2",
+		"owner":       "me",
+		"tier":        "3",
+		"critical":    "true",
+	}, tagsMap)
+}
+
+func TestBuildSpanTagsInvalidJSON(t *testing.T) {
+	tagsMap := BuildSpanTags([]string{"team:payments"}, `{"tier":`)
+	assert.Equal(t, map[string]string{"team": "payments"}, tagsMap)
+}
+
+func TestBuildSpanTagsSkipsNestedValues(t *testing.T) {
+	tagsMap := BuildSpanTags(nil, `{"team":"payments","nested":{"a":"b"},"list":["a"],"empty":null}`)
+	assert.Equal(t, map[string]string{"team": "payments"}, tagsMap)
+}
+
 func TestBuildTagsFromMap(t *testing.T) {
 	tagsMap := map[string]string{
 		"key0":              "value0",
@@ -340,6 +361,18 @@ func TestExtractRuntimeFromOsReleaseFileInvalidPath(t *testing.T) {
 	assert.Equal(t, "", result)
 }
 
+func TestGetRuntimeVersion(t *testing.T) {
+	assert.Equal(t, "18.x", getRuntimeVersion("nodejs18.x"))
+	assert.Equal(t, "3.9", getRuntimeVersion("python3.9"))
+	assert.Equal(t, "11", getRuntimeVersion("java11"))
+	assert.Equal(t, "6", getRuntimeVersion("dotnet6"))
+	assert.Equal(t, "1.x", getRuntimeVersion("go1.x"))
+	assert.Equal(t, "", getRuntimeVersion("provided.al2"))
+	assert.Equal(t, "", getRuntimeVersion("provided"))
+	assert.Equal(t, "", getRuntimeVersion("unknown"))
+	assert.Equal(t, "", getRuntimeVersion(""))
+}
+
 func TestCleanRuntimeValid(t *testing.T) {
 	runtimes := []string{
 		"AWS_Lambda_rapid",
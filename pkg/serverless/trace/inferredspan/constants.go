@@ -8,17 +8,20 @@ package inferredspan
 const (
 	// Below are used for inferred span tagging and enrichment
 	apiID            = "apiid"
+	apiKeyID         = "apikey_id"
 	apiName          = "apiname"
 	bucketARN        = "bucket_arn"
 	bucketName       = "bucketname"
 	connectionID     = "connection_id"
 	detailType       = "detail_type"
+	distributionID   = "distribution_id"
 	endpoint         = "endpoint"
 	eventID          = "event_id"
 	eventName        = "event_name"
 	eventSourceArn   = "event_source_arn"
 	eventType        = "event_type"
 	eventVersion     = "event_version"
+	fieldName        = "field_name"
 	httpURL          = "http.url"
 	httpMethod       = "http.method"
 	httpProtocol     = "http.protocol"
@@ -31,6 +34,7 @@ const (
 	objectSize       = "object_size"
 	objectETag       = "object_etag"
 	operationName    = "operation_name"
+	parentTypeName   = "parent_type_name"
 	partitionKey     = "partition_key"
 	queueName        = "queuename"
 	receiptHandle    = "receipt_handle"
@@ -51,6 +55,10 @@ const (
 	// Below are used for parsing and setting the event sources
 	sns = "sns"
 
+	// authorizerIntegrationLatency is the key of the authorizer context holding the latency of
+	// the Lambda authorizer, in milliseconds. It's missing when the authorizer response is cached.
+	authorizerIntegrationLatency = "integrationLatency"
+
 	// invocationType is used to look for the invocation type
 	// in the payload headers
 	invocationType = "X-Amz-Invocation-Type"
@@ -63,3 +71,71 @@ type EventBridgeEvent struct {
 	Source     string `json:"source"`
 	StartTime  string `json:"time"`
 }
+
+// AppSyncResolverEvent is used for unmarshalling an AppSync direct Lambda resolver event.
+// AWS Go libraries only provide the events of the resolver mapping templates.
+type AppSyncResolverEvent struct {
+	Request AppSyncRequest `json:"request"`
+	Info    AppSyncInfo    `json:"info"`
+}
+
+// AppSyncRequest is the HTTP request received by AppSync
+type AppSyncRequest struct {
+	Headers map[string]string `json:"headers"`
+	// DomainName is only set for the APIs using a custom domain name
+	DomainName string `json:"domainName"`
+}
+
+// AppSyncInfo describes the GraphQL field being resolved
+type AppSyncInfo struct {
+	FieldName      string `json:"fieldName"`
+	ParentTypeName string `json:"parentTypeName"`
+}
+
+// CloudFrontEvent is used for unmarshalling a Lambda@Edge event sent by CloudFront.
+// AWS Go libraries do not provide this type of event for deserialization.
+type CloudFrontEvent struct {
+	Records []CloudFrontEventRecord `json:"Records"`
+}
+
+// CloudFrontEventRecord is a record of a CloudFrontEvent
+type CloudFrontEventRecord struct {
+	CF CloudFrontEventData `json:"cf"`
+}
+
+// CloudFrontEventData holds the distribution configuration and the viewer request
+type CloudFrontEventData struct {
+	Config  CloudFrontConfig  `json:"config"`
+	Request CloudFrontRequest `json:"request"`
+}
+
+// CloudFrontConfig describes the distribution which triggered the function
+type CloudFrontConfig struct {
+	DistributionDomainName string `json:"distributionDomainName"`
+	DistributionID         string `json:"distributionId"`
+	EventType              string `json:"eventType"`
+	RequestID              string `json:"requestId"`
+}
+
+// CloudFrontRequest is the HTTP request received by CloudFront. Headers are indexed
+// by their lowercased name.
+type CloudFrontRequest struct {
+	ClientIP string                        `json:"clientIp"`
+	Headers  map[string][]CloudFrontHeader `json:"headers"`
+	Method   string                        `json:"method"`
+	URI      string                        `json:"uri"`
+}
+
+// CloudFrontHeader is an HTTP header of a CloudFrontRequest
+type CloudFrontHeader struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// Header returns the first value of the given lowercased header
+func (r CloudFrontRequest) Header(name string) string {
+	if values := r.Headers[name]; len(values) > 0 {
+		return values[0].Value
+	}
+	return ""
+}
@@ -13,6 +13,7 @@ import (
 
 	"github.com/aws/aws-lambda-go/events"
 
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
@@ -41,6 +42,10 @@ func (inferredSpan *InferredSpan) EnrichInferredSpanWithAPIGatewayRESTEvent(even
 		resourceNames: resource,
 		stage:         requestContext.Stage,
 	}
+	// the API key is only set when the method requires one, e.g. to enforce a usage plan
+	if requestContext.Identity.APIKeyID != "" {
+		inferredSpan.Span.Meta[apiKeyID] = requestContext.Identity.APIKeyID
+	}
 
 	inferredSpan.IsAsync = eventPayload.Headers[invocationType] == "Event"
 }
@@ -109,6 +114,41 @@ func (inferredSpan *InferredSpan) EnrichInferredSpanWithAPIGatewayWebsocketEvent
 	inferredSpan.IsAsync = eventPayload.Headers[invocationType] == "Event"
 }
 
+// GenerateAPIGatewayAuthorizerSpan returns the inferred span of the Lambda authorizer
+// which ran before the API Gateway request reached the function, as a child of the
+// API Gateway inferred span. The authorizer span starts with the request and lasts
+// for the authorizer latency. It returns nil when the authorizer context doesn't hold
+// the latency, e.g. when the authorizer response was cached.
+func (inferredSpan *InferredSpan) GenerateAPIGatewayAuthorizerSpan(authorizer map[string]interface{}) *InferredSpan {
+	latency, ok := authorizer[authorizerIntegrationLatency].(float64)
+	if !ok || latency <= 0 {
+		return nil
+	}
+	log.Debug("Generating an inferred span for an API Gateway authorizer")
+
+	span := inferredSpan.Span
+	return &InferredSpan{
+		CurrentInvocationStartTime: inferredSpan.CurrentInvocationStartTime,
+		Span: &pb.Span{
+			SpanID:   GenerateSpanId(),
+			ParentID: span.SpanID,
+			Name:     "aws.apigateway.authorizer",
+			Service:  span.Service,
+			Resource: span.Resource,
+			Type:     span.Type,
+			Start:    span.Start,
+			Duration: int64(latency * float64(time.Millisecond)),
+			Meta: map[string]string{
+				apiID:         span.Meta[apiID],
+				operationName: "aws.apigateway.authorizer",
+				requestID:     span.Meta[requestID],
+				resourceNames: span.Resource,
+				stage:         span.Meta[stage],
+			},
+		},
+	}
+}
+
 // EnrichInferredSpanWithSNSEvent uses the parsed event
 // payload to enrich the current inferred span. It applies a
 // specific set of data to the span expected from an SNS event.
@@ -261,6 +301,71 @@ func (inferredSpan *InferredSpan) EnrichInferredSpanWithDynamoDBEvent(eventPaylo
 	}
 }
 
+// EnrichInferredSpanWithAppSyncResolverEvent uses the parsed event
+// payload to enrich the current inferred span. It applies a
+// specific set of data to the span expected from an AppSync event.
+// The event doesn't hold the time of the request, so the span starts
+// with the invocation.
+func (inferredSpan *InferredSpan) EnrichInferredSpanWithAppSyncResolverEvent(eventPayload AppSyncResolverEvent) {
+	log.Debug("Enriching an inferred span for an AppSync resolver")
+	info := eventPayload.Info
+	resource := fmt.Sprintf("%s %s", info.ParentTypeName, info.FieldName)
+	domainName := eventPayload.Request.DomainName
+	if domainName == "" {
+		domainName = eventPayload.Request.Headers["host"]
+	}
+
+	inferredSpan.Span.Name = "aws.appsync"
+	inferredSpan.Span.Service = domainName
+	inferredSpan.Span.Resource = resource
+	inferredSpan.Span.Start = inferredSpan.CurrentInvocationStartTime.UnixNano()
+	inferredSpan.Span.Type = "graphql"
+	inferredSpan.Span.Meta = map[string]string{
+		fieldName:      info.FieldName,
+		httpURL:        domainName,
+		operationName:  "aws.appsync",
+		parentTypeName: info.ParentTypeName,
+		resourceNames:  resource,
+	}
+	if ua := eventPayload.Request.Headers["user-agent"]; ua != "" {
+		inferredSpan.Span.Meta[httpUserAgent] = ua
+	}
+}
+
+// EnrichInferredSpanWithCloudFrontEvent uses the parsed event
+// payload to enrich the current inferred span. It applies a
+// specific set of data to the span expected from a Lambda@Edge event.
+// The event doesn't hold the time of the request, so the span starts
+// with the invocation.
+func (inferredSpan *InferredSpan) EnrichInferredSpanWithCloudFrontEvent(eventPayload CloudFrontEvent) {
+	log.Debug("Enriching an inferred span for a CloudFront Lambda@Edge event")
+	cf := eventPayload.Records[0].CF
+	request := cf.Request
+	resource := fmt.Sprintf("%s %s", request.Method, request.URI)
+	domainName := cf.Config.DistributionDomainName
+	if domainName == "" {
+		domainName = request.Header("host")
+	}
+
+	inferredSpan.Span.Name = "aws.cloudfront"
+	inferredSpan.Span.Service = domainName
+	inferredSpan.Span.Resource = resource
+	inferredSpan.Span.Start = inferredSpan.CurrentInvocationStartTime.UnixNano()
+	inferredSpan.Span.Type = "http"
+	inferredSpan.Span.Meta = map[string]string{
+		distributionID: cf.Config.DistributionID,
+		endpoint:       request.URI,
+		eventType:      cf.Config.EventType,
+		httpURL:        fmt.Sprintf("%s%s", domainName, request.URI),
+		httpMethod:     request.Method,
+		httpSourceIP:   request.ClientIP,
+		httpUserAgent:  request.Header("user-agent"),
+		operationName:  "aws.cloudfront",
+		requestID:      cf.Config.RequestID,
+		resourceNames:  resource,
+	}
+}
+
 // CalculateStartTime converts AWS event timeEpochs to nanoseconds
 func calculateStartTime(epoch int64) int64 {
 	return epoch * 1e6
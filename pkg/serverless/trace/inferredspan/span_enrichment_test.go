@@ -48,6 +48,41 @@ func TestEnrichInferredSpanWithAPIGatewayRESTEvent(t *testing.T) {
 	assert.False(t, inferredSpan.IsAsync)
 }
 
+func TestEnrichInferredSpanWithAPIGatewayRESTEventWithAuthorizer(t *testing.T) {
+	var apiGatewayRestEvent events.APIGatewayProxyRequest
+	_ = json.Unmarshal(getEventFromFile("api-gateway-authorizer.json"), &apiGatewayRestEvent)
+
+	inferredSpan := mockInferredSpan()
+	inferredSpan.EnrichInferredSpanWithAPIGatewayRESTEvent(apiGatewayRestEvent)
+	assert.Equal(t, "a1b2c3d4e5", inferredSpan.Span.Meta[apiKeyID])
+
+	authorizerSpan := inferredSpan.GenerateAPIGatewayAuthorizerSpan(apiGatewayRestEvent.RequestContext.Authorizer)
+	assert.NotNil(t, authorizerSpan)
+
+	span := authorizerSpan.Span
+	assert.NotZero(t, span.SpanID)
+	assert.NotEqual(t, inferredSpan.Span.SpanID, span.SpanID)
+	assert.Equal(t, inferredSpan.Span.SpanID, span.ParentID)
+	assert.Equal(t, int64(1428582896000000000), span.Start)
+	assert.Equal(t, int64(71*time.Millisecond), span.Duration)
+	assert.Equal(t, "70ixmpl4fl.execute-api.us-east-2.amazonaws.com", span.Service)
+	assert.Equal(t, "aws.apigateway.authorizer", span.Name)
+	assert.Equal(t, "POST /path/to/resource", span.Resource)
+	assert.Equal(t, "http", span.Type)
+	assert.Equal(t, "1234567890", span.Meta[apiID])
+	assert.Equal(t, "aws.apigateway.authorizer", span.Meta[operationName])
+	assert.Equal(t, "c6af9ac6-7b61-11e6-9a41-93e8deadbeef", span.Meta[requestID])
+	assert.Equal(t, "prod", span.Meta[stage])
+	assert.False(t, authorizerSpan.IsAsync)
+}
+
+func TestGenerateAPIGatewayAuthorizerSpanWithoutLatency(t *testing.T) {
+	inferredSpan := mockInferredSpan()
+	// the latency is missing when the authorizer response is cached
+	assert.Nil(t, inferredSpan.GenerateAPIGatewayAuthorizerSpan(map[string]interface{}{"principalId": "user-123"}))
+	assert.Nil(t, inferredSpan.GenerateAPIGatewayAuthorizerSpan(nil))
+}
+
 func TestEnrichInferredSpanWithAPIGatewayNonProxyAsyncRESTEvent(t *testing.T) {
 	var apiGatewayRestEvent events.APIGatewayProxyRequest
 	_ = json.Unmarshal(getEventFromFile("api-gateway-non-proxy-async.json"), &apiGatewayRestEvent)
@@ -317,6 +352,58 @@ func TestEnrichInferredSpanWithDynamoDBEvent(t *testing.T) {
 	assert.True(t, inferredSpan.IsAsync)
 }
 
+func TestEnrichInferredSpanWithAppSyncResolverEvent(t *testing.T) {
+	var appSyncEvent AppSyncResolverEvent
+	_ = json.Unmarshal(getEventFromFile("appsync.json"), &appSyncEvent)
+	inferredSpan := mockInferredSpan()
+	inferredSpan.CurrentInvocationStartTime = time.Unix(1666812345, 0)
+	inferredSpan.EnrichInferredSpanWithAppSyncResolverEvent(appSyncEvent)
+
+	span := inferredSpan.Span
+	assert.Equal(t, uint64(7353030974370088224), span.TraceID)
+	assert.Equal(t, uint64(8048964810003407541), span.SpanID)
+	assert.Equal(t, int64(1666812345000000000), span.Start)
+	assert.Equal(t, "lm2cvrpxfnahxptpbxzfbjrtdi.appsync-api.us-east-1.amazonaws.com", span.Service)
+	assert.Equal(t, "aws.appsync", span.Name)
+	assert.Equal(t, "Query getPost", span.Resource)
+	assert.Equal(t, "graphql", span.Type)
+	assert.Equal(t, "getPost", span.Meta[fieldName])
+	assert.Equal(t, "Query", span.Meta[parentTypeName])
+	assert.Equal(t, "lm2cvrpxfnahxptpbxzfbjrtdi.appsync-api.us-east-1.amazonaws.com", span.Meta[httpURL])
+	assert.Equal(t, "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7)", span.Meta[httpUserAgent])
+	assert.Equal(t, "aws.appsync", span.Meta[operationName])
+	assert.Equal(t, "Query getPost", span.Meta[resourceNames])
+	assert.False(t, inferredSpan.IsAsync)
+}
+
+func TestEnrichInferredSpanWithCloudFrontEvent(t *testing.T) {
+	var cloudFrontEvent CloudFrontEvent
+	_ = json.Unmarshal(getEventFromFile("cloudfront-viewer-request.json"), &cloudFrontEvent)
+	inferredSpan := mockInferredSpan()
+	inferredSpan.CurrentInvocationStartTime = time.Unix(1666812345, 0)
+	inferredSpan.EnrichInferredSpanWithCloudFrontEvent(cloudFrontEvent)
+
+	span := inferredSpan.Span
+	assert.Equal(t, uint64(7353030974370088224), span.TraceID)
+	assert.Equal(t, uint64(8048964810003407541), span.SpanID)
+	assert.Equal(t, int64(1666812345000000000), span.Start)
+	assert.Equal(t, "d111111abcdef8.cloudfront.net", span.Service)
+	assert.Equal(t, "aws.cloudfront", span.Name)
+	assert.Equal(t, "GET /index.html", span.Resource)
+	assert.Equal(t, "http", span.Type)
+	assert.Equal(t, "EDFDVBD6EXAMPLE", span.Meta[distributionID])
+	assert.Equal(t, "/index.html", span.Meta[endpoint])
+	assert.Equal(t, "viewer-request", span.Meta[eventType])
+	assert.Equal(t, "d111111abcdef8.cloudfront.net/index.html", span.Meta[httpURL])
+	assert.Equal(t, "GET", span.Meta[httpMethod])
+	assert.Equal(t, "203.0.113.178", span.Meta[httpSourceIP])
+	assert.Equal(t, "curl/7.66.0", span.Meta[httpUserAgent])
+	assert.Equal(t, "aws.cloudfront", span.Meta[operationName])
+	assert.Equal(t, "4TyzHTaYWb1GX1qTfsHhEqV6HUDd_BzoBZnwfnvQc_1oF26ClkoUSEQ==", span.Meta[requestID])
+	assert.Equal(t, "GET /index.html", span.Meta[resourceNames])
+	assert.False(t, inferredSpan.IsAsync)
+}
+
 func TestFormatISOStartTime(t *testing.T) {
 	isotime := "2022-01-31T14:13:41.637Z"
 	startTime := formatISOStartTime(isotime)
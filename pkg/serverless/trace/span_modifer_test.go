@@ -92,6 +92,62 @@ func TestInferredSpanFunctionTagFiltering(t *testing.T) {
 	assert.False(t, tagOriginSelfSpanHasGlobalTags, "A span with meta._inferred_span.tag_origin = self should not get global tags")
 }
 
+func TestSpanTagsAppliedToInferredSpans(t *testing.T) {
+	cfg := config.New()
+	cfg.GlobalTags = map[string]string{"function_arn": "arn:aws:foo:bar:baz"}
+	cfg.Endpoints[0].APIKey = "test"
+	ctx, cancel := context.WithCancel(context.Background())
+	agnt := agent.NewAgent(ctx, cfg, telemetry.NewNoopCollector())
+	spanModifier := &spanModifier{
+		tags:     cfg.GlobalTags,
+		spanTags: map[string]string{"team": "payments"},
+	}
+	agnt.ModifySpan = spanModifier.ModifySpan
+	defer cancel()
+
+	tc := testutil.RandomTraceChunk(2, 1)
+	tc.Priority = 1 // ensure trace is never sampled out
+	tp := testutil.TracerPayloadWithChunk(tc)
+	tp.Chunks[0].Spans[0].Meta["_inferred_span.tag_source"] = "self"
+	tp.Chunks[0].Spans[1].Name = "aws.lambda"
+	go agnt.Process(&api.Payload{
+		TracerPayload: tp,
+		Source:        agnt.Receiver.Stats.GetTagStats(info.Tags{}),
+	})
+	timeout := time.After(2 * time.Second)
+	select {
+	case ss := <-agnt.TraceWriter.In:
+		tp = ss.TracerPayload
+	case <-timeout:
+		t.Fatal("timed out")
+	}
+
+	assert.Equal(t, "payments", tp.Chunks[0].Spans[0].GetMeta()["team"], "The inferred span should get span tags")
+	assert.Equal(t, "payments", tp.Chunks[0].Spans[1].GetMeta()["team"], "The execution span should get span tags")
+	_, inferredSpanHasGlobalTags := tp.Chunks[0].Spans[0].GetMeta()["function_arn"]
+	assert.False(t, inferredSpanHasGlobalTags, "The inferred span should still not get function tags")
+}
+
+func TestSetSpanTagsWhileModifyingSpans(t *testing.T) {
+	spanModifier := &spanModifier{spanTags: map[string]string{"team": "payments"}}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			spanModifier.setSpanTags(map[string]string{"team": "billing"})
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		spanModifier.ModifySpan(nil, &pb.Span{Service: "my-service", Meta: map[string]string{}})
+	}
+	<-done
+
+	span := &pb.Span{Service: "my-service", Meta: map[string]string{}}
+	spanModifier.ModifySpan(nil, span)
+	assert.Equal(t, "billing", span.Meta["team"])
+}
+
 func TestLambdaSpanChan(t *testing.T) {
 	cfg := config.New()
 	cfg.GlobalTags = map[string]string{
@@ -6,8 +6,11 @@
 package trace
 
 import (
+	"sync"
+
 	"github.com/DataDog/datadog-agent/pkg/serverless/trace/inferredspan"
 	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+	"github.com/DataDog/datadog-agent/pkg/trace/traceutil"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
@@ -16,11 +19,22 @@ const (
 )
 
 type spanModifier struct {
-	tags            map[string]string
+	tags map[string]string
+	// spanTags are user-defined tags applied to every span, inferred spans included. They are replaced by
+	// the daemon while the spans are modified on the trace processing goroutine, hence spanTagsMu.
+	spanTagsMu      sync.RWMutex
+	spanTags        map[string]string
 	lambdaSpanChan  chan<- *pb.Span
 	coldStartSpanId uint64
 }
 
+// setSpanTags replaces the user-defined tags applied to every span
+func (s *spanModifier) setSpanTags(tagMap map[string]string) {
+	s.spanTagsMu.Lock()
+	defer s.spanTagsMu.Unlock()
+	s.spanTags = tagMap
+}
+
 // ModifySpan applies extra logic to the given span
 func (s *spanModifier) ModifySpan(_ *pb.TraceChunk, span *pb.Span) {
 	if span.Service == "aws.lambda" {
@@ -47,4 +61,10 @@ func (s *spanModifier) ModifySpan(_ *pb.TraceChunk, span *pb.Span) {
 			span.Meta = spanMetadataTags
 		}
 	}
+
+	s.spanTagsMu.RLock()
+	defer s.spanTagsMu.RUnlock()
+	for k, v := range s.spanTags {
+		traceutil.SetMeta(span, k, v)
+	}
 }
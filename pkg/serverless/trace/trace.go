@@ -121,6 +121,15 @@ func (s *ServerlessTraceAgent) SetTags(tagMap map[string]string) {
 	}
 }
 
+// SetSpanTags sets the user-defined tags applied to every span, inferred spans included
+func (s *ServerlessTraceAgent) SetSpanTags(tagMap map[string]string) {
+	if s.Get() != nil {
+		s.spanModifier.setSpanTags(tagMap)
+	} else {
+		log.Debug("could not set span tags as the trace agent has not been initialized")
+	}
+}
+
 // Stop stops the trace agent
 func (s *ServerlessTraceAgent) Stop() {
 	if s.cancel != nil {
@@ -23,6 +23,7 @@ func TestEventPayloadParsing(t *testing.T) {
 		"api-gateway-v1.json":            isAPIGatewayEvent,
 		"api-gateway-v2.json":            isAPIGatewayV2Event,
 		"application-load-balancer.json": isALBEvent,
+		"appsync.json":                   isAppSyncResolverEvent,
 		"cloudwatch-events.json":         isCloudwatchEvent,
 		"cloudwatch-logs.json":           isCloudwatchLogsEvent,
 		"cloudfront.json":                isCloudFrontRequestEvent,
@@ -55,6 +56,7 @@ func TestEventPayloadParsingWrong(t *testing.T) {
 		"api-gateway-v1.json":            isAPIGatewayEvent,
 		"api-gateway-v2.json":            isAPIGatewayV2Event,
 		"application-load-balancer.json": isALBEvent,
+		"appsync.json":                   isAppSyncResolverEvent,
 		"cloudwatch-events.json":         isCloudwatchEvent,
 		"cloudwatch-logs.json":           isCloudwatchLogsEvent,
 		"cloudfront.json":                isCloudFrontRequestEvent,
@@ -96,6 +98,7 @@ func TestGetEventType(t *testing.T) {
 		"api-gateway-v1.json":            APIGatewayEvent,
 		"api-gateway-v2.json":            APIGatewayV2Event,
 		"application-load-balancer.json": ALBEvent,
+		"appsync.json":                   AppSyncResolverEvent,
 		"cloudwatch-events.json":         CloudWatchEvent,
 		"cloudwatch-logs.json":           CloudWatchLogsEvent,
 		"cloudfront.json":                CloudFrontRequestEvent,
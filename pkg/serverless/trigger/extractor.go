@@ -10,8 +10,11 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/DataDog/datadog-agent/pkg/serverless/trace/inferredspan"
 )
 
 // getAWSPartitionByRegion parses an AWS region and returns an AWS partition
@@ -48,6 +51,24 @@ func ExtractAlbEventARN(event events.ALBTargetGroupRequest) string {
 	return event.RequestContext.ELB.TargetGroupArn
 }
 
+// ExtractAppSyncResolverEventARN returns an ARN from an AppSyncResolverEvent. The API ID is
+// parsed from the default domain name of the API, <api-id>.appsync-api.<region>.amazonaws.com,
+// so an error is returned for the requests received on a custom domain name.
+func ExtractAppSyncResolverEventARN(event inferredspan.AppSyncResolverEvent, region string, accountID string) (string, error) {
+	host := event.Request.Headers["host"]
+	apiID, suffix, found := strings.Cut(host, ".")
+	if !found || apiID == "" || !strings.HasPrefix(suffix, "appsync-api.") {
+		return "", fmt.Errorf("Couldn't parse the AppSync API ID from host %q", host)
+	}
+	return fmt.Sprintf("arn:%v:appsync:%v:%v:apis/%v", getAWSPartitionByRegion(region), region, accountID, apiID), nil
+}
+
+// ExtractCloudFrontEventARN returns an ARN from a CloudFrontEvent
+func ExtractCloudFrontEventARN(event inferredspan.CloudFrontEvent, region string, accountID string) string {
+	// CloudFront is a global service, its ARNs don't contain any region
+	return fmt.Sprintf("arn:%v:cloudfront::%v:distribution/%v", getAWSPartitionByRegion(region), accountID, event.Records[0].CF.Config.DistributionID)
+}
+
 // ExtractCloudwatchEventARN returns an ARN from a CloudWatchEvent
 func ExtractCloudwatchEventARN(event events.CloudWatchEvent) string {
 	return event.Resources[0]
@@ -87,6 +108,25 @@ func ExtractSQSEventARN(event events.SQSEvent) string {
 	return event.Records[0].EventSourceARN
 }
 
+// ExtractSQSLatencies returns, for each record of a SQSEvent, the time elapsed between the message being
+// sent to the queue and the given invocation start. The records without a valid SentTimestamp are skipped.
+func ExtractSQSLatencies(event events.SQSEvent, startTime time.Time) []time.Duration {
+	latencies := make([]time.Duration, 0, len(event.Records))
+	for _, record := range event.Records {
+		sentTimestamp, err := strconv.ParseInt(record.Attributes["SentTimestamp"], 10, 64)
+		if err != nil {
+			continue
+		}
+		latency := startTime.Sub(time.UnixMilli(sentTimestamp))
+		if latency < 0 {
+			// the clocks of SQS and of the Lambda environment can drift apart
+			latency = 0
+		}
+		latencies = append(latencies, latency)
+	}
+	return latencies
+}
+
 // GetTagsFromAPIGatewayEvent returns a tagset containing http tags from an
 // APIGatewayProxyRequest
 func GetTagsFromAPIGatewayEvent(event events.APIGatewayProxyRequest) map[string]string {
@@ -162,6 +202,27 @@ func GetTagsFromLambdaFunctionURLRequest(event events.LambdaFunctionURLRequest)
 	return httpTags
 }
 
+// GetTagsFromCloudFrontEvent returns a tagset containing http tags from a
+// CloudFrontEvent
+func GetTagsFromCloudFrontEvent(event inferredspan.CloudFrontEvent) map[string]string {
+	cf := event.Records[0].CF
+	httpTags := make(map[string]string)
+	if cf.Config.DistributionDomainName != "" {
+		httpTags["http.url"] = cf.Config.DistributionDomainName
+	} else if host := cf.Request.Header("host"); host != "" {
+		httpTags["http.url"] = host
+	}
+	httpTags["http.url_details.path"] = cf.Request.URI
+	httpTags["http.method"] = cf.Request.Method
+	if referer := cf.Request.Header("referer"); referer != "" {
+		httpTags["http.referer"] = referer
+	}
+	if ua := cf.Request.Header("user-agent"); ua != "" {
+		httpTags["http.useragent"] = ua
+	}
+	return httpTags
+}
+
 // GetStatusCodeFromHTTPResponse parses a generic payload and returns
 // a status code, if it contains one. Returns an empty string if it does not,
 // or an error in case of json parsing error.
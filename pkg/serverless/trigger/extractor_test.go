@@ -10,9 +10,12 @@ import (
 	"compress/gzip"
 	"encoding/base64"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/serverless/trace/inferredspan"
 )
 
 func TestGetAWSPartitionByRegion(t *testing.T) {
@@ -73,6 +76,43 @@ func TestExtractAlbEventARN(t *testing.T) {
 	assert.Equal(t, "test-arn", arn)
 }
 
+func TestExtractAppSyncResolverEventARN(t *testing.T) {
+	event := inferredspan.AppSyncResolverEvent{
+		Request: inferredspan.AppSyncRequest{
+			Headers: map[string]string{
+				"host": "test-id.appsync-api.us-east-1.amazonaws.com",
+			},
+		},
+	}
+
+	arn, err := ExtractAppSyncResolverEventARN(event, "us-east-1", "123456789012")
+	assert.NoError(t, err)
+	assert.Equal(t, "arn:aws:appsync:us-east-1:123456789012:apis/test-id", arn)
+
+	// the API ID can't be parsed from a custom domain name
+	event.Request.DomainName = "api.example.com"
+	event.Request.Headers["host"] = "api.example.com"
+	_, err = ExtractAppSyncResolverEventARN(event, "us-east-1", "123456789012")
+	assert.Error(t, err)
+}
+
+func TestExtractCloudFrontEventARN(t *testing.T) {
+	event := inferredspan.CloudFrontEvent{
+		Records: []inferredspan.CloudFrontEventRecord{
+			{
+				CF: inferredspan.CloudFrontEventData{
+					Config: inferredspan.CloudFrontConfig{
+						DistributionID: "test-distribution",
+					},
+				},
+			},
+		},
+	}
+
+	arn := ExtractCloudFrontEventARN(event, "us-east-1", "123456789012")
+	assert.Equal(t, "arn:aws:cloudfront::123456789012:distribution/test-distribution", arn)
+}
+
 func TestExtractCloudwatchEventARN(t *testing.T) {
 	event := events.CloudWatchEvent{
 		Resources: []string{
@@ -202,6 +242,22 @@ func TestExtractSQSEventARN(t *testing.T) {
 	assert.Equal(t, "test-arn", arn)
 }
 
+func TestExtractSQSLatencies(t *testing.T) {
+	event := events.SQSEvent{
+		Records: []events.SQSMessage{
+			{Attributes: map[string]string{"SentTimestamp": "1634662094538"}},
+			{Attributes: map[string]string{"SentTimestamp": "1634662093538"}},
+			// the clock skew doesn't produce negative latencies
+			{Attributes: map[string]string{"SentTimestamp": "1634662095538"}},
+			{Attributes: map[string]string{"SentTimestamp": "invalid"}},
+			{},
+		},
+	}
+
+	latencies := ExtractSQSLatencies(event, time.UnixMilli(1634662095038))
+	assert.Equal(t, []time.Duration{500 * time.Millisecond, 1500 * time.Millisecond, 0}, latencies)
+}
+
 func TestExtractFunctionURLEventARN(t *testing.T) {
 	event := events.APIGatewayProxyRequest{
 		Headers: map[string]string{
@@ -315,6 +371,36 @@ func TestGetTagsFromFunctionURLRequest(t *testing.T) {
 	}, httpTags)
 }
 
+func TestGetTagsFromCloudFrontEvent(t *testing.T) {
+	event := inferredspan.CloudFrontEvent{
+		Records: []inferredspan.CloudFrontEventRecord{
+			{
+				CF: inferredspan.CloudFrontEventData{
+					Request: inferredspan.CloudFrontRequest{
+						Headers: map[string][]inferredspan.CloudFrontHeader{
+							"host":       {{Key: "Host", Value: "test-domain"}},
+							"referer":    {{Key: "Referer", Value: "referer"}},
+							"user-agent": {{Key: "User-Agent", Value: "user-agent"}},
+						},
+						Method: "GET",
+						URI:    "/path",
+					},
+				},
+			},
+		},
+	}
+
+	httpTags := GetTagsFromCloudFrontEvent(event)
+
+	assert.Equal(t, map[string]string{
+		"http.url":              "test-domain",
+		"http.url_details.path": "/path",
+		"http.method":           "GET",
+		"http.referer":          "referer",
+		"http.useragent":        "user-agent",
+	}, httpTags)
+}
+
 func TestExtractStatusCodeFromHTTPResponse(t *testing.T) {
 	noStatusCodePayload := []byte(`{}`)
 
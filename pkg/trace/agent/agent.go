@@ -8,6 +8,7 @@ package agent
 import (
 	"context"
 	"runtime"
+	"sync"
 	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/trace/remoteconfighandler"
@@ -45,6 +46,7 @@ const (
 type Agent struct {
 	Receiver              *api.HTTPReceiver
 	OTLPReceiver          *api.OTLPReceiver
+	FileReceiver          *api.FileReceiver
 	Concentrator          *stats.Concentrator
 	ClientStatsAggregator *stats.ClientStatsAggregator
 	Blacklister           *filters.Blacklister
@@ -59,6 +61,8 @@ type Agent struct {
 	RemoteConfigHandler   *remoteconfighandler.RemoteConfigHandler
 	TelemetryCollector    telemetry.TelemetryCollector
 	DebugServer           *api.DebugServer
+	// SamplingDecisions keeps the last sampling decisions, it is nil when disabled
+	SamplingDecisions *sampler.DecisionLog
 
 	// obfuscator is used to obfuscate sensitive data from various span
 	// tags based on their type.
@@ -75,6 +79,14 @@ type Agent struct {
 	// In takes incoming payloads to be processed by the agent.
 	In chan *api.Payload
 
+	// workers tracks the routines processing the payloads of In, so that the payloads
+	// already received are processed before the writers are stopped.
+	workers sync.WaitGroup
+
+	// clientStats tracks tracers computing stats on their side, it is only set
+	// when supplementing missing client stats is enabled.
+	clientStats *clientStatsTracker
+
 	// config
 	conf *config.AgentConfig
 
@@ -109,9 +121,17 @@ func NewAgent(ctx context.Context, conf *config.AgentConfig, telemetryCollector
 		conf:                  conf,
 		ctx:                   ctx,
 		DebugServer:           api.NewDebugServer(conf),
+		SamplingDecisions:     sampler.NewDecisionLog(conf.SamplingDecisionsBufferSize),
+	}
+	if conf.SupplementClientStats {
+		agnt.clientStats = newClientStatsTracker()
+	}
+	if agnt.SamplingDecisions != nil {
+		agnt.DebugServer.AddRoute("/debug/sampling", api.SamplingDecisionsHandler(agnt.SamplingDecisions))
 	}
 	agnt.Receiver = api.NewHTTPReceiver(conf, dynConf, in, agnt, telemetryCollector)
 	agnt.OTLPReceiver = api.NewOTLPReceiver(in, conf)
+	agnt.FileReceiver = api.NewFileReceiver(in, conf)
 	agnt.RemoteConfigHandler = remoteconfighandler.New(conf, agnt.PrioritySampler, agnt.RareSampler, agnt.ErrorsSampler)
 	agnt.TraceWriter = writer.NewTraceWriter(conf, agnt.PrioritySampler, agnt.ErrorsSampler, agnt.RareSampler, telemetryCollector)
 	return agnt
@@ -128,6 +148,7 @@ func (a *Agent) Run() {
 		a.NoPrioritySampler,
 		a.EventProcessor,
 		a.OTLPReceiver,
+		a.FileReceiver,
 		a.RemoteConfigHandler,
 		a.DebugServer,
 	} {
@@ -138,7 +159,11 @@ func (a *Agent) Run() {
 	go a.StatsWriter.Run()
 
 	for i := 0; i < runtime.NumCPU(); i++ {
-		go a.work()
+		a.workers.Add(1)
+		go func() {
+			defer a.workers.Done()
+			a.work()
+		}()
 	}
 
 	a.loop()
@@ -175,14 +200,42 @@ func (a *Agent) work() {
 
 }
 
+// waitForWorkers waits for the workers to process the payloads received before the receivers
+// were stopped, for at most the configured drain timeout.
+func (a *Agent) waitForWorkers() {
+	if a.conf.ReceiverPort == 0 {
+		// the receiver was never started, so it doesn't close In and the workers never return
+		return
+	}
+	timeout := a.conf.ShutdownDrainTimeout
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	done := make(chan struct{})
+	go func() {
+		a.workers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		log.Warnf("Timed out after %s waiting for the received payloads to be processed.", timeout)
+	}
+}
+
 func (a *Agent) loop() {
 	for {
 		select {
 		case <-a.ctx.Done():
 			log.Info("Exiting...")
+			// stop accepting new payloads, then process the ones already received before
+			// flushing the concentrator and draining the writers.
+			a.OTLPReceiver.Stop()
+			a.FileReceiver.Stop()
 			if err := a.Receiver.Stop(); err != nil {
 				log.Error(err)
 			}
+			a.waitForWorkers()
 			for _, stopper := range []interface{ Stop() }{
 				a.Concentrator,
 				a.ClientStatsAggregator,
@@ -193,7 +246,6 @@ func (a *Agent) loop() {
 				a.NoPrioritySampler,
 				a.RareSampler,
 				a.EventProcessor,
-				a.OTLPReceiver,
 				a.obfuscator,
 				a.obfuscator,
 				a.cardObfuscator,
@@ -236,7 +288,10 @@ func (a *Agent) Process(p *api.Payload) {
 	defer timing.Since("datadog.trace_agent.internal.process_payload_ms", now)
 	ts := p.Source
 	ss := new(writer.SampledChunks)
-	statsInput := stats.NewStatsInput(len(p.TracerPayload.Chunks), p.TracerPayload.ContainerID, p.ClientComputedStats, a.conf)
+	supplementStats := p.ClientComputedStats && a.clientStatsMissing(now, p)
+	computeStats := !p.ClientComputedStats || supplementStats
+	statsInput := stats.NewStatsInput(len(p.TracerPayload.Chunks), p.TracerPayload.ContainerID, !computeStats, a.conf)
+	statsInput.Supplemented = supplementStats
 
 	p.TracerPayload.Env = traceutil.NormalizeTag(p.TracerPayload.Env)
 
@@ -293,6 +348,9 @@ func (a *Agent) Process(p *api.Payload) {
 			}
 			a.obfuscateSpan(span)
 			a.Truncate(span)
+			if a.conf.ComputePeerService {
+				computePeerService(span, a.conf.PeerServicePrecedence)
+			}
 			if p.ClientComputedTopLevel {
 				traceutil.UpdateTracerTopLevel(span)
 			}
@@ -318,7 +376,7 @@ func (a *Agent) Process(p *api.Payload) {
 		}
 
 		pt := processedTrace(p, chunk, root)
-		if !p.ClientComputedStats {
+		if computeStats {
 			statsInput.Traces = append(statsInput.Traces, *pt.Clone())
 		}
 
@@ -421,8 +479,9 @@ func (a *Agent) processStats(in pb.ClientStatsPayload, lang, tracerVersion strin
 		// only allow the ContainerID stats dimension if we're in a Fargate instance or it's
 		// been explicitly enabled and it's not prohibited by the disable_cid_stats feature flag.
 		in.ContainerID = ""
-		in.Tags = nil
 	}
+	// the tags are set by the agent only, the container tags being resolved by the stats writer
+	in.Tags = nil
 	if in.Env == "" {
 		in.Env = a.conf.DefaultEnv
 	}
@@ -470,7 +529,32 @@ func mergeDuplicates(s pb.ClientStatsBucket) {
 
 // ProcessStats processes incoming client stats in from the given tracer.
 func (a *Agent) ProcessStats(in pb.ClientStatsPayload, lang, tracerVersion string) {
-	a.ClientStatsAggregator.In <- a.processStats(in, lang, tracerVersion)
+	in = a.processStats(in, lang, tracerVersion)
+	if a.clientStats != nil {
+		a.clientStats.trackStats(time.Now(), in.Lang, in.RuntimeID)
+	}
+	a.ClientStatsAggregator.In <- in
+}
+
+// clientStatsMissing reports whether the tracer which sent p claims to compute stats on its
+// side but hasn't sent any recently, in which case the agent computes stats for its spans.
+// Stats are only computed for the payloads from which the tracer didn't drop any P0 trace, as
+// stats computed from what's left of the traces would undercount the hits, errors and durations.
+func (a *Agent) clientStatsMissing(now time.Time, p *api.Payload) bool {
+	if a.clientStats == nil {
+		return false
+	}
+	if !a.clientStats.trackTraces(now, p.TracerPayload.LanguageName, p.TracerPayload.RuntimeID) {
+		return false
+	}
+	if p.ClientDroppedP0s > 0 {
+		log.Debugf("No client stats received from tracer %s (runtime ID %s), but it dropped %d P0 traces, not computing stats in the agent", p.TracerPayload.LanguageName, p.TracerPayload.RuntimeID, p.ClientDroppedP0s)
+		metrics.Count("datadog.trace_agent.stats.unsupplemented_payloads", 1, []string{"lang:" + p.TracerPayload.LanguageName}, 1)
+		return false
+	}
+	log.Debugf("No client stats received from tracer %s (runtime ID %s), computing stats in the agent", p.TracerPayload.LanguageName, p.TracerPayload.RuntimeID)
+	metrics.Count("datadog.trace_agent.stats.supplemented_payloads", 1, []string{"lang:" + p.TracerPayload.LanguageName}, 1)
+	return true
 }
 
 func isManualUserDrop(priority sampler.SamplingPriority, pt *traceutil.ProcessedTrace) bool {
@@ -498,15 +582,18 @@ func (a *Agent) sample(now time.Time, ts *info.TagStats, pt *traceutil.Processed
 	}
 	if a.conf.HasFeature("error_rare_sample_tracer_drop") {
 		if isManualUserDrop(priority, pt) {
+			a.recordSamplingDecision(now, pt, priority, sampler.MechanismUserDrop, false)
 			return 0, false, pt
 		}
 	} else { // This path to be deleted once manualUserDrop detection is available on all tracers for P < 1.
 		if priority < 0 {
+			a.recordSamplingDecision(now, pt, priority, sampler.MechanismUserDrop, false)
 			return 0, false, pt
 		}
 	}
 
-	sampled := a.runSamplers(now, *pt, hasPriority)
+	sampled, mechanism := a.runSamplers(now, *pt, hasPriority)
+	a.recordSamplingDecision(now, pt, priority, mechanism, sampled)
 	pt.TraceChunk.DroppedTrace = !sampled
 	numEvents, numExtracted := a.EventProcessor.Process(pt)
 
@@ -516,9 +603,25 @@ func (a *Agent) sample(now time.Time, ts *info.TagStats, pt *traceutil.Processed
 	return numEvents, sampled, pt
 }
 
+// recordSamplingDecision adds the sampling decision taken on pt to the decisions reported by the debug server.
+func (a *Agent) recordSamplingDecision(now time.Time, pt *traceutil.ProcessedTrace, priority sampler.SamplingPriority, mechanism string, kept bool) {
+	if a.SamplingDecisions == nil {
+		return
+	}
+	a.SamplingDecisions.Record(sampler.Decision{
+		Time:      now,
+		Service:   pt.Root.Service,
+		Resource:  pt.Root.Resource,
+		Env:       pt.TracerEnv,
+		Priority:  int(priority),
+		Mechanism: mechanism,
+		Kept:      kept,
+	})
+}
+
 // runSamplers runs all the agent's samplers on pt and returns the sampling decision
-// along with the sampling rate.
-func (a *Agent) runSamplers(now time.Time, pt traceutil.ProcessedTrace, hasPriority bool) bool {
+// along with the mechanism which took it.
+func (a *Agent) runSamplers(now time.Time, pt traceutil.ProcessedTrace, hasPriority bool) (bool, string) {
 	if hasPriority {
 		return a.samplePriorityTrace(now, pt)
 	}
@@ -528,25 +631,28 @@ func (a *Agent) runSamplers(now time.Time, pt traceutil.ProcessedTrace, hasPrior
 // samplePriorityTrace samples traces with priority set on them. PrioritySampler and
 // ErrorSampler are run in parallel. The RareSampler catches traces with rare top-level
 // or measured spans that are not caught by PrioritySampler and ErrorSampler.
-func (a *Agent) samplePriorityTrace(now time.Time, pt traceutil.ProcessedTrace) bool {
+func (a *Agent) samplePriorityTrace(now time.Time, pt traceutil.ProcessedTrace) (bool, string) {
 	// run this early to make sure the signature gets counted by the RareSampler.
 	rare := a.RareSampler.Sample(now, pt.TraceChunk, pt.TracerEnv)
 	if a.PrioritySampler.Sample(now, pt.TraceChunk, pt.Root, pt.TracerEnv, pt.ClientDroppedP0sWeight) {
-		return true
+		return true, sampler.MechanismPriority
 	}
 	if traceContainsError(pt.TraceChunk.Spans) {
-		return a.ErrorsSampler.Sample(now, pt.TraceChunk.Spans, pt.Root, pt.TracerEnv)
+		return a.ErrorsSampler.Sample(now, pt.TraceChunk.Spans, pt.Root, pt.TracerEnv), sampler.MechanismError
+	}
+	if rare {
+		return true, sampler.MechanismRare
 	}
-	return rare
+	return false, sampler.MechanismPriority
 }
 
 // sampleNoPriorityTrace samples traces with no priority set on them. The traces
 // get sampled by either the score sampler or the error sampler if they have an error.
-func (a *Agent) sampleNoPriorityTrace(now time.Time, pt traceutil.ProcessedTrace) bool {
+func (a *Agent) sampleNoPriorityTrace(now time.Time, pt traceutil.ProcessedTrace) (bool, string) {
 	if traceContainsError(pt.TraceChunk.Spans) {
-		return a.ErrorsSampler.Sample(now, pt.TraceChunk.Spans, pt.Root, pt.TracerEnv)
+		return a.ErrorsSampler.Sample(now, pt.TraceChunk.Spans, pt.Root, pt.TracerEnv), sampler.MechanismError
 	}
-	return a.NoPrioritySampler.Sample(now, pt.TraceChunk.Spans, pt.Root, pt.TracerEnv)
+	return a.NoPrioritySampler.Sample(now, pt.TraceChunk.Spans, pt.Root, pt.TracerEnv), sampler.MechanismNoPriority
 }
 
 func traceContainsError(trace pb.Trace) bool {
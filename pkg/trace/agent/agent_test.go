@@ -45,6 +45,31 @@ import (
 
 // Test to make sure that the joined effort of the quantizer and truncator, in that order, produce the
 // desired string
+func TestWaitForWorkers(t *testing.T) {
+	t.Run("receiver disabled", func(t *testing.T) {
+		// the workers never return, as In isn't closed when the receiver is disabled
+		a := &Agent{conf: &config.AgentConfig{ReceiverPort: 0, ShutdownDrainTimeout: time.Minute}}
+		a.workers.Add(1)
+
+		start := time.Now()
+		a.waitForWorkers()
+		assert.Less(t, time.Since(start), 10*time.Second)
+	})
+
+	t.Run("receiver enabled", func(t *testing.T) {
+		a := &Agent{conf: &config.AgentConfig{ReceiverPort: 8126, ShutdownDrainTimeout: time.Minute}}
+		a.workers.Add(1)
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			a.workers.Done()
+		}()
+
+		start := time.Now()
+		a.waitForWorkers()
+		assert.Less(t, time.Since(start), 10*time.Second)
+	})
+}
+
 func TestFormatTrace(t *testing.T) {
 	assert := assert.New(t)
 	resource := "SELECT name FROM people WHERE age = 42"
@@ -400,6 +425,45 @@ func TestProcess(t *testing.T) {
 		// without missing a trace
 		assert.Equal(t, gotCount, 3)
 	})
+
+	t.Run("PeerService", func(t *testing.T) {
+		for _, enabled := range []bool{false, true} {
+			cfg := config.New()
+			cfg.Endpoints[0].APIKey = "test"
+			cfg.ComputePeerService = enabled
+			cfg.ReplaceTags = []*config.ReplaceRule{{
+				Name: "peer.service",
+				Re:   regexp.MustCompile(`\.internal$`),
+				Repl: "",
+			}}
+			ctx, cancel := context.WithCancel(context.Background())
+			agnt := NewAgent(ctx, cfg, telemetry.NewNoopCollector())
+
+			now := time.Now()
+			span := &pb.Span{
+				TraceID:  1,
+				SpanID:   1,
+				Resource: "GET /users",
+				Type:     "http",
+				Start:    now.Add(-time.Second).UnixNano(),
+				Duration: (500 * time.Millisecond).Nanoseconds(),
+				Meta:     map[string]string{"span.kind": "client", "out.host": "users.internal"},
+			}
+			agnt.Process(&api.Payload{
+				TracerPayload: testutil.TracerPayloadWithChunk(testutil.TraceChunkWithSpan(span)),
+				Source:        info.NewReceiverStats().GetTagStats(info.Tags{}),
+			})
+			cancel()
+
+			if !enabled {
+				assert.NotContains(t, span.Meta, "peer.service")
+				continue
+			}
+			// the replace rules apply to the computed peer.service
+			assert.Equal(t, "users", span.Meta["peer.service"])
+			assert.Equal(t, "out.host", span.Meta["_dd.peer.service.source"])
+		}
+	})
 }
 
 func spansToChunk(spans ...*pb.Span) *pb.TraceChunk {
@@ -939,7 +1003,7 @@ func TestSampling(t *testing.T) {
 			a := configureAgent(tt.agentConfig)
 			for _, tc := range tt.testCases {
 				_, hasPriority := sampler.GetSamplingPriority(tc.trace.TraceChunk)
-				sampled := a.runSamplers(time.Now(), tc.trace, hasPriority)
+				sampled, _ := a.runSamplers(time.Now(), tc.trace, hasPriority)
 				assert.EqualValues(t, tc.wantSampled, sampled)
 			}
 		})
@@ -1039,6 +1103,50 @@ func TestSample(t *testing.T) {
 	}
 }
 
+func TestSampleRecordsDecisions(t *testing.T) {
+	cfg := &config.AgentConfig{TargetTPS: 5, ErrorTPS: 1000, Features: make(map[string]struct{})}
+	a := &Agent{
+		NoPrioritySampler: sampler.NewNoPrioritySampler(cfg),
+		ErrorsSampler:     sampler.NewErrorsSampler(cfg),
+		PrioritySampler:   sampler.NewPrioritySampler(cfg, &sampler.DynamicConfig{}),
+		RareSampler:       sampler.NewRareSampler(config.New()),
+		EventProcessor:    newEventProcessor(cfg),
+		SamplingDecisions: sampler.NewDecisionLog(10),
+		conf:              cfg,
+	}
+	genSpan := func(resource string, priority sampler.SamplingPriority, err int32) traceutil.ProcessedTrace {
+		root := &pb.Span{
+			Service:  "serv1",
+			Resource: resource,
+			Start:    time.Now().UnixNano(),
+			Duration: (100 * time.Millisecond).Nanoseconds(),
+			Metrics:  map[string]float64{"_top_level": 1},
+			Error:    err,
+			Meta:     map[string]string{},
+		}
+		pt := traceutil.ProcessedTrace{TraceChunk: testutil.TraceChunkWithSpan(root), Root: root, TracerEnv: "prod"}
+		pt.TraceChunk.Priority = int32(priority)
+		return pt
+	}
+
+	now := time.Now()
+	for _, pt := range []traceutil.ProcessedTrace{
+		genSpan("user-drop", sampler.PriorityUserDrop, 0),
+		genSpan("auto-keep", sampler.PriorityAutoKeep, 0),
+		genSpan("auto-drop-error", sampler.PriorityAutoDrop, 1),
+		genSpan("auto-drop", sampler.PriorityAutoDrop, 0),
+	} {
+		a.sample(now, info.NewReceiverStats().GetTagStats(info.Tags{}), &pt)
+	}
+
+	assert.Equal(t, []sampler.Decision{
+		{Time: now, Service: "serv1", Resource: "user-drop", Env: "prod", Priority: -1, Mechanism: sampler.MechanismUserDrop, Kept: false},
+		{Time: now, Service: "serv1", Resource: "auto-keep", Env: "prod", Priority: 1, Mechanism: sampler.MechanismPriority, Kept: true},
+		{Time: now, Service: "serv1", Resource: "auto-drop-error", Env: "prod", Priority: 0, Mechanism: sampler.MechanismError, Kept: true},
+		{Time: now, Service: "serv1", Resource: "auto-drop", Env: "prod", Priority: 0, Mechanism: sampler.MechanismPriority, Kept: false},
+	}, a.SamplingDecisions.Decisions())
+}
+
 func TestPartialSamplingFree(t *testing.T) {
 	cfg := &config.AgentConfig{RareSamplerEnabled: false, BucketInterval: 10 * time.Second}
 	statsChan := make(chan pb.StatsPayload, 100)
@@ -0,0 +1,110 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package agent
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// clientStatsWindow is the time after which a tracer reporting client computed stats
+	// without sending any stats payload is considered to be missing its stats. Tracers
+	// flush stats every 10 seconds, the window allows for a few missed flushes.
+	clientStatsWindow = 30 * time.Second
+
+	// clientStatsExpiry is the time after which an inactive tracer is forgotten.
+	clientStatsExpiry = 10 * time.Minute
+)
+
+// clientStatsKey identifies a tracer instance.
+type clientStatsKey struct {
+	lang      string
+	runtimeID string
+}
+
+type clientStatsEntry struct {
+	// firstTraces is the first time traces claiming client computed stats were received
+	firstTraces time.Time
+	// lastTraces is the last time traces claiming client computed stats were received
+	lastTraces time.Time
+	// lastStats is the last time a client stats payload was received
+	lastStats time.Time
+}
+
+// clientStatsTracker keeps track of the tracers which report computing stats on their
+// side so that the agent can compute stats itself when the tracer doesn't send them.
+type clientStatsTracker struct {
+	mu        sync.Mutex
+	tracers   map[clientStatsKey]*clientStatsEntry
+	lastPrune time.Time
+}
+
+func newClientStatsTracker() *clientStatsTracker {
+	return &clientStatsTracker{
+		tracers: make(map[clientStatsKey]*clientStatsEntry),
+	}
+}
+
+// trackTraces records that traces with client computed stats were received from the
+// given tracer and returns whether its stats are missing and should be computed by the agent.
+// Tracers without a runtime ID can't be matched with their stats and are never reported missing.
+func (t *clientStatsTracker) trackTraces(now time.Time, lang, runtimeID string) bool {
+	if runtimeID == "" {
+		return false
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.pruneLocked(now)
+
+	key := clientStatsKey{lang: lang, runtimeID: runtimeID}
+	e, ok := t.tracers[key]
+	if !ok {
+		e = &clientStatsEntry{firstTraces: now}
+		t.tracers[key] = e
+	}
+	e.lastTraces = now
+
+	// give new tracers the time to flush their first stats payload
+	if now.Sub(e.firstTraces) < clientStatsWindow {
+		return false
+	}
+	return now.Sub(e.lastStats) >= clientStatsWindow
+}
+
+// trackStats records that a client stats payload was received from the given tracer.
+func (t *clientStatsTracker) trackStats(now time.Time, lang, runtimeID string) {
+	if runtimeID == "" {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := clientStatsKey{lang: lang, runtimeID: runtimeID}
+	e, ok := t.tracers[key]
+	if !ok {
+		e = &clientStatsEntry{firstTraces: now, lastTraces: now}
+		t.tracers[key] = e
+	}
+	e.lastStats = now
+}
+
+// pruneLocked removes the tracers which haven't been active for a while.
+func (t *clientStatsTracker) pruneLocked(now time.Time) {
+	if now.Sub(t.lastPrune) < clientStatsWindow {
+		return
+	}
+	t.lastPrune = now
+
+	for key, e := range t.tracers {
+		if now.Sub(e.lastTraces) > clientStatsExpiry && now.Sub(e.lastStats) > clientStatsExpiry {
+			delete(t.tracers, key)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package agent
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/api"
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+)
+
+func TestClientStatsTracker(t *testing.T) {
+	now := time.Now()
+
+	t.Run("new-tracer", func(t *testing.T) {
+		tr := newClientStatsTracker()
+		assert.False(t, tr.trackTraces(now, "go", "rid"))
+		assert.False(t, tr.trackTraces(now.Add(clientStatsWindow/2), "go", "rid"))
+	})
+
+	t.Run("missing-stats", func(t *testing.T) {
+		tr := newClientStatsTracker()
+		assert.False(t, tr.trackTraces(now, "go", "rid"))
+		assert.True(t, tr.trackTraces(now.Add(clientStatsWindow), "go", "rid"))
+	})
+
+	t.Run("stats-received", func(t *testing.T) {
+		tr := newClientStatsTracker()
+		assert.False(t, tr.trackTraces(now, "go", "rid"))
+		tr.trackStats(now.Add(clientStatsWindow/2), "go", "rid")
+		assert.False(t, tr.trackTraces(now.Add(clientStatsWindow), "go", "rid"))
+		assert.True(t, tr.trackTraces(now.Add(2*clientStatsWindow), "go", "rid"))
+	})
+
+	t.Run("no-runtime-id", func(t *testing.T) {
+		tr := newClientStatsTracker()
+		assert.False(t, tr.trackTraces(now, "go", ""))
+		assert.False(t, tr.trackTraces(now.Add(clientStatsWindow), "go", ""))
+		assert.Empty(t, tr.tracers)
+	})
+
+	t.Run("prune", func(t *testing.T) {
+		tr := newClientStatsTracker()
+		tr.trackTraces(now, "go", "old")
+		tr.trackTraces(now.Add(clientStatsExpiry+time.Second), "go", "new")
+		assert.Len(t, tr.tracers, 1)
+		assert.Contains(t, tr.tracers, clientStatsKey{lang: "go", runtimeID: "new"})
+	})
+}
+
+func TestClientStatsMissing(t *testing.T) {
+	now := time.Now()
+	payload := func(droppedP0s int64) *api.Payload {
+		return &api.Payload{
+			TracerPayload:       &pb.TracerPayload{LanguageName: "go", RuntimeID: "rid"},
+			ClientComputedStats: true,
+			ClientDroppedP0s:    droppedP0s,
+		}
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		a := &Agent{}
+		assert.False(t, a.clientStatsMissing(now, payload(0)))
+		assert.False(t, a.clientStatsMissing(now.Add(clientStatsWindow), payload(0)))
+	})
+
+	t.Run("no-dropped-p0s", func(t *testing.T) {
+		a := &Agent{clientStats: newClientStatsTracker()}
+		assert.False(t, a.clientStatsMissing(now, payload(0)))
+		assert.True(t, a.clientStatsMissing(now.Add(clientStatsWindow), payload(0)))
+	})
+
+	t.Run("dropped-p0s", func(t *testing.T) {
+		// the stats computed from what's left of the traces would undercount
+		a := &Agent{clientStats: newClientStatsTracker()}
+		assert.False(t, a.clientStatsMissing(now, payload(3)))
+		assert.False(t, a.clientStatsMissing(now.Add(clientStatsWindow), payload(3)))
+		assert.True(t, a.clientStatsMissing(now.Add(clientStatsWindow), payload(0)))
+	})
+}
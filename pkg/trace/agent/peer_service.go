@@ -0,0 +1,40 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package agent
+
+import (
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+	"github.com/DataDog/datadog-agent/pkg/trace/traceutil"
+)
+
+const (
+	tagPeerService = "peer.service"
+	// tagPeerServiceSource is the tag `peer.service` was computed from, as set by the tracers computing it
+	tagPeerServiceSource = "_dd.peer.service.source"
+	tagSpanKind          = "span.kind"
+)
+
+// computePeerService sets `peer.service` on the outbound spans which don't have it, from the first tag of
+// precedence set on the span. Older tracers set span.kind on few of their integrations, so the spans
+// without span.kind are considered, the tags of precedence being those of outbound requests.
+func computePeerService(s *pb.Span, precedence []string) {
+	if s.Meta[tagPeerService] != "" {
+		return
+	}
+	switch strings.ToLower(s.Meta[tagSpanKind]) {
+	case "server", "consumer", "internal":
+		return
+	}
+	for _, tag := range precedence {
+		if v := s.Meta[tag]; v != "" {
+			traceutil.SetMeta(s, tagPeerService, v)
+			traceutil.SetMeta(s, tagPeerServiceSource, tag)
+			return
+		}
+	}
+}
@@ -0,0 +1,91 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package agent
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+)
+
+func TestComputePeerService(t *testing.T) {
+	for _, tt := range []struct {
+		name       string
+		meta       map[string]string
+		precedence []string
+		peer       string
+		source     string
+	}{
+		{
+			name:   "db.instance first",
+			meta:   map[string]string{"span.kind": "client", "db.instance": "users", "net.peer.name": "db-1", "out.host": "10.0.0.1"},
+			peer:   "users",
+			source: "db.instance",
+		},
+		{
+			name:   "net.peer.name before out.host",
+			meta:   map[string]string{"span.kind": "producer", "net.peer.name": "kafka-1", "out.host": "10.0.0.1"},
+			peer:   "kafka-1",
+			source: "net.peer.name",
+		},
+		{
+			name:   "out.host without span.kind",
+			meta:   map[string]string{"out.host": "cache"},
+			peer:   "cache",
+			source: "out.host",
+		},
+		{
+			name:   "empty tags are skipped",
+			meta:   map[string]string{"db.instance": "", "out.host": "cache"},
+			peer:   "cache",
+			source: "out.host",
+		},
+		{
+			name:       "configured precedence",
+			meta:       map[string]string{"db.instance": "users", "rpc.service": "billing"},
+			precedence: []string{"rpc.service", "db.instance"},
+			peer:       "billing",
+			source:     "rpc.service",
+		},
+		{
+			name: "existing peer.service is kept",
+			meta: map[string]string{"peer.service": "users-db", "db.instance": "users"},
+			peer: "users-db",
+		},
+		{
+			name: "server spans are skipped",
+			meta: map[string]string{"span.kind": "server", "out.host": "10.0.0.1"},
+		},
+		{
+			name: "internal spans are skipped",
+			meta: map[string]string{"span.kind": "INTERNAL", "out.host": "10.0.0.1"},
+		},
+		{
+			name: "no tag to compute from",
+			meta: map[string]string{"span.kind": "client"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			precedence := tt.precedence
+			if precedence == nil {
+				precedence = config.DefaultPeerServicePrecedence
+			}
+			s := &pb.Span{Meta: tt.meta}
+			computePeerService(s, precedence)
+			assert.Equal(t, tt.peer, s.Meta[tagPeerService])
+			assert.Equal(t, tt.source, s.Meta[tagPeerServiceSource])
+		})
+	}
+
+	t.Run("nil meta", func(t *testing.T) {
+		s := &pb.Span{}
+		computePeerService(s, config.DefaultPeerServicePrecedence)
+		assert.Empty(t, s.Meta)
+	})
+}
@@ -51,6 +51,39 @@ func (a *Agent) Truncate(s *pb.Span) {
 			s.Metrics[k] = v
 		}
 	}
+	for _, e := range s.SpanEvents {
+		if e != nil {
+			truncateSpanEvent(e)
+		}
+	}
+}
+
+// truncateSpanEvent checks that the span event name and attributes are within the max
+// length of meta keys and values and modifies them if they are not
+func truncateSpanEvent(e *pb.SpanEvent) {
+	if len(e.Name) > MaxMetaKeyLen {
+		log.Debugf("span.truncate: truncating `SpanEvents` name (max %d chars): %s", MaxMetaKeyLen, e.Name)
+		e.Name = traceutil.TruncateUTF8(e.Name, MaxMetaKeyLen) + "..."
+	}
+	for k, v := range e.Attributes {
+		modified := false
+
+		if len(k) > MaxMetaKeyLen {
+			log.Debugf("span.truncate: truncating `SpanEvents` attribute key (max %d chars): %s", MaxMetaKeyLen, k)
+			delete(e.Attributes, k)
+			k = traceutil.TruncateUTF8(k, MaxMetaKeyLen) + "..."
+			modified = true
+		}
+
+		if len(v) > MaxMetaValLen {
+			v = traceutil.TruncateUTF8(v, MaxMetaValLen) + "..."
+			modified = true
+		}
+
+		if modified {
+			e.Attributes[k] = v
+		}
+	}
 }
 
 const (
@@ -102,6 +102,29 @@ func TestTruncateMetaValueTooLong(t *testing.T) {
 	}
 }
 
+func TestTruncateSpanEvents(t *testing.T) {
+	a := &Agent{conf: config.New()}
+	s := testSpan()
+	s.SpanEvents = []*pb.SpanEvent{
+		nil,
+		{
+			Name: strings.Repeat("TOOLONG", 1000),
+			Attributes: map[string]string{
+				strings.Repeat("TOOLONG", 1000): "foo",
+				"foo":                           strings.Repeat("TOOLONG", 25000),
+			},
+		},
+	}
+	a.Truncate(s)
+	e := s.SpanEvents[1]
+	assert.True(t, len(e.Name) < MaxMetaKeyLen+4)
+	assert.Len(t, e.Attributes, 2)
+	for k, v := range e.Attributes {
+		assert.True(t, len(k) < MaxMetaKeyLen+4)
+		assert.True(t, len(v) < MaxMetaValLen+4)
+	}
+}
+
 func TestTruncateResource(t *testing.T) {
 	a := &Agent{conf: config.New()}
 	t.Run("over", func(t *testing.T) {
@@ -77,6 +77,9 @@ type HTTPReceiver struct {
 
 	rateLimiterResponse int // HTTP status code when refusing
 
+	// containerRateLimiter limits the payloads accepted from each container, nil when disabled
+	containerRateLimiter *containerRateLimiter
+
 	wg   sync.WaitGroup // waits for all requests to be processed
 	exit chan struct{}
 
@@ -104,6 +107,8 @@ func NewHTTPReceiver(conf *config.AgentConfig, dynConf *sampler.DynamicConfig, o
 
 		rateLimiterResponse: rateLimiterResponse,
 
+		containerRateLimiter: newContainerRateLimiter(conf.MaxContainerPayloadsPerSecond),
+
 		exit: make(chan struct{}),
 
 		outOfCPUCounter: atomic.NewUint32(0),
@@ -213,6 +218,9 @@ func (r *HTTPReceiver) Start() {
 	}
 
 	go r.RateLimiter.Run()
+	if r.containerRateLimiter != nil {
+		go r.containerRateLimiter.Run()
+	}
 
 	go func() {
 		defer watchdog.LogOnPanic()
@@ -268,6 +276,9 @@ func (r *HTTPReceiver) Stop() error {
 	<-r.exit
 
 	r.RateLimiter.Stop()
+	if r.containerRateLimiter != nil {
+		r.containerRateLimiter.Stop()
+	}
 
 	expiry := time.Now().Add(5 * time.Second) // give it 5 seconds
 	ctx, cancel := context.WithDeadline(context.Background(), expiry)
@@ -462,6 +473,18 @@ func (r *HTTPReceiver) handleTraces(v Version, w http.ResponseWriter, req *http.
 	if err == errInvalidHeaderTraceCountValue {
 		log.Errorf("Failed to count traces: %s", err)
 	}
+	if r.containerRateLimiter != nil {
+		containerID := r.containerIDProvider.GetContainerID(req.Context(), req.Header)
+		if !r.containerRateLimiter.Allow(containerID, time.Now()) {
+			// this container is sending more payloads than allowed, ask its tracer to back off
+			io.Copy(io.Discard, req.Body) //nolint:errcheck
+			w.WriteHeader(http.StatusTooManyRequests)
+			r.replyOK(req, v, w)
+			ts.PayloadRefused.Inc()
+			metrics.Count("datadog.trace_agent.receiver.container_rate_limited", 1, ts.AsTags(), 1)
+			return
+		}
+	}
 
 	start := time.Now()
 	tp, ranHook, err := decodeTracerPayload(v, req, ts, r.containerIDProvider)
@@ -777,6 +777,38 @@ func TestHandleTraces(t *testing.T) {
 	assert.Equal("C#|go|java|python|ruby", receiver.Languages())
 }
 
+func TestHandleTracesContainerRateLimit(t *testing.T) {
+	bts, err := testutil.GetTestTraces(1, 1, true).MarshalMsg(nil)
+	require.NoError(t, err)
+
+	conf := newTestReceiverConfig()
+	conf.MaxContainerPayloadsPerSecond = 1
+	receiver := newTestReceiverFromConfig(conf)
+	handler := receiver.handleWithVersion(v04, receiver.handleTraces)
+
+	send := func(containerID string) int {
+		select {
+		case <-receiver.out:
+		default:
+		}
+		rr := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/v0.4/traces", bytes.NewReader(bts))
+		req.Header.Set("Content-Type", "application/msgpack")
+		req.Header.Set(header.ContainerID, containerID)
+		handler.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	assert.Equal(t, http.StatusOK, send("runaway"))
+	assert.Equal(t, http.StatusTooManyRequests, send("runaway"))
+	// other containers are not affected
+	assert.Equal(t, http.StatusOK, send("quiet"))
+
+	ts, ok := receiver.Stats.Stats[info.Tags{EndpointVersion: "v0.4"}]
+	require.True(t, ok)
+	assert.Equal(t, int64(1), ts.PayloadRefused.Load())
+}
+
 // chunkedReader is a reader which forces partial reads, this is required
 // to trigger some network related bugs, such as body not being read fully by server.
 // Without this, all the data could be read/written at once, not triggering the issue.
@@ -0,0 +1,93 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package api
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// containerRateLimiter limits the number of payloads per second accepted from each container,
+// so that a single runaway tracer can not starve the others sharing the same agent.
+type containerRateLimiter struct {
+	mu       sync.Mutex
+	limit    rate.Limit
+	burst    int
+	limiters map[string]*containerLimiter
+
+	// expirePeriod specifies the interval after which idle containers are forgotten.
+	expirePeriod time.Duration
+	exit         chan struct{}
+}
+
+type containerLimiter struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// newContainerRateLimiter returns a rate limiter accepting up to payloadsPerSecond payloads
+// per second from each container. It returns nil when payloadsPerSecond is not positive.
+func newContainerRateLimiter(payloadsPerSecond float64) *containerRateLimiter {
+	if payloadsPerSecond <= 0 {
+		return nil
+	}
+	return &containerRateLimiter{
+		limit: rate.Limit(payloadsPerSecond),
+		// allow short bursts of up to one second worth of payloads
+		burst:        int(math.Max(1, math.Ceil(payloadsPerSecond))),
+		limiters:     make(map[string]*containerLimiter),
+		expirePeriod: 5 * time.Minute,
+		exit:         make(chan struct{}),
+	}
+}
+
+// Run runs the rate limiter, occasionally forgetting about containers that stopped sending payloads.
+func (l *containerRateLimiter) Run() {
+	t := time.NewTicker(l.expirePeriod)
+	defer t.Stop()
+	for {
+		select {
+		case now := <-t.C:
+			l.expire(now)
+		case <-l.exit:
+			return
+		}
+	}
+}
+
+// Stop stops the rate limiter.
+func (l *containerRateLimiter) Stop() { close(l.exit) }
+
+// Allow reports whether a payload coming from containerID may be accepted at time now.
+// Payloads without a container ID are always accepted.
+func (l *containerRateLimiter) Allow(containerID string, now time.Time) bool {
+	if l == nil || containerID == "" {
+		return true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	cl, ok := l.limiters[containerID]
+	if !ok {
+		cl = &containerLimiter{limiter: rate.NewLimiter(l.limit, l.burst)}
+		l.limiters[containerID] = cl
+	}
+	cl.lastSeen = now
+	return cl.limiter.AllowN(now, 1)
+}
+
+// expire removes the limiters of containers which were not seen for at least expirePeriod.
+func (l *containerRateLimiter) expire(now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for id, cl := range l.limiters {
+		if now.Sub(cl.lastSeen) >= l.expirePeriod {
+			delete(l.limiters, id)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContainerRateLimiter(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		l := newContainerRateLimiter(0)
+		assert.Nil(t, l)
+		assert.True(t, l.Allow("abc", time.Now()))
+	})
+
+	t.Run("per-container", func(t *testing.T) {
+		l := newContainerRateLimiter(2)
+		now := time.Now()
+		assert.True(t, l.Allow("abc", now))
+		assert.True(t, l.Allow("abc", now))
+		assert.False(t, l.Allow("abc", now))
+		// each container has its own budget
+		assert.True(t, l.Allow("def", now))
+		// payloads without a container are never limited
+		for i := 0; i < 10; i++ {
+			assert.True(t, l.Allow("", now))
+		}
+		// the budget refills over time
+		assert.True(t, l.Allow("abc", now.Add(time.Second)))
+	})
+
+	t.Run("expire", func(t *testing.T) {
+		l := newContainerRateLimiter(1)
+		now := time.Now()
+		l.Allow("old", now)
+		l.Allow("new", now.Add(l.expirePeriod))
+		l.expire(now.Add(l.expirePeriod))
+		assert.Len(t, l.limiters, 1)
+		assert.Contains(t, l.limiters, "new")
+	})
+}
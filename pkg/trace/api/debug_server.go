@@ -10,6 +10,7 @@ package api
 
 import (
 	"context"
+	"encoding/json"
 	"expvar"
 	"fmt"
 	"net"
@@ -21,6 +22,7 @@ import (
 
 	"github.com/DataDog/datadog-agent/pkg/trace/config"
 	"github.com/DataDog/datadog-agent/pkg/trace/log"
+	"github.com/DataDog/datadog-agent/pkg/trace/sampler"
 )
 
 const (
@@ -32,15 +34,22 @@ const (
 type DebugServer struct {
 	conf   *config.AgentConfig
 	server *http.Server
+	routes map[string]http.Handler
 }
 
 // NewDebugServer returns a debug server
 func NewDebugServer(conf *config.AgentConfig) *DebugServer {
 	return &DebugServer{
-		conf: conf,
+		conf:   conf,
+		routes: make(map[string]http.Handler),
 	}
 }
 
+// AddRoute adds a route to the debug server. It must be called before Start.
+func (ds *DebugServer) AddRoute(route string, handler http.Handler) {
+	ds.routes[route] = handler
+}
+
 // Start configures and starts the http server
 func (ds *DebugServer) Start() {
 	if ds.conf.DebugServerPort == 0 {
@@ -109,5 +118,32 @@ func (ds *DebugServer) mux() *http.ServeMux {
 		w.Header().Set("Access-Control-Allow-Origin", "http://127.0.0.1:"+ds.conf.GUIPort)
 		expvar.Handler().ServeHTTP(w, req)
 	}))
+	for route, handler := range ds.routes {
+		mux.Handle(route, handler)
+	}
 	return mux
 }
+
+// SamplingDecisionsHandler returns a handler reporting the last sampling decisions of the agent,
+// along with a summary of the decisions per service and resource. The decisions can be filtered
+// with the `service` and `resource` query parameters.
+func SamplingDecisionsHandler(decisions *sampler.DecisionLog) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		service, resource := req.URL.Query().Get("service"), req.URL.Query().Get("resource")
+		var recent []sampler.Decision
+		for _, d := range decisions.Decisions() {
+			if (service != "" && d.Service != service) || (resource != "" && d.Resource != resource) {
+				continue
+			}
+			recent = append(recent, d)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(struct {
+			Summary   []sampler.DecisionSummary `json:"summary"`
+			Decisions []sampler.Decision        `json:"decisions"`
+		}{
+			Summary:   sampler.SummarizeDecisions(recent),
+			Decisions: recent,
+		})
+	})
+}
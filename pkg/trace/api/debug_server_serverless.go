@@ -8,7 +8,12 @@
 
 package api
 
-import "github.com/DataDog/datadog-agent/pkg/trace/config"
+import (
+	"net/http"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/sampler"
+)
 
 type DebugServer struct{}
 
@@ -16,5 +21,8 @@ func NewDebugServer(conf *config.AgentConfig) *DebugServer {
 	return new(DebugServer)
 }
 
-func (*DebugServer) Start() {}
-func (*DebugServer) Stop()  {}
+func (*DebugServer) Start()                        {}
+func (*DebugServer) Stop()                         {}
+func (*DebugServer) AddRoute(string, http.Handler) {}
+
+func SamplingDecisionsHandler(*sampler.DecisionLog) http.Handler { return nil }
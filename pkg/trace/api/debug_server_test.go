@@ -0,0 +1,46 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2023-present Datadog, Inc.
+
+//go:build !serverless
+// +build !serverless
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/sampler"
+)
+
+func TestSamplingDecisionsHandler(t *testing.T) {
+	decisions := sampler.NewDecisionLog(10)
+	decisions.Record(sampler.Decision{Service: "web", Resource: "GET /", Mechanism: sampler.MechanismPriority, Kept: true})
+	decisions.Record(sampler.Decision{Service: "db", Resource: "SELECT", Mechanism: sampler.MechanismNoPriority, Kept: false})
+	decisions.Record(sampler.Decision{Service: "web", Resource: "GET /", Mechanism: sampler.MechanismPriority, Kept: false})
+
+	ds := NewDebugServer(nil)
+	ds.AddRoute("/debug/sampling", SamplingDecisionsHandler(decisions))
+
+	rec := httptest.NewRecorder()
+	ds.mux().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/sampling?service=web", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var resp struct {
+		Summary   []sampler.DecisionSummary `json:"summary"`
+		Decisions []sampler.Decision        `json:"decisions"`
+	}
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	assert.Len(t, resp.Decisions, 2)
+	require.Len(t, resp.Summary, 1)
+	assert.Equal(t, "web", resp.Summary[0].Service)
+	assert.Equal(t, 2, resp.Summary[0].Total)
+	assert.Equal(t, 0.5, resp.Summary[0].KeptRate)
+}
@@ -0,0 +1,263 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tinylib/msgp/msgp"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/info"
+	"github.com/DataDog/datadog-agent/pkg/trace/log"
+	"github.com/DataDog/datadog-agent/pkg/trace/metrics"
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+	"github.com/DataDog/datadog-agent/pkg/trace/watchdog"
+)
+
+// failedFilesDir is the directory, relative to the watched directory, where the files which couldn't
+// be imported are moved.
+const failedFilesDir = "failed"
+
+var (
+	errFileReceiverStopped = errors.New("file receiver stopped")
+	errPayloadTooLarge     = errors.New("payload exceeds the maximum size")
+)
+
+// FileReceiver imports the spans of the files dropped in a directory, for the hosts which can't send
+// them over the network at runtime. The files hold v0.4 payloads, the traces being lists of spans:
+//   - the files ending in .msgpack hold a sequence of msgpack payloads, which can be separated by new lines.
+//   - the files ending in .json, .jsonl or .ndjson hold a JSON payload per line.
+//
+// The files are imported by name order, and removed once imported. The files which couldn't be
+// imported are moved to the "failed" directory, the payloads read before the error being kept.
+// Files with another extension are ignored, so that they can be written under a temporary name then
+// renamed once complete.
+type FileReceiver struct {
+	out  chan<- *Payload
+	conf *config.AgentConfig
+
+	exit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewFileReceiver returns a new FileReceiver which sends the payloads of the files down the out channel.
+func NewFileReceiver(out chan<- *Payload, conf *config.AgentConfig) *FileReceiver {
+	return &FileReceiver{
+		out:  out,
+		conf: conf,
+		exit: make(chan struct{}),
+	}
+}
+
+// Start starts watching the directory, if one is configured.
+func (r *FileReceiver) Start() {
+	dir := r.conf.FileReceiver.Dir
+	if dir == "" {
+		return
+	}
+	interval := r.conf.FileReceiver.PollInterval
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	r.wg.Add(1)
+	go func() {
+		defer watchdog.LogOnPanic()
+		defer r.wg.Done()
+
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			r.importDir(dir)
+			select {
+			case <-r.exit:
+				return
+			case <-t.C:
+			}
+		}
+	}()
+	log.Infof("Importing traces from the files of %s", dir)
+}
+
+// Stop stops watching the directory. The file being imported is left in place, the payloads already
+// read from it being imported again on the next start.
+func (r *FileReceiver) Stop() {
+	close(r.exit)
+	r.wg.Wait()
+}
+
+// importDir imports the files of dir, by name order.
+func (r *FileReceiver) importDir(dir string) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		log.Errorf("Cannot list the files to import traces from: %v", err)
+		return
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() || strings.HasPrefix(entry.Name(), ".") || fileDecoder(entry.Name()) == nil {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		err := r.importFile(path)
+		if err == errFileReceiverStopped {
+			return
+		}
+		if err == nil {
+			metrics.Count("datadog.trace_agent.file_receiver.files", 1, []string{"success:true"}, 1)
+			if err := os.Remove(path); err != nil {
+				log.Errorf("Cannot remove the imported file %s: %v", path, err)
+			}
+			continue
+		}
+		metrics.Count("datadog.trace_agent.file_receiver.files", 1, []string{"success:false"}, 1)
+		log.Errorf("Cannot import the traces of %s: %v", path, err)
+		if err := moveToFailed(dir, entry.Name()); err != nil {
+			// leaving the file in place would import the same payloads again on the next scan
+			log.Errorf("Cannot move %s to the %s directory, removing it: %v", path, failedFilesDir, err)
+			os.Remove(path) //nolint:errcheck
+		}
+	}
+}
+
+func moveToFailed(dir, name string) error {
+	failed := filepath.Join(dir, failedFilesDir)
+	if err := os.MkdirAll(failed, 0700); err != nil {
+		return err
+	}
+	return os.Rename(filepath.Join(dir, name), filepath.Join(failed, name))
+}
+
+// importFile sends the payloads of the file at path down the out channel.
+func (r *FileReceiver) importFile(path string) error {
+	decode := fileDecoder(path)
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	ts := &info.TagStats{
+		Tags:  info.Tags{EndpointVersion: "file_v0.4"},
+		Stats: info.NewStats(),
+	}
+	return decode(f, r.conf.MaxRequestBytes, func(chunks []*pb.TraceChunk) error {
+		payload := &Payload{
+			Source:        ts,
+			TracerPayload: &pb.TracerPayload{Chunks: chunks},
+		}
+		metrics.Count("datadog.trace_agent.file_receiver.traces", int64(len(chunks)), nil, 1)
+		select {
+		case r.out <- payload:
+			return nil
+		case <-r.exit:
+			return errFileReceiverStopped
+		}
+	})
+}
+
+// payloadDecoder decodes the payloads of r, each of at most maxSize bytes, and calls send with each of them.
+type payloadDecoder func(r io.Reader, maxSize int64, send func([]*pb.TraceChunk) error) error
+
+// fileDecoder returns the decoder of the files named name, or nil when they aren't imported.
+func fileDecoder(name string) payloadDecoder {
+	switch filepath.Ext(name) {
+	case ".msgpack":
+		return decodeMsgpackPayloads
+	case ".json", ".jsonl", ".ndjson":
+		return decodeJSONPayloads
+	default:
+		return nil
+	}
+}
+
+// decodeMsgpackPayloads decodes a sequence of msgpack payloads. A payload being a msgpack array, it can't
+// start with a new line which is skipped between the payloads.
+func decodeMsgpackPayloads(r io.Reader, maxSize int64, send func([]*pb.TraceChunk) error) error {
+	reader := msgp.NewReader(r)
+	buf := getBuffer()
+	defer putBuffer(buf)
+	for n := 1; ; n++ {
+		for {
+			b, err := reader.R.Peek(1)
+			if err == io.EOF && len(b) == 0 {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if b[0] != '\n' {
+				break
+			}
+			reader.R.Skip(1) //nolint:errcheck
+		}
+		buf.Reset()
+		if _, err := reader.CopyNext(&limitedWriter{w: buf, n: maxSize}); err != nil {
+			return fmt.Errorf("payload %d: %w", n, err)
+		}
+		var traces pb.Traces
+		if _, err := traces.UnmarshalMsg(buf.Bytes()); err != nil {
+			return fmt.Errorf("payload %d: %w", n, err)
+		}
+		if err := send(traceChunksFromTraces(traces)); err != nil {
+			return err
+		}
+	}
+}
+
+// decodeJSONPayloads decodes a JSON payload per line, the empty lines being skipped.
+func decodeJSONPayloads(r io.Reader, maxSize int64, send func([]*pb.TraceChunk) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, int(maxSize))
+	for n := 1; scanner.Scan(); n++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var traces pb.Traces
+		if err := json.Unmarshal(line, &traces); err != nil {
+			return fmt.Errorf("line %d: %w", n, err)
+		}
+		chunks := traceChunksFromTraces(traces)
+		// unlike the msgpack decoder, the JSON decoder doesn't run the hook
+		runMetaHook(chunks)
+		if err := send(chunks); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		if err == bufio.ErrTooLong {
+			return errPayloadTooLarge
+		}
+		return err
+	}
+	return nil
+}
+
+// limitedWriter is a writer failing once more than n bytes are written to it.
+type limitedWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (l *limitedWriter) Write(p []byte) (int, error) {
+	if int64(len(p)) > l.n {
+		return 0, errPayloadTooLarge
+	}
+	l.n -= int64(len(p))
+	return l.w.Write(p)
+}
@@ -0,0 +1,160 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+	"github.com/DataDog/datadog-agent/pkg/trace/testutil"
+)
+
+func newTestFileReceiver(t *testing.T) (*FileReceiver, chan *Payload, string) {
+	dir := t.TempDir()
+	cfg := config.New()
+	cfg.FileReceiver.Dir = dir
+	out := make(chan *Payload, 10)
+	return NewFileReceiver(out, cfg), out, dir
+}
+
+func writeMsgpackFile(t *testing.T, path string, payloads ...pb.Traces) {
+	var b []byte
+	for _, traces := range payloads {
+		var err error
+		b, err = traces.MarshalMsg(b)
+		require.NoError(t, err)
+		b = append(b, '\n')
+	}
+	require.NoError(t, os.WriteFile(path, b, 0600))
+}
+
+func writeJSONFile(t *testing.T, path string, payloads ...pb.Traces) {
+	var buf bytes.Buffer
+	for _, traces := range payloads {
+		require.NoError(t, json.NewEncoder(&buf).Encode(traces))
+		buf.WriteString("\n")
+	}
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0600))
+}
+
+func receivedTraces(t *testing.T, out chan *Payload) []pb.Trace {
+	var traces []pb.Trace
+	for {
+		select {
+		case p := <-out:
+			assert.Equal(t, "file_v0.4", p.Source.EndpointVersion)
+			for _, chunk := range p.Chunks() {
+				traces = append(traces, chunk.Spans)
+			}
+		default:
+			return traces
+		}
+	}
+}
+
+func TestFileReceiverImport(t *testing.T) {
+	rcv, out, dir := newTestFileReceiver(t)
+	msgpackTraces := testutil.GetTestTraces(3, 2, true)
+	jsonTraces := testutil.GetTestTraces(2, 2, true)
+	writeMsgpackFile(t, filepath.Join(dir, "0.msgpack"), msgpackTraces[:1], msgpackTraces[1:])
+	writeJSONFile(t, filepath.Join(dir, "1.jsonl"), jsonTraces)
+	// files being written are ignored
+	writeJSONFile(t, filepath.Join(dir, "2.jsonl.tmp"), jsonTraces)
+
+	rcv.importDir(dir)
+
+	expected := append(append([]pb.Trace{}, msgpackTraces...), jsonTraces...)
+	assert.Equal(t, expected, receivedTraces(t, out))
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "2.jsonl.tmp", entries[0].Name())
+}
+
+func TestFileReceiverFailedFile(t *testing.T) {
+	for name, content := range map[string][]byte{
+		"invalid.json":    []byte("[[{\"service\": \"a\"}]]\n{\n"),
+		"invalid.msgpack": {0x91, 0x91, 0xc1},
+		"truncated.msgpack": func() []byte {
+			b, err := testutil.GetTestTraces(1, 2, true).MarshalMsg(nil)
+			require.NoError(t, err)
+			return b[:len(b)-1]
+		}(),
+	} {
+		t.Run(name, func(t *testing.T) {
+			rcv, out, dir := newTestFileReceiver(t)
+			require.NoError(t, os.WriteFile(filepath.Join(dir, name), content, 0600))
+
+			rcv.importDir(dir)
+
+			if name == "invalid.json" {
+				// the payloads read before the error are imported
+				assert.Len(t, receivedTraces(t, out), 1)
+			} else {
+				assert.Empty(t, receivedTraces(t, out))
+			}
+			assert.NoFileExists(t, filepath.Join(dir, name))
+			assert.FileExists(t, filepath.Join(dir, failedFilesDir, name))
+
+			// the failed files aren't imported again
+			rcv.importDir(dir)
+			assert.Empty(t, receivedTraces(t, out))
+		})
+	}
+}
+
+func TestFileReceiverPayloadTooLarge(t *testing.T) {
+	traces := testutil.GetTestTraces(10, 10, true)
+	for _, name := range []string{"large.msgpack", "large.json"} {
+		t.Run(name, func(t *testing.T) {
+			rcv, out, dir := newTestFileReceiver(t)
+			rcv.conf.MaxRequestBytes = 100
+			if filepath.Ext(name) == ".json" {
+				writeJSONFile(t, filepath.Join(dir, name), traces)
+			} else {
+				writeMsgpackFile(t, filepath.Join(dir, name), traces)
+			}
+
+			rcv.importDir(dir)
+
+			assert.Empty(t, receivedTraces(t, out))
+			assert.FileExists(t, filepath.Join(dir, failedFilesDir, name))
+		})
+	}
+}
+
+func TestFileReceiverStop(t *testing.T) {
+	dir := t.TempDir()
+	cfg := config.New()
+	cfg.FileReceiver.Dir = dir
+	cfg.FileReceiver.PollInterval = 10 * time.Millisecond
+	// the receiver is blocked on the unbuffered channel until it's stopped
+	rcv := NewFileReceiver(make(chan *Payload), cfg)
+	writeMsgpackFile(t, filepath.Join(dir, "0.msgpack"), testutil.GetTestTraces(1, 1, true))
+	rcv.Start()
+
+	done := make(chan struct{})
+	go func() {
+		rcv.Stop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the receiver didn't stop")
+	}
+	// the file being imported is kept to be imported on the next start
+	assert.FileExists(t, filepath.Join(dir, "0.msgpack"))
+}
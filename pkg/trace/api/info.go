@@ -69,6 +69,7 @@ func (r *HTTPReceiver) makeInfoHandler() (hash string, handler http.HandlerFunc)
 		ClientDropP0s    bool          `json:"client_drop_p0s"`
 		SpanMetaStructs  bool          `json:"span_meta_structs"`
 		LongRunningSpans bool          `json:"long_running_spans"`
+		SpanEvents       bool          `json:"span_events"`
 		Config           reducedConfig `json:"config"`
 	}{
 		Version:          r.conf.AgentVersion,
@@ -78,6 +79,7 @@ func (r *HTTPReceiver) makeInfoHandler() (hash string, handler http.HandlerFunc)
 		ClientDropP0s:    true,
 		SpanMetaStructs:  true,
 		LongRunningSpans: true,
+		SpanEvents:       true,
 		Config: reducedConfig{
 			DefaultEnv:             r.conf.DefaultEnv,
 			TargetTPS:              r.conf.TargetTPS,
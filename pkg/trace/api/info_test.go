@@ -301,6 +301,7 @@ func TestInfoHandler(t *testing.T) {
 		"client_drop_p0s":    nil,
 		"span_meta_structs":  nil,
 		"long_running_spans": nil,
+		"span_events":        nil,
 		"config": map[string]interface{}{
 			"default_env":               nil,
 			"target_tps":                nil,
@@ -231,6 +231,14 @@ type WriterConfig struct {
 	// FlushPeriodSeconds specifies the frequency at which the writer's buffer
 	// will be flushed to the sender, in seconds. Fractions are permitted.
 	FlushPeriodSeconds float64 `mapstructure:"flush_period_seconds"`
+
+	// Compression specifies the algorithm used to compress the payloads sent
+	// to the intake: "gzip" (default) or "zstd".
+	Compression string `mapstructure:"compression"`
+
+	// CompressionLevel specifies the compression level. The fastest level of
+	// the algorithm is used by default.
+	CompressionLevel int `mapstructure:"compression_level"`
 }
 
 // FargateOrchestratorName is a Fargate orchestrator name.
@@ -289,6 +297,15 @@ type SymDBProxyConfig struct {
 	AdditionalEndpoints map[string][]string `json:"-"` // Never marshal this field
 }
 
+// FileReceiverConfig holds the configuration of the receiver importing the spans of the files
+// dropped in a directory, for the hosts which can't send them over the network.
+type FileReceiverConfig struct {
+	// Dir is the directory watched for files, the receiver is disabled when empty.
+	Dir string
+	// PollInterval is the interval at which the directory is scanned for new files.
+	PollInterval time.Duration
+}
+
 // AgentConfig handles the interpretation of the configuration (with default
 // behaviors) in one place. It is also a simple structure to share across all
 // the Agent components, with 100% safe and reliable values.
@@ -321,6 +338,13 @@ type AgentConfig struct {
 	ExtraAggregators       []string      // DEPRECATED
 	PeerServiceAggregation bool          // enables/disables stats aggregation for peer.service, used by Concentrator and ClientStatsAggregator
 	ComputeStatsBySpanKind bool          // enables/disables the computing of stats based on a span's `span.kind` field
+	SupplementClientStats  bool          // enables/disables computing stats in the agent for tracers claiming client computed stats without sending them
+
+	// ComputePeerService enables setting `peer.service` in the agent on the outbound spans missing it, from the
+	// first tag of PeerServicePrecedence set on the span. This gives consistent dependency names for the tracers
+	// which don't compute it.
+	ComputePeerService    bool
+	PeerServicePrecedence []string
 
 	// Sampler configuration
 	ExtraSampleRate float64
@@ -343,6 +367,10 @@ type AgentConfig struct {
 	ReceiverTimeout int
 	MaxRequestBytes int64 // specifies the maximum allowed request size for incoming trace payloads
 
+	// MaxContainerPayloadsPerSecond limits the number of trace payloads per second accepted
+	// from each container. A value of 0 disables the limit.
+	MaxContainerPayloadsPerSecond float64
+
 	WindowsPipeName        string
 	PipeBufferSize         int
 	PipeSecurityDescriptor string
@@ -354,6 +382,7 @@ type AgentConfig struct {
 	StatsWriter             *WriterConfig
 	TraceWriter             *WriterConfig
 	ConnectionResetInterval time.Duration // frequency at which outgoing connections are reset. 0 means no reset is performed
+	ShutdownDrainTimeout    time.Duration // maximum time given to process and send the received payloads when stopping
 
 	// internal telemetry
 	StatsdEnabled  bool
@@ -444,6 +473,13 @@ type AgentConfig struct {
 
 	// DebugServerPort defines the port used by the debug server
 	DebugServerPort int
+
+	// SamplingDecisionsBufferSize is the number of sampling decisions reported by
+	// the /debug/sampling endpoint of the debug server, 0 disables it
+	SamplingDecisionsBufferSize int
+
+	// FileReceiver specifies the settings of the receiver importing spans from files.
+	FileReceiver FileReceiverConfig
 }
 
 // RemoteClient client is used to APM Sampling Updates from a remote source.
@@ -459,6 +495,9 @@ type Tag struct {
 	K, V string
 }
 
+// DefaultPeerServicePrecedence lists the tags `peer.service` is computed from by default, by order of precedence
+var DefaultPeerServicePrecedence = []string{"db.instance", "net.peer.name", "out.host"}
+
 // New returns a configuration with the default values.
 func New() *AgentConfig {
 	return &AgentConfig{
@@ -469,6 +508,8 @@ func New() *AgentConfig {
 		Site:                "datadoghq.com",
 		MaxCatalogEntries:   5000,
 
+		FileReceiver: FileReceiverConfig{PollInterval: 5 * time.Second},
+
 		BucketInterval: time.Duration(10) * time.Second,
 
 		ExtraSampleRate: 1.0,
@@ -492,6 +533,7 @@ func New() *AgentConfig {
 		StatsWriter:             new(WriterConfig),
 		TraceWriter:             new(WriterConfig),
 		ConnectionResetInterval: 0, // disabled
+		ShutdownDrainTimeout:    5 * time.Second,
 
 		StatsdHost:    "localhost",
 		StatsdPort:    8125,
@@ -508,6 +550,7 @@ func New() *AgentConfig {
 		AnalyzedSpansByService:      make(map[string]map[string]float64),
 		Obfuscation:                 &ObfuscationConfig{},
 		MaxResourceLen:              5000,
+		PeerServicePrecedence:       DefaultPeerServicePrecedence,
 
 		GlobalTags: make(map[string]string),
 
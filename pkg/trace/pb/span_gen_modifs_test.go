@@ -410,3 +410,53 @@ func TestDeserialization(t *testing.T) {
 		})
 	})
 }
+
+func TestSpanEventsSerialization(t *testing.T) {
+	span := Span{
+		Service: "web",
+		Name:    "http.request",
+		SpanEvents: []*SpanEvent{
+			{
+				TimeUnixNano: 1680000000000000000,
+				Name:         "exception",
+				Attributes: map[string]string{
+					"exception.type":    "ValueError",
+					"exception.message": "invalid literal",
+				},
+			},
+			{TimeUnixNano: 1680000000000000001, Name: "retry"},
+		},
+	}
+
+	t.Run("msgpack", func(t *testing.T) {
+		bts, err := span.MarshalMsg(nil)
+		assert.NoError(t, err)
+		assert.LessOrEqual(t, len(bts), span.Msgsize())
+
+		var out Span
+		_, err = out.UnmarshalMsg(bts)
+		assert.NoError(t, err)
+		assert.Equal(t, span.SpanEvents, out.SpanEvents)
+	})
+
+	t.Run("msgpack-nil", func(t *testing.T) {
+		bts := msgp.AppendMapHeader(nil, 1)
+		bts = msgp.AppendString(bts, "span_events")
+		bts = msgp.AppendNil(bts)
+
+		var out Span
+		_, err := out.UnmarshalMsg(bts)
+		assert.NoError(t, err)
+		assert.Nil(t, out.SpanEvents)
+	})
+
+	t.Run("protobuf", func(t *testing.T) {
+		bts, err := span.Marshal()
+		assert.NoError(t, err)
+		assert.Len(t, bts, span.Size())
+
+		var out Span
+		assert.NoError(t, out.Unmarshal(bts))
+		assert.Equal(t, span.SpanEvents, out.SpanEvents)
+	})
+}
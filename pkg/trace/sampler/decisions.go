@@ -0,0 +1,125 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package sampler
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Mechanisms of the sampling decisions taken by the agent
+const (
+	// MechanismUserDrop is used for traces dropped by the user in the tracer
+	MechanismUserDrop = "user_drop"
+	// MechanismPriority is used for traces kept or dropped according to the priority set by the tracer
+	MechanismPriority = "priority"
+	// MechanismError is used for traces containing errors, sampled by the errors sampler
+	MechanismError = "error"
+	// MechanismRare is used for traces kept by the rare sampler
+	MechanismRare = "rare"
+	// MechanismNoPriority is used for traces without priority, sampled by the no priority sampler
+	MechanismNoPriority = "no_priority"
+)
+
+// Decision is a sampling decision taken by the agent on a trace chunk
+type Decision struct {
+	Time      time.Time `json:"time"`
+	Service   string    `json:"service"`
+	Resource  string    `json:"resource"`
+	Env       string    `json:"env"`
+	Priority  int       `json:"priority"`
+	Mechanism string    `json:"mechanism"`
+	Kept      bool      `json:"kept"`
+}
+
+// DecisionSummary summarizes the sampling decisions taken on the traces of a service and resource
+type DecisionSummary struct {
+	Service  string  `json:"service"`
+	Resource string  `json:"resource"`
+	Total    int     `json:"total"`
+	Kept     int     `json:"kept"`
+	KeptRate float64 `json:"kept_rate"`
+	// Mechanisms counts the decisions of each mechanism
+	Mechanisms map[string]int `json:"mechanisms"`
+}
+
+// DecisionLog keeps the last sampling decisions in a ring buffer, so that users can
+// understand why the traces of a given service or resource are missing.
+type DecisionLog struct {
+	mu        sync.Mutex
+	decisions []Decision
+	next      int
+	full      bool
+}
+
+// NewDecisionLog returns a DecisionLog keeping the last size decisions, or nil if size is not positive.
+func NewDecisionLog(size int) *DecisionLog {
+	if size <= 0 {
+		return nil
+	}
+	return &DecisionLog{decisions: make([]Decision, size)}
+}
+
+// Record adds a decision to the log, evicting the oldest one when the log is full.
+func (l *DecisionLog) Record(d Decision) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.decisions[l.next] = d
+	l.next++
+	if l.next == len(l.decisions) {
+		l.next = 0
+		l.full = true
+	}
+}
+
+// Decisions returns the decisions of the log, from the oldest to the most recent.
+func (l *DecisionLog) Decisions() []Decision {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.full {
+		return append([]Decision(nil), l.decisions[:l.next]...)
+	}
+	return append(append([]Decision(nil), l.decisions[l.next:]...), l.decisions[:l.next]...)
+}
+
+// SummarizeDecisions groups the decisions by service and resource, sorted by service and resource.
+func SummarizeDecisions(decisions []Decision) []DecisionSummary {
+	type key struct{ service, resource string }
+	summaries := make(map[key]*DecisionSummary)
+	for _, d := range decisions {
+		k := key{service: d.Service, resource: d.Resource}
+		s, ok := summaries[k]
+		if !ok {
+			s = &DecisionSummary{Service: d.Service, Resource: d.Resource, Mechanisms: make(map[string]int)}
+			summaries[k] = s
+		}
+		s.Total++
+		if d.Kept {
+			s.Kept++
+		}
+		s.Mechanisms[d.Mechanism]++
+	}
+
+	result := make([]DecisionSummary, 0, len(summaries))
+	for _, s := range summaries {
+		s.KeptRate = float64(s.Kept) / float64(s.Total)
+		result = append(result, *s)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Service != result[j].Service {
+			return result[i].Service < result[j].Service
+		}
+		return result[i].Resource < result[j].Resource
+	})
+	return result
+}
@@ -0,0 +1,56 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package sampler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecisionLog(t *testing.T) {
+	assert.Nil(t, NewDecisionLog(0))
+	var disabled *DecisionLog
+	disabled.Record(Decision{Service: "web"})
+	assert.Empty(t, disabled.Decisions())
+
+	l := NewDecisionLog(3)
+	l.Record(Decision{Resource: "a"})
+	l.Record(Decision{Resource: "b"})
+	assert.Equal(t, []Decision{{Resource: "a"}, {Resource: "b"}}, l.Decisions())
+
+	l.Record(Decision{Resource: "c"})
+	l.Record(Decision{Resource: "d"})
+	assert.Equal(t, []Decision{{Resource: "b"}, {Resource: "c"}, {Resource: "d"}}, l.Decisions())
+}
+
+func TestSummarizeDecisions(t *testing.T) {
+	summary := SummarizeDecisions([]Decision{
+		{Service: "web", Resource: "GET /users", Mechanism: MechanismPriority, Kept: true},
+		{Service: "db", Resource: "SELECT", Mechanism: MechanismNoPriority, Kept: false},
+		{Service: "web", Resource: "GET /users", Mechanism: MechanismPriority, Kept: false},
+		{Service: "web", Resource: "GET /users", Mechanism: MechanismError, Kept: true},
+		{Service: "web", Resource: "GET /users", Mechanism: MechanismUserDrop, Kept: false},
+	})
+	assert.Equal(t, []DecisionSummary{
+		{
+			Service:    "db",
+			Resource:   "SELECT",
+			Total:      1,
+			Kept:       0,
+			KeptRate:   0,
+			Mechanisms: map[string]int{MechanismNoPriority: 1},
+		},
+		{
+			Service:    "web",
+			Resource:   "GET /users",
+			Total:      4,
+			Kept:       2,
+			KeptRate:   0.5,
+			Mechanisms: map[string]int{MechanismPriority: 2, MechanismError: 1, MechanismUserDrop: 1},
+		},
+	}, summary)
+}
@@ -43,6 +43,8 @@ type PayloadAggregationKey struct {
 	Hostname    string
 	Version     string
 	ContainerID string
+	// Supplemented is true for the stats computed by the agent for a tracer missing its client stats
+	Supplemented bool
 }
 
 func getStatusCode(s *pb.Span) uint32 {
@@ -129,10 +129,17 @@ func computeStatsForSpanKind(s *pb.Span) bool {
 	}
 }
 
+// SupplementedStatsTag tags the stats payloads computed by the agent for tracers which claim to
+// compute stats on their side but don't send them, so they can be told apart from client stats.
+const SupplementedStatsTag = "_dd.supplemented_client_stats:true"
+
 // Input specifies a set of traces originating from a certain payload.
 type Input struct {
 	Traces      []traceutil.ProcessedTrace
 	ContainerID string
+	// Supplemented is true when the traces come from a tracer which claims to compute stats
+	// on its side but doesn't send them, the stats computed by the agent being tagged as such.
+	Supplemented bool
 }
 
 // NewStatsInput allocates a stats input for an incoming trace payload
@@ -156,14 +163,14 @@ func NewStatsInput(numChunks int, containerID string, clientComputedStats bool,
 func (c *Concentrator) Add(t Input) {
 	c.mu.Lock()
 	for _, trace := range t.Traces {
-		c.addNow(&trace, t.ContainerID)
+		c.addNow(&trace, t.ContainerID, t.Supplemented)
 	}
 	c.mu.Unlock()
 }
 
 // addNow adds the given input into the concentrator.
 // Callers must guard!
-func (c *Concentrator) addNow(pt *traceutil.ProcessedTrace, containerID string) {
+func (c *Concentrator) addNow(pt *traceutil.ProcessedTrace, containerID string, supplemented bool) {
 	hostname := pt.TracerHostname
 	if hostname == "" {
 		hostname = c.agentHostname
@@ -174,10 +181,11 @@ func (c *Concentrator) addNow(pt *traceutil.ProcessedTrace, containerID string)
 	}
 	weight := weight(pt.Root)
 	aggKey := PayloadAggregationKey{
-		Env:         env,
-		Hostname:    hostname,
-		Version:     pt.AppVersion,
-		ContainerID: containerID,
+		Env:          env,
+		Hostname:     hostname,
+		Version:      pt.AppVersion,
+		ContainerID:  containerID,
+		Supplemented: supplemented,
 	}
 	for _, s := range pt.TraceChunk.Spans {
 		isTop := traceutil.HasTopLevel(s)
@@ -250,6 +258,9 @@ func (c *Concentrator) flushNow(now int64, force bool) pb.StatsPayload {
 			Version:     k.Version,
 			Stats:       s,
 		}
+		if k.Supplemented {
+			p.Tags = []string{SupplementedStatsTag}
+		}
 		sb = append(sb, p)
 	}
 	return pb.StatsPayload{Stats: sb, AgentHostname: c.agentHostname, AgentEnv: c.agentEnv, AgentVersion: c.agentVersion}
@@ -106,12 +106,34 @@ func TestTracerHostname(t *testing.T) {
 	traceutil.ComputeTopLevel(spans)
 	testTrace := toProcessedTrace(spans, "none", "tracer-hostname")
 	c := NewTestConcentrator(now)
-	c.addNow(testTrace, "")
+	c.addNow(testTrace, "", false)
 
 	stats := c.flushNow(now.UnixNano()+int64(c.bufferLen)*testBucketInterval, false)
 	assert.Equal("tracer-hostname", stats.Stats[0].Hostname)
 }
 
+// TestSupplementedStats tests that the stats computed for tracers missing their client stats are tagged.
+func TestSupplementedStats(t *testing.T) {
+	assert := assert.New(t)
+	now := time.Now()
+
+	spans := []*pb.Span{
+		testSpan(1, 0, 50, 5, "A1", "resource1", 0),
+	}
+	traceutil.ComputeTopLevel(spans)
+	c := NewTestConcentrator(now)
+	c.addNow(toProcessedTrace(spans, "none", ""), "", true)
+	c.addNow(toProcessedTrace(spans, "none", ""), "", false)
+
+	stats := c.flushNow(now.UnixNano()+int64(c.bufferLen)*testBucketInterval, false)
+	assert.Len(stats.Stats, 2)
+	var tags [][]string
+	for _, p := range stats.Stats {
+		tags = append(tags, p.Tags)
+	}
+	assert.ElementsMatch([][]string{{SupplementedStatsTag}, nil}, tags)
+}
+
 // TestConcentratorOldestTs tests that the Agent doesn't report time buckets from a
 // time before its start
 func TestConcentratorOldestTs(t *testing.T) {
@@ -135,7 +157,7 @@ func TestConcentratorOldestTs(t *testing.T) {
 		// Running cold, all spans in the past should end up in the current time bucket.
 		flushTime := now.UnixNano()
 		c := NewTestConcentrator(now)
-		c.addNow(testTrace, "")
+		c.addNow(testTrace, "", false)
 
 		for i := 0; i < c.bufferLen; i++ {
 			stats := c.flushNow(flushTime, false)
@@ -172,7 +194,7 @@ func TestConcentratorOldestTs(t *testing.T) {
 		flushTime := now.UnixNano()
 		c := NewTestConcentrator(now)
 		c.oldestTs = alignTs(flushTime, c.bsize) - int64(c.bufferLen-1)*c.bsize
-		c.addNow(testTrace, "")
+		c.addNow(testTrace, "", false)
 
 		for i := 0; i < c.bufferLen-1; i++ {
 			stats := c.flushNow(flushTime, false)
@@ -252,7 +274,7 @@ func TestConcentratorStatsTotals(t *testing.T) {
 	testTrace := toProcessedTrace(spans, "none", "")
 
 	t.Run("ok", func(t *testing.T) {
-		c.addNow(testTrace, "")
+		c.addNow(testTrace, "", false)
 
 		var duration uint64
 		var hits uint64
@@ -421,7 +443,7 @@ func TestConcentratorStatsCounts(t *testing.T) {
 	traceutil.ComputeTopLevel(spans)
 	testTrace := toProcessedTrace(spans, "none", "")
 
-	c.addNow(testTrace, "")
+	c.addNow(testTrace, "", false)
 
 	// flush every testBucketInterval
 	flushTime := now.UnixNano()
@@ -470,7 +492,7 @@ func generateDistribution(t *testing.T, generator func(i int) int64) *ddsketch.D
 		spans = append(spans, testSpan(uint64(i)+1, 0, generator(i), 0, "A1", "resource1", 0))
 	}
 	traceutil.ComputeTopLevel(spans)
-	c.addNow(toProcessedTrace(spans, "none", ""), "")
+	c.addNow(toProcessedTrace(spans, "none", ""), "", false)
 	stats := c.flushNow(now.UnixNano()+c.bsize*int64(c.bufferLen), false)
 	expectedFlushedTs := alignedNow
 	assert.Len(stats.Stats, 1)
@@ -521,7 +543,7 @@ func TestIgnoresPartialSpans(t *testing.T) {
 	testTrace := toProcessedTrace(spans, "none", "tracer-hostname")
 
 	c := NewTestConcentrator(now)
-	c.addNow(testTrace, "")
+	c.addNow(testTrace, "", false)
 
 	stats := c.flushNow(now.UnixNano()+int64(c.bufferLen)*testBucketInterval, false)
 	assert.Empty(stats.GetStats())
@@ -535,7 +557,7 @@ func TestForceFlush(t *testing.T) {
 	traceutil.ComputeTopLevel(spans)
 	testTrace := toProcessedTrace(spans, "none", "")
 	c := NewTestConcentrator(now)
-	c.addNow(testTrace, "")
+	c.addNow(testTrace, "", false)
 
 	assert.Len(c.buckets, 1)
 
@@ -581,7 +603,7 @@ func TestPeerServiceStats(t *testing.T) {
 		testTrace := toProcessedTrace(spans, "none", "")
 		c := NewTestConcentrator(now)
 		c.peerSvcAggregation = true
-		c.addNow(testTrace, "")
+		c.addNow(testTrace, "", false)
 		stats := c.flushNow(now.UnixNano()+int64(c.bufferLen)*testBucketInterval, false)
 		assert.Len(stats.Stats[0].Stats[0].Stats, 2)
 		for _, st := range stats.Stats[0].Stats[0].Stats {
@@ -598,7 +620,7 @@ func TestPeerServiceStats(t *testing.T) {
 		testTrace := toProcessedTrace(spans, "none", "")
 		c := NewTestConcentrator(now)
 		c.peerSvcAggregation = false
-		c.addNow(testTrace, "")
+		c.addNow(testTrace, "", false)
 		stats := c.flushNow(now.UnixNano()+int64(c.bufferLen)*testBucketInterval, false)
 		assert.Len(stats.Stats[0].Stats[0].Stats, 2)
 		for _, st := range stats.Stats[0].Stats[0].Stats {
@@ -657,7 +679,7 @@ func TestComputeStatsThroughSpanKindCheck(t *testing.T) {
 		traceutil.ComputeTopLevel(spans)
 		testTrace := toProcessedTrace(spans, "none", "")
 		c := NewTestConcentrator(now)
-		c.addNow(testTrace, "")
+		c.addNow(testTrace, "", false)
 		stats := c.flushNow(now.UnixNano()+int64(c.bufferLen)*testBucketInterval, false)
 		assert.Len(stats.Stats[0].Stats[0].Stats, 3)
 		opNames := make(map[string]struct{}, 3)
@@ -676,7 +698,7 @@ func TestComputeStatsThroughSpanKindCheck(t *testing.T) {
 		testTrace := toProcessedTrace(spans, "none", "")
 		c := NewTestConcentrator(now)
 		c.computeStatsBySpanKind = true
-		c.addNow(testTrace, "")
+		c.addNow(testTrace, "", false)
 		stats := c.flushNow(now.UnixNano()+int64(c.bufferLen)*testBucketInterval, false)
 		assert.Len(stats.Stats[0].Stats[0].Stats, 4)
 		opNames := make(map[string]struct{}, 4)
@@ -126,10 +126,11 @@ func (sb *RawBucket) Export() map[PayloadAggregationKey]pb.ClientStatsBucket {
 			continue
 		}
 		key := PayloadAggregationKey{
-			Hostname:    k.Hostname,
-			Version:     k.Version,
-			Env:         k.Env,
-			ContainerID: k.ContainerID,
+			Hostname:     k.Hostname,
+			Version:      k.Version,
+			Env:          k.Env,
+			ContainerID:  k.ContainerID,
+			Supplemented: k.Supplemented,
 		}
 		s, ok := m[key]
 		if !ok {
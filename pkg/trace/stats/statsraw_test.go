@@ -114,7 +114,7 @@ func BenchmarkHandleSpanRandom(b *testing.B) {
 	b.ReportAllocs()
 	for i := 0; i < b.N; i++ {
 		for _, span := range benchSpans {
-			sb.HandleSpan(span, 1, true, "", PayloadAggregationKey{"a", "b", "c", "d"}, true)
+			sb.HandleSpan(span, 1, true, "", PayloadAggregationKey{"a", "b", "c", "d", false}, true)
 		}
 	}
 }
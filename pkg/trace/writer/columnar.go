@@ -0,0 +1,262 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package writer
+
+import (
+	"github.com/tinylib/msgp/msgp"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+)
+
+// featureColumnarPayload enables the experimental v1.0 intake protocol, which encodes the trace payloads
+// with the columnar encoding instead of protobuf.
+const featureColumnarPayload = "columnar_payload"
+
+// pathTracesV1 is the target host API path for delivering traces encoded with the columnar encoding.
+const pathTracesV1 = "/api/v1.0/traces"
+
+// columnarPayloadVersion is the version of the columnar encoding, sent as the "version" field of the payloads.
+const columnarPayloadVersion = "1.0"
+
+// columnarEncoder encodes an agent payload as msgpack, each trace chunk being written as columns of span
+// fields rather than as a list of spans. The strings of a chunk are written once in its string table and
+// referenced by their index, index 0 being the empty string, so that the services, names, resources and tags
+// repeated across the spans of a chunk are only sent once.
+//
+// An agent payload is written as a map with the keys:
+//   - "version", "host_name", "env", "agent_version", "target_tps", "error_tps", "rare_sampler_enabled"
+//   - "tracer_payloads", an array of maps with the keys "container_id", "language_name", "language_version",
+//     "tracer_version", "runtime_id", "env", "hostname", "app_version", "tags" and "chunks"
+//
+// A chunk is written as a map with the keys "priority", "origin", "dropped_trace", "tags", "strings" (the
+// string table) and "spans". Spans is a map of columns, each column holding one value per span:
+//   - "service", "name", "resource", "type": indexes in the string table
+//   - "trace_id", "span_id", "parent_id", "start", "duration", "error": integers
+//   - "meta": arrays of alternated key and value indexes
+//   - "metrics": arrays of alternated key indexes and float values
+//   - "meta_struct": arrays of alternated key indexes and binary values
+//   - "span_events": arrays of span events, each written as an array of its time, its name index and an
+//     array of alternated attribute key and value indexes
+//
+// The encoder isn't safe for concurrent use, its string table being reused across the chunks.
+type columnarEncoder struct {
+	strings map[string]uint32
+	table   []string
+	spans   []byte
+}
+
+func newColumnarEncoder() *columnarEncoder {
+	return &columnarEncoder{
+		strings: make(map[string]uint32),
+	}
+}
+
+// encode returns the columnar encoding of the given payload.
+func (e *columnarEncoder) encode(p *pb.AgentPayload) []byte {
+	b := make([]byte, 0, p.Size())
+	b = msgp.AppendMapHeader(b, 8)
+	b = msgp.AppendString(b, "version")
+	b = msgp.AppendString(b, columnarPayloadVersion)
+	b = msgp.AppendString(b, "host_name")
+	b = msgp.AppendString(b, p.HostName)
+	b = msgp.AppendString(b, "env")
+	b = msgp.AppendString(b, p.Env)
+	b = msgp.AppendString(b, "agent_version")
+	b = msgp.AppendString(b, p.AgentVersion)
+	b = msgp.AppendString(b, "target_tps")
+	b = msgp.AppendFloat64(b, p.TargetTPS)
+	b = msgp.AppendString(b, "error_tps")
+	b = msgp.AppendFloat64(b, p.ErrorTPS)
+	b = msgp.AppendString(b, "rare_sampler_enabled")
+	b = msgp.AppendBool(b, p.RareSamplerEnabled)
+	b = msgp.AppendString(b, "tracer_payloads")
+	b = msgp.AppendArrayHeader(b, uint32(len(p.TracerPayloads)))
+	for _, tp := range p.TracerPayloads {
+		b = e.appendTracerPayload(b, tp)
+	}
+	return b
+}
+
+func (e *columnarEncoder) appendTracerPayload(b []byte, tp *pb.TracerPayload) []byte {
+	b = msgp.AppendMapHeader(b, 10)
+	b = msgp.AppendString(b, "container_id")
+	b = msgp.AppendString(b, tp.ContainerID)
+	b = msgp.AppendString(b, "language_name")
+	b = msgp.AppendString(b, tp.LanguageName)
+	b = msgp.AppendString(b, "language_version")
+	b = msgp.AppendString(b, tp.LanguageVersion)
+	b = msgp.AppendString(b, "tracer_version")
+	b = msgp.AppendString(b, tp.TracerVersion)
+	b = msgp.AppendString(b, "runtime_id")
+	b = msgp.AppendString(b, tp.RuntimeID)
+	b = msgp.AppendString(b, "env")
+	b = msgp.AppendString(b, tp.Env)
+	b = msgp.AppendString(b, "hostname")
+	b = msgp.AppendString(b, tp.Hostname)
+	b = msgp.AppendString(b, "app_version")
+	b = msgp.AppendString(b, tp.AppVersion)
+	b = msgp.AppendString(b, "tags")
+	b = appendStringMap(b, tp.Tags)
+	b = msgp.AppendString(b, "chunks")
+	b = msgp.AppendArrayHeader(b, uint32(len(tp.Chunks)))
+	for _, chunk := range tp.Chunks {
+		b = e.appendChunk(b, chunk)
+	}
+	return b
+}
+
+func (e *columnarEncoder) appendChunk(b []byte, chunk *pb.TraceChunk) []byte {
+	e.reset()
+	// the spans are written before the string table, which is only complete once all the spans were
+	// visited, and then moved after it
+	e.spans = e.appendSpans(e.spans[:0], chunk.Spans)
+
+	b = msgp.AppendMapHeader(b, 6)
+	b = msgp.AppendString(b, "priority")
+	b = msgp.AppendInt32(b, chunk.Priority)
+	b = msgp.AppendString(b, "origin")
+	b = msgp.AppendString(b, chunk.Origin)
+	b = msgp.AppendString(b, "dropped_trace")
+	b = msgp.AppendBool(b, chunk.DroppedTrace)
+	b = msgp.AppendString(b, "tags")
+	b = appendStringMap(b, chunk.Tags)
+	b = msgp.AppendString(b, "strings")
+	b = msgp.AppendArrayHeader(b, uint32(len(e.table)))
+	for _, s := range e.table {
+		b = msgp.AppendString(b, s)
+	}
+	b = msgp.AppendString(b, "spans")
+	return append(b, e.spans...)
+}
+
+func (e *columnarEncoder) appendSpans(b []byte, spans []*pb.Span) []byte {
+	n := uint32(len(spans))
+	b = msgp.AppendMapHeader(b, 14)
+
+	for _, column := range []struct {
+		name  string
+		value func(s *pb.Span) string
+	}{
+		{"service", func(s *pb.Span) string { return s.Service }},
+		{"name", func(s *pb.Span) string { return s.Name }},
+		{"resource", func(s *pb.Span) string { return s.Resource }},
+		{"type", func(s *pb.Span) string { return s.Type }},
+	} {
+		b = msgp.AppendString(b, column.name)
+		b = msgp.AppendArrayHeader(b, n)
+		for _, s := range spans {
+			b = msgp.AppendUint32(b, e.index(column.value(s)))
+		}
+	}
+
+	for _, column := range []struct {
+		name  string
+		value func(s *pb.Span) uint64
+	}{
+		{"trace_id", func(s *pb.Span) uint64 { return s.TraceID }},
+		{"span_id", func(s *pb.Span) uint64 { return s.SpanID }},
+		{"parent_id", func(s *pb.Span) uint64 { return s.ParentID }},
+	} {
+		b = msgp.AppendString(b, column.name)
+		b = msgp.AppendArrayHeader(b, n)
+		for _, s := range spans {
+			b = msgp.AppendUint64(b, column.value(s))
+		}
+	}
+
+	for _, column := range []struct {
+		name  string
+		value func(s *pb.Span) int64
+	}{
+		{"start", func(s *pb.Span) int64 { return s.Start }},
+		{"duration", func(s *pb.Span) int64 { return s.Duration }},
+		{"error", func(s *pb.Span) int64 { return int64(s.Error) }},
+	} {
+		b = msgp.AppendString(b, column.name)
+		b = msgp.AppendArrayHeader(b, n)
+		for _, s := range spans {
+			b = msgp.AppendInt64(b, column.value(s))
+		}
+	}
+
+	b = msgp.AppendString(b, "meta")
+	b = msgp.AppendArrayHeader(b, n)
+	for _, s := range spans {
+		b = e.appendIndexedStringMap(b, s.Meta)
+	}
+
+	b = msgp.AppendString(b, "metrics")
+	b = msgp.AppendArrayHeader(b, n)
+	for _, s := range spans {
+		b = msgp.AppendArrayHeader(b, uint32(2*len(s.Metrics)))
+		for k, v := range s.Metrics {
+			b = msgp.AppendUint32(b, e.index(k))
+			b = msgp.AppendFloat64(b, v)
+		}
+	}
+
+	b = msgp.AppendString(b, "meta_struct")
+	b = msgp.AppendArrayHeader(b, n)
+	for _, s := range spans {
+		b = msgp.AppendArrayHeader(b, uint32(2*len(s.MetaStruct)))
+		for k, v := range s.MetaStruct {
+			b = msgp.AppendUint32(b, e.index(k))
+			b = msgp.AppendBytes(b, v)
+		}
+	}
+
+	b = msgp.AppendString(b, "span_events")
+	b = msgp.AppendArrayHeader(b, n)
+	for _, s := range spans {
+		b = msgp.AppendArrayHeader(b, uint32(len(s.SpanEvents)))
+		for _, event := range s.SpanEvents {
+			b = msgp.AppendArrayHeader(b, 3)
+			b = msgp.AppendUint64(b, event.TimeUnixNano)
+			b = msgp.AppendUint32(b, e.index(event.Name))
+			b = e.appendIndexedStringMap(b, event.Attributes)
+		}
+	}
+	return b
+}
+
+// appendIndexedStringMap writes a map of strings as an array of alternated key and value indexes.
+func (e *columnarEncoder) appendIndexedStringMap(b []byte, m map[string]string) []byte {
+	b = msgp.AppendArrayHeader(b, uint32(2*len(m)))
+	for k, v := range m {
+		b = msgp.AppendUint32(b, e.index(k))
+		b = msgp.AppendUint32(b, e.index(v))
+	}
+	return b
+}
+
+// index returns the index of a string in the string table of the current chunk, adding it if needed.
+func (e *columnarEncoder) index(s string) uint32 {
+	if idx, ok := e.strings[s]; ok {
+		return idx
+	}
+	idx := uint32(len(e.table))
+	e.strings[s] = idx
+	e.table = append(e.table, s)
+	return idx
+}
+
+// reset empties the string table, which is kept allocated for the next chunk.
+func (e *columnarEncoder) reset() {
+	for s := range e.strings {
+		delete(e.strings, s)
+	}
+	e.table = e.table[:0]
+	e.index("")
+}
+
+func appendStringMap(b []byte, m map[string]string) []byte {
+	b = msgp.AppendMapHeader(b, uint32(len(m)))
+	for k, v := range m {
+		b = msgp.AppendString(b, k)
+		b = msgp.AppendString(b, v)
+	}
+	return b
+}
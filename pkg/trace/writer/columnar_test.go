@@ -0,0 +1,401 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package writer
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tinylib/msgp/msgp"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+	"github.com/DataDog/datadog-agent/pkg/trace/telemetry"
+	"github.com/DataDog/datadog-agent/pkg/trace/testutil"
+)
+
+func testColumnarPayload() *pb.AgentPayload {
+	chunks := testutil.GetTestTraceChunks(3, 20, true)
+	chunks[0].Origin = "lambda"
+	chunks[0].Tags = map[string]string{"_dd.p.dm": "-4"}
+	chunks[0].Spans[0].MetaStruct = map[string][]byte{"appsec": []byte{0x80}}
+	chunks[0].Spans[0].SpanEvents = []*pb.SpanEvent{{
+		TimeUnixNano: 1,
+		Name:         "exception",
+		Attributes:   map[string]string{"message": "boom"},
+	}}
+	return &pb.AgentPayload{
+		HostName:           testHostname,
+		Env:                testEnv,
+		AgentVersion:       "7.0.0",
+		TargetTPS:          10,
+		ErrorTPS:           5,
+		RareSamplerEnabled: true,
+		TracerPayloads: []*pb.TracerPayload{{
+			ContainerID:   "abc",
+			LanguageName:  "go",
+			TracerVersion: "1.0",
+			Tags:          map[string]string{"env": "prod"},
+			Chunks:        chunks,
+		}},
+	}
+}
+
+func TestColumnarEncoder(t *testing.T) {
+	payload := testColumnarPayload()
+	e := newColumnarEncoder()
+	b := e.encode(payload)
+
+	decoded, err := decodeColumnar(b)
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(payload, decoded), "expected %v, got %v", payload, decoded)
+
+	// the string table is reset for every chunk, so encoding a payload again gives the same result
+	assert.Len(t, e.encode(payload), len(b))
+}
+
+func TestColumnarEncoderSize(t *testing.T) {
+	chunk := testutil.GetTestTraceChunks(1, 1, true)[0]
+	for i := 0; i < 100; i++ {
+		span := *chunk.Spans[0]
+		span.SpanID = uint64(i + 1)
+		chunk.Spans = append(chunk.Spans, &span)
+	}
+	payload := &pb.AgentPayload{TracerPayloads: []*pb.TracerPayload{{Chunks: []*pb.TraceChunk{chunk}}}}
+
+	b := newColumnarEncoder().encode(payload)
+	assert.Less(t, len(b), payload.Size())
+}
+
+func TestTraceWriterColumnar(t *testing.T) {
+	srv := newTestServer()
+	defer srv.Close()
+	cfg := &config.AgentConfig{
+		Hostname:   testHostname,
+		DefaultEnv: testEnv,
+		Endpoints: []*config.Endpoint{{
+			APIKey: "123",
+			Host:   srv.URL,
+		}},
+		TraceWriter: &config.WriterConfig{ConnectionLimit: 200, QueueSize: 40},
+		Features:    map[string]struct{}{featureColumnarPayload: {}},
+	}
+	tw := NewTraceWriter(cfg, mockSampler, mockSampler, mockSampler, telemetry.NewNoopCollector())
+	go tw.Run()
+	sampled := randomSampledSpans(20, 8)
+	tw.In <- sampled
+	tw.Stop()
+	assert.Eventually(t, func() bool { return len(srv.Payloads()) == 1 }, 5*time.Second, 10*time.Millisecond)
+
+	p := srv.Payloads()[0]
+	assert.Equal(t, "application/msgpack", p.headers["Content-Type"])
+	gzipr, err := gzip.NewReader(p.body)
+	require.NoError(t, err)
+	slurp, err := io.ReadAll(gzipr)
+	require.NoError(t, err)
+	decoded, err := decodeColumnar(slurp)
+	require.NoError(t, err)
+	assert.Equal(t, testHostname, decoded.HostName)
+	require.Len(t, decoded.TracerPayloads, 1)
+	assert.True(t, proto.Equal(sampled.TracerPayload, decoded.TracerPayloads[0]))
+}
+
+// decodeColumnar decodes a payload written by columnarEncoder.
+func decodeColumnar(b []byte) (*pb.AgentPayload, error) {
+	var p pb.AgentPayload
+	n, b, err := msgp.ReadMapHeaderBytes(b)
+	if err != nil {
+		return nil, err
+	}
+	for i := uint32(0); i < n; i++ {
+		var key string
+		if key, b, err = msgp.ReadStringBytes(b); err != nil {
+			return nil, err
+		}
+		switch key {
+		case "version":
+			_, b, err = msgp.ReadStringBytes(b)
+		case "host_name":
+			p.HostName, b, err = msgp.ReadStringBytes(b)
+		case "env":
+			p.Env, b, err = msgp.ReadStringBytes(b)
+		case "agent_version":
+			p.AgentVersion, b, err = msgp.ReadStringBytes(b)
+		case "target_tps":
+			p.TargetTPS, b, err = msgp.ReadFloat64Bytes(b)
+		case "error_tps":
+			p.ErrorTPS, b, err = msgp.ReadFloat64Bytes(b)
+		case "rare_sampler_enabled":
+			p.RareSamplerEnabled, b, err = msgp.ReadBoolBytes(b)
+		case "tracer_payloads":
+			var sz uint32
+			if sz, b, err = msgp.ReadArrayHeaderBytes(b); err != nil {
+				return nil, err
+			}
+			for j := uint32(0); j < sz && err == nil; j++ {
+				var tp *pb.TracerPayload
+				tp, b, err = decodeColumnarTracerPayload(b)
+				p.TracerPayloads = append(p.TracerPayloads, tp)
+			}
+		default:
+			b, err = msgp.Skip(b)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &p, nil
+}
+
+func decodeColumnarTracerPayload(b []byte) (*pb.TracerPayload, []byte, error) {
+	var tp pb.TracerPayload
+	n, b, err := msgp.ReadMapHeaderBytes(b)
+	if err != nil {
+		return nil, b, err
+	}
+	for i := uint32(0); i < n; i++ {
+		var key string
+		if key, b, err = msgp.ReadStringBytes(b); err != nil {
+			return nil, b, err
+		}
+		switch key {
+		case "container_id":
+			tp.ContainerID, b, err = msgp.ReadStringBytes(b)
+		case "language_name":
+			tp.LanguageName, b, err = msgp.ReadStringBytes(b)
+		case "language_version":
+			tp.LanguageVersion, b, err = msgp.ReadStringBytes(b)
+		case "tracer_version":
+			tp.TracerVersion, b, err = msgp.ReadStringBytes(b)
+		case "runtime_id":
+			tp.RuntimeID, b, err = msgp.ReadStringBytes(b)
+		case "env":
+			tp.Env, b, err = msgp.ReadStringBytes(b)
+		case "hostname":
+			tp.Hostname, b, err = msgp.ReadStringBytes(b)
+		case "app_version":
+			tp.AppVersion, b, err = msgp.ReadStringBytes(b)
+		case "tags":
+			tp.Tags, b, err = decodeStringMap(b)
+		case "chunks":
+			var sz uint32
+			if sz, b, err = msgp.ReadArrayHeaderBytes(b); err != nil {
+				return nil, b, err
+			}
+			for j := uint32(0); j < sz && err == nil; j++ {
+				var chunk *pb.TraceChunk
+				chunk, b, err = decodeColumnarChunk(b)
+				tp.Chunks = append(tp.Chunks, chunk)
+			}
+		default:
+			b, err = msgp.Skip(b)
+		}
+		if err != nil {
+			return nil, b, err
+		}
+	}
+	return &tp, b, nil
+}
+
+func decodeColumnarChunk(b []byte) (*pb.TraceChunk, []byte, error) {
+	var chunk pb.TraceChunk
+	var table []string
+	n, b, err := msgp.ReadMapHeaderBytes(b)
+	if err != nil {
+		return nil, b, err
+	}
+	for i := uint32(0); i < n; i++ {
+		var key string
+		if key, b, err = msgp.ReadStringBytes(b); err != nil {
+			return nil, b, err
+		}
+		switch key {
+		case "priority":
+			chunk.Priority, b, err = msgp.ReadInt32Bytes(b)
+		case "origin":
+			chunk.Origin, b, err = msgp.ReadStringBytes(b)
+		case "dropped_trace":
+			chunk.DroppedTrace, b, err = msgp.ReadBoolBytes(b)
+		case "tags":
+			chunk.Tags, b, err = decodeStringMap(b)
+		case "strings":
+			var sz uint32
+			if sz, b, err = msgp.ReadArrayHeaderBytes(b); err != nil {
+				return nil, b, err
+			}
+			table = make([]string, sz)
+			for j := range table {
+				if table[j], b, err = msgp.ReadStringBytes(b); err != nil {
+					return nil, b, err
+				}
+			}
+		case "spans":
+			chunk.Spans, b, err = decodeColumnarSpans(b, table)
+		default:
+			b, err = msgp.Skip(b)
+		}
+		if err != nil {
+			return nil, b, err
+		}
+	}
+	return &chunk, b, nil
+}
+
+func decodeColumnarSpans(b []byte, table []string) ([]*pb.Span, []byte, error) {
+	var spans []*pb.Span
+	span := func(j uint32) *pb.Span {
+		for uint32(len(spans)) <= j {
+			spans = append(spans, &pb.Span{})
+		}
+		return spans[j]
+	}
+
+	n, b, err := msgp.ReadMapHeaderBytes(b)
+	if err != nil {
+		return nil, b, err
+	}
+	for i := uint32(0); i < n; i++ {
+		var key string
+		if key, b, err = msgp.ReadStringBytes(b); err != nil {
+			return nil, b, err
+		}
+		var sz uint32
+		if sz, b, err = msgp.ReadArrayHeaderBytes(b); err != nil {
+			return nil, b, err
+		}
+		for j := uint32(0); j < sz; j++ {
+			s := span(j)
+			switch key {
+			case "service":
+				s.Service, b, err = tableString(b, table)
+			case "name":
+				s.Name, b, err = tableString(b, table)
+			case "resource":
+				s.Resource, b, err = tableString(b, table)
+			case "type":
+				s.Type, b, err = tableString(b, table)
+			case "trace_id":
+				s.TraceID, b, err = msgp.ReadUint64Bytes(b)
+			case "span_id":
+				s.SpanID, b, err = msgp.ReadUint64Bytes(b)
+			case "parent_id":
+				s.ParentID, b, err = msgp.ReadUint64Bytes(b)
+			case "start":
+				s.Start, b, err = msgp.ReadInt64Bytes(b)
+			case "duration":
+				s.Duration, b, err = msgp.ReadInt64Bytes(b)
+			case "error":
+				s.Error, b, err = msgp.ReadInt32Bytes(b)
+			case "meta":
+				s.Meta, b, err = decodeIndexedStringMap(b, table)
+			case "metrics":
+				var kv uint32
+				if kv, b, err = msgp.ReadArrayHeaderBytes(b); err != nil || kv == 0 {
+					break
+				}
+				s.Metrics = make(map[string]float64, kv/2)
+				for k := uint32(0); k < kv/2 && err == nil; k++ {
+					var name string
+					if name, b, err = tableString(b, table); err == nil {
+						s.Metrics[name], b, err = msgp.ReadFloat64Bytes(b)
+					}
+				}
+			case "meta_struct":
+				var kv uint32
+				if kv, b, err = msgp.ReadArrayHeaderBytes(b); err != nil || kv == 0 {
+					break
+				}
+				s.MetaStruct = make(map[string][]byte, kv/2)
+				for k := uint32(0); k < kv/2 && err == nil; k++ {
+					var name string
+					if name, b, err = tableString(b, table); err == nil {
+						s.MetaStruct[name], b, err = msgp.ReadBytesBytes(b, nil)
+					}
+				}
+			case "span_events":
+				var events uint32
+				if events, b, err = msgp.ReadArrayHeaderBytes(b); err != nil {
+					break
+				}
+				for k := uint32(0); k < events && err == nil; k++ {
+					event := &pb.SpanEvent{}
+					if _, b, err = msgp.ReadArrayHeaderBytes(b); err != nil {
+						break
+					}
+					if event.TimeUnixNano, b, err = msgp.ReadUint64Bytes(b); err != nil {
+						break
+					}
+					if event.Name, b, err = tableString(b, table); err != nil {
+						break
+					}
+					event.Attributes, b, err = decodeIndexedStringMap(b, table)
+					s.SpanEvents = append(s.SpanEvents, event)
+				}
+			default:
+				b, err = msgp.Skip(b)
+			}
+			if err != nil {
+				return nil, b, err
+			}
+		}
+	}
+	return spans, b, nil
+}
+
+func decodeIndexedStringMap(b []byte, table []string) (map[string]string, []byte, error) {
+	kv, b, err := msgp.ReadArrayHeaderBytes(b)
+	if err != nil || kv == 0 {
+		return nil, b, err
+	}
+	m := make(map[string]string, kv/2)
+	for k := uint32(0); k < kv/2; k++ {
+		var key, value string
+		if key, b, err = tableString(b, table); err != nil {
+			return nil, b, err
+		}
+		if value, b, err = tableString(b, table); err != nil {
+			return nil, b, err
+		}
+		m[key] = value
+	}
+	return m, b, nil
+}
+
+func decodeStringMap(b []byte) (map[string]string, []byte, error) {
+	sz, b, err := msgp.ReadMapHeaderBytes(b)
+	if err != nil || sz == 0 {
+		return nil, b, err
+	}
+	m := make(map[string]string, sz)
+	for k := uint32(0); k < sz; k++ {
+		var key, value string
+		if key, b, err = msgp.ReadStringBytes(b); err != nil {
+			return nil, b, err
+		}
+		if value, b, err = msgp.ReadStringBytes(b); err != nil {
+			return nil, b, err
+		}
+		m[key] = value
+	}
+	return m, b, nil
+}
+
+func tableString(b []byte, table []string) (string, []byte, error) {
+	idx, b, err := msgp.ReadUint32Bytes(b)
+	if err != nil {
+		return "", b, err
+	}
+	if int(idx) >= len(table) {
+		return "", b, fmt.Errorf("string index %d out of range", idx)
+	}
+	return table[idx], b, nil
+}
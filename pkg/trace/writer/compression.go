@@ -0,0 +1,118 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package writer
+
+import (
+	"compress/gzip"
+	"io"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/log"
+	"github.com/DataDog/datadog-agent/pkg/trace/metrics"
+)
+
+const (
+	// compressionGzip compresses payloads with gzip. It is the default.
+	compressionGzip = "gzip"
+	// compressionZstd compresses payloads with zstd, which costs less CPU than gzip
+	// for a similar compression ratio.
+	compressionZstd = "zstd"
+
+	// zstdBestSpeed and zstdBestCompression bound the zstd compression levels, which are
+	// mapped to the closest level of the encoder.
+	zstdBestSpeed       = 1
+	zstdBestCompression = 22
+)
+
+// compressor compresses the payloads of a writer with the configured algorithm.
+type compressor struct {
+	// encoding is the algorithm used, it is also the value of the Content-Encoding header.
+	encoding string
+	level    int
+	tags     []string
+}
+
+// newCompressor returns the compressor configured for the given writer. Unknown algorithms
+// and invalid levels fall back to the defaults.
+func newCompressor(cfg *config.WriterConfig) *compressor {
+	c := &compressor{encoding: compressionGzip, level: gzip.BestSpeed}
+	switch cfg.Compression {
+	case "", compressionGzip:
+		if cfg.CompressionLevel != 0 {
+			if cfg.CompressionLevel < gzip.BestSpeed || cfg.CompressionLevel > gzip.BestCompression {
+				log.Warnf("Invalid gzip compression level %d, using %d", cfg.CompressionLevel, c.level)
+			} else {
+				c.level = cfg.CompressionLevel
+			}
+		}
+	case compressionZstd:
+		c.encoding = compressionZstd
+		c.level = zstdBestSpeed
+		if cfg.CompressionLevel != 0 {
+			if cfg.CompressionLevel < zstdBestSpeed || cfg.CompressionLevel > zstdBestCompression {
+				log.Warnf("Invalid zstd compression level %d, using %d", cfg.CompressionLevel, c.level)
+			} else {
+				c.level = cfg.CompressionLevel
+			}
+		}
+	default:
+		log.Warnf("Unknown compression %q, using %s", cfg.Compression, compressionGzip)
+	}
+	c.tags = []string{"compression:" + c.encoding}
+	return c
+}
+
+// compress writes the compressed data to w and reports the compression telemetry under
+// the given metric prefix.
+func (c *compressor) compress(w io.Writer, data []byte, metricPrefix string) error {
+	start := time.Now()
+	counter := &countingWriter{w: w}
+
+	var cw io.WriteCloser
+	switch c.encoding {
+	case compressionZstd:
+		zw, err := zstd.NewWriter(counter, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(c.level)), zstd.WithEncoderConcurrency(1))
+		if err != nil {
+			return err
+		}
+		cw = zw
+	default:
+		gw, err := gzip.NewWriterLevel(counter, c.level)
+		if err != nil {
+			return err
+		}
+		cw = gw
+	}
+
+	_, err := cw.Write(data)
+	if cerr := cw.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+
+	metrics.Timing(metricPrefix+".compress_time", time.Since(start), c.tags, 1)
+	if counter.n > 0 {
+		metrics.Histogram(metricPrefix+".compression_ratio", float64(len(data))/float64(counter.n), c.tags, 1)
+	}
+	return nil
+}
+
+// countingWriter counts the bytes written to the underlying writer.
+type countingWriter struct {
+	w io.Writer
+	n int
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += n
+	return n, err
+}
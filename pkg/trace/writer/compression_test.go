@@ -0,0 +1,101 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package writer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+)
+
+func TestNewCompressor(t *testing.T) {
+	for _, tt := range []struct {
+		name             string
+		cfg              config.WriterConfig
+		expectedEncoding string
+		expectedLevel    int
+	}{
+		{
+			name:             "default",
+			expectedEncoding: "gzip",
+			expectedLevel:    gzip.BestSpeed,
+		},
+		{
+			name:             "gzip level",
+			cfg:              config.WriterConfig{Compression: "gzip", CompressionLevel: 6},
+			expectedEncoding: "gzip",
+			expectedLevel:    6,
+		},
+		{
+			name:             "invalid gzip level",
+			cfg:              config.WriterConfig{Compression: "gzip", CompressionLevel: 42},
+			expectedEncoding: "gzip",
+			expectedLevel:    gzip.BestSpeed,
+		},
+		{
+			name:             "zstd",
+			cfg:              config.WriterConfig{Compression: "zstd"},
+			expectedEncoding: "zstd",
+			expectedLevel:    zstdBestSpeed,
+		},
+		{
+			name:             "zstd level",
+			cfg:              config.WriterConfig{Compression: "zstd", CompressionLevel: 3},
+			expectedEncoding: "zstd",
+			expectedLevel:    3,
+		},
+		{
+			name:             "unknown",
+			cfg:              config.WriterConfig{Compression: "lz4", CompressionLevel: 3},
+			expectedEncoding: "gzip",
+			expectedLevel:    gzip.BestSpeed,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newCompressor(&tt.cfg)
+			assert.Equal(t, tt.expectedEncoding, c.encoding)
+			assert.Equal(t, tt.expectedLevel, c.level)
+		})
+	}
+}
+
+func TestCompress(t *testing.T) {
+	data := bytes.Repeat([]byte("a highly compressible trace payload "), 100)
+
+	t.Run("gzip", func(t *testing.T) {
+		var buf bytes.Buffer
+		c := newCompressor(&config.WriterConfig{})
+		require.NoError(t, c.compress(&buf, data, "datadog.trace_agent.test"))
+		assert.Less(t, buf.Len(), len(data))
+
+		r, err := gzip.NewReader(&buf)
+		require.NoError(t, err)
+		uncompressed, err := io.ReadAll(r)
+		require.NoError(t, err)
+		assert.Equal(t, data, uncompressed)
+	})
+
+	t.Run("zstd", func(t *testing.T) {
+		var buf bytes.Buffer
+		c := newCompressor(&config.WriterConfig{Compression: "zstd"})
+		require.NoError(t, c.compress(&buf, data, "datadog.trace_agent.test"))
+		assert.Less(t, buf.Len(), len(data))
+
+		r, err := zstd.NewReader(&buf)
+		require.NoError(t, err)
+		defer r.Close()
+		uncompressed, err := io.ReadAll(r)
+		require.NoError(t, err)
+		assert.Equal(t, data, uncompressed)
+	})
+}
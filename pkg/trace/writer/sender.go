@@ -23,6 +23,7 @@ import (
 
 	"github.com/DataDog/datadog-agent/pkg/trace/config"
 	"github.com/DataDog/datadog-agent/pkg/trace/log"
+	"github.com/DataDog/datadog-agent/pkg/trace/metrics"
 	"github.com/DataDog/datadog-agent/pkg/trace/telemetry"
 )
 
@@ -43,13 +44,14 @@ func newSenders(cfg *config.AgentConfig, r eventRecorder, path string, climit, q
 			os.Exit(1)
 		}
 		senders[i] = newSender(&senderConfig{
-			client:    cfg.NewHTTPClient(),
-			maxConns:  int(maxConns),
-			maxQueued: qsize,
-			url:       url,
-			apiKey:    endpoint.APIKey,
-			recorder:  r,
-			userAgent: fmt.Sprintf("Datadog Trace Agent/%s/%s", cfg.AgentVersion, cfg.GitCommit),
+			client:       cfg.NewHTTPClient(),
+			maxConns:     int(maxConns),
+			maxQueued:    qsize,
+			url:          url,
+			apiKey:       endpoint.APIKey,
+			recorder:     r,
+			userAgent:    fmt.Sprintf("Datadog Trace Agent/%s/%s", cfg.AgentVersion, cfg.GitCommit),
+			drainTimeout: cfg.ShutdownDrainTimeout,
 		})
 	}
 	return senders
@@ -129,8 +131,14 @@ type senderConfig struct {
 	recorder eventRecorder
 	// userAgent is the computed user agent we'll use when communicating with Datadog
 	userAgent string
+	// drainTimeout specifies how long to wait for the inflight payloads to be sent
+	// when stopping. It defaults to defaultDrainTimeout.
+	drainTimeout time.Duration
 }
 
+// defaultDrainTimeout is the default time given to the senders to send their inflight payloads when stopping.
+const defaultDrainTimeout = 5 * time.Second
+
 // sender is responsible for sending payloads to a given URL. It uses a size-limited
 // retry queue with a backoff mechanism in case of retriable errors.
 type sender struct {
@@ -181,19 +189,27 @@ func (s *sender) backoff() {
 }
 
 // Stop stops the sender. It attempts to wait for all inflight payloads to complete
-// with a timeout of 5 seconds.
-func (s *sender) Stop() {
+// within the drain timeout, and returns the number of payloads which completed while
+// stopping and the number of payloads which were still inflight at the deadline.
+func (s *sender) Stop() (drained, dropped int) {
+	pending := int(s.inflight.Load())
 	s.WaitForInflight()
 	s.mu.Lock()
 	s.closed = true
 	s.mu.Unlock()
 	close(s.queue)
+	dropped = int(s.inflight.Load())
+	return pending - dropped, dropped
 }
 
 // WaitForInflight blocks until all in progress payloads are sent,
-// or the timeout is reached.
+// or the drain timeout is reached.
 func (s *sender) WaitForInflight() {
-	timeout := time.After(5 * time.Second)
+	drainTimeout := s.cfg.drainTimeout
+	if drainTimeout <= 0 {
+		drainTimeout = defaultDrainTimeout
+	}
+	timeout := time.After(drainTimeout)
 outer:
 	for {
 		select {
@@ -427,17 +443,38 @@ func (p *payload) httpRequest(url *url.URL) (*http.Request, error) {
 	return req, nil
 }
 
-// stopSenders attempts to simultaneously stop a group of senders.
-func stopSenders(senders []*sender) {
-	var wg sync.WaitGroup
+// stopSenders attempts to simultaneously stop a group of senders. It returns the total
+// number of payloads drained and dropped by the senders while stopping.
+func stopSenders(senders []*sender) (drained, dropped int) {
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex
+	)
 	for _, s := range senders {
 		wg.Add(1)
 		go func(s *sender) {
 			defer wg.Done()
-			s.Stop()
+			d, l := s.Stop()
+			mu.Lock()
+			drained += d
+			dropped += l
+			mu.Unlock()
 		}(s)
 	}
 	wg.Wait()
+	return drained, dropped
+}
+
+// reportDrain logs and reports the number of payloads which were sent, or dropped because the
+// drain timeout was reached, while stopping the senders of the named writer.
+func reportDrain(writer string, drained, dropped int) {
+	metrics.Count("datadog.trace_agent."+writer+".shutdown.drained", int64(drained), nil, 1)
+	metrics.Count("datadog.trace_agent."+writer+".shutdown.dropped", int64(dropped), nil, 1)
+	if dropped > 0 {
+		log.Warnf("%s stopped: %d payloads drained, %d payloads dropped after reaching the drain timeout", writer, drained, dropped)
+		return
+	}
+	log.Infof("%s stopped: %d payloads drained", writer, drained)
 }
 
 // sendPayloads sends the payload p to all senders.
@@ -107,6 +107,37 @@ func TestSender(t *testing.T) {
 		assert.Equal(0, server.Failed(), "failed")
 	})
 
+	t.Run("drain", func(t *testing.T) {
+		server := newTestServerWithLatency(50 * time.Millisecond)
+		defer server.Close()
+
+		s := newSender(testSenderConfig(server.URL))
+		for i := 0; i < 10; i++ {
+			s.Push(expectResponses(200))
+		}
+		drained, dropped := s.Stop()
+
+		assert.Equal(t, 10, drained, "drained")
+		assert.Equal(t, 0, dropped, "dropped")
+		assert.Equal(t, 10, server.Accepted(), "accepted")
+	})
+
+	t.Run("drain-timeout", func(t *testing.T) {
+		server := newTestServerWithLatency(time.Second)
+		defer server.Close()
+
+		cfg := testSenderConfig(server.URL)
+		cfg.drainTimeout = 100 * time.Millisecond
+		s := newSender(cfg)
+		for i := 0; i < 10; i++ {
+			s.Push(expectResponses(200))
+		}
+		drained, dropped := s.Stop()
+
+		assert.Equal(t, 0, drained, "drained")
+		assert.Equal(t, 10, dropped, "dropped")
+	})
+
 	t.Run("Push", func(t *testing.T) {
 		s := &sender{
 			cfg:      &senderConfig{},
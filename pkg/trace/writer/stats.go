@@ -6,7 +6,6 @@
 package writer
 
 import (
-	"compress/gzip"
 	"errors"
 	"io"
 	"math"
@@ -20,8 +19,6 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/trace/metrics/timing"
 	"github.com/DataDog/datadog-agent/pkg/trace/pb"
 	"github.com/DataDog/datadog-agent/pkg/trace/telemetry"
-
-	"github.com/tinylib/msgp/msgp"
 )
 
 // pathStats is the target host API path for delivering stats.
@@ -46,6 +43,8 @@ type StatsWriter struct {
 	stats   *info.StatsWriterInfo
 	conf    *config.AgentConfig
 
+	compressor *compressor
+
 	// syncMode reports whether the writer should flush on its own or only when FlushSync is called
 	syncMode  bool
 	payloads  []pb.StatsPayload // payloads buffered for sync mode
@@ -57,13 +56,14 @@ type StatsWriter struct {
 // NewStatsWriter returns a new StatsWriter. It must be started using Run.
 func NewStatsWriter(cfg *config.AgentConfig, in <-chan pb.StatsPayload, telemetryCollector telemetry.TelemetryCollector) *StatsWriter {
 	sw := &StatsWriter{
-		in:        in,
-		stats:     &info.StatsWriterInfo{},
-		stop:      make(chan struct{}),
-		flushChan: make(chan chan struct{}),
-		syncMode:  cfg.SynchronousFlushing,
-		easylog:   log.NewThrottled(5, 10*time.Second), // no more than 5 messages every 10 seconds
-		conf:      cfg,
+		in:         in,
+		stats:      &info.StatsWriterInfo{},
+		stop:       make(chan struct{}),
+		flushChan:  make(chan chan struct{}),
+		syncMode:   cfg.SynchronousFlushing,
+		easylog:    log.NewThrottled(5, 10*time.Second), // no more than 5 messages every 10 seconds
+		conf:       cfg,
+		compressor: newCompressor(cfg.StatsWriter),
 	}
 	climit := cfg.StatsWriter.ConnectionLimit
 	if climit == 0 {
@@ -105,6 +105,7 @@ func (w *StatsWriter) Run() {
 		case <-t.C:
 			w.report()
 		case <-w.stop:
+			w.drainAndSend()
 			return
 		}
 	}
@@ -127,7 +128,21 @@ func (w *StatsWriter) FlushSync() error {
 func (w *StatsWriter) Stop() {
 	w.stop <- struct{}{}
 	<-w.stop
-	stopSenders(w.senders)
+	drained, dropped := stopSenders(w.senders)
+	reportDrain("stats_writer", drained, dropped)
+}
+
+// drainAndSend sends the stats which were received, but not sent yet, to the senders
+func (w *StatsWriter) drainAndSend() {
+	for {
+		select {
+		case stats := <-w.in:
+			w.addStats(stats)
+		default:
+			w.sendPayloads()
+			return
+		}
+	}
 }
 
 func (w *StatsWriter) addStats(sp pb.StatsPayload) {
@@ -141,9 +156,9 @@ func (w *StatsWriter) SendPayload(p pb.StatsPayload) {
 	req := newPayload(map[string]string{
 		headerLanguages:    strings.Join(info.Languages(), "|"),
 		"Content-Type":     "application/msgpack",
-		"Content-Encoding": "gzip",
+		"Content-Encoding": w.compressor.encoding,
 	})
-	if err := encodePayload(req.body, p); err != nil {
+	if err := w.encodePayload(req.body, p); err != nil {
 		log.Errorf("Stats encoding error: %v", err)
 		return
 	}
@@ -161,18 +176,13 @@ func (w *StatsWriter) resetBuffer() {
 	w.payloads = make([]pb.StatsPayload, 0, len(w.payloads))
 }
 
-// encodePayload encodes the payload as Gzipped msgPack into w.
-func encodePayload(w io.Writer, payload pb.StatsPayload) error {
-	gz, err := gzip.NewWriterLevel(w, gzip.BestSpeed)
+// encodePayload encodes the payload as compressed msgPack into dst.
+func (w *StatsWriter) encodePayload(dst io.Writer, payload pb.StatsPayload) error {
+	b, err := payload.MarshalMsg(nil)
 	if err != nil {
 		return err
 	}
-	defer func() {
-		if err := gz.Close(); err != nil {
-			log.Errorf("Error closing gzip stream when writing stats payload: %v", err)
-		}
-	}()
-	return msgp.Encode(gz, &payload)
+	return w.compressor.compress(dst, b, "datadog.trace_agent.stats_writer")
 }
 
 // buildPayloads splits pb.ClientStatsPayload that have more than maxEntriesPerPayload
@@ -216,9 +226,9 @@ func (w *StatsWriter) buildPayloads(sp pb.StatsPayload, maxEntriesPerPayload int
 }
 
 // resolveContainerTags takes any ContainerID found in p to fill in the appropriate tags.
+// The container tags are appended to the tags already set by the agent.
 func (w *StatsWriter) resolveContainerTags(p *pb.ClientStatsPayload) {
 	if p.ContainerID == "" {
-		p.Tags = nil
 		return
 	}
 	ctags, err := w.conf.ContainerTags(p.ContainerID)
@@ -226,11 +236,8 @@ func (w *StatsWriter) resolveContainerTags(p *pb.ClientStatsPayload) {
 	case err != nil:
 		log.Tracef("Error resolving container tags for %q: %v", p.ContainerID, err)
 		p.ContainerID = ""
-		p.Tags = nil
-	case len(ctags) == 0:
-		p.Tags = nil
-	default:
-		p.Tags = ctags
+	case len(ctags) > 0:
+		p.Tags = append(p.Tags[:len(p.Tags):len(p.Tags)], ctags...)
 	}
 }
 
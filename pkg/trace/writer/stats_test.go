@@ -7,6 +7,7 @@ package writer
 
 import (
 	"compress/gzip"
+	"fmt"
 	"math"
 	"math/rand"
 	"runtime"
@@ -277,6 +278,30 @@ func TestStatsSyncWriter(t *testing.T) {
 	})
 }
 
+func TestStatsResolveContainerTags(t *testing.T) {
+	sw := &StatsWriter{conf: &config.AgentConfig{
+		ContainerTags: func(cid string) ([]string, error) {
+			if cid == "unknown" {
+				return nil, fmt.Errorf("unknown container")
+			}
+			return []string{"container_name:" + cid}, nil
+		},
+	}}
+
+	p := pb.ClientStatsPayload{ContainerID: "cid", Tags: []string{"agent:tag"}}
+	sw.resolveContainerTags(&p)
+	assert.Equal(t, []string{"agent:tag", "container_name:cid"}, p.Tags)
+
+	p = pb.ClientStatsPayload{Tags: []string{"agent:tag"}}
+	sw.resolveContainerTags(&p)
+	assert.Equal(t, []string{"agent:tag"}, p.Tags)
+
+	p = pb.ClientStatsPayload{ContainerID: "unknown", Tags: []string{"agent:tag"}}
+	sw.resolveContainerTags(&p)
+	assert.Empty(t, p.ContainerID)
+	assert.Equal(t, []string{"agent:tag"}, p.Tags)
+}
+
 func testStatsWriter() (*StatsWriter, chan pb.StatsPayload, *testServer) {
 	srv := newTestServer()
 	// We use a blocking channel to make sure that sends get received on the
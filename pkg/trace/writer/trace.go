@@ -6,7 +6,6 @@
 package writer
 
 import (
-	"compress/gzip"
 	"errors"
 	"math"
 	"strings"
@@ -66,9 +65,13 @@ type TraceWriter struct {
 	senders      []*sender
 	stop         chan struct{}
 	stats        *info.TraceWriterInfo
-	wg           sync.WaitGroup // waits for gzippers
+	wg           sync.WaitGroup // waits for compressors
 	tick         time.Duration  // flush frequency
 	agentVersion string
+	compressor   *compressor
+
+	// columnar is the encoder of the experimental v1.0 intake protocol, nil when payloads are encoded with protobuf
+	columnar *columnarEncoder
 
 	tracerPayloads []*pb.TracerPayload // tracer payloads buffered
 	bufferedSize   int                 // estimated buffer size
@@ -96,6 +99,7 @@ func NewTraceWriter(cfg *config.AgentConfig, prioritySampler samplerTPSReader, e
 		syncMode:        cfg.SynchronousFlushing,
 		tick:            5 * time.Second,
 		agentVersion:    cfg.AgentVersion,
+		compressor:      newCompressor(cfg.TraceWriter),
 		easylog:         log.NewThrottled(5, 10*time.Second), // no more than 5 messages every 10 seconds
 	}
 	climit := cfg.TraceWriter.ConnectionLimit
@@ -118,8 +122,14 @@ func NewTraceWriter(cfg *config.AgentConfig, prioritySampler samplerTPSReader, e
 	if s := cfg.TraceWriter.FlushPeriodSeconds; s != 0 {
 		tw.tick = time.Duration(s*1000) * time.Millisecond
 	}
+	path := pathTraces
+	if cfg.HasFeature(featureColumnarPayload) {
+		log.Infof("Trace writer using the experimental columnar encoding")
+		tw.columnar = newColumnarEncoder()
+		path = pathTracesV1
+	}
 	log.Debugf("Trace writer initialized (climit=%d qsize=%d)", climit, qsize)
-	tw.senders = newSenders(cfg, tw, pathTraces, climit, qsize, telemetryCollector)
+	tw.senders = newSenders(cfg, tw, path, climit, qsize, telemetryCollector)
 	return tw
 }
 
@@ -128,7 +138,8 @@ func (w *TraceWriter) Stop() {
 	log.Debug("Exiting trace writer. Trying to flush whatever is left...")
 	w.stop <- struct{}{}
 	<-w.stop
-	stopSenders(w.senders)
+	drained, dropped := stopSenders(w.senders)
+	reportDrain("trace_writer", drained, dropped)
 }
 
 // Run starts the TraceWriter.
@@ -248,10 +259,22 @@ func (w *TraceWriter) flush() {
 		TracerPayloads:     w.tracerPayloads,
 	}
 	log.Debugf("Reported agent rates: target_tps=%v errors_tps=%v rare_sampling=%v", p.TargetTPS, p.ErrorTPS, p.RareSamplerEnabled)
-	b, err := proto.Marshal(&p)
-	if err != nil {
-		log.Errorf("Failed to serialize payload, data dropped: %v", err)
-		return
+	contentType := "application/x-protobuf"
+	var b []byte
+	if w.columnar != nil {
+		contentType = "application/msgpack"
+		b = w.columnar.encode(&p)
+		// A/B telemetry comparing the size of the columnar encoding to the size of the protobuf encoding,
+		// which is computed without encoding the payload
+		metrics.Count("datadog.trace_agent.trace_writer.encoded_bytes", int64(len(b)), []string{"encoding:columnar"}, 1)
+		metrics.Count("datadog.trace_agent.trace_writer.encoded_bytes", int64(p.Size()), []string{"encoding:protobuf"}, 1)
+	} else {
+		var err error
+		b, err = proto.Marshal(&p)
+		if err != nil {
+			log.Errorf("Failed to serialize payload, data dropped: %v", err)
+			return
+		}
 	}
 
 	w.stats.BytesUncompressed.Add(int64(len(b)))
@@ -261,23 +284,14 @@ func (w *TraceWriter) flush() {
 		defer timing.Since("datadog.trace_agent.trace_writer.compress_ms", time.Now())
 		defer w.wg.Done()
 		p := newPayload(map[string]string{
-			"Content-Type":     "application/x-protobuf",
-			"Content-Encoding": "gzip",
+			"Content-Type":     contentType,
+			"Content-Encoding": w.compressor.encoding,
 			headerLanguages:    strings.Join(info.Languages(), "|"),
 		})
-		gzipw, err := gzip.NewWriterLevel(p.body, gzip.BestSpeed)
-		if err != nil {
-			// it will never happen, unless an invalid compression is chosen;
-			// we know gzip.BestSpeed is valid.
-			log.Errorf("gzip.NewWriterLevel: %d", err)
+		if err := w.compressor.compress(p.body, b, "datadog.trace_agent.trace_writer"); err != nil {
+			log.Errorf("Error compressing trace payload, data dropped: %v", err)
 			return
 		}
-		if _, err := gzipw.Write(b); err != nil {
-			log.Errorf("Error gzipping trace payload: %v", err)
-		}
-		if err := gzipw.Close(); err != nil {
-			log.Errorf("Error closing gzip stream when writing trace payload: %v", err)
-		}
 
 		sendPayloads(w.senders, p, w.syncMode)
 	}()
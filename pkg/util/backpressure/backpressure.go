@@ -0,0 +1,46 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package backpressure exposes a process-wide signal raised by the flush
+// pipeline (serializer and forwarder) when its queues are saturated, so that
+// intakes such as the DogStatsD listeners can shed low priority data instead
+// of blocking.
+package backpressure
+
+import (
+	"time"
+
+	"go.uber.org/atomic"
+)
+
+// holdDuration is how long the signal stays active after being raised. The
+// flush pipeline only raises the signal when it fails to enqueue data, keeping
+// it active for a while avoids flapping between two flushes.
+const holdDuration = 15 * time.Second
+
+var activeUntil = atomic.NewInt64(0)
+
+// Raise marks the flush pipeline as saturated.
+func Raise() {
+	raiseAt(time.Now())
+}
+
+// IsActive returns whether the flush pipeline has been saturated recently.
+func IsActive() bool {
+	return isActiveAt(time.Now())
+}
+
+// Reset clears the signal.
+func Reset() {
+	activeUntil.Store(0)
+}
+
+func raiseAt(now time.Time) {
+	activeUntil.Store(now.Add(holdDuration).UnixNano())
+}
+
+func isActiveAt(now time.Time) bool {
+	return now.UnixNano() < activeUntil.Load()
+}
@@ -0,0 +1,28 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package backpressure
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignal(t *testing.T) {
+	defer Reset()
+	now := time.Now()
+
+	assert.False(t, isActiveAt(now))
+
+	raiseAt(now)
+	assert.True(t, isActiveAt(now))
+	assert.True(t, isActiveAt(now.Add(holdDuration-time.Second)))
+	assert.False(t, isActiveAt(now.Add(holdDuration)))
+
+	Reset()
+	assert.False(t, isActiveAt(now))
+}
@@ -350,6 +350,10 @@ def ninja_cgo_type_files(nw, windows):
                 "pkg/network/ebpf/c/tracer/tracer.h",
                 "pkg/network/ebpf/c/protocols/kafka/types.h",
             ],
+            "pkg/network/protocols/dns/dns_types.go": [
+                "pkg/network/ebpf/c/tracer/tracer.h",
+                "pkg/network/ebpf/c/protocols/dns/types.h",
+            ],
             "pkg/network/telemetry/telemetry_types.go": [
                 "pkg/ebpf/c/telemetry_types.h",
             ],